@@ -0,0 +1,62 @@
+package aptos
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	AddressLength = 32
+
+	// 单签 ed25519 账户认证密钥的 scheme 字节
+	ed25519Scheme = 0x00
+	// 0x0 - 0xa 为系统保留地址, AIP-40 规定使用短格式
+	maxSpecialAddress = 0x0a
+)
+
+var errInvalidAddress = errors.New("invalid aptos address")
+
+type Address [AddressLength]byte
+
+// AddressFromPublicKey 认证密钥 sha3-256(公钥 || scheme), 未轮换密钥时即为账户地址
+func AddressFromPublicKey(publicKey []byte) Address {
+	return sha3.Sum256(append(append([]byte{}, publicKey...), ed25519Scheme))
+}
+
+// ParseAddress 支持省略前导 0 的短格式
+func ParseAddress(address string) (Address, error) {
+	var addr Address
+	s := strings.TrimPrefix(strings.ToLower(address), "0x")
+	if len(s) == 0 || len(s) > AddressLength*2 {
+		return addr, errInvalidAddress
+	}
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return addr, errInvalidAddress
+	}
+	copy(addr[AddressLength-len(raw):], raw)
+	return addr, nil
+}
+
+func (a Address) IsSpecial() bool {
+	for _, b := range a[:AddressLength-1] {
+		if b != 0 {
+			return false
+		}
+	}
+	return a[AddressLength-1] <= maxSpecialAddress
+}
+
+// String AIP-40 格式, 系统地址为短格式, 其余为 64 位 hex
+func (a Address) String() string {
+	if a.IsSpecial() {
+		return "0x" + hex.EncodeToString(a[AddressLength-1:])[1:]
+	}
+	return "0x" + hex.EncodeToString(a[:])
+}
@@ -1 +1,791 @@
 package aptos
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	ChainName = "Aptos"
+
+	coinBalanceFunction        = "0x1::coin::balance"
+	fungibleBalanceFunction    = "0x1::primary_fungible_store::balance"
+	originatingAddressFunction = "0x1::account::originating_address"
+)
+
+type ChainAdaptor struct {
+	aptosClient *AptosClient
+}
+
+func init() {
+	chain.Register(ChainName, NewChainAdaptor)
+}
+
+func NewChainAdaptor(conf *config.Config) (chain.IChainAdaptor, error) {
+	node, ok := conf.Chain(ChainName)
+	if !ok {
+		return nil, errors.New("aptos rpc not configured")
+	}
+	return &ChainAdaptor{
+		aptosClient: NewAptosClient(node.RPCs[0].RPCURL, node.DataApiKey, node.TimeOut.Duration()),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// ConvertAddress 返回 ed25519 公钥对应的认证密钥, 即未轮换密钥账户的地址
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if len(req.PublicKey) != ed25519.PublicKeySize {
+		return &account.ConvertAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid ed25519 public key",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: AddressFromPublicKey(req.PublicKey).String(),
+	}, nil
+}
+
+// ValidAddress 0x0 - 0xa 为框架保留地址, 不能作为提现地址
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	address, err := ParseAddress(req.Address)
+	if err != nil || !strings.HasPrefix(strings.ToLower(req.Address), "0x") {
+		return &account.ValidAddressResponse{
+			Code:  common.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:             common.ReturnCode_SUCCESS,
+		Msg:              "valid address",
+		Valid:            true,
+		CanWithdrawal:    !address.IsSpecial(),
+		CanonicalAddress: address.String(),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	block, err := c.getBlock(req.Height, req.ViewTx)
+	if err != nil {
+		log.Error("get block by number fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	height, _ := strconv.ParseInt(block.BlockHeight, 10, 64)
+	var txList []*account.BlockInfoTransactionList
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		item := &account.BlockInfoTransactionList{
+			Hash:   tx.Hash,
+			From:   tx.Sender,
+			Time:   microsToSeconds(tx.Timestamp),
+			Fee:    txFee(tx),
+			Status: tx.VmStatus,
+		}
+		if to, amount, _, ok := parseTransferPayload(&tx.Payload); ok {
+			item.To = to
+			item.Amount = amount
+		}
+		txList = append(txList, item)
+	}
+	return &account.BlockResponse{
+		Code:         common.ReturnCode_SUCCESS,
+		Msg:          "get block by number success",
+		Height:       height,
+		Hash:         block.BlockHash,
+		Transactions: txList,
+	}, nil
+}
+
+// GetBlockByHash Aptos 节点不提供按区块哈希查询的接口
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return &account.BlockResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "aptos does not support get block by hash",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	return &account.BlockHeaderResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "aptos does not support get block header by hash",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	block, err := c.getBlock(req.Height, false)
+	if err != nil {
+		log.Error("get block header by number fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	timestamp, _ := strconv.ParseUint(microsToSeconds(block.BlockTimestamp), 10, 64)
+	return &account.BlockHeaderResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get block header by number success",
+		BlockHeader: &account.BlockHeader{
+			Number: block.BlockHeight,
+			Time:   timestamp,
+		},
+	}, nil
+}
+
+func (c *ChainAdaptor) getBlock(height int64, withTransactions bool) (*Block, error) {
+	if height == 0 {
+		info, err := c.aptosClient.GetLedgerInfo()
+		if err != nil {
+			return nil, err
+		}
+		if height, err = strconv.ParseInt(info.BlockHeight, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	return c.aptosClient.GetBlockByHeight(uint64(height), withTransactions)
+}
+
+/**
+ * @description: 查询余额与 sequence number
+ * 合约地址为空时查询 APT, 含 "::" 时为 coin 类型, 否则为 fungible asset 的 metadata 地址
+ * ProposerKeyIndex 非 0 时表示账户密钥已轮换, Address 为新公钥派生的认证密钥, 先通过 originating_address 反查账户地址
+ * AccountNumber 返回链上当前的认证密钥, 调用方可据此判断密钥是否已轮换
+ */
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	address := req.Address
+	if req.ProposerKeyIndex != 0 {
+		originating, err := c.originatingAddress(req.Address)
+		if err != nil {
+			log.Error("get originating address fail", "err", err)
+			return &account.AccountResponse{
+				Code:    common.ReturnCode_ERROR,
+				Msg:     "get originating address fail",
+				Balance: "0",
+			}, nil
+		}
+		address = originating
+	}
+	sequence, authKey := "0", ""
+	data, err := c.aptosClient.GetAccount(address)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		log.Error("get account fail", "err", err)
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "get account fail",
+			Balance: "0",
+		}, nil
+	}
+	if data != nil {
+		sequence, authKey = data.SequenceNumber, data.AuthenticationKey
+	}
+	balance, err := c.balance(address, req.ContractAddress)
+	if err != nil {
+		log.Error("get balance by address fail", "err", err)
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "get balance by address fail",
+			Balance: "0",
+		}, nil
+	}
+	return &account.AccountResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: authKey,
+		Sequence:      sequence,
+		Network:       ChainName,
+		Balance:       balance,
+	}, nil
+}
+
+// originatingAddress 密钥轮换后, 由认证密钥反查原始账户地址, 未轮换时返回认证密钥本身
+func (c *ChainAdaptor) originatingAddress(authKey string) (string, error) {
+	result, err := c.aptosClient.View(&ViewRequest{
+		Function:  originatingAddressFunction,
+		Arguments: []interface{}{authKey},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(result) == 0 {
+		return "", errors.New("empty view result")
+	}
+	var option struct {
+		Vec []string `json:"vec"`
+	}
+	if err := json.Unmarshal(result[0], &option); err != nil {
+		return "", err
+	}
+	if len(option.Vec) == 0 {
+		return authKey, nil
+	}
+	return option.Vec[0], nil
+}
+
+func (c *ChainAdaptor) balance(address, contractAddress string) (string, error) {
+	request := &ViewRequest{
+		Function:      coinBalanceFunction,
+		TypeArguments: []string{AptosCoinType},
+		Arguments:     []interface{}{address},
+	}
+	if strings.Contains(contractAddress, "::") {
+		request.TypeArguments = []string{contractAddress}
+	} else if contractAddress != "" {
+		request = &ViewRequest{
+			Function:      fungibleBalanceFunction,
+			TypeArguments: []string{fungibleMetadataType},
+			Arguments:     []interface{}{address, contractAddress},
+		}
+	}
+	result, err := c.aptosClient.View(request)
+	if err != nil {
+		return "", err
+	}
+	if len(result) == 0 {
+		return "", errors.New("empty view result")
+	}
+	var balance string
+	if err := json.Unmarshal(result[0], &balance); err != nil {
+		return "", err
+	}
+	return balance, nil
+}
+
+/**
+ * @description: 估算手续费, 单位为 octa
+ * rawTx 为 base64(json) 的 TxStructure, 带 public_key 时通过 simulate 得到 gas 用量, 否则按转账经验值估算
+ * 三档手续费分别使用 deprioritized/gas_estimate/prioritized 的 gas 价格
+ */
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	estimation, err := c.aptosClient.EstimateGasPrice()
+	if err != nil {
+		log.Error("estimate gas price fail", "err", err)
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "estimate gas price fail",
+		}, nil
+	}
+	gasUnits := uint64(defaultTransferGasUnits)
+	if req.RawTx != "" {
+		txStructure, err := decodeTxStructure(req.RawTx)
+		if err != nil {
+			log.Error("decode tx structure fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "decode tx structure fail",
+			}, nil
+		}
+		if txStructure.PublicKey != "" {
+			_, simulated, err := c.buildRawTransaction(txStructure)
+			if err != nil {
+				log.Error("simulate transaction fail", "err", err)
+				return &account.FeeResponse{
+					Code: common.ReturnCode_ERROR,
+					Msg:  "simulate transaction fail",
+				}, nil
+			}
+			gasUnits = simulated
+		}
+	}
+	fee := func(price uint64) string {
+		return new(big.Int).Mul(new(big.Int).SetUint64(gasUnits), new(big.Int).SetUint64(price)).String()
+	}
+	return &account.FeeResponse{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee(estimation.DeprioritizedGasEstimate),
+		NormalFee: fee(estimation.GasEstimate),
+		FastFee:   fee(estimation.PrioritizedGasEstimate),
+	}, nil
+}
+
+// buildRawTransaction 补全 sequence number, chain id, gas 价格与过期时间, 并通过 simulate 确定 max gas amount
+// 返回交易及模拟得到的 gas 用量
+func (c *ChainAdaptor) buildRawTransaction(txStructure *TxStructure) (*RawTransaction, uint64, error) {
+	publicKey, err := hex.DecodeString(strings.TrimPrefix(txStructure.PublicKey, "0x"))
+	if err != nil || len(publicKey) != ed25519PublicKeyLength {
+		return nil, 0, errors.New("invalid ed25519 public key")
+	}
+	sender, err := ParseAddress(txStructure.FromAddress)
+	if err != nil {
+		return nil, 0, err
+	}
+	to, err := ParseAddress(txStructure.ToAddress)
+	if err != nil {
+		return nil, 0, err
+	}
+	amount, err := strconv.ParseUint(txStructure.Value, 10, 64)
+	if err != nil || amount == 0 {
+		return nil, 0, errors.New("invalid transfer value")
+	}
+	payload, err := NewTransferPayload(txStructure.ContractAddress, to, amount)
+	if err != nil {
+		return nil, 0, err
+	}
+	rawTx := &RawTransaction{
+		Sender:                  sender,
+		Payload:                 payload,
+		MaxGasAmount:            txStructure.MaxGasAmount,
+		GasUnitPrice:            txStructure.GasUnitPrice,
+		ExpirationTimestampSecs: txStructure.ExpirationTimestampSecs,
+		ChainId:                 txStructure.ChainId,
+	}
+	if txStructure.SequenceNumber != "" {
+		if rawTx.SequenceNumber, err = strconv.ParseUint(txStructure.SequenceNumber, 10, 64); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		data, err := c.aptosClient.GetAccount(sender.String())
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, 0, err
+		}
+		if data != nil {
+			if rawTx.SequenceNumber, err = strconv.ParseUint(data.SequenceNumber, 10, 64); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	if rawTx.ChainId == 0 {
+		info, err := c.aptosClient.GetLedgerInfo()
+		if err != nil {
+			return nil, 0, err
+		}
+		rawTx.ChainId = info.ChainId
+	}
+	if rawTx.GasUnitPrice == 0 {
+		estimation, err := c.aptosClient.EstimateGasPrice()
+		if err != nil {
+			return nil, 0, err
+		}
+		rawTx.GasUnitPrice = estimation.GasEstimate
+	}
+	if rawTx.ExpirationTimestampSecs == 0 {
+		rawTx.ExpirationTimestampSecs = uint64(time.Now().Unix()) + defaultExpirationSeconds
+	}
+
+	// 模拟时 max gas amount 取上限, 再按实际用量放大作为最终值
+	userMaxGasAmount := rawTx.MaxGasAmount
+	if userMaxGasAmount == 0 {
+		rawTx.MaxGasAmount = defaultMaxGasAmount
+	}
+	simulated, err := c.aptosClient.SimulateTransaction(rawTx.SignedTransaction(publicKey, make([]byte, ed25519SignatureLength)))
+	if err != nil {
+		return nil, 0, err
+	}
+	if !simulated.Success {
+		return nil, 0, fmt.Errorf("simulate transaction fail: %s", simulated.VmStatus)
+	}
+	gasUsed, err := strconv.ParseUint(simulated.GasUsed, 10, 64)
+	if err != nil {
+		return nil, 0, err
+	}
+	if userMaxGasAmount == 0 {
+		rawTx.MaxGasAmount = gasUsed * gasAmountAdjustmentPercent / 100
+	}
+	return rawTx, gasUsed, nil
+}
+
+// SendTx raw_tx 为 BuildSignedTransaction 返回的 BCS 编码已签名交易 (hex)
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	signedTx, err := hex.DecodeString(strings.TrimPrefix(req.RawTx, "0x"))
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	tx, err := c.aptosClient.SubmitTransaction(signedTx)
+	if err != nil {
+		log.Error("submit transaction fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "submit transaction fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: tx.Hash,
+	}, nil
+}
+
+// GetTxByAddress 返回地址发出的交易, Cursor 为起始 sequence number
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	limit := req.Pagesize
+	if limit == 0 {
+		limit = defaultTransactionPageLimit
+	}
+	txs, err := c.aptosClient.GetAccountTransactions(req.Address, req.Cursor, limit)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		log.Error("get account transactions fail", "err", err)
+		return &account.TxAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by address fail",
+		}, nil
+	}
+	var txList []*account.TxMessage
+	for i := range txs {
+		txMessage := toTxMessage(&txs[i])
+		if req.ContractAddress != "" && txMessage.ContractAddress != req.ContractAddress {
+			continue
+		}
+		txList = append(txList, txMessage)
+	}
+	return &account.TxAddressResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txList,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	tx, err := c.aptosClient.GetTransactionByHash(req.Hash)
+	if errors.Is(err, ErrNotFound) {
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_SUCCESS,
+			Msg:  "tx not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get tx by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}, nil
+	}
+	return &account.TxHashResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by hash success",
+		Tx:   toTxMessage(tx),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, errStart := strconv.ParseUint(req.Start, 10, 64)
+	end, errEnd := strconv.ParseUint(req.End, 10, 64)
+	if errStart != nil || errEnd != nil || start > end {
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid block range",
+		}, nil
+	}
+	var blockList []*account.BlockData
+	for height := start; height <= end; height++ {
+		block, err := c.aptosClient.GetBlockByHeight(height, true)
+		if err != nil {
+			log.Error("get block fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block by range fail",
+			}, nil
+		}
+		var txList []*account.TxMessage
+		for i := range block.Transactions {
+			txList = append(txList, toTxMessage(&block.Transactions[i]))
+		}
+		blockList = append(blockList, &account.BlockData{
+			Hash:         block.BlockHash,
+			Transactions: txList,
+		})
+	}
+	return &account.BlockByRangeResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "get block by range success",
+		Blocks: blockList,
+	}, nil
+}
+
+/**
+ * @description: 创建未签名交易
+ * base64_tx 为 base64(json) 的 TxStructure, public_key 必填
+ * 返回 base64(json) 的 UnsignedTx, 签名对象为 signing_message (hex)
+ */
+func (c *ChainAdaptor) CreateUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txStructure, err := decodeTxStructure(req.Base64Tx)
+	if err != nil {
+		log.Error("decode tx structure fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode tx structure fail",
+		}, nil
+	}
+	rawTx, _, err := c.buildRawTransaction(txStructure)
+	if err != nil {
+		log.Error("build raw transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "build raw transaction fail",
+		}, nil
+	}
+	unsignedTx, _ := json.Marshal(&UnsignedTx{
+		RawTx:          hex.EncodeToString(rawTx.Serialize()),
+		PublicKey:      strings.TrimPrefix(txStructure.PublicKey, "0x"),
+		SigningMessage: hex.EncodeToString(rawTx.SigningMessage()),
+	})
+	return &account.UnSignTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "create un sign transaction success",
+		UnSignTx: base64.StdEncoding.EncodeToString(unsignedTx),
+	}, nil
+}
+
+/**
+ * @description: 组装签名交易
+ * base64_tx 为 CreateUnSignTransaction 返回值, signature 为对 signing_message 的 64 字节 ed25519 签名 (hex)
+ * 返回 BCS 编码的已签名交易 (hex), 可直接用于 SendTx
+ */
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	unsignedJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var unsignedTx UnsignedTx
+	if err := json.Unmarshal(unsignedJson, &unsignedTx); err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "unmarshal unsigned tx fail",
+		}, nil
+	}
+	rawTxBytes, err := hex.DecodeString(unsignedTx.RawTx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	rawTx, err := DeserializeRawTransaction(rawTxBytes)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "deserialize raw tx fail",
+		}, nil
+	}
+	publicKey, err := hex.DecodeString(unsignedTx.PublicKey)
+	if err != nil || len(publicKey) != ed25519PublicKeyLength {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != ed25519SignatureLength {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	if !ed25519.Verify(publicKey, rawTx.SigningMessage(), signature) {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "verify signature fail",
+		}, nil
+	}
+	return &account.SignedTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "build signed transaction success",
+		SignedTx: hex.EncodeToString(rawTx.SignedTransaction(publicKey, signature)),
+	}, nil
+}
+
+// DecodeTransaction raw_tx 为 BCS 编码的已签名交易 (hex), 返回 base64(json) 的 TxStructure
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	signedTx, err := hex.DecodeString(strings.TrimPrefix(req.RawTx, "0x"))
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	rawTx, publicKey, _, err := DeserializeSignedTransaction(signedTx)
+	if err != nil {
+		log.Error("deserialize signed transaction fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "deserialize signed transaction fail",
+		}, nil
+	}
+	txStructure, err := rawTx.DecodeTransfer()
+	if err != nil {
+		log.Error("decode transfer fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode transfer fail",
+		}, nil
+	}
+	txStructure.PublicKey = hex.EncodeToString(publicKey)
+	txJson, _ := json.Marshal(txStructure)
+	return &account.DecodeTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "decode transaction success",
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "aptos does not support verify signed transaction",
+	}, nil
+}
+
+// GetExtraData 返回离线签名所需的 chain_id, sequence_number 与 gas_unit_price (json)
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	info, err := c.aptosClient.GetLedgerInfo()
+	if err != nil {
+		log.Error("get ledger info fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get ledger info fail",
+		}, nil
+	}
+	estimation, err := c.aptosClient.EstimateGasPrice()
+	if err != nil {
+		log.Error("estimate gas price fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "estimate gas price fail",
+		}, nil
+	}
+	sequence := "0"
+	data, err := c.aptosClient.GetAccount(req.Address)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		log.Error("get account fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	if data != nil {
+		sequence = data.SequenceNumber
+	}
+	value, _ := json.Marshal(map[string]string{
+		"chain_id":        strconv.Itoa(int(info.ChainId)),
+		"sequence_number": sequence,
+		"gas_unit_price":  strconv.FormatUint(estimation.GasEstimate, 10),
+	})
+	return &account.ExtraDataResponse{
+		Code:  common.ReturnCode_SUCCESS,
+		Msg:   "get extra data success",
+		Value: string(value),
+	}, nil
+}
+
+func decodeTxStructure(base64Tx string) (*TxStructure, error) {
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var txStructure TxStructure
+	if err := json.Unmarshal(txJson, &txStructure); err != nil {
+		return nil, err
+	}
+	return &txStructure, nil
+}
+
+func toTxMessage(tx *Transaction) *account.TxMessage {
+	txMessage := &account.TxMessage{
+		Hash:     tx.Hash,
+		Height:   tx.Version,
+		Fee:      txFee(tx),
+		Datetime: microsToSeconds(tx.Timestamp),
+	}
+	switch {
+	case tx.Type == pendingTransactionType:
+		txMessage.Status = account.TxStatus_Pending
+	case tx.Success:
+		txMessage.Status = account.TxStatus_Success
+	default:
+		txMessage.Status = account.TxStatus_Failed
+	}
+	if to, amount, contract, ok := parseTransferPayload(&tx.Payload); ok {
+		txMessage.Froms = []*account.Address{{Address: tx.Sender}}
+		txMessage.Tos = []*account.Address{{Address: to}}
+		txMessage.Values = []*account.Value{{Value: amount}}
+		txMessage.ContractAddress = contract
+	}
+	return txMessage
+}
+
+// parseTransferPayload 解析 REST 接口返回的转账 payload, 返回接收方, 金额与代币标识
+func parseTransferPayload(payload *TransactionPayload) (string, string, string, bool) {
+	var args []json.RawMessage
+	var contract string
+	switch payload.Function {
+	case transferFunction:
+		args = payload.Arguments
+	case transferCoinsFunction:
+		args = payload.Arguments
+		if len(payload.TypeArguments) == 1 && payload.TypeArguments[0] != AptosCoinType {
+			contract = payload.TypeArguments[0]
+		}
+	case transferFungibleFunction:
+		if len(payload.Arguments) != 3 {
+			return "", "", "", false
+		}
+		var metadata struct {
+			Inner string `json:"inner"`
+		}
+		if err := json.Unmarshal(payload.Arguments[0], &metadata); err != nil {
+			return "", "", "", false
+		}
+		contract = metadata.Inner
+		args = payload.Arguments[1:]
+	default:
+		return "", "", "", false
+	}
+	if len(args) != 2 {
+		return "", "", "", false
+	}
+	var to, amount string
+	if json.Unmarshal(args[0], &to) != nil || json.Unmarshal(args[1], &amount) != nil {
+		return "", "", "", false
+	}
+	return to, amount, contract, true
+}
+
+func txFee(tx *Transaction) string {
+	gasUsed, ok1 := new(big.Int).SetString(tx.GasUsed, 10)
+	gasPrice, ok2 := new(big.Int).SetString(tx.GasUnitPrice, 10)
+	if !ok1 || !ok2 {
+		return ""
+	}
+	return gasUsed.Mul(gasUsed, gasPrice).String()
+}
+
+// microsToSeconds Aptos 时间戳单位为微秒
+func microsToSeconds(timestamp string) string {
+	micros, err := strconv.ParseUint(timestamp, 10, 64)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatUint(micros/1000000, 10)
+}
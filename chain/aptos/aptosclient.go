@@ -0,0 +1,245 @@
+package aptos
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	defaultRequestTimeout = 10 * time.Second
+
+	bcsSignedTransactionContentType = "application/x.aptos.signed_transaction+bcs"
+
+	pendingTransactionType = "pending_transaction"
+)
+
+var ErrNotFound = errors.New("aptos resource not found")
+
+type LedgerInfo struct {
+	ChainId         uint8  `json:"chain_id"`
+	LedgerVersion   string `json:"ledger_version"`
+	BlockHeight     string `json:"block_height"`
+	LedgerTimestamp string `json:"ledger_timestamp"`
+}
+
+type AccountData struct {
+	SequenceNumber    string `json:"sequence_number"`
+	AuthenticationKey string `json:"authentication_key"`
+}
+
+type GasEstimation struct {
+	DeprioritizedGasEstimate uint64 `json:"deprioritized_gas_estimate"`
+	GasEstimate              uint64 `json:"gas_estimate"`
+	PrioritizedGasEstimate   uint64 `json:"prioritized_gas_estimate"`
+}
+
+type TransactionPayload struct {
+	Type          string            `json:"type"`
+	Function      string            `json:"function"`
+	TypeArguments []string          `json:"type_arguments"`
+	Arguments     []json.RawMessage `json:"arguments"`
+}
+
+// Transaction REST 接口返回的交易, pending 交易没有 version 与执行结果
+type Transaction struct {
+	Type           string             `json:"type"`
+	Hash           string             `json:"hash"`
+	Version        string             `json:"version"`
+	Sender         string             `json:"sender"`
+	SequenceNumber string             `json:"sequence_number"`
+	Success        bool               `json:"success"`
+	VmStatus       string             `json:"vm_status"`
+	GasUsed        string             `json:"gas_used"`
+	GasUnitPrice   string             `json:"gas_unit_price"`
+	MaxGasAmount   string             `json:"max_gas_amount"`
+	Timestamp      string             `json:"timestamp"`
+	Payload        TransactionPayload `json:"payload"`
+}
+
+type Block struct {
+	BlockHeight    string        `json:"block_height"`
+	BlockHash      string        `json:"block_hash"`
+	BlockTimestamp string        `json:"block_timestamp"`
+	FirstVersion   string        `json:"first_version"`
+	LastVersion    string        `json:"last_version"`
+	Transactions   []Transaction `json:"transactions"`
+}
+
+type ViewRequest struct {
+	Function      string        `json:"function"`
+	TypeArguments []string      `json:"type_arguments"`
+	Arguments     []interface{} `json:"arguments"`
+}
+
+type apiError struct {
+	Message   string `json:"message"`
+	ErrorCode string `json:"error_code"`
+}
+
+// AptosClient Aptos 节点 REST 客户端, baseUrl 包含 /v1
+type AptosClient struct {
+	baseUrl    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewAptosClient(baseUrl, apiKey string, timeout time.Duration) *AptosClient {
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &AptosClient{
+		baseUrl:    strings.TrimRight(baseUrl, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *AptosClient) do(method, path, contentType string, body []byte, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, c.baseUrl+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		var apiErr apiError
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("aptos api %s: %s (%s)", path, apiErr.Message, apiErr.ErrorCode)
+		}
+		return fmt.Errorf("aptos api %s status %d: %s", path, resp.StatusCode, string(data))
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		log.Error("unmarshal aptos response fail", "path", path, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (c *AptosClient) get(path string, result interface{}) error {
+	return c.do(http.MethodGet, path, "application/json", nil, result)
+}
+
+func (c *AptosClient) postJson(path string, body interface{}, result interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.do(http.MethodPost, path, "application/json", data, result)
+}
+
+func (c *AptosClient) GetLedgerInfo() (*LedgerInfo, error) {
+	var info LedgerInfo
+	if err := c.get("/", &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetAccount 账户未创建时返回 ErrNotFound
+func (c *AptosClient) GetAccount(address string) (*AccountData, error) {
+	var data AccountData
+	if err := c.get("/accounts/"+address, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (c *AptosClient) View(request *ViewRequest) ([]json.RawMessage, error) {
+	if request.TypeArguments == nil {
+		request.TypeArguments = []string{}
+	}
+	var result []json.RawMessage
+	if err := c.postJson("/view", request, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *AptosClient) EstimateGasPrice() (*GasEstimation, error) {
+	var estimation GasEstimation
+	if err := c.get("/estimate_gas_price", &estimation); err != nil {
+		return nil, err
+	}
+	return &estimation, nil
+}
+
+// SimulateTransaction signedTx 的签名须为全 0, 节点拒绝带有效签名的模拟请求
+func (c *AptosClient) SimulateTransaction(signedTx []byte) (*Transaction, error) {
+	var result []Transaction
+	path := "/transactions/simulate?estimate_gas_unit_price=true&estimate_max_gas_amount=true"
+	if err := c.do(http.MethodPost, path, bcsSignedTransactionContentType, signedTx, &result); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, errors.New("empty simulate result")
+	}
+	return &result[0], nil
+}
+
+func (c *AptosClient) SubmitTransaction(signedTx []byte) (*Transaction, error) {
+	var tx Transaction
+	if err := c.do(http.MethodPost, "/transactions", bcsSignedTransactionContentType, signedTx, &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func (c *AptosClient) GetTransactionByHash(hash string) (*Transaction, error) {
+	var tx Transaction
+	if err := c.get("/transactions/by_hash/"+hash, &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// GetAccountTransactions 返回账户发出的交易, start 为起始 sequence number
+func (c *AptosClient) GetAccountTransactions(address string, start string, limit uint32) ([]Transaction, error) {
+	params := url.Values{}
+	if start != "" {
+		params.Set("start", start)
+	}
+	params.Set("limit", strconv.FormatUint(uint64(limit), 10))
+	var txs []Transaction
+	if err := c.get("/accounts/"+address+"/transactions?"+params.Encode(), &txs); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+func (c *AptosClient) GetBlockByHeight(height uint64, withTransactions bool) (*Block, error) {
+	var block Block
+	path := fmt.Sprintf("/blocks/by_height/%d?with_transactions=%t", height, withTransactions)
+	if err := c.get(path, &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
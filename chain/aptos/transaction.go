@@ -0,0 +1,469 @@
+package aptos
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/CavnHan/wallet-chain-account/common/bcs"
+)
+
+const (
+	AptosCoinType = "0x1::aptos_coin::AptosCoin"
+
+	transferCoinsFunction    = "0x1::aptos_account::transfer_coins"
+	transferFunction         = "0x1::aptos_account::transfer"
+	transferFungibleFunction = "0x1::primary_fungible_store::transfer"
+	fungibleMetadataType     = "0x1::fungible_asset::Metadata"
+
+	rawTransactionSalt = "APTOS::RawTransaction"
+
+	payloadEntryFunction        = 2
+	authenticatorEd25519        = 0
+	typeTagVector               = 6
+	typeTagStruct               = 7
+	ed25519PublicKeyLength      = 32
+	ed25519SignatureLength      = 64
+	maxTypeTagDepth             = 8
+	defaultExpirationSeconds    = 600
+	defaultMaxGasAmount         = 200000
+	gasAmountAdjustmentPercent  = 150
+	defaultTransferGasUnits     = 1000
+	defaultTransactionPageLimit = 25
+)
+
+var typeTagNames = map[uint8]string{
+	0: "bool", 1: "u8", 2: "u64", 3: "u128", 4: "address", 5: "signer", 8: "u16", 9: "u32", 10: "u256",
+}
+
+// TxStructure CreateUnSignTransaction 入参及 DecodeTransaction 出参, 以 base64(json) 传递
+// ContractAddress 为空时转 APT; 含 "::" 时为 coin 类型, 否则为 fungible asset 的 metadata 地址
+// PublicKey 为发送方 ed25519 公钥 (hex), 用于 simulate 与组装签名交易
+type TxStructure struct {
+	FromAddress             string `json:"from_address"`
+	ToAddress               string `json:"to_address"`
+	Value                   string `json:"value"`
+	ContractAddress         string `json:"contract_address,omitempty"`
+	PublicKey               string `json:"public_key,omitempty"`
+	SequenceNumber          string `json:"sequence_number,omitempty"`
+	MaxGasAmount            uint64 `json:"max_gas_amount,omitempty"`
+	GasUnitPrice            uint64 `json:"gas_unit_price,omitempty"`
+	ExpirationTimestampSecs uint64 `json:"expiration_timestamp_secs,omitempty"`
+	ChainId                 uint8  `json:"chain_id,omitempty"`
+}
+
+// UnsignedTx CreateUnSignTransaction 返回值, 签名对象为 SigningMessage
+type UnsignedTx struct {
+	RawTx          string `json:"raw_tx"`
+	PublicKey      string `json:"public_key"`
+	SigningMessage string `json:"signing_message"`
+}
+
+type StructTag struct {
+	Address  Address
+	Module   string
+	Name     string
+	TypeArgs []TypeTag
+}
+
+// TypeTag Struct 为空时为基础类型, Vector 不为空时为 vector<T>
+type TypeTag struct {
+	Primitive uint8
+	Vector    *TypeTag
+	Struct    *StructTag
+}
+
+type EntryFunction struct {
+	ModuleAddress Address
+	ModuleName    string
+	Function      string
+	TypeArgs      []TypeTag
+	Args          [][]byte
+}
+
+type RawTransaction struct {
+	Sender                  Address
+	SequenceNumber          uint64
+	Payload                 EntryFunction
+	MaxGasAmount            uint64
+	GasUnitPrice            uint64
+	ExpirationTimestampSecs uint64
+	ChainId                 uint8
+}
+
+// ParseTypeTag 解析 0x1::coin::Coin<0x1::aptos_coin::AptosCoin> 形式的类型
+func ParseTypeTag(s string) (TypeTag, error) {
+	tag, rest, err := parseTypeTag(strings.ReplaceAll(s, " ", ""), 0)
+	if err != nil {
+		return TypeTag{}, err
+	}
+	if rest != "" {
+		return TypeTag{}, fmt.Errorf("invalid type tag %s", s)
+	}
+	return tag, nil
+}
+
+func parseTypeTag(s string, depth int) (TypeTag, string, error) {
+	if depth > maxTypeTagDepth {
+		return TypeTag{}, "", errors.New("type tag too deep")
+	}
+	end := strings.IndexAny(s, "<>,")
+	if end < 0 {
+		end = len(s)
+	}
+	head := s[:end]
+	for k, name := range typeTagNames {
+		if head == name {
+			return TypeTag{Primitive: k}, s[end:], nil
+		}
+	}
+	if head == "vector" {
+		if end >= len(s) || s[end] != '<' {
+			return TypeTag{}, "", errors.New("invalid vector type tag")
+		}
+		inner, rest, err := parseTypeTag(s[end+1:], depth+1)
+		if err != nil || !strings.HasPrefix(rest, ">") {
+			return TypeTag{}, "", errors.New("invalid vector type tag")
+		}
+		return TypeTag{Vector: &inner}, rest[1:], nil
+	}
+	parts := strings.Split(head, "::")
+	if len(parts) != 3 {
+		return TypeTag{}, "", fmt.Errorf("invalid struct type tag %s", head)
+	}
+	address, err := ParseAddress(parts[0])
+	if err != nil {
+		return TypeTag{}, "", err
+	}
+	tag := &StructTag{Address: address, Module: parts[1], Name: parts[2]}
+	rest := s[end:]
+	if strings.HasPrefix(rest, "<") {
+		rest = rest[1:]
+		for {
+			arg, r, err := parseTypeTag(rest, depth+1)
+			if err != nil {
+				return TypeTag{}, "", err
+			}
+			tag.TypeArgs = append(tag.TypeArgs, arg)
+			if strings.HasPrefix(r, ",") {
+				rest = r[1:]
+				continue
+			}
+			if !strings.HasPrefix(r, ">") {
+				return TypeTag{}, "", errors.New("invalid struct type arguments")
+			}
+			rest = r[1:]
+			break
+		}
+	}
+	return TypeTag{Struct: tag}, rest, nil
+}
+
+func (t TypeTag) String() string {
+	switch {
+	case t.Vector != nil:
+		return "vector<" + t.Vector.String() + ">"
+	case t.Struct != nil:
+		s := t.Struct.Address.String() + "::" + t.Struct.Module + "::" + t.Struct.Name
+		if len(t.Struct.TypeArgs) > 0 {
+			args := make([]string, len(t.Struct.TypeArgs))
+			for i, arg := range t.Struct.TypeArgs {
+				args[i] = arg.String()
+			}
+			s += "<" + strings.Join(args, ", ") + ">"
+		}
+		return s
+	default:
+		return typeTagNames[t.Primitive]
+	}
+}
+
+func (t TypeTag) serialize(w *bcs.Writer) {
+	switch {
+	case t.Vector != nil:
+		w.Uleb128(typeTagVector)
+		t.Vector.serialize(w)
+	case t.Struct != nil:
+		w.Uleb128(typeTagStruct)
+		w.Fixed(t.Struct.Address[:])
+		w.Str(t.Struct.Module)
+		w.Str(t.Struct.Name)
+		w.Uleb128(uint64(len(t.Struct.TypeArgs)))
+		for _, arg := range t.Struct.TypeArgs {
+			arg.serialize(w)
+		}
+	default:
+		w.Uleb128(uint64(t.Primitive))
+	}
+}
+
+func deserializeTypeTag(r *bcs.Reader, depth int) (TypeTag, error) {
+	if depth > maxTypeTagDepth {
+		return TypeTag{}, bcs.ErrInvalidBcs
+	}
+	variant, err := r.Uleb128()
+	if err != nil {
+		return TypeTag{}, err
+	}
+	switch variant {
+	case typeTagVector:
+		inner, err := deserializeTypeTag(r, depth+1)
+		if err != nil {
+			return TypeTag{}, err
+		}
+		return TypeTag{Vector: &inner}, nil
+	case typeTagStruct:
+		tag := &StructTag{}
+		raw, err := r.Fixed(AddressLength)
+		if err != nil {
+			return TypeTag{}, err
+		}
+		copy(tag.Address[:], raw)
+		if tag.Module, err = r.Str(); err != nil {
+			return TypeTag{}, err
+		}
+		if tag.Name, err = r.Str(); err != nil {
+			return TypeTag{}, err
+		}
+		n, err := r.Uleb128()
+		if err != nil {
+			return TypeTag{}, err
+		}
+		for i := uint64(0); i < n; i++ {
+			arg, err := deserializeTypeTag(r, depth+1)
+			if err != nil {
+				return TypeTag{}, err
+			}
+			tag.TypeArgs = append(tag.TypeArgs, arg)
+		}
+		return TypeTag{Struct: tag}, nil
+	default:
+		if _, ok := typeTagNames[uint8(variant)]; !ok {
+			return TypeTag{}, bcs.ErrInvalidBcs
+		}
+		return TypeTag{Primitive: uint8(variant)}, nil
+	}
+}
+
+// NewTransferPayload APT 与 coin 使用 aptos_account::transfer_coins, fungible asset 使用 primary_fungible_store::transfer
+func NewTransferPayload(contractAddress string, to Address, amount uint64) (EntryFunction, error) {
+	amountArg := binary.LittleEndian.AppendUint64(nil, amount)
+	if contractAddress != "" && !strings.Contains(contractAddress, "::") {
+		metadata, err := ParseAddress(contractAddress)
+		if err != nil {
+			return EntryFunction{}, err
+		}
+		metadataType, _ := ParseTypeTag(fungibleMetadataType)
+		return EntryFunction{
+			ModuleAddress: Address{AddressLength - 1: 1},
+			ModuleName:    "primary_fungible_store",
+			Function:      "transfer",
+			TypeArgs:      []TypeTag{metadataType},
+			Args:          [][]byte{metadata[:], to[:], amountArg},
+		}, nil
+	}
+	coinType := contractAddress
+	if coinType == "" {
+		coinType = AptosCoinType
+	}
+	typeTag, err := ParseTypeTag(coinType)
+	if err != nil {
+		return EntryFunction{}, err
+	}
+	if typeTag.Struct == nil {
+		return EntryFunction{}, fmt.Errorf("invalid coin type %s", coinType)
+	}
+	return EntryFunction{
+		ModuleAddress: Address{AddressLength - 1: 1},
+		ModuleName:    "aptos_account",
+		Function:      "transfer_coins",
+		TypeArgs:      []TypeTag{typeTag},
+		Args:          [][]byte{to[:], amountArg},
+	}, nil
+}
+
+func (f *EntryFunction) FunctionId() string {
+	return f.ModuleAddress.String() + "::" + f.ModuleName + "::" + f.Function
+}
+
+func (tx *RawTransaction) Serialize() []byte {
+	w := &bcs.Writer{}
+	w.Fixed(tx.Sender[:])
+	w.U64(tx.SequenceNumber)
+	w.Uleb128(payloadEntryFunction)
+	w.Fixed(tx.Payload.ModuleAddress[:])
+	w.Str(tx.Payload.ModuleName)
+	w.Str(tx.Payload.Function)
+	w.Uleb128(uint64(len(tx.Payload.TypeArgs)))
+	for _, tag := range tx.Payload.TypeArgs {
+		tag.serialize(w)
+	}
+	w.Uleb128(uint64(len(tx.Payload.Args)))
+	for _, arg := range tx.Payload.Args {
+		w.Vec(arg)
+	}
+	w.U64(tx.MaxGasAmount)
+	w.U64(tx.GasUnitPrice)
+	w.U64(tx.ExpirationTimestampSecs)
+	w.U8(tx.ChainId)
+	return w.Bytes()
+}
+
+// SigningMessage sha3-256("APTOS::RawTransaction") || bcs(RawTransaction)
+func (tx *RawTransaction) SigningMessage() []byte {
+	salt := sha3.Sum256([]byte(rawTransactionSalt))
+	return append(salt[:], tx.Serialize()...)
+}
+
+// SignedTransaction bcs(RawTransaction) || Ed25519 认证器
+func (tx *RawTransaction) SignedTransaction(publicKey, signature []byte) []byte {
+	w := bcs.NewWriter(tx.Serialize())
+	w.Uleb128(authenticatorEd25519)
+	w.Vec(publicKey)
+	w.Vec(signature)
+	return w.Bytes()
+}
+
+func deserializeRawTransaction(r *bcs.Reader) (*RawTransaction, error) {
+	tx := &RawTransaction{}
+	raw, err := r.Fixed(AddressLength)
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.Sender[:], raw)
+	if tx.SequenceNumber, err = r.U64(); err != nil {
+		return nil, err
+	}
+	variant, err := r.Uleb128()
+	if err != nil {
+		return nil, err
+	}
+	if variant != payloadEntryFunction {
+		return nil, errors.New("only entry function payload is supported")
+	}
+	if raw, err = r.Fixed(AddressLength); err != nil {
+		return nil, err
+	}
+	copy(tx.Payload.ModuleAddress[:], raw)
+	if tx.Payload.ModuleName, err = r.Str(); err != nil {
+		return nil, err
+	}
+	if tx.Payload.Function, err = r.Str(); err != nil {
+		return nil, err
+	}
+	n, err := r.Uleb128()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < n; i++ {
+		tag, err := deserializeTypeTag(r, 0)
+		if err != nil {
+			return nil, err
+		}
+		tx.Payload.TypeArgs = append(tx.Payload.TypeArgs, tag)
+	}
+	if n, err = r.Uleb128(); err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < n; i++ {
+		arg, err := r.Vec()
+		if err != nil {
+			return nil, err
+		}
+		tx.Payload.Args = append(tx.Payload.Args, arg)
+	}
+	if tx.MaxGasAmount, err = r.U64(); err != nil {
+		return nil, err
+	}
+	if tx.GasUnitPrice, err = r.U64(); err != nil {
+		return nil, err
+	}
+	if tx.ExpirationTimestampSecs, err = r.U64(); err != nil {
+		return nil, err
+	}
+	if tx.ChainId, err = r.U8(); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func DeserializeRawTransaction(data []byte) (*RawTransaction, error) {
+	r := bcs.NewReader(data)
+	tx, err := deserializeRawTransaction(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Remaining()) != 0 {
+		return nil, bcs.ErrInvalidBcs
+	}
+	return tx, nil
+}
+
+// DeserializeSignedTransaction 只支持单签 ed25519 认证器
+func DeserializeSignedTransaction(data []byte) (*RawTransaction, []byte, []byte, error) {
+	r := bcs.NewReader(data)
+	tx, err := deserializeRawTransaction(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	variant, err := r.Uleb128()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if variant != authenticatorEd25519 {
+		return nil, nil, nil, errors.New("only ed25519 authenticator is supported")
+	}
+	publicKey, err := r.Vec()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	signature, err := r.Vec()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return tx, publicKey, signature, nil
+}
+
+// DecodeTransfer 从 transfer/transfer_coins/primary_fungible_store::transfer 中解析转账
+func (tx *RawTransaction) DecodeTransfer() (*TxStructure, error) {
+	txStructure := &TxStructure{
+		FromAddress:             tx.Sender.String(),
+		SequenceNumber:          strconv.FormatUint(tx.SequenceNumber, 10),
+		MaxGasAmount:            tx.MaxGasAmount,
+		GasUnitPrice:            tx.GasUnitPrice,
+		ExpirationTimestampSecs: tx.ExpirationTimestampSecs,
+		ChainId:                 tx.ChainId,
+	}
+	args := tx.Payload.Args
+	switch tx.Payload.FunctionId() {
+	case transferFunction, transferCoinsFunction:
+		if len(args) != 2 || len(args[0]) != AddressLength || len(args[1]) != 8 {
+			return nil, bcs.ErrInvalidBcs
+		}
+		if len(tx.Payload.TypeArgs) == 1 {
+			if coinType := tx.Payload.TypeArgs[0].String(); coinType != AptosCoinType {
+				txStructure.ContractAddress = coinType
+			}
+		}
+	case transferFungibleFunction:
+		if len(args) != 3 || len(args[0]) != AddressLength || len(args[1]) != AddressLength || len(args[2]) != 8 {
+			return nil, bcs.ErrInvalidBcs
+		}
+		var metadata Address
+		copy(metadata[:], args[0])
+		txStructure.ContractAddress = metadata.String()
+		args = args[1:]
+	default:
+		return nil, fmt.Errorf("unsupported function %s", tx.Payload.FunctionId())
+	}
+	var to Address
+	copy(to[:], args[0])
+	amount := binary.LittleEndian.Uint64(args[1])
+	txStructure.ToAddress = to.String()
+	txStructure.Value = strconv.FormatUint(amount, 10)
+	return txStructure, nil
+}
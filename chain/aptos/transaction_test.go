@@ -0,0 +1,149 @@
+package aptos
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// 发送方公钥为 0x00..0x1f, sequence 42, max gas 2000, gas price 100, 过期时间 1700000600, 主网
+const (
+	testPublicKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	testSender    = "0xa48b46cfc7b26c4da6d5dd176a84104dabdf394eda11e71880c0c6f42ba43bc3"
+	testTo        = "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	// 主网 USDC 的 fungible asset metadata 地址
+	testMetadata = "0xbae207659db88bea0cbead6da0ed00aac12edcdda169e591cd41c94180b46f3b"
+
+	// sha3-256("APTOS::RawTransaction")
+	rawTransactionSaltHash = "b5e97db07fa0bd0e5598aa3643a9bc6f6693bddc1a9fec9e674a461eaa00b193"
+
+	// aptos_account::transfer_coins<AptosCoin>, 1.5 APT
+	aptTransferTx = "a48b46cfc7b26c4da6d5dd176a84104dabdf394eda11e71880c0c6f42ba43bc32a000000000000000200000000000000000000000000000000000000000000000000000000000000010d6170746f735f6163636f756e740e7472616e736665725f636f696e73010700000000000000000000000000000000000000000000000000000000000000010a6170746f735f636f696e094170746f73436f696e000220aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa0880d1f00800000000d007000000000000640000000000000058f353650000000001"
+	// primary_fungible_store::transfer<Metadata>, 2.5 USDC
+	faTransferTx = "a48b46cfc7b26c4da6d5dd176a84104dabdf394eda11e71880c0c6f42ba43bc32a00000000000000020000000000000000000000000000000000000000000000000000000000000001167072696d6172795f66756e6769626c655f73746f7265087472616e73666572010700000000000000000000000000000000000000000000000000000000000000010e66756e6769626c655f6173736574084d65746164617461000320bae207659db88bea0cbead6da0ed00aac12edcdda169e591cd41c94180b46f3b20aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa08a025260000000000d007000000000000640000000000000058f353650000000001"
+)
+
+func testRawTransaction(t *testing.T, contractAddress string, amount uint64) *RawTransaction {
+	t.Helper()
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	to, _ := ParseAddress(testTo)
+	payload, err := NewTransferPayload(contractAddress, to, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &RawTransaction{
+		Sender:                  AddressFromPublicKey(publicKey),
+		SequenceNumber:          42,
+		Payload:                 payload,
+		MaxGasAmount:            2000,
+		GasUnitPrice:            100,
+		ExpirationTimestampSecs: 1700000600,
+		ChainId:                 1,
+	}
+}
+
+func TestRawTransactionSerialize(t *testing.T) {
+	tests := []struct {
+		name            string
+		contractAddress string
+		amount          uint64
+		want            string
+		wantTransfer    TxStructure
+	}{
+		{"apt", "", 150_000_000, aptTransferTx, TxStructure{Value: "150000000"}},
+		{"fungible asset", testMetadata, 2_500_000, faTransferTx, TxStructure{Value: "2500000", ContractAddress: testMetadata}},
+	}
+	for _, tt := range tests {
+		tx := testRawTransaction(t, tt.contractAddress, tt.amount)
+		if got := hex.EncodeToString(tx.Serialize()); got != tt.want {
+			t.Errorf("%s: raw tx = %s, want %s", tt.name, got, tt.want)
+		}
+		if got := hex.EncodeToString(tx.SigningMessage()); got != rawTransactionSaltHash+tt.want {
+			t.Errorf("%s: signing message = %s", tt.name, got)
+		}
+
+		raw, _ := hex.DecodeString(tt.want)
+		decoded, err := DeserializeRawTransaction(raw)
+		if err != nil {
+			t.Errorf("%s: deserialize: %v", tt.name, err)
+			continue
+		}
+		transfer, err := decoded.DecodeTransfer()
+		if err != nil {
+			t.Errorf("%s: decode transfer: %v", tt.name, err)
+			continue
+		}
+		want := tt.wantTransfer
+		want.FromAddress, want.ToAddress, want.SequenceNumber = testSender, testTo, "42"
+		want.MaxGasAmount, want.GasUnitPrice, want.ExpirationTimestampSecs, want.ChainId = 2000, 100, 1700000600, 1
+		if *transfer != want {
+			t.Errorf("%s: transfer = %+v, want %+v", tt.name, *transfer, want)
+		}
+	}
+}
+
+func TestSignedTransaction(t *testing.T) {
+	tx := testRawTransaction(t, "", 150_000_000)
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	signature := make([]byte, ed25519SignatureLength)
+	signature[0] = 0xcc
+	signed := tx.SignedTransaction(publicKey, signature)
+	// 认证器: variant 0, 公钥长度 32, 签名长度 64
+	if want := aptTransferTx + "0020" + testPublicKey + "40" + hex.EncodeToString(signature); hex.EncodeToString(signed) != want {
+		t.Fatalf("signed tx = %x, want %s", signed, want)
+	}
+	decoded, gotKey, gotSig, err := DeserializeSignedTransaction(signed)
+	if err != nil || hex.EncodeToString(decoded.Serialize()) != aptTransferTx ||
+		hex.EncodeToString(gotKey) != testPublicKey || gotSig[0] != 0xcc {
+		t.Errorf("deserialize = %x, %x, %v", gotKey, gotSig, err)
+	}
+	// 多签认证器
+	multi := append(tx.Serialize(), 0x01)
+	if _, _, _, err := DeserializeSignedTransaction(multi); err == nil {
+		t.Error("multi-ed25519 authenticator accepted")
+	}
+}
+
+func TestDeserializeRawTransactionInvalid(t *testing.T) {
+	raw, _ := hex.DecodeString(aptTransferTx)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated", raw[:len(raw)-1]},
+		{"trailing bytes", append(append([]byte{}, raw...), 0)},
+		// payload variant 改为 script (0)
+		{"script payload", append(append(append([]byte{}, raw[:40]...), 0), raw[41:]...)},
+	}
+	for _, tt := range tests {
+		if _, err := DeserializeRawTransaction(tt.data); err == nil {
+			t.Errorf("%s: decoded", tt.name)
+		}
+	}
+}
+
+func TestParseTypeTag(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{AptosCoinType, AptosCoinType, false},
+		{"0x1::coin::CoinStore<0x1::aptos_coin::AptosCoin>", "0x1::coin::CoinStore<0x1::aptos_coin::AptosCoin>", false},
+		{"vector<u8>", "vector<u8>", false},
+		{"0x01::pair::Pair<u64, vector<address>>", "0x1::pair::Pair<u64, vector<address>>", false},
+		{"0x1::coin", "", true},
+		{"vector<u8", "", true},
+		{strings.Repeat("vector<", 10) + "u8" + strings.Repeat(">", 10), "", true},
+	}
+	for _, tt := range tests {
+		tag, err := ParseTypeTag(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && tag.String() != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.in, tag, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package arweave
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+const (
+	addressLength  = 32
+	modulusLength  = 512
+	publicExponent = 65537
+)
+
+var errInvalidAddress = errors.New("invalid arweave address")
+
+// AddressFromModulus base64url(sha256(n))
+func AddressFromModulus(modulus []byte) string {
+	hash := sha256.Sum256(modulus)
+	return b64Encode(hash[:])
+}
+
+// ValidateAddress 地址为 32 字节哈希的 base64url (无填充) 编码, 共 43 个字符
+func ValidateAddress(address string) error {
+	raw, err := b64Decode(address)
+	if err != nil || len(raw) != addressLength {
+		return errInvalidAddress
+	}
+	return nil
+}
+
+/**
+ * @description: 从公钥中取出 RSA 模数
+ * 支持 JWK (json, 使用 n 字段) 与 512 字节的原始模数
+ */
+func ModulusFromPublicKey(publicKey []byte) ([]byte, error) {
+	modulus := publicKey
+	if len(publicKey) > 0 && publicKey[0] == '{' {
+		var jwk struct {
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		}
+		if err := json.Unmarshal(publicKey, &jwk); err != nil {
+			return nil, err
+		}
+		if jwk.Kty != "RSA" || (jwk.E != "" && jwk.E != "AQAB") {
+			return nil, errors.New("jwk is not an arweave rsa key")
+		}
+		var err error
+		if modulus, err = b64Decode(jwk.N); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := publicKeyFromModulus(modulus); err != nil {
+		return nil, err
+	}
+	return modulus, nil
+}
+
+// publicKeyFromModulus Arweave 钱包为 4096 位 RSA 密钥
+func publicKeyFromModulus(modulus []byte) (*rsa.PublicKey, error) {
+	if len(modulus) != modulusLength || modulus[0]&0x80 == 0 {
+		return nil, errors.New("invalid rsa modulus, expect 4096 bits")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: publicExponent}, nil
+}
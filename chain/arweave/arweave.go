@@ -1 +1,772 @@
 package arweave
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	ChainName = "Arweave"
+
+	defaultTxPageSize = 20
+	// 按地址查询时合并发出与收到的交易, 需从头拉取到目标页, 限制可查询的深度
+	maxTxAddressDepth = 1000
+	maxBlockTxPages   = 20
+)
+
+type ChainAdaptor struct {
+	arweaveClient *ArweaveClient
+}
+
+func init() {
+	chain.Register(ChainName, NewChainAdaptor)
+}
+
+func NewChainAdaptor(conf *config.Config) (chain.IChainAdaptor, error) {
+	node, ok := conf.Chain(ChainName)
+	if !ok {
+		return nil, errors.New("arweave rpc not configured")
+	}
+	return &ChainAdaptor{
+		arweaveClient: NewArweaveClient(node.RPCs[0].RPCURL, node.TimeOut.Duration()),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// ConvertAddress public_key 为 JWK (json) 或 512 字节 RSA 模数, 地址为 base64url(sha256(n))
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	modulus, err := ModulusFromPublicKey(req.PublicKey)
+	if err != nil {
+		log.Error("parse rsa public key fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: AddressFromModulus(modulus),
+	}, nil
+}
+
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if err := ValidateAddress(req.Address); err != nil {
+		return &account.ValidAddressResponse{
+			Code:  common.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:             common.ReturnCode_SUCCESS,
+		Msg:              "valid address",
+		Valid:            true,
+		CanWithdrawal:    true,
+		CanonicalAddress: req.Address,
+	}, nil
+}
+
+// GetBlockByNumber height 为 0 时返回最新区块, view_tx 为 true 时通过 GraphQL 查询区块内交易详情
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	height := uint64(req.Height)
+	if height == 0 {
+		info, err := c.arweaveClient.GetInfo()
+		if err != nil {
+			log.Error("get network info fail", "err", err)
+			return &account.BlockResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get network info fail",
+			}, nil
+		}
+		height = info.Height
+	}
+	block, err := c.arweaveClient.GetBlockByHeight(height)
+	if err != nil {
+		log.Error("get block by height fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return c.toBlockResponse(block, req.ViewTx)
+}
+
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	block, err := c.arweaveClient.GetBlockByHash(req.Hash)
+	if err != nil {
+		log.Error("get block by hash fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by hash fail",
+		}, nil
+	}
+	return c.toBlockResponse(block, req.ViewTx)
+}
+
+func (c *ChainAdaptor) toBlockResponse(block *Block, viewTx bool) (*account.BlockResponse, error) {
+	var txList []*account.BlockInfoTransactionList
+	if viewTx {
+		txs, err := c.getBlockTransactions(block.Height, block.Height)
+		if err != nil {
+			log.Error("get block transactions fail", "err", err)
+			return &account.BlockResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block transactions fail",
+			}, nil
+		}
+		for i := range txs {
+			tx := &txs[i]
+			txList = append(txList, &account.BlockInfoTransactionList{
+				From:   tx.Owner.Address,
+				To:     tx.Recipient,
+				Hash:   tx.Id,
+				Time:   strconv.FormatUint(block.Timestamp, 10),
+				Amount: tx.Quantity.Winston,
+				Fee:    tx.Fee.Winston,
+				Status: account.TxStatus_Success.String(),
+			})
+		}
+	} else {
+		for _, id := range block.Txs {
+			txList = append(txList, &account.BlockInfoTransactionList{Hash: id})
+		}
+	}
+	return &account.BlockResponse{
+		Code:         common.ReturnCode_SUCCESS,
+		Msg:          "get block success",
+		Height:       int64(block.Height),
+		Hash:         block.IndepHash,
+		Transactions: txList,
+	}, nil
+}
+
+// getBlockTransactions 区块范围内的全部交易, 按高度升序
+func (c *ChainAdaptor) getBlockTransactions(minHeight, maxHeight uint64) ([]GraphqlTransaction, error) {
+	filter := &TransactionFilter{MinHeight: minHeight, MaxHeight: maxHeight, First: maxGraphqlPageSize, Ascending: true}
+	var txs []GraphqlTransaction
+	for page := 0; page < maxBlockTxPages; page++ {
+		result, err := c.arweaveClient.QueryTransactions(filter)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, result.Transactions...)
+		if !result.HasNextPage || len(result.Cursors) == 0 {
+			return txs, nil
+		}
+		filter.After = result.Cursors[len(result.Cursors)-1]
+	}
+	return nil, errors.New("too many transactions in block range")
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	block, err := c.arweaveClient.GetBlockByHash(req.Hash)
+	if err != nil {
+		log.Error("get block by hash fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return toBlockHeaderResponse(block), nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	height := uint64(req.Height)
+	if height == 0 {
+		info, err := c.arweaveClient.GetInfo()
+		if err != nil {
+			log.Error("get network info fail", "err", err)
+			return &account.BlockHeaderResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get network info fail",
+			}, nil
+		}
+		height = info.Height
+	}
+	block, err := c.arweaveClient.GetBlockByHeight(height)
+	if err != nil {
+		log.Error("get block by height fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return toBlockHeaderResponse(block), nil
+}
+
+func toBlockHeaderResponse(block *Block) *account.BlockHeaderResponse {
+	return &account.BlockHeaderResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get block header success",
+		BlockHeader: &account.BlockHeader{
+			ParentHash: block.PreviousBlock,
+			Number:     strconv.FormatUint(block.Height, 10),
+			Time:       block.Timestamp,
+		},
+	}
+}
+
+// GetAccount 余额单位为 winston, Arweave 没有账户序号与代币
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	if req.ContractAddress != "" {
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "arweave does not support token",
+			Balance: "0",
+		}, nil
+	}
+	balance, err := c.arweaveClient.GetBalance(req.Address)
+	if err != nil {
+		log.Error("get balance by address fail", "err", err)
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "get balance by address fail",
+			Balance: "0",
+		}, nil
+	}
+	return &account.AccountResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Network:       ChainName,
+		Balance:       balance,
+	}, nil
+}
+
+/**
+ * @description: 估算手续费, 单位为 winston
+ * 费用由数据大小与目标地址决定 (/price/{bytes}/{target}), 向新地址转账时包含额外费用
+ * rawTx 为 base64(json) 的 TxStructure 时按其数据与目标地址查询, 否则按不带数据的转账查询; 没有优先级, 三档手续费相同
+ */
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	dataSize, target := 0, ""
+	if req.RawTx != "" {
+		txStructure, err := decodeTxStructure(req.RawTx)
+		if err != nil {
+			log.Error("decode tx structure fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "decode tx structure fail",
+			}, nil
+		}
+		data, err := b64Decode(txStructure.Data)
+		if err != nil {
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "invalid data",
+			}, nil
+		}
+		dataSize, target = len(data), txStructure.ToAddress
+	}
+	fee, err := c.arweaveClient.GetPrice(dataSize, target)
+	if err != nil {
+		log.Error("get price fail", "err", err)
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get price fail",
+		}, nil
+	}
+	return &account.FeeResponse{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee,
+		NormalFee: fee,
+		FastFee:   fee,
+	}, nil
+}
+
+// SendTx raw_tx 为 BuildSignedTransaction 返回的 base64(json) 签名交易, tx_hash 为交易 id
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	tx, err := decodeTransaction(req.RawTx)
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	if err := tx.Verify(); err != nil {
+		log.Error("verify transaction fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "verify transaction fail",
+		}, nil
+	}
+	if err := c.arweaveClient.SubmitTransaction(tx); err != nil {
+		log.Error("submit transaction fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "submit transaction fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: tx.Id,
+	}, nil
+}
+
+/**
+ * @description: 查询地址发出与收到的交易, 按 page/pagesize 分页, 待打包交易排在最前
+ * GraphQL 不支持 owners 与 recipients 的或查询, 两者分别拉取后合并, 可查询深度为 1000 笔
+ */
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	pageSize := req.Pagesize
+	if pageSize == 0 {
+		pageSize = defaultTxPageSize
+	}
+	page := req.Page
+	if page == 0 {
+		page = 1
+	}
+	depth := page * pageSize
+	if depth > maxTxAddressDepth {
+		return &account.TxAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "page exceeds query depth",
+		}, nil
+	}
+	seen := make(map[string]bool)
+	var txs []GraphqlTransaction
+	for _, filter := range []*TransactionFilter{
+		{Owners: []string{req.Address}},
+		{Recipients: []string{req.Address}},
+	} {
+		result, err := c.queryTransactions(filter, depth)
+		if err != nil {
+			log.Error("query transactions fail", "err", err)
+			return &account.TxAddressResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get tx by address fail",
+			}, nil
+		}
+		for _, tx := range result {
+			if !seen[tx.Id] {
+				seen[tx.Id] = true
+				txs = append(txs, tx)
+			}
+		}
+	}
+	sort.SliceStable(txs, func(i, j int) bool {
+		if txs[i].Block == nil || txs[j].Block == nil {
+			return txs[i].Block == nil && txs[j].Block != nil
+		}
+		return txs[i].Block.Height > txs[j].Block.Height
+	})
+	var txList []*account.TxMessage
+	for i := (page - 1) * pageSize; i < depth && int(i) < len(txs); i++ {
+		txList = append(txList, toTxMessage(&txs[i]))
+	}
+	return &account.TxAddressResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txList,
+	}, nil
+}
+
+func (c *ChainAdaptor) queryTransactions(filter *TransactionFilter, limit uint32) ([]GraphqlTransaction, error) {
+	var txs []GraphqlTransaction
+	for uint32(len(txs)) < limit {
+		filter.First = limit - uint32(len(txs))
+		result, err := c.arweaveClient.QueryTransactions(filter)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, result.Transactions...)
+		if !result.HasNextPage || len(result.Cursors) == 0 {
+			break
+		}
+		filter.After = result.Cursors[len(result.Cursors)-1]
+	}
+	return txs, nil
+}
+
+// GetTxByHash 交易已提交未打包时为 Pending, Arweave 交易打包后不会执行失败
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	tx, err := c.arweaveClient.GetTransaction(req.Hash)
+	if errors.Is(err, ErrNotFound) {
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_SUCCESS,
+			Msg:  "tx not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if errors.Is(err, ErrPending) {
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_SUCCESS,
+			Msg:  "tx pending",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_Pending},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get transaction fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}, nil
+	}
+	txMessage := &account.TxMessage{
+		Hash:   tx.Id,
+		Fee:    tx.Reward,
+		Status: account.TxStatus_Pending,
+	}
+	if modulus, err := b64Decode(tx.Owner); err == nil {
+		txMessage.Froms = []*account.Address{{Address: AddressFromModulus(modulus)}}
+	}
+	if tx.Target != "" {
+		txMessage.Tos = []*account.Address{{Address: tx.Target}}
+		txMessage.Values = []*account.Value{{Value: tx.Quantity}}
+	}
+	status, err := c.arweaveClient.GetTransactionStatus(req.Hash)
+	if err != nil && !errors.Is(err, ErrPending) && !errors.Is(err, ErrNotFound) {
+		log.Error("get transaction status fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get transaction status fail",
+		}, nil
+	}
+	if err == nil {
+		txMessage.Status = account.TxStatus_Success
+		txMessage.Height = strconv.FormatUint(status.BlockHeight, 10)
+	}
+	return &account.TxHashResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by hash success",
+		Tx:   txMessage,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, errStart := strconv.ParseUint(req.Start, 10, 64)
+	end, errEnd := strconv.ParseUint(req.End, 10, 64)
+	if errStart != nil || errEnd != nil || start > end {
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid block range",
+		}, nil
+	}
+	txs, err := c.getBlockTransactions(start, end)
+	if err != nil {
+		log.Error("get block transactions fail", "err", err)
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by range fail",
+		}, nil
+	}
+	var blockList []*account.BlockData
+	for height := start; height <= end; height++ {
+		block, err := c.arweaveClient.GetBlockByHeight(height)
+		if err != nil {
+			log.Error("get block by height fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block by range fail",
+			}, nil
+		}
+		var txList []*account.TxMessage
+		for i := range txs {
+			if txs[i].Block != nil && txs[i].Block.Height == height {
+				txList = append(txList, toTxMessage(&txs[i]))
+			}
+		}
+		blockList = append(blockList, &account.BlockData{
+			Hash:         block.IndepHash,
+			Transactions: txList,
+		})
+	}
+	return &account.BlockByRangeResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "get block by range success",
+		Blocks: blockList,
+	}, nil
+}
+
+/**
+ * @description: 创建未签名交易
+ * base64_tx 为 base64(json) 的 TxStructure, to_address 为空时为纯数据交易, data 为 base64url 编码
+ * 返回 base64(json) 的 UnsignedTx, 签名对象为 signing_message (hex), 即各字段的 deep hash
+ */
+func (c *ChainAdaptor) CreateUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txStructure, err := decodeTxStructure(req.Base64Tx)
+	if err != nil {
+		log.Error("decode tx structure fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode tx structure fail",
+		}, nil
+	}
+	tx, err := c.buildTransaction(txStructure)
+	if err != nil {
+		log.Error("build transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "build transaction fail",
+		}, nil
+	}
+	message, err := tx.SignatureData()
+	if err != nil {
+		log.Error("get signature data fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get signature data fail",
+		}, nil
+	}
+	unsignedTx, _ := json.Marshal(&UnsignedTx{
+		Transaction:    tx,
+		SigningMessage: hex.EncodeToString(message),
+	})
+	return &account.UnSignTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "create un sign transaction success",
+		UnSignTx: base64.StdEncoding.EncodeToString(unsignedTx),
+	}, nil
+}
+
+func (c *ChainAdaptor) buildTransaction(txStructure *TxStructure) (*Transaction, error) {
+	modulus, err := b64Decode(txStructure.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := publicKeyFromModulus(modulus); err != nil {
+		return nil, err
+	}
+	if AddressFromModulus(modulus) != txStructure.FromAddress {
+		return nil, errors.New("public key does not match from address")
+	}
+	quantity := "0"
+	if txStructure.ToAddress != "" {
+		if err := ValidateAddress(txStructure.ToAddress); err != nil {
+			return nil, err
+		}
+		value, ok := new(big.Int).SetString(txStructure.Value, 10)
+		if !ok || value.Sign() <= 0 {
+			return nil, errors.New("invalid transfer value")
+		}
+		quantity = value.String()
+	}
+	data, err := b64Decode(txStructure.Data)
+	if err != nil {
+		return nil, err
+	}
+	if txStructure.ToAddress == "" && len(data) == 0 {
+		return nil, errors.New("transaction has neither target nor data")
+	}
+	reward := txStructure.Reward
+	if reward == "" {
+		if reward, err = c.arweaveClient.GetPrice(len(data), txStructure.ToAddress); err != nil {
+			return nil, err
+		}
+	}
+	lastTx := txStructure.LastTx
+	if lastTx == "" {
+		if lastTx, err = c.arweaveClient.GetTxAnchor(); err != nil {
+			return nil, err
+		}
+	}
+	return NewTransaction(modulus, txStructure.ToAddress, quantity, data, txStructure.Tags, reward, lastTx)
+}
+
+/**
+ * @description: 组装签名交易
+ * base64_tx 为 CreateUnSignTransaction 返回值, signature 为对 signing_message 的 RSA-PSS (SHA-256) 签名 (hex, 512 字节)
+ * 返回 base64(json) 的签名交易, 可直接用于 SendTx
+ */
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	unsignedJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var unsignedTx UnsignedTx
+	if err := json.Unmarshal(unsignedJson, &unsignedTx); err != nil || unsignedTx.Transaction == nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "unmarshal unsigned tx fail",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != modulusLength {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	tx := unsignedTx.Transaction
+	tx.SetSignature(signature)
+	if err := tx.Verify(); err != nil {
+		log.Error("verify signature fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "verify signature fail",
+		}, nil
+	}
+	txJson, _ := json.Marshal(tx)
+	return &account.SignedTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "build signed transaction success",
+		SignedTx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+// DecodeTransaction raw_tx 为 base64(json) 的交易, 支持转账与数据交易, tags 解码为明文
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	tx, err := decodeTransaction(req.RawTx)
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	modulus, err := b64Decode(tx.Owner)
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid owner",
+		}, nil
+	}
+	txStructure := &TxStructure{
+		FromAddress: AddressFromModulus(modulus),
+		ToAddress:   tx.Target,
+		Value:       tx.Quantity,
+		PublicKey:   tx.Owner,
+		Data:        tx.Data,
+		Reward:      tx.Reward,
+		LastTx:      tx.LastTx,
+		DataSize:    tx.DataSize,
+	}
+	for _, tag := range tx.Tags {
+		name, errName := b64Decode(tag.Name)
+		value, errValue := b64Decode(tag.Value)
+		if errName != nil || errValue != nil {
+			return &account.DecodeTransactionResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "invalid tag",
+			}, nil
+		}
+		txStructure.Tags = append(txStructure.Tags, TagPair{Name: string(name), Value: string(value)})
+	}
+	if txStructure.ValueAr, err = WinstonToAr(tx.Quantity); err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid quantity",
+		}, nil
+	}
+	if txStructure.RewardAr, err = WinstonToAr(tx.Reward); err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid reward",
+		}, nil
+	}
+	txJson, _ := json.Marshal(txStructure)
+	return &account.DecodeTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "decode transaction success",
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "arweave does not support verify signed transaction",
+	}, nil
+}
+
+// GetExtraData 返回离线构建交易所需的 tx_anchor 与不带数据转账的 price (json)
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	anchor, err := c.arweaveClient.GetTxAnchor()
+	if err != nil {
+		log.Error("get tx anchor fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx anchor fail",
+		}, nil
+	}
+	price, err := c.arweaveClient.GetPrice(0, req.Address)
+	if err != nil {
+		log.Error("get price fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get price fail",
+		}, nil
+	}
+	value, _ := json.Marshal(map[string]string{
+		"tx_anchor": anchor,
+		"price":     price,
+	})
+	return &account.ExtraDataResponse{
+		Code:  common.ReturnCode_SUCCESS,
+		Msg:   "get extra data success",
+		Value: string(value),
+	}, nil
+}
+
+func decodeTxStructure(base64Tx string) (*TxStructure, error) {
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var txStructure TxStructure
+	if err := json.Unmarshal(txJson, &txStructure); err != nil {
+		return nil, err
+	}
+	return &txStructure, nil
+}
+
+func decodeTransaction(base64Tx string) (*Transaction, error) {
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var tx Transaction
+	if err := json.Unmarshal(txJson, &tx); err != nil {
+		return nil, err
+	}
+	if tx.Format != transactionFormat {
+		return nil, errInvalidTransaction
+	}
+	return &tx, nil
+}
+
+// toTxMessage 纯数据交易没有接收方与金额
+func toTxMessage(tx *GraphqlTransaction) *account.TxMessage {
+	txMessage := &account.TxMessage{
+		Hash:   tx.Id,
+		Froms:  []*account.Address{{Address: tx.Owner.Address}},
+		Fee:    tx.Fee.Winston,
+		Status: account.TxStatus_Pending,
+	}
+	if tx.Recipient != "" {
+		txMessage.Tos = []*account.Address{{Address: tx.Recipient}}
+		txMessage.Values = []*account.Value{{Value: tx.Quantity.Winston}}
+	}
+	if tx.Block != nil {
+		txMessage.Status = account.TxStatus_Success
+		txMessage.Height = strconv.FormatUint(tx.Block.Height, 10)
+		txMessage.Datetime = strconv.FormatUint(tx.Block.Timestamp, 10)
+	}
+	return txMessage
+}
@@ -0,0 +1,306 @@
+package arweave
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	defaultRequestTimeout = 10 * time.Second
+
+	// GraphQL 每页最多返回 100 条
+	maxGraphqlPageSize = 100
+)
+
+var (
+	ErrNotFound = errors.New("arweave resource not found")
+	// ErrPending 交易已提交但尚未打包
+	ErrPending = errors.New("arweave transaction pending")
+)
+
+type NetworkInfo struct {
+	Network string `json:"network"`
+	Height  uint64 `json:"height"`
+	Current string `json:"current"`
+}
+
+type Block struct {
+	IndepHash     string   `json:"indep_hash"`
+	PreviousBlock string   `json:"previous_block"`
+	Height        uint64   `json:"height"`
+	Timestamp     uint64   `json:"timestamp"`
+	Txs           []string `json:"txs"`
+}
+
+type TxStatus struct {
+	BlockHeight           uint64 `json:"block_height"`
+	BlockIndepHash        string `json:"block_indep_hash"`
+	NumberOfConfirmations uint64 `json:"number_of_confirmations"`
+}
+
+// GraphqlTransaction GraphQL 查询返回的交易, 未打包时 Block 为空
+type GraphqlTransaction struct {
+	Id        string `json:"id"`
+	Recipient string `json:"recipient"`
+	Owner     struct {
+		Address string `json:"address"`
+	} `json:"owner"`
+	Fee struct {
+		Winston string `json:"winston"`
+	} `json:"fee"`
+	Quantity struct {
+		Winston string `json:"winston"`
+	} `json:"quantity"`
+	Data struct {
+		Size string `json:"size"`
+	} `json:"data"`
+	Tags  []TagPair `json:"tags"`
+	Block *struct {
+		Id        string `json:"id"`
+		Height    uint64 `json:"height"`
+		Timestamp uint64 `json:"timestamp"`
+	} `json:"block"`
+}
+
+type GraphqlPage struct {
+	Transactions []GraphqlTransaction
+	Cursors      []string
+	HasNextPage  bool
+}
+
+// TransactionFilter GraphQL transactions 查询条件, 空字段不参与过滤
+type TransactionFilter struct {
+	Owners     []string
+	Recipients []string
+	MinHeight  uint64
+	MaxHeight  uint64
+	After      string
+	First      uint32
+	Ascending  bool
+}
+
+const transactionsQuery = `query($owners: [String!], $recipients: [String!], $block: BlockFilter, $first: Int, $after: String, $sort: SortOrder) {
+  transactions(owners: $owners, recipients: $recipients, block: $block, first: $first, after: $after, sort: $sort, bundledIn: null) {
+    pageInfo { hasNextPage }
+    edges {
+      cursor
+      node {
+        id recipient
+        owner { address }
+        fee { winston }
+        quantity { winston }
+        data { size }
+        tags { name value }
+        block { id height timestamp }
+      }
+    }
+  }
+}`
+
+// ArweaveClient Arweave 网关客户端, REST 接口与 /graphql
+type ArweaveClient struct {
+	baseUrl    string
+	httpClient *http.Client
+}
+
+func NewArweaveClient(baseUrl string, timeout time.Duration) *ArweaveClient {
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &ArweaveClient{
+		baseUrl:    strings.TrimRight(baseUrl, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// do 返回响应体, 404 返回 ErrNotFound, 202 (交易待打包) 返回 ErrPending
+func (c *ArweaveClient) do(method, path string, body interface{}) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseUrl+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return data, nil
+	case http.StatusAccepted:
+		return data, ErrPending
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	}
+	return nil, fmt.Errorf("arweave api %s status %d: %s", path, resp.StatusCode, string(data))
+}
+
+func (c *ArweaveClient) getJson(path string, result interface{}) error {
+	data, err := c.do(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		log.Error("unmarshal arweave response fail", "path", path, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (c *ArweaveClient) getText(path string) (string, error) {
+	data, err := c.do(http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (c *ArweaveClient) GetInfo() (*NetworkInfo, error) {
+	var info NetworkInfo
+	if err := c.getJson("/info", &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetBalance 单位 winston
+func (c *ArweaveClient) GetBalance(address string) (string, error) {
+	return c.getText("/wallet/" + address + "/balance")
+}
+
+func (c *ArweaveClient) GetLastTx(address string) (string, error) {
+	return c.getText("/wallet/" + address + "/last_tx")
+}
+
+// GetTxAnchor 交易锚点, 使用最近区块而非账户最后一笔交易, 同一账户可并发发送多笔交易
+func (c *ArweaveClient) GetTxAnchor() (string, error) {
+	return c.getText("/tx_anchor")
+}
+
+// GetPrice 存储 dataSize 字节数据的费用, target 非空时包含向新地址转账的额外费用, 单位 winston
+func (c *ArweaveClient) GetPrice(dataSize int, target string) (string, error) {
+	path := fmt.Sprintf("/price/%d", dataSize)
+	if target != "" {
+		path += "/" + target
+	}
+	return c.getText(path)
+}
+
+func (c *ArweaveClient) SubmitTransaction(tx *Transaction) error {
+	_, err := c.do(http.MethodPost, "/tx", tx)
+	return err
+}
+
+// GetTransaction 交易待打包时返回 ErrPending
+func (c *ArweaveClient) GetTransaction(id string) (*Transaction, error) {
+	data, err := c.do(http.MethodGet, "/tx/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+	var tx Transaction
+	if err := json.Unmarshal(data, &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// GetTransactionStatus 交易待打包时返回 ErrPending
+func (c *ArweaveClient) GetTransactionStatus(id string) (*TxStatus, error) {
+	var status TxStatus
+	if err := c.getJson("/tx/"+id+"/status", &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *ArweaveClient) GetBlockByHeight(height uint64) (*Block, error) {
+	var block Block
+	if err := c.getJson(fmt.Sprintf("/block/height/%d", height), &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (c *ArweaveClient) GetBlockByHash(hash string) (*Block, error) {
+	var block Block
+	if err := c.getJson("/block/hash/"+hash, &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// QueryTransactions 通过 /graphql 查询交易, 不包含 bundle 中的数据项
+func (c *ArweaveClient) QueryTransactions(filter *TransactionFilter) (*GraphqlPage, error) {
+	variables := map[string]any{"first": filter.First, "sort": "HEIGHT_DESC"}
+	if filter.First == 0 || filter.First > maxGraphqlPageSize {
+		variables["first"] = maxGraphqlPageSize
+	}
+	if filter.Ascending {
+		variables["sort"] = "HEIGHT_ASC"
+	}
+	if len(filter.Owners) > 0 {
+		variables["owners"] = filter.Owners
+	}
+	if len(filter.Recipients) > 0 {
+		variables["recipients"] = filter.Recipients
+	}
+	if filter.MaxHeight > 0 {
+		variables["block"] = map[string]uint64{"min": filter.MinHeight, "max": filter.MaxHeight}
+	}
+	if filter.After != "" {
+		variables["after"] = filter.After
+	}
+	data, err := c.do(http.MethodPost, "/graphql", map[string]any{"query": transactionsQuery, "variables": variables})
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Data struct {
+			Transactions struct {
+				PageInfo struct {
+					HasNextPage bool `json:"hasNextPage"`
+				} `json:"pageInfo"`
+				Edges []struct {
+					Cursor string             `json:"cursor"`
+					Node   GraphqlTransaction `json:"node"`
+				} `json:"edges"`
+			} `json:"transactions"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("arweave graphql: %s", result.Errors[0].Message)
+	}
+	page := &GraphqlPage{HasNextPage: result.Data.Transactions.PageInfo.HasNextPage}
+	for _, edge := range result.Data.Transactions.Edges {
+		page.Transactions = append(page.Transactions, edge.Node)
+		page.Cursors = append(page.Cursors, edge.Cursor)
+	}
+	return page, nil
+}
@@ -0,0 +1,324 @@
+package arweave
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+const (
+	transactionFormat = 2
+
+	maxChunkSize = 256 * 1024
+	minChunkSize = 32 * 1024
+	// 随交易一起提交的数据上限, 更大的数据需通过 /chunk 分块上传
+	maxInlineDataSize = 10 * 1024 * 1024
+)
+
+var errInvalidTransaction = errors.New("invalid arweave transaction")
+
+// Tag name 与 value 为 base64url 编码
+type Tag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Transaction format 2 交易, 二进制字段均为 base64url (无填充) 编码, 金额为 winston 字符串
+type Transaction struct {
+	Format    int    `json:"format"`
+	Id        string `json:"id"`
+	LastTx    string `json:"last_tx"`
+	Owner     string `json:"owner"`
+	Tags      []Tag  `json:"tags"`
+	Target    string `json:"target"`
+	Quantity  string `json:"quantity"`
+	Data      string `json:"data"`
+	DataSize  string `json:"data_size"`
+	DataRoot  string `json:"data_root"`
+	Reward    string `json:"reward"`
+	Signature string `json:"signature"`
+}
+
+// TagPair 解码后的 tag
+type TagPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// TxStructure CreateUnSignTransaction 的输入, to_address 为空时为纯数据交易
+type TxStructure struct {
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address,omitempty"`
+	// 转账金额, 单位 winston
+	Value string `json:"value"`
+	// base64url 编码的 RSA 模数, 即 JWK 中的 n
+	PublicKey string    `json:"public_key"`
+	Data      string    `json:"data,omitempty"`
+	Tags      []TagPair `json:"tags,omitempty"`
+	// 为空时通过 /price 查询
+	Reward string `json:"reward,omitempty"`
+	// 为空时通过 /tx_anchor 获取
+	LastTx string `json:"last_tx,omitempty"`
+	// DecodeTransaction 返回的 AR 金额, 构建交易时忽略
+	ValueAr  string `json:"value_ar,omitempty"`
+	RewardAr string `json:"reward_ar,omitempty"`
+	DataSize string `json:"data_size,omitempty"`
+}
+
+// UnsignedTx CreateUnSignTransaction 的返回值, 签名方使用 RSA-PSS (SHA-256) 对 signing_message 签名
+type UnsignedTx struct {
+	Transaction    *Transaction `json:"transaction"`
+	SigningMessage string       `json:"signing_message"`
+}
+
+func b64Encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func b64Decode(data string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(data)
+}
+
+/**
+ * @description: 构建交易
+ * data 非空时计算分块 merkle 根作为 data_root, 数据随交易提交
+ */
+func NewTransaction(owner []byte, target string, quantity string, data []byte, tags []TagPair, reward string, lastTx string) (*Transaction, error) {
+	if len(data) > maxInlineDataSize {
+		return nil, errors.New("data too large to submit with transaction")
+	}
+	tx := &Transaction{
+		Format:   transactionFormat,
+		LastTx:   lastTx,
+		Owner:    b64Encode(owner),
+		Tags:     []Tag{},
+		Target:   target,
+		Quantity: quantity,
+		Data:     b64Encode(data),
+		DataSize: strconv.Itoa(len(data)),
+		Reward:   reward,
+	}
+	for _, tag := range tags {
+		tx.Tags = append(tx.Tags, Tag{Name: b64Encode([]byte(tag.Name)), Value: b64Encode([]byte(tag.Value))})
+	}
+	if len(data) > 0 {
+		tx.DataRoot = b64Encode(DataRoot(data))
+	}
+	return tx, nil
+}
+
+// SignatureData 签名消息: deep_hash(["2", owner, target, quantity, reward, last_tx, tags, data_size, data_root])
+func (t *Transaction) SignatureData() ([]byte, error) {
+	if t.Format != transactionFormat {
+		return nil, errInvalidTransaction
+	}
+	var fields [][]byte
+	for _, value := range []string{t.Owner, t.Target} {
+		raw, err := b64Decode(value)
+		if err != nil {
+			return nil, errInvalidTransaction
+		}
+		fields = append(fields, raw)
+	}
+	lastTx, err := b64Decode(t.LastTx)
+	if err != nil {
+		return nil, errInvalidTransaction
+	}
+	dataRoot, err := b64Decode(t.DataRoot)
+	if err != nil {
+		return nil, errInvalidTransaction
+	}
+	tags := make([]any, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		name, errName := b64Decode(tag.Name)
+		value, errValue := b64Decode(tag.Value)
+		if errName != nil || errValue != nil {
+			return nil, errInvalidTransaction
+		}
+		tags = append(tags, []any{name, value})
+	}
+	return deepHash([]any{
+		[]byte(strconv.Itoa(t.Format)),
+		fields[0],
+		fields[1],
+		[]byte(t.Quantity),
+		[]byte(t.Reward),
+		lastTx,
+		tags,
+		[]byte(t.DataSize),
+		dataRoot,
+	})
+}
+
+// PublicKey owner 为 RSA 模数, 公钥指数固定为 65537
+func (t *Transaction) PublicKey() (*rsa.PublicKey, error) {
+	modulus, err := b64Decode(t.Owner)
+	if err != nil {
+		return nil, err
+	}
+	return publicKeyFromModulus(modulus)
+}
+
+// SetSignature 设置签名, 交易 id 为 sha256(signature)
+func (t *Transaction) SetSignature(signature []byte) {
+	id := sha256.Sum256(signature)
+	t.Signature = b64Encode(signature)
+	t.Id = b64Encode(id[:])
+}
+
+/**
+ * @description: 校验签名与交易 id
+ * 签名为 RSA-PSS (SHA-256), 不同 SDK 的盐长度不同 (32 或最大长度), 按自动识别校验
+ */
+func (t *Transaction) Verify() error {
+	publicKey, err := t.PublicKey()
+	if err != nil {
+		return err
+	}
+	signature, err := b64Decode(t.Signature)
+	if err != nil {
+		return err
+	}
+	if err := verifySignature(publicKey, t, signature); err != nil {
+		return err
+	}
+	id := sha256.Sum256(signature)
+	if t.Id != b64Encode(id[:]) {
+		return errors.New("transaction id does not match signature")
+	}
+	return nil
+}
+
+func verifySignature(publicKey *rsa.PublicKey, t *Transaction, signature []byte) error {
+	message, err := t.SignatureData()
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(message)
+	return rsa.VerifyPSS(publicKey, crypto.SHA256, digest[:], signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+}
+
+/**
+ * @description: deep hash, 元素为 []byte 或 []any
+ * blob: sha384(sha384("blob" || len) || sha384(data))
+ * list: acc = sha384("list" || len), 依次 acc = sha384(acc || deep_hash(item))
+ */
+func deepHash(data any) ([]byte, error) {
+	switch value := data.(type) {
+	case []byte:
+		tag := sha512.Sum384([]byte("blob" + strconv.Itoa(len(value))))
+		hash := sha512.Sum384(value)
+		tagged := sha512.Sum384(append(tag[:], hash[:]...))
+		return tagged[:], nil
+	case []any:
+		acc := sha512.Sum384([]byte("list" + strconv.Itoa(len(value))))
+		for _, item := range value {
+			hash, err := deepHash(item)
+			if err != nil {
+				return nil, err
+			}
+			acc = sha512.Sum384(append(acc[:], hash...))
+		}
+		return acc[:], nil
+	}
+	return nil, fmt.Errorf("unsupported deep hash type %T", data)
+}
+
+type merkleNode struct {
+	id           []byte
+	maxByteRange uint64
+}
+
+func hashAll(parts ...[]byte) []byte {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+func sha256Of(data []byte) []byte {
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// noteBytes 32 字节大端整数
+func noteBytes(v uint64) []byte {
+	note := make([]byte, 32)
+	binary.BigEndian.PutUint64(note[24:], v)
+	return note
+}
+
+/**
+ * @description: 数据分块 merkle 根
+ * 按 256KiB 分块, 剩余部分小于 32KiB 时与前一块平分; 叶子为 hash(hash(chunk_hash) || hash(max_range)), 分支为 hash(hash(left) || hash(right) || hash(left_max_range))
+ */
+func DataRoot(data []byte) []byte {
+	var leaves []merkleNode
+	var cursor uint64
+	rest := data
+	for len(rest) >= maxChunkSize {
+		chunkSize := maxChunkSize
+		if next := len(rest) - maxChunkSize; next > 0 && next < minChunkSize {
+			chunkSize = (len(rest) + 1) / 2
+		}
+		cursor += uint64(chunkSize)
+		leaves = append(leaves, leafNode(rest[:chunkSize], cursor))
+		rest = rest[chunkSize:]
+	}
+	cursor += uint64(len(rest))
+	leaves = append(leaves, leafNode(rest, cursor))
+
+	layer := leaves
+	for len(layer) > 1 {
+		var next []merkleNode
+		for i := 0; i < len(layer); i += 2 {
+			if i+1 == len(layer) {
+				next = append(next, layer[i])
+				continue
+			}
+			left, right := layer[i], layer[i+1]
+			next = append(next, merkleNode{
+				id:           hashAll(sha256Of(left.id), sha256Of(right.id), sha256Of(noteBytes(left.maxByteRange))),
+				maxByteRange: right.maxByteRange,
+			})
+		}
+		layer = next
+	}
+	return layer[0].id
+}
+
+func leafNode(chunk []byte, maxByteRange uint64) merkleNode {
+	return merkleNode{
+		id:           hashAll(sha256Of(sha256Of(chunk)), sha256Of(noteBytes(maxByteRange))),
+		maxByteRange: maxByteRange,
+	}
+}
+
+// WinstonToAr 1 AR = 10^12 winston
+func WinstonToAr(winston string) (string, error) {
+	value, ok := new(big.Int).SetString(winston, 10)
+	if !ok || value.Sign() < 0 {
+		return "", errors.New("invalid winston amount")
+	}
+	return new(big.Rat).SetFrac(value, winstonPerAr).FloatString(12), nil
+}
+
+// ArToWinston 超过 12 位小数的部分被截断
+func ArToWinston(ar string) (string, error) {
+	value, ok := new(big.Rat).SetString(ar)
+	if !ok || value.Sign() < 0 {
+		return "", errors.New("invalid ar amount")
+	}
+	value.Mul(value, new(big.Rat).SetInt(winstonPerAr))
+	return new(big.Int).Quo(value.Num(), value.Denom()).String(), nil
+}
+
+var winstonPerAr = new(big.Int).Exp(big.NewInt(10), big.NewInt(12), nil)
@@ -0,0 +1,161 @@
+package arweave
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// 期望值由独立实现按 arweave-js 的 deepHash 与 chunking 规则计算
+func TestDeepHash(t *testing.T) {
+	tests := []struct {
+		name string
+		data any
+		want string
+	}{
+		{"empty blob", []byte{}, "fbf00cc444f5fea9dc3bedf62a13fba8ae87e7445fc910567a23bec4eb82fadb1143c433069314d8362983dc3c2e4a38"},
+		{"blob", []byte("abc"), "71115a30152ebcffb6defbb643abc8ef76f01fe323f1d62340646085960f6e347cb2d8e9a46ddee655b3012c6131d4e0"},
+		{"empty list", []any{}, "a69e7d37fdc7f040a9ec16aae84de24fab4a653dac4de0bd247e36bab9fe45d9289c5a04a893c95285812f5cefc9707a"},
+		{"nested list", []any{[]byte("a"), []any{[]byte("b"), []byte("c")}}, "30bce0a753c170f214f57dd0244bc29c76526aea405cd8bff8af8301a7d10424e1c57f63ab4d55070b99f48f72a8c2e7"},
+	}
+	for _, tt := range tests {
+		got, err := deepHash(tt.data)
+		if err != nil || hex.EncodeToString(got) != tt.want {
+			t.Errorf("%s: deep hash = %x, %v, want %s", tt.name, got, err, tt.want)
+		}
+	}
+	for _, data := range []any{"abc", []any{[]byte("a"), 1}, nil} {
+		if _, err := deepHash(data); err == nil {
+			t.Errorf("deep hash of %#v succeeded", data)
+		}
+	}
+}
+
+// testData 按 i mod 251 填充, 避免分块内容重复
+func testData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestDataRoot(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		want string
+	}{
+		{"single byte", 1, "Ht_yZhXGBDUZfLv4OD6we7FkrjcpDpGPwQZEZgEJVfk"},
+		// 恰好一块时末尾补一个空块
+		{"one full chunk", maxChunkSize, "gty7KB2baLFp7OGxuV2wBeX3NippS1tNVlMOZryIq5o"},
+		// 剩余 1KiB 小于 32KiB, 与前一块平分
+		{"rebalanced tail", maxChunkSize + 1024, "4p4ZCUNl9_MSEfyo0wces4wLNY57I5q1jb-Iy8vRQM0"},
+		// 三个叶子, 奇数节点直接上移
+		{"odd leaves", 2*maxChunkSize + maxChunkSize/2, "cjtn4cwsiAqSouoH9H9MynxXfMKygPVpNSp50F8E1t8"},
+	}
+	for _, tt := range tests {
+		if got := b64Encode(DataRoot(testData(tt.size))); got != tt.want {
+			t.Errorf("%s: data root = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSignatureData(t *testing.T) {
+	owner := testData(512)
+	target := b64Encode(bytes.Repeat([]byte{0xaa}, 32))
+	lastTx := b64Encode(bytes.Repeat([]byte{0x11}, 48))
+	tags := []TagPair{{Name: "Content-Type", Value: "text/plain"}}
+	tx, err := NewTransaction(owner, target, "1500000000000", []byte("hello arweave"), tags, "65595508", lastTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.DataRoot != "zdc2qMz3Noxajh3QLj7cqCzP0i3bGKydzntyYLhBKdg" || tx.DataSize != "13" {
+		t.Errorf("data root %s, size %s", tx.DataRoot, tx.DataSize)
+	}
+	message, err := tx.SignatureData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "60d7e778eea5b3ff6a90bcb0d23c0d024bc648d6fc1d6a8820c21cd21f8a6bf41f02462b7fc5baccf113fd02a2980912"; hex.EncodeToString(message) != want {
+		t.Errorf("signature data = %x, want %s", message, want)
+	}
+
+	tx.Target = "!"
+	if _, err := tx.SignatureData(); err == nil {
+		t.Error("invalid target accepted")
+	}
+	tx.Target, tx.Format = target, 1
+	if _, err := tx.SignatureData(); err == nil {
+		t.Error("format 1 transaction accepted")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := NewTransaction(key.N.Bytes(), "", "0", []byte("data"), nil, "1000", b64Encode(make([]byte, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, _ := tx.SignatureData()
+	digest := sha256.Sum256(message)
+	// 不同 SDK 使用 32 字节或最大长度的盐
+	for _, saltLength := range []int{32, rsa.PSSSaltLengthAuto} {
+		signature, err := rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: saltLength})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx.SetSignature(signature)
+		if err := tx.Verify(); err != nil {
+			t.Errorf("salt length %d: %v", saltLength, err)
+		}
+	}
+	tx.Reward = "1001"
+	if err := tx.Verify(); err == nil {
+		t.Error("tampered reward verified")
+	}
+}
+
+func TestWinstonAr(t *testing.T) {
+	tests := []struct {
+		winston string
+		ar      string
+	}{
+		{"0", "0.000000000000"},
+		{"1", "0.000000000001"},
+		{"1500000000000", "1.500000000000"},
+		{"123456789012345678", "123456.789012345678"},
+	}
+	for _, tt := range tests {
+		if got, err := WinstonToAr(tt.winston); err != nil || got != tt.ar {
+			t.Errorf("WinstonToAr(%s) = %s, %v, want %s", tt.winston, got, err, tt.ar)
+		}
+		if got, err := ArToWinston(tt.ar); err != nil || got != tt.winston {
+			t.Errorf("ArToWinston(%s) = %s, %v, want %s", tt.ar, got, err, tt.winston)
+		}
+	}
+	// 超过 12 位小数截断
+	if got, _ := ArToWinston("0.0000000000019"); got != "1" {
+		t.Errorf("ArToWinston(0.0000000000019) = %s, want 1", got)
+	}
+	if got, _ := ArToWinston("2"); got != "2000000000000" {
+		t.Errorf("ArToWinston(2) = %s, want 2000000000000", got)
+	}
+	for _, bad := range []string{"-1", "1.5", "abc", ""} {
+		if _, err := WinstonToAr(bad); err == nil {
+			t.Errorf("WinstonToAr(%q) succeeded", bad)
+		}
+	}
+	for _, bad := range []string{"-0.1", "abc", ""} {
+		if _, err := ArToWinston(bad); err == nil {
+			t.Errorf("ArToWinston(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,995 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/chain/utxo"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	ChainName = "Bitcoin"
+
+	defaultTxPageSize = 20
+	// 费率估算的目标确认区块数
+	slowFeeBlocks   = 12
+	normalFeeBlocks = 6
+	fastFeeBlocks   = 2
+	// 节点无法估算费率时 (如 regtest) 使用的最低中继费率, 单位 sat/vB
+	minRelayFeeRate = 1.0
+)
+
+type ChainAdaptor struct {
+	utxoClient  utxo.UtxoClient
+	params      *utxo.NetParams
+	addressType utxo.AddressType
+}
+
+// Params rpc_url 为 http(s) 时使用 Bitcoin Core JSON-RPC (rpc_user/rpc_pass 为 basic auth), 为 tcp:// 或 ssl:// 时使用 Electrum 协议
+// Network 为 mainnet/testnet/regtest, AddressType 为 ConvertAddress 派生的地址类型
+type Params struct {
+	Network     string `yaml:"network"`
+	AddressType string `yaml:"address_type"`
+}
+
+func init() {
+	chain.Register(ChainName, NewChainAdaptor)
+	config.RegisterChainValidator(ChainName, func(node *config.ChainConfig) error {
+		_, _, err := decodeParams(node)
+		return err
+	})
+}
+
+func decodeParams(node *config.ChainConfig) (*utxo.NetParams, utxo.AddressType, error) {
+	var conf Params
+	if err := node.DecodeParams(&conf); err != nil {
+		return nil, "", fmt.Errorf("params: %w", err)
+	}
+	network := conf.Network
+	if network == "" {
+		network = utxo.BitcoinMainNetParams.Name
+	}
+	params, ok := utxo.NetParamsByName(network)
+	if !ok {
+		return nil, "", fmt.Errorf("params: unknown bitcoin network %s", network)
+	}
+	addressType := utxo.AddressType(conf.AddressType)
+	if addressType == "" {
+		addressType = utxo.AddressP2WPKH
+	}
+	if !params.Supports(addressType) {
+		return nil, "", fmt.Errorf("params: %s does not support %s address", network, addressType)
+	}
+	return params, addressType, nil
+}
+
+func NewChainAdaptor(conf *config.Config) (chain.IChainAdaptor, error) {
+	node, ok := conf.Chain(ChainName)
+	if !ok {
+		return nil, errors.New("bitcoin rpc not configured")
+	}
+	params, addressType, err := decodeParams(node)
+	if err != nil {
+		return nil, err
+	}
+	rpc := node.RPCs[0]
+	client, err := utxo.NewUtxoClient(rpc.RPCURL, rpc.RPCUser, rpc.RPCPass, node.TimeOut.Duration())
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		utxoClient:  client,
+		params:      params,
+		addressType: addressType,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// ConvertAddress public_key 为 33 字节压缩公钥 (P2PKH 也可为 65 字节非压缩公钥), 地址类型由配置的 address_type 决定
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	address, err := utxo.NewAddress(req.PublicKey, c.addressType, c.params)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: address,
+	}, nil
+}
+
+// ValidAddress 支持 P2PKH/P2SH/P2WPKH/P2WSH/P2TR, bech32 地址的规范格式为小写
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	script, _, err := utxo.DecodeAddress(req.Address, c.params)
+	if err != nil {
+		return &account.ValidAddressResponse{
+			Code:  common.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	canonical, _ := utxo.ScriptAddress(script, c.params)
+	return &account.ValidAddressResponse{
+		Code:             common.ReturnCode_SUCCESS,
+		Msg:              "valid address",
+		Valid:            true,
+		CanWithdrawal:    true,
+		CanonicalAddress: canonical,
+	}, nil
+}
+
+// GetBlockByNumber height 为 0 时返回最新区块; Electrum 后端不提供区块交易, 仅返回区块哈希
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	height := uint64(req.Height)
+	if height == 0 {
+		best, err := c.utxoClient.GetBestHeight()
+		if err != nil {
+			log.Error("get best height fail", "err", err)
+			return &account.BlockResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get best height fail",
+			}, nil
+		}
+		height = best
+	}
+	header, err := c.utxoClient.GetBlockHeader(height)
+	if err != nil {
+		log.Error("get block header fail", "height", height, "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return c.toBlockResponse(header, req.ViewTx)
+}
+
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	header, err := c.utxoClient.GetBlockHeaderByHash(req.Hash)
+	if err != nil {
+		log.Error("get block header fail", "hash", req.Hash, "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by hash fail",
+		}, nil
+	}
+	return c.toBlockResponse(header, req.ViewTx)
+}
+
+// toBlockResponse view_tx 为 true 时返回每笔交易的输出总额与第一个输出地址, 不查询输入的前序交易
+func (c *ChainAdaptor) toBlockResponse(header *utxo.BlockHeader, viewTx bool) (*account.BlockResponse, error) {
+	block, err := c.utxoClient.GetBlock(header.Hash)
+	if errors.Is(err, utxo.ErrUnsupported) && !viewTx {
+		return &account.BlockResponse{
+			Code:   common.ReturnCode_SUCCESS,
+			Msg:    "get block success",
+			Height: int64(header.Height),
+			Hash:   header.Hash,
+		}, nil
+	}
+	if err != nil {
+		log.Error("get block fail", "hash", header.Hash, "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block fail",
+		}, nil
+	}
+	var txList []*account.BlockInfoTransactionList
+	for _, tx := range block.Txs {
+		item := &account.BlockInfoTransactionList{Hash: tx.TxHash()}
+		if viewTx {
+			var total int64
+			for _, out := range tx.TxOut {
+				total += out.Value
+			}
+			if len(tx.TxOut) > 0 {
+				item.To, _ = utxo.ScriptAddress(tx.TxOut[0].PkScript, c.params)
+			}
+			item.Amount = strconv.FormatInt(total, 10)
+			item.Time = strconv.FormatUint(uint64(header.Time), 10)
+			item.Status = account.TxStatus_Success.String()
+		}
+		txList = append(txList, item)
+	}
+	return &account.BlockResponse{
+		Code:         common.ReturnCode_SUCCESS,
+		Msg:          "get block success",
+		Height:       int64(header.Height),
+		Hash:         header.Hash,
+		Transactions: txList,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	header, err := c.utxoClient.GetBlockHeaderByHash(req.Hash)
+	if err != nil {
+		log.Error("get block header fail", "hash", req.Hash, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return toBlockHeaderResponse(header), nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	height := uint64(req.Height)
+	if height == 0 {
+		best, err := c.utxoClient.GetBestHeight()
+		if err != nil {
+			log.Error("get best height fail", "err", err)
+			return &account.BlockHeaderResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get best height fail",
+			}, nil
+		}
+		height = best
+	}
+	header, err := c.utxoClient.GetBlockHeader(height)
+	if err != nil {
+		log.Error("get block header fail", "height", height, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return toBlockHeaderResponse(header), nil
+}
+
+func toBlockHeaderResponse(header *utxo.BlockHeader) *account.BlockHeaderResponse {
+	return &account.BlockHeaderResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get block header success",
+		BlockHeader: &account.BlockHeader{
+			ParentHash: header.PrevHash,
+			Number:     strconv.FormatUint(header.Height, 10),
+			Time:       uint64(header.Time),
+		},
+	}
+}
+
+// GetAccount 余额为地址全部 UTXO 之和 (含未确认), 单位 satoshi
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	if req.ContractAddress != "" {
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "bitcoin does not support token",
+			Balance: "0",
+		}, nil
+	}
+	utxos, err := c.listUnspent(req.Address, 0)
+	if err != nil {
+		log.Error("list unspent fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "get balance by address fail",
+			Balance: "0",
+		}, nil
+	}
+	var balance int64
+	for _, u := range utxos {
+		balance += u.Value
+	}
+	return &account.AccountResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Network:       c.params.Name,
+		Balance:       strconv.FormatInt(balance, 10),
+	}, nil
+}
+
+// GetFee 返回 12/6/2 个区块内确认的费率, 单位 sat/vB
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	var fees [3]string
+	for i, blocks := range []int{slowFeeBlocks, normalFeeBlocks, fastFeeBlocks} {
+		rate, err := c.estimateFeeRate(blocks)
+		if err != nil {
+			log.Error("estimate fee rate fail", "blocks", blocks, "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "estimate fee rate fail",
+			}, nil
+		}
+		fees[i] = strconv.FormatFloat(rate, 'f', -1, 64)
+	}
+	return &account.FeeResponse{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fees[0],
+		NormalFee: fees[1],
+		FastFee:   fees[2],
+	}, nil
+}
+
+// estimateFeeRate 节点无法估算时使用最低中继费率, 其他错误直接返回
+func (c *ChainAdaptor) estimateFeeRate(blocks int) (float64, error) {
+	rate, err := c.utxoClient.EstimateFeeRate(blocks)
+	if err != nil {
+		var netErr interface{ Timeout() bool }
+		if errors.As(err, &netErr) {
+			return 0, err
+		}
+		log.Warn("fee rate unavailable, use min relay fee", "blocks", blocks, "err", err)
+		return minRelayFeeRate, nil
+	}
+	if rate < minRelayFeeRate {
+		rate = minRelayFeeRate
+	}
+	return rate, nil
+}
+
+// SendTx raw_tx 为 BuildSignedTransaction 返回的 hex 原始交易
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(req.RawTx, "0x"))
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	tx, err := utxo.DeserializeTx(raw)
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	txId, err := c.utxoClient.SendRawTransaction(raw)
+	if err != nil {
+		log.Error("send raw transaction fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	if txId != tx.TxHash() {
+		log.Warn("broadcast tx id mismatch", "expect", tx.TxHash(), "actual", txId)
+	}
+	return &account.SendTxResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: tx.TxHash(),
+	}, nil
+}
+
+/**
+ * @description: 查询地址交易, 按 page/pagesize 分页, 内存池交易排在最前
+ * 依赖 Electrum 的 scripthash 历史索引, Bitcoin Core 后端不索引地址, 返回错误
+ */
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	script, _, err := utxo.DecodeAddress(req.Address, c.params)
+	if err != nil {
+		return &account.TxAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	history, err := c.utxoClient.GetHistory(req.Address, script)
+	if err != nil {
+		log.Error("get address history fail", "address", req.Address, "err", err)
+		return &account.TxAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by address fail",
+		}, nil
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		if history[i].Height == 0 || history[j].Height == 0 {
+			return history[i].Height == 0 && history[j].Height != 0
+		}
+		return history[i].Height > history[j].Height
+	})
+	pageSize := req.Pagesize
+	if pageSize == 0 {
+		pageSize = defaultTxPageSize
+	}
+	page := req.Page
+	if page == 0 {
+		page = 1
+	}
+	var txList []*account.TxMessage
+	for i := (page - 1) * pageSize; i < page*pageSize && int(i) < len(history); i++ {
+		info, err := c.utxoClient.GetTransaction(history[i].TxId)
+		if err != nil {
+			log.Error("get transaction fail", "tx_id", history[i].TxId, "err", err)
+			return &account.TxAddressResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get tx by address fail",
+			}, nil
+		}
+		txList = append(txList, c.toTxMessage(info))
+	}
+	return &account.TxAddressResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txList,
+	}, nil
+}
+
+// GetTxByHash 未打包为 Pending, 已打包即为 Success; Bitcoin Core 后端需开启 txindex
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	info, err := c.utxoClient.GetTransaction(req.Hash)
+	if errors.Is(err, utxo.ErrTxNotFound) {
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_SUCCESS,
+			Msg:  "tx not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get transaction fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}, nil
+	}
+	return &account.TxHashResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by hash success",
+		Tx:   c.toTxMessage(info),
+	}, nil
+}
+
+// toTxMessage 查询输入的前序交易得到发送地址与手续费, 查询失败时不填写对应字段
+func (c *ChainAdaptor) toTxMessage(info *utxo.TxInfo) *account.TxMessage {
+	tx := info.Tx
+	txMessage := &account.TxMessage{
+		Hash:   tx.TxHash(),
+		Status: account.TxStatus_Pending,
+	}
+	if info.Confirmations > 0 {
+		txMessage.Status = account.TxStatus_Success
+		txMessage.Height = strconv.FormatUint(info.Height, 10)
+		txMessage.Datetime = strconv.FormatUint(info.Time, 10)
+	}
+	var outTotal int64
+	for _, out := range tx.TxOut {
+		address, _ := utxo.ScriptAddress(out.PkScript, c.params)
+		txMessage.Tos = append(txMessage.Tos, &account.Address{Address: address})
+		txMessage.Values = append(txMessage.Values, &account.Value{Value: strconv.FormatInt(out.Value, 10)})
+		outTotal += out.Value
+	}
+	prevOuts, err := c.fetchPrevOuts(tx)
+	if err != nil {
+		log.Warn("fetch prev outputs fail", "tx_id", txMessage.Hash, "err", err)
+		return txMessage
+	}
+	var inTotal int64
+	for _, prevOut := range prevOuts {
+		address, _ := utxo.ScriptAddress(prevOut.PkScript, c.params)
+		txMessage.Froms = append(txMessage.Froms, &account.Address{Address: address})
+		inTotal += prevOut.Value
+	}
+	if len(prevOuts) > 0 {
+		txMessage.Fee = strconv.FormatInt(inTotal-outTotal, 10)
+	}
+	return txMessage
+}
+
+// fetchPrevOuts coinbase 交易没有前序输出, 返回空
+func (c *ChainAdaptor) fetchPrevOuts(tx *utxo.MsgTx) ([]*utxo.TxOut, error) {
+	if len(tx.TxIn) == 1 && tx.TxIn[0].PreviousOutPoint.Hash == [32]byte{} {
+		return nil, nil
+	}
+	cache := make(map[string]*utxo.MsgTx)
+	var prevOuts []*utxo.TxOut
+	for _, in := range tx.TxIn {
+		txId := in.PreviousOutPoint.TxId()
+		prevTx, ok := cache[txId]
+		if !ok {
+			info, err := c.utxoClient.GetTransaction(txId)
+			if err != nil {
+				return nil, err
+			}
+			prevTx = info.Tx
+			cache[txId] = prevTx
+		}
+		if int(in.PreviousOutPoint.Index) >= len(prevTx.TxOut) {
+			return nil, errors.New("prev output index out of range")
+		}
+		prevOuts = append(prevOuts, prevTx.TxOut[in.PreviousOutPoint.Index])
+	}
+	return prevOuts, nil
+}
+
+// GetBlockByRange 逐个区块返回交易的输出, 不查询前序交易; Electrum 后端不支持
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, errStart := strconv.ParseUint(req.Start, 10, 64)
+	end, errEnd := strconv.ParseUint(req.End, 10, 64)
+	if errStart != nil || errEnd != nil || start > end {
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid block range",
+		}, nil
+	}
+	var blockList []*account.BlockData
+	for height := start; height <= end; height++ {
+		header, err := c.utxoClient.GetBlockHeader(height)
+		if err != nil {
+			log.Error("get block header fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block by range fail",
+			}, nil
+		}
+		block, err := c.utxoClient.GetBlock(header.Hash)
+		if err != nil {
+			log.Error("get block fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block by range fail",
+			}, nil
+		}
+		var txList []*account.TxMessage
+		for _, tx := range block.Txs {
+			txMessage := &account.TxMessage{
+				Hash:     tx.TxHash(),
+				Status:   account.TxStatus_Success,
+				Height:   strconv.FormatUint(height, 10),
+				Datetime: strconv.FormatUint(uint64(header.Time), 10),
+			}
+			for _, out := range tx.TxOut {
+				address, _ := utxo.ScriptAddress(out.PkScript, c.params)
+				txMessage.Tos = append(txMessage.Tos, &account.Address{Address: address})
+				txMessage.Values = append(txMessage.Values, &account.Value{Value: strconv.FormatInt(out.Value, 10)})
+			}
+			txList = append(txList, txMessage)
+		}
+		blockList = append(blockList, &account.BlockData{
+			Hash:         header.Hash,
+			Transactions: txList,
+		})
+	}
+	return &account.BlockByRangeResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "get block by range success",
+		Blocks: blockList,
+	}, nil
+}
+
+/**
+ * @description: 创建未签名交易
+ * base64_tx 为 base64(json) 的 TxStructure, 通过 branch and bound 选择 UTXO 并找零, 返回 base64 编码的 PSBT (BIP174)
+ * 每个输入的签名摘要写入 proprietary 字段 (wca, 子类型 0x00), 只能签哈希的签名机可直接使用;
+ * P2TR 输入按 BIP86 key path 签名, 需使用调整后的私钥
+ */
+func (c *ChainAdaptor) CreateUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txStructure, err := decodeTxStructure(req.Base64Tx)
+	if err != nil {
+		log.Error("decode tx structure fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode tx structure fail",
+		}, nil
+	}
+	packet, err := c.buildPsbt(txStructure)
+	if err != nil {
+		log.Error("build psbt fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "build transaction fail: " + err.Error(),
+		}, nil
+	}
+	return &account.UnSignTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "create un sign transaction success",
+		UnSignTx: packet.B64Encode(),
+	}, nil
+}
+
+func (c *ChainAdaptor) buildPsbt(txStructure *TxStructure) (*utxo.Psbt, error) {
+	fromScript, fromType, err := utxo.DecodeAddress(txStructure.FromAddress, c.params)
+	if err != nil {
+		return nil, err
+	}
+	// P2SH 地址只支持 P2SH-P2WPKH
+	if fromType == utxo.AddressP2SH {
+		fromType = utxo.AddressP2SHP2WPKH
+	}
+	if fromType == utxo.AddressP2WSH {
+		return nil, errors.New("p2wsh from address is not supported")
+	}
+	publicKey, err := hex.DecodeString(strings.TrimPrefix(txStructure.PublicKey, "0x"))
+	if err != nil {
+		return nil, errors.New("invalid public key")
+	}
+	pubKeyScript, err := utxo.PayToPubKeyScript(publicKey, fromType)
+	if err != nil || !bytes.Equal(pubKeyScript, fromScript) {
+		return nil, errors.New("public key does not match from address")
+	}
+
+	outputs := txStructure.Outputs
+	if len(outputs) == 0 {
+		outputs = []*TxOutput{{Address: txStructure.ToAddress, Value: txStructure.Value}}
+	}
+	var txOuts []*utxo.TxOut
+	for _, output := range outputs {
+		script, _, err := utxo.DecodeAddress(output.Address, c.params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output address %s", output.Address)
+		}
+		value, err := strconv.ParseInt(output.Value, 10, 64)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid output value %s", output.Value)
+		}
+		txOuts = append(txOuts, &utxo.TxOut{Value: value, PkScript: script})
+	}
+	changeScript := fromScript
+	if txStructure.ChangeAddress != "" {
+		if changeScript, _, err = utxo.DecodeAddress(txStructure.ChangeAddress, c.params); err != nil {
+			return nil, errors.New("invalid change address")
+		}
+	}
+
+	var feeRate float64
+	if txStructure.FeeRate != "" {
+		if feeRate, err = strconv.ParseFloat(txStructure.FeeRate, 64); err != nil || feeRate < minRelayFeeRate {
+			return nil, errors.New("invalid fee rate")
+		}
+	} else if feeRate, err = c.estimateFeeRate(normalFeeBlocks); err != nil {
+		return nil, err
+	}
+
+	minConfirmations := txStructure.MinConfirmations
+	if minConfirmations == 0 {
+		minConfirmations = 1
+	}
+	candidates, err := c.listUnspent(txStructure.FromAddress, minConfirmations)
+	if err != nil {
+		return nil, err
+	}
+	if len(txStructure.Utxos) > 0 {
+		candidates, err = filterUtxos(candidates, txStructure.Utxos)
+		if err != nil {
+			return nil, err
+		}
+	}
+	selection, err := utxo.SelectCoins(&utxo.CoinSelectionRequest{
+		Utxos:        candidates,
+		InputType:    fromType,
+		Outputs:      txOuts,
+		ChangeScript: changeScript,
+		FeeRate:      feeRate,
+		Params:       c.params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sequence := uint32(utxo.RbfSequence)
+	if txStructure.DisableRbf {
+		sequence = utxo.MaxSequence - 1
+	}
+	tx := &utxo.MsgTx{Version: 2, LockTime: txStructure.LockTime}
+	for _, u := range selection.Selected {
+		outPoint, err := utxo.NewOutPoint(u.TxId, u.Index)
+		if err != nil {
+			return nil, err
+		}
+		tx.TxIn = append(tx.TxIn, &utxo.TxIn{PreviousOutPoint: outPoint, Sequence: sequence})
+	}
+	tx.TxOut = txOuts
+	if selection.Change > 0 {
+		tx.TxOut = append(tx.TxOut, &utxo.TxOut{Value: selection.Change, PkScript: changeScript})
+	}
+
+	packet, err := utxo.NewPsbt(tx)
+	if err != nil {
+		return nil, err
+	}
+	for i, u := range selection.Selected {
+		in := packet.Inputs[i]
+		in.WitnessUtxo = &utxo.TxOut{Value: u.Value, PkScript: u.PkScript}
+		switch fromType {
+		case utxo.AddressP2PKH:
+			// legacy 输入须提供完整的前序交易
+			info, err := c.utxoClient.GetTransaction(u.TxId)
+			if err != nil {
+				return nil, err
+			}
+			in.NonWitnessUtxo, in.WitnessUtxo = info.Tx, nil
+		case utxo.AddressP2SHP2WPKH:
+			in.RedeemScript = utxo.PayToWitnessPubKeyHashScript(utxo.Hash160(publicKey))
+		}
+		if fromType == utxo.AddressP2TR {
+			in.TapInternalKey = xOnlyPubKey(publicKey)
+		} else {
+			in.SighashType = uint32(c.params.SigHashType())
+			in.Bip32Derivation = []*utxo.Bip32Derivation{{PubKey: publicKey}}
+		}
+	}
+	// 找零到发送地址时标明找零输出的公钥, 便于签名端校验
+	if selection.Change > 0 && bytes.Equal(changeScript, fromScript) {
+		out := packet.Outputs[len(packet.Outputs)-1]
+		switch fromType {
+		case utxo.AddressP2TR:
+			out.TapInternalKey = xOnlyPubKey(publicKey)
+		case utxo.AddressP2SHP2WPKH:
+			out.RedeemScript = utxo.PayToWitnessPubKeyHashScript(utxo.Hash160(publicKey))
+			out.Bip32Derivation = []*utxo.Bip32Derivation{{PubKey: publicKey}}
+		default:
+			out.Bip32Derivation = []*utxo.Bip32Derivation{{PubKey: publicKey}}
+		}
+	}
+	for i := range packet.Inputs {
+		hash, err := packet.SignatureHash(i, c.params)
+		if err != nil {
+			return nil, err
+		}
+		packet.SetSigningHash(i, hash)
+	}
+	return packet, nil
+}
+
+// filterUtxos 只使用指定的 UTXO, 指定的 UTXO 须存在且满足确认数
+func filterUtxos(candidates []*utxo.Utxo, inputs []*TxInput) ([]*utxo.Utxo, error) {
+	available := make(map[string]*utxo.Utxo)
+	for _, u := range candidates {
+		available[fmt.Sprintf("%s:%d", u.TxId, u.Index)] = u
+	}
+	var selected []*utxo.Utxo
+	for _, input := range inputs {
+		u, ok := available[fmt.Sprintf("%s:%d", input.TxId, input.Index)]
+		if !ok {
+			return nil, fmt.Errorf("utxo %s:%d not available", input.TxId, input.Index)
+		}
+		selected = append(selected, u)
+	}
+	return selected, nil
+}
+
+func xOnlyPubKey(publicKey []byte) []byte {
+	key, _ := btcec.ParsePubKey(publicKey)
+	return schnorr.SerializePubKey(key)
+}
+
+/**
+ * @description: 合并签名并 finalize PSBT
+ * base64_tx 为 CreateUnSignTransaction 返回的 PSBT; signature 为签名端返回的 PSBT (base64, 含 partial_sig 或 tap_key_sig),
+ * 或按输入顺序以逗号分隔的 hex 签名 (ECDSA 为 DER 或 64 字节 r||s, P2TR 为 64 字节 schnorr)
+ * 所有签名均重新校验, 返回 hex 编码的原始交易, 可直接用于 SendTx
+ */
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	packet, err := utxo.DecodePsbtBase64(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode psbt fail",
+		}, nil
+	}
+	if err := c.applySignatures(packet, req.Signature); err != nil {
+		log.Error("apply signatures fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "verify signature fail: " + err.Error(),
+		}, nil
+	}
+	if err := packet.Finalize(); err != nil {
+		log.Error("finalize psbt fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "finalize psbt fail: " + err.Error(),
+		}, nil
+	}
+	tx, err := packet.Extract()
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "extract transaction fail",
+		}, nil
+	}
+	return &account.SignedTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "build signed transaction success",
+		SignedTx: hex.EncodeToString(tx.Serialize()),
+	}, nil
+}
+
+func (c *ChainAdaptor) applySignatures(packet *utxo.Psbt, signature string) error {
+	if signed, err := utxo.DecodePsbtBase64(signature); err == nil {
+		return packet.Combine(signed, c.params)
+	}
+	signatures := strings.Split(signature, ",")
+	if len(signatures) != len(packet.Inputs) {
+		return fmt.Errorf("expect %d signatures, got %d", len(packet.Inputs), len(signatures))
+	}
+	for i, sigHex := range signatures {
+		sig, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(sigHex), "0x"))
+		if err != nil {
+			return fmt.Errorf("input %d invalid signature hex", i)
+		}
+		if err := packet.AddSignature(i, packet.SignerPubKey(i), sig, c.params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeTransaction raw_tx 为 hex 原始交易或 base64 PSBT, 返回 base64(json) 的 DecodedTx; PSBT 包含输入金额, 可计算手续费
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	var tx *utxo.MsgTx
+	var prevOuts []*utxo.TxOut
+	if packet, err := utxo.DecodePsbtBase64(req.RawTx); err == nil {
+		tx = packet.UnsignedTx
+		if finalized, err := packet.Extract(); err == nil {
+			tx = finalized
+		}
+		prevOuts, _ = packet.PrevOuts()
+	} else {
+		raw, err := hex.DecodeString(strings.TrimPrefix(req.RawTx, "0x"))
+		if err != nil {
+			return &account.DecodeTransactionResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "decode raw tx fail",
+			}, nil
+		}
+		if tx, err = utxo.DeserializeTx(raw); err != nil {
+			return &account.DecodeTransactionResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+	}
+	decoded := &DecodedTx{
+		TxId:     tx.TxHash(),
+		Version:  tx.Version,
+		LockTime: tx.LockTime,
+		VSize:    tx.VirtualSize(),
+	}
+	var inTotal, outTotal int64
+	for i, in := range tx.TxIn {
+		input := &DecodedInput{
+			TxId:     in.PreviousOutPoint.TxId(),
+			Index:    in.PreviousOutPoint.Index,
+			Sequence: in.Sequence,
+		}
+		if len(in.SignatureScript) > 0 || len(in.Witness) > 0 {
+			decoded.Signed = true
+		}
+		if prevOuts != nil {
+			input.Address, _ = utxo.ScriptAddress(prevOuts[i].PkScript, c.params)
+			input.Value = strconv.FormatInt(prevOuts[i].Value, 10)
+			inTotal += prevOuts[i].Value
+		}
+		decoded.Inputs = append(decoded.Inputs, input)
+	}
+	for _, out := range tx.TxOut {
+		address, _ := utxo.ScriptAddress(out.PkScript, c.params)
+		decoded.Outputs = append(decoded.Outputs, &DecodedOutput{
+			Address: address,
+			Value:   strconv.FormatInt(out.Value, 10),
+			Script:  hex.EncodeToString(out.PkScript),
+		})
+		outTotal += out.Value
+	}
+	if prevOuts != nil {
+		decoded.Fee = strconv.FormatInt(inTotal-outTotal, 10)
+	}
+	txJson, _ := json.Marshal(decoded)
+	return &account.DecodeTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "decode transaction success",
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "bitcoin does not support verify signed transaction",
+	}, nil
+}
+
+// GetExtraData 返回网络参数、最新高度与普通费率 (json), 用于离线构建交易
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	height, err := c.utxoClient.GetBestHeight()
+	if err != nil {
+		log.Error("get best height fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get best height fail",
+		}, nil
+	}
+	feeRate, err := c.estimateFeeRate(normalFeeBlocks)
+	if err != nil {
+		log.Error("estimate fee rate fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "estimate fee rate fail",
+		}, nil
+	}
+	value, _ := json.Marshal(map[string]interface{}{
+		"network":      c.params.Name,
+		"address_type": c.addressType,
+		"best_height":  height,
+		"fee_rate":     feeRate,
+	})
+	return &account.ExtraDataResponse{
+		Code:  common.ReturnCode_SUCCESS,
+		Msg:   "get extra data success",
+		Value: string(value),
+	}, nil
+}
+
+// GetUnspentOutputs 返回确认数不少于 min_confirmations 的 UTXO, 金额单位 satoshi
+func (c *ChainAdaptor) GetUnspentOutputs(req *account.UnspentOutputsRequest) (*account.UnspentOutputsResponse, error) {
+	utxos, err := c.listUnspent(req.Address, req.MinConfirmations)
+	if err != nil {
+		log.Error("list unspent fail", "address", req.Address, "err", err)
+		return &account.UnspentOutputsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get unspent outputs fail",
+		}, nil
+	}
+	var outputs []*account.UnspentOutput
+	for _, u := range utxos {
+		outputs = append(outputs, &account.UnspentOutput{
+			TxId:          u.TxId,
+			Index:         u.Index,
+			Value:         strconv.FormatInt(u.Value, 10),
+			Script:        hex.EncodeToString(u.PkScript),
+			Address:       req.Address,
+			Height:        u.Height,
+			Confirmations: u.Confirmations,
+		})
+	}
+	return &account.UnspentOutputsResponse{
+		Code:           common.ReturnCode_SUCCESS,
+		Msg:            "get unspent outputs success",
+		UnspentOutputs: outputs,
+	}, nil
+}
+
+// listUnspent 按确认数过滤, 结果按高度与 outpoint 排序保证选择结果稳定
+func (c *ChainAdaptor) listUnspent(address string, minConfirmations uint64) ([]*utxo.Utxo, error) {
+	script, _, err := utxo.DecodeAddress(address, c.params)
+	if err != nil {
+		return nil, err
+	}
+	utxos, err := c.utxoClient.ListUnspent(address, script)
+	if err != nil {
+		return nil, err
+	}
+	var result []*utxo.Utxo
+	for _, u := range utxos {
+		if u.Confirmations >= minConfirmations {
+			result = append(result, u)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Height != result[j].Height {
+			return result[i].Height < result[j].Height
+		}
+		if result[i].TxId != result[j].TxId {
+			return result[i].TxId < result[j].TxId
+		}
+		return result[i].Index < result[j].Index
+	})
+	return result, nil
+}
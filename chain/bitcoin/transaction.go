@@ -0,0 +1,77 @@
+package bitcoin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+type TxOutput struct {
+	Address string `json:"address"`
+	// 单位 satoshi
+	Value string `json:"value"`
+}
+
+type TxInput struct {
+	TxId  string `json:"tx_id"`
+	Index uint32 `json:"index"`
+	// 单位 satoshi
+	Value string `json:"value"`
+}
+
+/**
+ * TxStructure CreateUnSignTransaction 的输入
+ * outputs 为空时使用 to_address 与 value; utxos 为空时查询 from_address 的全部 UTXO 参与选择
+ * fee_rate 单位 sat/vB, 为空时按 6 个区块确认估算; change_address 为空时找零到 from_address
+ */
+type TxStructure struct {
+	FromAddress      string      `json:"from_address"`
+	PublicKey        string      `json:"public_key"`
+	ToAddress        string      `json:"to_address"`
+	Value            string      `json:"value"`
+	Outputs          []*TxOutput `json:"outputs"`
+	Utxos            []*TxInput  `json:"utxos"`
+	FeeRate          string      `json:"fee_rate"`
+	ChangeAddress    string      `json:"change_address"`
+	MinConfirmations uint64      `json:"min_confirmations"`
+	LockTime         uint32      `json:"lock_time"`
+	DisableRbf       bool        `json:"disable_rbf"`
+}
+
+type DecodedInput struct {
+	TxId     string `json:"tx_id"`
+	Index    uint32 `json:"index"`
+	Sequence uint32 `json:"sequence"`
+	// PSBT 或可查询到前序交易时填写
+	Address string `json:"address,omitempty"`
+	Value   string `json:"value,omitempty"`
+}
+
+type DecodedOutput struct {
+	Address string `json:"address,omitempty"`
+	Value   string `json:"value"`
+	Script  string `json:"script"`
+}
+
+// DecodedTx DecodeTransaction 的输出, 金额单位 satoshi
+type DecodedTx struct {
+	TxId     string           `json:"tx_id"`
+	Version  int32            `json:"version"`
+	LockTime uint32           `json:"lock_time"`
+	Inputs   []*DecodedInput  `json:"inputs"`
+	Outputs  []*DecodedOutput `json:"outputs"`
+	Fee      string           `json:"fee,omitempty"`
+	VSize    int64            `json:"vsize"`
+	Signed   bool             `json:"signed"`
+}
+
+func decodeTxStructure(base64Tx string) (*TxStructure, error) {
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var txStructure TxStructure
+	if err := json.Unmarshal(txJson, &txStructure); err != nil {
+		return nil, err
+	}
+	return &txStructure, nil
+}
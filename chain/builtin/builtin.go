@@ -0,0 +1,16 @@
+// Package builtin 导入仓库内置的全部链适配器, 各适配器在 init() 中通过 chain.Register 注册
+// 外部模块中的链以同样方式空导入即可被调度器加载
+package builtin
+
+import (
+	_ "github.com/CavnHan/wallet-chain-account/chain/aptos"
+	_ "github.com/CavnHan/wallet-chain-account/chain/arweave"
+	_ "github.com/CavnHan/wallet-chain-account/chain/bitcoin"
+	_ "github.com/CavnHan/wallet-chain-account/chain/cosmos"
+	_ "github.com/CavnHan/wallet-chain-account/chain/ethereum"
+	_ "github.com/CavnHan/wallet-chain-account/chain/mantle"
+	_ "github.com/CavnHan/wallet-chain-account/chain/solana"
+	_ "github.com/CavnHan/wallet-chain-account/chain/sui"
+	_ "github.com/CavnHan/wallet-chain-account/chain/ton"
+	_ "github.com/CavnHan/wallet-chain-account/chain/tron"
+)
@@ -1,6 +1,10 @@
 package chain
 
-import "github.com/CavnHan/wallet-chain-account/rpc/account"
+import (
+	"context"
+
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+)
 
 type IChainAdaptor interface {
 	GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error)
@@ -22,3 +26,51 @@ type IChainAdaptor interface {
 	VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error)
 	GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error)
 }
+
+// IUtxoAdaptor UTXO 模型的链额外实现的接口, 账户模型的链不需要实现
+type IUtxoAdaptor interface {
+	GetUnspentOutputs(req *account.UnspentOutputsRequest) (*account.UnspentOutputsResponse, error)
+}
+
+// IReorgAdaptor 能追踪规范链并回溯分叉点的链实现
+type IReorgAdaptor interface {
+	DetectReorg(req *account.ReorgRequest) (*account.ReorgResponse, error)
+}
+
+// ITransferLogAdaptor 能按区块范围查询代币转账事件的链实现
+type ITransferLogAdaptor interface {
+	GetTransferLogs(req *account.TransferLogsRequest) (*account.TransferLogsResponse, error)
+}
+
+// INftAdaptor 支持 ERC-721/1155 NFT 的链实现
+type INftAdaptor interface {
+	GetNfts(req *account.NftsRequest) (*account.NftsResponse, error)
+	GetNftMetadata(req *account.NftMetadataRequest) (*account.NftMetadataResponse, error)
+	BuildNftTransfer(req *account.NftTransferRequest) (*account.NftTransferResponse, error)
+}
+
+// IBatchBalanceAdaptor 支持在同一区块批量查询余额的链实现
+type IBatchBalanceAdaptor interface {
+	GetBalances(req *account.BalancesRequest) (*account.BalancesResponse, error)
+}
+
+// INonceAdaptor 支持预留账户 nonce 的链实现, 供并发构造交易时避免 nonce 冲突
+type INonceAdaptor interface {
+	ReserveNonce(req *account.ReserveNonceRequest) (*account.ReserveNonceResponse, error)
+	ReleaseNonce(req *account.ReleaseNonceRequest) (*account.ReleaseNonceResponse, error)
+}
+
+// IBlockSubscriber 支持推送新区块的链实现, 推送到 ctx 结束或 send 失败为止
+type IBlockSubscriber interface {
+	SubscribeBlocks(ctx context.Context, req *account.SubscribeBlocksRequest, send func(*account.BlockEvent) error) error
+}
+
+// IAddressWatcher 支持推送监听地址转账的链实现, 推送到 ctx 结束或 send 失败为止
+type IAddressWatcher interface {
+	WatchAddresses(ctx context.Context, req *account.WatchAddressesRequest, send func(*account.TransferEvent) error) error
+}
+
+// IClosableAdaptor 持有连接等资源的适配器实现, 热加载替换后在请求处理完毕时调用
+type IClosableAdaptor interface {
+	Close()
+}
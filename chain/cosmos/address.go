@@ -0,0 +1,61 @@
+package cosmos
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/ripemd160"
+
+	"github.com/CavnHan/wallet-chain-account/common/bech32"
+)
+
+const (
+	// 普通账户地址为 20 字节, 模块账户与 ICA 账户为 32 字节
+	accountAddressLength = 20
+	moduleAddressLength  = 32
+)
+
+var errInvalidAddress = errors.New("invalid cosmos address")
+
+// PubKeyToAddress 地址为 ripemd160(sha256(压缩公钥)) 的 bech32 编码
+func PubKeyToAddress(publicKey []byte, prefix string) (string, error) {
+	compressed, err := compressPubKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	sha := sha256.Sum256(compressed)
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+	return bech32.EncodeFromBase256(prefix, hasher.Sum(nil))
+}
+
+// DecodeAddress 校验地址前缀, 返回原始地址字节
+func DecodeAddress(address, prefix string) ([]byte, error) {
+	hrp, raw, err := bech32.DecodeToBase256(address)
+	if err != nil {
+		return nil, err
+	}
+	if hrp != prefix || (len(raw) != accountAddressLength && len(raw) != moduleAddressLength) {
+		return nil, errInvalidAddress
+	}
+	return raw, nil
+}
+
+func compressPubKey(publicKey []byte) ([]byte, error) {
+	switch len(publicKey) {
+	case 33:
+		if _, err := crypto.DecompressPubkey(publicKey); err != nil {
+			return nil, err
+		}
+		return publicKey, nil
+	case 65:
+		pub, err := crypto.UnmarshalPubkey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.CompressPubkey(pub), nil
+	default:
+		return nil, errors.New("invalid secp256k1 public key")
+	}
+}
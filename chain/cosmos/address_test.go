@@ -0,0 +1,62 @@
+package cosmos
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// cosmjs 测试水龙头账户
+const (
+	testPublicKey = "034f04181eeba35391b858633a765c4a0c189697b40d216354d50890d350c70290"
+	testAddress   = "cosmos1pkptre7fdkl6gfrzlesjjvhxhlc3r4gmmk8rs6"
+	// 地址字节为 0x01..0x14
+	testToAddress = "cosmos1qypqxpq9qcrsszg2pvxq6rs0zqg3yyc5lzv7xu"
+)
+
+func TestPubKeyToAddress(t *testing.T) {
+	compressed, _ := hex.DecodeString(testPublicKey)
+	pub, err := crypto.DecompressPubkey(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		key     []byte
+		prefix  string
+		want    string
+		wantErr bool
+	}{
+		{"compressed", compressed, "cosmos", testAddress, false},
+		{"uncompressed", crypto.FromECDSAPub(pub), "cosmos", testAddress, false},
+		{"osmosis prefix", compressed, "osmo", "osmo1pkptre7fdkl6gfrzlesjjvhxhlc3r4gmnd5nxg", false},
+		{"short key", compressed[:32], "cosmos", "", true},
+	}
+	for _, tt := range tests {
+		got, err := PubKeyToAddress(tt.key, tt.prefix)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: got %s, %v, want %s", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		prefix  string
+		want    string
+		wantErr bool
+	}{
+		{testToAddress, "cosmos", "0102030405060708090a0b0c0d0e0f1011121314", false},
+		{testToAddress, "osmo", "", true},
+		// 校验和错误
+		{testToAddress[:len(testToAddress)-1] + "a", "cosmos", "", true},
+	}
+	for _, tt := range tests {
+		raw, err := DecodeAddress(tt.address, tt.prefix)
+		if (err != nil) != tt.wantErr || hex.EncodeToString(raw) != tt.want {
+			t.Errorf("%s (%s): got %x, %v, want %s", tt.address, tt.prefix, raw, err, tt.want)
+		}
+	}
+}
@@ -1 +1,884 @@
 package cosmos
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	ChainName = "Cosmos"
+
+	defaultTxPageSize = 20
+)
+
+// 三档手续费相对配置 gas 价格的倍数 (百分比)
+var feeMultipliers = [3]int64{100, 125, 150}
+
+type ChainAdaptor struct {
+	lcdClient    *LcdClient
+	bech32Prefix string
+	denom        string
+	gasPrice     *big.Rat
+
+	chainIdOnce sync.Once
+	chainId     string
+}
+
+// Params 每个 Cosmos SDK 链 (zone) 的地址前缀, 原生代币和 gas 价格不同, 需单独配置
+// ChainId 为空时从最新区块头读取
+type Params struct {
+	ChainId      string `yaml:"chain_id"`
+	Bech32Prefix string `yaml:"bech32_prefix"`
+	Denom        string `yaml:"denom"`
+	GasPrice     string `yaml:"gas_price"`
+}
+
+func init() {
+	chain.Register(ChainName, NewChainAdaptor)
+	config.RegisterChainValidator(ChainName, func(node *config.ChainConfig) error {
+		_, _, err := decodeParams(node)
+		return err
+	})
+}
+
+func decodeParams(node *config.ChainConfig) (*Params, *big.Rat, error) {
+	var params Params
+	if err := node.DecodeParams(&params); err != nil {
+		return nil, nil, fmt.Errorf("params: %w", err)
+	}
+	if params.Bech32Prefix == "" || params.Denom == "" {
+		return nil, nil, errors.New("params: bech32_prefix and denom must be configured")
+	}
+	gasPrice, ok := new(big.Rat).SetString(params.GasPrice)
+	if !ok || gasPrice.Sign() < 0 {
+		return nil, nil, fmt.Errorf("params: invalid gas_price %q", params.GasPrice)
+	}
+	return &params, gasPrice, nil
+}
+
+func NewChainAdaptor(conf *config.Config) (chain.IChainAdaptor, error) {
+	node, ok := conf.Chain(ChainName)
+	if !ok {
+		return nil, errors.New("cosmos rpc not configured")
+	}
+	params, gasPrice, err := decodeParams(node)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		lcdClient:    NewLcdClient(node.RPCs[0].RPCURL, node.TimeOut.Duration()),
+		bech32Prefix: params.Bech32Prefix,
+		denom:        params.Denom,
+		gasPrice:     gasPrice,
+		chainId:      params.ChainId,
+	}, nil
+}
+
+// getChainId 未配置 chain_id 时从最新区块头读取一次
+func (c *ChainAdaptor) getChainId() (string, error) {
+	c.chainIdOnce.Do(func() {
+		if c.chainId != "" {
+			return
+		}
+		block, err := c.lcdClient.GetLatestBlock()
+		if err != nil {
+			log.Error("get cosmos chain id fail", "err", err)
+			return
+		}
+		c.chainId = block.Block.Header.ChainID
+	})
+	if c.chainId == "" {
+		return "", errors.New("cosmos chain id unavailable")
+	}
+	return c.chainId, nil
+}
+
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	address, err := PubKeyToAddress(req.PublicKey, c.bech32Prefix)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: address,
+	}, nil
+}
+
+// ValidAddress 32 字节的模块账户与 ICA 账户没有私钥, 不能作为提现地址
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	raw, err := DecodeAddress(req.Address, c.bech32Prefix)
+	if err != nil {
+		return &account.ValidAddressResponse{
+			Code:  common.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:             common.ReturnCode_SUCCESS,
+		Msg:              "valid address",
+		Valid:            true,
+		CanWithdrawal:    len(raw) == accountAddressLength,
+		CanonicalAddress: strings.ToLower(req.Address),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	block, err := c.getBlock(req.Height)
+	if err != nil {
+		log.Error("get block by number fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	height, _ := strconv.ParseInt(block.Block.Header.Height, 10, 64)
+	var txList []*account.BlockInfoTransactionList
+	if req.ViewTx {
+		for _, tx := range block.Block.Data.Txs {
+			txBytes, err := base64.StdEncoding.DecodeString(tx)
+			if err != nil {
+				continue
+			}
+			item := &account.BlockInfoTransactionList{
+				Hash: txHash(txBytes),
+				Time: strconv.FormatInt(block.Block.Header.Time.Unix(), 10),
+			}
+			if txStructure, err := decodeTxRaw(txBytes); err == nil {
+				item.From = txStructure.FromAddress
+				item.To = txStructure.ToAddress
+				item.Amount = txStructure.Value
+				item.Fee = txStructure.FeeAmount
+			}
+			txList = append(txList, item)
+		}
+	}
+	return &account.BlockResponse{
+		Code:         common.ReturnCode_SUCCESS,
+		Msg:          "get block by number success",
+		Height:       height,
+		Hash:         base64ToHex(block.BlockID.Hash),
+		Transactions: txList,
+	}, nil
+}
+
+// GetBlockByHash LCD 不提供按区块哈希查询的接口
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return &account.BlockResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "cosmos does not support get block by hash",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	return &account.BlockHeaderResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "cosmos does not support get block header by hash",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	block, err := c.getBlock(req.Height)
+	if err != nil {
+		log.Error("get block header by number fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	header := block.Block.Header
+	return &account.BlockHeaderResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get block header by number success",
+		BlockHeader: &account.BlockHeader{
+			ParentHash: base64ToHex(header.LastBlockID.Hash),
+			CoinBase:   base64ToHex(header.ProposerAddress),
+			Root:       base64ToHex(header.AppHash),
+			TxHash:     base64ToHex(header.DataHash),
+			Number:     header.Height,
+			Time:       uint64(header.Time.Unix()),
+		},
+	}, nil
+}
+
+func (c *ChainAdaptor) getBlock(height int64) (*Block, error) {
+	if height == 0 {
+		return c.lcdClient.GetLatestBlock()
+	}
+	return c.lcdClient.GetBlockByHeight(height)
+}
+
+/**
+ * @description: 查询账户余额及 account_number/sequence
+ * 合约地址填 denom (如 IBC denom), 为空时查询配置的原生代币; 链上不存在的账户返回 0
+ */
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	denom := req.ContractAddress
+	if denom == "" {
+		denom = c.denom
+	}
+	accountNumber, sequence, err := c.accountNumberAndSequence(req.Address)
+	if err != nil {
+		log.Error("get account fail", "err", err)
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "get account fail",
+			Balance: "0",
+		}, nil
+	}
+	balance, err := c.lcdClient.GetBalance(req.Address, denom)
+	if err != nil {
+		log.Error("get balance by address fail", "err", err)
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "get balance by address fail",
+			Balance: "0",
+		}, nil
+	}
+	amount := balance.Amount
+	if amount == "" {
+		amount = "0"
+	}
+	return &account.AccountResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: strconv.FormatUint(accountNumber, 10),
+		Sequence:      strconv.FormatUint(sequence, 10),
+		Network:       ChainName,
+		Balance:       amount,
+	}, nil
+}
+
+func (c *ChainAdaptor) accountNumberAndSequence(address string) (uint64, uint64, error) {
+	acc, err := c.lcdClient.GetAccount(address)
+	if errors.Is(err, ErrNotFound) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	base := acc.Base()
+	accountNumber, err := strconv.ParseUint(base.AccountNumber, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	sequence, err := strconv.ParseUint(base.Sequence, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return accountNumber, sequence, nil
+}
+
+/**
+ * @description: 估算手续费, 单位为配置 denom 的最小单位
+ * rawTx 为 base64(json) 的 TxStructure, 为空时按 req.Address 的原生代币转账估算
+ * gas 通过 simulate 得到并乘以调整系数, 三档手续费为 gas * gas_price 的 1/1.25/1.5 倍
+ */
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	txStructure := &TxStructure{FromAddress: req.Address, ToAddress: req.Address, Value: "1"}
+	if req.RawTx != "" {
+		decoded, err := decodeTxStructure(req.RawTx)
+		if err != nil {
+			log.Error("decode tx structure fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "decode tx structure fail",
+			}, nil
+		}
+		txStructure = decoded
+	}
+	gasLimit := txStructure.GasLimit
+	if gasLimit == 0 {
+		var err error
+		gasLimit, err = c.simulateGas(txStructure)
+		if err != nil {
+			log.Error("simulate tx fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "simulate tx fail",
+			}, nil
+		}
+	}
+	fee := func(multiplier int64) string {
+		price := new(big.Rat).Mul(c.gasPrice, big.NewRat(multiplier, 100))
+		return c.feeAmount(gasLimit, price)
+	}
+	return &account.FeeResponse{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee(feeMultipliers[0]),
+		NormalFee: fee(feeMultipliers[1]),
+		FastFee:   fee(feeMultipliers[2]),
+	}, nil
+}
+
+// feeAmount gas * price 向上取整
+func (c *ChainAdaptor) feeAmount(gasLimit uint64, price *big.Rat) string {
+	total := new(big.Rat).Mul(new(big.Rat).SetInt(new(big.Int).SetUint64(gasLimit)), price)
+	quo, rem := new(big.Int).QuoRem(total.Num(), total.Denom(), new(big.Int))
+	if rem.Sign() > 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	return quo.String()
+}
+
+// simulateGas 用空签名的交易模拟执行, 返回乘以调整系数后的 gas limit
+func (c *ChainAdaptor) simulateGas(txStructure *TxStructure) (uint64, error) {
+	_, sequence, err := c.resolveAccount(txStructure)
+	if err != nil {
+		return 0, err
+	}
+	var publicKey []byte
+	if txStructure.PublicKey != "" {
+		if publicKey, err = c.senderPublicKey(txStructure); err != nil {
+			return 0, err
+		}
+	}
+	body, err := c.buildTxBody(txStructure)
+	if err != nil {
+		return 0, err
+	}
+	authInfo := &AuthInfo{
+		PublicKey: publicKey,
+		Sequence:  sequence,
+		FeeAmount: []Coin{{Denom: c.denom, Amount: "0"}},
+		GasLimit:  defaultGasLimit,
+	}
+	txRaw := &TxRaw{BodyBytes: body.Marshal(), AuthInfoBytes: authInfo.Marshal(), Signatures: [][]byte{{}}}
+	gasInfo, err := c.lcdClient.Simulate(base64.StdEncoding.EncodeToString(txRaw.Marshal()))
+	if err != nil {
+		return 0, err
+	}
+	gasUsed, err := strconv.ParseUint(gasInfo.GasUsed, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return gasUsed * gasAdjustmentNum / gasAdjustmentDen, nil
+}
+
+func (c *ChainAdaptor) resolveAccount(txStructure *TxStructure) (uint64, uint64, error) {
+	if txStructure.AccountNumber != "" && txStructure.Sequence != "" {
+		accountNumber, err := strconv.ParseUint(txStructure.AccountNumber, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		sequence, err := strconv.ParseUint(txStructure.Sequence, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		return accountNumber, sequence, nil
+	}
+	return c.accountNumberAndSequence(txStructure.FromAddress)
+}
+
+// senderPublicKey 校验公钥与发送地址一致, 返回压缩公钥
+func (c *ChainAdaptor) senderPublicKey(txStructure *TxStructure) ([]byte, error) {
+	publicKey, err := hex.DecodeString(strings.TrimPrefix(txStructure.PublicKey, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := compressPubKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	address, err := PubKeyToAddress(compressed, c.bech32Prefix)
+	if err != nil {
+		return nil, err
+	}
+	if address != txStructure.FromAddress {
+		return nil, errors.New("public key does not match from address")
+	}
+	return compressed, nil
+}
+
+func (c *ChainAdaptor) buildTxBody(txStructure *TxStructure) (*TxBody, error) {
+	if _, err := DecodeAddress(txStructure.FromAddress, c.bech32Prefix); err != nil {
+		return nil, err
+	}
+	if _, err := DecodeAddress(txStructure.ToAddress, c.bech32Prefix); err != nil {
+		return nil, err
+	}
+	amount, ok := new(big.Int).SetString(txStructure.Value, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, errors.New("invalid transfer value")
+	}
+	if len(txStructure.Memo) > maxMemoLength {
+		return nil, errors.New("memo too long")
+	}
+	denom := txStructure.Denom
+	if denom == "" {
+		denom = c.denom
+	}
+	return &TxBody{
+		Messages: []MsgSend{{
+			FromAddress: txStructure.FromAddress,
+			ToAddress:   txStructure.ToAddress,
+			Amount:      []Coin{{Denom: denom, Amount: amount.String()}},
+		}},
+		Memo: txStructure.Memo,
+	}, nil
+}
+
+// SendTx raw_tx 为 base64 编码的 TxRaw, CheckTx 失败时返回错误
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	txResponse, err := c.lcdClient.BroadcastTx(req.RawTx)
+	if err != nil {
+		log.Error("broadcast tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "broadcast tx fail",
+		}, nil
+	}
+	if txResponse.Code != 0 {
+		log.Error("broadcast tx rejected", "code", txResponse.Code, "codespace", txResponse.Codespace, "log", txResponse.RawLog)
+		return &account.SendTxResponse{
+			Code:   common.ReturnCode_ERROR,
+			Msg:    txResponse.RawLog,
+			TxHash: txResponse.TxHash,
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: txResponse.TxHash,
+	}, nil
+}
+
+/**
+ * @description: 查询地址交易记录
+ * 分别按 message.sender 与 transfer.recipient 查询后合并, Page 从 1 开始
+ */
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	page, pageSize := req.Page, req.Pagesize
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultTxPageSize
+	}
+	seen := make(map[string]bool)
+	var txResponses []TxResponse
+	for _, event := range []string{"message.sender", "transfer.recipient"} {
+		txs, err := c.lcdClient.SearchTxs(fmt.Sprintf("%s='%s'", event, req.Address), page, pageSize)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			log.Error("search txs fail", "event", event, "err", err)
+			return &account.TxAddressResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get tx by address fail",
+			}, nil
+		}
+		for _, tx := range txs {
+			if !seen[tx.TxHash] {
+				seen[tx.TxHash] = true
+				txResponses = append(txResponses, tx)
+			}
+		}
+	}
+	sort.SliceStable(txResponses, func(i, j int) bool {
+		hi, _ := strconv.ParseInt(txResponses[i].Height, 10, 64)
+		hj, _ := strconv.ParseInt(txResponses[j].Height, 10, 64)
+		return hi > hj
+	})
+	var txList []*account.TxMessage
+	for i := range txResponses {
+		txMessage := c.toTxMessage(&txResponses[i])
+		if req.ContractAddress != "" && txMessage.ContractAddress != req.ContractAddress {
+			continue
+		}
+		txList = append(txList, txMessage)
+	}
+	return &account.TxAddressResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txList,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	hash := strings.ToUpper(strings.TrimPrefix(req.Hash, "0x"))
+	txResponse, err := c.lcdClient.GetTx(hash)
+	if errors.Is(err, ErrNotFound) {
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_SUCCESS,
+			Msg:  "tx not found",
+			Tx:   &account.TxMessage{Hash: hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get tx by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}, nil
+	}
+	return &account.TxHashResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by hash success",
+		Tx:   c.toTxMessage(txResponse),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, errStart := strconv.ParseInt(req.Start, 10, 64)
+	end, errEnd := strconv.ParseInt(req.End, 10, 64)
+	if errStart != nil || errEnd != nil || start <= 0 || start > end {
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid block range",
+		}, nil
+	}
+	var blockList []*account.BlockData
+	for height := start; height <= end; height++ {
+		block, err := c.lcdClient.GetBlockByHeight(height)
+		if err != nil {
+			log.Error("get block fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block by range fail",
+			}, nil
+		}
+		var txList []*account.TxMessage
+		for _, tx := range block.Block.Data.Txs {
+			txBytes, err := base64.StdEncoding.DecodeString(tx)
+			if err != nil {
+				continue
+			}
+			txMessage := &account.TxMessage{Hash: txHash(txBytes), Height: block.Block.Header.Height}
+			if txStructure, err := decodeTxRaw(txBytes); err == nil {
+				txMessage.Froms = []*account.Address{{Address: txStructure.FromAddress}}
+				txMessage.Tos = []*account.Address{{Address: txStructure.ToAddress}}
+				txMessage.Values = []*account.Value{{Value: txStructure.Value}}
+				txMessage.Memo = txStructure.Memo
+				if txStructure.Denom != c.denom {
+					txMessage.ContractAddress = txStructure.Denom
+				}
+			}
+			txList = append(txList, txMessage)
+		}
+		blockList = append(blockList, &account.BlockData{
+			Hash:         base64ToHex(block.BlockID.Hash),
+			Transactions: txList,
+		})
+	}
+	return &account.BlockByRangeResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "get block by range success",
+		Blocks: blockList,
+	}, nil
+}
+
+/**
+ * @description: 创建未签名交易
+ * base64_tx 为 base64(json) 的 TxStructure, public_key 必填, 用于写入 AuthInfo
+ * 返回 base64 编码的 SignDoc, 签名对象为 sha256(SignDoc)
+ */
+func (c *ChainAdaptor) CreateUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txStructure, err := decodeTxStructure(req.Base64Tx)
+	if err != nil {
+		log.Error("decode tx structure fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode tx structure fail",
+		}, nil
+	}
+	signDoc, err := c.buildSignDoc(txStructure)
+	if err != nil {
+		log.Error("build sign doc fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "build sign doc fail",
+		}, nil
+	}
+	return &account.UnSignTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "create un sign transaction success",
+		UnSignTx: base64.StdEncoding.EncodeToString(signDoc.Marshal()),
+	}, nil
+}
+
+func (c *ChainAdaptor) buildSignDoc(txStructure *TxStructure) (*SignDoc, error) {
+	publicKey, err := c.senderPublicKey(txStructure)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.buildTxBody(txStructure)
+	if err != nil {
+		return nil, err
+	}
+	accountNumber, sequence, err := c.resolveAccount(txStructure)
+	if err != nil {
+		return nil, err
+	}
+	chainId := txStructure.ChainId
+	if chainId == "" {
+		if chainId, err = c.getChainId(); err != nil {
+			return nil, err
+		}
+	}
+	gasLimit := txStructure.GasLimit
+	if gasLimit == 0 {
+		if gasLimit, err = c.simulateGas(txStructure); err != nil {
+			return nil, err
+		}
+	}
+	feeAmount := txStructure.FeeAmount
+	if feeAmount == "" {
+		feeAmount = c.feeAmount(gasLimit, c.gasPrice)
+	}
+	authInfo := &AuthInfo{
+		PublicKey: publicKey,
+		Sequence:  sequence,
+		FeeAmount: []Coin{{Denom: c.denom, Amount: feeAmount}},
+		GasLimit:  gasLimit,
+	}
+	return &SignDoc{
+		BodyBytes:     body.Marshal(),
+		AuthInfoBytes: authInfo.Marshal(),
+		ChainId:       chainId,
+		AccountNumber: accountNumber,
+	}, nil
+}
+
+/**
+ * @description: 组装签名交易
+ * base64_tx 为 CreateUnSignTransaction 返回的 SignDoc, signature 为 64 字节 r||s 签名 (hex), 65 字节时忽略 v
+ * 返回 base64 编码的 TxRaw, 可直接用于 SendTx
+ */
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	signDocBytes, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	signDoc, err := UnmarshalSignDoc(signDocBytes)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode sign doc fail",
+		}, nil
+	}
+	authInfo, err := UnmarshalAuthInfo(signDoc.AuthInfoBytes)
+	if err != nil || len(authInfo.PublicKey) == 0 {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode auth info fail",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || (len(signature) != crypto.SignatureLength-1 && len(signature) != crypto.SignatureLength) {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	signature = signature[:crypto.SignatureLength-1]
+	hash := sha256.Sum256(signDocBytes)
+	if !crypto.VerifySignature(authInfo.PublicKey, hash[:], signature) {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "verify signature fail",
+		}, nil
+	}
+	txRaw := &TxRaw{
+		BodyBytes:     signDoc.BodyBytes,
+		AuthInfoBytes: signDoc.AuthInfoBytes,
+		Signatures:    [][]byte{signature},
+	}
+	return &account.SignedTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "build signed transaction success",
+		SignedTx: base64.StdEncoding.EncodeToString(txRaw.Marshal()),
+	}, nil
+}
+
+// DecodeTransaction raw_tx 为 base64 编码的 TxRaw, 返回 base64(json) 的 TxStructure
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	txBytes, err := base64.StdEncoding.DecodeString(req.RawTx)
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	txStructure, err := decodeTxRaw(txBytes)
+	if err != nil {
+		log.Error("decode tx raw fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode tx raw fail",
+		}, nil
+	}
+	txJson, _ := json.Marshal(txStructure)
+	return &account.DecodeTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "decode transaction success",
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "cosmos does not support verify signed transaction",
+	}, nil
+}
+
+// GetExtraData 返回离线签名所需的 chain_id, account_number 与 sequence (json)
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	chainId, err := c.getChainId()
+	if err != nil {
+		log.Error("get chain id fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get chain id fail",
+		}, nil
+	}
+	accountNumber, sequence, err := c.accountNumberAndSequence(req.Address)
+	if err != nil {
+		log.Error("get account fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	value, _ := json.Marshal(map[string]string{
+		"chain_id":       chainId,
+		"account_number": strconv.FormatUint(accountNumber, 10),
+		"sequence":       strconv.FormatUint(sequence, 10),
+	})
+	return &account.ExtraDataResponse{
+		Code:  common.ReturnCode_SUCCESS,
+		Msg:   "get extra data success",
+		Value: string(value),
+	}, nil
+}
+
+func (c *ChainAdaptor) toTxMessage(txResponse *TxResponse) *account.TxMessage {
+	txMessage := &account.TxMessage{
+		Hash:   txResponse.TxHash,
+		Height: txResponse.Height,
+		Status: account.TxStatus_Success,
+	}
+	if txResponse.Code != 0 {
+		txMessage.Status = account.TxStatus_Failed
+	}
+	if t, err := time.Parse(time.RFC3339, txResponse.Timestamp); err == nil {
+		txMessage.Datetime = strconv.FormatInt(t.Unix(), 10)
+	}
+	if txResponse.Tx == nil {
+		return txMessage
+	}
+	txMessage.Memo = txResponse.Tx.Body.Memo
+	for _, coin := range txResponse.Tx.AuthInfo.Fee.Amount {
+		if coin.Denom == c.denom {
+			txMessage.Fee = coin.Amount
+		}
+	}
+	for _, msg := range txResponse.Tx.Body.Messages {
+		if msg.Type != MsgSendTypeUrl {
+			continue
+		}
+		for _, coin := range msg.Amount {
+			txMessage.Froms = append(txMessage.Froms, &account.Address{Address: msg.FromAddress})
+			txMessage.Tos = append(txMessage.Tos, &account.Address{Address: msg.ToAddress})
+			txMessage.Values = append(txMessage.Values, &account.Value{Value: coin.Amount})
+			if coin.Denom != c.denom {
+				txMessage.ContractAddress = coin.Denom
+			}
+		}
+	}
+	return txMessage
+}
+
+func decodeTxStructure(base64Tx string) (*TxStructure, error) {
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var txStructure TxStructure
+	if err := json.Unmarshal(txJson, &txStructure); err != nil {
+		return nil, err
+	}
+	return &txStructure, nil
+}
+
+// decodeTxRaw 解析 TxRaw 中的第一条 MsgSend
+func decodeTxRaw(txBytes []byte) (*TxStructure, error) {
+	txRaw, err := UnmarshalTxRaw(txBytes)
+	if err != nil {
+		return nil, err
+	}
+	body, err := UnmarshalTxBody(txRaw.BodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(body.Messages) == 0 || len(body.Messages[0].Amount) == 0 {
+		return nil, errors.New("no MsgSend found in tx")
+	}
+	authInfo, err := UnmarshalAuthInfo(txRaw.AuthInfoBytes)
+	if err != nil {
+		return nil, err
+	}
+	msg := body.Messages[0]
+	txStructure := &TxStructure{
+		FromAddress: msg.FromAddress,
+		ToAddress:   msg.ToAddress,
+		Value:       msg.Amount[0].Amount,
+		Denom:       msg.Amount[0].Denom,
+		Memo:        body.Memo,
+		PublicKey:   hex.EncodeToString(authInfo.PublicKey),
+		Sequence:    strconv.FormatUint(authInfo.Sequence, 10),
+		GasLimit:    authInfo.GasLimit,
+	}
+	if len(authInfo.FeeAmount) > 0 {
+		txStructure.FeeAmount = authInfo.FeeAmount[0].Amount
+	}
+	return txStructure, nil
+}
+
+// txHash Tendermint 交易哈希为 TxRaw 字节的 sha256, 大写 hex
+func txHash(txBytes []byte) string {
+	hash := sha256.Sum256(txBytes)
+	return strings.ToUpper(hex.EncodeToString(hash[:]))
+}
+
+func base64ToHex(value string) string {
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return value
+	}
+	return strings.ToUpper(hex.EncodeToString(raw))
+}
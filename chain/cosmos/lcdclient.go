@@ -0,0 +1,278 @@
+package cosmos
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	defaultRequestTimeout = 10 * time.Second
+
+	broadcastModeSync = "BROADCAST_MODE_SYNC"
+)
+
+var ErrNotFound = errors.New("cosmos lcd resource not found")
+
+type Coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+type BaseAccount struct {
+	Address       string `json:"address"`
+	AccountNumber string `json:"account_number"`
+	Sequence      string `json:"sequence"`
+}
+
+// Account 兼容 BaseAccount 与各类 vesting 账户
+type Account struct {
+	Type string `json:"@type"`
+	BaseAccount
+	BaseVestingAccount *struct {
+		BaseAccount BaseAccount `json:"base_account"`
+	} `json:"base_vesting_account"`
+}
+
+func (a *Account) Base() BaseAccount {
+	if a.BaseVestingAccount != nil {
+		return a.BaseVestingAccount.BaseAccount
+	}
+	return a.BaseAccount
+}
+
+type BlockHeader struct {
+	ChainID     string    `json:"chain_id"`
+	Height      string    `json:"height"`
+	Time        time.Time `json:"time"`
+	LastBlockID struct {
+		Hash string `json:"hash"`
+	} `json:"last_block_id"`
+	DataHash        string `json:"data_hash"`
+	AppHash         string `json:"app_hash"`
+	ProposerAddress string `json:"proposer_address"`
+}
+
+// Block hash 字段均为 base64 编码, txs 为 base64 编码的 TxRaw
+type Block struct {
+	BlockID struct {
+		Hash string `json:"hash"`
+	} `json:"block_id"`
+	Block struct {
+		Header BlockHeader `json:"header"`
+		Data   struct {
+			Txs []string `json:"txs"`
+		} `json:"data"`
+	} `json:"block"`
+}
+
+type TxMessage struct {
+	Type        string `json:"@type"`
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	Amount      []Coin `json:"amount"`
+}
+
+type Tx struct {
+	Body struct {
+		Messages []TxMessage `json:"messages"`
+		Memo     string      `json:"memo"`
+	} `json:"body"`
+	AuthInfo struct {
+		Fee struct {
+			Amount   []Coin `json:"amount"`
+			GasLimit string `json:"gas_limit"`
+		} `json:"fee"`
+	} `json:"auth_info"`
+}
+
+type TxResponse struct {
+	Height    string `json:"height"`
+	TxHash    string `json:"txhash"`
+	Codespace string `json:"codespace"`
+	Code      uint32 `json:"code"`
+	RawLog    string `json:"raw_log"`
+	GasWanted string `json:"gas_wanted"`
+	GasUsed   string `json:"gas_used"`
+	Tx        *Tx    `json:"tx"`
+	Timestamp string `json:"timestamp"`
+}
+
+type GasInfo struct {
+	GasWanted string `json:"gas_wanted"`
+	GasUsed   string `json:"gas_used"`
+}
+
+type lcdError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// LcdClient Cosmos SDK REST (LCD / grpc-gateway) 客户端
+type LcdClient struct {
+	baseUrl    string
+	httpClient *http.Client
+}
+
+func NewLcdClient(baseUrl string, timeout time.Duration) *LcdClient {
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &LcdClient{
+		baseUrl:    strings.TrimRight(baseUrl, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *LcdClient) do(method, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseUrl+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var lcdErr lcdError
+		_ = json.Unmarshal(data, &lcdErr)
+		// grpc NotFound 状态码为 5
+		if resp.StatusCode == http.StatusNotFound || lcdErr.Code == 5 {
+			return ErrNotFound
+		}
+		return fmt.Errorf("cosmos lcd %s status %d: %s", path, resp.StatusCode, string(data))
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		log.Error("unmarshal cosmos response fail", "path", path, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (c *LcdClient) get(path string, result interface{}) error {
+	return c.do(http.MethodGet, path, nil, result)
+}
+
+func (c *LcdClient) post(path string, body interface{}, result interface{}) error {
+	return c.do(http.MethodPost, path, body, result)
+}
+
+// GetAccount 账户从未收到过转账时链上不存在, 返回 ErrNotFound
+func (c *LcdClient) GetAccount(address string) (*Account, error) {
+	var result struct {
+		Account Account `json:"account"`
+	}
+	if err := c.get("/cosmos/auth/v1beta1/accounts/"+address, &result); err != nil {
+		return nil, err
+	}
+	return &result.Account, nil
+}
+
+func (c *LcdClient) GetBalance(address, denom string) (*Coin, error) {
+	var result struct {
+		Balance Coin `json:"balance"`
+	}
+	path := fmt.Sprintf("/cosmos/bank/v1beta1/balances/%s/by_denom?denom=%s", address, url.QueryEscape(denom))
+	if err := c.get(path, &result); err != nil {
+		return nil, err
+	}
+	return &result.Balance, nil
+}
+
+func (c *LcdClient) GetLatestBlock() (*Block, error) {
+	var block Block
+	if err := c.get("/cosmos/base/tendermint/v1beta1/blocks/latest", &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (c *LcdClient) GetBlockByHeight(height int64) (*Block, error) {
+	var block Block
+	if err := c.get("/cosmos/base/tendermint/v1beta1/blocks/"+strconv.FormatInt(height, 10), &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// Simulate txBytes 为 base64 编码的 TxRaw, 签名可为空
+func (c *LcdClient) Simulate(txBytes string) (*GasInfo, error) {
+	var result struct {
+		GasInfo GasInfo `json:"gas_info"`
+	}
+	if err := c.post("/cosmos/tx/v1beta1/simulate", map[string]interface{}{"tx_bytes": txBytes}, &result); err != nil {
+		return nil, err
+	}
+	return &result.GasInfo, nil
+}
+
+// BroadcastTx 同步模式广播, 返回 CheckTx 结果
+func (c *LcdClient) BroadcastTx(txBytes string) (*TxResponse, error) {
+	var result struct {
+		TxResponse TxResponse `json:"tx_response"`
+	}
+	body := map[string]interface{}{"tx_bytes": txBytes, "mode": broadcastModeSync}
+	if err := c.post("/cosmos/tx/v1beta1/txs", body, &result); err != nil {
+		return nil, err
+	}
+	return &result.TxResponse, nil
+}
+
+func (c *LcdClient) GetTx(hash string) (*TxResponse, error) {
+	var result struct {
+		TxResponse TxResponse `json:"tx_response"`
+	}
+	if err := c.get("/cosmos/tx/v1beta1/txs/"+hash, &result); err != nil {
+		return nil, err
+	}
+	return &result.TxResponse, nil
+}
+
+// SearchTxs 按事件查询交易, page 从 1 开始, 按高度倒序
+// v0.50 起参数名为 query, 旧版本为 events, query 失败时回退
+func (c *LcdClient) SearchTxs(event string, page, limit uint32) ([]TxResponse, error) {
+	txs, err := c.searchTxs("query", event, page, limit)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return c.searchTxs("events", event, page, limit)
+	}
+	return txs, err
+}
+
+func (c *LcdClient) searchTxs(param, event string, page, limit uint32) ([]TxResponse, error) {
+	params := url.Values{}
+	params.Set(param, event)
+	params.Set("order_by", "ORDER_BY_DESC")
+	params.Set("page", strconv.FormatUint(uint64(page), 10))
+	params.Set("limit", strconv.FormatUint(uint64(limit), 10))
+	var result struct {
+		TxResponses []TxResponse `json:"tx_responses"`
+	}
+	if err := c.get("/cosmos/tx/v1beta1/txs?"+params.Encode(), &result); err != nil {
+		return nil, err
+	}
+	return result.TxResponses, nil
+}
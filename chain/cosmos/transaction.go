@@ -0,0 +1,335 @@
+package cosmos
+
+import (
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	MsgSendTypeUrl   = "/cosmos.bank.v1beta1.MsgSend"
+	PubKeyTypeUrl    = "/cosmos.crypto.secp256k1.PubKey"
+	signModeDirect   = 1
+	maxMemoLength    = 256
+	defaultGasLimit  = 200000
+	gasAdjustmentNum = 13
+	gasAdjustmentDen = 10
+)
+
+var errInvalidTx = errors.New("invalid cosmos transaction")
+
+// TxStructure CreateUnSignTransaction 入参及 DecodeTransaction 出参, 以 base64(json) 传递
+// Denom 为空时使用配置的原生代币, 可填 IBC denom; PublicKey 为发送方 secp256k1 公钥 (hex)
+// AccountNumber/Sequence 为空时从链上查询; GasLimit/FeeAmount 为空时通过 simulate 估算
+type TxStructure struct {
+	ChainId       string `json:"chain_id,omitempty"`
+	FromAddress   string `json:"from_address"`
+	ToAddress     string `json:"to_address"`
+	Value         string `json:"value"`
+	Denom         string `json:"denom,omitempty"`
+	Memo          string `json:"memo,omitempty"`
+	PublicKey     string `json:"public_key,omitempty"`
+	AccountNumber string `json:"account_number,omitempty"`
+	Sequence      string `json:"sequence,omitempty"`
+	GasLimit      uint64 `json:"gas_limit,omitempty"`
+	FeeAmount     string `json:"fee_amount,omitempty"`
+}
+
+// SignDoc SIGN_MODE_DIRECT 签名对象, 签名为 sha256(SignDoc) 的 64 字节 secp256k1 签名 (r||s)
+type SignDoc struct {
+	BodyBytes     []byte
+	AuthInfoBytes []byte
+	ChainId       string
+	AccountNumber uint64
+}
+
+type TxRaw struct {
+	BodyBytes     []byte
+	AuthInfoBytes []byte
+	Signatures    [][]byte
+}
+
+type MsgSend struct {
+	FromAddress string
+	ToAddress   string
+	Amount      []Coin
+}
+
+type TxBody struct {
+	Messages []MsgSend
+	Memo     string
+}
+
+type AuthInfo struct {
+	PublicKey []byte
+	Sequence  uint64
+	FeeAmount []Coin
+	GasLimit  uint64
+}
+
+func appendBytesField(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendStringField(b []byte, num protowire.Number, v string) []byte {
+	return appendBytesField(b, num, []byte(v))
+}
+
+func appendVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func encodeCoin(coin Coin) []byte {
+	b := appendStringField(nil, 1, coin.Denom)
+	return appendStringField(b, 2, coin.Amount)
+}
+
+func encodeAny(typeUrl string, value []byte) []byte {
+	b := appendStringField(nil, 1, typeUrl)
+	return appendBytesField(b, 2, value)
+}
+
+func (m *MsgSend) Marshal() []byte {
+	b := appendStringField(nil, 1, m.FromAddress)
+	b = appendStringField(b, 2, m.ToAddress)
+	for _, coin := range m.Amount {
+		b = appendBytesField(b, 3, encodeCoin(coin))
+	}
+	return b
+}
+
+func (t *TxBody) Marshal() []byte {
+	var b []byte
+	for i := range t.Messages {
+		b = appendBytesField(b, 1, encodeAny(MsgSendTypeUrl, t.Messages[i].Marshal()))
+	}
+	return appendStringField(b, 2, t.Memo)
+}
+
+// Marshal 单签名者, PublicKey 为空时用于 simulate
+func (a *AuthInfo) Marshal() []byte {
+	var signerInfo []byte
+	if len(a.PublicKey) > 0 {
+		signerInfo = appendBytesField(signerInfo, 1, encodeAny(PubKeyTypeUrl, appendBytesField(nil, 1, a.PublicKey)))
+	}
+	single := appendVarintField(nil, 1, signModeDirect)
+	signerInfo = appendBytesField(signerInfo, 2, appendBytesField(nil, 1, single))
+	signerInfo = appendVarintField(signerInfo, 3, a.Sequence)
+
+	var fee []byte
+	for _, coin := range a.FeeAmount {
+		fee = appendBytesField(fee, 1, encodeCoin(coin))
+	}
+	fee = appendVarintField(fee, 2, a.GasLimit)
+
+	b := protowire.AppendTag(nil, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, signerInfo)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendBytes(b, fee)
+}
+
+func (s *SignDoc) Marshal() []byte {
+	b := appendBytesField(nil, 1, s.BodyBytes)
+	b = appendBytesField(b, 2, s.AuthInfoBytes)
+	b = appendStringField(b, 3, s.ChainId)
+	return appendVarintField(b, 4, s.AccountNumber)
+}
+
+func (t *TxRaw) Marshal() []byte {
+	b := appendBytesField(nil, 1, t.BodyBytes)
+	b = appendBytesField(b, 2, t.AuthInfoBytes)
+	for _, sig := range t.Signatures {
+		// 签名为空时仍需占位, simulate 按签名数量校验
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendBytes(b, sig)
+	}
+	return b
+}
+
+// rangeFields 遍历 protobuf 字段, 仅处理 varint 与 bytes 类型
+func rangeFields(data []byte, f func(num protowire.Number, typ protowire.Type, varint uint64, bytes []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return errInvalidTx
+		}
+		data = data[n:]
+		var varint uint64
+		var bytes []byte
+		switch typ {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			bytes, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return errInvalidTx
+		}
+		data = data[n:]
+		if err := f(num, typ, varint, bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func UnmarshalSignDoc(data []byte) (*SignDoc, error) {
+	signDoc := &SignDoc{}
+	err := rangeFields(data, func(num protowire.Number, typ protowire.Type, varint uint64, bytes []byte) error {
+		switch num {
+		case 1:
+			signDoc.BodyBytes = bytes
+		case 2:
+			signDoc.AuthInfoBytes = bytes
+		case 3:
+			signDoc.ChainId = string(bytes)
+		case 4:
+			signDoc.AccountNumber = varint
+		}
+		return nil
+	})
+	return signDoc, err
+}
+
+func UnmarshalTxRaw(data []byte) (*TxRaw, error) {
+	txRaw := &TxRaw{}
+	err := rangeFields(data, func(num protowire.Number, typ protowire.Type, varint uint64, bytes []byte) error {
+		switch num {
+		case 1:
+			txRaw.BodyBytes = bytes
+		case 2:
+			txRaw.AuthInfoBytes = bytes
+		case 3:
+			txRaw.Signatures = append(txRaw.Signatures, bytes)
+		}
+		return nil
+	})
+	if err != nil || len(txRaw.BodyBytes) == 0 {
+		return nil, errInvalidTx
+	}
+	return txRaw, nil
+}
+
+func unmarshalCoin(data []byte) (Coin, error) {
+	var coin Coin
+	err := rangeFields(data, func(num protowire.Number, typ protowire.Type, varint uint64, bytes []byte) error {
+		switch num {
+		case 1:
+			coin.Denom = string(bytes)
+		case 2:
+			coin.Amount = string(bytes)
+		}
+		return nil
+	})
+	return coin, err
+}
+
+func unmarshalAny(data []byte) (string, []byte, error) {
+	var typeUrl string
+	var value []byte
+	err := rangeFields(data, func(num protowire.Number, typ protowire.Type, varint uint64, bytes []byte) error {
+		switch num {
+		case 1:
+			typeUrl = string(bytes)
+		case 2:
+			value = bytes
+		}
+		return nil
+	})
+	return typeUrl, value, err
+}
+
+// UnmarshalTxBody 只解析 MsgSend, 其他类型的消息忽略
+func UnmarshalTxBody(data []byte) (*TxBody, error) {
+	body := &TxBody{}
+	err := rangeFields(data, func(num protowire.Number, typ protowire.Type, varint uint64, bytes []byte) error {
+		switch num {
+		case 1:
+			typeUrl, value, err := unmarshalAny(bytes)
+			if err != nil || typeUrl != MsgSendTypeUrl {
+				return err
+			}
+			var msg MsgSend
+			err = rangeFields(value, func(num protowire.Number, typ protowire.Type, varint uint64, bytes []byte) error {
+				switch num {
+				case 1:
+					msg.FromAddress = string(bytes)
+				case 2:
+					msg.ToAddress = string(bytes)
+				case 3:
+					coin, err := unmarshalCoin(bytes)
+					if err != nil {
+						return err
+					}
+					msg.Amount = append(msg.Amount, coin)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			body.Messages = append(body.Messages, msg)
+		case 2:
+			body.Memo = string(bytes)
+		}
+		return nil
+	})
+	return body, err
+}
+
+func UnmarshalAuthInfo(data []byte) (*AuthInfo, error) {
+	authInfo := &AuthInfo{}
+	err := rangeFields(data, func(num protowire.Number, typ protowire.Type, varint uint64, bytes []byte) error {
+		switch num {
+		case 1:
+			// 只取第一个签名者
+			if authInfo.PublicKey != nil || authInfo.Sequence != 0 {
+				return nil
+			}
+			return rangeFields(bytes, func(num protowire.Number, typ protowire.Type, varint uint64, bytes []byte) error {
+				switch num {
+				case 1:
+					_, value, err := unmarshalAny(bytes)
+					if err != nil {
+						return err
+					}
+					return rangeFields(value, func(num protowire.Number, typ protowire.Type, varint uint64, bytes []byte) error {
+						if num == 1 {
+							authInfo.PublicKey = bytes
+						}
+						return nil
+					})
+				case 3:
+					authInfo.Sequence = varint
+				}
+				return nil
+			})
+		case 2:
+			return rangeFields(bytes, func(num protowire.Number, typ protowire.Type, varint uint64, bytes []byte) error {
+				switch num {
+				case 1:
+					coin, err := unmarshalCoin(bytes)
+					if err != nil {
+						return err
+					}
+					authInfo.FeeAmount = append(authInfo.FeeAmount, coin)
+				case 2:
+					authInfo.GasLimit = varint
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return authInfo, err
+}
@@ -0,0 +1,94 @@
+package cosmos
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+// 期望值按 cosmos-sdk proto 定义独立编码: MsgSend 1234567uatom, 手续费 5000uatom, gas 200000, sequence 7
+const (
+	testBodyBytes     = "0a90010a1c2f636f736d6f732e62616e6b2e763162657461312e4d736753656e6412700a2d636f736d6f7331706b707472653766646b6c366766727a6c65736a6a766878686c63337234676d6d6b38727336122d636f736d6f7331717970717870713971637273737a673270767871367273307a716733797963356c7a763778751a100a057561746f6d120731323334353637121365786368616e6765206465706f736974203432"
+	testAuthInfoBytes = "0a500a460a1f2f636f736d6f732e63727970746f2e736563703235366b312e5075624b657912230a21034f04181eeba35391b858633a765c4a0c189697b40d216354d50890d350c7029012040a020801180712130a0d0a057561746f6d12043530303010c09a0c"
+	testSignDoc       = "0aa8010a90010a1c2f636f736d6f732e62616e6b2e763162657461312e4d736753656e6412700a2d636f736d6f7331706b707472653766646b6c366766727a6c65736a6a766878686c63337234676d6d6b38727336122d636f736d6f7331717970717870713971637273737a673270767871367273307a716733797963356c7a763778751a100a057561746f6d120731323334353637121365786368616e6765206465706f73697420343212670a500a460a1f2f636f736d6f732e63727970746f2e736563703235366b312e5075624b657912230a21034f04181eeba35391b858633a765c4a0c189697b40d216354d50890d350c7029012040a020801180712130a0d0a057561746f6d12043530303010c09a0c1a0b636f736d6f736875622d3420b960"
+)
+
+func testTx() (*TxBody, *AuthInfo) {
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	body := &TxBody{
+		Messages: []MsgSend{{FromAddress: testAddress, ToAddress: testToAddress, Amount: []Coin{{Denom: "uatom", Amount: "1234567"}}}},
+		Memo:     "exchange deposit 42",
+	}
+	authInfo := &AuthInfo{
+		PublicKey: publicKey,
+		Sequence:  7,
+		FeeAmount: []Coin{{Denom: "uatom", Amount: "5000"}},
+		GasLimit:  200000,
+	}
+	return body, authInfo
+}
+
+func TestSignDocMarshal(t *testing.T) {
+	body, authInfo := testTx()
+	if got := hex.EncodeToString(body.Marshal()); got != testBodyBytes {
+		t.Errorf("body = %s, want %s", got, testBodyBytes)
+	}
+	if got := hex.EncodeToString(authInfo.Marshal()); got != testAuthInfoBytes {
+		t.Errorf("auth info = %s, want %s", got, testAuthInfoBytes)
+	}
+	signDoc := &SignDoc{BodyBytes: body.Marshal(), AuthInfoBytes: authInfo.Marshal(), ChainId: "cosmoshub-4", AccountNumber: 12345}
+	if got := hex.EncodeToString(signDoc.Marshal()); got != testSignDoc {
+		t.Errorf("sign doc = %s, want %s", got, testSignDoc)
+	}
+}
+
+func TestSignDocUnmarshal(t *testing.T) {
+	raw, _ := hex.DecodeString(testSignDoc)
+	signDoc, err := UnmarshalSignDoc(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signDoc.ChainId != "cosmoshub-4" || signDoc.AccountNumber != 12345 {
+		t.Errorf("chain id %s, account number %d", signDoc.ChainId, signDoc.AccountNumber)
+	}
+	wantBody, wantAuthInfo := testTx()
+	body, err := UnmarshalTxBody(signDoc.BodyBytes)
+	if err != nil || !reflect.DeepEqual(body, wantBody) {
+		t.Errorf("body = %+v, %v, want %+v", body, err, wantBody)
+	}
+	authInfo, err := UnmarshalAuthInfo(signDoc.AuthInfoBytes)
+	if err != nil || !reflect.DeepEqual(authInfo, wantAuthInfo) {
+		t.Errorf("auth info = %+v, %v, want %+v", authInfo, err, wantAuthInfo)
+	}
+}
+
+func TestTxRawMarshal(t *testing.T) {
+	body, _ := hex.DecodeString(testBodyBytes)
+	authInfo, _ := hex.DecodeString(testAuthInfoBytes)
+	sig := make([]byte, 64)
+	sig[0] = 0xaa
+	tests := []struct {
+		name       string
+		signatures [][]byte
+		want       string
+	}{
+		{"signed", [][]byte{sig}, "0aa801" + testBodyBytes + "1267" + testAuthInfoBytes + "1a40" + hex.EncodeToString(sig)},
+		// simulate 使用的空签名仍占位
+		{"empty signature", [][]byte{nil}, "0aa801" + testBodyBytes + "1267" + testAuthInfoBytes + "1a00"},
+	}
+	for _, tt := range tests {
+		txRaw := &TxRaw{BodyBytes: body, AuthInfoBytes: authInfo, Signatures: tt.signatures}
+		encoded := txRaw.Marshal()
+		if hex.EncodeToString(encoded) != tt.want {
+			t.Errorf("%s: tx raw = %x, want %s", tt.name, encoded, tt.want)
+			continue
+		}
+		decoded, err := UnmarshalTxRaw(encoded)
+		if err != nil || len(decoded.Signatures) != 1 || len(decoded.Signatures[0]) != len(tt.signatures[0]) {
+			t.Errorf("%s: decoded %+v, %v", tt.name, decoded, err)
+		}
+	}
+	if _, err := UnmarshalTxRaw([]byte{0x0a, 0x05, 0x01}); err == nil {
+		t.Error("truncated tx raw decoded")
+	}
+}
@@ -0,0 +1,359 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	// MaxMulticallQueries 单次 aggregate3 调用最多包含的查询数, 过多时节点的 eth_call gas 上限或响应大小可能超限
+	MaxMulticallQueries = 500
+	// MaxBalanceQueries GetBalances 单次请求最多的 (地址, 代币) 数
+	MaxBalanceQueries = 10000
+	// Multicall3 不可用时每个 JSON-RPC 批量请求的调用数, 部分节点服务商限制批量大小
+	maxBatchCalls = 100
+)
+
+// Multicall3Address Multicall3 合约地址, 以太坊主网, 测试网与主流 L2 (含 Mantle) 上地址相同
+var Multicall3Address = ethcommon.HexToAddress("0xcA11bde05779ba9813c652A5f8cAb7F4f7da0b0f")
+
+var multicall3ABI = mustParseABI(`[
+	{"type":"function","name":"aggregate3","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}],"stateMutability":"payable"},
+	{"type":"function","name":"getEthBalance","inputs":[{"name":"addr","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}],"stateMutability":"view"}
+]`)
+
+// multicall3Call aggregate3 的参数, 字段名需与 ABI 的 components 对应
+type multicall3Call struct {
+	Target       ethcommon.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+/**
+ * @description: 解析区块标签, 返回值直接用于 eth_call/eth_getBalance 的区块参数
+ * 支持 latest (或空), pending, safe, finalized, earliest, 十进制高度与 0x 开头的十六进制高度
+ * @param tag 区块标签
+ */
+func ParseBlockTag(tag string) (*big.Int, error) {
+	switch strings.ToLower(tag) {
+	case "", "latest":
+		return nil, nil
+	case "pending":
+		return big.NewInt(int64(rpc.PendingBlockNumber)), nil
+	case "safe":
+		return big.NewInt(int64(rpc.SafeBlockNumber)), nil
+	case "finalized":
+		return big.NewInt(int64(rpc.FinalizedBlockNumber)), nil
+	case "earliest":
+		return new(big.Int), nil
+	}
+	if strings.HasPrefix(tag, "0x") || strings.HasPrefix(tag, "0X") {
+		height, err := hexutil.DecodeBig(strings.ToLower(tag))
+		if err != nil {
+			return nil, fmt.Errorf("invalid block tag %s", tag)
+		}
+		return height, nil
+	}
+	height, ok := new(big.Int).SetString(tag, 10)
+	if !ok || height.Sign() < 0 {
+		return nil, fmt.Errorf("invalid block tag %s", tag)
+	}
+	return height, nil
+}
+
+// TokenBalance ERC-20 balanceOf, blockNumber 为 ParseBlockTag 的结果
+func TokenBalance(client EthClient, token, owner ethcommon.Address, blockNumber *big.Int) (*big.Int, error) {
+	values, err := callView(client, token, blockNumber, method(erc20ABI, "balanceOf(address)"), owner)
+	if err != nil {
+		return nil, err
+	}
+	return values[0].(*big.Int), nil
+}
+
+// BalanceQuery 批量查询的一项, Token 为零地址时查询原生币余额
+type BalanceQuery struct {
+	Owner ethcommon.Address
+	Token ethcommon.Address
+}
+
+// BalanceResult 与 BalanceQuery 一一对应, 单项失败 (合约 revert 或不是 ERC-20) 时 Err 不为空
+type BalanceResult struct {
+	Balance *big.Int
+	Err     error
+}
+
+/**
+ * @description: 通过一次 Multicall3 aggregate3 调用查询多个 (地址, 代币) 的余额
+ * 原生币使用 Multicall3 的 getEthBalance, 代币使用 balanceOf; 单项失败不影响其他项
+ * Multicall3 在该高度尚未部署或节点请求失败时返回 error
+ * @param client 节点客户端
+ * @param queries 查询项, 最多 MaxMulticallQueries 个
+ * @param blockNumber 区块, 为 ParseBlockTag 的结果
+ */
+func MulticallBalances(client EthClient, queries []BalanceQuery, blockNumber *big.Int) ([]BalanceResult, error) {
+	if len(queries) == 0 {
+		return nil, nil
+	}
+	if len(queries) > MaxMulticallQueries {
+		return nil, fmt.Errorf("too many balance queries, max %d", MaxMulticallQueries)
+	}
+	calls := make([]multicall3Call, len(queries))
+	for i, query := range queries {
+		var (
+			data []byte
+			err  error
+		)
+		if query.Token == (ethcommon.Address{}) {
+			calls[i].Target = Multicall3Address
+			data, err = packCall(method(multicall3ABI, "getEthBalance(address)"), query.Owner)
+		} else {
+			calls[i].Target = query.Token
+			data, err = packCall(method(erc20ABI, "balanceOf(address)"), query.Owner)
+		}
+		if err != nil {
+			return nil, err
+		}
+		calls[i].AllowFailure = true
+		calls[i].CallData = data
+	}
+	aggregate := method(multicall3ABI, "aggregate3((address,bool,bytes)[])")
+	values, err := callView(client, Multicall3Address, blockNumber, aggregate, calls)
+	if errors.Is(err, errUnexpectedOutput) {
+		return nil, fmt.Errorf("multicall3 unavailable at block %s: %w", toBlockNumArg(blockNumber), err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	returned := *abi.ConvertType(values[0], new([]multicall3Result)).(*[]multicall3Result)
+	if len(returned) != len(queries) {
+		return nil, fmt.Errorf("multicall3 returned %d results for %d calls", len(returned), len(queries))
+	}
+	results := make([]BalanceResult, len(queries))
+	for i, r := range returned {
+		switch {
+		case !r.Success:
+			results[i].Err = errors.New("balance call reverted")
+		//调用没有代码的地址也会成功, 但没有返回值
+		case len(r.ReturnData) != 32:
+			results[i].Err = fmt.Errorf("unexpected balance output %x", r.ReturnData)
+		default:
+			results[i].Balance = new(big.Int).SetBytes(r.ReturnData)
+		}
+	}
+	return results, nil
+}
+
+/**
+ * @description: GetAccount 的余额查询, 与批量查询相同通过 Multicall3 读取, 合约 revert 或地址不是 ERC-20 时返回 error
+ * 该区块 Multicall3 不可用 (尚未部署) 时改用 eth_getBalance 或 balanceOf
+ * @param client 节点客户端
+ * @param owner 地址
+ * @param token 代币合约地址, 零地址表示原生币
+ * @param blockNumber 区块, 为 ParseBlockTag 的结果
+ */
+func AccountBalance(client EthClient, owner, token ethcommon.Address, blockNumber *big.Int) (*big.Int, error) {
+	results, err := MulticallBalances(client, []BalanceQuery{{Owner: owner, Token: token}}, blockNumber)
+	if errors.Is(err, errUnexpectedOutput) {
+		log.Debug("multicall3 unavailable, fall back to direct balance call", "block", toBlockNumArg(blockNumber), "err", err)
+		if token == (ethcommon.Address{}) {
+			return client.BalanceAt(owner, blockNumber)
+		}
+		return TokenBalance(client, token, owner, blockNumber)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results[0].Balance, results[0].Err
+}
+
+/**
+ * @description: 查询多个地址在同一区块的原生币与代币余额
+ * 先把 block_tag 解析为确定的区块 (不支持 pending), 所有查询都使用该高度, 查询结束后校验该高度的区块哈希未变化
+ * 按 MaxMulticallQueries 分组通过 Multicall3 查询, 该高度 Multicall3 不可用时改用 JSON-RPC 批量请求;
+ * 地址无效, 单项调用失败或整组请求失败时只在对应项中返回错误
+ * @param client 节点客户端
+ * @param req 查询请求, 返回 addresses 与 contract_addresses 的每个组合;
+ * contract_addresses 为空时只查询原生币, 其中的空字符串表示原生币
+ */
+func BalancesResponse(client EthClient, req *account.BalancesRequest) *account.BalancesResponse {
+	tokens := req.ContractAddresses
+	if len(tokens) == 0 {
+		tokens = []string{""}
+	}
+	if len(req.Addresses) == 0 {
+		return &account.BalancesResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "addresses is required",
+		}
+	}
+	if len(req.Addresses)*len(tokens) > MaxBalanceQueries {
+		return &account.BalancesResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  fmt.Sprintf("too many balance queries, max %d (addresses * contract_addresses)", MaxBalanceQueries),
+		}
+	}
+	if strings.EqualFold(req.BlockTag, "pending") {
+		return &account.BalancesResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "pending block tag is not supported",
+		}
+	}
+	blockNumber, err := ParseBlockTag(req.BlockTag)
+	if err != nil {
+		return &account.BalancesResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}
+	}
+	header, err := client.BlockHeaderByNumber(blockNumber)
+	if err != nil {
+		log.Error("get block header fail", "tag", req.BlockTag, "err", err)
+		return &account.BalancesResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header fail",
+		}
+	}
+
+	balances := make([]*account.AddressBalance, 0, len(req.Addresses)*len(tokens))
+	var (
+		queries []BalanceQuery
+		//queries 中每项在 balances 中的下标
+		indexes []int
+	)
+	for _, address := range req.Addresses {
+		for _, token := range tokens {
+			balance := &account.AddressBalance{Address: address, ContractAddress: token}
+			balances = append(balances, balance)
+			if !ethcommon.IsHexAddress(address) || (token != "" && !ethcommon.IsHexAddress(token)) {
+				balance.Code = common.ReturnCode_ERROR
+				balance.Msg = "invalid address or contract address"
+				continue
+			}
+			queries = append(queries, BalanceQuery{Owner: ethcommon.HexToAddress(address), Token: ethcommon.HexToAddress(token)})
+			indexes = append(indexes, len(balances)-1)
+		}
+	}
+	failed := len(balances) - len(queries)
+	for i, result := range batchBalances(client, queries, header.Number) {
+		balance := balances[indexes[i]]
+		if result.Err != nil {
+			balance.Code = common.ReturnCode_ERROR
+			balance.Msg = result.Err.Error()
+			failed++
+			continue
+		}
+		balance.Code = common.ReturnCode_SUCCESS
+		balance.Balance = result.Balance.String()
+	}
+
+	after, err := client.BlockHeaderByNumber(header.Number)
+	if err != nil {
+		log.Error("verify block header fail", "height", header.Number, "err", err)
+		return &account.BalancesResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "verify block header fail",
+		}
+	}
+	if after.Hash() != header.Hash() {
+		log.Warn("block reorged during balance query", "height", header.Number, "before", header.Hash(), "after", after.Hash())
+		return &account.BalancesResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "block reorged during query, retry later",
+		}
+	}
+	msg := "get balances success"
+	if failed > 0 {
+		msg = fmt.Sprintf("get balances finished, %d of %d failed", failed, len(balances))
+	}
+	return &account.BalancesResponse{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       msg,
+		Height:    header.Number.Uint64(),
+		BlockHash: header.Hash().String(),
+		Balances:  balances,
+	}
+}
+
+// batchBalances 分组查询余额, Multicall3 不可用时剩余的分组改用 JSON-RPC 批量请求, 整组失败时组内每项都返回错误
+func batchBalances(client EthClient, queries []BalanceQuery, blockNumber *big.Int) []BalanceResult {
+	results := make([]BalanceResult, len(queries))
+	multicall := true
+	for start := 0; start < len(queries); {
+		if multicall {
+			end := min(start+MaxMulticallQueries, len(queries))
+			chunk, err := MulticallBalances(client, queries[start:end], blockNumber)
+			if errors.Is(err, errUnexpectedOutput) {
+				log.Warn("multicall3 unavailable, fall back to json-rpc batch", "block", blockNumber, "err", err)
+				multicall = false
+				continue
+			}
+			if err != nil {
+				log.Error("multicall balances fail", "block", blockNumber, "err", err)
+				for i := start; i < end; i++ {
+					results[i].Err = fmt.Errorf("multicall fail: %w", err)
+				}
+			} else {
+				copy(results[start:end], chunk)
+			}
+			start = end
+			continue
+		}
+		end := min(start+maxBatchCalls, len(queries))
+		batchCallBalances(client, queries[start:end], blockNumber, results[start:end])
+		start = end
+	}
+	return results
+}
+
+// batchCallBalances 一次 JSON-RPC 批量请求查询余额, 原生币使用 eth_getBalance, 代币使用 eth_call balanceOf
+func batchCallBalances(client EthClient, queries []BalanceQuery, blockNumber *big.Int, results []BalanceResult) {
+	elems := make([]rpc.BatchElem, len(queries))
+	balances := make([]hexutil.Big, len(queries))
+	outputs := make([]hexutil.Bytes, len(queries))
+	balanceOf := method(erc20ABI, "balanceOf(address)")
+	for i, query := range queries {
+		if query.Token == (ethcommon.Address{}) {
+			elems[i] = rpc.BatchElem{Method: "eth_getBalance", Args: []interface{}{query.Owner, toBlockNumArg(blockNumber)}, Result: &balances[i]}
+			continue
+		}
+		data, _ := packCall(balanceOf, query.Owner)
+		token := query.Token
+		elems[i] = rpc.BatchElem{Method: "eth_call", Args: []interface{}{toCallArg(ethereum.CallMsg{To: &token, Data: data}), toBlockNumArg(blockNumber)}, Result: &outputs[i]}
+	}
+	if err := client.BatchCall(elems); err != nil {
+		log.Error("batch call balances fail", "block", blockNumber, "err", err)
+		for i := range results {
+			results[i].Err = fmt.Errorf("batch call fail: %w", err)
+		}
+		return
+	}
+	for i, elem := range elems {
+		switch {
+		case elem.Error != nil:
+			results[i].Err = elem.Error
+		case queries[i].Token == (ethcommon.Address{}):
+			results[i].Balance = new(big.Int).Set(balances[i].ToInt())
+		case len(outputs[i]) != 32:
+			results[i].Err = fmt.Errorf("unexpected balance output %x", []byte(outputs[i]))
+		default:
+			results[i].Balance = new(big.Int).SetBytes(outputs[i])
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+type callArg struct {
+	To    ethcommon.Address `json:"to"`
+	Input hexutil.Bytes     `json:"input"`
+}
+
+func TestParseBlockTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    *big.Int
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"latest", nil, false},
+		{"pending", big.NewInt(-1), false},
+		{"finalized", big.NewInt(-3), false},
+		{"safe", big.NewInt(-4), false},
+		{"earliest", big.NewInt(0), false},
+		{"100", big.NewInt(100), false},
+		{"0x64", big.NewInt(100), false},
+		{"-1", nil, true},
+		{"0xzz", nil, true},
+		{"tip", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBlockTag(tt.tag)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", tt.tag, err, tt.wantErr)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && got.Cmp(tt.want) != 0) {
+			t.Errorf("%q: got %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestAccountBalance(t *testing.T) {
+	owner := ethcommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+	token := ethcommon.HexToAddress("0x00000000000000000000000000000000000000bb")
+	balanceWord := ethcommon.LeftPadBytes(big.NewInt(42).Bytes(), 32)
+	aggregate := method(multicall3ABI, "aggregate3((address,bool,bytes)[])")
+
+	tests := []struct {
+		name      string
+		multicall bool
+		token     ethcommon.Address
+		reverted  bool
+		wantErr   bool
+		// 期望调用的方法
+		wantMethod string
+	}{
+		{name: "native via multicall", multicall: true, wantMethod: "eth_call"},
+		{name: "token via multicall", multicall: true, token: token, wantMethod: "eth_call"},
+		{name: "token reverted", multicall: true, token: token, reverted: true, wantErr: true, wantMethod: "eth_call"},
+		{name: "native without multicall", wantMethod: "eth_getBalance"},
+		{name: "token without multicall", token: token, wantMethod: "eth_call"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var methods []string
+			client := newTestClient(t, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+				var tag string
+				decodeParam(t, params, 1, &tag)
+				if tag != "0x64" {
+					t.Errorf("%s at block %s, want 0x64", method, tag)
+				}
+				switch method {
+				case "eth_getBalance":
+					methods = append(methods, method)
+					return (*hexutil.Big)(big.NewInt(42))
+				case "eth_call":
+					var arg callArg
+					decodeParam(t, params, 0, &arg)
+					if arg.To != Multicall3Address {
+						methods = append(methods, method)
+						if arg.To != token {
+							t.Errorf("balanceOf sent to %s", arg.To)
+						}
+						return hexutil.Bytes(balanceWord)
+					}
+					if !tt.multicall {
+						//该高度没有部署 Multicall3
+						return hexutil.Bytes{}
+					}
+					methods = append(methods, method)
+					out, err := aggregate.Outputs.Pack([]multicall3Result{{Success: !tt.reverted, ReturnData: balanceWord}})
+					if err != nil {
+						t.Fatal(err)
+					}
+					return hexutil.Bytes(out)
+				}
+				t.Errorf("unexpected method %s", method)
+				return nil
+			})
+			balance, err := AccountBalance(client, owner, tt.token, big.NewInt(100))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && balance.Cmp(big.NewInt(42)) != 0 {
+				t.Errorf("balance = %s, want 42", balance)
+			}
+			if len(methods) != 1 || methods[0] != tt.wantMethod {
+				t.Errorf("balance read with %v, want %s", methods, tt.wantMethod)
+			}
+		})
+	}
+}
+
+// 返回的 block_hash 与重组检查都须使用节点返回的哈希; 同高度区块被替换时本地计算的哈希可能不变
+func TestBalancesResponseNodeHash(t *testing.T) {
+	const height = 100
+	tests := []struct {
+		name     string
+		reorged  bool
+		wantCode common.ReturnCode
+	}{
+		{"stable", false, common.ReturnCode_SUCCESS},
+		{"reorged during query", true, common.ReturnCode_ERROR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headerCalls int
+			var lookups []ethcommon.Hash
+			node := pectraNode(height, &lookups, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+				switch method {
+				case "eth_getBalance":
+					return (*hexutil.Big)(big.NewInt(42))
+				case "eth_call":
+					//该高度没有部署 Multicall3
+					return hexutil.Bytes{}
+				}
+				t.Errorf("unexpected method %s", method)
+				return nil
+			})
+			client := newTestClient(t, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+				result := node(t, method, params)
+				if method != "eth_getBlockByNumber" {
+					return result
+				}
+				headerCalls++
+				if tt.reorged && headerCalls > 1 {
+					result.(map[string]interface{})["hash"] = pectraHash(height + 1000).String()
+				}
+				return result
+			})
+			resp := BalancesResponse(client, &account.BalancesRequest{
+				Addresses: []string{"0x00000000000000000000000000000000000000aa"},
+				BlockTag:  "100",
+			})
+			if resp.Code != tt.wantCode {
+				t.Fatalf("code = %v (%s), want %v", resp.Code, resp.Msg, tt.wantCode)
+			}
+			if !tt.reorged && resp.BlockHash != pectraHash(height).String() {
+				t.Errorf("block hash = %s, want %s", resp.BlockHash, pectraHash(height))
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+// 未配置 confirmations 时, 交易被打包即视为确认
+const defaultConfirmations = 1
+
+// Confirmer 按配置的确认数或节点的 safe/finalized 区块标签判断区块是否已确认
+type Confirmer struct {
+	client EthClient
+	mode   string
+	depth  uint64
+}
+
+func NewConfirmer(client EthClient, node *config.ChainConfig) *Confirmer {
+	mode := node.ConfirmationMode
+	if mode == "" {
+		mode = config.ConfirmationModeDepth
+	}
+	depth := node.Confirmations
+	if depth == 0 {
+		depth = defaultConfirmations
+	}
+	return &Confirmer{client: client, mode: mode, depth: depth}
+}
+
+// ConfirmationView 某一时刻的链头与确认边界, 同一请求中的多笔交易共用, 避免重复查询
+type ConfirmationView struct {
+	Latest uint64
+	// 标签模式下 safe/finalized 区块的高度
+	tagHeight uint64
+	depth     uint64
+	byTag     bool
+}
+
+/**
+ * @description: 查询最新区块高度, 标签模式下同时查询 safe/finalized 区块高度
+ */
+func (c *Confirmer) View() (*ConfirmationView, error) {
+	latest, err := c.client.BlockHeaderByNumber(nil)
+	if err != nil {
+		return nil, err
+	}
+	view := &ConfirmationView{Latest: latest.Number.Uint64(), depth: c.depth}
+	switch c.mode {
+	case config.ConfirmationModeSafe:
+		header, err := c.client.LatestSafeBlockHeader()
+		if err != nil {
+			return nil, err
+		}
+		view.byTag, view.tagHeight = true, header.Number.Uint64()
+	case config.ConfirmationModeFinalized:
+		header, err := c.client.LatestFinalizedBlockHeader()
+		if err != nil {
+			return nil, err
+		}
+		view.byTag, view.tagHeight = true, header.Number.Uint64()
+	}
+	return view, nil
+}
+
+// Confirmations 区块的确认数, 区块自身计 1 个确认
+func (v *ConfirmationView) Confirmations(height uint64) uint64 {
+	if height > v.Latest {
+		return 0
+	}
+	return v.Latest - height + 1
+}
+
+func (v *ConfirmationView) Confirmed(height uint64) bool {
+	if v.byTag {
+		return height <= v.tagHeight
+	}
+	return v.Confirmations(height) >= v.depth
+}
+
+// Status 已打包交易的状态, 未达到确认条件前为 Pending
+func (v *ConfirmationView) Status(height uint64, succeeded bool) account.TxStatus {
+	switch {
+	case !v.Confirmed(height):
+		return account.TxStatus_Pending
+	case succeeded:
+		return account.TxStatus_Success
+	}
+	return account.TxStatus_Failed
+}
+
+/**
+ * @description: GetTxByHash 的公共实现, 交易未打包或未达到确认条件时状态为 Pending
+ * @param client 节点客户端
+ * @param confirmer 确认判断
+ * @param hash 交易哈希
+ * @param chainId 链 id, 用于恢复发送方
+ */
+func TxByHashResponse(client EthClient, confirmer *Confirmer, hash string, chainId *big.Int) *account.TxHashResponse {
+	tx, err := client.TxByHash(ethcommon.HexToHash(hash))
+	if err != nil {
+		log.Error("get tx by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}
+	}
+	receipt, err := client.TxReceiptByHash(tx.Hash())
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
+		log.Error("get tx receipt fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx receipt fail",
+		}
+	}
+	var from string
+	if sender, err := types.Sender(types.LatestSignerForChainID(chainId), tx); err == nil {
+		from = sender.String()
+	}
+	var to string
+	if tx.To() != nil {
+		to = tx.To().String()
+	}
+	txMessage := &account.TxMessage{
+		Hash:   tx.Hash().String(),
+		Froms:  []*account.Address{{Address: from}},
+		Tos:    []*account.Address{{Address: to}},
+		Values: []*account.Value{{Value: tx.Value().String()}},
+		Status: account.TxStatus_Pending,
+		Type:   int32(tx.Type()),
+	}
+	if receipt != nil {
+		view, err := confirmer.View()
+		if err != nil {
+			log.Error("get confirmation view fail", "err", err)
+			return &account.TxHashResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get latest block fail",
+			}
+		}
+		height := receipt.BlockNumber.Uint64()
+		txMessage.Index = uint32(receipt.TransactionIndex)
+		txMessage.Height = receipt.BlockNumber.String()
+		//部分节点 (如升级 EIP-1559 前的链) 的收据没有 effectiveGasPrice, 使用交易的 gas price
+		gasPrice := receipt.EffectiveGasPrice
+		if gasPrice == nil {
+			gasPrice = tx.GasPrice()
+		}
+		txMessage.Fee = new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), gasPrice).String()
+		txMessage.Status = view.Status(height, receipt.Status == types.ReceiptStatusSuccessful)
+		txMessage.Confirmations = view.Confirmations(height)
+		if receipt.ContractAddress != (ethcommon.Address{}) {
+			txMessage.ContractAddress = receipt.ContractAddress.String()
+		}
+	}
+	return &account.TxHashResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by hash success",
+		Tx:   txMessage,
+	}
+}
+
+/**
+ * @description: GetBlockByNumber/GetBlockByHash 的公共实现, 区块未达到确认条件时其中的交易状态为 Pending
+ * 已确认区块按每笔交易的收据判断成功或失败, 单笔收据查询失败时状态为 Other
+ * @param confirmer 确认判断
+ * @param block 区块
+ */
+func BlockResponse(confirmer *Confirmer, block *RpcBlock) *account.BlockResponse {
+	view, err := confirmer.View()
+	if err != nil {
+		log.Error("get confirmation view fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get latest block fail",
+		}
+	}
+	height := block.Number.ToInt().Uint64()
+	statuses := make([]account.TxStatus, len(block.Transactions))
+	for i := range statuses {
+		statuses[i] = account.TxStatus_Pending
+	}
+	if view.Confirmed(height) {
+		if err := receiptStatuses(confirmer.client, view, height, block.Transactions, statuses); err != nil {
+			log.Error("get block receipts fail", "height", height, "err", err)
+			return &account.BlockResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block receipts fail",
+			}
+		}
+	}
+	var txListRet []*account.BlockInfoTransactionList
+	for i, v := range block.Transactions {
+		txListRet = append(txListRet, &account.BlockInfoTransactionList{
+			To:     v.To,
+			Hash:   v.Hash,
+			Status: statuses[i].String(),
+		})
+	}
+	return &account.BlockResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "get block success",
+		Height:        int64(height),
+		Hash:          block.Hash.String(),
+		BaseFee:       block.BaseFee,
+		Transactions:  txListRet,
+		Confirmations: view.Confirmations(height),
+	}
+}
+
+// receiptStatus 收据中判断执行结果所需的字段
+type receiptStatus struct {
+	Status hexutil.Uint64 `json:"status"`
+}
+
+// receiptStatuses 分组批量查询已确认区块中交易的收据, 按执行结果填入 statuses; 整组请求失败时返回错误
+func receiptStatuses(client EthClient, view *ConfirmationView, height uint64, txs []TransactionList, statuses []account.TxStatus) error {
+	for start := 0; start < len(txs); start += maxBatchCalls {
+		end := min(start+maxBatchCalls, len(txs))
+		receipts := make([]*receiptStatus, end-start)
+		elems := make([]rpc.BatchElem, end-start)
+		for i := range elems {
+			elems[i] = rpc.BatchElem{Method: "eth_getTransactionReceipt", Args: []interface{}{ethcommon.HexToHash(txs[start+i].Hash)}, Result: &receipts[i]}
+		}
+		if err := client.BatchCall(elems); err != nil {
+			return err
+		}
+		for i, elem := range elems {
+			if elem.Error != nil || receipts[i] == nil {
+				log.Warn("get tx receipt fail", "hash", txs[start+i].Hash, "err", elem.Error)
+				statuses[start+i] = account.TxStatus_Other
+				continue
+			}
+			statuses[start+i] = view.Status(height, uint64(receipts[i].Status) == types.ReceiptStatusSuccessful)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+// 已确认区块中的交易按各自的收据判断状态, 未确认时都为 Pending
+func TestBlockResponseStatuses(t *testing.T) {
+	succeeded := ethcommon.HexToHash("0x01")
+	failed := ethcommon.HexToHash("0x02")
+	missing := ethcommon.HexToHash("0x03")
+	block := &RpcBlock{
+		Hash:   ethcommon.HexToHash("0xb1"),
+		Number: hexutil.Big(*big.NewInt(100)),
+		Transactions: []TransactionList{
+			{Hash: succeeded.String()},
+			{Hash: failed.String()},
+			{Hash: missing.String()},
+		},
+	}
+	tests := []struct {
+		name string
+		head uint64
+		want []account.TxStatus
+	}{
+		{"confirmed", 105, []account.TxStatus{account.TxStatus_Success, account.TxStatus_Failed, account.TxStatus_Other}},
+		{"unconfirmed", 101, []account.TxStatus{account.TxStatus_Pending, account.TxStatus_Pending, account.TxStatus_Pending}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+				switch method {
+				case "eth_getBlockByNumber":
+					return &types.Header{Number: new(big.Int).SetUint64(tt.head), Difficulty: new(big.Int)}
+				case "eth_getTransactionReceipt":
+					var hash ethcommon.Hash
+					decodeParam(t, params, 0, &hash)
+					switch hash {
+					case succeeded:
+						return map[string]string{"status": "0x1"}
+					case failed:
+						return map[string]string{"status": "0x0"}
+					}
+					return nil
+				}
+				t.Errorf("unexpected method %s", method)
+				return nil
+			})
+			resp := BlockResponse(NewConfirmer(client, &config.ChainConfig{Node: config.Node{Confirmations: 3}}), block)
+			if resp.Code != common.ReturnCode_SUCCESS {
+				t.Fatalf("code = %v (%s)", resp.Code, resp.Msg)
+			}
+			for i, tx := range resp.Transactions {
+				if tx.Status != tt.want[i].String() {
+					t.Errorf("tx %d: status = %s, want %s", i, tx.Status, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// 收据没有 effectiveGasPrice 时按交易的 gas price 计算手续费
+func TestTxByHashResponseWithoutEffectiveGasPrice(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainId := big.NewInt(1)
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainId), &types.LegacyTx{
+		Nonce:    1,
+		GasPrice: big.NewInt(3),
+		Gas:      21000,
+		To:       &ethcommon.Address{0xaa},
+		Value:    big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(t, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+		switch method {
+		case "eth_getTransactionByHash":
+			return tx
+		case "eth_getTransactionReceipt":
+			return &types.Receipt{
+				Status:      types.ReceiptStatusSuccessful,
+				TxHash:      tx.Hash(),
+				GasUsed:     21000,
+				BlockNumber: big.NewInt(100),
+				Logs:        []*types.Log{},
+			}
+		case "eth_getBlockByNumber":
+			return &types.Header{Number: big.NewInt(100), Difficulty: new(big.Int)}
+		}
+		t.Errorf("unexpected method %s", method)
+		return nil
+	})
+	resp := TxByHashResponse(client, NewConfirmer(client, &config.ChainConfig{}), tx.Hash().String(), chainId)
+	if resp.Code != common.ReturnCode_SUCCESS {
+		t.Fatalf("code = %v (%s)", resp.Code, resp.Msg)
+	}
+	if resp.Tx.Fee != "63000" || resp.Tx.Status != account.TxStatus_Success {
+		t.Errorf("fee = %s status = %v, want 63000 Success", resp.Tx.Fee, resp.Tx.Status)
+	}
+}
@@ -2,6 +2,7 @@ package ethereum
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/CavnHan/wallet-chain-account/common/global_const"
@@ -27,25 +28,61 @@ const (
 )
 
 type TransactionList struct {
-	To   string `json:"to"`
-	Hash string `json:"hash"`
+	From  string       `json:"from"`
+	To    string       `json:"to"`
+	Hash  string       `json:"hash"`
+	Value *hexutil.Big `json:"value"`
 }
 
 type RpcBlock struct {
 	Hash         common.Hash       `json:"hash"`
+	ParentHash   common.Hash       `json:"parentHash"`
+	Number       hexutil.Big       `json:"number"`
 	Transactions []TransactionList `json:"transactions"`
 	BaseFee      string            `json:"baseFeePerGas"`
 }
 
+// RpcHeader 节点返回的区块头, Hash 使用节点给出的 hash 字段
+// go-ethereum 本地重新计算的哈希不包含其不认识的新字段 (如 Pectra 的 requestsHash), 升级后会与链上哈希不一致
+type RpcHeader struct {
+	types.Header
+	hash common.Hash
+}
+
+// Hash 节点返回的区块哈希, 没有 hash 字段时 (如手工构造的区块头) 按本地计算
+func (h *RpcHeader) Hash() common.Hash {
+	if h.hash == (common.Hash{}) {
+		return h.Header.Hash()
+	}
+	return h.hash
+}
+
+func (h *RpcHeader) UnmarshalJSON(input []byte) error {
+	var nodeHash struct {
+		Hash *common.Hash `json:"hash"`
+	}
+	if err := json.Unmarshal(input, &nodeHash); err != nil {
+		return err
+	}
+	if err := h.Header.UnmarshalJSON(input); err != nil {
+		return err
+	}
+	h.hash = common.Hash{}
+	if nodeHash.Hash != nil {
+		h.hash = *nodeHash.Hash
+	}
+	return nil
+}
+
 type EthClient interface {
-	BlockHeaderByNumber(*big.Int) (*types.Header, error)
+	BlockHeaderByNumber(*big.Int) (*RpcHeader, error)
 
 	BlockByNumber(*big.Int) (*RpcBlock, error)
 	BlockByHash(common.Hash) (*RpcBlock, error)
-	LatestSafeBlockHeader() (*types.Header, error)
-	LatestFinalizedBlockHeader() (*types.Header, error)
-	BlockHeaderByHash(common.Hash) (*types.Header, error)
-	BlockHeadersByRange(*big.Int, *big.Int, uint) ([]types.Header, error)
+	LatestSafeBlockHeader() (*RpcHeader, error)
+	LatestFinalizedBlockHeader() (*RpcHeader, error)
+	BlockHeaderByHash(common.Hash) (*RpcHeader, error)
+	BlockHeadersByRange(*big.Int, *big.Int, uint) ([]RpcHeader, error)
 
 	TxByHash(common.Hash) (*types.Transaction, error)
 	TxReceiptByHash(common.Hash) (*types.Receipt, error)
@@ -54,12 +91,24 @@ type EthClient interface {
 	FilterLogs(filterQuery ethereum.FilterQuery, chainId uint) (Logs, error)
 
 	TxCountByAddress(common.Address) (hexutil.Uint64, error)
+	// NonceAt 指定区块的 nonce, blockNumber 为 nil 时为最新区块, 负数为区块标签
+	NonceAt(common.Address, *big.Int) (hexutil.Uint64, error)
+	// PendingNonceAt 包含交易池中交易的 nonce (eth_getTransactionCount "pending")
+	PendingNonceAt(common.Address) (uint64, error)
+	BalanceAt(common.Address, *big.Int) (*big.Int, error)
+	CallContract(ethereum.CallMsg, *big.Int) ([]byte, error)
+	EstimateGas(ethereum.CallMsg) (uint64, error)
+	// BatchCall JSON-RPC 批量请求, 单个调用的错误在 BatchElem.Error 中
+	BatchCall([]rpc.BatchElem) error
 
 	SendRawTransaction(rawTx string) error
 
 	SuggestGasPrice() (*big.Int, error)
 	SuggestGasTipCap() (*big.Int, error)
 
+	// SubscribeNewHead 需要 ws/ipc 连接, http 连接返回 rpc.ErrNotificationsUnsupported
+	SubscribeNewHead(ctx context.Context, ch chan<- *RpcHeader) (ethereum.Subscription, error)
+
 	Close()
 }
 
@@ -93,11 +142,11 @@ func DialEthClient(ctx context.Context, rpcUrl string) (EthClient, error) {
 	return &clnt{rpc: NewRPC(rpcClient)}, nil
 }
 
-func (c *clnt) BlockHeaderByHash(hash common.Hash) (*types.Header, error) {
+func (c *clnt) BlockHeaderByHash(hash common.Hash) (*RpcHeader, error) {
 	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancel()
 
-	var header *types.Header
+	var header *RpcHeader
 	err := c.rpc.CallContext(ctxwt, &header, "eth_getBlockByHash", hash, false)
 	if err != nil {
 		return nil, err
@@ -112,11 +161,11 @@ func (c *clnt) BlockHeaderByHash(hash common.Hash) (*types.Header, error) {
 	return header, nil
 }
 
-func (c *clnt) LatestSafeBlockHeader() (*types.Header, error) {
+func (c *clnt) LatestSafeBlockHeader() (*RpcHeader, error) {
 	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancel()
 
-	var header *types.Header
+	var header *RpcHeader
 	err := c.rpc.CallContext(ctxwt, &header, "eth_getBlockByNumber", "safe", false)
 	if err != nil {
 		return nil, err
@@ -127,11 +176,11 @@ func (c *clnt) LatestSafeBlockHeader() (*types.Header, error) {
 	return header, nil
 }
 
-func (c *clnt) LatestFinalizedBlockHeader() (*types.Header, error) {
+func (c *clnt) LatestFinalizedBlockHeader() (*RpcHeader, error) {
 	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancel()
 
-	var header *types.Header
+	var header *RpcHeader
 	err := c.rpc.CallContext(ctxwt, &header, "eth_getBlockByNumber", "finalized", false)
 	if err != nil {
 		return nil, err
@@ -185,6 +234,72 @@ func (c *clnt) TxCountByAddress(address common.Address) (hexutil.Uint64, error)
 	return nonce, err
 }
 
+func (c *clnt) PendingNonceAt(address common.Address) (uint64, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	var nonce hexutil.Uint64
+	err := c.rpc.CallContext(ctxwt, &nonce, "eth_getTransactionCount", address, "pending")
+	if err != nil {
+		log.Error("Call eth_getTransactionCount pending fail", "err", err)
+		return 0, err
+	}
+	return uint64(nonce), nil
+}
+
+func (c *clnt) NonceAt(address common.Address, blockNumber *big.Int) (hexutil.Uint64, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	var nonce hexutil.Uint64
+	err := c.rpc.CallContext(ctxwt, &nonce, "eth_getTransactionCount", address, toBlockNumArg(blockNumber))
+	if err != nil {
+		log.Error("Call eth_getTransactionCount method fail", "err", err)
+		return 0, err
+	}
+	return nonce, nil
+}
+
+func (c *clnt) BalanceAt(address common.Address, blockNumber *big.Int) (*big.Int, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	var balance hexutil.Big
+	err := c.rpc.CallContext(ctxwt, &balance, "eth_getBalance", address, toBlockNumArg(blockNumber))
+	if err != nil {
+		log.Error("Call eth_getBalance method fail", "err", err)
+		return nil, err
+	}
+	return (*big.Int)(&balance), nil
+}
+
+func (c *clnt) CallContract(msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	var result hexutil.Bytes
+	err := c.rpc.CallContext(ctxwt, &result, "eth_call", toCallArg(msg), toBlockNumArg(blockNumber))
+	if err != nil {
+		log.Error("Call eth_call method fail", "err", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *clnt) EstimateGas(msg ethereum.CallMsg) (uint64, error) {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	var gas hexutil.Uint64
+	err := c.rpc.CallContext(ctxwt, &gas, "eth_estimateGas", toCallArg(msg))
+	if err != nil {
+		log.Error("Call eth_estimateGas method fail", "err", err)
+		return 0, err
+	}
+	return uint64(gas), nil
+}
+
+func (c *clnt) BatchCall(elems []rpc.BatchElem) error {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout*10)
+	defer cancel()
+	return c.rpc.BatchCallContext(ctxwt, elems)
+}
+
 func (c *clnt) SuggestGasPrice() (*big.Int, error) {
 	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancel()
@@ -215,11 +330,11 @@ func (c *clnt) SendRawTransaction(rawTx string) error {
 	return nil
 }
 
-func (c *clnt) BlockHeaderByNumber(number *big.Int) (*types.Header, error) {
+func (c *clnt) BlockHeaderByNumber(number *big.Int) (*RpcHeader, error) {
 	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancel()
 
-	var header *types.Header
+	var header *RpcHeader
 	err := c.rpc.CallContext(ctxwt, &header, "eth_getBlockByNumber", toBlockNumArg(number), false)
 	if err != nil {
 		log.Error("Call eth_getBlockByNumber method fail", "err", err)
@@ -232,17 +347,18 @@ func (c *clnt) BlockHeaderByNumber(number *big.Int) (*types.Header, error) {
 	return header, nil
 }
 
-func (c *clnt) BlockHeadersByRange(startHeight, endHeight *big.Int, chainId uint) ([]types.Header, error) {
+func (c *clnt) BlockHeadersByRange(startHeight, endHeight *big.Int, chainId uint) ([]RpcHeader, error) {
 	if startHeight.Cmp(endHeight) == 0 {
 		header, err := c.BlockHeaderByNumber(startHeight)
 		if err != nil {
 			return nil, err
 		}
-		return []types.Header{*header}, nil
+		return []RpcHeader{*header}, nil
 	}
 
 	count := new(big.Int).Sub(endHeight, startHeight).Uint64() + 1
-	headers := make([]types.Header, count)
+	//先按原始 JSON 接收, 超过链头的高度节点返回 null, 直接解码到区块头会报缺少字段
+	results := make([]json.RawMessage, count)
 	batchElems := make([]rpc.BatchElem, count)
 	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancel()
@@ -266,12 +382,10 @@ func (c *clnt) BlockHeadersByRange(startHeight, endHeight *big.Int, chainId uint
 					height := new(big.Int).Add(startHeight, new(big.Int).SetUint64(uint64(j)))
 					batchElems[j] = rpc.BatchElem{
 						Method: "eth_getBlockByNumber",
-						Result: new(types.Header),
+						Result: &results[j],
 						Error:  nil,
 					}
-					header := new(types.Header)
-					batchElems[j].Error = c.rpc.CallContext(ctxwt, header, batchElems[j].Method, toBlockNumArg(height), false)
-					batchElems[j].Result = header
+					batchElems[j].Error = c.rpc.CallContext(ctxwt, &results[j], batchElems[j].Method, toBlockNumArg(height), false)
 				}
 			}(start, end)
 		}
@@ -280,27 +394,36 @@ func (c *clnt) BlockHeadersByRange(startHeight, endHeight *big.Int, chainId uint
 	} else {
 		for i := uint64(0); i < count; i++ {
 			height := new(big.Int).Add(startHeight, new(big.Int).SetUint64(i))
-			batchElems[i] = rpc.BatchElem{Method: "eth_getBlockByNumber", Args: []interface{}{toBlockNumArg(height), false}, Result: &headers[i]}
+			batchElems[i] = rpc.BatchElem{Method: "eth_getBlockByNumber", Args: []interface{}{toBlockNumArg(height), false}, Result: &results[i]}
 		}
 		err := c.rpc.BatchCallContext(ctxwt, batchElems)
 		if err != nil {
 			return nil, err
 		}
 	}
-	size := 0
+	headers := make([]RpcHeader, 0, count)
 	for i, batchElem := range batchElems {
-		header, ok := batchElem.Result.(*types.Header)
-		if !ok {
-			return nil, fmt.Errorf("unable to transform rpc response %v into types.Header", batchElem.Result)
+		//节点返回 null, 说明该高度还未出块, 只返回之前的区块头
+		if batchElem.Error == nil && isNullResult(results[i]) {
+			break
+		}
+		if batchElem.Error != nil {
+			return nil, batchElem.Error
+		}
+		var header RpcHeader
+		if err := json.Unmarshal(results[i], &header); err != nil {
+			return nil, fmt.Errorf("unable to decode block header at index %d: %w", i, err)
 		}
-		headers[i] = *header
-		size = size + 1
+		headers = append(headers, header)
 	}
-	headers = headers[:size]
 
 	return headers, nil
 }
 
+func isNullResult(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
+}
+
 func (c *clnt) TxByHash(hash common.Hash) (*types.Transaction, error) {
 	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancel()
@@ -348,6 +471,10 @@ func (c *clnt) Close() {
 	c.rpc.Close()
 }
 
+func (c *clnt) SubscribeNewHead(ctx context.Context, ch chan<- *RpcHeader) (ethereum.Subscription, error) {
+	return c.rpc.EthSubscribe(ctx, ch, "newHeads")
+}
+
 type Logs struct {
 	Logs          []types.Log
 	ToBlockHeader *types.Header
@@ -392,6 +519,7 @@ type RPC interface {
 	Close()
 	CallContext(ctx context.Context, result any, method string, args ...any) error
 	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+	EthSubscribe(ctx context.Context, channel any, args ...any) (*rpc.ClientSubscription, error)
 }
 
 type rpcClient struct {
@@ -416,6 +544,10 @@ func (c *rpcClient) BatchCallContext(ctx context.Context, b []rpc.BatchElem) err
 	return err
 }
 
+func (c *rpcClient) EthSubscribe(ctx context.Context, channel any, args ...any) (*rpc.ClientSubscription, error) {
+	return c.rpc.EthSubscribe(ctx, channel, args...)
+}
+
 func toBlockNumArg(number *big.Int) string {
 	if number == nil {
 		return "latest"
@@ -426,6 +558,26 @@ func toBlockNumArg(number *big.Int) string {
 	return rpc.BlockNumber(number.Int64()).String()
 }
 
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	return arg
+}
+
 func toFilterArg(q ethereum.FilterQuery) (interface{}, error) {
 	arg := map[string]interface{}{"address": q.Addresses, "topics": q.Topics}
 	if q.BlockHash != nil {
@@ -0,0 +1,122 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// rpcHandler 按方法与参数返回结果, 返回 nil 时结果为 null
+type rpcHandler func(t *testing.T, method string, params []json.RawMessage) interface{}
+
+// newTestClient 以 handler 模拟节点的 JSON-RPC 接口, 支持单个与批量请求
+func newTestClient(t *testing.T, handler rpcHandler) *clnt {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		batch := len(body) > 0 && body[0] == '['
+		var reqs []rpcRequest
+		if !batch {
+			body = append(append(json.RawMessage("["), body...), ']')
+		}
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		resps := make([]rpcResponse, len(reqs))
+		for i, req := range reqs {
+			result, err := json.Marshal(handler(t, req.Method, req.Params))
+			if err != nil {
+				t.Errorf("encode %s result: %v", req.Method, err)
+			}
+			resps[i] = rpcResponse{Version: "2.0", ID: req.ID, Result: result}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if batch {
+			_ = json.NewEncoder(w).Encode(resps)
+		} else {
+			_ = json.NewEncoder(w).Encode(resps[0])
+		}
+	}))
+	t.Cleanup(srv.Close)
+	rpcClient, err := rpc.DialHTTP(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &clnt{rpc: NewRPC(rpcClient)}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// decodeParam 解码第 i 个参数
+func decodeParam(t *testing.T, params []json.RawMessage, i int, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(params[i], v); err != nil {
+		t.Errorf("decode param %d: %v", i, err)
+	}
+}
+
+func TestBlockHeadersByRange(t *testing.T) {
+	const head = 105
+	//只出块到 head 高度的节点, 更高的高度返回 null
+	client := newTestClient(t, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+		var tag string
+		decodeParam(t, params, 0, &tag)
+		height, err := hexutil.DecodeUint64(tag)
+		if err != nil {
+			t.Errorf("decode block height: %v", err)
+		}
+		if height > head {
+			return nil
+		}
+		return &types.Header{Number: new(big.Int).SetUint64(height), Difficulty: new(big.Int)}
+	})
+
+	tests := []struct {
+		name       string
+		start, end uint64
+		want       int
+	}{
+		{"within head", 100, 104, 5},
+		{"ends at head", 100, 105, 6},
+		{"past head", 100, 110, 6},
+		{"entirely past head", 106, 110, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, err := client.BlockHeadersByRange(new(big.Int).SetUint64(tt.start), new(big.Int).SetUint64(tt.end), 1)
+			if err != nil {
+				t.Fatalf("BlockHeadersByRange: %v", err)
+			}
+			if len(headers) != tt.want {
+				t.Fatalf("got %d headers, want %d", len(headers), tt.want)
+			}
+			for i, header := range headers {
+				if header.Number.Uint64() != tt.start+uint64(i) {
+					t.Errorf("header %d has number %d", i, header.Number.Uint64())
+				}
+			}
+		})
+	}
+}
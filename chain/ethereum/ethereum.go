@@ -2,15 +2,19 @@ package ethereum
 
 import (
 	"context"
+	"errors"
 	"math/big"
 	"strconv"
-	"time"
+	"strings"
 
+	chainaccount "github.com/dapplink-labs/chain-explorer-api/common/account"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/chain/tracker"
+	"github.com/CavnHan/wallet-chain-account/common/global_const"
 	"github.com/CavnHan/wallet-chain-account/config"
 	"github.com/CavnHan/wallet-chain-account/rpc/account"
 	"github.com/CavnHan/wallet-chain-account/rpc/common"
@@ -23,23 +27,55 @@ const ChainName = "Ethereum"
 type ChainAdaptor struct {
 	ethClient     EthClient
 	ethDataClient *EthData
+	confirmer     *Confirmer
+	tracker       *tracker.Tracker
+	nonces        *NonceAllocator
+}
+
+func init() {
+	chain.Register(ChainName, NewChainAdaptor)
+	config.RegisterChainValidator(ChainName, validateConfig)
+	config.RegisterLegacyNodeKey(ChainName, "eth")
+}
+
+// validateConfig 交易记录查询依赖区块浏览器接口
+func validateConfig(node *config.ChainConfig) error {
+	if node.DataApiUrl == "" {
+		return errors.New("data_api_url: required")
+	}
+	return nil
 }
 
 func NewChainAdaptor(conf *config.Config) (chain.IChainAdaptor, error) {
-	ethClient, err := DialEthClient(context.Background(), conf.WalletNode.Eth.RPCs[0].RPCURL)
+	node, ok := conf.Chain(ChainName)
+	if !ok {
+		return nil, errors.New("ethereum rpc not configured")
+	}
+	if err := validateConfig(node); err != nil {
+		return nil, err
+	}
+	ethClient, err := DialEthClient(context.Background(), node.RPCs[0].RPCURL)
 	if err != nil {
 		return nil, err
 	}
-	ethDataClient, err := NewEthDataClient(conf.WalletNode.Eth.DataApiUrl, conf.WalletNode.Eth.DataApiKey, time.Duration(conf.WalletNode.Eth.TimeOut))
+	ethDataClient, err := NewEthDataClient(node.DataApiUrl, node.DataApiKey, node.TimeOut.Duration())
 	if err != nil {
 		return nil, err
 	}
 	return &ChainAdaptor{
 		ethClient:     ethClient,
 		ethDataClient: ethDataClient,
+		confirmer:     NewConfirmer(ethClient, node),
+		tracker:       NewTracker(ethClient),
+		nonces:        SharedNonceAllocator(ChainName, conf.NetWork, ethClient),
 	}, nil
 }
 
+// Close 热加载替换后关闭 RPC 连接
+func (c ChainAdaptor) Close() {
+	c.ethClient.Close()
+}
+
 func (c ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
 	return &account.SupportChainsResponse{
 		Code:    common.ReturnCode_SUCCESS,
@@ -72,27 +108,7 @@ func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest)
 			Msg:  "get latest block header fail",
 		}, nil
 	}
-	blockHeader := &account.BlockHeader{
-		ParentHash:       blockInfo.ParentHash.String(),
-		UncleHash:        blockInfo.UncleHash.String(),
-		CoinBase:         blockInfo.Coinbase.String(),
-		Root:             blockInfo.Root.String(),
-		TxHash:           blockInfo.TxHash.String(),
-		ReceiptHash:      blockInfo.ReceiptHash.String(),
-		ParentBeaconRoot: blockInfo.ParentBeaconRoot.String(),
-		Difficulty:       blockInfo.Difficulty.String(),
-		Number:           blockInfo.Number.String(),
-		GasLimit:         blockInfo.GasLimit,
-		GasUsed:          blockInfo.GasUsed,
-		Time:             blockInfo.Time,
-		Extra:            string(blockInfo.Extra),
-		MixDigest:        blockInfo.MixDigest.String(),
-		Nonce:            strconv.FormatUint(blockInfo.Nonce.Uint64(), 10),
-		BaseFee:          blockInfo.BaseFee.String(),
-		WithdrawalsHash:  blockInfo.WithdrawalsHash.String(),
-		BlobGasUsed:      *blockInfo.BlobGasUsed,
-		ExcessBlobGas:    *blockInfo.ExcessBlobGas,
-	}
+	blockHeader := ToBlockHeader(blockInfo)
 	return &account.BlockHeaderResponse{
 		Code:        common.ReturnCode_SUCCESS,
 		Msg:         "get latest block header success",
@@ -100,6 +116,42 @@ func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest)
 	}, nil
 }
 
+// ToBlockHeader 转换区块头, 升级前的区块没有的字段留空
+func ToBlockHeader(blockInfo *RpcHeader) *account.BlockHeader {
+	blockHeader := &account.BlockHeader{
+		ParentHash:  blockInfo.ParentHash.String(),
+		UncleHash:   blockInfo.UncleHash.String(),
+		CoinBase:    blockInfo.Coinbase.String(),
+		Root:        blockInfo.Root.String(),
+		TxHash:      blockInfo.TxHash.String(),
+		ReceiptHash: blockInfo.ReceiptHash.String(),
+		Difficulty:  blockInfo.Difficulty.String(),
+		Number:      blockInfo.Number.String(),
+		GasLimit:    blockInfo.GasLimit,
+		GasUsed:     blockInfo.GasUsed,
+		Time:        blockInfo.Time,
+		Extra:       string(blockInfo.Extra),
+		MixDigest:   blockInfo.MixDigest.String(),
+		Nonce:       strconv.FormatUint(blockInfo.Nonce.Uint64(), 10),
+	}
+	if blockInfo.ParentBeaconRoot != nil {
+		blockHeader.ParentBeaconRoot = blockInfo.ParentBeaconRoot.String()
+	}
+	if blockInfo.BaseFee != nil {
+		blockHeader.BaseFee = blockInfo.BaseFee.String()
+	}
+	if blockInfo.WithdrawalsHash != nil {
+		blockHeader.WithdrawalsHash = blockInfo.WithdrawalsHash.String()
+	}
+	if blockInfo.BlobGasUsed != nil {
+		blockHeader.BlobGasUsed = *blockInfo.BlobGasUsed
+	}
+	if blockInfo.ExcessBlobGas != nil {
+		blockHeader.ExcessBlobGas = *blockInfo.ExcessBlobGas
+	}
+	return blockHeader
+}
+
 func (c ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
 	var blockNumber *big.Int
 	if req.Height == 0 {
@@ -115,27 +167,7 @@ func (c ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberReque
 			Msg:  "get lates block number fail",
 		}, nil
 	}
-	blockHeader := &account.BlockHeader{
-		ParentHash:       blockInfo.ParentHash.String(),
-		UncleHash:        blockInfo.UncleHash.String(),
-		CoinBase:         blockInfo.Coinbase.String(),
-		Root:             blockInfo.Root.String(),
-		TxHash:           blockInfo.TxHash.String(),
-		ReceiptHash:      blockInfo.ReceiptHash.String(),
-		ParentBeaconRoot: blockInfo.ParentBeaconRoot.String(),
-		Difficulty:       blockInfo.Difficulty.String(),
-		Number:           blockInfo.Number.String(),
-		GasLimit:         blockInfo.GasLimit,
-		GasUsed:          blockInfo.GasUsed,
-		Time:             blockInfo.Time,
-		Extra:            string(blockInfo.Extra),
-		MixDigest:        blockInfo.MixDigest.String(),
-		Nonce:            strconv.FormatUint(blockInfo.Nonce.Uint64(), 10),
-		BaseFee:          blockInfo.BaseFee.String(),
-		WithdrawalsHash:  blockInfo.WithdrawalsHash.String(),
-		BlobGasUsed:      *blockInfo.BlobGasUsed,
-		ExcessBlobGas:    *blockInfo.ExcessBlobGas,
-	}
+	blockHeader := ToBlockHeader(blockInfo)
 	return &account.BlockHeaderResponse{
 		Code:        common.ReturnCode_SUCCESS,
 		Msg:         "get latest block header success",
@@ -143,48 +175,78 @@ func (c ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberReque
 	}, nil
 }
 
+// GetBlockByNumber height 为 0 时返回最新区块
 func (c ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
-	block, err := c.ethClient.BlockByNumber(big.NewInt(req.Height))
+	var blockNumber *big.Int
+	if req.Height != 0 {
+		blockNumber = big.NewInt(req.Height)
+	}
+	block, err := c.ethClient.BlockByNumber(blockNumber)
 	if err != nil {
-		log.Error("block by number error", err)
+		log.Error("block by number error", "err", err)
 		return &account.BlockResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "block by number error",
 		}, nil
 	}
-	var txListRet []*account.BlockInfoTransactionList
-	for _, v := range block.Transactions {
-		bitItem := &account.BlockInfoTransactionList{
-			From:   "0x000",
-			To:     v.To,
-			Hash:   v.Hash,
-			Time:   "0",
-			Amount: "10",
-			Fee:    "0",
-			Status: "1",
-		}
-		txListRet = append(txListRet, bitItem)
+	//按高度查询的区块属于节点当前的规范链, 记录下来用于发现重组
+	if _, err := c.tracker.Observe(block.TrackerHeader()); err != nil {
+		log.Warn("track block fail", "height", req.Height, "err", err)
 	}
-	return &account.BlockResponse{
-		Code:         common.ReturnCode_SUCCESS,
-		Msg:          "get block by number success",
-		Hash:         block.Hash.String(),
-		BaseFee:      block.BaseFee,
-		Transactions: txListRet,
-	}, nil
+	return BlockResponse(c.confirmer, block), nil
 }
 
 func (c ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
-	return nil, nil
+	if !ethcommon.IsHexAddress(req.Address) {
+		return &account.ValidAddressResponse{
+			Code:  common.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:             common.ReturnCode_SUCCESS,
+		Msg:              "valid address",
+		Valid:            true,
+		CanWithdrawal:    true,
+		CanonicalAddress: ethcommon.HexToAddress(req.Address).String(),
+	}, nil
 }
 
 func (c ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
-	//TODO implement me
-	panic("implement me")
+	block, err := c.ethClient.BlockByHash(ethcommon.HexToHash(req.Hash))
+	if err != nil {
+		log.Error("block by hash error", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "block by hash error",
+		}, nil
+	}
+	return BlockResponse(c.confirmer, block), nil
 }
 
+/**
+ * @description: 查询账户 nonce 与余额
+ * 余额通过 Multicall3 读取 (见 AccountBalance), 合约地址为空时为 ETH 余额, 否则为代币的 balanceOf
+ * block_tag 指定查询的区块 (latest, safe, finalized, pending 或高度), 为空时为最新区块, nonce 与余额读取同一区块
+ * sequence 为该区块的 nonce, 并发构造交易时应通过 ReserveNonce 分配 nonce
+ */
 func (c ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
-	nonceResult, err := c.ethClient.TxCountByAddress(ethcommon.HexToAddress(req.Address))
+	blockNumber, err := ParseBlockTag(req.BlockTag)
+	if err != nil {
+		return &account.AccountResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	if !ethcommon.IsHexAddress(req.Address) || (req.ContractAddress != "" && !ethcommon.IsHexAddress(req.ContractAddress)) {
+		return &account.AccountResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid address or contract address",
+		}, nil
+	}
+	address := ethcommon.HexToAddress(req.Address)
+	nonceResult, err := c.ethClient.NonceAt(address, blockNumber)
 	if err != nil {
 		log.Error("get nonce by account fail", "err:", err)
 		return &account.AccountResponse{
@@ -192,25 +254,22 @@ func (c ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountR
 			Msg:  "get nonce by address fail",
 		}, nil
 	}
-	//获取balance
-	//合约地址或者普通账户
-	balanceResult, err := c.ethDataClient.getBalanceByaddress(req.ContractAddress, req.Address)
+	balance, err := AccountBalance(c.ethClient, address, ethcommon.HexToAddress(req.ContractAddress), blockNumber)
 	if err != nil {
 		log.Error("get balance by address fail", "err:", err)
 		return &account.AccountResponse{
 			Code:    common.ReturnCode_ERROR,
 			Msg:     "get balance by address fail",
 			Balance: "0",
-		}, err
+		}, nil
 	}
 	return &account.AccountResponse{
 		Code:          common.ReturnCode_SUCCESS,
 		Msg:           "get account response success",
 		AccountNumber: "0",
 		Sequence:      nonceResult.String(),
-		Balance:       balanceResult.BalanceStr,
+		Balance:       balance.String(),
 	}, nil
-
 }
 
 func (c ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
@@ -223,19 +282,119 @@ func (c ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxRespons
 	panic("implement me")
 }
 
+/**
+ * @description: 通过区块浏览器查询地址的交易, contract_address 不为空时查询该代币的转账
+ * 确认数按节点的最新区块计算, 未达到确认条件的交易状态为 Pending
+ */
 func (c ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
-	//TODO implement me
-	panic("implement me")
+	action := chainaccount.EtherscanActionTxList
+	if req.ContractAddress != "" {
+		action = chainaccount.EtherscanActionTokenTx
+	}
+	txs, err := c.ethDataClient.GetTxByAddress(uint64(req.Page), uint64(req.Pagesize), req.Address, action)
+	if err != nil {
+		log.Error("get tx by address fail", "err", err)
+		return &account.TxAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by address fail",
+		}, nil
+	}
+	view, err := c.confirmer.View()
+	if err != nil {
+		log.Error("get confirmation view fail", "err", err)
+		return &account.TxAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get latest block fail",
+		}, nil
+	}
+	var txList []*account.TxMessage
+	for _, tx := range txs.TransactionList {
+		if req.ContractAddress != "" && !strings.EqualFold(tx.TokenContractAddress, req.ContractAddress) {
+			continue
+		}
+		height, _ := strconv.ParseUint(tx.Height, 10, 64)
+		value := tx.Amount
+		if req.ContractAddress != "" {
+			value = tx.TokenValue
+		}
+		txList = append(txList, &account.TxMessage{
+			Hash:            tx.TxId,
+			Froms:           []*account.Address{{Address: tx.From}},
+			Tos:             []*account.Address{{Address: tx.To}},
+			Values:          []*account.Value{{Value: value}},
+			Fee:             tx.TxFee,
+			Status:          view.Status(height, tx.IsError != "1"),
+			Height:          tx.Height,
+			ContractAddress: tx.TokenContractAddress,
+			Datetime:        tx.TransactionTime,
+			Confirmations:   view.Confirmations(height),
+		})
+	}
+	return &account.TxAddressResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txList,
+	}, nil
 }
 
+// GetTxByHash 交易未打包或未达到确认条件时状态为 Pending
 func (c ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
-	//TODO implement me
-	panic("implement me")
+	return TxByHashResponse(c.ethClient, c.confirmer, req.Hash, new(big.Int).SetUint64(global_const.EthereumChainId)), nil
 }
 
+// GetBlockByRange start/end 为十进制区块高度, 区块头需首尾相连, 最多返回 100 个区块
 func (c ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
-	//TODO implement me
-	panic("implement me")
+	return BlockByRangeResponse(c.ethClient, c.tracker, req, uint(global_const.EthereumChainId)), nil
+}
+
+// DetectReorg 判断调用方记录的区块是否已被重组, 返回分叉点与被重组掉的区块
+func (c ChainAdaptor) DetectReorg(req *account.ReorgRequest) (*account.ReorgResponse, error) {
+	return ReorgResponse(c.tracker, req), nil
+}
+
+// GetTransferLogs 查询区块范围内的 ERC-20/721/1155 转账事件, 最多 10000 个区块
+func (c ChainAdaptor) GetTransferLogs(req *account.TransferLogsRequest) (*account.TransferLogsResponse, error) {
+	return TransferLogsResponse(c.ethClient, req, uint(global_const.EthereumChainId)), nil
+}
+
+// GetNfts 查询地址持有的 NFT, 未指定区块范围时从区块浏览器的 NFT 转账记录收集
+func (c ChainAdaptor) GetNfts(req *account.NftsRequest) (*account.NftsResponse, error) {
+	return NftsResponse(c.ethClient, c.ethDataClient, req, uint(global_const.EthereumChainId)), nil
+}
+
+// GetNftMetadata 读取 NFT 的 tokenURI/uri
+func (c ChainAdaptor) GetNftMetadata(req *account.NftMetadataRequest) (*account.NftMetadataResponse, error) {
+	return NftMetadataResponse(c.ethClient, req), nil
+}
+
+// BuildNftTransfer 构造未签名的 ERC-721/1155 safeTransferFrom 交易
+func (c ChainAdaptor) BuildNftTransfer(req *account.NftTransferRequest) (*account.NftTransferResponse, error) {
+	return NftTransferResponse(c.ethClient, c.nonces, req, new(big.Int).SetUint64(global_const.EthereumChainId)), nil
+}
+
+// GetBalances 在同一区块批量查询原生币与 ERC-20 余额, 使用 Multicall3 或 JSON-RPC 批量请求
+func (c ChainAdaptor) GetBalances(req *account.BalancesRequest) (*account.BalancesResponse, error) {
+	return BalancesResponse(c.ethClient, req), nil
+}
+
+// ReserveNonce 在 pending nonce 之上预留地址的下一个可用 nonce
+func (c ChainAdaptor) ReserveNonce(req *account.ReserveNonceRequest) (*account.ReserveNonceResponse, error) {
+	return ReserveNonceResponse(c.nonces, req), nil
+}
+
+// ReleaseNonce 释放预留的 nonce, 返回广播失败留下的空缺
+func (c ChainAdaptor) ReleaseNonce(req *account.ReleaseNonceRequest) (*account.ReleaseNonceResponse, error) {
+	return ReleaseNonceResponse(c.nonces, req), nil
+}
+
+// SubscribeBlocks 推送新区块与重组事件, ws 节点使用 newHeads 订阅, 否则轮询
+func (c ChainAdaptor) SubscribeBlocks(ctx context.Context, req *account.SubscribeBlocksRequest, send func(*account.BlockEvent) error) error {
+	return SubscribeBlocks(ctx, c.ethClient, req, uint(global_const.EthereumChainId), send)
+}
+
+// WatchAddresses 推送监听地址的原生币与 ERC-20 转账, 确认数随新区块更新
+func (c ChainAdaptor) WatchAddresses(ctx context.Context, req *account.WatchAddressesRequest, send func(*account.TransferEvent) error) error {
+	return WatchAddresses(ctx, c.ethClient, c.confirmer, req, uint(global_const.EthereumChainId), send)
 }
 
 func (c ChainAdaptor) CreateUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
@@ -248,9 +407,9 @@ func (c ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionReque
 	panic("implement me")
 }
 
+// DecodeTransaction raw_tx 为十六进制的交易编码, 识别原生币, ERC-20 与 NFT 转账
 func (c ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
-	//TODO implement me
-	panic("implement me")
+	return DecodeTransactionResponse(c.ethClient, req.RawTx, new(big.Int).SetUint64(global_const.EthereumChainId)), nil
 }
 
 func (c ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
@@ -0,0 +1,587 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	chainaccount "github.com/dapplink-labs/chain-explorer-api/common/account"
+
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	// 单次查询最多校验的 NFT 数量, 每个都需要一次 eth_call
+	maxNftCandidates = 1000
+	// 区块浏览器转账记录每页条数与最多页数 (etherscan 最多返回 10000 条)
+	nftHistoryPageSize = 1000
+	nftHistoryMaxPages = 10
+	// 估算的 gas limit 上浮 20%, 接收方合约的回调消耗可能变化
+	gasLimitBufferPercent = 120
+)
+
+// ERC-165 接口 id
+var (
+	erc721InterfaceId  = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	erc1155InterfaceId = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+)
+
+var (
+	errNotNft = errors.New("contract is not ERC-721 or ERC-1155")
+	// errUnexpectedOutput 返回值无法解析, 通常是地址没有合约代码或合约未实现该方法
+	errUnexpectedOutput = errors.New("unexpected call output")
+)
+
+var (
+	erc20ABI = mustParseABI(`[
+		{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+		{"type":"function","name":"transferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}
+	]`)
+	erc721ABI = mustParseABI(`[
+		{"type":"function","name":"supportsInterface","inputs":[{"name":"interfaceId","type":"bytes4"}],"outputs":[{"name":"","type":"bool"}],"stateMutability":"view"},
+		{"type":"function","name":"ownerOf","inputs":[{"name":"tokenId","type":"uint256"}],"outputs":[{"name":"","type":"address"}],"stateMutability":"view"},
+		{"type":"function","name":"tokenURI","inputs":[{"name":"tokenId","type":"uint256"}],"outputs":[{"name":"","type":"string"}],"stateMutability":"view"},
+		{"type":"function","name":"transferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"outputs":[]},
+		{"type":"function","name":"safeTransferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"outputs":[]},
+		{"type":"function","name":"safeTransferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"},{"name":"data","type":"bytes"}],"outputs":[]}
+	]`)
+	erc1155ABI = mustParseABI(`[
+		{"type":"function","name":"balanceOf","inputs":[{"name":"account","type":"address"},{"name":"id","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+		{"type":"function","name":"uri","inputs":[{"name":"id","type":"uint256"}],"outputs":[{"name":"","type":"string"}],"stateMutability":"view"},
+		{"type":"function","name":"safeTransferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"id","type":"uint256"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"}],"outputs":[]},
+		{"type":"function","name":"safeBatchTransferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"ids","type":"uint256[]"},{"name":"values","type":"uint256[]"},{"name":"data","type":"bytes"}],"outputs":[]}
+	]`)
+)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// method 按签名查找方法, 同名重载的方法在 abi.ABI 中会被重命名, 不能按名字查找
+func method(a abi.ABI, sig string) abi.Method {
+	for _, m := range a.Methods {
+		if m.Sig == sig {
+			return m
+		}
+	}
+	panic("method not found: " + sig)
+}
+
+func packCall(m abi.Method, args ...interface{}) ([]byte, error) {
+	input, err := m.Inputs.Pack(args...)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, m.ID...), input...), nil
+}
+
+// callView 在 blockNumber 高度 (nil 为最新区块) 调用只读方法并解析返回值, 返回值无法解析时返回 errUnexpectedOutput
+func callView(client EthClient, contract ethcommon.Address, blockNumber *big.Int, m abi.Method, args ...interface{}) ([]interface{}, error) {
+	data, err := packCall(m, args...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.CallContract(ethereum.CallMsg{To: &contract, Data: data}, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	values, err := m.Outputs.Unpack(result)
+	if err != nil || len(values) == 0 {
+		return nil, fmt.Errorf("%w: %s returned %x", errUnexpectedOutput, m.Name, result)
+	}
+	return values, nil
+}
+
+// unsupportedCall 合约 revert 或返回值无法解析, 与节点不可用区分
+func unsupportedCall(err error) bool {
+	return errors.Is(err, errUnexpectedOutput) || strings.Contains(strings.ToLower(err.Error()), "revert")
+}
+
+/**
+ * @description: 通过 ERC-165 supportsInterface 判断合约是 ERC-721 还是 ERC-1155
+ * @param client 节点客户端
+ * @param contract 合约地址
+ */
+func DetectNftStandard(client EthClient, contract ethcommon.Address) (account.TokenStandard, error) {
+	supports := method(erc721ABI, "supportsInterface(bytes4)")
+	for _, candidate := range []struct {
+		id       [4]byte
+		standard account.TokenStandard
+	}{
+		{erc721InterfaceId, account.TokenStandard_ERC721},
+		{erc1155InterfaceId, account.TokenStandard_ERC1155},
+	} {
+		values, err := callView(client, contract, nil, supports, candidate.id)
+		if err != nil && unsupportedCall(err) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if ok, _ := values[0].(bool); ok {
+			return candidate.standard, nil
+		}
+	}
+	return 0, errNotNft
+}
+
+// parseTokenId 十进制 token id
+func parseTokenId(tokenId string) (*big.Int, bool) {
+	id, ok := new(big.Int).SetString(tokenId, 10)
+	if !ok || id.Sign() < 0 {
+		return nil, false
+	}
+	return id, true
+}
+
+// nftKey 候选 NFT
+type nftKey struct {
+	contract ethcommon.Address
+	tokenId  string
+}
+
+/**
+ * @description: 查询地址当前持有的 ERC-721/1155 NFT
+ * 先收集地址转入过的 NFT, 再逐个在链上校验当前持有情况 (ownerOf / balanceOf), 已转出的不返回
+ * end_height 不为 0 时从区块范围内的转账日志收集 (只能发现范围内转入的 NFT), 否则从区块浏览器的转账记录收集
+ * @param client 节点客户端
+ * @param explorer 区块浏览器, 为 nil 时必须指定区块范围
+ * @param req 查询请求, contract_address 不为空时只查询该合约
+ * @param chainId 链 id
+ */
+func NftsResponse(client EthClient, explorer *EthData, req *account.NftsRequest, chainId uint) *account.NftsResponse {
+	if !ethcommon.IsHexAddress(req.Address) {
+		return &account.NftsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}
+	}
+	owner := ethcommon.HexToAddress(req.Address)
+	var contracts []ethcommon.Address
+	if req.ContractAddress != "" {
+		if !ethcommon.IsHexAddress(req.ContractAddress) {
+			return &account.NftsResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "invalid contract address",
+			}
+		}
+		contracts = []ethcommon.Address{ethcommon.HexToAddress(req.ContractAddress)}
+	}
+
+	var (
+		candidates map[nftKey]account.TokenStandard
+		err        error
+	)
+	switch {
+	case req.EndHeight != 0:
+		if req.StartHeight > req.EndHeight || req.EndHeight-req.StartHeight >= maxTransferLogRange {
+			return &account.NftsResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  fmt.Sprintf("invalid block range, at most %d blocks", maxTransferLogRange),
+			}
+		}
+		candidates, err = nftsFromLogs(client, owner, contracts, req.StartHeight, req.EndHeight, chainId)
+	case explorer != nil:
+		candidates, err = nftsFromExplorer(explorer, owner, contracts)
+	default:
+		return &account.NftsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "block range is required",
+		}
+	}
+	if err != nil {
+		log.Error("collect nft transfers fail", "address", req.Address, "err", err)
+		return &account.NftsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "collect nft transfers fail",
+		}
+	}
+	if len(candidates) > maxNftCandidates {
+		return &account.NftsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  fmt.Sprintf("more than %d nfts to check, please specify contract_address", maxNftCandidates),
+		}
+	}
+
+	nfts := make([]*account.NftToken, 0, len(candidates))
+	for key, standard := range candidates {
+		amount, err := nftBalance(client, owner, key, standard)
+		if err != nil {
+			log.Error("check nft ownership fail", "contract", key.contract, "tokenId", key.tokenId, "err", err)
+			return &account.NftsResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "check nft ownership fail",
+			}
+		}
+		if amount.Sign() == 0 {
+			continue
+		}
+		nfts = append(nfts, &account.NftToken{
+			ContractAddress: key.contract.String(),
+			Standard:        standard,
+			TokenId:         key.tokenId,
+			Amount:          amount.String(),
+		})
+	}
+	sort.Slice(nfts, func(i, j int) bool {
+		if nfts[i].ContractAddress != nfts[j].ContractAddress {
+			return nfts[i].ContractAddress < nfts[j].ContractAddress
+		}
+		if len(nfts[i].TokenId) != len(nfts[j].TokenId) {
+			return len(nfts[i].TokenId) < len(nfts[j].TokenId)
+		}
+		return nfts[i].TokenId < nfts[j].TokenId
+	})
+	return &account.NftsResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get nfts success",
+		Nfts: nfts,
+	}
+}
+
+// nftsFromLogs 区块范围内转入 owner 的 NFT
+func nftsFromLogs(client EthClient, owner ethcommon.Address, contracts []ethcommon.Address, start, end uint64, chainId uint) (map[nftKey]account.TokenStandard, error) {
+	transfers, err := scanTransferLogs(client, start, end, &owner, contracts, chainId)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make(map[nftKey]account.TokenStandard)
+	for _, transfer := range transfers {
+		if transfer.Standard == account.TokenStandard_ERC20 || ethcommon.HexToAddress(transfer.To) != owner {
+			continue
+		}
+		candidates[nftKey{contract: ethcommon.HexToAddress(transfer.ContractAddress), tokenId: transfer.TokenId}] = transfer.Standard
+	}
+	return candidates, nil
+}
+
+// nftsFromExplorer 区块浏览器记录中转入 owner 的 NFT, 超过 etherscan 的返回上限时只包含最早的记录
+func nftsFromExplorer(explorer *EthData, owner ethcommon.Address, contracts []ethcommon.Address) (map[nftKey]account.TokenStandard, error) {
+	candidates := make(map[nftKey]account.TokenStandard)
+	actions := map[chainaccount.ActionType]account.TokenStandard{
+		chainaccount.EtherscanActionTokenNftTx:  account.TokenStandard_ERC721,
+		chainaccount.EtherscanActionToken1155Tx: account.TokenStandard_ERC1155,
+	}
+	for action, standard := range actions {
+		for page := uint64(1); page <= nftHistoryMaxPages; page++ {
+			txs, err := explorer.GetTxByAddress(page, nftHistoryPageSize, owner.String(), action)
+			if err != nil {
+				return nil, err
+			}
+			for _, tx := range txs.TransactionList {
+				contract := ethcommon.HexToAddress(tx.TokenContractAddress)
+				if !ethcommon.IsHexAddress(tx.To) || ethcommon.HexToAddress(tx.To) != owner || !containsAddress(contracts, contract) {
+					continue
+				}
+				if _, ok := parseTokenId(tx.TokenId); !ok {
+					continue
+				}
+				candidates[nftKey{contract: contract, tokenId: tx.TokenId}] = standard
+			}
+			if len(txs.TransactionList) < nftHistoryPageSize {
+				break
+			}
+			if page == nftHistoryMaxPages {
+				log.Warn("nft transfer history truncated", "address", owner, "action", action)
+			}
+		}
+	}
+	return candidates, nil
+}
+
+// containsAddress contracts 为空时不过滤
+func containsAddress(contracts []ethcommon.Address, contract ethcommon.Address) bool {
+	if len(contracts) == 0 {
+		return true
+	}
+	for _, c := range contracts {
+		if c == contract {
+			return true
+		}
+	}
+	return false
+}
+
+// nftBalance owner 当前持有的数量, 已销毁 (ownerOf revert) 或合约不符合标准时为 0
+func nftBalance(client EthClient, owner ethcommon.Address, key nftKey, standard account.TokenStandard) (*big.Int, error) {
+	id, _ := parseTokenId(key.tokenId)
+	var (
+		values []interface{}
+		err    error
+	)
+	if standard == account.TokenStandard_ERC1155 {
+		values, err = callView(client, key.contract, nil, method(erc1155ABI, "balanceOf(address,uint256)"), owner, id)
+	} else {
+		values, err = callView(client, key.contract, nil, method(erc721ABI, "ownerOf(uint256)"), id)
+	}
+	if err != nil && unsupportedCall(err) {
+		log.Warn("nft not readable, skipped", "contract", key.contract, "tokenId", key.tokenId, "err", err)
+		return new(big.Int), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch value := values[0].(type) {
+	case *big.Int:
+		return value, nil
+	case ethcommon.Address:
+		if value == owner {
+			return big.NewInt(1), nil
+		}
+	}
+	return new(big.Int), nil
+}
+
+/**
+ * @description: 读取 NFT 的元数据地址, ERC-721 为 tokenURI, ERC-1155 为 uri 并替换其中的 {id}
+ * 只返回地址, 不请求元数据内容
+ * @param client 节点客户端
+ * @param req 查询请求
+ */
+func NftMetadataResponse(client EthClient, req *account.NftMetadataRequest) *account.NftMetadataResponse {
+	if !ethcommon.IsHexAddress(req.ContractAddress) {
+		return &account.NftMetadataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid contract address",
+		}
+	}
+	id, ok := parseTokenId(req.TokenId)
+	if !ok {
+		return &account.NftMetadataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid token id",
+		}
+	}
+	contract := ethcommon.HexToAddress(req.ContractAddress)
+	standard, err := DetectNftStandard(client, contract)
+	if err != nil {
+		log.Error("detect nft standard fail", "contract", req.ContractAddress, "err", err)
+		return &account.NftMetadataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}
+	}
+	m := method(erc721ABI, "tokenURI(uint256)")
+	if standard == account.TokenStandard_ERC1155 {
+		m = method(erc1155ABI, "uri(uint256)")
+	}
+	values, err := callView(client, contract, nil, m, id)
+	if err != nil {
+		log.Error("read token uri fail", "contract", req.ContractAddress, "tokenId", req.TokenId, "err", err)
+		return &account.NftMetadataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "read token uri fail",
+		}
+	}
+	uri := values[0].(string)
+	if standard == account.TokenStandard_ERC1155 {
+		//ERC-1155 规定 {id} 替换为 64 位小写十六进制, 不带 0x
+		uri = strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", id))
+	}
+	return &account.NftMetadataResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "get nft metadata success",
+		Standard: standard,
+		TokenUri: uri,
+	}
+}
+
+/**
+ * @description: 构造未签名的 NFT safeTransferFrom 交易 (EIP-1559)
+ * ERC-721 的 amount 为空或 1, data 不为空时调用带 data 的 safeTransferFrom; ERC-1155 的 amount 必填
+ * nonce 为空时通过 nonces 在 pending nonce 之上预留 (有效期 DefaultNonceReservationTtl, 广播后节点计入即释放), gas_limit 为 0 时按 eth_estimateGas 上浮 20%, 手续费为空时按最新区块的 base fee 计算
+ * 返回交易的 RLP 编码与待签名哈希, 均为 0x 开头的十六进制
+ * @param client 节点客户端
+ * @param nonces 适配器的 nonce 分配器
+ * @param req 构造请求
+ * @param chainId 链 id
+ */
+func NftTransferResponse(client EthClient, nonces *NonceAllocator, req *account.NftTransferRequest, chainId *big.Int) *account.NftTransferResponse {
+	if !ethcommon.IsHexAddress(req.From) || !ethcommon.IsHexAddress(req.To) {
+		return &account.NftTransferResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid from or to address",
+		}
+	}
+	if !ethcommon.IsHexAddress(req.ContractAddress) {
+		return &account.NftTransferResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid contract address",
+		}
+	}
+	id, ok := parseTokenId(req.TokenId)
+	if !ok {
+		return &account.NftTransferResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid token id",
+		}
+	}
+	var extra []byte
+	if req.Data != "" {
+		decoded, err := hexutil.Decode(req.Data)
+		if err != nil {
+			return &account.NftTransferResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "invalid data",
+			}
+		}
+		extra = decoded
+	}
+	from, to := ethcommon.HexToAddress(req.From), ethcommon.HexToAddress(req.To)
+	contract := ethcommon.HexToAddress(req.ContractAddress)
+
+	standard, err := DetectNftStandard(client, contract)
+	if err != nil {
+		log.Error("detect nft standard fail", "contract", req.ContractAddress, "err", err)
+		return &account.NftTransferResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}
+	}
+	var data []byte
+	switch standard {
+	case account.TokenStandard_ERC721:
+		if req.Amount != "" && req.Amount != "1" {
+			return &account.NftTransferResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "amount of ERC-721 transfer must be 1",
+			}
+		}
+		if extra == nil {
+			data, err = packCall(method(erc721ABI, "safeTransferFrom(address,address,uint256)"), from, to, id)
+		} else {
+			data, err = packCall(method(erc721ABI, "safeTransferFrom(address,address,uint256,bytes)"), from, to, id, extra)
+		}
+	case account.TokenStandard_ERC1155:
+		amount, ok := new(big.Int).SetString(req.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			return &account.NftTransferResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "invalid amount",
+			}
+		}
+		if extra == nil {
+			extra = []byte{}
+		}
+		data, err = packCall(method(erc1155ABI, "safeTransferFrom(address,address,uint256,uint256,bytes)"), from, to, id, amount, extra)
+	}
+	if err != nil {
+		log.Error("pack safeTransferFrom fail", "err", err)
+		return &account.NftTransferResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "pack safeTransferFrom fail",
+		}
+	}
+
+	var nonce uint64
+	if req.Nonce == "" {
+		//与 ReserveNonce 共用分配器, 避免与待打包交易及并发构造的交易使用相同 nonce
+		reservation, err := nonces.Reserve(from, DefaultNonceReservationTtl)
+		if err != nil {
+			log.Error("reserve nonce fail", "address", req.From, "err", err)
+			return &account.NftTransferResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get nonce fail",
+			}
+		}
+		nonce = reservation.Nonce
+	} else if nonce, err = strconv.ParseUint(req.Nonce, 10, 64); err != nil {
+		return &account.NftTransferResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid nonce",
+		}
+	}
+	gasLimit := req.GasLimit
+	if gasLimit == 0 {
+		estimated, err := client.EstimateGas(ethereum.CallMsg{From: from, To: &contract, Data: data})
+		if err != nil {
+			return &account.NftTransferResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "estimate gas fail: " + err.Error(),
+			}
+		}
+		gasLimit = estimated * gasLimitBufferPercent / 100
+	}
+	gasTipCap, gasFeeCap, err := dynamicFees(client, req.MaxPriorityFeePerGas, req.MaxFeePerGas)
+	if err != nil {
+		log.Error("get fee fail", "err", err)
+		return &account.NftTransferResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get fee fail",
+		}
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainId,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        &contract,
+		Value:     new(big.Int),
+		Data:      data,
+	})
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		log.Error("marshal tx fail", "err", err)
+		return &account.NftTransferResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "marshal tx fail",
+		}
+	}
+	return &account.NftTransferResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "build nft transfer success",
+		Standard: standard,
+		UnSignTx: hexutil.Encode(rawTx),
+		SignHash: types.LatestSignerForChainID(chainId).Hash(tx).String(),
+	}
+}
+
+// dynamicFees 解析十进制的手续费, 为空时小费取 eth_maxPriorityFeePerGas, 上限取 2 * baseFee + 小费
+func dynamicFees(client EthClient, maxPriorityFeePerGas, maxFeePerGas string) (*big.Int, *big.Int, error) {
+	var gasTipCap *big.Int
+	if maxPriorityFeePerGas == "" {
+		tip, err := client.SuggestGasTipCap()
+		if err != nil {
+			return nil, nil, err
+		}
+		gasTipCap = tip
+	} else if tip, ok := new(big.Int).SetString(maxPriorityFeePerGas, 10); ok && tip.Sign() >= 0 {
+		gasTipCap = tip
+	} else {
+		return nil, nil, fmt.Errorf("invalid max_priority_fee_per_gas %s", maxPriorityFeePerGas)
+	}
+	if maxFeePerGas != "" {
+		feeCap, ok := new(big.Int).SetString(maxFeePerGas, 10)
+		if !ok || feeCap.Cmp(gasTipCap) < 0 {
+			return nil, nil, fmt.Errorf("invalid max_fee_per_gas %s", maxFeePerGas)
+		}
+		return gasTipCap, feeCap, nil
+	}
+	latest, err := client.BlockHeaderByNumber(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if latest.BaseFee == nil {
+		return nil, nil, errors.New("latest block has no base fee")
+	}
+	feeCap := new(big.Int).Mul(latest.BaseFee, big.NewInt(2))
+	return gasTipCap, feeCap.Add(feeCap, gasTipCap), nil
+}
@@ -0,0 +1,295 @@
+package ethereum
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	// DefaultNonceReservationTtl 请求未指定 ttl 时预留的有效期, 超时未释放的 nonce 可重新分配
+	DefaultNonceReservationTtl = 2 * time.Minute
+	// MaxNonceReservationTtl 预留的最长有效期
+	MaxNonceReservationTtl = 30 * time.Minute
+	// 已广播的 nonce 的保留时间, 超过后仍未计入节点的 pending nonce 视为交易已被丢弃, 可重新分配
+	broadcastedNonceRetention = 30 * time.Minute
+)
+
+// ErrNonceReservationNotFound 预留不存在, 已过期被重新分配, 或 reservation_id 不匹配
+var ErrNonceReservationNotFound = errors.New("nonce reservation not found")
+
+type nonceReservation struct {
+	id          string
+	expiresAt   time.Time
+	broadcasted bool
+}
+
+// NonceReservation 一次分配的结果, Gaps 为分配后仍存在的空缺 nonce, 按升序
+type NonceReservation struct {
+	Nonce        uint64
+	Id           string
+	ExpiresAt    time.Time
+	PendingNonce uint64
+	Gaps         []uint64
+}
+
+/**
+ * @description: 按地址分配 nonce, 只记录在本进程内
+ * 在节点的 pending nonce 之上跳过本地已预留或已广播的 nonce, 分配最小的空闲值, 因此空缺会被优先补上;
+ * 低于 pending nonce 的记录已被节点计入, 到期的记录被丢弃
+ * 空缺指 pending nonce 与本地记录的最大 nonce 之间没有被持有的 nonce, 通常由广播失败或预留过期造成,
+ * 存在空缺时更高 nonce 的交易不会被打包
+ */
+type NonceAllocator struct {
+	client EthClient
+	*nonceState
+}
+
+// nonceState 预留记录, 与节点客户端分开保存, 热加载重建适配器后继续使用
+type nonceState struct {
+	network string
+
+	lock sync.Mutex
+	//地址 -> nonce -> 预留
+	reservations map[ethcommon.Address]map[uint64]*nonceReservation
+}
+
+// 链名 -> 预留记录
+var (
+	nonceStatesLock sync.Mutex
+	nonceStates     = make(map[string]*nonceState)
+)
+
+func newNonceState(network string) *nonceState {
+	return &nonceState{
+		network:      network,
+		reservations: make(map[ethcommon.Address]map[uint64]*nonceReservation),
+	}
+}
+
+// NewNonceAllocator 独立的分配器, 预留记录只属于该实例
+func NewNonceAllocator(client EthClient) *NonceAllocator {
+	return &NonceAllocator{client: client, nonceState: newNonceState("")}
+}
+
+/**
+ * @description: 适配器使用的分配器, 同一条链共享预留记录, 热加载重建适配器后未释放的预留仍然有效
+ * network 变化时 chain id 不同, 原有预留作废
+ * @param chainName 链名
+ * @param network 网络
+ * @param client 新适配器的节点客户端
+ */
+func SharedNonceAllocator(chainName, network string, client EthClient) *NonceAllocator {
+	nonceStatesLock.Lock()
+	defer nonceStatesLock.Unlock()
+	state := nonceStates[chainName]
+	if state == nil || state.network != network {
+		if state != nil {
+			log.Warn("network changed, drop nonce reservations", "chain", chainName, "from", state.network, "to", network)
+		}
+		state = newNonceState(network)
+		nonceStates[chainName] = state
+	}
+	return &NonceAllocator{client: client, nonceState: state}
+}
+
+/**
+ * @description: 预留地址的下一个可用 nonce
+ * @param address 地址
+ * @param ttl 有效期, 到期前需调用 Release
+ */
+func (a *NonceAllocator) Reserve(address ethcommon.Address, ttl time.Duration) (*NonceReservation, error) {
+	pending, err := a.client.PendingNonceAt(address)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newReservationId()
+	if err != nil {
+		return nil, err
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	reserved := a.prune(address, pending)
+	nonce := pending
+	for reserved[nonce] != nil {
+		nonce++
+	}
+	expiresAt := time.Now().Add(ttl)
+	reserved[nonce] = &nonceReservation{id: id, expiresAt: expiresAt}
+	return &NonceReservation{
+		Nonce:        nonce,
+		Id:           id,
+		ExpiresAt:    expiresAt,
+		PendingNonce: pending,
+		Gaps:         nonceGaps(reserved, pending),
+	}, nil
+}
+
+/**
+ * @description: 释放预留的 nonce
+ * 广播成功的 nonce 继续保留, 直到节点的 pending nonce 超过它; 广播失败的 nonce 立即释放, 若有更高的 nonce 被持有则形成空缺
+ * @param address 地址
+ * @param nonce 预留的 nonce
+ * @param id 预留 id
+ * @param broadcasted 交易是否已成功广播
+ * @return 节点的 pending nonce 与释放后的空缺
+ */
+func (a *NonceAllocator) Release(address ethcommon.Address, nonce uint64, id string, broadcasted bool) (uint64, []uint64, error) {
+	pending, err := a.client.PendingNonceAt(address)
+	if err != nil {
+		return 0, nil, err
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	reservation := a.reservations[address][nonce]
+	switch {
+	case reservation != nil && reservation.id == id:
+		if broadcasted {
+			reservation.broadcasted = true
+			reservation.expiresAt = time.Now().Add(broadcastedNonceRetention)
+		} else {
+			delete(a.reservations[address], nonce)
+		}
+	//已广播的交易可能已被节点计入并清理了记录
+	case broadcasted && nonce < pending:
+	default:
+		return 0, nil, ErrNonceReservationNotFound
+	}
+	reserved := a.prune(address, pending)
+	//没有记录的地址不再保留
+	if len(reserved) == 0 {
+		delete(a.reservations, address)
+	}
+	gaps := nonceGaps(reserved, pending)
+	if len(gaps) > 0 {
+		log.Warn("nonce gaps detected", "address", address, "pending", pending, "gaps", gaps)
+	}
+	return pending, gaps, nil
+}
+
+// prune 清理已被节点计入或已到期的记录, 返回地址的记录 (不为 nil); 调用方需持有锁
+func (a *NonceAllocator) prune(address ethcommon.Address, pending uint64) map[uint64]*nonceReservation {
+	reserved := a.reservations[address]
+	if reserved == nil {
+		reserved = make(map[uint64]*nonceReservation)
+		a.reservations[address] = reserved
+	}
+	now := time.Now()
+	for nonce, reservation := range reserved {
+		if nonce < pending || now.After(reservation.expiresAt) {
+			delete(reserved, nonce)
+		}
+	}
+	return reserved
+}
+
+// nonceGaps pending nonce 与最大记录之间未被持有的 nonce, 按升序
+func nonceGaps(reserved map[uint64]*nonceReservation, pending uint64) []uint64 {
+	nonces := make([]uint64, 0, len(reserved))
+	for nonce := range reserved {
+		nonces = append(nonces, nonce)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	var gaps []uint64
+	next := pending
+	for _, nonce := range nonces {
+		for ; next < nonce; next++ {
+			gaps = append(gaps, next)
+		}
+		next = nonce + 1
+	}
+	return gaps
+}
+
+func newReservationId() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+/**
+ * @description: ReserveNonce 的公共实现
+ * @param allocator nonce 分配器
+ * @param req 请求, ttl_seconds 为 0 时使用 DefaultNonceReservationTtl
+ */
+func ReserveNonceResponse(allocator *NonceAllocator, req *account.ReserveNonceRequest) *account.ReserveNonceResponse {
+	if !ethcommon.IsHexAddress(req.Address) {
+		return &account.ReserveNonceResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}
+	}
+	//先比较秒数再转换, 过大的 ttl_seconds 转为 time.Duration 会溢出为负数
+	if req.TtlSeconds > uint64(MaxNonceReservationTtl/time.Second) {
+		return &account.ReserveNonceResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "ttl exceeds " + MaxNonceReservationTtl.String(),
+		}
+	}
+	ttl := DefaultNonceReservationTtl
+	if req.TtlSeconds != 0 {
+		ttl = time.Duration(req.TtlSeconds) * time.Second
+	}
+	reservation, err := allocator.Reserve(ethcommon.HexToAddress(req.Address), ttl)
+	if err != nil {
+		log.Error("reserve nonce fail", "address", req.Address, "err", err)
+		return &account.ReserveNonceResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "reserve nonce fail",
+		}
+	}
+	return &account.ReserveNonceResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "reserve nonce success",
+		Nonce:         reservation.Nonce,
+		ReservationId: reservation.Id,
+		ExpiresAt:     reservation.ExpiresAt.Unix(),
+		PendingNonce:  reservation.PendingNonce,
+		Gaps:          reservation.Gaps,
+	}
+}
+
+/**
+ * @description: ReleaseNonce 的公共实现, 返回释放后的空缺供调用方补发交易
+ * @param allocator nonce 分配器
+ * @param req 请求, broadcasted 表示交易是否已成功广播
+ */
+func ReleaseNonceResponse(allocator *NonceAllocator, req *account.ReleaseNonceRequest) *account.ReleaseNonceResponse {
+	if !ethcommon.IsHexAddress(req.Address) {
+		return &account.ReleaseNonceResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}
+	}
+	pending, gaps, err := allocator.Release(ethcommon.HexToAddress(req.Address), req.Nonce, req.ReservationId, req.Broadcasted)
+	if errors.Is(err, ErrNonceReservationNotFound) {
+		return &account.ReleaseNonceResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "nonce reservation not found or expired",
+		}
+	}
+	if err != nil {
+		log.Error("release nonce fail", "address", req.Address, "nonce", req.Nonce, "err", err)
+		return &account.ReleaseNonceResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "release nonce fail",
+		}
+	}
+	return &account.ReleaseNonceResponse{
+		Code:         common.ReturnCode_SUCCESS,
+		Msg:          "release nonce success",
+		PendingNonce: pending,
+		Gaps:         gaps,
+	}
+}
@@ -0,0 +1,151 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+var nonceTestAddress = ethcommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+// newPendingNonceClient 节点的 pending nonce 为 *pending
+func newPendingNonceClient(t *testing.T, pending *atomic.Uint64) *clnt {
+	return newTestClient(t, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+		var tag string
+		decodeParam(t, params, 1, &tag)
+		if method != "eth_getTransactionCount" || tag != "pending" {
+			t.Errorf("unexpected call %s at %s", method, tag)
+		}
+		return hexutil.Uint64(pending.Load())
+	})
+}
+
+func reserveNonce(t *testing.T, a *NonceAllocator, ttl time.Duration) *NonceReservation {
+	t.Helper()
+	reservation, err := a.Reserve(nonceTestAddress, ttl)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	return reservation
+}
+
+func TestNonceAllocator(t *testing.T) {
+	var pending atomic.Uint64
+	pending.Store(5)
+	a := NewNonceAllocator(newPendingNonceClient(t, &pending))
+
+	first := reserveNonce(t, a, time.Minute)
+	second := reserveNonce(t, a, time.Minute)
+	third := reserveNonce(t, a, time.Minute)
+	if first.Nonce != 5 || second.Nonce != 6 || third.Nonce != 7 {
+		t.Fatalf("nonces = %d %d %d, want 5 6 7", first.Nonce, second.Nonce, third.Nonce)
+	}
+
+	//广播失败的 6 形成空缺, 下一次分配补上空缺
+	_, gaps, err := a.Release(nonceTestAddress, second.Nonce, second.Id, false)
+	if err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if !reflect.DeepEqual(gaps, []uint64{6}) {
+		t.Errorf("gaps = %v, want [6]", gaps)
+	}
+	if refill := reserveNonce(t, a, time.Minute); refill.Nonce != 6 || len(refill.Gaps) != 0 {
+		t.Errorf("refill = %d gaps %v, want 6 without gaps", refill.Nonce, refill.Gaps)
+	}
+
+	if _, _, err := a.Release(nonceTestAddress, third.Nonce, "wrong", false); err != ErrNonceReservationNotFound {
+		t.Errorf("release with wrong id: err = %v", err)
+	}
+
+	//节点计入 5 与 6 后, 已广播的 7 仍被持有
+	if _, _, err := a.Release(nonceTestAddress, third.Nonce, third.Id, true); err != nil {
+		t.Fatalf("Release broadcasted: %v", err)
+	}
+	pending.Store(7)
+	if next := reserveNonce(t, a, time.Minute); next.Nonce != 8 || next.PendingNonce != 7 {
+		t.Errorf("next = %d pending %d, want 8 pending 7", next.Nonce, next.PendingNonce)
+	}
+	//已被节点计入的已广播 nonce 可以释放
+	pending.Store(9)
+	if _, _, err := a.Release(nonceTestAddress, 7, third.Id, true); err != nil {
+		t.Errorf("release counted nonce: %v", err)
+	}
+}
+
+func TestNonceAllocatorExpiry(t *testing.T) {
+	var pending atomic.Uint64
+	a := NewNonceAllocator(newPendingNonceClient(t, &pending))
+	expired := reserveNonce(t, a, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if again := reserveNonce(t, a, time.Minute); again.Nonce != expired.Nonce {
+		t.Errorf("nonce after expiry = %d, want %d", again.Nonce, expired.Nonce)
+	}
+	if _, _, err := a.Release(nonceTestAddress, expired.Nonce, expired.Id, false); err != ErrNonceReservationNotFound {
+		t.Errorf("release expired reservation: err = %v", err)
+	}
+}
+
+func TestSharedNonceAllocator(t *testing.T) {
+	var pending atomic.Uint64
+	client := newPendingNonceClient(t, &pending)
+	const chainName = "SharedNonceTest"
+
+	before := SharedNonceAllocator(chainName, "mainnet", client)
+	held := reserveNonce(t, before, time.Minute)
+	//热加载重建适配器
+	after := SharedNonceAllocator(chainName, "mainnet", client)
+	if next := reserveNonce(t, after, time.Minute); next.Nonce != held.Nonce+1 {
+		t.Errorf("nonce after rebuild = %d, want %d", next.Nonce, held.Nonce+1)
+	}
+	if _, _, err := after.Release(nonceTestAddress, held.Nonce, held.Id, false); err != nil {
+		t.Errorf("release reservation made before rebuild: %v", err)
+	}
+
+	switched := SharedNonceAllocator(chainName, "testnet", client)
+	if next := reserveNonce(t, switched, time.Minute); next.Nonce != 0 {
+		t.Errorf("nonce after network change = %d, want 0", next.Nonce)
+	}
+}
+
+func TestReserveNonceTtl(t *testing.T) {
+	var pending atomic.Uint64
+	a := NewNonceAllocator(newPendingNonceClient(t, &pending))
+	maxSeconds := uint64(MaxNonceReservationTtl / time.Second)
+	tests := []struct {
+		ttlSeconds uint64
+		wantCode   common.ReturnCode
+		wantTtl    time.Duration
+	}{
+		{0, common.ReturnCode_SUCCESS, DefaultNonceReservationTtl},
+		{60, common.ReturnCode_SUCCESS, time.Minute},
+		{maxSeconds, common.ReturnCode_SUCCESS, MaxNonceReservationTtl},
+		{maxSeconds + 1, common.ReturnCode_ERROR, 0},
+		//转换为 time.Duration 会溢出为负数
+		{uint64(math.MaxInt64/int64(time.Second)) + 1, common.ReturnCode_ERROR, 0},
+		{math.MaxUint64, common.ReturnCode_ERROR, 0},
+	}
+	for _, tt := range tests {
+		start := time.Now()
+		resp := ReserveNonceResponse(a, &account.ReserveNonceRequest{Address: nonceTestAddress.Hex(), TtlSeconds: tt.ttlSeconds})
+		if resp.Code != tt.wantCode {
+			t.Errorf("ttl %d: code = %v (%s), want %v", tt.ttlSeconds, resp.Code, resp.Msg, tt.wantCode)
+			continue
+		}
+		if resp.Code != common.ReturnCode_SUCCESS {
+			continue
+		}
+		expiresIn := time.Unix(resp.ExpiresAt, 0).Sub(start)
+		if expiresIn < tt.wantTtl-time.Second || expiresIn > tt.wantTtl+time.Second {
+			t.Errorf("ttl %d: expires in %s, want %s", tt.ttlSeconds, expiresIn, tt.wantTtl)
+		}
+	}
+}
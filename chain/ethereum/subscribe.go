@@ -0,0 +1,301 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/CavnHan/wallet-chain-account/chain/tracker"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+var (
+	// errSubscriptionStopped 已推送错误事件, 正常结束推送
+	errSubscriptionStopped = errors.New("subscription stopped")
+	// errRetryLater 节点暂时不可用, 等待下一轮
+	errRetryLater = errors.New("retry later")
+)
+
+const (
+	// 节点不支持 newHeads 订阅 (http 连接) 时轮询最新区块的间隔
+	defaultPollInterval = 3 * time.Second
+	// 订阅 newHeads 时仍定期检查, 防止漏掉通知
+	subscribedPollInterval = 30 * time.Second
+)
+
+/**
+ * @description: 推送新区块, 直到 ctx 结束或推送失败
+ * ws 连接使用 eth_subscribe("newHeads") 触发, 否则轮询; 每次都按高度补齐到最新区块, 因此通知丢失或重连不会漏块
+ * 已推送的区块被重组时先推送 reorged 事件 (分叉点与孤块), 再从分叉点之后重新推送
+ * from_height 为 0 时从最新区块开始; 断线重连时传入上次推送的高度 + 1 与 last_hash (上次推送的区块哈希),
+ * last_hash 已不在规范链上时先推送重组事件
+ * 订阅失败以 code 为 ERROR 的事件结束推送
+ * @param ctx 订阅的上下文
+ * @param client 节点客户端
+ * @param req 订阅请求
+ * @param chainId 链 id, 部分链需分组查询区块头
+ * @param send 推送事件
+ */
+func SubscribeBlocks(ctx context.Context, client EthClient, req *account.SubscribeBlocksRequest, chainId uint, send func(*account.BlockEvent) error) error {
+	handler := &blockEvents{client: client, fullBlock: req.FullBlock, send: send}
+	return subscribe(ctx, client, req.FromHeight, req.LastHash, chainId, handler)
+}
+
+// blockHandler 按高度顺序处理订阅到的规范链区块
+type blockHandler interface {
+	// onBlock 处理新区块, 节点请求失败时返回 *nodeError, 下一轮重试该区块
+	onBlock(header *RpcHeader) error
+	// onReorg 已处理的区块被重组, 之后从分叉点之后重新处理
+	onReorg(reorg *tracker.Reorg) error
+	// onError 订阅失败, 推送错误事件
+	onError(msg string, height uint64) error
+}
+
+// nodeError 节点暂时不可用, 等待下一轮重试
+type nodeError struct {
+	msg string
+	err error
+}
+
+func (e *nodeError) Error() string {
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *nodeError) Unwrap() error {
+	return e.err
+}
+
+/**
+ * @description: 按高度顺序把规范链区块交给 handler 处理, 直到 ctx 结束或处理失败
+ * @param from 起始高度, 0 表示最新区块
+ * @param lastHash 调用方已处理的 from - 1 高度的区块哈希, 为空时不检查
+ */
+func subscribe(ctx context.Context, client EthClient, from uint64, lastHash string, chainId uint, handler blockHandler) error {
+	s := &blockSubscription{
+		client: client,
+		//每个订阅单独记录已处理的区块, 与其他订阅和查询互不影响
+		tracker:  NewTracker(client),
+		chainId:  chainId,
+		handler:  handler,
+		from:     from,
+		lastHash: lastHash,
+	}
+	if err := s.run(ctx); !errors.Is(err, errSubscriptionStopped) {
+		return err
+	}
+	return nil
+}
+
+type blockSubscription struct {
+	client   EthClient
+	tracker  *tracker.Tracker
+	chainId  uint
+	handler  blockHandler
+	from     uint64
+	lastHash string
+	//下一个要处理的高度
+	next uint64
+}
+
+func (s *blockSubscription) run(ctx context.Context) error {
+	if err := s.resume(); err != nil {
+		return s.fail("resume subscription fail", err)
+	}
+
+	heads := make(chan *RpcHeader, 16)
+	var subErr <-chan error
+	sub, err := s.client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		log.Info("newHeads subscription unavailable, polling instead", "err", err)
+	} else {
+		defer sub.Unsubscribe()
+		subErr = sub.Err()
+	}
+	for {
+		if err := s.catchUp(); err != nil && !errors.Is(err, errRetryLater) {
+			return err
+		}
+		interval := defaultPollInterval
+		if subErr != nil {
+			interval = subscribedPollInterval
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heads:
+		case err := <-subErr:
+			log.Warn("newHeads subscription dropped, polling instead", "err", err)
+			subErr = nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// resume 确定起始高度, 调用方的上一个区块已被重组时推送重组事件
+func (s *blockSubscription) resume() error {
+	if s.from == 0 {
+		latest, err := s.client.BlockHeaderByNumber(nil)
+		if err != nil {
+			return err
+		}
+		s.next = latest.Number.Uint64()
+		return nil
+	}
+	s.next = s.from
+	if s.lastHash == "" {
+		return nil
+	}
+	reorg, _, err := s.tracker.FindFork(s.next-1, ethcommon.HexToHash(s.lastHash).String())
+	if err != nil {
+		return err
+	}
+	if reorg != nil {
+		if err := s.handler.onReorg(reorg); err != nil {
+			return err
+		}
+		s.next = reorg.ForkHeight + 1
+	}
+	return nil
+}
+
+/**
+ * @description: 推送 next 到最新区块之间的区块
+ * 节点请求失败或区块头不连续时返回 errRetryLater 等待下一轮; 推送失败, 回溯过深或连接已关闭 (适配器被热加载替换) 时结束订阅
+ */
+func (s *blockSubscription) catchUp() error {
+	latest, err := s.client.BlockHeaderByNumber(nil)
+	if err != nil {
+		return s.retry("get latest block header fail", err)
+	}
+	//最新高度未超过已推送的高度时, 同高度的区块也可能被替换
+	if latest.Number.Uint64() < s.next {
+		if err := s.observe([]RpcHeader{*latest}); err != nil {
+			return err
+		}
+	}
+	for s.next <= latest.Number.Uint64() {
+		end := s.next + maxBlockRange - 1
+		if end > latest.Number.Uint64() {
+			end = latest.Number.Uint64()
+		}
+		headers, err := s.client.BlockHeadersByRange(new(big.Int).SetUint64(s.next), new(big.Int).SetUint64(end), s.chainId)
+		if err != nil {
+			return s.retry("get block headers by range fail", err)
+		}
+		if len(headers) == 0 {
+			return nil
+		}
+		next := s.next
+		if err := s.observe(headers); err != nil {
+			return err
+		}
+		//已推送的区块被重组, 从分叉点之后重新推送
+		if s.next != next {
+			continue
+		}
+		for i := range headers {
+			var nodeErr *nodeError
+			if err := s.handler.onBlock(&headers[i]); errors.As(err, &nodeErr) {
+				return s.retry(nodeErr.msg, nodeErr.err)
+			} else if err != nil {
+				return err
+			}
+			s.next = headers[i].Number.Uint64() + 1
+		}
+	}
+	return nil
+}
+
+// observe 记录区块头, 已推送的区块被重组时推送重组事件并回退 next
+func (s *blockSubscription) observe(headers []RpcHeader) error {
+	trackerHeaders := make([]*tracker.Header, len(headers))
+	for i := range headers {
+		trackerHeaders[i] = toTrackerHeader(&headers[i])
+	}
+	reorg, err := s.tracker.Observe(trackerHeaders...)
+	if errors.Is(err, tracker.ErrForkTooDeep) {
+		return s.fail("fork point too deep", err)
+	}
+	if err != nil {
+		return s.retry("track block headers fail", err)
+	}
+	//分叉点之上的区块都还未推送时无需通知
+	if reorg == nil || reorg.ForkHeight+1 >= s.next {
+		return nil
+	}
+	if err := s.handler.onReorg(reorg); err != nil {
+		return err
+	}
+	s.next = reorg.ForkHeight + 1
+	return nil
+}
+
+// retry 节点暂时不可用时等待下一轮, 连接已关闭时结束订阅
+func (s *blockSubscription) retry(msg string, err error) error {
+	if errors.Is(err, rpc.ErrClientQuit) {
+		return s.fail("chain client closed, please resubscribe", err)
+	}
+	log.Warn(msg, "next", s.next, "err", err)
+	return errRetryLater
+}
+
+// fail 推送错误事件并结束订阅
+func (s *blockSubscription) fail(msg string, err error) error {
+	log.Error(msg, "next", s.next, "err", err)
+	if err := s.handler.onError(msg, s.next); err != nil {
+		return err
+	}
+	return errSubscriptionStopped
+}
+
+// blockEvents SubscribeBlocks 的处理器, 推送区块头 (及区块) 与重组事件
+type blockEvents struct {
+	client    EthClient
+	fullBlock bool
+	send      func(*account.BlockEvent) error
+}
+
+func (h *blockEvents) onBlock(header *RpcHeader) error {
+	event := &account.BlockEvent{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "new block",
+		Height: header.Number.Uint64(),
+		Hash:   header.Hash().String(),
+		Header: ToBlockHeader(header),
+	}
+	if h.fullBlock {
+		block, err := h.client.BlockByHash(header.Hash())
+		if err != nil {
+			return &nodeError{msg: "get block by hash fail", err: err}
+		}
+		event.Block = toBlockData(block)
+	}
+	return h.send(event)
+}
+
+func (h *blockEvents) onReorg(reorg *tracker.Reorg) error {
+	return h.send(&account.BlockEvent{
+		Code:           common.ReturnCode_SUCCESS,
+		Msg:            "chain reorg",
+		Height:         reorg.ForkHeight,
+		Hash:           reorg.ForkHash,
+		Reorged:        true,
+		ForkHeight:     reorg.ForkHeight,
+		ForkHash:       reorg.ForkHash,
+		OrphanedHashes: reorg.Orphaned,
+	})
+}
+
+func (h *blockEvents) onError(msg string, height uint64) error {
+	return h.send(&account.BlockEvent{
+		Code:   common.ReturnCode_ERROR,
+		Msg:    msg,
+		Height: height,
+	})
+}
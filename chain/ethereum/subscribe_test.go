@@ -0,0 +1,41 @@
+package ethereum
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+)
+
+// 推送的哈希与按哈希查询的区块都须使用节点返回的哈希
+func TestSubscribeBlocksNodeHash(t *testing.T) {
+	var blockHashes []ethcommon.Hash
+	client := newTestClient(t, pectraNode(102, &blockHashes, nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var events []*account.BlockEvent
+	req := &account.SubscribeBlocksRequest{FromHeight: 100, FullBlock: true}
+	err := SubscribeBlocks(ctx, client, req, 1, func(event *account.BlockEvent) error {
+		events = append(events, event)
+		if len(events) == 3 {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SubscribeBlocks: %v", err)
+	}
+	if len(events) != 3 || len(blockHashes) != 3 {
+		t.Fatalf("got %d events by %d hash lookups, want 3", len(events), len(blockHashes))
+	}
+	for i, event := range events {
+		want := pectraHash(100 + uint64(i))
+		if event.Hash != want.String() || blockHashes[i] != want || event.Block.Hash != want.String() {
+			t.Errorf("event %d: hash %s, looked up %s, block %s, want %s", i, event.Hash, blockHashes[i], event.Block.Hash, want)
+		}
+	}
+}
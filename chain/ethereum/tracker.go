@@ -0,0 +1,200 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain/tracker"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+// 单次 GetBlockByRange 最多返回的区块数
+const maxBlockRange = 100
+
+// headerSource 为链追踪器提供区块头查询, 以太坊与 EVM 兼容链共用
+type headerSource struct {
+	client EthClient
+}
+
+// NewTracker 创建基于 EthClient 的链追踪器
+func NewTracker(client EthClient) *tracker.Tracker {
+	return tracker.New(headerSource{client: client}, tracker.DefaultDepth)
+}
+
+func (s headerSource) HeaderByNumber(number uint64) (*tracker.Header, error) {
+	header, err := s.client.BlockHeaderByNumber(new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, err
+	}
+	return toTrackerHeader(header), nil
+}
+
+func (s headerSource) HeaderByHash(hash string) (*tracker.Header, error) {
+	header, err := s.client.BlockHeaderByHash(ethcommon.HexToHash(hash))
+	if err != nil {
+		return nil, err
+	}
+	return toTrackerHeader(header), nil
+}
+
+func toTrackerHeader(header *RpcHeader) *tracker.Header {
+	return &tracker.Header{
+		Number:     header.Number.Uint64(),
+		Hash:       header.Hash().String(),
+		ParentHash: header.ParentHash.String(),
+	}
+}
+
+// TrackerHeader 区块对应的追踪器区块头
+func (b *RpcBlock) TrackerHeader() *tracker.Header {
+	return &tracker.Header{
+		Number:     b.Number.ToInt().Uint64(),
+		Hash:       b.Hash.String(),
+		ParentHash: b.ParentHash.String(),
+	}
+}
+
+/**
+ * @description: 查询 [start, end] 范围内的区块
+ * 区块头需首尾相连, 并交给追踪器记录 (与记录冲突时以新链为准); 区块体按区块头哈希查询, 保证与区块头属于同一条链
+ * 范围超出最新区块时只返回已出块的部分
+ * @param client 节点客户端
+ * @param t 链追踪器
+ * @param start 起始高度
+ * @param end 结束高度 (含)
+ * @param chainId 链 id, 部分链需分组查询
+ */
+func BlocksByRange(client EthClient, t *tracker.Tracker, start, end uint64, chainId uint) ([]*RpcBlock, error) {
+	headers, err := client.BlockHeadersByRange(new(big.Int).SetUint64(start), new(big.Int).SetUint64(end), chainId)
+	if err != nil {
+		return nil, err
+	}
+	trackerHeaders := make([]*tracker.Header, len(headers))
+	for i := range headers {
+		trackerHeaders[i] = toTrackerHeader(&headers[i])
+	}
+	reorg, err := t.Observe(trackerHeaders...)
+	if err != nil {
+		return nil, err
+	}
+	if reorg != nil {
+		log.Warn("block range crosses reorg", "start", start, "end", end, "forkHeight", reorg.ForkHeight)
+	}
+	blocks := make([]*RpcBlock, 0, len(trackerHeaders))
+	for _, header := range trackerHeaders {
+		block, err := client.BlockByHash(ethcommon.HexToHash(header.Hash))
+		if err != nil {
+			return nil, fmt.Errorf("get block %s: %w", header.Hash, err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+/**
+ * @description: GetBlockByRange 的公共实现, start/end 为十进制区块高度
+ * 节点返回的区块头不连续时 (通常正在切换分叉) 返回错误, 调用方稍后重试
+ */
+func BlockByRangeResponse(client EthClient, t *tracker.Tracker, req *account.BlockByRangeRequest, chainId uint) *account.BlockByRangeResponse {
+	start, okStart := new(big.Int).SetString(req.Start, 10)
+	end, okEnd := new(big.Int).SetString(req.End, 10)
+	if !okStart || !okEnd || start.Sign() < 0 || start.Cmp(end) > 0 {
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid block range",
+		}
+	}
+	if new(big.Int).Sub(end, start).Uint64() >= maxBlockRange {
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  fmt.Sprintf("block range exceeds %d blocks", maxBlockRange),
+		}
+	}
+	blocks, err := BlocksByRange(client, t, start.Uint64(), end.Uint64(), chainId)
+	if errors.Is(err, tracker.ErrBrokenChain) {
+		log.Warn("block range not linked", "err", err)
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "block headers not linked, node may be switching fork, retry later",
+		}
+	}
+	if err != nil {
+		log.Error("get block by range fail", "err", err)
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by range fail",
+		}
+	}
+	blockList := make([]*account.BlockData, 0, len(blocks))
+	for _, block := range blocks {
+		blockList = append(blockList, toBlockData(block))
+	}
+	return &account.BlockByRangeResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "get block by range success",
+		Blocks: blockList,
+	}
+}
+
+func toBlockData(block *RpcBlock) *account.BlockData {
+	height := block.Number.ToInt().String()
+	var txList []*account.TxMessage
+	for i, tx := range block.Transactions {
+		txList = append(txList, &account.TxMessage{
+			Hash:   tx.Hash,
+			Index:  uint32(i),
+			Tos:    []*account.Address{{Address: tx.To}},
+			Height: height,
+		})
+	}
+	return &account.BlockData{
+		Hash:         block.Hash.String(),
+		BaseFee:      block.BaseFee,
+		Transactions: txList,
+	}
+}
+
+/**
+ * @description: DetectReorg 的公共实现
+ * 判断调用方记录的区块是否仍在规范链上, 不在时返回分叉点与被重组掉的区块哈希 (按高度升序), 调用方据此回滚
+ */
+func ReorgResponse(t *tracker.Tracker, req *account.ReorgRequest) *account.ReorgResponse {
+	if req.Hash == "" {
+		return &account.ReorgResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "block hash is required",
+		}
+	}
+	reorg, canonical, err := t.FindFork(req.Height, ethcommon.HexToHash(req.Hash).String())
+	if errors.Is(err, tracker.ErrForkTooDeep) {
+		log.Error("fork point too deep", "height", req.Height, "hash", req.Hash)
+		return &account.ReorgResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  fmt.Sprintf("fork point deeper than %d blocks", tracker.DefaultDepth),
+		}
+	}
+	if err != nil {
+		log.Error("detect reorg fail", "err", err)
+		return &account.ReorgResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "detect reorg fail",
+		}
+	}
+	resp := &account.ReorgResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "detect reorg success",
+		CanonicalHash: canonical.Hash,
+	}
+	if reorg != nil {
+		resp.Reorged = true
+		resp.ForkHeight = reorg.ForkHeight
+		resp.ForkHash = reorg.ForkHash
+		resp.OrphanedHashes = reorg.Orphaned
+	}
+	return resp
+}
@@ -0,0 +1,105 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// pectraHash 模拟链上哈希, 与 go-ethereum 本地计算的哈希不同
+func pectraHash(height uint64) ethcommon.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("pectra-%d", height)))
+}
+
+// pectraHeader 带 requestsHash 的区块头, go-ethereum v1.14 不认识该字段, 本地计算的哈希与节点返回的 hash 不一致
+func pectraHeader(t *testing.T, height uint64) map[string]interface{} {
+	t.Helper()
+	header := &types.Header{
+		Number:     new(big.Int).SetUint64(height),
+		ParentHash: pectraHash(height - 1),
+		Difficulty: new(big.Int),
+		BaseFee:    big.NewInt(7),
+	}
+	raw, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		t.Fatal(err)
+	}
+	fields["requestsHash"] = "0xe3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	fields["hash"] = pectraHash(height).String()
+	return fields
+}
+
+// pectraNode 出块到 head 高度的节点, 区块头带 requestsHash; 按哈希查询的区块记录在 lookups 中, 其他方法交给 next
+func pectraNode(head uint64, lookups *[]ethcommon.Hash, next rpcHandler) rpcHandler {
+	return func(t *testing.T, method string, params []json.RawMessage) interface{} {
+		switch method {
+		case "eth_getBlockByNumber":
+			var tag string
+			decodeParam(t, params, 0, &tag)
+			if tag == "latest" {
+				return pectraHeader(t, head)
+			}
+			height, err := hexutil.DecodeUint64(tag)
+			if err != nil {
+				t.Errorf("decode block height: %v", err)
+			}
+			if height > head {
+				return nil
+			}
+			return pectraHeader(t, height)
+		case "eth_getBlockByHash":
+			var hash ethcommon.Hash
+			decodeParam(t, params, 0, &hash)
+			*lookups = append(*lookups, hash)
+			for height := uint64(0); height <= head; height++ {
+				if hash == pectraHash(height) {
+					return pectraHeader(t, height)
+				}
+			}
+			return nil
+		}
+		if next == nil {
+			t.Errorf("unexpected method %s", method)
+			return nil
+		}
+		return next(t, method, params)
+	}
+}
+
+func TestBlocksByRangeNodeHash(t *testing.T) {
+	var blockHashes []ethcommon.Hash
+	client := newTestClient(t, pectraNode(102, &blockHashes, nil))
+
+	header, err := client.BlockHeaderByHash(pectraHash(101))
+	if err != nil {
+		t.Fatalf("BlockHeaderByHash: %v", err)
+	}
+	if header.Hash() != pectraHash(101) || header.Header.Hash() == pectraHash(101) {
+		t.Errorf("hash = %s, local hash = %s, want node hash %s", header.Hash(), header.Header.Hash(), pectraHash(101))
+	}
+
+	blockHashes = nil
+	blocks, err := BlocksByRange(client, NewTracker(client), 100, 102, 1)
+	if err != nil {
+		t.Fatalf("BlocksByRange: %v", err)
+	}
+	if len(blocks) != 3 || len(blockHashes) != 3 {
+		t.Fatalf("got %d blocks by %d hash lookups, want 3", len(blocks), len(blockHashes))
+	}
+	for i, block := range blocks {
+		want := pectraHash(100 + uint64(i))
+		if blockHashes[i] != want || block.Hash != want {
+			t.Errorf("block %d: looked up %s, got %s, want %s", i, blockHashes[i], block.Hash, want)
+		}
+	}
+}
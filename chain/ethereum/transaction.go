@@ -1 +1,225 @@
 package ethereum
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+// TxStructure DecodeTransaction 解析出的交易, 以 base64 编码的 JSON 返回
+// 识别出代币转账时 contract_address 为代币合约, to_address/value 为代币的接收方与数量,
+// ERC-1155 批量转账的 id 与数量在 token_ids/amounts 中
+type TxStructure struct {
+	ChainId              string `json:"chain_id,omitempty"`
+	Nonce                uint64 `json:"nonce"`
+	FromAddress          string `json:"from_address,omitempty"`
+	ToAddress            string `json:"to_address"`
+	Value                string `json:"value"`
+	GasLimit             uint64 `json:"gas_limit"`
+	GasPrice             string `json:"gas_price,omitempty"`
+	MaxFeePerGas         string `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas,omitempty"`
+	Signed               bool   `json:"signed"`
+
+	ContractAddress string `json:"contract_address,omitempty"`
+	// TokenStandard erc20, erc721 或 erc1155
+	TokenStandard string `json:"token_standard,omitempty"`
+	Method        string `json:"method,omitempty"`
+	// TokenFrom transferFrom/safeTransferFrom 中转出代币的地址, 可能与交易发送方不同
+	TokenFrom string   `json:"token_from,omitempty"`
+	TokenId   string   `json:"token_id,omitempty"`
+	TokenIds  []string `json:"token_ids,omitempty"`
+	Amounts   []string `json:"amounts,omitempty"`
+	// Data 未识别的合约调用数据, 或 safeTransferFrom 附带的 data
+	Data string `json:"data,omitempty"`
+}
+
+/**
+ * @description: 解析 0x 开头的十六进制交易编码, 已签名的交易同时恢复发送方
+ * 识别 ERC-20 transfer/transferFrom, ERC-721 transferFrom/safeTransferFrom 与 ERC-1155 safeTransferFrom/safeBatchTransferFrom;
+ * ERC-20 与 ERC-721 的 transferFrom 选择器相同, 通过 ERC-165 区分
+ * @param client 节点客户端
+ * @param rawTx 交易编码
+ * @param chainId 链 id, 交易的链 id 不同时报错
+ */
+func DecodeTransactionResponse(client EthClient, rawTx string, chainId *big.Int) *account.DecodeTransactionResponse {
+	txData, err := hexutil.Decode(rawTx)
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(txData); err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "unmarshal raw tx fail",
+		}
+	}
+	v, r, s := tx.RawSignatureValues()
+	signed := v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0
+
+	txStructure := &TxStructure{
+		Nonce:    tx.Nonce(),
+		Value:    tx.Value().String(),
+		GasLimit: tx.Gas(),
+		Signed:   signed,
+	}
+	//未签名的 legacy 交易无法从 v 推导链 id
+	if tx.Type() != types.LegacyTxType || (signed && tx.Protected()) {
+		if tx.ChainId().Cmp(chainId) != 0 {
+			return &account.DecodeTransactionResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  fmt.Sprintf("chain id mismatch, tx %s expected %s", tx.ChainId(), chainId),
+			}
+		}
+		txStructure.ChainId = tx.ChainId().String()
+	}
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		txStructure.GasPrice = tx.GasPrice().String()
+	} else {
+		txStructure.MaxFeePerGas = tx.GasFeeCap().String()
+		txStructure.MaxPriorityFeePerGas = tx.GasTipCap().String()
+	}
+	if signed {
+		var signer types.Signer = types.HomesteadSigner{}
+		if tx.Protected() {
+			signer = types.LatestSignerForChainID(tx.ChainId())
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			log.Error("recover tx sender fail", "err", err)
+			return &account.DecodeTransactionResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "recover tx sender fail",
+			}
+		}
+		txStructure.FromAddress = from.String()
+	}
+	if tx.To() != nil {
+		txStructure.ToAddress = tx.To().String()
+		if err := decodeTokenCall(client, *tx.To(), tx.Data(), txStructure); err != nil {
+			log.Error("decode token call fail", "err", err)
+			return &account.DecodeTransactionResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "decode token call fail",
+			}
+		}
+	} else if len(tx.Data()) > 0 {
+		txStructure.Data = hexutil.Encode(tx.Data())
+	}
+
+	txJson, _ := json.Marshal(txStructure)
+	return &account.DecodeTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "decode transaction success",
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
+	}
+}
+
+// decodeTokenCall 识别代币转账调用并填写代币字段, 未识别的调用只填写 data; 只有区分 transferFrom 时访问节点
+func decodeTokenCall(client EthClient, contract ethcommon.Address, data []byte, txStructure *TxStructure) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if len(data) < 4 {
+		txStructure.Data = hexutil.Encode(data)
+		return nil
+	}
+	var (
+		m        *abi.Method
+		standard account.TokenStandard
+	)
+	for _, candidate := range []struct {
+		abi      abi.ABI
+		standard account.TokenStandard
+	}{
+		{erc20ABI, account.TokenStandard_ERC20},
+		{erc721ABI, account.TokenStandard_ERC721},
+		{erc1155ABI, account.TokenStandard_ERC1155},
+	} {
+		if found, err := candidate.abi.MethodById(data[:4]); err == nil && !found.IsConstant() {
+			m, standard = found, candidate.standard
+			break
+		}
+	}
+	if m == nil {
+		txStructure.Data = hexutil.Encode(data)
+		return nil
+	}
+	args, err := m.Inputs.Unpack(data[4:])
+	if err != nil {
+		//选择器相同但参数不符合, 按未识别的调用返回
+		txStructure.Data = hexutil.Encode(data)
+		return nil
+	}
+	if m.Name == "transferFrom" {
+		detected, err := DetectNftStandard(client, contract)
+		switch {
+		case err == nil && detected == account.TokenStandard_ERC721:
+			standard = account.TokenStandard_ERC721
+		case err == nil || errors.Is(err, errNotNft):
+			standard = account.TokenStandard_ERC20
+		default:
+			return err
+		}
+	}
+
+	txStructure.ContractAddress = contract.String()
+	txStructure.TokenStandard = strings.ToLower(standard.String())
+	txStructure.Method = m.RawName
+	switch {
+	case standard == account.TokenStandard_ERC20 && m.RawName == "transfer":
+		txStructure.ToAddress = args[0].(ethcommon.Address).String()
+		txStructure.Value = args[1].(*big.Int).String()
+	case standard == account.TokenStandard_ERC20:
+		txStructure.TokenFrom = args[0].(ethcommon.Address).String()
+		txStructure.ToAddress = args[1].(ethcommon.Address).String()
+		txStructure.Value = args[2].(*big.Int).String()
+	case standard == account.TokenStandard_ERC721:
+		txStructure.TokenFrom = args[0].(ethcommon.Address).String()
+		txStructure.ToAddress = args[1].(ethcommon.Address).String()
+		txStructure.TokenId = args[2].(*big.Int).String()
+		txStructure.Value = "1"
+		if len(args) == 4 && len(args[3].([]byte)) > 0 {
+			txStructure.Data = hexutil.Encode(args[3].([]byte))
+		}
+	case m.RawName == "safeTransferFrom":
+		txStructure.TokenFrom = args[0].(ethcommon.Address).String()
+		txStructure.ToAddress = args[1].(ethcommon.Address).String()
+		txStructure.TokenId = args[2].(*big.Int).String()
+		txStructure.Value = args[3].(*big.Int).String()
+		if extra := args[4].([]byte); len(extra) > 0 {
+			txStructure.Data = hexutil.Encode(extra)
+		}
+	default:
+		ids, amounts := args[2].([]*big.Int), args[3].([]*big.Int)
+		if len(ids) != len(amounts) {
+			return fmt.Errorf("ids and values of safeBatchTransferFrom mismatch")
+		}
+		txStructure.TokenFrom = args[0].(ethcommon.Address).String()
+		txStructure.ToAddress = args[1].(ethcommon.Address).String()
+		txStructure.Value = ""
+		for i := range ids {
+			txStructure.TokenIds = append(txStructure.TokenIds, ids[i].String())
+			txStructure.Amounts = append(txStructure.Amounts, amounts[i].String())
+		}
+		if extra := args[4].([]byte); len(extra) > 0 {
+			txStructure.Data = hexutil.Encode(extra)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+// 单次查询转账日志的最大区块数, 节点拒绝时再自动拆分
+const maxTransferLogRange = 10000
+
+var (
+	// TransferSingleEventTopic ERC-1155 TransferSingle(address,address,address,uint256,uint256) 事件签名
+	TransferSingleEventTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	// TransferBatchEventTopic ERC-1155 TransferBatch(address,address,address,uint256[],uint256[]) 事件签名
+	TransferBatchEventTopic = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+)
+
+// TransferBatch 事件 data 部分的 ids 与 values
+var transferBatchArguments = func() abi.Arguments {
+	uint256Array, _ := abi.NewType("uint256[]", "", nil)
+	return abi.Arguments{{Type: uint256Array}, {Type: uint256Array}}
+}()
+
+// 节点因结果过多或区块范围过大拒绝 eth_getLogs 时的错误信息 (小写), 不同节点服务商的措辞不同
+var tooManyLogsErrors = []string{
+	"query returned more than",
+	"response size",
+	"too many",
+	"too large",
+	"too wide",
+	"block range",
+	"exceed maximum",
+}
+
+/**
+ * @description: 查询区块范围内的 ERC-20/721/1155 转账事件
+ * ERC-20 与 ERC-721 的 Transfer 事件签名相同, 按 tokenId 是否 indexed (topic 数量) 区分
+ * ERC-1155 的 TransferBatch 按 id 展开为多条记录, 共用同一 log_index
+ * 节点因结果过多拒绝查询时把区块范围对半拆分后重试, 单个区块仍被拒绝时返回错误
+ * @param client 节点客户端
+ * @param req 查询请求, address 匹配转出或转入地址, contract_address 匹配代币合约, 为空时不过滤
+ * @param chainId 链 id
+ */
+func TransferLogsResponse(client EthClient, req *account.TransferLogsRequest, chainId uint) *account.TransferLogsResponse {
+	if req.StartHeight > req.EndHeight {
+		return &account.TransferLogsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid block range",
+		}
+	}
+	if req.EndHeight-req.StartHeight >= maxTransferLogRange {
+		return &account.TransferLogsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  fmt.Sprintf("block range exceeds %d blocks", maxTransferLogRange),
+		}
+	}
+	var contracts []ethcommon.Address
+	if req.ContractAddress != "" {
+		if !ethcommon.IsHexAddress(req.ContractAddress) {
+			return &account.TransferLogsResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "invalid contract address",
+			}
+		}
+		contracts = []ethcommon.Address{ethcommon.HexToAddress(req.ContractAddress)}
+	}
+	var address *ethcommon.Address
+	if req.Address != "" {
+		if !ethcommon.IsHexAddress(req.Address) {
+			return &account.TransferLogsResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "invalid address",
+			}
+		}
+		addr := ethcommon.HexToAddress(req.Address)
+		address = &addr
+	}
+	transfers, err := scanTransferLogs(client, req.StartHeight, req.EndHeight, address, contracts, chainId)
+	if err != nil {
+		log.Error("filter transfer logs fail", "start", req.StartHeight, "end", req.EndHeight, "err", err)
+		return &account.TransferLogsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "filter transfer logs fail",
+		}
+	}
+	return &account.TransferLogsResponse{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       "get transfer logs success",
+		Transfers: transfers,
+	}
+}
+
+// scanTransferLogs 查询并解析转账事件, 按高度与日志序号升序; address 为 nil 时不按地址过滤
+func scanTransferLogs(client EthClient, start, end uint64, address *ethcommon.Address, contracts []ethcommon.Address, chainId uint) ([]*account.TransferLog, error) {
+	var queries [][][]ethcommon.Hash
+	if address == nil {
+		queries = [][][]ethcommon.Hash{{{TransferEventTopic, TransferSingleEventTopic, TransferBatchEventTopic}}}
+	} else {
+		topic := ethcommon.BytesToHash(address.Bytes())
+		erc1155 := []ethcommon.Hash{TransferSingleEventTopic, TransferBatchEventTopic}
+		queries = [][][]ethcommon.Hash{
+			{{TransferEventTopic}, {topic}},
+			{{TransferEventTopic}, nil, {topic}},
+			{erc1155, nil, {topic}},
+			{erc1155, nil, nil, {topic}},
+		}
+	}
+
+	seen := make(map[string]bool)
+	var logs []types.Log
+	for _, topics := range queries {
+		result, err := filterLogsSplit(client, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: contracts,
+			Topics:    topics,
+		}, chainId)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range result {
+			key := fmt.Sprintf("%s-%d", l.TxHash, l.Index)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			logs = append(logs, l)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].Index < logs[j].Index
+	})
+
+	transfers := make([]*account.TransferLog, 0, len(logs))
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		decoded, err := DecodeTransferLog(l)
+		if err != nil {
+			log.Warn("skip undecodable transfer log", "tx", l.TxHash, "index", l.Index, "err", err)
+			continue
+		}
+		transfers = append(transfers, decoded...)
+	}
+	return transfers, nil
+}
+
+// filterLogsSplit 查询日志, 节点因结果过多拒绝时对半拆分区块范围
+func filterLogsSplit(client EthClient, query ethereum.FilterQuery, chainId uint) ([]types.Log, error) {
+	result, err := client.FilterLogs(query, chainId)
+	if err == nil {
+		return result.Logs, nil
+	}
+	from, to := query.FromBlock.Uint64(), query.ToBlock.Uint64()
+	if !isTooManyLogs(err) || from >= to {
+		return nil, err
+	}
+	mid := from + (to-from)/2
+	log.Debug("too many logs, split block range", "from", from, "to", to, "err", err)
+
+	lower, upper := query, query
+	lower.ToBlock = new(big.Int).SetUint64(mid)
+	upper.FromBlock = new(big.Int).SetUint64(mid + 1)
+	logs, err := filterLogsSplit(client, lower, chainId)
+	if err != nil {
+		return nil, err
+	}
+	upperLogs, err := filterLogsSplit(client, upper, chainId)
+	if err != nil {
+		return nil, err
+	}
+	return append(logs, upperLogs...), nil
+}
+
+func isTooManyLogs(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range tooManyLogsErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @description: 解析 ERC-20/721/1155 转账事件, TransferBatch 按 id 展开为多条
+ * ERC-721 的 value 固定为 1, ERC-20 的 token_id 为空
+ * @param l 日志
+ */
+func DecodeTransferLog(l types.Log) ([]*account.TransferLog, error) {
+	if len(l.Topics) == 0 {
+		return nil, fmt.Errorf("log has no topics")
+	}
+	transfer := &account.TransferLog{
+		TxHash:          l.TxHash.String(),
+		LogIndex:        uint32(l.Index),
+		Height:          l.BlockNumber,
+		BlockHash:       l.BlockHash.String(),
+		ContractAddress: l.Address.String(),
+	}
+	switch l.Topics[0] {
+	case TransferEventTopic:
+		switch {
+		case len(l.Topics) == 3 && len(l.Data) == 32:
+			transfer.Standard = account.TokenStandard_ERC20
+			transfer.Value = new(big.Int).SetBytes(l.Data).String()
+		case len(l.Topics) == 4 && len(l.Data) == 0:
+			transfer.Standard = account.TokenStandard_ERC721
+			transfer.TokenId = l.Topics[3].Big().String()
+			transfer.Value = "1"
+		default:
+			return nil, fmt.Errorf("unexpected Transfer log layout, topics %d data %d", len(l.Topics), len(l.Data))
+		}
+		transfer.From = ethcommon.BytesToAddress(l.Topics[1].Bytes()).String()
+		transfer.To = ethcommon.BytesToAddress(l.Topics[2].Bytes()).String()
+		return []*account.TransferLog{transfer}, nil
+	case TransferSingleEventTopic, TransferBatchEventTopic:
+		if len(l.Topics) != 4 {
+			return nil, fmt.Errorf("unexpected ERC-1155 log layout, topics %d", len(l.Topics))
+		}
+		transfer.Standard = account.TokenStandard_ERC1155
+		transfer.Operator = ethcommon.BytesToAddress(l.Topics[1].Bytes()).String()
+		transfer.From = ethcommon.BytesToAddress(l.Topics[2].Bytes()).String()
+		transfer.To = ethcommon.BytesToAddress(l.Topics[3].Bytes()).String()
+	default:
+		return nil, fmt.Errorf("not a transfer event %s", l.Topics[0])
+	}
+
+	if l.Topics[0] == TransferSingleEventTopic {
+		if len(l.Data) != 64 {
+			return nil, fmt.Errorf("unexpected TransferSingle data length %d", len(l.Data))
+		}
+		transfer.TokenId = new(big.Int).SetBytes(l.Data[:32]).String()
+		transfer.Value = new(big.Int).SetBytes(l.Data[32:]).String()
+		return []*account.TransferLog{transfer}, nil
+	}
+	values, err := transferBatchArguments.Unpack(l.Data)
+	if err != nil {
+		return nil, fmt.Errorf("unpack TransferBatch data: %w", err)
+	}
+	ids, okIds := values[0].([]*big.Int)
+	amounts, okAmounts := values[1].([]*big.Int)
+	if !okIds || !okAmounts || len(ids) != len(amounts) {
+		return nil, fmt.Errorf("ids and values of TransferBatch mismatch")
+	}
+	transfers := make([]*account.TransferLog, 0, len(ids))
+	for i := range ids {
+		transfers = append(transfers, &account.TransferLog{
+			TxHash:          transfer.TxHash,
+			LogIndex:        transfer.LogIndex,
+			Height:          transfer.Height,
+			BlockHash:       transfer.BlockHash,
+			ContractAddress: transfer.ContractAddress,
+			Standard:        transfer.Standard,
+			Operator:        transfer.Operator,
+			From:            transfer.From,
+			To:              transfer.To,
+			TokenId:         ids[i].String(),
+			Value:           amounts[i].String(),
+		})
+	}
+	return transfers, nil
+}
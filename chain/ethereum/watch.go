@@ -0,0 +1,294 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/CavnHan/wallet-chain-account/chain/tracker"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+// 单个订阅最多监听的地址数, 地址作为 eth_getLogs 的 topic 过滤条件
+const maxWatchAddresses = 1000
+
+// TransferEventTopic ERC-20 Transfer(address,address,uint256) 事件签名
+var TransferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+/**
+ * @description: 推送与监听地址相关的转入转出
+ * 原生币转账从区块交易中匹配 (只含外部交易, 执行失败的交易不推送), ERC-20 转账按 Transfer 事件的 from/to topic 过滤日志
+ * 转账首次出现时推送一次, 之后每个新区块推送一次确认数更新, 直到达到确认条件 (status 为 Success);
+ * 未确认的转账所在区块被重组时推送 removed 事件
+ * checkpoints 为 true 时每处理完一个区块推送一次 tx_hash 为空的检查点 (height/block_hash 为该区块), 用于断线重连
+ * 起始高度与断线重连同 SubscribeBlocks
+ * @param ctx 订阅的上下文
+ * @param client 节点客户端
+ * @param confirmer 确认条件
+ * @param req 订阅请求, contract_addresses 为空时匹配所有 ERC-20 合约
+ * @param chainId 链 id
+ * @param send 推送事件
+ */
+func WatchAddresses(ctx context.Context, client EthClient, confirmer *Confirmer, req *account.WatchAddressesRequest, chainId uint, send func(*account.TransferEvent) error) error {
+	watcher, err := newAddressWatcher(client, confirmer, req, chainId, send)
+	if err != nil {
+		return send(&account.TransferEvent{
+			Code: common.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		})
+	}
+	return subscribe(ctx, client, req.FromHeight, req.LastHash, chainId, watcher)
+}
+
+// addressWatcher WatchAddresses 的处理器
+type addressWatcher struct {
+	client      EthClient
+	confirmer   *Confirmer
+	chainId     uint
+	checkpoints bool
+	send        func(*account.TransferEvent) error
+
+	addresses map[ethcommon.Address]bool
+	//监听地址对应的 topic, 用于过滤 Transfer 事件的 from/to
+	topics    []ethcommon.Hash
+	contracts []ethcommon.Address
+	//已推送但未确认的转账, 按高度升序
+	pending []*account.TransferEvent
+}
+
+func newAddressWatcher(client EthClient, confirmer *Confirmer, req *account.WatchAddressesRequest, chainId uint, send func(*account.TransferEvent) error) (*addressWatcher, error) {
+	if len(req.Addresses) == 0 {
+		return nil, fmt.Errorf("addresses is required")
+	}
+	if len(req.Addresses) > maxWatchAddresses {
+		return nil, fmt.Errorf("too many addresses, max %d", maxWatchAddresses)
+	}
+	w := &addressWatcher{
+		client:      client,
+		confirmer:   confirmer,
+		chainId:     chainId,
+		checkpoints: req.Checkpoints,
+		send:        send,
+		addresses:   make(map[ethcommon.Address]bool, len(req.Addresses)),
+	}
+	for _, address := range req.Addresses {
+		if !ethcommon.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid address %s", address)
+		}
+		addr := ethcommon.HexToAddress(address)
+		if w.addresses[addr] {
+			continue
+		}
+		w.addresses[addr] = true
+		w.topics = append(w.topics, ethcommon.BytesToHash(addr.Bytes()))
+	}
+	for _, contract := range req.ContractAddresses {
+		if !ethcommon.IsHexAddress(contract) {
+			return nil, fmt.Errorf("invalid contract address %s", contract)
+		}
+		w.contracts = append(w.contracts, ethcommon.HexToAddress(contract))
+	}
+	return w, nil
+}
+
+/**
+ * @description: 匹配区块中的转账并推送, 同时更新未确认转账的确认数
+ * 先完成所有节点查询再推送, 查询失败重试时不会重复推送
+ */
+func (w *addressWatcher) onBlock(header *RpcHeader) error {
+	transfers, err := w.nativeTransfers(header)
+	if err != nil {
+		return err
+	}
+	tokenTransfers, err := w.tokenTransfers(header)
+	if err != nil {
+		return err
+	}
+	transfers = append(transfers, tokenTransfers...)
+	view, err := w.confirmer.View()
+	if err != nil {
+		return &nodeError{msg: "get confirmation view fail", err: err}
+	}
+
+	pending := w.pending[:0]
+	for _, transfer := range w.pending {
+		confirmations := view.Confirmations(transfer.Height)
+		if confirmations == transfer.Confirmations {
+			pending = append(pending, transfer)
+			continue
+		}
+		transfer.Confirmations = confirmations
+		transfer.Status = view.Status(transfer.Height, true)
+		transfer.Msg = "transfer confirmations updated"
+		if err := w.send(transfer); err != nil {
+			return err
+		}
+		if transfer.Status == account.TxStatus_Pending {
+			pending = append(pending, transfer)
+		}
+	}
+	w.pending = pending
+
+	for _, transfer := range transfers {
+		transfer.Confirmations = view.Confirmations(transfer.Height)
+		transfer.Status = view.Status(transfer.Height, true)
+		if err := w.send(transfer); err != nil {
+			return err
+		}
+		if transfer.Status == account.TxStatus_Pending {
+			w.pending = append(w.pending, transfer)
+		}
+	}
+	if !w.checkpoints {
+		return nil
+	}
+	return w.send(&account.TransferEvent{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       "checkpoint",
+		Height:    header.Number.Uint64(),
+		BlockHash: header.Hash().String(),
+	})
+}
+
+// nativeTransfers 区块中与监听地址相关且执行成功的原生币转账
+func (w *addressWatcher) nativeTransfers(header *RpcHeader) ([]*account.TransferEvent, error) {
+	block, err := w.client.BlockByHash(header.Hash())
+	if err != nil {
+		return nil, &nodeError{msg: "get block by hash fail", err: err}
+	}
+	var transfers []*account.TransferEvent
+	for _, tx := range block.Transactions {
+		if tx.Value == nil || tx.Value.ToInt().Sign() == 0 || tx.To == "" {
+			continue
+		}
+		from, to := ethcommon.HexToAddress(tx.From), ethcommon.HexToAddress(tx.To)
+		direction, ok := w.direction(from, to)
+		if !ok {
+			continue
+		}
+		receipt, err := w.client.TxReceiptByHash(ethcommon.HexToHash(tx.Hash))
+		if err != nil {
+			return nil, &nodeError{msg: "get tx receipt fail", err: err}
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		transfers = append(transfers, &account.TransferEvent{
+			Code:      common.ReturnCode_SUCCESS,
+			Msg:       "native transfer",
+			TxHash:    tx.Hash,
+			Height:    header.Number.Uint64(),
+			BlockHash: header.Hash().String(),
+			From:      from.String(),
+			To:        to.String(),
+			Value:     tx.Value.ToInt().String(),
+			Direction: direction,
+		})
+	}
+	return transfers, nil
+}
+
+// tokenTransfers 区块中 from 或 to 为监听地址的 ERC-20 Transfer 事件, 按日志序号升序
+func (w *addressWatcher) tokenTransfers(header *RpcHeader) ([]*account.TransferEvent, error) {
+	blockHash := header.Hash()
+	queries := [][][]ethcommon.Hash{
+		{{TransferEventTopic}, w.topics},
+		{{TransferEventTopic}, nil, w.topics},
+	}
+	seen := make(map[string]bool)
+	var logs []types.Log
+	for _, topics := range queries {
+		result, err := w.client.FilterLogs(ethereum.FilterQuery{
+			BlockHash: &blockHash,
+			Addresses: w.contracts,
+			Topics:    topics,
+		}, w.chainId)
+		if err != nil {
+			return nil, &nodeError{msg: "filter transfer logs fail", err: err}
+		}
+		for _, l := range result.Logs {
+			key := fmt.Sprintf("%s-%d", l.TxHash, l.Index)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			logs = append(logs, l)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Index < logs[j].Index })
+
+	var transfers []*account.TransferEvent
+	for _, l := range logs {
+		//ERC-721 的 Transfer 事件签名相同, 但 tokenId 也是 indexed, 没有 data
+		if l.Removed || len(l.Topics) != 3 || len(l.Data) != 32 {
+			continue
+		}
+		from := ethcommon.BytesToAddress(l.Topics[1].Bytes())
+		to := ethcommon.BytesToAddress(l.Topics[2].Bytes())
+		direction, ok := w.direction(from, to)
+		if !ok {
+			continue
+		}
+		transfers = append(transfers, &account.TransferEvent{
+			Code:            common.ReturnCode_SUCCESS,
+			Msg:             "token transfer",
+			TxHash:          l.TxHash.String(),
+			LogIndex:        uint32(l.Index),
+			Height:          header.Number.Uint64(),
+			BlockHash:       blockHash.String(),
+			From:            from.String(),
+			To:              to.String(),
+			Value:           new(big.Int).SetBytes(l.Data).String(),
+			ContractAddress: l.Address.String(),
+			Direction:       direction,
+		})
+	}
+	return transfers, nil
+}
+
+func (w *addressWatcher) direction(from, to ethcommon.Address) (account.TransferDirection, bool) {
+	switch {
+	case w.addresses[from] && w.addresses[to]:
+		return account.TransferDirection_DirectionSelf, true
+	case w.addresses[to]:
+		return account.TransferDirection_DirectionIn, true
+	case w.addresses[from]:
+		return account.TransferDirection_DirectionOut, true
+	}
+	return 0, false
+}
+
+// onReorg 未确认的转账所在区块被重组时推送 removed 事件, 已确认的转账不再跟踪
+func (w *addressWatcher) onReorg(reorg *tracker.Reorg) error {
+	pending := w.pending[:0]
+	for _, transfer := range w.pending {
+		if transfer.Height <= reorg.ForkHeight {
+			pending = append(pending, transfer)
+			continue
+		}
+		transfer.Removed = true
+		transfer.Confirmations = 0
+		transfer.Status = account.TxStatus_NotFound
+		transfer.Msg = "transfer removed by chain reorg"
+		if err := w.send(transfer); err != nil {
+			return err
+		}
+	}
+	w.pending = pending
+	return nil
+}
+
+func (w *addressWatcher) onError(msg string, height uint64) error {
+	return w.send(&account.TransferEvent{
+		Code:   common.ReturnCode_ERROR,
+		Msg:    msg,
+		Height: height,
+	})
+}
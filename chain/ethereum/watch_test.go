@@ -0,0 +1,99 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+)
+
+type filterArg struct {
+	BlockHash *ethcommon.Hash `json:"blockHash"`
+}
+
+// 转账与检查点的 block_hash 以及查询区块和日志使用的哈希都须为节点返回的哈希
+func TestWatchAddressesNodeHash(t *testing.T) {
+	const height = 101
+	watched := ethcommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+	sender := ethcommon.HexToAddress("0x00000000000000000000000000000000000000cc")
+	token := ethcommon.HexToAddress("0x00000000000000000000000000000000000000bb")
+	txHash := ethcommon.HexToHash("0x01")
+	blockHash := pectraHash(height)
+
+	var blockHashes, logHashes []ethcommon.Hash
+	node := pectraNode(height, &blockHashes, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+		switch method {
+		case "eth_getTransactionReceipt":
+			return &types.Receipt{
+				Status:      types.ReceiptStatusSuccessful,
+				TxHash:      txHash,
+				BlockHash:   blockHash,
+				BlockNumber: big.NewInt(height),
+				Logs:        []*types.Log{},
+			}
+		case "eth_getLogs":
+			var arg filterArg
+			decodeParam(t, params, 0, &arg)
+			if arg.BlockHash != nil {
+				logHashes = append(logHashes, *arg.BlockHash)
+			}
+			return []*types.Log{{
+				Address:     token,
+				Topics:      []ethcommon.Hash{TransferEventTopic, ethcommon.BytesToHash(sender.Bytes()), ethcommon.BytesToHash(watched.Bytes())},
+				Data:        ethcommon.LeftPadBytes(big.NewInt(5).Bytes(), 32),
+				BlockNumber: height,
+				TxHash:      txHash,
+				BlockHash:   blockHash,
+			}}
+		}
+		t.Errorf("unexpected method %s", method)
+		return nil
+	})
+	client := newTestClient(t, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+		result := node(t, method, params)
+		//按哈希查询的区块带一笔转入监听地址的交易
+		if block, ok := result.(map[string]interface{}); ok && method == "eth_getBlockByHash" {
+			block["transactions"] = []TransactionList{{From: sender.String(), To: watched.String(), Hash: txHash.String(), Value: (*hexutil.Big)(big.NewInt(1))}}
+		}
+		return result
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var events []*account.TransferEvent
+	req := &account.WatchAddressesRequest{Addresses: []string{watched.String()}, FromHeight: height, Checkpoints: true}
+	err := WatchAddresses(ctx, client, NewConfirmer(client, &config.ChainConfig{}), req, 1, func(event *account.TransferEvent) error {
+		events = append(events, event)
+		if event.Msg == "checkpoint" {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WatchAddresses: %v", err)
+	}
+	wantMsgs := []string{"native transfer", "token transfer", "checkpoint"}
+	if len(events) != len(wantMsgs) {
+		t.Fatalf("got %d events, want %d", len(events), len(wantMsgs))
+	}
+	for i, event := range events {
+		if event.Msg != wantMsgs[i] || event.BlockHash != blockHash.String() {
+			t.Errorf("event %d: %s in block %s, want %s in block %s", i, event.Msg, event.BlockHash, wantMsgs[i], blockHash)
+		}
+	}
+	if len(blockHashes) != 1 || blockHashes[0] != blockHash {
+		t.Errorf("blocks looked up by %v, want %s", blockHashes, blockHash)
+	}
+	//按 from 与 to 各查询一次日志
+	if len(logHashes) != 2 || logHashes[0] != blockHash || logHashes[1] != blockHash {
+		t.Errorf("logs filtered by %v, want %s", logHashes, blockHash)
+	}
+}
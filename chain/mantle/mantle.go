@@ -0,0 +1,475 @@
+package mantle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	ethereum2 "github.com/CavnHan/wallet-chain-account/chain/ethereum"
+	"github.com/CavnHan/wallet-chain-account/chain/tracker"
+	"github.com/CavnHan/wallet-chain-account/common/global_const"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	ChainName = "Mantle"
+
+	NativeSymbol = "MNT"
+	BVMETHSymbol = "ETH"
+
+	// 未提供 rawTx 时按原生转账估算 L2 gas
+	defaultTransferGasLimit = 21000
+)
+
+type ChainAdaptor struct {
+	ethClient ethereum2.EthClient
+	oracle    *GasPriceOracle
+	confirmer *ethereum2.Confirmer
+	tracker   *tracker.Tracker
+	nonces    *ethereum2.NonceAllocator
+	chainId   *big.Int
+}
+
+func init() {
+	chain.Register(ChainName, NewChainAdaptor)
+	config.RegisterChainValidator(ChainName, validateConfig)
+	config.RegisterLegacyNodeKey(ChainName, "mantle")
+}
+
+// validateConfig Mantle 没有链参数, params 中的键都视为拼写错误
+func validateConfig(node *config.ChainConfig) error {
+	var params struct{}
+	if err := node.DecodeParams(&params); err != nil {
+		return fmt.Errorf("params: %w", err)
+	}
+	return nil
+}
+
+func NewChainAdaptor(conf *config.Config) (chain.IChainAdaptor, error) {
+	node, ok := conf.Chain(ChainName)
+	if !ok {
+		return nil, errors.New("mantle rpc not configured")
+	}
+	if err := validateConfig(node); err != nil {
+		return nil, err
+	}
+	ethClient, err := ethereum2.DialEthClient(context.Background(), node.RPCs[0].RPCURL)
+	if err != nil {
+		return nil, err
+	}
+	chainId := new(big.Int).SetUint64(global_const.MantleChainId)
+	if conf.NetWork != "mainnet" {
+		chainId = new(big.Int).SetUint64(global_const.MantleSepoliaChainId)
+	}
+	return &ChainAdaptor{
+		ethClient: ethClient,
+		oracle:    NewGasPriceOracle(ethClient),
+		confirmer: ethereum2.NewConfirmer(ethClient, node),
+		tracker:   ethereum2.NewTracker(ethClient),
+		nonces:    ethereum2.SharedNonceAllocator(ChainName, conf.NetWork, ethClient),
+		chainId:   chainId,
+	}, nil
+}
+
+// Close 热加载替换后关闭 RPC 连接
+func (c *ChainAdaptor) Close() {
+	c.ethClient.Close()
+}
+
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	publicKey, err := crypto.UnmarshalPubkey(req.PublicKey)
+	if err != nil {
+		compressed, errDecompress := crypto.DecompressPubkey(req.PublicKey)
+		if errDecompress != nil {
+			log.Error("parse public key fail", "err", err)
+			return &account.ConvertAddressResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "parse public key fail",
+			}, nil
+		}
+		publicKey = compressed
+	}
+	return &account.ConvertAddressResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: crypto.PubkeyToAddress(*publicKey).String(),
+	}, nil
+}
+
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !ethcommon.IsHexAddress(req.Address) {
+		return &account.ValidAddressResponse{
+			Code:  common.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:             common.ReturnCode_SUCCESS,
+		Msg:              "valid address",
+		Valid:            true,
+		CanWithdrawal:    true,
+		CanonicalAddress: ethcommon.HexToAddress(req.Address).String(),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	var blockNumber *big.Int
+	if req.Height != 0 {
+		blockNumber = big.NewInt(req.Height)
+	}
+	block, err := c.ethClient.BlockByNumber(blockNumber)
+	if err != nil {
+		log.Error("block by number error", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "block by number error",
+		}, nil
+	}
+	//按高度查询的区块属于节点当前的规范链, 记录下来用于发现重组
+	if _, err := c.tracker.Observe(block.TrackerHeader()); err != nil {
+		log.Warn("track block fail", "height", req.Height, "err", err)
+	}
+	return ethereum2.BlockResponse(c.confirmer, block), nil
+}
+
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	block, err := c.ethClient.BlockByHash(ethcommon.HexToHash(req.Hash))
+	if err != nil {
+		log.Error("block by hash error", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "block by hash error",
+		}, nil
+	}
+	return ethereum2.BlockResponse(c.confirmer, block), nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	blockInfo, err := c.ethClient.BlockHeaderByHash(ethcommon.HexToHash(req.Hash))
+	if err != nil {
+		log.Error("get block header by hash fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        common.ReturnCode_SUCCESS,
+		Msg:         "get block header by hash success",
+		BlockHeader: ethereum2.ToBlockHeader(blockInfo),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	var blockNumber *big.Int
+	if req.Height != 0 {
+		blockNumber = big.NewInt(req.Height)
+	}
+	blockInfo, err := c.ethClient.BlockHeaderByNumber(blockNumber)
+	if err != nil {
+		log.Error("get block header by number fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        common.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: ethereum2.ToBlockHeader(blockInfo),
+	}, nil
+}
+
+/**
+ * @description: 查询账户余额
+ * 合约地址为空时返回原生 MNT 余额; Coin 为 ETH 时按 BVM_ETH 代币查询
+ * block_tag 指定查询的区块 (latest, safe, finalized, pending 或高度), 为空时为最新区块, nonce 与余额读取同一区块
+ * sequence 为该区块的 nonce, 并发构造交易时应通过 ReserveNonce 分配 nonce
+ */
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	blockNumber, err := ethereum2.ParseBlockTag(req.BlockTag)
+	if err != nil {
+		return &account.AccountResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	if !ethcommon.IsHexAddress(req.Address) || (req.ContractAddress != "" && !ethcommon.IsHexAddress(req.ContractAddress)) {
+		return &account.AccountResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid address or contract address",
+		}, nil
+	}
+	address := ethcommon.HexToAddress(req.Address)
+	nonceResult, err := c.ethClient.NonceAt(address, blockNumber)
+	if err != nil {
+		log.Error("get nonce by account fail", "err", err)
+		return &account.AccountResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get nonce by address fail",
+		}, nil
+	}
+
+	contractAddress := req.ContractAddress
+	if contractAddress == "" && strings.EqualFold(req.Coin, BVMETHSymbol) {
+		contractAddress = BVMETHAddress
+	}
+
+	balance, err := ethereum2.AccountBalance(c.ethClient, address, ethcommon.HexToAddress(contractAddress), blockNumber)
+	if err != nil {
+		log.Error("get balance by address fail", "err", err)
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "get balance by address fail",
+			Balance: "0",
+		}, nil
+	}
+	return &account.AccountResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      nonceResult.String(),
+		Network:       ChainName,
+		Balance:       balance.String(),
+	}, nil
+}
+
+/**
+ * @description: 估算手续费, 单位为 MNT 的 wei
+ * fee = gasLimit * gasPrice + L1 rollup fee, 其中 L1 fee 已按 tokenRatio 折算为 MNT
+ * gasPrice 为最新区块的 base fee 加上 tip, slow/normal/fast 分别使用建议 tip 的 0.5/1/2 倍
+ * rawTx 为空时按原生转账估算
+ */
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	txData, gasLimit, signed, err := c.feeTxData(req.RawTx)
+	if err != nil {
+		log.Error("decode raw tx fail", "err", err)
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	latest, err := c.ethClient.BlockHeaderByNumber(nil)
+	if err != nil {
+		log.Error("get latest block header fail", "err", err)
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get latest block header fail",
+		}, nil
+	}
+	if latest.BaseFee == nil {
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "latest block has no base fee",
+		}, nil
+	}
+	gasTipCap, err := c.ethClient.SuggestGasTipCap()
+	if err != nil {
+		log.Error("get gas tip cap fail", "err", err)
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get gas tip cap fail",
+		}, nil
+	}
+	params, err := c.oracle.Params()
+	if err != nil {
+		log.Error("get l1 fee params fail", "err", err)
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get l1 fee params fail",
+		}, nil
+	}
+	l1Fee := params.L1Fee(txData, signed)
+
+	//gasPrice = baseFee + tip * tipPercent / 100
+	fee := func(tipPercent int64) string {
+		tip := new(big.Int).Mul(gasTipCap, big.NewInt(tipPercent))
+		price := new(big.Int).Add(latest.BaseFee, tip.Div(tip, big.NewInt(100)))
+		total := new(big.Int).Mul(price, new(big.Int).SetUint64(gasLimit))
+		return total.Add(total, l1Fee).String()
+	}
+	return &account.FeeResponse{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee(50),
+		NormalFee: fee(100),
+		FastFee:   fee(200),
+	}, nil
+}
+
+// feeTxData 返回用于计算 L1 fee 的交易编码、L2 gas limit 以及交易是否已签名
+func (c *ChainAdaptor) feeTxData(rawTx string) ([]byte, uint64, bool, error) {
+	if rawTx == "" {
+		to := ethcommon.HexToAddress(global_const.ZeroAddress)
+		tx := types.NewTx(&types.DynamicFeeTx{
+			ChainID: c.chainId,
+			Gas:     defaultTransferGasLimit,
+			To:      &to,
+			Value:   big.NewInt(1),
+		})
+		txData, err := tx.MarshalBinary()
+		return txData, defaultTransferGasLimit, false, err
+	}
+	txData, err := hexutil.Decode(rawTx)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(txData); err != nil {
+		return nil, 0, false, err
+	}
+	v, r, s := tx.RawSignatureValues()
+	signed := v.Sign() != 0 || r.Sign() != 0 || s.Sign() != 0
+	return txData, tx.Gas(), signed, nil
+}
+
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	txData, err := hexutil.Decode(req.RawTx)
+	if err != nil {
+		log.Error("decode raw tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(txData); err != nil {
+		log.Error("unmarshal raw tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "unmarshal raw tx fail",
+		}, nil
+	}
+	if err := c.ethClient.SendRawTransaction(req.RawTx); err != nil {
+		log.Error("send raw tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "send raw tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: tx.Hash().String(),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	return &account.TxAddressResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "mantle does not support get tx by address",
+	}, nil
+}
+
+// GetTxByHash 交易未打包或未达到确认条件时状态为 Pending
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	return ethereum2.TxByHashResponse(c.ethClient, c.confirmer, req.Hash, c.chainId), nil
+}
+
+// GetBlockByRange start/end 为十进制区块高度, 区块头需首尾相连, 最多返回 100 个区块
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	return ethereum2.BlockByRangeResponse(c.ethClient, c.tracker, req, uint(c.chainId.Uint64())), nil
+}
+
+// DetectReorg 判断调用方记录的区块是否已被重组, 返回分叉点与被重组掉的区块
+func (c *ChainAdaptor) DetectReorg(req *account.ReorgRequest) (*account.ReorgResponse, error) {
+	return ethereum2.ReorgResponse(c.tracker, req), nil
+}
+
+// GetNfts 查询地址持有的 NFT, 需指定区块范围, 从范围内的转账日志收集
+func (c *ChainAdaptor) GetNfts(req *account.NftsRequest) (*account.NftsResponse, error) {
+	return ethereum2.NftsResponse(c.ethClient, nil, req, uint(c.chainId.Uint64())), nil
+}
+
+// GetNftMetadata 读取 NFT 的 tokenURI/uri
+func (c *ChainAdaptor) GetNftMetadata(req *account.NftMetadataRequest) (*account.NftMetadataResponse, error) {
+	return ethereum2.NftMetadataResponse(c.ethClient, req), nil
+}
+
+// BuildNftTransfer 构造未签名的 ERC-721/1155 safeTransferFrom 交易
+func (c *ChainAdaptor) BuildNftTransfer(req *account.NftTransferRequest) (*account.NftTransferResponse, error) {
+	return ethereum2.NftTransferResponse(c.ethClient, c.nonces, req, c.chainId), nil
+}
+
+// GetTransferLogs 查询区块范围内的 ERC-20/721/1155 转账事件, 最多 10000 个区块
+func (c *ChainAdaptor) GetTransferLogs(req *account.TransferLogsRequest) (*account.TransferLogsResponse, error) {
+	return ethereum2.TransferLogsResponse(c.ethClient, req, uint(c.chainId.Uint64())), nil
+}
+
+// GetBalances 在同一区块批量查询原生币与 ERC-20 余额, 使用 Multicall3 或 JSON-RPC 批量请求
+func (c *ChainAdaptor) GetBalances(req *account.BalancesRequest) (*account.BalancesResponse, error) {
+	return ethereum2.BalancesResponse(c.ethClient, req), nil
+}
+
+// ReserveNonce 在 pending nonce 之上预留地址的下一个可用 nonce
+func (c *ChainAdaptor) ReserveNonce(req *account.ReserveNonceRequest) (*account.ReserveNonceResponse, error) {
+	return ethereum2.ReserveNonceResponse(c.nonces, req), nil
+}
+
+// ReleaseNonce 释放预留的 nonce, 返回广播失败留下的空缺
+func (c *ChainAdaptor) ReleaseNonce(req *account.ReleaseNonceRequest) (*account.ReleaseNonceResponse, error) {
+	return ethereum2.ReleaseNonceResponse(c.nonces, req), nil
+}
+
+// SubscribeBlocks 推送新区块与重组事件, ws 节点使用 newHeads 订阅, 否则轮询
+func (c *ChainAdaptor) SubscribeBlocks(ctx context.Context, req *account.SubscribeBlocksRequest, send func(*account.BlockEvent) error) error {
+	return ethereum2.SubscribeBlocks(ctx, c.ethClient, req, uint(c.chainId.Uint64()), send)
+}
+
+// WatchAddresses 推送监听地址的原生币与 ERC-20 转账, 确认数随新区块更新
+func (c *ChainAdaptor) WatchAddresses(ctx context.Context, req *account.WatchAddressesRequest, send func(*account.TransferEvent) error) error {
+	return ethereum2.WatchAddresses(ctx, c.ethClient, c.confirmer, req, uint(c.chainId.Uint64()), send)
+}
+
+func (c *ChainAdaptor) CreateUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	return &account.UnSignTransactionResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "mantle does not support create un sign transaction",
+	}, nil
+}
+
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	return &account.SignedTransactionResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "mantle does not support build signed transaction",
+	}, nil
+}
+
+// DecodeTransaction raw_tx 为十六进制的交易编码, 识别原生币, ERC-20 与 NFT 转账
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return ethereum2.DecodeTransactionResponse(c.ethClient, req.RawTx, c.chainId), nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "mantle does not support verify signed transaction",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "mantle does not support get extra data",
+	}, nil
+}
@@ -0,0 +1,175 @@
+package mantle
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	ethereum2 "github.com/CavnHan/wallet-chain-account/chain/ethereum"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type callArg struct {
+	To    ethcommon.Address `json:"to"`
+	Input hexutil.Bytes     `json:"input"`
+}
+
+// rpcHandler 按方法与参数返回结果, 返回 nil 时结果为 null
+type rpcHandler func(t *testing.T, method string, params []json.RawMessage) interface{}
+
+// newTestAdaptor 以 handler 模拟 Mantle 节点的 JSON-RPC 接口
+func newTestAdaptor(t *testing.T, handler rpcHandler) *ChainAdaptor {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		result, err := json.Marshal(handler(t, req.Method, req.Params))
+		if err != nil {
+			t.Errorf("encode %s result: %v", req.Method, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": json.RawMessage(result)})
+	}))
+	t.Cleanup(srv.Close)
+	client, err := ethereum2.DialEthClient(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return &ChainAdaptor{ethClient: client, oracle: NewGasPriceOracle(client), chainId: big.NewInt(5000)}
+}
+
+func decodeParam(t *testing.T, params []json.RawMessage, i int, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(params[i], v); err != nil {
+		t.Errorf("decode param %d: %v", i, err)
+	}
+}
+
+func word(v int64) hexutil.Bytes {
+	return ethcommon.LeftPadBytes(big.NewInt(v).Bytes(), 32)
+}
+
+// oracleCall GasPriceOracle 的参数: l1BaseFee 30 gwei, overhead 188, scalar 0.684, tokenRatio 4000
+func oracleCall(t *testing.T, arg callArg) interface{} {
+	t.Helper()
+	if arg.To != ethcommon.HexToAddress(GasPriceOracleAddress) {
+		t.Errorf("oracle call sent to %s", arg.To)
+	}
+	switch string(arg.Input) {
+	case string(l1BaseFeeSelector):
+		return word(30_000_000_000)
+	case string(overheadSelector):
+		return word(188)
+	case string(scalarSelector):
+		return word(684_000)
+	case string(decimalsSelector):
+		return word(6)
+	case string(tokenRatioSelector):
+		return word(4000)
+	}
+	t.Errorf("unexpected oracle selector %x", []byte(arg.Input))
+	return nil
+}
+
+// 未签名的 EIP-1559 交易: chainId 5000, nonce 7, gas 60000, 向 0x..aa 转 1000 wei
+const unsignedTx = "0x02e682138807010282ea609400000000000000000000000000000000000000aa8203e880c0808080"
+
+// fee = (baseFee + tip * k) * gasLimit + L1 fee, L1 fee 按 19 个零字节, 21 个非零字节加 68 字节签名计 1500 gas
+func TestGetFee(t *testing.T) {
+	c := newTestAdaptor(t, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+		switch method {
+		case "eth_getBlockByNumber":
+			return &types.Header{Number: big.NewInt(100), Difficulty: new(big.Int), BaseFee: big.NewInt(1000)}
+		case "eth_maxPriorityFeePerGas":
+			return (*hexutil.Big)(big.NewInt(200))
+		case "eth_call":
+			var arg callArg
+			decodeParam(t, params, 0, &arg)
+			return oracleCall(t, arg)
+		}
+		t.Errorf("unexpected method %s", method)
+		return nil
+	})
+	resp, err := c.GetFee(&account.FeeRequest{RawTx: unsignedTx})
+	if err != nil || resp.Code != common.ReturnCode_SUCCESS {
+		t.Fatalf("GetFee: %v, %v (%s)", err, resp.Code, resp.Msg)
+	}
+	want := []string{"138551040066000000", "138551040072000000", "138551040084000000"}
+	got := []string{resp.SlowFee, resp.NormalFee, resp.FastFee}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("fees = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+// Coin 为 ETH 且未指定合约时按 BVM_ETH 代币查询余额
+func TestGetAccountBVMETH(t *testing.T) {
+	owner := ethcommon.HexToAddress("0x00000000000000000000000000000000000000aa")
+	var balanceOfTo []ethcommon.Address
+	c := newTestAdaptor(t, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+		switch method {
+		case "eth_getTransactionCount":
+			return hexutil.Uint64(5)
+		case "eth_call":
+			var arg callArg
+			decodeParam(t, params, 0, &arg)
+			if arg.To == ethereum2.Multicall3Address {
+				//该链未部署 Multicall3
+				return hexutil.Bytes{}
+			}
+			balanceOfTo = append(balanceOfTo, arg.To)
+			// balanceOf(owner)
+			if want := "0x70a08231" + strings.Repeat("0", 24) + strings.ToLower(owner.Hex()[2:]); arg.Input.String() != want {
+				t.Errorf("call input = %s, want %s", arg.Input, want)
+			}
+			return word(42)
+		case "eth_getBalance":
+			t.Error("native balance queried for BVM_ETH")
+			return (*hexutil.Big)(big.NewInt(1))
+		}
+		t.Errorf("unexpected method %s", method)
+		return nil
+	})
+	resp, err := c.GetAccount(&account.AccountRequest{Address: owner.Hex(), Coin: "eth"})
+	if err != nil || resp.Code != common.ReturnCode_SUCCESS {
+		t.Fatalf("GetAccount: %v, %v (%s)", err, resp.Code, resp.Msg)
+	}
+	if resp.Balance != "42" || resp.Sequence != "0x5" {
+		t.Errorf("balance %s sequence %s, want 42 and 0x5", resp.Balance, resp.Sequence)
+	}
+	if len(balanceOfTo) != 1 || balanceOfTo[0] != ethcommon.HexToAddress(BVMETHAddress) {
+		t.Errorf("balanceOf sent to %v, want %s", balanceOfTo, BVMETHAddress)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	if err := validateConfig(&config.ChainConfig{}); err != nil {
+		t.Errorf("empty params: %v", err)
+	}
+	err := validateConfig(&config.ChainConfig{Params: map[string]interface{}{"gas_limit": 21000}})
+	if err == nil || !strings.Contains(err.Error(), "gas_limit") {
+		t.Errorf("err = %v, want gas_limit rejected", err)
+	}
+}
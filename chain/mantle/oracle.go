@@ -0,0 +1,113 @@
+package mantle
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	ethereum2 "github.com/CavnHan/wallet-chain-account/chain/ethereum"
+)
+
+const (
+	// Mantle 上 MNT 为原生 gas 代币, ETH 以 BVM_ETH (ERC-20) 形式存在
+	BVMETHAddress = "0xdEAddEaDdeadDEadDEADDEAddEADDEAddead1111"
+	// L2 预部署的 GasPriceOracle, 用于计算 L1 rollup fee
+	GasPriceOracleAddress = "0x420000000000000000000000000000000000000F"
+
+	// 未签名交易计算 L1 data gas 时补齐的签名长度 (r, s, v)
+	signaturePaddingBytes = 68
+)
+
+var (
+	l1BaseFeeSelector  = crypto.Keccak256([]byte("l1BaseFee()"))[:4]
+	overheadSelector   = crypto.Keccak256([]byte("overhead()"))[:4]
+	scalarSelector     = crypto.Keccak256([]byte("scalar()"))[:4]
+	decimalsSelector   = crypto.Keccak256([]byte("decimals()"))[:4]
+	tokenRatioSelector = crypto.Keccak256([]byte("tokenRatio()"))[:4]
+)
+
+// L1FeeParams GasPriceOracle 中参与 L1 rollup fee 计算的参数
+type L1FeeParams struct {
+	L1BaseFee  *big.Int
+	Overhead   *big.Int
+	Scalar     *big.Int
+	Decimals   *big.Int
+	TokenRatio *big.Int
+}
+
+type GasPriceOracle struct {
+	ethClient ethereum2.EthClient
+	address   common.Address
+}
+
+func NewGasPriceOracle(ethClient ethereum2.EthClient) *GasPriceOracle {
+	return &GasPriceOracle{
+		ethClient: ethClient,
+		address:   common.HexToAddress(GasPriceOracleAddress),
+	}
+}
+
+func (o *GasPriceOracle) callUint(selector []byte) (*big.Int, error) {
+	result, err := o.ethClient.CallContract(ethereum.CallMsg{To: &o.address, Data: selector}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) < 32 {
+		return nil, errors.New("gas price oracle returned short result")
+	}
+	return new(big.Int).SetBytes(result[:32]), nil
+}
+
+// Params 读取最新块上的 L1 fee 参数
+func (o *GasPriceOracle) Params() (*L1FeeParams, error) {
+	selectors := [][]byte{l1BaseFeeSelector, overheadSelector, scalarSelector, decimalsSelector, tokenRatioSelector}
+	values := make([]*big.Int, len(selectors))
+	for i, selector := range selectors {
+		value, err := o.callUint(selector)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return &L1FeeParams{
+		L1BaseFee:  values[0],
+		Overhead:   values[1],
+		Scalar:     values[2],
+		Decimals:   values[3],
+		TokenRatio: values[4],
+	}, nil
+}
+
+/**
+ * @description: 计算 L1 rollup fee, 单位为 MNT 的 wei
+ * L1 fee = (dataGas + overhead) * l1BaseFee * scalar / 10^decimals * tokenRatio
+ * @param txData 交易的 RLP 编码
+ * @param signed 交易是否已签名, 未签名时补齐签名长度
+ */
+func (p *L1FeeParams) L1Fee(txData []byte, signed bool) *big.Int {
+	l1GasUsed := new(big.Int).SetUint64(dataGas(txData, signed))
+	l1GasUsed.Add(l1GasUsed, p.Overhead)
+	fee := new(big.Int).Mul(l1GasUsed, p.L1BaseFee)
+	fee.Mul(fee, p.Scalar)
+	fee.Div(fee, new(big.Int).Exp(big.NewInt(10), p.Decimals, nil))
+	return fee.Mul(fee, p.TokenRatio)
+}
+
+// dataGas 按 calldata 计价规则统计交易数据 gas, 零字节 4, 非零字节 16
+func dataGas(data []byte, signed bool) uint64 {
+	var gas uint64
+	for _, b := range data {
+		if b == 0 {
+			gas += 4
+		} else {
+			gas += 16
+		}
+	}
+	if !signed {
+		gas += signaturePaddingBytes * 16
+	}
+	return gas
+}
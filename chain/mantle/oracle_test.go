@@ -0,0 +1,50 @@
+package mantle
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+// L1 fee = (dataGas + 188) * 30 gwei * 684000 / 10^6 * 4000
+func TestL1Fee(t *testing.T) {
+	c := newTestAdaptor(t, func(t *testing.T, method string, params []json.RawMessage) interface{} {
+		if method != "eth_call" {
+			t.Errorf("unexpected method %s", method)
+			return nil
+		}
+		var arg callArg
+		decodeParam(t, params, 0, &arg)
+		return oracleCall(t, arg)
+	})
+	oracleParams, err := c.oracle.Params()
+	if err != nil {
+		t.Fatalf("Params: %v", err)
+	}
+	tests := []struct {
+		name   string
+		data   []byte
+		signed bool
+		want   string
+	}{
+		// 2 个零字节, 2 个非零字节: 40 gas
+		{"signed", []byte{0x00, 0x01, 0x02, 0x00}, true, "18714240000000000"},
+		// 补齐 68 字节签名: 40 + 1088 gas
+		{"unsigned", []byte{0x00, 0x01, 0x02, 0x00}, false, "108017280000000000"},
+		{"empty signed", nil, true, "15431040000000000"},
+	}
+	for _, tt := range tests {
+		if got := oracleParams.L1Fee(tt.data, tt.signed); got.Cmp(mustBig(t, tt.want)) != 0 {
+			t.Errorf("%s: L1Fee = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func mustBig(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("invalid number %s", s)
+	}
+	return v
+}
@@ -0,0 +1,55 @@
+package chain
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/CavnHan/wallet-chain-account/config"
+)
+
+// Factory 链适配器工厂方法
+type Factory func(conf *config.Config) (IChainAdaptor, error)
+
+var (
+	factoriesLock sync.RWMutex
+	factories     = make(map[string]Factory)
+)
+
+/**
+ * @description: 注册链适配器, 由适配器包在 init() 中调用, 外部包通过空导入 (import _) 即可接入
+ * 链名重复或工厂为空时 panic, 与 database/sql.Register 一致
+ * @param name 链名
+ * @param factory 工厂方法
+ */
+func Register(name string, factory Factory) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	if factory == nil {
+		panic("chain: register nil factory for " + name)
+	}
+	if _, dup := factories[name]; dup {
+		panic(fmt.Sprintf("chain: register called twice for %s", name))
+	}
+	factories[name] = factory
+}
+
+// Lookup 查找已注册的工厂方法
+func Lookup(name string) (Factory, bool) {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+	factory, ok := factories[name]
+	return factory, ok
+}
+
+// Registered 已注册的链名, 按字母序
+func Registered() []string {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,69 @@
+package chain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CavnHan/wallet-chain-account/config"
+)
+
+func testFactory(conf *config.Config) (IChainAdaptor, error) {
+	return nil, nil
+}
+
+// mustPanic 返回 f 的 panic 信息, 未 panic 时测试失败
+func mustPanic(t *testing.T, f func()) (msg string) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			msg, _ = r.(string)
+			return
+		}
+		t.Error("expected panic")
+	}()
+	f()
+	return ""
+}
+
+func TestRegister(t *testing.T) {
+	Register("RegistryTestChainB", testFactory)
+	Register("RegistryTestChainA", testFactory)
+
+	if factory, ok := Lookup("RegistryTestChainA"); !ok || factory == nil {
+		t.Errorf("Lookup(RegistryTestChainA) = %v, %v, want registered factory", factory, ok)
+	}
+	if _, ok := Lookup("RegistryTestChainMissing"); ok {
+		t.Error("Lookup found an unregistered chain")
+	}
+	var names []string
+	for _, name := range Registered() {
+		if strings.HasPrefix(name, "RegistryTestChain") {
+			names = append(names, name)
+		}
+	}
+	if strings.Join(names, ",") != "RegistryTestChainA,RegistryTestChainB" {
+		t.Errorf("Registered = %v, want sorted test chains", names)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	Register("RegistryTestChainDup", testFactory)
+	tests := []struct {
+		name    string
+		chain   string
+		factory Factory
+		want    string
+	}{
+		{"nil factory", "RegistryTestChainNil", nil, "register nil factory for RegistryTestChainNil"},
+		{"duplicate name", "RegistryTestChainDup", testFactory, "register called twice for RegistryTestChainDup"},
+	}
+	for _, tt := range tests {
+		msg := mustPanic(t, func() { Register(tt.chain, tt.factory) })
+		if !strings.Contains(msg, tt.want) {
+			t.Errorf("%s: panic = %q, want %q", tt.name, msg, tt.want)
+		}
+	}
+	if _, ok := Lookup("RegistryTestChainNil"); ok {
+		t.Error("nil factory was registered")
+	}
+}
@@ -0,0 +1,120 @@
+package solana
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/CavnHan/wallet-chain-account/common/base58"
+)
+
+const PublicKeyLength = 32
+
+var (
+	SystemProgramID          = MustPublicKeyFromBase58("11111111111111111111111111111111")
+	TokenProgramID           = MustPublicKeyFromBase58("TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA")
+	Token2022ProgramID       = MustPublicKeyFromBase58("TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb")
+	AssociatedTokenProgramID = MustPublicKeyFromBase58("ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL")
+	ComputeBudgetProgramID   = MustPublicKeyFromBase58("ComputeBudget111111111111111111111111111111")
+)
+
+var (
+	errInvalidPublicKey       = errors.New("invalid solana public key")
+	errProgramAddressNotFound = errors.New("unable to find a viable program address bump seed")
+
+	programDerivedAddressSeedLabel = []byte("ProgramDerivedAddress")
+)
+
+type PublicKey [PublicKeyLength]byte
+
+func PublicKeyFromBase58(address string) (PublicKey, error) {
+	var pk PublicKey
+	raw, err := base58.Decode(address)
+	if err != nil || len(raw) != PublicKeyLength {
+		return pk, errInvalidPublicKey
+	}
+	copy(pk[:], raw)
+	return pk, nil
+}
+
+func MustPublicKeyFromBase58(address string) PublicKey {
+	pk, err := PublicKeyFromBase58(address)
+	if err != nil {
+		panic(err)
+	}
+	return pk
+}
+
+func (pk PublicKey) String() string {
+	return base58.Encode(pk[:])
+}
+
+// ed25519 曲线参数, p = 2^255 - 19, d = -121665/121666
+var (
+	curveP = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	curveD = func() *big.Int {
+		d := new(big.Int).ModInverse(big.NewInt(121666), curveP)
+		d.Mul(d, big.NewInt(-121665))
+		return d.Mod(d, curveP)
+	}()
+)
+
+// IsOnCurve 判断 32 字节是否为合法的 ed25519 压缩点, PDA 必须不在曲线上
+func IsOnCurve(pk PublicKey) bool {
+	var le [PublicKeyLength]byte
+	copy(le[:], pk[:])
+	le[31] &= 0x7f
+	for i, j := 0, len(le)-1; i < j; i, j = i+1, j-1 {
+		le[i], le[j] = le[j], le[i]
+	}
+	y := new(big.Int).SetBytes(le[:])
+	y.Mod(y, curveP)
+	y2 := new(big.Int).Mul(y, y)
+	y2.Mod(y2, curveP)
+	u := new(big.Int).Sub(y2, big.NewInt(1))
+	u.Mod(u, curveP)
+	v := new(big.Int).Mul(curveD, y2)
+	v.Add(v, big.NewInt(1))
+	v.Mod(v, curveP)
+	if v.Sign() == 0 {
+		return false
+	}
+	x2 := new(big.Int).Mul(u, new(big.Int).ModInverse(v, curveP))
+	x2.Mod(x2, curveP)
+	if x2.Sign() == 0 {
+		return true
+	}
+	return new(big.Int).ModSqrt(x2, curveP) != nil
+}
+
+func createProgramAddress(seeds [][]byte, programID PublicKey) (PublicKey, error) {
+	h := sha256.New()
+	for _, seed := range seeds {
+		h.Write(seed)
+	}
+	h.Write(programID[:])
+	h.Write(programDerivedAddressSeedLabel)
+	var pk PublicKey
+	copy(pk[:], h.Sum(nil))
+	if IsOnCurve(pk) {
+		return PublicKey{}, errInvalidPublicKey
+	}
+	return pk, nil
+}
+
+// FindProgramAddress 从 255 开始递减 bump, 返回第一个不在曲线上的地址
+func FindProgramAddress(seeds [][]byte, programID PublicKey) (PublicKey, uint8, error) {
+	for bump := 255; bump >= 0; bump-- {
+		pk, err := createProgramAddress(append(seeds, []byte{byte(bump)}), programID)
+		if err == nil {
+			return pk, uint8(bump), nil
+		}
+	}
+	return PublicKey{}, 0, errProgramAddressNotFound
+}
+
+// FindAssociatedTokenAddress 计算 owner 在 mint 下的关联代币账户 (ATA)
+func FindAssociatedTokenAddress(owner, mint, tokenProgramID PublicKey) (PublicKey, error) {
+	ata, _, err := FindProgramAddress([][]byte{owner[:], tokenProgramID[:], mint[:]}, AssociatedTokenProgramID)
+	return ata, err
+}
@@ -0,0 +1,48 @@
+package solana
+
+import "testing"
+
+// 期望值由独立实现 (sha256 + ed25519 曲线判定) 计算
+func TestFindAssociatedTokenAddress(t *testing.T) {
+	tests := []struct {
+		owner    PublicKey
+		want     PublicKey
+		wantBump uint8
+	}{
+		{fromKey, fromATA, 255},
+		// bump 255 落在曲线上
+		{toKey, toATA, 254},
+	}
+	for _, tt := range tests {
+		ata, err := FindAssociatedTokenAddress(tt.owner, usdcMint, TokenProgramID)
+		if err != nil || ata != tt.want {
+			t.Errorf("ATA(%s) = %s, %v, want %s", tt.owner, ata, err, tt.want)
+		}
+		_, bump, _ := FindProgramAddress([][]byte{tt.owner[:], TokenProgramID[:], usdcMint[:]}, AssociatedTokenProgramID)
+		if bump != tt.wantBump {
+			t.Errorf("bump(%s) = %d, want %d", tt.owner, bump, tt.wantBump)
+		}
+	}
+}
+
+func TestIsOnCurve(t *testing.T) {
+	tests := []struct {
+		key  PublicKey
+		want bool
+	}{
+		{fromKey, true},
+		// 任意 32 字节约一半不在曲线上
+		{toKey, false},
+		{usdcMint, true},
+		{SystemProgramID, true},
+		{fromATA, false},
+		{toATA, false},
+		// y = 1 为单位元
+		{PublicKey{1}, true},
+	}
+	for _, tt := range tests {
+		if got := IsOnCurve(tt.key); got != tt.want {
+			t.Errorf("IsOnCurve(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
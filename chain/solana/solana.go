@@ -1 +1,763 @@
 package solana
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	ChainName = "Solana"
+
+	// 未设置 compute unit limit 时单条指令的默认上限
+	defaultComputeUnitLimit = 200000
+	microLamportsPerLamport = 1000000
+
+	defaultTxPageSize = 20
+)
+
+type ChainAdaptor struct {
+	solClient SolClient
+}
+
+func init() {
+	chain.Register(ChainName, NewChainAdaptor)
+}
+
+func NewChainAdaptor(conf *config.Config) (chain.IChainAdaptor, error) {
+	node, ok := conf.Chain(ChainName)
+	if !ok {
+		return nil, errors.New("solana rpc not configured")
+	}
+	solClient, err := DialSolClient(context.Background(), node.RPCs[0].RPCURL)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		solClient: solClient,
+	}, nil
+}
+
+// Close 热加载替换后关闭 RPC 连接
+func (c *ChainAdaptor) Close() {
+	c.solClient.Close()
+}
+
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// ConvertAddress Solana 地址即 32 字节 ed25519 公钥的 base58 编码
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if len(req.PublicKey) != ed25519.PublicKeySize {
+		return &account.ConvertAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid ed25519 public key",
+		}, nil
+	}
+	var pk PublicKey
+	copy(pk[:], req.PublicKey)
+	return &account.ConvertAddressResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: pk.String(),
+	}, nil
+}
+
+// ValidAddress PDA 等不在曲线上的地址没有私钥, 不能作为提现地址
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	pk, err := PublicKeyFromBase58(req.Address)
+	if err != nil {
+		return &account.ValidAddressResponse{
+			Code:  common.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:             common.ReturnCode_SUCCESS,
+		Msg:              "valid address",
+		Valid:            true,
+		CanWithdrawal:    IsOnCurve(pk),
+		CanonicalAddress: pk.String(),
+	}, nil
+}
+
+// GetBlockByNumber height 为 slot, 为 0 时取最新 slot
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	slot, block, err := c.getBlock(uint64(req.Height))
+	if err != nil {
+		log.Error("get block by number fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	var txList []*account.BlockInfoTransactionList
+	if req.ViewTx {
+		for _, signature := range block.Signatures {
+			item := &account.BlockInfoTransactionList{Hash: signature}
+			if block.BlockTime != nil {
+				item.Time = strconv.FormatInt(*block.BlockTime, 10)
+			}
+			txList = append(txList, item)
+		}
+	}
+	return &account.BlockResponse{
+		Code:         common.ReturnCode_SUCCESS,
+		Msg:          "get block by number success",
+		Height:       int64(slot),
+		Hash:         block.Blockhash,
+		Transactions: txList,
+	}, nil
+}
+
+// GetBlockByHash Solana 节点不提供按 blockhash 查询区块的接口
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return &account.BlockResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "solana does not support get block by hash",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	return &account.BlockHeaderResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "solana does not support get block header by hash",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	slot, block, err := c.getBlock(uint64(req.Height))
+	if err != nil {
+		log.Error("get block header by number fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	header := &account.BlockHeader{
+		ParentHash: block.PreviousBlockhash,
+		Number:     strconv.FormatUint(slot, 10),
+	}
+	if block.BlockTime != nil {
+		header.Time = uint64(*block.BlockTime)
+	}
+	return &account.BlockHeaderResponse{
+		Code:        common.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: header,
+	}, nil
+}
+
+func (c *ChainAdaptor) getBlock(slot uint64) (uint64, *BlockResult, error) {
+	if slot == 0 {
+		latest, err := c.solClient.GetSlot(CommitmentConfirmed)
+		if err != nil {
+			return 0, nil, err
+		}
+		slot = latest
+	}
+	block, err := c.solClient.GetBlock(slot)
+	return slot, block, err
+}
+
+// GetAccount 合约地址为空时返回 SOL 余额 (lamports), 否则返回该 mint 下所有代币账户余额之和
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	balance := new(big.Int)
+	if req.ContractAddress == "" {
+		lamports, err := c.solClient.GetBalance(req.Address)
+		if err != nil {
+			log.Error("get balance by address fail", "err", err)
+			return &account.AccountResponse{
+				Code:    common.ReturnCode_ERROR,
+				Msg:     "get balance by address fail",
+				Balance: "0",
+			}, nil
+		}
+		balance.SetUint64(lamports)
+	} else {
+		tokenAccounts, err := c.solClient.GetTokenAccountsByOwner(req.Address, req.ContractAddress)
+		if err != nil {
+			log.Error("get token accounts by owner fail", "err", err)
+			return &account.AccountResponse{
+				Code:    common.ReturnCode_ERROR,
+				Msg:     "get token accounts by owner fail",
+				Balance: "0",
+			}, nil
+		}
+		for _, tokenAccount := range tokenAccounts {
+			amount, ok := new(big.Int).SetString(tokenAccount.Account.Data.Parsed.Info.TokenAmount.Amount, 10)
+			if ok {
+				balance.Add(balance, amount)
+			}
+		}
+	}
+	return &account.AccountResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Network:       ChainName,
+		Balance:       balance.String(),
+	}, nil
+}
+
+/**
+ * @description: 估算手续费, 单位为 lamports
+ * rawTx 为 base64(json) 的 TxStructure, 为空时按 req.Address 的 SOL 转账估算
+ * 基础费用取 getFeeForMessage, 优先费按 getRecentPrioritizationFees 的 25/50/75 分位 * compute unit limit 计算
+ */
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	txStructure := &TxStructure{FromAddress: req.Address, ToAddress: req.Address, Value: "1"}
+	if req.RawTx != "" {
+		decoded, err := decodeTxStructure(req.RawTx)
+		if err != nil {
+			log.Error("decode tx structure fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "decode tx structure fail",
+			}, nil
+		}
+		txStructure = decoded
+	}
+	// 基础费用不含优先费, 先去掉 compute unit price
+	baseStructure := *txStructure
+	baseStructure.ComputeUnitPrice = 0
+	message, err := c.buildMessage(&baseStructure)
+	if err != nil {
+		log.Error("build message fail", "err", err)
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "build message fail",
+		}, nil
+	}
+	baseFee, err := c.solClient.GetFeeForMessage(base64.StdEncoding.EncodeToString(message.Serialize()))
+	if err != nil {
+		log.Error("get fee for message fail", "err", err)
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get fee for message fail",
+		}, nil
+	}
+	priorityFees, err := c.solClient.GetRecentPrioritizationFees(message.WritableAccounts())
+	if err != nil {
+		log.Error("get recent prioritization fees fail", "err", err)
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get recent prioritization fees fail",
+		}, nil
+	}
+	var prices []uint64
+	for _, fee := range priorityFees {
+		prices = append(prices, fee.PrioritizationFee)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+	unitLimit := uint64(txStructure.ComputeUnitLimit)
+	if unitLimit == 0 {
+		unitLimit = defaultComputeUnitLimit
+	}
+	fee := func(percentile int) string {
+		var price uint64
+		if len(prices) > 0 {
+			price = prices[(len(prices)-1)*percentile/100]
+		}
+		priority := new(big.Int).Mul(new(big.Int).SetUint64(price), new(big.Int).SetUint64(unitLimit))
+		priority.Add(priority, big.NewInt(microLamportsPerLamport-1))
+		priority.Div(priority, big.NewInt(microLamportsPerLamport))
+		return priority.Add(priority, new(big.Int).SetUint64(baseFee)).String()
+	}
+	return &account.FeeResponse{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee(25),
+		NormalFee: fee(50),
+		FastFee:   fee(75),
+	}, nil
+}
+
+// SendTx raw_tx 为 base64 编码的已签名交易, 返回交易签名
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	signature, err := c.solClient.SendTransaction(req.RawTx)
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: signature,
+	}, nil
+}
+
+/**
+ * @description: 查询地址交易记录
+ * 合约地址不为空时查询该地址在此 mint 下的关联代币账户, Cursor 为上一页最后一笔交易的签名
+ */
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	address := req.Address
+	if req.ContractAddress != "" {
+		ata, err := c.associatedTokenAddress(req.Address, req.ContractAddress)
+		if err != nil {
+			log.Error("derive associated token address fail", "err", err)
+			return &account.TxAddressResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "derive associated token address fail",
+			}, nil
+		}
+		address = ata.String()
+	}
+	pageSize := req.Pagesize
+	if pageSize == 0 {
+		pageSize = defaultTxPageSize
+	}
+	signatureInfos, err := c.solClient.GetSignaturesForAddress(address, pageSize, req.Cursor)
+	if err != nil {
+		log.Error("get signatures for address fail", "err", err)
+		return &account.TxAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get signatures for address fail",
+		}, nil
+	}
+	signatures := make([]string, len(signatureInfos))
+	for i, info := range signatureInfos {
+		signatures[i] = info.Signature
+	}
+	txs, err := c.solClient.GetTransactions(signatures)
+	if err != nil {
+		log.Error("get transactions fail", "err", err)
+		return &account.TxAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get transactions fail",
+		}, nil
+	}
+	var txList []*account.TxMessage
+	for i, info := range signatureInfos {
+		txMessage := toTxMessage(info.Signature, txs[i])
+		txMessage.Status = txStatus(info.ConfirmationStatus, info.Err)
+		txList = append(txList, txMessage)
+	}
+	return &account.TxAddressResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txList,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	statuses, err := c.solClient.GetSignatureStatuses([]string{req.Hash})
+	if err != nil {
+		log.Error("get signature status fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get signature status fail",
+		}, nil
+	}
+	if len(statuses) == 0 || statuses[0] == nil {
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_SUCCESS,
+			Msg:  "tx not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	tx, err := c.solClient.GetTransaction(req.Hash)
+	if err != nil {
+		log.Error("get tx by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}, nil
+	}
+	txMessage := toTxMessage(req.Hash, tx)
+	txMessage.Status = txStatus(statuses[0].ConfirmationStatus, statuses[0].Err)
+	return &account.TxHashResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by hash success",
+		Tx:   txMessage,
+	}, nil
+}
+
+// GetBlockByRange start/end 为 slot, 跳过的 slot 不返回
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, errStart := strconv.ParseUint(req.Start, 10, 64)
+	end, errEnd := strconv.ParseUint(req.End, 10, 64)
+	if errStart != nil || errEnd != nil || start > end {
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid block range",
+		}, nil
+	}
+	slots, err := c.solClient.GetBlocks(start, end)
+	if err != nil {
+		log.Error("get blocks fail", "err", err)
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get blocks fail",
+		}, nil
+	}
+	var blockList []*account.BlockData
+	for _, slot := range slots {
+		block, err := c.solClient.GetBlock(slot)
+		if err != nil {
+			log.Error("get block fail", "slot", slot, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block fail",
+			}, nil
+		}
+		var txList []*account.TxMessage
+		for _, signature := range block.Signatures {
+			txList = append(txList, &account.TxMessage{
+				Hash:   signature,
+				Height: strconv.FormatUint(slot, 10),
+			})
+		}
+		blockList = append(blockList, &account.BlockData{
+			Hash:         block.Blockhash,
+			Transactions: txList,
+		})
+	}
+	return &account.BlockByRangeResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "get block by range success",
+		Blocks: blockList,
+	}, nil
+}
+
+/**
+ * @description: 创建未签名交易
+ * base64_tx 为 base64(json) 的 TxStructure, 未指定 recent_blockhash 时取节点最新值
+ * SPL 转账时接收方关联代币账户不存在则先创建 (createIdempotent), 租金由发送方支付
+ * 返回 base64 编码的消息, 签名对象为消息原始字节
+ */
+func (c *ChainAdaptor) CreateUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txStructure, err := decodeTxStructure(req.Base64Tx)
+	if err != nil {
+		log.Error("decode tx structure fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode tx structure fail",
+		}, nil
+	}
+	message, err := c.buildMessage(txStructure)
+	if err != nil {
+		log.Error("build message fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "build message fail",
+		}, nil
+	}
+	return &account.UnSignTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "create un sign transaction success",
+		UnSignTx: base64.StdEncoding.EncodeToString(message.Serialize()),
+	}, nil
+}
+
+func (c *ChainAdaptor) buildMessage(txStructure *TxStructure) (*Message, error) {
+	from, err := PublicKeyFromBase58(txStructure.FromAddress)
+	if err != nil {
+		return nil, err
+	}
+	to, err := PublicKeyFromBase58(txStructure.ToAddress)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := strconv.ParseUint(txStructure.Value, 10, 64)
+	if err != nil || amount == 0 {
+		return nil, errors.New("invalid transfer value")
+	}
+
+	var instructions []Instruction
+	if txStructure.ComputeUnitLimit > 0 {
+		instructions = append(instructions, SetComputeUnitLimit(txStructure.ComputeUnitLimit))
+	}
+	if txStructure.ComputeUnitPrice > 0 {
+		instructions = append(instructions, SetComputeUnitPrice(txStructure.ComputeUnitPrice))
+	}
+	if txStructure.ContractAddress == "" {
+		instructions = append(instructions, SystemTransfer(from, to, amount))
+	} else {
+		mint, err := PublicKeyFromBase58(txStructure.ContractAddress)
+		if err != nil {
+			return nil, err
+		}
+		tokenProgramID, decimals, err := c.mintInfo(txStructure.ContractAddress)
+		if err != nil {
+			return nil, err
+		}
+		source, err := FindAssociatedTokenAddress(from, mint, tokenProgramID)
+		if err != nil {
+			return nil, err
+		}
+		destination, err := FindAssociatedTokenAddress(to, mint, tokenProgramID)
+		if err != nil {
+			return nil, err
+		}
+		destinationInfo, err := c.solClient.GetAccountInfo(destination.String())
+		if err != nil {
+			return nil, err
+		}
+		if destinationInfo == nil {
+			instructions = append(instructions, CreateAssociatedTokenAccountIdempotent(from, destination, to, mint, tokenProgramID))
+		}
+		instructions = append(instructions, TokenTransferChecked(source, mint, destination, from, amount, decimals, tokenProgramID))
+	}
+
+	blockhash := txStructure.RecentBlockhash
+	if blockhash == "" {
+		latest, err := c.solClient.GetLatestBlockhash(CommitmentConfirmed)
+		if err != nil {
+			return nil, err
+		}
+		blockhash = latest.Blockhash
+	}
+	recentBlockhash, err := PublicKeyFromBase58(blockhash)
+	if err != nil {
+		return nil, err
+	}
+	return NewMessage(from, instructions, recentBlockhash), nil
+}
+
+// mintInfo mint 账户的 owner 即代币程序 (Token 或 Token-2022)
+func (c *ChainAdaptor) mintInfo(mint string) (PublicKey, uint8, error) {
+	info, err := c.solClient.GetAccountInfo(mint)
+	if err != nil {
+		return PublicKey{}, 0, err
+	}
+	if info == nil {
+		return PublicKey{}, 0, errors.New("mint account not found")
+	}
+	tokenProgramID, err := PublicKeyFromBase58(info.Owner)
+	if err != nil {
+		return PublicKey{}, 0, err
+	}
+	if tokenProgramID != TokenProgramID && tokenProgramID != Token2022ProgramID {
+		return PublicKey{}, 0, errors.New("account is not a token mint")
+	}
+	var parsed ParsedMint
+	if err := json.Unmarshal(info.Data, &parsed); err != nil || parsed.Parsed.Type != "mint" {
+		return PublicKey{}, 0, errors.New("account is not a token mint")
+	}
+	return tokenProgramID, parsed.Parsed.Info.Decimals, nil
+}
+
+func (c *ChainAdaptor) associatedTokenAddress(owner, mint string) (PublicKey, error) {
+	ownerKey, err := PublicKeyFromBase58(owner)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	mintKey, err := PublicKeyFromBase58(mint)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	tokenProgramID, _, err := c.mintInfo(mint)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	return FindAssociatedTokenAddress(ownerKey, mintKey, tokenProgramID)
+}
+
+/**
+ * @description: 组装签名交易
+ * base64_tx 为 CreateUnSignTransaction 返回的消息, signature 为手续费支付者对消息的 64 字节 ed25519 签名 (hex)
+ * 返回 base64 编码的已签名交易, 可直接用于 SendTx
+ */
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	messageBytes, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	message, err := DeserializeMessage(messageBytes)
+	if err != nil || len(message.AccountKeys) == 0 {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode message fail",
+		}, nil
+	}
+	if message.Header.NumRequiredSignatures != 1 {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "only single signer message is supported",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != SignatureLength {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	feePayer := message.AccountKeys[0]
+	if !ed25519.Verify(feePayer[:], messageBytes, signature) {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "verify signature fail",
+		}, nil
+	}
+	return &account.SignedTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "build signed transaction success",
+		SignedTx: base64.StdEncoding.EncodeToString(SerializeTransaction([][]byte{signature}, messageBytes)),
+	}, nil
+}
+
+// DecodeTransaction raw_tx 为 base64 编码的已签名交易, 返回 base64(json) 的 TxStructure
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	data, err := base64.StdEncoding.DecodeString(req.RawTx)
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	_, messageBytes, err := DeserializeTransaction(data)
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode signed transaction fail",
+		}, nil
+	}
+	message, err := DeserializeMessage(messageBytes)
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode message fail",
+		}, nil
+	}
+	txStructure, err := message.DecodeTransfer()
+	if err != nil {
+		log.Error("decode transfer fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode transfer fail",
+		}, nil
+	}
+	txJson, _ := json.Marshal(txStructure)
+	return &account.DecodeTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "decode transaction success",
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "solana does not support verify signed transaction",
+	}, nil
+}
+
+// GetExtraData 返回最新的 recent blockhash, 供离线构造交易使用
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	latest, err := c.solClient.GetLatestBlockhash(CommitmentConfirmed)
+	if err != nil {
+		log.Error("get latest blockhash fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get latest blockhash fail",
+		}, nil
+	}
+	return &account.ExtraDataResponse{
+		Code:  common.ReturnCode_SUCCESS,
+		Msg:   "get latest blockhash success",
+		Value: latest.Blockhash,
+	}, nil
+}
+
+func decodeTxStructure(base64Tx string) (*TxStructure, error) {
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var txStructure TxStructure
+	if err := json.Unmarshal(txJson, &txStructure); err != nil {
+		return nil, err
+	}
+	return &txStructure, nil
+}
+
+// txStatus finalized 视为成功, processed/confirmed 仍可能被回滚, 视为 Pending
+func txStatus(confirmationStatus string, txErr json.RawMessage) account.TxStatus {
+	if len(txErr) > 0 && string(txErr) != "null" {
+		return account.TxStatus_Failed
+	}
+	if confirmationStatus == CommitmentFinalized {
+		return account.TxStatus_Success
+	}
+	return account.TxStatus_Pending
+}
+
+// transferInfo jsonParsed 编码下 system transfer 与 spl-token transfer/transferChecked 的字段
+type transferInfo struct {
+	Source      string       `json:"source"`
+	Destination string       `json:"destination"`
+	Authority   string       `json:"authority"`
+	Mint        string       `json:"mint"`
+	Lamports    json.Number  `json:"lamports"`
+	Amount      string       `json:"amount"`
+	TokenAmount *TokenAmount `json:"tokenAmount"`
+}
+
+func toTxMessage(signature string, tx *TransactionResult) *account.TxMessage {
+	txMessage := &account.TxMessage{Hash: signature}
+	if tx == nil {
+		return txMessage
+	}
+	txMessage.Height = strconv.FormatUint(tx.Slot, 10)
+	if tx.BlockTime != nil {
+		txMessage.Datetime = strconv.FormatInt(*tx.BlockTime, 10)
+	}
+	if tx.Meta != nil {
+		txMessage.Fee = strconv.FormatUint(tx.Meta.Fee, 10)
+	}
+	for _, ins := range tx.Transaction.Message.Instructions {
+		if ins.Parsed == nil {
+			continue
+		}
+		var info transferInfo
+		if err := json.Unmarshal(ins.Parsed.Info, &info); err != nil {
+			continue
+		}
+		switch {
+		case ins.Program == "system" && ins.Parsed.Type == "transfer":
+			txMessage.Froms = append(txMessage.Froms, &account.Address{Address: info.Source})
+			txMessage.Tos = append(txMessage.Tos, &account.Address{Address: info.Destination})
+			txMessage.Values = append(txMessage.Values, &account.Value{Value: info.Lamports.String()})
+		case ins.Program == "spl-token" && ins.Parsed.Type == "transferChecked" && info.TokenAmount != nil:
+			txMessage.Froms = append(txMessage.Froms, &account.Address{Address: info.Authority})
+			txMessage.Tos = append(txMessage.Tos, &account.Address{Address: info.Destination})
+			txMessage.Values = append(txMessage.Values, &account.Value{Value: info.TokenAmount.Amount})
+			txMessage.ContractAddress = info.Mint
+		case ins.Program == "spl-token" && ins.Parsed.Type == "transfer":
+			txMessage.Froms = append(txMessage.Froms, &account.Address{Address: info.Authority})
+			txMessage.Tos = append(txMessage.Tos, &account.Address{Address: info.Destination})
+			txMessage.Values = append(txMessage.Values, &account.Value{Value: info.Amount})
+		}
+	}
+	return txMessage
+}
@@ -0,0 +1,325 @@
+package solana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	retry2 "github.com/CavnHan/wallet-chain-account/common/retry"
+)
+
+const (
+	defaultDialTimeout    = 5 * time.Second
+	defaultDialAttempts   = 5
+	defaultRequestTimeout = 10 * time.Second
+
+	CommitmentProcessed = "processed"
+	CommitmentConfirmed = "confirmed"
+	CommitmentFinalized = "finalized"
+)
+
+type contextValue[T any] struct {
+	Context struct {
+		Slot uint64 `json:"slot"`
+	} `json:"context"`
+	Value T `json:"value"`
+}
+
+type LatestBlockhash struct {
+	Blockhash            string `json:"blockhash"`
+	LastValidBlockHeight uint64 `json:"lastValidBlockHeight"`
+}
+
+type AccountInfo struct {
+	Lamports   uint64          `json:"lamports"`
+	Owner      string          `json:"owner"`
+	Executable bool            `json:"executable"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// ParsedMint jsonParsed 编码下 mint 账户数据
+type ParsedMint struct {
+	Parsed struct {
+		Type string `json:"type"`
+		Info struct {
+			Decimals uint8  `json:"decimals"`
+			Supply   string `json:"supply"`
+		} `json:"info"`
+	} `json:"parsed"`
+	Program string `json:"program"`
+}
+
+type TokenAmount struct {
+	Amount   string `json:"amount"`
+	Decimals uint8  `json:"decimals"`
+}
+
+type TokenAccount struct {
+	Pubkey  string `json:"pubkey"`
+	Account struct {
+		Data struct {
+			Parsed struct {
+				Info struct {
+					Mint        string      `json:"mint"`
+					Owner       string      `json:"owner"`
+					TokenAmount TokenAmount `json:"tokenAmount"`
+				} `json:"info"`
+			} `json:"parsed"`
+		} `json:"data"`
+	} `json:"account"`
+}
+
+type PrioritizationFee struct {
+	Slot              uint64 `json:"slot"`
+	PrioritizationFee uint64 `json:"prioritizationFee"`
+}
+
+type SignatureStatus struct {
+	Slot               uint64          `json:"slot"`
+	Confirmations      *uint64         `json:"confirmations"`
+	Err                json.RawMessage `json:"err"`
+	ConfirmationStatus string          `json:"confirmationStatus"`
+}
+
+type SignatureInfo struct {
+	Signature          string          `json:"signature"`
+	Slot               uint64          `json:"slot"`
+	Err                json.RawMessage `json:"err"`
+	BlockTime          *int64          `json:"blockTime"`
+	ConfirmationStatus string          `json:"confirmationStatus"`
+}
+
+type ParsedInstruction struct {
+	Program   string `json:"program"`
+	ProgramId string `json:"programId"`
+	Parsed    *struct {
+		Type string          `json:"type"`
+		Info json.RawMessage `json:"info"`
+	} `json:"parsed"`
+}
+
+type TransactionResult struct {
+	Slot      uint64 `json:"slot"`
+	BlockTime *int64 `json:"blockTime"`
+	Meta      *struct {
+		Err json.RawMessage `json:"err"`
+		Fee uint64          `json:"fee"`
+	} `json:"meta"`
+	Transaction struct {
+		Signatures []string `json:"signatures"`
+		Message    struct {
+			AccountKeys []struct {
+				Pubkey   string `json:"pubkey"`
+				Signer   bool   `json:"signer"`
+				Writable bool   `json:"writable"`
+			} `json:"accountKeys"`
+			Instructions    []ParsedInstruction `json:"instructions"`
+			RecentBlockhash string              `json:"recentBlockhash"`
+		} `json:"message"`
+	} `json:"transaction"`
+}
+
+type BlockResult struct {
+	Blockhash         string   `json:"blockhash"`
+	PreviousBlockhash string   `json:"previousBlockhash"`
+	ParentSlot        uint64   `json:"parentSlot"`
+	BlockTime         *int64   `json:"blockTime"`
+	BlockHeight       *uint64  `json:"blockHeight"`
+	Signatures        []string `json:"signatures"`
+}
+
+type SolClient interface {
+	GetSlot(commitment string) (uint64, error)
+	GetBalance(address string) (uint64, error)
+	GetAccountInfo(address string) (*AccountInfo, error)
+	GetTokenAccountsByOwner(owner, mint string) ([]TokenAccount, error)
+	GetLatestBlockhash(commitment string) (*LatestBlockhash, error)
+	GetFeeForMessage(message string) (uint64, error)
+	GetRecentPrioritizationFees(accounts []string) ([]PrioritizationFee, error)
+	SendTransaction(tx string) (string, error)
+	GetSignatureStatuses(signatures []string) ([]*SignatureStatus, error)
+	GetSignaturesForAddress(address string, limit uint32, before string) ([]SignatureInfo, error)
+	GetTransaction(signature string) (*TransactionResult, error)
+	GetTransactions(signatures []string) ([]*TransactionResult, error)
+	GetBlock(slot uint64) (*BlockResult, error)
+	GetBlocks(start, end uint64) ([]uint64, error)
+	Close()
+}
+
+type clnt struct {
+	rpc *rpc.Client
+}
+
+func DialSolClient(ctx context.Context, rpcUrl string) (SolClient, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultDialTimeout)
+	defer cancel()
+
+	bOff := retry2.Exponential()
+	rpcClient, err := retry2.Do(ctx, defaultDialAttempts, bOff, func() (*rpc.Client, error) {
+		client, err := rpc.DialContext(ctx, rpcUrl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial address (%s): %w", rpcUrl, err)
+		}
+		return client, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &clnt{rpc: rpcClient}, nil
+}
+
+func (c *clnt) call(result any, method string, args ...any) error {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	if err := c.rpc.CallContext(ctxwt, result, method, args...); err != nil {
+		log.Error("Call solana method fail", "method", method, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (c *clnt) GetSlot(commitment string) (uint64, error) {
+	var slot uint64
+	err := c.call(&slot, "getSlot", map[string]any{"commitment": commitment})
+	return slot, err
+}
+
+func (c *clnt) GetBalance(address string) (uint64, error) {
+	var result contextValue[uint64]
+	err := c.call(&result, "getBalance", address, map[string]any{"commitment": CommitmentConfirmed})
+	return result.Value, err
+}
+
+// GetAccountInfo 账户不存在时返回 nil
+func (c *clnt) GetAccountInfo(address string) (*AccountInfo, error) {
+	var result contextValue[*AccountInfo]
+	err := c.call(&result, "getAccountInfo", address, map[string]any{"encoding": "jsonParsed", "commitment": CommitmentConfirmed})
+	return result.Value, err
+}
+
+func (c *clnt) GetTokenAccountsByOwner(owner, mint string) ([]TokenAccount, error) {
+	var result contextValue[[]TokenAccount]
+	err := c.call(&result, "getTokenAccountsByOwner", owner, map[string]any{"mint": mint}, map[string]any{"encoding": "jsonParsed", "commitment": CommitmentConfirmed})
+	return result.Value, err
+}
+
+func (c *clnt) GetLatestBlockhash(commitment string) (*LatestBlockhash, error) {
+	var result contextValue[LatestBlockhash]
+	if err := c.call(&result, "getLatestBlockhash", map[string]any{"commitment": commitment}); err != nil {
+		return nil, err
+	}
+	return &result.Value, nil
+}
+
+// GetFeeForMessage message 为 base64 编码的消息, blockhash 过期时节点返回 null
+func (c *clnt) GetFeeForMessage(message string) (uint64, error) {
+	var result contextValue[*uint64]
+	if err := c.call(&result, "getFeeForMessage", message, map[string]any{"commitment": CommitmentConfirmed}); err != nil {
+		return 0, err
+	}
+	if result.Value == nil {
+		return 0, fmt.Errorf("message blockhash expired")
+	}
+	return *result.Value, nil
+}
+
+func (c *clnt) GetRecentPrioritizationFees(accounts []string) ([]PrioritizationFee, error) {
+	var fees []PrioritizationFee
+	err := c.call(&fees, "getRecentPrioritizationFees", accounts)
+	return fees, err
+}
+
+// SendTransaction tx 为 base64 编码的已签名交易
+func (c *clnt) SendTransaction(tx string) (string, error) {
+	var signature string
+	err := c.call(&signature, "sendTransaction", tx, map[string]any{"encoding": "base64", "preflightCommitment": CommitmentConfirmed})
+	return signature, err
+}
+
+func (c *clnt) GetSignatureStatuses(signatures []string) ([]*SignatureStatus, error) {
+	var result contextValue[[]*SignatureStatus]
+	err := c.call(&result, "getSignatureStatuses", signatures, map[string]any{"searchTransactionHistory": true})
+	return result.Value, err
+}
+
+func (c *clnt) GetSignaturesForAddress(address string, limit uint32, before string) ([]SignatureInfo, error) {
+	opts := map[string]any{"commitment": CommitmentConfirmed}
+	if limit > 0 {
+		opts["limit"] = limit
+	}
+	if before != "" {
+		opts["before"] = before
+	}
+	var result []SignatureInfo
+	err := c.call(&result, "getSignaturesForAddress", address, opts)
+	return result, err
+}
+
+func transactionOpts() map[string]any {
+	return map[string]any{
+		"encoding":                       "jsonParsed",
+		"commitment":                     CommitmentConfirmed,
+		"maxSupportedTransactionVersion": 0,
+	}
+}
+
+// GetTransaction 交易不存在时返回 nil
+func (c *clnt) GetTransaction(signature string) (*TransactionResult, error) {
+	var result *TransactionResult
+	err := c.call(&result, "getTransaction", signature, transactionOpts())
+	return result, err
+}
+
+func (c *clnt) GetTransactions(signatures []string) ([]*TransactionResult, error) {
+	if len(signatures) == 0 {
+		return nil, nil
+	}
+	results := make([]*TransactionResult, len(signatures))
+	batchElems := make([]rpc.BatchElem, len(signatures))
+	for i, signature := range signatures {
+		batchElems[i] = rpc.BatchElem{Method: "getTransaction", Args: []any{signature, transactionOpts()}, Result: &results[i]}
+	}
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	if err := c.rpc.BatchCallContext(ctxwt, batchElems); err != nil {
+		return nil, err
+	}
+	for _, elem := range batchElems {
+		if elem.Error != nil {
+			return nil, elem.Error
+		}
+	}
+	return results, nil
+}
+
+func (c *clnt) GetBlock(slot uint64) (*BlockResult, error) {
+	var block *BlockResult
+	opts := map[string]any{
+		"encoding":                       "json",
+		"transactionDetails":             "signatures",
+		"rewards":                        false,
+		"commitment":                     CommitmentConfirmed,
+		"maxSupportedTransactionVersion": 0,
+	}
+	if err := c.call(&block, "getBlock", slot, opts); err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not available", slot)
+	}
+	return block, nil
+}
+
+func (c *clnt) GetBlocks(start, end uint64) ([]uint64, error) {
+	var slots []uint64
+	err := c.call(&slots, "getBlocks", start, end, map[string]any{"commitment": CommitmentConfirmed})
+	return slots, err
+}
+
+func (c *clnt) Close() {
+	c.rpc.Close()
+}
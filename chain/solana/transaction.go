@@ -0,0 +1,462 @@
+package solana
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+const (
+	SignatureLength = 64
+
+	// 版本化消息首字节最高位为 1
+	messageVersionPrefix = 0x80
+
+	systemInstructionTransfer       = 2
+	tokenInstructionTransfer        = 3
+	tokenInstructionTransferChecked = 12
+	ataInstructionCreateIdempotent  = 1
+	computeBudgetSetUnitLimit       = 2
+	computeBudgetSetUnitPrice       = 3
+)
+
+var errInvalidMessage = errors.New("invalid solana message")
+
+// TxStructure CreateUnSignTransaction 入参及 DecodeTransaction 出参, 以 base64(json) 传递
+// ContractAddress 为 SPL mint 地址, 为空时为 SOL 转账; Value 单位为 lamports 或代币最小单位
+// ComputeUnitPrice 单位为 micro-lamports, 大于 0 时附加优先费指令
+type TxStructure struct {
+	FromAddress      string `json:"from_address"`
+	ToAddress        string `json:"to_address"`
+	Value            string `json:"value"`
+	ContractAddress  string `json:"contract_address,omitempty"`
+	ComputeUnitPrice uint64 `json:"compute_unit_price,omitempty"`
+	ComputeUnitLimit uint32 `json:"compute_unit_limit,omitempty"`
+	RecentBlockhash  string `json:"recent_blockhash,omitempty"`
+}
+
+type AccountMeta struct {
+	PublicKey  PublicKey
+	IsSigner   bool
+	IsWritable bool
+}
+
+type Instruction struct {
+	ProgramID PublicKey
+	Accounts  []AccountMeta
+	Data      []byte
+}
+
+type MessageHeader struct {
+	NumRequiredSignatures       uint8
+	NumReadonlySignedAccounts   uint8
+	NumReadonlyUnsignedAccounts uint8
+}
+
+type CompiledInstruction struct {
+	ProgramIDIndex uint8
+	Accounts       []uint8
+	Data           []byte
+}
+
+// Message legacy 消息, 序列化结果即为 ed25519 签名对象
+type Message struct {
+	Header          MessageHeader
+	AccountKeys     []PublicKey
+	RecentBlockhash PublicKey
+	Instructions    []CompiledInstruction
+}
+
+func SystemTransfer(from, to PublicKey, lamports uint64) Instruction {
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint32(data, systemInstructionTransfer)
+	binary.LittleEndian.PutUint64(data[4:], lamports)
+	return Instruction{
+		ProgramID: SystemProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: from, IsSigner: true, IsWritable: true},
+			{PublicKey: to, IsWritable: true},
+		},
+		Data: data,
+	}
+}
+
+func TokenTransferChecked(source, mint, destination, owner PublicKey, amount uint64, decimals uint8, tokenProgramID PublicKey) Instruction {
+	data := make([]byte, 10)
+	data[0] = tokenInstructionTransferChecked
+	binary.LittleEndian.PutUint64(data[1:], amount)
+	data[9] = decimals
+	return Instruction{
+		ProgramID: tokenProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: source, IsWritable: true},
+			{PublicKey: mint},
+			{PublicKey: destination, IsWritable: true},
+			{PublicKey: owner, IsSigner: true},
+		},
+		Data: data,
+	}
+}
+
+// CreateAssociatedTokenAccountIdempotent ATA 已存在时指令不报错
+func CreateAssociatedTokenAccountIdempotent(payer, ata, owner, mint, tokenProgramID PublicKey) Instruction {
+	return Instruction{
+		ProgramID: AssociatedTokenProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: payer, IsSigner: true, IsWritable: true},
+			{PublicKey: ata, IsWritable: true},
+			{PublicKey: owner},
+			{PublicKey: mint},
+			{PublicKey: SystemProgramID},
+			{PublicKey: tokenProgramID},
+		},
+		Data: []byte{ataInstructionCreateIdempotent},
+	}
+}
+
+func SetComputeUnitLimit(units uint32) Instruction {
+	data := make([]byte, 5)
+	data[0] = computeBudgetSetUnitLimit
+	binary.LittleEndian.PutUint32(data[1:], units)
+	return Instruction{ProgramID: ComputeBudgetProgramID, Data: data}
+}
+
+func SetComputeUnitPrice(microLamports uint64) Instruction {
+	data := make([]byte, 9)
+	data[0] = computeBudgetSetUnitPrice
+	binary.LittleEndian.PutUint64(data[1:], microLamports)
+	return Instruction{ProgramID: ComputeBudgetProgramID, Data: data}
+}
+
+/**
+ * @description: 编译 legacy 消息
+ * 账户排序: 手续费支付者, 可写签名者, 只读签名者, 可写非签名者, 只读非签名者
+ */
+func NewMessage(feePayer PublicKey, instructions []Instruction, recentBlockhash PublicKey) *Message {
+	type keyMeta struct {
+		signer, writable bool
+	}
+	metas := map[PublicKey]*keyMeta{feePayer: {signer: true, writable: true}}
+	order := []PublicKey{feePayer}
+	add := func(pk PublicKey, signer, writable bool) {
+		if m, ok := metas[pk]; ok {
+			m.signer = m.signer || signer
+			m.writable = m.writable || writable
+			return
+		}
+		metas[pk] = &keyMeta{signer: signer, writable: writable}
+		order = append(order, pk)
+	}
+	for _, ins := range instructions {
+		for _, acc := range ins.Accounts {
+			add(acc.PublicKey, acc.IsSigner, acc.IsWritable)
+		}
+		add(ins.ProgramID, false, false)
+	}
+
+	var keys []PublicKey
+	var header MessageHeader
+	groups := []struct{ signer, writable bool }{{true, true}, {true, false}, {false, true}, {false, false}}
+	for _, g := range groups {
+		for _, pk := range order {
+			m := metas[pk]
+			if m.signer != g.signer || m.writable != g.writable {
+				continue
+			}
+			keys = append(keys, pk)
+			if g.signer {
+				header.NumRequiredSignatures++
+				if !g.writable {
+					header.NumReadonlySignedAccounts++
+				}
+			} else if !g.writable {
+				header.NumReadonlyUnsignedAccounts++
+			}
+		}
+	}
+	index := make(map[PublicKey]uint8, len(keys))
+	for i, pk := range keys {
+		index[pk] = uint8(i)
+	}
+
+	msg := &Message{Header: header, AccountKeys: keys, RecentBlockhash: recentBlockhash}
+	for _, ins := range instructions {
+		compiled := CompiledInstruction{ProgramIDIndex: index[ins.ProgramID], Data: ins.Data}
+		for _, acc := range ins.Accounts {
+			compiled.Accounts = append(compiled.Accounts, index[acc.PublicKey])
+		}
+		msg.Instructions = append(msg.Instructions, compiled)
+	}
+	return msg
+}
+
+func (m *Message) Serialize() []byte {
+	buf := []byte{m.Header.NumRequiredSignatures, m.Header.NumReadonlySignedAccounts, m.Header.NumReadonlyUnsignedAccounts}
+	buf = appendCompactU16(buf, len(m.AccountKeys))
+	for _, pk := range m.AccountKeys {
+		buf = append(buf, pk[:]...)
+	}
+	buf = append(buf, m.RecentBlockhash[:]...)
+	buf = appendCompactU16(buf, len(m.Instructions))
+	for _, ins := range m.Instructions {
+		buf = append(buf, ins.ProgramIDIndex)
+		buf = appendCompactU16(buf, len(ins.Accounts))
+		buf = append(buf, ins.Accounts...)
+		buf = appendCompactU16(buf, len(ins.Data))
+		buf = append(buf, ins.Data...)
+	}
+	return buf
+}
+
+// DeserializeMessage 支持 legacy 与 v0 消息, v0 的地址查找表部分不做解析
+func DeserializeMessage(data []byte) (*Message, error) {
+	r := &reader{data: data}
+	first, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	if first&messageVersionPrefix != 0 {
+		if first, err = r.byte(); err != nil {
+			return nil, err
+		}
+	}
+	msg := &Message{}
+	msg.Header.NumRequiredSignatures = first
+	if msg.Header.NumReadonlySignedAccounts, err = r.byte(); err != nil {
+		return nil, err
+	}
+	if msg.Header.NumReadonlyUnsignedAccounts, err = r.byte(); err != nil {
+		return nil, err
+	}
+	numKeys, err := r.compactU16()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < numKeys; i++ {
+		raw, err := r.bytes(PublicKeyLength)
+		if err != nil {
+			return nil, err
+		}
+		var pk PublicKey
+		copy(pk[:], raw)
+		msg.AccountKeys = append(msg.AccountKeys, pk)
+	}
+	blockhash, err := r.bytes(PublicKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	copy(msg.RecentBlockhash[:], blockhash)
+	numInstructions, err := r.compactU16()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < numInstructions; i++ {
+		var ins CompiledInstruction
+		if ins.ProgramIDIndex, err = r.byte(); err != nil {
+			return nil, err
+		}
+		numAccounts, err := r.compactU16()
+		if err != nil {
+			return nil, err
+		}
+		if ins.Accounts, err = r.bytes(numAccounts); err != nil {
+			return nil, err
+		}
+		dataLen, err := r.compactU16()
+		if err != nil {
+			return nil, err
+		}
+		if ins.Data, err = r.bytes(dataLen); err != nil {
+			return nil, err
+		}
+		msg.Instructions = append(msg.Instructions, ins)
+	}
+	return msg, nil
+}
+
+// SerializeTransaction 已签名交易: 签名数量 + 签名 + 消息
+func SerializeTransaction(signatures [][]byte, message []byte) []byte {
+	buf := appendCompactU16(nil, len(signatures))
+	for _, sig := range signatures {
+		buf = append(buf, sig...)
+	}
+	return append(buf, message...)
+}
+
+func DeserializeTransaction(data []byte) ([][]byte, []byte, error) {
+	r := &reader{data: data}
+	numSignatures, err := r.compactU16()
+	if err != nil {
+		return nil, nil, err
+	}
+	var signatures [][]byte
+	for i := 0; i < numSignatures; i++ {
+		sig, err := r.bytes(SignatureLength)
+		if err != nil {
+			return nil, nil, err
+		}
+		signatures = append(signatures, sig)
+	}
+	return signatures, r.data[r.pos:], nil
+}
+
+/**
+ * @description: 从消息中解析 SOL 或 SPL 转账
+ * SPL 转账的接收方默认为代币账户, 若同一消息中创建了该 ATA 则还原为钱包地址
+ */
+func (m *Message) DecodeTransfer() (*TxStructure, error) {
+	key := func(ins CompiledInstruction, i int) (PublicKey, error) {
+		if i >= len(ins.Accounts) || int(ins.Accounts[i]) >= len(m.AccountKeys) {
+			return PublicKey{}, errInvalidMessage
+		}
+		return m.AccountKeys[ins.Accounts[i]], nil
+	}
+	ataOwners := make(map[PublicKey]PublicKey)
+	var txStructure *TxStructure
+	var unitPrice uint64
+	var unitLimit uint32
+	for _, ins := range m.Instructions {
+		if int(ins.ProgramIDIndex) >= len(m.AccountKeys) {
+			return nil, errInvalidMessage
+		}
+		programID := m.AccountKeys[ins.ProgramIDIndex]
+		switch {
+		case programID == SystemProgramID && len(ins.Data) == 12 && binary.LittleEndian.Uint32(ins.Data) == systemInstructionTransfer:
+			from, err := key(ins, 0)
+			if err != nil {
+				return nil, err
+			}
+			to, err := key(ins, 1)
+			if err != nil {
+				return nil, err
+			}
+			txStructure = &TxStructure{
+				FromAddress: from.String(),
+				ToAddress:   to.String(),
+				Value:       strconv.FormatUint(binary.LittleEndian.Uint64(ins.Data[4:]), 10),
+			}
+		case (programID == TokenProgramID || programID == Token2022ProgramID) && len(ins.Data) == 10 && ins.Data[0] == tokenInstructionTransferChecked:
+			mint, err := key(ins, 1)
+			if err != nil {
+				return nil, err
+			}
+			destination, err := key(ins, 2)
+			if err != nil {
+				return nil, err
+			}
+			owner, err := key(ins, 3)
+			if err != nil {
+				return nil, err
+			}
+			txStructure = &TxStructure{
+				FromAddress:     owner.String(),
+				ToAddress:       destination.String(),
+				Value:           strconv.FormatUint(binary.LittleEndian.Uint64(ins.Data[1:]), 10),
+				ContractAddress: mint.String(),
+			}
+		case (programID == TokenProgramID || programID == Token2022ProgramID) && len(ins.Data) == 9 && ins.Data[0] == tokenInstructionTransfer:
+			destination, err := key(ins, 1)
+			if err != nil {
+				return nil, err
+			}
+			owner, err := key(ins, 2)
+			if err != nil {
+				return nil, err
+			}
+			txStructure = &TxStructure{
+				FromAddress: owner.String(),
+				ToAddress:   destination.String(),
+				Value:       strconv.FormatUint(binary.LittleEndian.Uint64(ins.Data[1:]), 10),
+			}
+		case programID == AssociatedTokenProgramID:
+			ata, err := key(ins, 1)
+			if err != nil {
+				return nil, err
+			}
+			owner, err := key(ins, 2)
+			if err != nil {
+				return nil, err
+			}
+			ataOwners[ata] = owner
+		case programID == ComputeBudgetProgramID && len(ins.Data) == 9 && ins.Data[0] == computeBudgetSetUnitPrice:
+			unitPrice = binary.LittleEndian.Uint64(ins.Data[1:])
+		case programID == ComputeBudgetProgramID && len(ins.Data) == 5 && ins.Data[0] == computeBudgetSetUnitLimit:
+			unitLimit = binary.LittleEndian.Uint32(ins.Data[1:])
+		}
+	}
+	if txStructure == nil {
+		return nil, errors.New("no transfer instruction found")
+	}
+	if destination, err := PublicKeyFromBase58(txStructure.ToAddress); err == nil {
+		if owner, ok := ataOwners[destination]; ok {
+			txStructure.ToAddress = owner.String()
+		}
+	}
+	txStructure.ComputeUnitPrice = unitPrice
+	txStructure.ComputeUnitLimit = unitLimit
+	txStructure.RecentBlockhash = m.RecentBlockhash.String()
+	return txStructure, nil
+}
+
+// WritableAccounts 返回消息中的可写账户, 用于查询优先费
+func (m *Message) WritableAccounts() []string {
+	var accounts []string
+	numSigned := int(m.Header.NumRequiredSignatures)
+	numUnsignedWritable := len(m.AccountKeys) - numSigned - int(m.Header.NumReadonlyUnsignedAccounts)
+	for i, pk := range m.AccountKeys {
+		if i < numSigned-int(m.Header.NumReadonlySignedAccounts) ||
+			(i >= numSigned && i < numSigned+numUnsignedWritable) {
+			accounts = append(accounts, pk.String())
+		}
+	}
+	return accounts
+}
+
+func appendCompactU16(buf []byte, n int) []byte {
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(buf, b)
+		}
+		buf = append(buf, b|0x80)
+	}
+}
+
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) byte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, errInvalidMessage
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *reader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, errInvalidMessage
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *reader) compactU16() (int, error) {
+	n := 0
+	for i := 0; i < 3; i++ {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		n |= int(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return n, nil
+		}
+	}
+	return 0, errInvalidMessage
+}
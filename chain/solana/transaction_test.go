@@ -0,0 +1,118 @@
+package solana
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// 测试账户为 sha256("from"), sha256("to"), sha256("blockhash") 的 base58 形式
+var (
+	fromKey   = MustPublicKeyFromBase58("8ukkSPFecLhdnkzp6s1h6zLSLkPzDPk1oaMuaM6Rxmjr")
+	toKey     = MustPublicKeyFromBase58("7t7yfuynrNBBRtHpwC9Vn2QwAcMxuUE3Y6nt27LP413E")
+	blockhash = MustPublicKeyFromBase58("4ruaGCyaofHWGxPFXFVjuEJCdfBGZ2wCtEx6LzdzVqtV")
+	usdcMint  = MustPublicKeyFromBase58("EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v")
+	fromATA   = MustPublicKeyFromBase58("4YEYdfKmhKWGmy9gD5JvQUzuAjTkgKmoB58VQgmZW5ff")
+	toATA     = MustPublicKeyFromBase58("Es1Jv5XzrLwb5xpyoZ8yYCG42yk2mcahzKaF2GoGPsFj")
+)
+
+const (
+	// 1.5 SOL: header 1/0/1, 账户 [from, to, system]
+	solTransferMessage = "0100010375857a45899985be4c4d941e90b6b396d6c92a4c7437aaf0bf102089fe21379d663ea1bfffe5038f3f0cf667f14c4257eff52d77ce7f2a218f72e9286616ea390000000000000000000000000000000000000000000000000000000000000000395bf727f9aac5e80911591073fcf9c826f428804131ca089beba3869421749a01020200010c02000000002f685900000000"
+	// 2.5 USDC, 优先费 5000 micro-lamports, 同时创建接收方 ATA: header 1/0/6
+	splTransferMessage = "0100060975857a45899985be4c4d941e90b6b396d6c92a4c7437aaf0bf102089fe21379dcdf751db235a6626d258b43c98da724650122e73eae24d462579620cef5c9abe349361b53412525a3305fb3b29b30dad3e9a26a6e738f50362ae81428451049a0306466fe5211732ffecadba72c39be7bc8ce5bbc5f7126b2c439b3a40000000663ea1bfffe5038f3f0cf667f14c4257eff52d77ce7f2a218f72e9286616ea39c6fa7af3bedbad3a3d65f36aabc97431b1bbe4c2d2f6e0e47ca60203452f5d61000000000000000000000000000000000000000000000000000000000000000006ddf6e1d765a193d9cbe146ceeb79ac1cb485ed5f5b37913a8cf5857eff00a98c97258f4e2489f1bb3d1029148e0d830b5a1399daff1084048e7bd8dbe9f859395bf727f9aac5e80911591073fcf9c826f428804131ca089beba3869421749a03030009038813000000000000080600010405060701010704020501000a0ca02526000000000006"
+)
+
+func TestMessageSerialize(t *testing.T) {
+	tests := []struct {
+		name         string
+		instructions []Instruction
+		want         string
+		wantTransfer TxStructure
+	}{
+		{
+			name:         "sol transfer",
+			instructions: []Instruction{SystemTransfer(fromKey, toKey, 1_500_000_000)},
+			want:         solTransferMessage,
+			wantTransfer: TxStructure{FromAddress: fromKey.String(), ToAddress: toKey.String(), Value: "1500000000"},
+		},
+		{
+			name: "spl transfer with ata creation",
+			instructions: []Instruction{
+				SetComputeUnitPrice(5000),
+				CreateAssociatedTokenAccountIdempotent(fromKey, toATA, toKey, usdcMint, TokenProgramID),
+				TokenTransferChecked(fromATA, usdcMint, toATA, fromKey, 2_500_000, 6, TokenProgramID),
+			},
+			want: splTransferMessage,
+			// 接收方还原为钱包地址
+			wantTransfer: TxStructure{FromAddress: fromKey.String(), ToAddress: toKey.String(), Value: "2500000",
+				ContractAddress: usdcMint.String(), ComputeUnitPrice: 5000},
+		},
+	}
+	for _, tt := range tests {
+		msg := NewMessage(fromKey, tt.instructions, blockhash)
+		if got := hex.EncodeToString(msg.Serialize()); got != tt.want {
+			t.Errorf("%s: message = %s, want %s", tt.name, got, tt.want)
+		}
+		raw, _ := hex.DecodeString(tt.want)
+		decoded, err := DeserializeMessage(raw)
+		if err != nil {
+			t.Errorf("%s: deserialize: %v", tt.name, err)
+			continue
+		}
+		transfer, err := decoded.DecodeTransfer()
+		if err != nil {
+			t.Errorf("%s: decode transfer: %v", tt.name, err)
+			continue
+		}
+		tt.wantTransfer.RecentBlockhash = blockhash.String()
+		if *transfer != tt.wantTransfer {
+			t.Errorf("%s: transfer = %+v, want %+v", tt.name, *transfer, tt.wantTransfer)
+		}
+	}
+}
+
+func TestWritableAccounts(t *testing.T) {
+	raw, _ := hex.DecodeString(splTransferMessage)
+	msg, err := DeserializeMessage(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := msg.WritableAccounts()
+	want := []string{fromKey.String(), toATA.String(), fromATA.String()}
+	if len(got) != len(want) {
+		t.Fatalf("writable = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("writable = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestTransactionSerialize(t *testing.T) {
+	message, _ := hex.DecodeString(solTransferMessage)
+	sig := make([]byte, SignatureLength)
+	sig[0] = 0xaa
+	encoded := SerializeTransaction([][]byte{sig}, message)
+	if want := "01" + hex.EncodeToString(sig) + solTransferMessage; hex.EncodeToString(encoded) != want {
+		t.Fatalf("transaction = %x, want %s", encoded, want)
+	}
+	sigs, gotMessage, err := DeserializeTransaction(encoded)
+	if err != nil || len(sigs) != 1 || sigs[0][0] != 0xaa || hex.EncodeToString(gotMessage) != solTransferMessage {
+		t.Errorf("deserialize = %x, %x, %v", sigs, gotMessage, err)
+	}
+	// 签名被截断
+	if _, _, err := DeserializeTransaction(encoded[:40]); err == nil {
+		t.Error("truncated transaction decoded")
+	}
+}
+
+func TestDeserializeMessageTruncated(t *testing.T) {
+	raw, _ := hex.DecodeString(solTransferMessage)
+	for _, n := range []int{0, 3, 4 + 32, len(raw) - 1} {
+		if _, err := DeserializeMessage(raw[:n]); err == nil {
+			t.Errorf("message truncated to %d bytes decoded", n)
+		}
+	}
+}
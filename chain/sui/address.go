@@ -0,0 +1,86 @@
+package sui
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	AddressLength = 32
+
+	// 签名方案标识, 地址为 blake2b-256(flag || 公钥)
+	FlagEd25519   = 0x00
+	FlagSecp256k1 = 0x01
+	FlagSecp256r1 = 0x02
+)
+
+var errInvalidAddress = errors.New("invalid sui address")
+
+type Address [AddressLength]byte
+
+// AddressFromPublicKey ed25519 为 32 字节公钥, secp256k1/secp256r1 为 33 字节压缩公钥
+func AddressFromPublicKey(flag byte, publicKey []byte) Address {
+	return blake2b.Sum256(append([]byte{flag}, publicKey...))
+}
+
+// PublicKeyFlag 按公钥长度推断签名方案, 65 字节非压缩 secp256k1 公钥会被压缩
+func PublicKeyFlag(publicKey []byte) (byte, []byte, error) {
+	switch len(publicKey) {
+	case ed25519.PublicKeySize:
+		return FlagEd25519, publicKey, nil
+	case 33:
+		if _, err := crypto.DecompressPubkey(publicKey); err != nil {
+			return 0, nil, err
+		}
+		return FlagSecp256k1, publicKey, nil
+	case 65:
+		pub, err := crypto.UnmarshalPubkey(publicKey)
+		if err != nil {
+			return 0, nil, err
+		}
+		return FlagSecp256k1, crypto.CompressPubkey(pub), nil
+	default:
+		return 0, nil, errors.New("unsupported public key length")
+	}
+}
+
+// ParseAddress 支持省略前导 0 的短格式
+func ParseAddress(address string) (Address, error) {
+	var addr Address
+	if !strings.HasPrefix(strings.ToLower(address), "0x") {
+		return addr, errInvalidAddress
+	}
+	s := address[2:]
+	if len(s) == 0 || len(s) > AddressLength*2 {
+		return addr, errInvalidAddress
+	}
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return addr, errInvalidAddress
+	}
+	copy(addr[AddressLength-len(raw):], raw)
+	return addr, nil
+}
+
+// IsSystem 0x1 (std), 0x2 (sui framework), 0x5 (system state) 等系统对象地址
+func (a Address) IsSystem() bool {
+	for _, b := range a[:AddressLength-2] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String 完整的 64 位 hex 格式
+func (a Address) String() string {
+	return "0x" + hex.EncodeToString(a[:])
+}
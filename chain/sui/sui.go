@@ -1 +1,943 @@
 package sui
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/common/base58"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	ChainName = "Sui"
+
+	// 模拟执行时的 gas 上限, 节点在 payment 为空时使用虚拟 gas coin
+	maxDryRunGasBudget = 50_000_000_000
+	// gas 预算的安全余量, 按计算单位计, 与官方 SDK 一致
+	gasSafeOverheadUnits = 1000
+	// 未提供交易时按最小计算单位与新建一个 Coin 对象的存储费估算
+	defaultComputationUnits = 1000
+	defaultStorageCost      = 1976000
+
+	coinPageLimit    = 50
+	maxCoinPages     = 10
+	maxSelectedCoins = 256
+
+	defaultTxPageSize = 20
+	multiGetLimit     = 50
+)
+
+// 慢/普通/快三档 gas 价格相对参考价格的百分比
+var gasPriceMultipliers = [3]uint64{100, 125, 150}
+
+type ChainAdaptor struct {
+	suiClient SuiClient
+}
+
+func init() {
+	chain.Register(ChainName, NewChainAdaptor)
+}
+
+func NewChainAdaptor(conf *config.Config) (chain.IChainAdaptor, error) {
+	node, ok := conf.Chain(ChainName)
+	if !ok {
+		return nil, errors.New("sui rpc not configured")
+	}
+	suiClient, err := DialSuiClient(context.Background(), node.RPCs[0].RPCURL)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		suiClient: suiClient,
+	}, nil
+}
+
+// Close 热加载替换后关闭 RPC 连接
+func (c *ChainAdaptor) Close() {
+	c.suiClient.Close()
+}
+
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// ConvertAddress 32 字节公钥按 ed25519 处理, 33/65 字节公钥按 secp256k1 处理
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	flag, publicKey, err := PublicKeyFlag(req.PublicKey)
+	if err != nil {
+		return &account.ConvertAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: AddressFromPublicKey(flag, publicKey).String(),
+	}, nil
+}
+
+// ValidAddress 0x1/0x2/0x5 等系统地址不能作为提现地址
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	address, err := ParseAddress(req.Address)
+	if err != nil {
+		return &account.ValidAddressResponse{
+			Code:  common.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:             common.ReturnCode_SUCCESS,
+		Msg:              "valid address",
+		Valid:            true,
+		CanWithdrawal:    !address.IsSystem(),
+		CanonicalAddress: address.String(),
+	}, nil
+}
+
+// GetBlockByNumber 区块对应 Sui 的 checkpoint, height 为 0 时返回最新 checkpoint
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	checkpoint, err := c.getCheckpoint(req.Height)
+	if err != nil {
+		log.Error("get checkpoint fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return c.toBlockResponse(checkpoint, req.ViewTx)
+}
+
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	checkpoint, err := c.suiClient.GetCheckpoint(req.Hash)
+	if err != nil {
+		log.Error("get checkpoint fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by hash fail",
+		}, nil
+	}
+	return c.toBlockResponse(checkpoint, req.ViewTx)
+}
+
+func (c *ChainAdaptor) toBlockResponse(checkpoint *Checkpoint, viewTx bool) (*account.BlockResponse, error) {
+	height, _ := strconv.ParseInt(checkpoint.SequenceNumber, 10, 64)
+	var txList []*account.BlockInfoTransactionList
+	if viewTx {
+		txs, err := c.getTransactionBlocks(checkpoint.Transactions)
+		if err != nil {
+			log.Error("get checkpoint transactions fail", "err", err)
+			return &account.BlockResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get checkpoint transactions fail",
+			}, nil
+		}
+		for i := range txs {
+			txMessage := toTxMessage(&txs[i])
+			item := &account.BlockInfoTransactionList{
+				Hash:   txMessage.Hash,
+				Time:   txMessage.Datetime,
+				Fee:    txMessage.Fee,
+				Status: txMessage.Status.String(),
+			}
+			if len(txMessage.Froms) > 0 {
+				item.From = txMessage.Froms[0].Address
+			}
+			if len(txMessage.Tos) > 0 {
+				item.To = txMessage.Tos[0].Address
+				item.Amount = txMessage.Values[0].Value
+			}
+			txList = append(txList, item)
+		}
+	} else {
+		for _, digest := range checkpoint.Transactions {
+			txList = append(txList, &account.BlockInfoTransactionList{Hash: digest})
+		}
+	}
+	return &account.BlockResponse{
+		Code:         common.ReturnCode_SUCCESS,
+		Msg:          "get block success",
+		Height:       height,
+		Hash:         checkpoint.Digest,
+		Transactions: txList,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	checkpoint, err := c.suiClient.GetCheckpoint(req.Hash)
+	if err != nil {
+		log.Error("get checkpoint fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return toBlockHeaderResponse(checkpoint), nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	checkpoint, err := c.getCheckpoint(req.Height)
+	if err != nil {
+		log.Error("get checkpoint fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return toBlockHeaderResponse(checkpoint), nil
+}
+
+func toBlockHeaderResponse(checkpoint *Checkpoint) *account.BlockHeaderResponse {
+	timestampMs, _ := strconv.ParseUint(checkpoint.TimestampMs, 10, 64)
+	return &account.BlockHeaderResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get block header success",
+		BlockHeader: &account.BlockHeader{
+			ParentHash: checkpoint.PreviousDigest,
+			Number:     checkpoint.SequenceNumber,
+			Time:       timestampMs / 1000,
+		},
+	}
+}
+
+func (c *ChainAdaptor) getCheckpoint(height int64) (*Checkpoint, error) {
+	sequence := uint64(height)
+	if height == 0 {
+		latest, err := c.suiClient.GetLatestCheckpointSequenceNumber()
+		if err != nil {
+			return nil, err
+		}
+		sequence = latest
+	}
+	return c.suiClient.GetCheckpoint(strconv.FormatUint(sequence, 10))
+}
+
+func (c *ChainAdaptor) getTransactionBlocks(digests []string) ([]TransactionBlock, error) {
+	var txs []TransactionBlock
+	for start := 0; start < len(digests); start += multiGetLimit {
+		end := start + multiGetLimit
+		if end > len(digests) {
+			end = len(digests)
+		}
+		batch, err := c.suiClient.MultiGetTransactionBlocks(digests[start:end])
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, batch...)
+	}
+	return txs, nil
+}
+
+/**
+ * @description: 查询余额, 合约地址为 Coin 类型, 为空时查询 SUI
+ * Sui 基于对象模型, 没有账户序号, Sequence 与 AccountNumber 固定返回 0
+ */
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	coinType := req.ContractAddress
+	if coinType == "" {
+		coinType = SuiCoinType
+	}
+	balance, err := c.suiClient.GetBalance(req.Address, coinType)
+	if err != nil {
+		log.Error("get balance by address fail", "err", err)
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "get balance by address fail",
+			Balance: "0",
+		}, nil
+	}
+	return &account.AccountResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Network:       ChainName,
+		Balance:       balance.TotalBalance,
+	}, nil
+}
+
+/**
+ * @description: 估算手续费, 单位为 MIST
+ * rawTx 为 base64(json) 的 TxStructure 时通过 sui_dryRunTransactionBlock 得到计算与存储费用, 否则按经验值估算
+ * 三档手续费的 gas 价格分别为参考价格的 100%/125%/150%, 存储费用不随 gas 价格变化
+ */
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	referencePrice, err := c.suiClient.GetReferenceGasPrice()
+	if err != nil {
+		log.Error("get reference gas price fail", "err", err)
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get reference gas price fail",
+		}, nil
+	}
+	computationUnits, storageCost, storageRebate := uint64(defaultComputationUnits), uint64(defaultStorageCost), uint64(0)
+	if req.RawTx != "" {
+		txStructure, err := decodeTxStructure(req.RawTx)
+		if err != nil {
+			log.Error("decode tx structure fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "decode tx structure fail",
+			}, nil
+		}
+		tx, _, err := c.draftTransaction(txStructure)
+		if err != nil {
+			log.Error("build transaction fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "build transaction fail",
+			}, nil
+		}
+		tx.GasPrice = referencePrice
+		gasUsed, err := c.dryRun(tx)
+		if err != nil {
+			log.Error("dry run transaction fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "dry run transaction fail",
+			}, nil
+		}
+		computationUnits = gasUsed.computation / referencePrice
+		storageCost, storageRebate = gasUsed.storage, gasUsed.rebate
+	}
+	var fees [3]string
+	for i, multiplier := range gasPriceMultipliers {
+		fee := new(big.Int).SetUint64(computationUnits * (referencePrice * multiplier / 100))
+		fee.Add(fee, new(big.Int).SetUint64(storageCost))
+		fee.Sub(fee, new(big.Int).SetUint64(storageRebate))
+		if fee.Sign() < 0 {
+			fee.SetUint64(0)
+		}
+		fees[i] = fee.String()
+	}
+	return &account.FeeResponse{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fees[0],
+		NormalFee: fees[1],
+		FastFee:   fees[2],
+	}, nil
+}
+
+type gasCost struct {
+	computation uint64
+	storage     uint64
+	rebate      uint64
+}
+
+// budget 与官方 SDK 一致: max(计算费用 + 余量, 计算费用 + 余量 + 存储费用 - 存储返还)
+func (g gasCost) budget(gasPrice uint64) uint64 {
+	base := g.computation + gasSafeOverheadUnits*gasPrice
+	if g.storage > g.rebate {
+		return base + g.storage - g.rebate
+	}
+	return base
+}
+
+// dryRun 以空 gas payment 与最大预算模拟执行, 返回 gas 消耗
+func (c *ChainAdaptor) dryRun(tx *TransactionData) (gasCost, error) {
+	draft := *tx
+	draft.GasPayment = nil
+	draft.GasBudget = maxDryRunGasBudget
+	result, err := c.suiClient.DryRunTransactionBlock(base64.StdEncoding.EncodeToString(draft.Serialize()))
+	if err != nil {
+		return gasCost{}, err
+	}
+	if result.Effects.Status.Status != ExecutionStatusSuccess {
+		return gasCost{}, fmt.Errorf("dry run fail: %s", result.Effects.Status.Error)
+	}
+	var cost gasCost
+	summary := result.Effects.GasUsed
+	for _, item := range []struct {
+		value string
+		dest  *uint64
+	}{
+		{summary.ComputationCost, &cost.computation},
+		{summary.StorageCost, &cost.storage},
+		{summary.StorageRebate, &cost.rebate},
+	} {
+		if *item.dest, err = strconv.ParseUint(item.value, 10, 64); err != nil {
+			return gasCost{}, err
+		}
+	}
+	return cost, nil
+}
+
+// draftTransaction 选择并合并代币 Coin 对象, 构建未设置 gas 的转账交易, 返回交易与转账金额
+func (c *ChainAdaptor) draftTransaction(txStructure *TxStructure) (*TransactionData, uint64, error) {
+	sender, err := ParseAddress(txStructure.FromAddress)
+	if err != nil {
+		return nil, 0, err
+	}
+	to, err := ParseAddress(txStructure.ToAddress)
+	if err != nil {
+		return nil, 0, err
+	}
+	amount, err := strconv.ParseUint(txStructure.Value, 10, 64)
+	if err != nil || amount == 0 {
+		return nil, 0, errors.New("invalid transfer value")
+	}
+	var coins []ObjectRef
+	if !IsSuiCoin(txStructure.ContractAddress) {
+		if coins, err = c.selectCoins(sender.String(), txStructure.ContractAddress, amount); err != nil {
+			return nil, 0, err
+		}
+	}
+	return NewTransferTransaction(sender, to, amount, coins), amount, nil
+}
+
+/**
+ * @description: 选择 Coin 对象覆盖目标金额
+ * 按余额从大到小选择, 以减少需要合并的对象数量; 对象数量不超过 gas payment 上限 256
+ */
+func (c *ChainAdaptor) selectCoins(owner, coinType string, amount uint64) ([]ObjectRef, error) {
+	var coins []Coin
+	cursor := ""
+	for page := 0; page < maxCoinPages; page++ {
+		result, err := c.suiClient.GetCoins(owner, coinType, cursor, coinPageLimit)
+		if err != nil {
+			return nil, err
+		}
+		coins = append(coins, result.Data...)
+		if !result.HasNextPage || result.NextCursor == nil {
+			break
+		}
+		cursor = *result.NextCursor
+	}
+	balances := make([]uint64, len(coins))
+	for i := range coins {
+		balance, err := strconv.ParseUint(coins[i].Balance, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		balances[i] = balance
+	}
+	index := make([]int, len(coins))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return balances[index[i]] > balances[index[j]]
+	})
+	var selected []ObjectRef
+	var total uint64
+	for _, i := range index {
+		if total >= amount || len(selected) >= maxSelectedCoins {
+			break
+		}
+		ref, err := coinObjectRef(&coins[i])
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, ref)
+		total += balances[i]
+	}
+	if total < amount {
+		return nil, fmt.Errorf("insufficient %s balance: need %d, selected %d", coinType, amount, total)
+	}
+	return selected, nil
+}
+
+func coinObjectRef(coin *Coin) (ObjectRef, error) {
+	var ref ObjectRef
+	id, err := ParseAddress(coin.CoinObjectId)
+	if err != nil {
+		return ref, err
+	}
+	version, err := strconv.ParseUint(coin.Version, 10, 64)
+	if err != nil {
+		return ref, err
+	}
+	digest, err := base58.Decode(coin.Digest)
+	if err != nil || len(digest) != digestLength {
+		return ref, fmt.Errorf("invalid object digest %s", coin.Digest)
+	}
+	return ObjectRef{ObjectId: id, Version: version, Digest: digest}, nil
+}
+
+// SendTx raw_tx 为 BuildSignedTransaction 返回的 base64 编码 bcs(SenderSignedData)
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	signedTx, err := base64.StdEncoding.DecodeString(req.RawTx)
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	tx, signature, err := DeserializeSignedTransaction(signedTx)
+	if err != nil {
+		log.Error("deserialize signed transaction fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "deserialize signed transaction fail",
+		}, nil
+	}
+	result, err := c.suiClient.ExecuteTransactionBlock(
+		base64.StdEncoding.EncodeToString(tx.Serialize()),
+		[]string{base64.StdEncoding.EncodeToString(signature)},
+	)
+	if err != nil {
+		log.Error("execute transaction fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "execute transaction fail",
+		}, nil
+	}
+	if result.Effects != nil && result.Effects.Status.Status != ExecutionStatusSuccess {
+		log.Warn("transaction executed with failure", "digest", result.Digest, "err", result.Effects.Status.Error)
+	}
+	return &account.SendTxResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: result.Digest,
+	}, nil
+}
+
+// GetTxByAddress 合并地址作为发送方与接收方的交易, Cursor 为上一页最后一笔交易的 digest
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	pageSize := req.Pagesize
+	if pageSize == 0 {
+		pageSize = defaultTxPageSize
+	}
+	seen := make(map[string]bool)
+	var txs []TransactionBlock
+	for _, filter := range []string{"FromAddress", "ToAddress"} {
+		page, err := c.suiClient.QueryTransactionBlocks(map[string]any{filter: req.Address}, req.Cursor, pageSize, true)
+		if err != nil {
+			log.Error("query transaction blocks fail", "filter", filter, "err", err)
+			return &account.TxAddressResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get tx by address fail",
+			}, nil
+		}
+		for _, tx := range page.Data {
+			if !seen[tx.Digest] {
+				seen[tx.Digest] = true
+				txs = append(txs, tx)
+			}
+		}
+	}
+	sort.SliceStable(txs, func(i, j int) bool {
+		ci, _ := strconv.ParseUint(txs[i].Checkpoint, 10, 64)
+		cj, _ := strconv.ParseUint(txs[j].Checkpoint, 10, 64)
+		return ci > cj
+	})
+	var txList []*account.TxMessage
+	for i := range txs {
+		txMessage := toTxMessage(&txs[i])
+		if req.ContractAddress != "" && txMessage.ContractAddress != req.ContractAddress {
+			continue
+		}
+		txList = append(txList, txMessage)
+	}
+	return &account.TxAddressResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txList,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	tx, err := c.suiClient.GetTransactionBlock(req.Hash)
+	if errors.Is(err, ErrNotFound) {
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_SUCCESS,
+			Msg:  "tx not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get transaction block fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}, nil
+	}
+	return &account.TxHashResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by hash success",
+		Tx:   toTxMessage(tx),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, errStart := strconv.ParseUint(req.Start, 10, 64)
+	end, errEnd := strconv.ParseUint(req.End, 10, 64)
+	if errStart != nil || errEnd != nil || start > end {
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid block range",
+		}, nil
+	}
+	var blockList []*account.BlockData
+	for sequence := start; sequence <= end; sequence++ {
+		checkpoint, err := c.suiClient.GetCheckpoint(strconv.FormatUint(sequence, 10))
+		if err != nil {
+			log.Error("get checkpoint fail", "sequence", sequence, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block by range fail",
+			}, nil
+		}
+		txs, err := c.getTransactionBlocks(checkpoint.Transactions)
+		if err != nil {
+			log.Error("get checkpoint transactions fail", "sequence", sequence, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block by range fail",
+			}, nil
+		}
+		var txList []*account.TxMessage
+		for i := range txs {
+			txList = append(txList, toTxMessage(&txs[i]))
+		}
+		blockList = append(blockList, &account.BlockData{
+			Hash:         checkpoint.Digest,
+			Transactions: txList,
+		})
+	}
+	return &account.BlockByRangeResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "get block by range success",
+		Blocks: blockList,
+	}, nil
+}
+
+/**
+ * @description: 创建未签名交易
+ * base64_tx 为 base64(json) 的 TxStructure, public_key 必填且须与 from_address 对应
+ * 代币转账会合并所选 Coin 对象后拆分出转账金额, gas 预算由 dry run 结果计算, gas coin 按预算 (转 SUI 时加上金额) 选择
+ * 返回 base64(json) 的 UnsignedTx, 签名对象为 signing_message (hex)
+ */
+func (c *ChainAdaptor) CreateUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txStructure, err := decodeTxStructure(req.Base64Tx)
+	if err != nil {
+		log.Error("decode tx structure fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode tx structure fail",
+		}, nil
+	}
+	publicKey, err := hex.DecodeString(strings.TrimPrefix(txStructure.PublicKey, "0x"))
+	if err != nil {
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	flag, publicKey, err := PublicKeyFlag(publicKey)
+	if err != nil {
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	tx, err := c.buildTransaction(txStructure)
+	if err != nil {
+		log.Error("build transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "build transaction fail",
+		}, nil
+	}
+	if AddressFromPublicKey(flag, publicKey) != tx.Sender {
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "public key does not match from address",
+		}, nil
+	}
+	unsignedTx, _ := json.Marshal(&UnsignedTx{
+		TxBytes:        base64.StdEncoding.EncodeToString(tx.Serialize()),
+		PublicKey:      hex.EncodeToString(publicKey),
+		SigningMessage: hex.EncodeToString(tx.SigningMessage()),
+	})
+	return &account.UnSignTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "create un sign transaction success",
+		UnSignTx: base64.StdEncoding.EncodeToString(unsignedTx),
+	}, nil
+}
+
+func (c *ChainAdaptor) buildTransaction(txStructure *TxStructure) (*TransactionData, error) {
+	tx, amount, err := c.draftTransaction(txStructure)
+	if err != nil {
+		return nil, err
+	}
+	tx.GasPrice = txStructure.GasPrice
+	if tx.GasPrice == 0 {
+		if tx.GasPrice, err = c.suiClient.GetReferenceGasPrice(); err != nil {
+			return nil, err
+		}
+	}
+	tx.GasBudget = txStructure.GasBudget
+	if tx.GasBudget == 0 {
+		gasUsed, err := c.dryRun(tx)
+		if err != nil {
+			return nil, err
+		}
+		tx.GasBudget = gasUsed.budget(tx.GasPrice)
+	}
+	gasAmount := tx.GasBudget
+	if IsSuiCoin(txStructure.ContractAddress) {
+		gasAmount += amount
+	}
+	if tx.GasPayment, err = c.selectCoins(tx.Sender.String(), SuiCoinType, gasAmount); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+/**
+ * @description: 组装签名交易
+ * base64_tx 为 CreateUnSignTransaction 返回值, signature 为对 signing_message 的 64 字节签名 (hex)
+ * secp256k1 签名为对 sha256(signing_message) 的 r||s, s 须为低位值
+ * 返回 base64 编码的 bcs(SenderSignedData), 可直接用于 SendTx
+ */
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	unsignedJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var unsignedTx UnsignedTx
+	if err := json.Unmarshal(unsignedJson, &unsignedTx); err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "unmarshal unsigned tx fail",
+		}, nil
+	}
+	txBytes, err := base64.StdEncoding.DecodeString(unsignedTx.TxBytes)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode tx bytes fail",
+		}, nil
+	}
+	tx, err := DeserializeTransactionData(txBytes)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "deserialize tx bytes fail",
+		}, nil
+	}
+	publicKey, err := hex.DecodeString(unsignedTx.PublicKey)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	flag, publicKey, err := PublicKeyFlag(publicKey)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != ed25519SignatureLength {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	message := tx.SigningMessage()
+	var verified bool
+	if flag == FlagEd25519 {
+		verified = ed25519.Verify(publicKey, message, signature)
+	} else {
+		hash := sha256.Sum256(message)
+		verified = crypto.VerifySignature(publicKey, hash[:], signature)
+	}
+	if !verified {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "verify signature fail",
+		}, nil
+	}
+	return &account.SignedTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "build signed transaction success",
+		SignedTx: base64.StdEncoding.EncodeToString(tx.SignedTransaction(SerializedSignature(flag, signature, publicKey))),
+	}, nil
+}
+
+// DecodeTransaction raw_tx 为 base64 编码的 bcs(SenderSignedData), 代币转账通过 Coin 对象类型解析 contract_address
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	signedTx, err := base64.StdEncoding.DecodeString(req.RawTx)
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	tx, signature, err := DeserializeSignedTransaction(signedTx)
+	if err != nil {
+		log.Error("deserialize signed transaction fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "deserialize signed transaction fail",
+		}, nil
+	}
+	txStructure, err := tx.DecodeTransfer()
+	if err != nil {
+		log.Error("decode transfer fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode transfer fail",
+		}, nil
+	}
+	if len(signature) > 1+ed25519SignatureLength {
+		txStructure.PublicKey = hex.EncodeToString(signature[1+ed25519SignatureLength:])
+	}
+	if len(txStructure.CoinObjects) > 0 {
+		object, err := c.suiClient.GetObject(txStructure.CoinObjects[0])
+		if err != nil {
+			log.Error("get coin object fail", "err", err)
+			return &account.DecodeTransactionResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get coin object fail",
+			}, nil
+		}
+		txStructure.ContractAddress = coinTypeOf(object.Type)
+	}
+	txJson, _ := json.Marshal(txStructure)
+	return &account.DecodeTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "decode transaction success",
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "sui does not support verify signed transaction",
+	}, nil
+}
+
+// GetExtraData 返回离线构建交易所需的 chain_identifier 与 reference_gas_price (json)
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	chainId, err := c.suiClient.GetChainIdentifier()
+	if err != nil {
+		log.Error("get chain identifier fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get chain identifier fail",
+		}, nil
+	}
+	referencePrice, err := c.suiClient.GetReferenceGasPrice()
+	if err != nil {
+		log.Error("get reference gas price fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get reference gas price fail",
+		}, nil
+	}
+	value, _ := json.Marshal(map[string]string{
+		"chain_identifier":    chainId,
+		"reference_gas_price": strconv.FormatUint(referencePrice, 10),
+	})
+	return &account.ExtraDataResponse{
+		Code:  common.ReturnCode_SUCCESS,
+		Msg:   "get extra data success",
+		Value: string(value),
+	}, nil
+}
+
+func decodeTxStructure(base64Tx string) (*TxStructure, error) {
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var txStructure TxStructure
+	if err := json.Unmarshal(txJson, &txStructure); err != nil {
+		return nil, err
+	}
+	return &txStructure, nil
+}
+
+// coinTypeOf 从 0x2::coin::Coin<T> 中取出 T
+func coinTypeOf(objectType string) string {
+	start, end := strings.Index(objectType, "<"), strings.LastIndex(objectType, ">")
+	if start < 0 || end <= start {
+		return objectType
+	}
+	return objectType[start+1 : end]
+}
+
+/**
+ * @description: 由余额变化还原转账
+ * 优先取非 SUI 的代币变化, 余额减少的地址为 froms, 余额增加的地址为 tos, 发送方的 SUI 变化包含手续费
+ */
+func toTxMessage(tx *TransactionBlock) *account.TxMessage {
+	txMessage := &account.TxMessage{
+		Hash:   tx.Digest,
+		Height: tx.Checkpoint,
+		Status: account.TxStatus_Pending,
+	}
+	if timestampMs, err := strconv.ParseUint(tx.TimestampMs, 10, 64); err == nil {
+		txMessage.Datetime = strconv.FormatUint(timestampMs/1000, 10)
+	}
+	if tx.Effects != nil {
+		gasUsed := tx.Effects.GasUsed
+		fee, ok := new(big.Int).SetString(gasUsed.ComputationCost, 10)
+		storage, ok2 := new(big.Int).SetString(gasUsed.StorageCost, 10)
+		rebate, ok3 := new(big.Int).SetString(gasUsed.StorageRebate, 10)
+		if ok && ok2 && ok3 {
+			txMessage.Fee = fee.Add(fee, storage).Sub(fee, rebate).String()
+		}
+		if tx.Effects.Status.Status != ExecutionStatusSuccess {
+			txMessage.Status = account.TxStatus_Failed
+		} else if tx.Checkpoint != "" {
+			txMessage.Status = account.TxStatus_Success
+		}
+	}
+	coinType := SuiCoinType
+	for _, change := range tx.BalanceChanges {
+		if !IsSuiCoin(change.CoinType) {
+			coinType = change.CoinType
+			break
+		}
+	}
+	for i := range tx.BalanceChanges {
+		change := &tx.BalanceChanges[i]
+		owner := change.AddressOwner()
+		if owner == "" || (change.CoinType != coinType && !(IsSuiCoin(coinType) && IsSuiCoin(change.CoinType))) {
+			continue
+		}
+		if strings.HasPrefix(change.Amount, "-") {
+			txMessage.Froms = append(txMessage.Froms, &account.Address{Address: owner})
+		} else {
+			txMessage.Tos = append(txMessage.Tos, &account.Address{Address: owner})
+			txMessage.Values = append(txMessage.Values, &account.Value{Value: change.Amount})
+		}
+	}
+	if !IsSuiCoin(coinType) {
+		txMessage.ContractAddress = coinType
+	}
+	return txMessage
+}
@@ -0,0 +1,314 @@
+package sui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	retry2 "github.com/CavnHan/wallet-chain-account/common/retry"
+)
+
+const (
+	defaultDialTimeout    = 5 * time.Second
+	defaultDialAttempts   = 5
+	defaultRequestTimeout = 10 * time.Second
+
+	executeRequestType = "WaitForLocalExecution"
+
+	ExecutionStatusSuccess = "success"
+)
+
+var ErrNotFound = errors.New("sui transaction not found")
+
+type Balance struct {
+	CoinType        string `json:"coinType"`
+	CoinObjectCount uint64 `json:"coinObjectCount"`
+	TotalBalance    string `json:"totalBalance"`
+}
+
+type Coin struct {
+	CoinType     string `json:"coinType"`
+	CoinObjectId string `json:"coinObjectId"`
+	Version      string `json:"version"`
+	Digest       string `json:"digest"`
+	Balance      string `json:"balance"`
+}
+
+type CoinPage struct {
+	Data        []Coin  `json:"data"`
+	NextCursor  *string `json:"nextCursor"`
+	HasNextPage bool    `json:"hasNextPage"`
+}
+
+type ObjectData struct {
+	ObjectId string `json:"objectId"`
+	Version  string `json:"version"`
+	Digest   string `json:"digest"`
+	Type     string `json:"type"`
+}
+
+type GasCostSummary struct {
+	ComputationCost         string `json:"computationCost"`
+	StorageCost             string `json:"storageCost"`
+	StorageRebate           string `json:"storageRebate"`
+	NonRefundableStorageFee string `json:"nonRefundableStorageFee"`
+}
+
+type TransactionEffects struct {
+	Status struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	} `json:"status"`
+	GasUsed GasCostSummary `json:"gasUsed"`
+}
+
+// BalanceChange owner 可能为 {"AddressOwner": "0x.."}, {"ObjectOwner": ..}, {"Shared": ..} 或 "Immutable"
+type BalanceChange struct {
+	Owner    json.RawMessage `json:"owner"`
+	CoinType string          `json:"coinType"`
+	Amount   string          `json:"amount"`
+}
+
+// AddressOwner 非地址所有时返回空字符串
+func (b *BalanceChange) AddressOwner() string {
+	var owner struct {
+		AddressOwner string `json:"AddressOwner"`
+	}
+	if err := json.Unmarshal(b.Owner, &owner); err != nil {
+		return ""
+	}
+	return owner.AddressOwner
+}
+
+type TransactionBlock struct {
+	Digest      string `json:"digest"`
+	Transaction *struct {
+		Data struct {
+			Sender  string `json:"sender"`
+			GasData struct {
+				Owner  string `json:"owner"`
+				Price  string `json:"price"`
+				Budget string `json:"budget"`
+			} `json:"gasData"`
+		} `json:"data"`
+	} `json:"transaction"`
+	Effects        *TransactionEffects `json:"effects"`
+	BalanceChanges []BalanceChange     `json:"balanceChanges"`
+	TimestampMs    string              `json:"timestampMs"`
+	Checkpoint     string              `json:"checkpoint"`
+}
+
+type TransactionBlockPage struct {
+	Data        []TransactionBlock `json:"data"`
+	NextCursor  *string            `json:"nextCursor"`
+	HasNextPage bool               `json:"hasNextPage"`
+}
+
+type DryRunResult struct {
+	Effects        TransactionEffects `json:"effects"`
+	BalanceChanges []BalanceChange    `json:"balanceChanges"`
+}
+
+type Checkpoint struct {
+	Epoch          string   `json:"epoch"`
+	SequenceNumber string   `json:"sequenceNumber"`
+	Digest         string   `json:"digest"`
+	PreviousDigest string   `json:"previousDigest"`
+	TimestampMs    string   `json:"timestampMs"`
+	Transactions   []string `json:"transactions"`
+}
+
+// transactionBlockOptions 查询交易时返回的字段
+var transactionBlockOptions = map[string]any{
+	"showInput":          true,
+	"showEffects":        true,
+	"showBalanceChanges": true,
+}
+
+type SuiClient interface {
+	GetChainIdentifier() (string, error)
+	GetReferenceGasPrice() (uint64, error)
+	GetBalance(owner, coinType string) (*Balance, error)
+	GetCoins(owner, coinType, cursor string, limit uint32) (*CoinPage, error)
+	GetObject(objectId string) (*ObjectData, error)
+	DryRunTransactionBlock(txBytes string) (*DryRunResult, error)
+	ExecuteTransactionBlock(txBytes string, signatures []string) (*TransactionBlock, error)
+	GetTransactionBlock(digest string) (*TransactionBlock, error)
+	MultiGetTransactionBlocks(digests []string) ([]TransactionBlock, error)
+	QueryTransactionBlocks(filter map[string]any, cursor string, limit uint32, descending bool) (*TransactionBlockPage, error)
+	GetLatestCheckpointSequenceNumber() (uint64, error)
+	GetCheckpoint(id string) (*Checkpoint, error)
+	Close()
+}
+
+type clnt struct {
+	rpc *rpc.Client
+}
+
+func DialSuiClient(ctx context.Context, rpcUrl string) (SuiClient, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultDialTimeout)
+	defer cancel()
+
+	bOff := retry2.Exponential()
+	rpcClient, err := retry2.Do(ctx, defaultDialAttempts, bOff, func() (*rpc.Client, error) {
+		client, err := rpc.DialContext(ctx, rpcUrl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial address (%s): %w", rpcUrl, err)
+		}
+		return client, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &clnt{rpc: rpcClient}, nil
+}
+
+func (c *clnt) call(result any, method string, args ...any) error {
+	ctxwt, cancel := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancel()
+	if err := c.rpc.CallContext(ctxwt, result, method, args...); err != nil {
+		log.Error("Call sui method fail", "method", method, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (c *clnt) GetChainIdentifier() (string, error) {
+	var chainId string
+	err := c.call(&chainId, "sui_getChainIdentifier")
+	return chainId, err
+}
+
+// GetReferenceGasPrice 当前 epoch 的参考 gas 价格, 单位 MIST
+func (c *clnt) GetReferenceGasPrice() (uint64, error) {
+	var price bigUint
+	err := c.call(&price, "suix_getReferenceGasPrice")
+	return uint64(price), err
+}
+
+func (c *clnt) GetBalance(owner, coinType string) (*Balance, error) {
+	var balance Balance
+	if err := c.call(&balance, "suix_getBalance", owner, coinType); err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+func (c *clnt) GetCoins(owner, coinType, cursor string, limit uint32) (*CoinPage, error) {
+	var page CoinPage
+	var cursorArg any
+	if cursor != "" {
+		cursorArg = cursor
+	}
+	if err := c.call(&page, "suix_getCoins", owner, coinType, cursorArg, limit); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *clnt) GetObject(objectId string) (*ObjectData, error) {
+	var result struct {
+		Data  *ObjectData     `json:"data"`
+		Error json.RawMessage `json:"error"`
+	}
+	if err := c.call(&result, "sui_getObject", objectId, map[string]any{"showType": true}); err != nil {
+		return nil, err
+	}
+	if result.Data == nil {
+		return nil, fmt.Errorf("get object %s fail: %s", objectId, string(result.Error))
+	}
+	return result.Data, nil
+}
+
+// DryRunTransactionBlock txBytes 为 base64 编码的 bcs(TransactionData), 不需要签名
+func (c *clnt) DryRunTransactionBlock(txBytes string) (*DryRunResult, error) {
+	var result DryRunResult
+	if err := c.call(&result, "sui_dryRunTransactionBlock", txBytes); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExecuteTransactionBlock signatures 为 base64 编码的序列化签名 (flag || signature || public key)
+func (c *clnt) ExecuteTransactionBlock(txBytes string, signatures []string) (*TransactionBlock, error) {
+	var result TransactionBlock
+	err := c.call(&result, "sui_executeTransactionBlock", txBytes, signatures, map[string]any{"showEffects": true}, executeRequestType)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTransactionBlock 交易不存在时返回 ErrNotFound
+func (c *clnt) GetTransactionBlock(digest string) (*TransactionBlock, error) {
+	var result TransactionBlock
+	if err := c.call(&result, "sui_getTransactionBlock", digest, transactionBlockOptions); err != nil {
+		if strings.Contains(err.Error(), "Could not find the referenced transaction") {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *clnt) MultiGetTransactionBlocks(digests []string) ([]TransactionBlock, error) {
+	var result []TransactionBlock
+	err := c.call(&result, "sui_multiGetTransactionBlocks", digests, transactionBlockOptions)
+	return result, err
+}
+
+// QueryTransactionBlocks filter 如 {"FromAddress": "0x.."}, cursor 为上一页最后一笔交易的 digest
+func (c *clnt) QueryTransactionBlocks(filter map[string]any, cursor string, limit uint32, descending bool) (*TransactionBlockPage, error) {
+	var page TransactionBlockPage
+	var cursorArg any
+	if cursor != "" {
+		cursorArg = cursor
+	}
+	query := map[string]any{"filter": filter, "options": transactionBlockOptions}
+	if err := c.call(&page, "suix_queryTransactionBlocks", query, cursorArg, limit, descending); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *clnt) GetLatestCheckpointSequenceNumber() (uint64, error) {
+	var sequence bigUint
+	err := c.call(&sequence, "sui_getLatestCheckpointSequenceNumber")
+	return uint64(sequence), err
+}
+
+// GetCheckpoint id 为 checkpoint 序号或 digest
+func (c *clnt) GetCheckpoint(id string) (*Checkpoint, error) {
+	var checkpoint Checkpoint
+	if err := c.call(&checkpoint, "sui_getCheckpoint", id); err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+func (c *clnt) Close() {
+	c.rpc.Close()
+}
+
+// bigUint Sui JSON-RPC 中 u64 以字符串返回
+type bigUint uint64
+
+func (b *bigUint) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*b = bigUint(v)
+	return nil
+}
@@ -0,0 +1,542 @@
+package sui
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/CavnHan/wallet-chain-account/common/base58"
+	"github.com/CavnHan/wallet-chain-account/common/bcs"
+)
+
+const (
+	SuiCoinType = "0x2::sui::SUI"
+
+	// TransactionData::V1 与 TransactionKind::ProgrammableTransaction
+	transactionDataV1       = 0
+	programmableTransaction = 0
+
+	callArgPure             = 0
+	callArgObject           = 1
+	objectArgImmOrOwnedType = 0
+
+	argumentGasCoin      = 0
+	argumentInput        = 1
+	argumentResult       = 2
+	argumentNestedResult = 3
+
+	CommandTransferObjects = 1
+	CommandSplitCoins      = 2
+	CommandMergeCoins      = 3
+
+	expirationNone = 0
+
+	digestLength           = 32
+	ed25519SignatureLength = 64
+	transactionDigestSalt  = "TransactionData::"
+)
+
+// intent 前缀 (scope=TransactionData, version=V0, app=Sui)
+var transactionIntent = []byte{0, 0, 0}
+
+// TxStructure CreateUnSignTransaction/DecodeTransaction 使用的 json 结构, 金额单位为 MIST
+// contract_address 为 Coin 类型, 为空时转 SUI
+type TxStructure struct {
+	FromAddress     string `json:"from_address"`
+	ToAddress       string `json:"to_address"`
+	Value           string `json:"value"`
+	ContractAddress string `json:"contract_address"`
+	PublicKey       string `json:"public_key"`
+	GasBudget       uint64 `json:"gas_budget"`
+	GasPrice        uint64 `json:"gas_price"`
+	// DecodeTransaction 返回被合并/拆分的 Coin 对象, 构建交易时忽略
+	CoinObjects []string `json:"coin_objects,omitempty"`
+}
+
+// UnsignedTx CreateUnSignTransaction 的返回值, 签名方对 signing_message 签名
+type UnsignedTx struct {
+	TxBytes        string `json:"tx_bytes"`
+	PublicKey      string `json:"public_key"`
+	SigningMessage string `json:"signing_message"`
+}
+
+type ObjectRef struct {
+	ObjectId Address
+	Version  uint64
+	Digest   []byte
+}
+
+// CallArg 只支持 Pure 参数与 owned 对象
+type CallArg struct {
+	Pure   []byte
+	Object *ObjectRef
+}
+
+type Argument struct {
+	Kind        uint8
+	Index       uint16
+	ResultIndex uint16
+}
+
+type Command struct {
+	Kind uint64
+	// SplitCoins/MergeCoins 的目标 coin, TransferObjects 的接收地址
+	Target Argument
+	// SplitCoins 的金额, MergeCoins 的来源 coin, TransferObjects 的对象
+	Arguments []Argument
+}
+
+type TransactionData struct {
+	Sender     Address
+	Inputs     []CallArg
+	Commands   []Command
+	GasPayment []ObjectRef
+	GasOwner   Address
+	GasPrice   uint64
+	GasBudget  uint64
+}
+
+func GasCoin() Argument {
+	return Argument{Kind: argumentGasCoin}
+}
+
+func Input(index uint16) Argument {
+	return Argument{Kind: argumentInput, Index: index}
+}
+
+func NestedResult(index, resultIndex uint16) Argument {
+	return Argument{Kind: argumentNestedResult, Index: index, ResultIndex: resultIndex}
+}
+
+func PureU64(v uint64) CallArg {
+	return CallArg{Pure: binary.LittleEndian.AppendUint64(nil, v)}
+}
+
+func PureAddress(address Address) CallArg {
+	return CallArg{Pure: address[:]}
+}
+
+/**
+ * @description: 构建转账的 programmable transaction block
+ * coins 为空时从 gas coin 中拆分 SUI, 否则先将 coins 合并到第一个对象再拆分出转账金额
+ */
+func NewTransferTransaction(sender, to Address, amount uint64, coins []ObjectRef) *TransactionData {
+	tx := &TransactionData{Sender: sender, GasOwner: sender}
+	source := GasCoin()
+	if len(coins) > 0 {
+		for i := range coins {
+			tx.Inputs = append(tx.Inputs, CallArg{Object: &coins[i]})
+		}
+		source = Input(0)
+		if len(coins) > 1 {
+			var sources []Argument
+			for i := 1; i < len(coins); i++ {
+				sources = append(sources, Input(uint16(i)))
+			}
+			tx.Commands = append(tx.Commands, Command{Kind: CommandMergeCoins, Target: source, Arguments: sources})
+		}
+	}
+	amountIndex := uint16(len(tx.Inputs))
+	tx.Inputs = append(tx.Inputs, PureU64(amount), PureAddress(to))
+	splitIndex := uint16(len(tx.Commands))
+	tx.Commands = append(tx.Commands,
+		Command{Kind: CommandSplitCoins, Target: source, Arguments: []Argument{Input(amountIndex)}},
+		Command{Kind: CommandTransferObjects, Target: Input(amountIndex + 1), Arguments: []Argument{NestedResult(splitIndex, 0)}},
+	)
+	return tx
+}
+
+func (a Argument) serialize(w *bcs.Writer) {
+	w.Uleb128(uint64(a.Kind))
+	switch a.Kind {
+	case argumentInput, argumentResult:
+		w.U16(a.Index)
+	case argumentNestedResult:
+		w.U16(a.Index)
+		w.U16(a.ResultIndex)
+	}
+}
+
+func serializeArguments(w *bcs.Writer, args []Argument) {
+	w.Uleb128(uint64(len(args)))
+	for _, arg := range args {
+		arg.serialize(w)
+	}
+}
+
+func (o *ObjectRef) serialize(w *bcs.Writer) {
+	w.Fixed(o.ObjectId[:])
+	w.U64(o.Version)
+	w.Vec(o.Digest)
+}
+
+func (tx *TransactionData) Serialize() []byte {
+	w := &bcs.Writer{}
+	w.Uleb128(transactionDataV1)
+	w.Uleb128(programmableTransaction)
+	w.Uleb128(uint64(len(tx.Inputs)))
+	for _, input := range tx.Inputs {
+		if input.Object != nil {
+			w.Uleb128(callArgObject)
+			w.Uleb128(objectArgImmOrOwnedType)
+			input.Object.serialize(w)
+		} else {
+			w.Uleb128(callArgPure)
+			w.Vec(input.Pure)
+		}
+	}
+	w.Uleb128(uint64(len(tx.Commands)))
+	for _, command := range tx.Commands {
+		w.Uleb128(command.Kind)
+		switch command.Kind {
+		case CommandTransferObjects:
+			serializeArguments(w, command.Arguments)
+			command.Target.serialize(w)
+		default:
+			command.Target.serialize(w)
+			serializeArguments(w, command.Arguments)
+		}
+	}
+	w.Fixed(tx.Sender[:])
+	w.Uleb128(uint64(len(tx.GasPayment)))
+	for i := range tx.GasPayment {
+		tx.GasPayment[i].serialize(w)
+	}
+	w.Fixed(tx.GasOwner[:])
+	w.U64(tx.GasPrice)
+	w.U64(tx.GasBudget)
+	w.Uleb128(expirationNone)
+	return w.Bytes()
+}
+
+// SigningMessage blake2b-256(intent || bcs(TransactionData)), ed25519 直接签名, secp256k1 对其 sha256 签名
+func (tx *TransactionData) SigningMessage() []byte {
+	digest := blake2b.Sum256(append(append([]byte{}, transactionIntent...), tx.Serialize()...))
+	return digest[:]
+}
+
+// Digest 交易哈希, base58(blake2b-256("TransactionData::" || bcs(TransactionData)))
+func (tx *TransactionData) Digest() string {
+	digest := blake2b.Sum256(append([]byte(transactionDigestSalt), tx.Serialize()...))
+	return base58.Encode(digest[:])
+}
+
+// SerializedSignature flag || signature || public key
+func SerializedSignature(flag byte, signature, publicKey []byte) []byte {
+	return append(append([]byte{flag}, signature...), publicKey...)
+}
+
+// SignedTransaction bcs(SenderSignedData), 即单个 intent 消息与其签名列表
+func (tx *TransactionData) SignedTransaction(signature []byte) []byte {
+	w := &bcs.Writer{}
+	w.Uleb128(1)
+	w.Fixed(transactionIntent)
+	w.Fixed(tx.Serialize())
+	w.Uleb128(1)
+	w.Vec(signature)
+	return w.Bytes()
+}
+
+func deserializeArgument(r *bcs.Reader) (Argument, error) {
+	kind, err := r.Uleb128()
+	if err != nil {
+		return Argument{}, err
+	}
+	arg := Argument{Kind: uint8(kind)}
+	switch kind {
+	case argumentGasCoin:
+	case argumentInput, argumentResult:
+		if arg.Index, err = r.U16(); err != nil {
+			return Argument{}, err
+		}
+	case argumentNestedResult:
+		if arg.Index, err = r.U16(); err != nil {
+			return Argument{}, err
+		}
+		if arg.ResultIndex, err = r.U16(); err != nil {
+			return Argument{}, err
+		}
+	default:
+		return Argument{}, bcs.ErrInvalidBcs
+	}
+	return arg, nil
+}
+
+func deserializeArguments(r *bcs.Reader) ([]Argument, error) {
+	n, err := r.Uleb128()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.Remaining())) {
+		return nil, bcs.ErrInvalidBcs
+	}
+	args := make([]Argument, 0, n)
+	for i := uint64(0); i < n; i++ {
+		arg, err := deserializeArgument(r)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+func deserializeObjectRef(r *bcs.Reader) (ObjectRef, error) {
+	var ref ObjectRef
+	id, err := r.Fixed(AddressLength)
+	if err != nil {
+		return ref, err
+	}
+	copy(ref.ObjectId[:], id)
+	if ref.Version, err = r.U64(); err != nil {
+		return ref, err
+	}
+	if ref.Digest, err = r.Vec(); err != nil {
+		return ref, err
+	}
+	if len(ref.Digest) != digestLength {
+		return ref, bcs.ErrInvalidBcs
+	}
+	return ref, nil
+}
+
+func deserializeTransactionData(r *bcs.Reader) (*TransactionData, error) {
+	version, err := r.Uleb128()
+	if err != nil {
+		return nil, err
+	}
+	kind, err := r.Uleb128()
+	if err != nil {
+		return nil, err
+	}
+	if version != transactionDataV1 || kind != programmableTransaction {
+		return nil, errors.New("only programmable transaction is supported")
+	}
+	tx := &TransactionData{}
+	n, err := r.Uleb128()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < n; i++ {
+		variant, err := r.Uleb128()
+		if err != nil {
+			return nil, err
+		}
+		switch variant {
+		case callArgPure:
+			pure, err := r.Vec()
+			if err != nil {
+				return nil, err
+			}
+			tx.Inputs = append(tx.Inputs, CallArg{Pure: pure})
+		case callArgObject:
+			objectType, err := r.Uleb128()
+			if err != nil {
+				return nil, err
+			}
+			if objectType != objectArgImmOrOwnedType {
+				return nil, errors.New("only owned object input is supported")
+			}
+			ref, err := deserializeObjectRef(r)
+			if err != nil {
+				return nil, err
+			}
+			tx.Inputs = append(tx.Inputs, CallArg{Object: &ref})
+		default:
+			return nil, bcs.ErrInvalidBcs
+		}
+	}
+	if n, err = r.Uleb128(); err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < n; i++ {
+		command := Command{}
+		if command.Kind, err = r.Uleb128(); err != nil {
+			return nil, err
+		}
+		switch command.Kind {
+		case CommandTransferObjects:
+			if command.Arguments, err = deserializeArguments(r); err != nil {
+				return nil, err
+			}
+			if command.Target, err = deserializeArgument(r); err != nil {
+				return nil, err
+			}
+		case CommandSplitCoins, CommandMergeCoins:
+			if command.Target, err = deserializeArgument(r); err != nil {
+				return nil, err
+			}
+			if command.Arguments, err = deserializeArguments(r); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported command %d", command.Kind)
+		}
+		tx.Commands = append(tx.Commands, command)
+	}
+	sender, err := r.Fixed(AddressLength)
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.Sender[:], sender)
+	if n, err = r.Uleb128(); err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < n; i++ {
+		ref, err := deserializeObjectRef(r)
+		if err != nil {
+			return nil, err
+		}
+		tx.GasPayment = append(tx.GasPayment, ref)
+	}
+	owner, err := r.Fixed(AddressLength)
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.GasOwner[:], owner)
+	if tx.GasPrice, err = r.U64(); err != nil {
+		return nil, err
+	}
+	if tx.GasBudget, err = r.U64(); err != nil {
+		return nil, err
+	}
+	expiration, err := r.Uleb128()
+	if err != nil {
+		return nil, err
+	}
+	if expiration != expirationNone {
+		return nil, errors.New("transaction expiration is not supported")
+	}
+	return tx, nil
+}
+
+func DeserializeTransactionData(data []byte) (*TransactionData, error) {
+	r := bcs.NewReader(data)
+	tx, err := deserializeTransactionData(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Remaining()) != 0 {
+		return nil, bcs.ErrInvalidBcs
+	}
+	return tx, nil
+}
+
+// DeserializeSignedTransaction 返回交易与序列化签名 (flag || signature || public key), 只支持单签
+func DeserializeSignedTransaction(data []byte) (*TransactionData, []byte, error) {
+	r := bcs.NewReader(data)
+	n, err := r.Uleb128()
+	if err != nil {
+		return nil, nil, err
+	}
+	if n != 1 {
+		return nil, nil, errors.New("only single sender signed data is supported")
+	}
+	intent, err := r.Fixed(len(transactionIntent))
+	if err != nil {
+		return nil, nil, err
+	}
+	if string(intent) != string(transactionIntent) {
+		return nil, nil, errors.New("invalid transaction intent")
+	}
+	tx, err := deserializeTransactionData(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n, err = r.Uleb128(); err != nil {
+		return nil, nil, err
+	}
+	if n != 1 {
+		return nil, nil, errors.New("only single signature is supported")
+	}
+	signature, err := r.Vec()
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, signature, nil
+}
+
+/**
+ * @description: 从 NewTransferTransaction 构建的交易中解析转账
+ * 交易中不包含 Coin 类型, 转代币时 CoinObjects 返回被合并的 Coin 对象, 由调用方查询对象类型
+ */
+func (tx *TransactionData) DecodeTransfer() (*TxStructure, error) {
+	txStructure := &TxStructure{
+		FromAddress: tx.Sender.String(),
+		GasBudget:   tx.GasBudget,
+		GasPrice:    tx.GasPrice,
+	}
+	pure := func(arg Argument, size int) ([]byte, error) {
+		if arg.Kind != argumentInput || int(arg.Index) >= len(tx.Inputs) {
+			return nil, bcs.ErrInvalidBcs
+		}
+		input := tx.Inputs[arg.Index]
+		if input.Object != nil || len(input.Pure) != size {
+			return nil, bcs.ErrInvalidBcs
+		}
+		return input.Pure, nil
+	}
+	var split *Command
+	var splitIndex int
+	for i := range tx.Commands {
+		command := &tx.Commands[i]
+		switch command.Kind {
+		case CommandSplitCoins:
+			if split != nil || len(command.Arguments) != 1 {
+				return nil, errors.New("unsupported split coins command")
+			}
+			split, splitIndex = command, i
+		case CommandTransferObjects:
+			if split == nil || len(command.Arguments) != 1 {
+				return nil, errors.New("unsupported transfer objects command")
+			}
+			object := command.Arguments[0]
+			if object.Kind != argumentNestedResult || int(object.Index) != splitIndex || object.ResultIndex != 0 {
+				return nil, errors.New("transfer object is not the split coin")
+			}
+			amount, err := pure(split.Arguments[0], 8)
+			if err != nil {
+				return nil, err
+			}
+			to, err := pure(command.Target, AddressLength)
+			if err != nil {
+				return nil, err
+			}
+			var recipient Address
+			copy(recipient[:], to)
+			txStructure.ToAddress = recipient.String()
+			txStructure.Value = strconv.FormatUint(binary.LittleEndian.Uint64(amount), 10)
+		}
+	}
+	if split == nil || txStructure.ToAddress == "" {
+		return nil, errors.New("not a transfer transaction")
+	}
+	if split.Target.Kind != argumentGasCoin {
+		for _, input := range tx.Inputs {
+			if input.Object != nil {
+				txStructure.CoinObjects = append(txStructure.CoinObjects, input.Object.ObjectId.String())
+			}
+		}
+		if len(txStructure.CoinObjects) == 0 {
+			return nil, errors.New("missing coin objects")
+		}
+	}
+	return txStructure, nil
+}
+
+// IsSuiCoin 判断 Coin 类型是否为 SUI, 兼容 0x2 的长短地址格式
+func IsSuiCoin(coinType string) bool {
+	if coinType == "" {
+		return true
+	}
+	parts := strings.SplitN(coinType, "::", 2)
+	if len(parts) != 2 || parts[1] != "sui::SUI" {
+		return false
+	}
+	address, err := ParseAddress(parts[0])
+	return err == nil && address == Address{AddressLength - 1: 2}
+}
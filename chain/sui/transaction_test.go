@@ -0,0 +1,135 @@
+package sui
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+// 发送方为 ed25519 公钥 0x00..0x1f, gas price 750, gas budget 0.005 SUI
+const (
+	testPublicKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	testSender    = "0x0ddaaec3ffac93977c83c3d7440e9e65663850d4861be2f48532548d0a463336"
+	testTo        = "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+
+	// 从 gas coin 拆分 1.5 SUI 转出
+	suiTransferTx = "0000020008002f6859000000000020bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb02020001010000010103000000000101000ddaaec3ffac93977c83c3d7440e9e65663850d4861be2f48532548d0a46333601111111111111111111111111111111111111111111111111111111111111111164000000000000002021212121212121212121212121212121212121212121212121212121212121210ddaaec3ffac93977c83c3d7440e9e65663850d4861be2f48532548d0a463336ee02000000000000404b4c000000000000"
+	suiTransferSigningMessage = "ea1cf6b03fe6491dbd402110d85f6a549195b007c8af7959b7c6f99362c6a379"
+	suiTransferDigest         = "7KaYVSNDeA6RptRnuuuuuofw5ZTM9N7FqU9kP2USheAe"
+
+	// 合并两个 Coin 对象后拆分 2500000 转出
+	coinTransferTx = "0000040100212121212121212121212121212121212121212121212121212121212121212107000000000000002031313131313131313131313131313131313131313131313131313131313131310100222222222222222222222222222222222222222222222222222222222222222208000000000000002032323232323232323232323232323232323232323232323232323232323232320008a0252600000000000020bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb0303010000010101000201000001010200010103010000000103000ddaaec3ffac93977c83c3d7440e9e65663850d4861be2f48532548d0a46333601111111111111111111111111111111111111111111111111111111111111111164000000000000002021212121212121212121212121212121212121212121212121212121212121210ddaaec3ffac93977c83c3d7440e9e65663850d4861be2f48532548d0a463336ee02000000000000404b4c000000000000"
+)
+
+// objectRef 对象 ID 为 32 个 b, 摘要为 32 个 b+0x10
+func objectRef(b byte, version uint64) ObjectRef {
+	var id Address
+	copy(id[:], bytes.Repeat([]byte{b}, AddressLength))
+	return ObjectRef{ObjectId: id, Version: version, Digest: bytes.Repeat([]byte{b + 0x10}, digestLength)}
+}
+
+func testTransaction(t *testing.T, amount uint64, coins []ObjectRef) *TransactionData {
+	t.Helper()
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	to, _ := ParseAddress(testTo)
+	tx := NewTransferTransaction(AddressFromPublicKey(FlagEd25519, publicKey), to, amount, coins)
+	tx.GasPayment = []ObjectRef{objectRef(0x11, 100)}
+	tx.GasPrice = 750
+	tx.GasBudget = 5_000_000
+	return tx
+}
+
+func TestTransactionDataSerialize(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       uint64
+		coins        []ObjectRef
+		want         string
+		wantTransfer TxStructure
+	}{
+		{"sui", 1_500_000_000, nil, suiTransferTx, TxStructure{Value: "1500000000"}},
+		{
+			name:   "merged coins",
+			amount: 2_500_000,
+			coins:  []ObjectRef{objectRef(0x21, 7), objectRef(0x22, 8)},
+			want:   coinTransferTx,
+			wantTransfer: TxStructure{Value: "2500000", CoinObjects: []string{
+				"0x2121212121212121212121212121212121212121212121212121212121212121",
+				"0x2222222222222222222222222222222222222222222222222222222222222222",
+			}},
+		},
+	}
+	for _, tt := range tests {
+		tx := testTransaction(t, tt.amount, tt.coins)
+		if got := hex.EncodeToString(tx.Serialize()); got != tt.want {
+			t.Errorf("%s: tx = %s, want %s", tt.name, got, tt.want)
+		}
+		raw, _ := hex.DecodeString(tt.want)
+		decoded, err := DeserializeTransactionData(raw)
+		if err != nil {
+			t.Errorf("%s: deserialize: %v", tt.name, err)
+			continue
+		}
+		transfer, err := decoded.DecodeTransfer()
+		if err != nil {
+			t.Errorf("%s: decode transfer: %v", tt.name, err)
+			continue
+		}
+		want := tt.wantTransfer
+		want.FromAddress, want.ToAddress, want.GasPrice, want.GasBudget = testSender, testTo, 750, 5_000_000
+		if !reflect.DeepEqual(*transfer, want) {
+			t.Errorf("%s: transfer = %+v, want %+v", tt.name, *transfer, want)
+		}
+	}
+}
+
+func TestSigningMessageAndDigest(t *testing.T) {
+	tx := testTransaction(t, 1_500_000_000, nil)
+	if got := hex.EncodeToString(tx.SigningMessage()); got != suiTransferSigningMessage {
+		t.Errorf("signing message = %s, want %s", got, suiTransferSigningMessage)
+	}
+	if got := tx.Digest(); got != suiTransferDigest {
+		t.Errorf("digest = %s, want %s", got, suiTransferDigest)
+	}
+}
+
+func TestSignedTransaction(t *testing.T) {
+	tx := testTransaction(t, 1_500_000_000, nil)
+	publicKey, _ := hex.DecodeString(testPublicKey)
+	sig := SerializedSignature(FlagEd25519, bytes.Repeat([]byte{0xcc}, ed25519SignatureLength), publicKey)
+	signed := tx.SignedTransaction(sig)
+	// 1 个 intent 消息, intent 000000, 1 个签名, 签名长度 97
+	want := "01000000" + suiTransferTx + "0161" + "00" + hex.EncodeToString(bytes.Repeat([]byte{0xcc}, 64)) + testPublicKey
+	if hex.EncodeToString(signed) != want {
+		t.Fatalf("signed tx = %x, want %s", signed, want)
+	}
+	decoded, gotSig, err := DeserializeSignedTransaction(signed)
+	if err != nil || hex.EncodeToString(decoded.Serialize()) != suiTransferTx || !bytes.Equal(gotSig, sig) {
+		t.Errorf("deserialize = %x, %v", gotSig, err)
+	}
+	// intent scope 不是 TransactionData
+	bad := append([]byte{}, signed...)
+	bad[1] = 3
+	if _, _, err := DeserializeSignedTransaction(bad); err == nil {
+		t.Error("personal message intent accepted")
+	}
+}
+
+func TestDeserializeTransactionDataInvalid(t *testing.T) {
+	raw, _ := hex.DecodeString(suiTransferTx)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated", raw[:len(raw)-1]},
+		{"trailing bytes", append(append([]byte{}, raw...), 0)},
+		// 带过期 epoch
+		{"expiration", append(append([]byte{}, raw[:len(raw)-1]...), 1, 9, 0, 0, 0, 0, 0, 0, 0)},
+	}
+	for _, tt := range tests {
+		if _, err := DeserializeTransactionData(tt.data); err == nil {
+			t.Errorf("%s: decoded", tt.name)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package ton
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	friendlyAddressLength = 36
+
+	tagBounceable    = 0x11
+	tagNonBounceable = 0x51
+	tagTestOnly      = 0x80
+
+	BasechainId   = 0
+	MasterchainId = -1
+)
+
+var errInvalidAddress = errors.New("invalid ton address")
+
+// Address addr_std, 即 workchain 与账户哈希
+type Address struct {
+	Workchain int8
+	Hash      [32]byte
+}
+
+// AddressFlags user-friendly 地址中携带的标志, raw 地址两者均为 false
+type AddressFlags struct {
+	UserFriendly bool
+	Bounceable   bool
+	TestOnly     bool
+}
+
+/**
+ * @description: 解析地址, 支持三种格式
+ * raw: <workchain>:<64 位 hex>
+ * user-friendly: 36 字节 (tag || workchain || hash || crc16) 的 base64/base64url 编码, tag 区分 bounceable (EQ..) 与 non-bounceable (UQ..)
+ */
+func ParseAddress(address string) (*Address, *AddressFlags, error) {
+	if strings.Contains(address, ":") {
+		parts := strings.SplitN(address, ":", 2)
+		workchain, err := strconv.ParseInt(parts[0], 10, 8)
+		if err != nil {
+			return nil, nil, errInvalidAddress
+		}
+		hash, err := hex.DecodeString(parts[1])
+		if err != nil || len(hash) != 32 {
+			return nil, nil, errInvalidAddress
+		}
+		addr := &Address{Workchain: int8(workchain)}
+		copy(addr.Hash[:], hash)
+		return addr, &AddressFlags{}, nil
+	}
+	if len(address) != 48 {
+		return nil, nil, errInvalidAddress
+	}
+	raw, err := base64.URLEncoding.DecodeString(strings.NewReplacer("+", "-", "/", "_").Replace(address))
+	if err != nil || len(raw) != friendlyAddressLength {
+		return nil, nil, errInvalidAddress
+	}
+	if crc16(raw[:34]) != binary.BigEndian.Uint16(raw[34:]) {
+		return nil, nil, errors.New("invalid ton address checksum")
+	}
+	flags := &AddressFlags{UserFriendly: true, TestOnly: raw[0]&tagTestOnly != 0}
+	switch raw[0] &^ tagTestOnly {
+	case tagBounceable:
+		flags.Bounceable = true
+	case tagNonBounceable:
+	default:
+		return nil, nil, errInvalidAddress
+	}
+	addr := &Address{Workchain: int8(raw[1])}
+	copy(addr.Hash[:], raw[2:34])
+	return addr, flags, nil
+}
+
+// Raw <workchain>:<hex>
+func (a *Address) Raw() string {
+	return strconv.Itoa(int(a.Workchain)) + ":" + hex.EncodeToString(a.Hash[:])
+}
+
+// UserFriendly base64url 编码的 user-friendly 地址
+func (a *Address) UserFriendly(bounceable, testOnly bool) string {
+	raw := make([]byte, 0, friendlyAddressLength)
+	tag := byte(tagNonBounceable)
+	if bounceable {
+		tag = tagBounceable
+	}
+	if testOnly {
+		tag |= tagTestOnly
+	}
+	raw = append(raw, tag, byte(a.Workchain))
+	raw = append(raw, a.Hash[:]...)
+	raw = binary.BigEndian.AppendUint16(raw, crc16(raw))
+	return base64.URLEncoding.EncodeToString(raw)
+}
+
+func (a *Address) Equal(other *Address) bool {
+	return other != nil && a.Workchain == other.Workchain && a.Hash == other.Hash
+}
+
+// crc16 CRC-16/XMODEM
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,41 @@
+package ton
+
+import "testing"
+
+// 零地址与 elector 合约地址
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		raw     string
+		flags   AddressFlags
+	}{
+		{"EQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAM9c", "0:0000000000000000000000000000000000000000000000000000000000000000", AddressFlags{UserFriendly: true, Bounceable: true}},
+		{"Ef8zMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzMzM0vF", "-1:3333333333333333333333333333333333333333333333333333333333333333", AddressFlags{UserFriendly: true, Bounceable: true}},
+		{"-1:3333333333333333333333333333333333333333333333333333333333333333", "-1:3333333333333333333333333333333333333333333333333333333333333333", AddressFlags{}},
+		{"UQCD39VS5jcptHL8vMjEXrzGaRcCVYtoTscn4bpAOg8xqM4s", "0:83dfd552e63729b472fcbcc8c45ebcc6691702558b684ec727e1ba403a0f31a8", AddressFlags{UserFriendly: true}},
+		{"kQCD39VS5jcptHL8vMjEXrzGaRcCVYtoTscn4bpAOg8xqChj", "0:83dfd552e63729b472fcbcc8c45ebcc6691702558b684ec727e1ba403a0f31a8", AddressFlags{UserFriendly: true, Bounceable: true, TestOnly: true}},
+	}
+	for _, tt := range tests {
+		address, flags, err := ParseAddress(tt.address)
+		if err != nil {
+			t.Errorf("ParseAddress(%s): %v", tt.address, err)
+			continue
+		}
+		if address.Raw() != tt.raw || *flags != tt.flags {
+			t.Errorf("ParseAddress(%s) = %s %+v, want %s %+v", tt.address, address.Raw(), *flags, tt.raw, tt.flags)
+		}
+		if tt.flags.UserFriendly && address.UserFriendly(tt.flags.Bounceable, tt.flags.TestOnly) != tt.address {
+			t.Errorf("UserFriendly = %s, want %s", address.UserFriendly(tt.flags.Bounceable, tt.flags.TestOnly), tt.address)
+		}
+	}
+	for _, invalid := range []string{
+		"EQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAM9d",
+		"EQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAM9",
+		"0:00000000000000000000000000000000000000000000000000000000000000",
+		"x:0000000000000000000000000000000000000000000000000000000000000000",
+	} {
+		if _, _, err := ParseAddress(invalid); err == nil {
+			t.Errorf("ParseAddress(%s) should fail", invalid)
+		}
+	}
+}
@@ -0,0 +1,550 @@
+package ton
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math/big"
+)
+
+const (
+	maxCellBits = 1023
+	maxCellRefs = 4
+
+	bocMagic = 0xb5ee9c72
+)
+
+var (
+	errCellOverflow  = errors.New("cell overflow")
+	errCellUnderflow = errors.New("cell underflow")
+	errInvalidBoc    = errors.New("invalid boc")
+
+	crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// Cell TVM 普通 cell, 数据按位高位在前存储, 不支持 exotic cell
+type Cell struct {
+	data   []byte
+	bitLen int
+	refs   []*Cell
+
+	hash      []byte
+	depth     uint16
+	depthDone bool
+}
+
+func (c *Cell) BitLen() int {
+	return c.bitLen
+}
+
+func (c *Cell) Refs() []*Cell {
+	return c.refs
+}
+
+func (c *Cell) BeginParse() *Slice {
+	return &Slice{cell: c}
+}
+
+// descriptors d1 = 引用数, d2 = ceil(bits/8) + floor(bits/8)
+func (c *Cell) descriptors() []byte {
+	return []byte{byte(len(c.refs)), byte((c.bitLen+7)/8 + c.bitLen/8)}
+}
+
+// paddedData 不足整字节时补 1 再补 0
+func (c *Cell) paddedData() []byte {
+	data := append([]byte{}, c.data[:(c.bitLen+7)/8]...)
+	if c.bitLen%8 != 0 {
+		data[len(data)-1] |= 1 << (7 - uint(c.bitLen%8))
+	}
+	return data
+}
+
+// Hash cell 的 representation hash
+func (c *Cell) Hash() []byte {
+	if c.hash != nil {
+		return c.hash
+	}
+	repr := append(c.descriptors(), c.paddedData()...)
+	for _, ref := range c.refs {
+		repr = binary.BigEndian.AppendUint16(repr, ref.Depth())
+	}
+	for _, ref := range c.refs {
+		repr = append(repr, ref.Hash()...)
+	}
+	hash := sha256.Sum256(repr)
+	c.hash = hash[:]
+	return c.hash
+}
+
+func (c *Cell) Depth() uint16 {
+	if !c.depthDone {
+		for _, ref := range c.refs {
+			if d := ref.Depth() + 1; d > c.depth {
+				c.depth = d
+			}
+		}
+		c.depthDone = true
+	}
+	return c.depth
+}
+
+// ToBoc 序列化为带 crc32c 校验的单根 bag of cells, 相同 cell 只保存一次
+func (c *Cell) ToBoc() []byte {
+	var order []*Cell
+	index := make(map[string]int)
+	var visit func(cell *Cell)
+	visit = func(cell *Cell) {
+		key := string(cell.Hash())
+		if _, ok := index[key]; ok {
+			return
+		}
+		index[key] = -1
+		for _, ref := range cell.refs {
+			visit(ref)
+		}
+		order = append(order, cell)
+	}
+	visit(c)
+	// 后序遍历的逆序保证父 cell 在子 cell 之前
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	for i, cell := range order {
+		index[string(cell.Hash())] = i
+	}
+
+	sizeBytes := byteLength(uint64(len(order)))
+	var payload []byte
+	for _, cell := range order {
+		payload = append(payload, cell.descriptors()...)
+		payload = append(payload, cell.paddedData()...)
+		for _, ref := range cell.refs {
+			payload = appendUint(payload, uint64(index[string(ref.Hash())]), sizeBytes)
+		}
+	}
+	offBytes := byteLength(uint64(len(payload)))
+
+	boc := binary.BigEndian.AppendUint32(nil, bocMagic)
+	// has_idx = 0, has_crc32c = 1, has_cache_bits = 0, flags = 0
+	boc = append(boc, 0x40|byte(sizeBytes), byte(offBytes))
+	boc = appendUint(boc, uint64(len(order)), sizeBytes)
+	boc = appendUint(boc, 1, sizeBytes)
+	boc = appendUint(boc, 0, sizeBytes)
+	boc = appendUint(boc, uint64(len(payload)), offBytes)
+	boc = appendUint(boc, 0, sizeBytes)
+	boc = append(boc, payload...)
+	return binary.LittleEndian.AppendUint32(boc, crc32.Checksum(boc, crc32cTable))
+}
+
+// ParseBoc 解析单根 bag of cells, 返回根 cell
+func ParseBoc(boc []byte) (*Cell, error) {
+	if len(boc) < 10 || binary.BigEndian.Uint32(boc) != bocMagic {
+		return nil, errInvalidBoc
+	}
+	flags := boc[4]
+	hasIdx, hasCrc := flags&0x80 != 0, flags&0x40 != 0
+	sizeBytes, offBytes := int(flags&0x07), int(boc[5])
+	if sizeBytes == 0 || sizeBytes > 4 || offBytes == 0 || offBytes > 8 {
+		return nil, errInvalidBoc
+	}
+	if hasCrc {
+		body := boc[:len(boc)-4]
+		if crc32.Checksum(body, crc32cTable) != binary.LittleEndian.Uint32(boc[len(boc)-4:]) {
+			return nil, errors.New("boc crc32c mismatch")
+		}
+		boc = body
+	}
+	r := &bocReader{data: boc, pos: 6}
+	cellCount, err := r.uint(sizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	rootCount, err := r.uint(sizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	if rootCount != 1 {
+		return nil, errors.New("only single root boc is supported")
+	}
+	if _, err := r.uint(sizeBytes); err != nil {
+		return nil, err
+	}
+	if _, err := r.uint(offBytes); err != nil {
+		return nil, err
+	}
+	rootIndex, err := r.uint(sizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	if hasIdx {
+		if _, err := r.bytes(int(cellCount) * offBytes); err != nil {
+			return nil, err
+		}
+	}
+	if cellCount > uint64(len(boc)) || rootIndex >= cellCount {
+		return nil, errInvalidBoc
+	}
+	type rawCell struct {
+		cell *Cell
+		refs []uint64
+	}
+	raws := make([]rawCell, cellCount)
+	for i := range raws {
+		descriptors, err := r.bytes(2)
+		if err != nil {
+			return nil, err
+		}
+		refCount, dataLen := int(descriptors[0]&0x07), int(descriptors[1]+1)/2
+		if descriptors[0]&0x08 != 0 || refCount > maxCellRefs {
+			return nil, errors.New("exotic cell is not supported")
+		}
+		data, err := r.bytes(dataLen)
+		if err != nil {
+			return nil, err
+		}
+		cell := &Cell{data: append([]byte{}, data...), bitLen: dataLen * 8}
+		if descriptors[1]%2 == 1 {
+			// 去掉补位: 最后一个 1 及其后的 0
+			last := cell.data[dataLen-1]
+			if last == 0 {
+				return nil, errInvalidBoc
+			}
+			trailing := 0
+			for last&1 == 0 {
+				last >>= 1
+				trailing++
+			}
+			cell.bitLen -= trailing + 1
+			cell.data[dataLen-1] &^= 1 << uint(trailing)
+		}
+		raws[i].cell = cell
+		for j := 0; j < refCount; j++ {
+			ref, err := r.uint(sizeBytes)
+			if err != nil {
+				return nil, err
+			}
+			if ref <= uint64(i) || ref >= cellCount {
+				return nil, errInvalidBoc
+			}
+			raws[i].refs = append(raws[i].refs, ref)
+		}
+	}
+	for i := range raws {
+		for _, ref := range raws[i].refs {
+			raws[i].cell.refs = append(raws[i].cell.refs, raws[ref].cell)
+		}
+	}
+	return raws[rootIndex].cell, nil
+}
+
+type bocReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bocReader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, errInvalidBoc
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *bocReader) uint(n int) (uint64, error) {
+	b, err := r.bytes(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, x := range b {
+		v = v<<8 | uint64(x)
+	}
+	return v, nil
+}
+
+func byteLength(v uint64) int {
+	n := 1
+	for v >= 1<<(8*uint(n)) && n < 8 {
+		n++
+	}
+	return n
+}
+
+func appendUint(b []byte, v uint64, n int) []byte {
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, byte(v>>(8*uint(i))))
+	}
+	return b
+}
+
+// Builder 构建 cell, 溢出错误在 EndCell 时返回
+type Builder struct {
+	data   []byte
+	bitLen int
+	refs   []*Cell
+	err    error
+}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+func (b *Builder) BitsLeft() int {
+	return maxCellBits - b.bitLen
+}
+
+func (b *Builder) StoreBit(bit bool) *Builder {
+	if b.bitLen >= maxCellBits {
+		b.err = errCellOverflow
+		return b
+	}
+	if b.bitLen%8 == 0 {
+		b.data = append(b.data, 0)
+	}
+	if bit {
+		b.data[b.bitLen/8] |= 1 << (7 - uint(b.bitLen%8))
+	}
+	b.bitLen++
+	return b
+}
+
+func (b *Builder) StoreUint(v uint64, bits int) *Builder {
+	for i := bits - 1; i >= 0; i-- {
+		b.StoreBit(i < 64 && v>>uint(i)&1 == 1)
+	}
+	return b
+}
+
+// StoreInt 补码存储
+func (b *Builder) StoreInt(v int64, bits int) *Builder {
+	for i := bits - 1; i >= 0; i-- {
+		shift := uint(i)
+		if shift > 63 {
+			shift = 63
+		}
+		b.StoreBit(v>>shift&1 == 1)
+	}
+	return b
+}
+
+func (b *Builder) StoreBigUint(v *big.Int, bits int) *Builder {
+	if v.Sign() < 0 || v.BitLen() > bits {
+		b.err = errCellOverflow
+		return b
+	}
+	for i := bits - 1; i >= 0; i-- {
+		b.StoreBit(v.Bit(i) == 1)
+	}
+	return b
+}
+
+func (b *Builder) StoreBytes(p []byte) *Builder {
+	for _, x := range p {
+		b.StoreUint(uint64(x), 8)
+	}
+	return b
+}
+
+// StoreCoins VarUInteger 16: 4 位字节长度 + 金额
+func (b *Builder) StoreCoins(v *big.Int) *Builder {
+	if v.Sign() < 0 {
+		b.err = errCellOverflow
+		return b
+	}
+	n := (v.BitLen() + 7) / 8
+	if n > 15 {
+		b.err = errCellOverflow
+		return b
+	}
+	b.StoreUint(uint64(n), 4)
+	return b.StoreBigUint(v, n*8)
+}
+
+// StoreAddress nil 为 addr_none, 否则为 addr_std 且无 anycast
+func (b *Builder) StoreAddress(address *Address) *Builder {
+	if address == nil {
+		return b.StoreUint(0, 2)
+	}
+	b.StoreUint(2, 2).StoreBit(false)
+	b.StoreInt(int64(address.Workchain), 8)
+	return b.StoreBytes(address.Hash[:])
+}
+
+func (b *Builder) StoreRef(c *Cell) *Builder {
+	if len(b.refs) >= maxCellRefs {
+		b.err = errCellOverflow
+		return b
+	}
+	b.refs = append(b.refs, c)
+	return b
+}
+
+func (b *Builder) StoreMaybeRef(c *Cell) *Builder {
+	if c == nil {
+		return b.StoreBit(false)
+	}
+	return b.StoreBit(true).StoreRef(c)
+}
+
+// StoreCell 追加另一个 cell 的数据与引用
+func (b *Builder) StoreCell(c *Cell) *Builder {
+	s := c.BeginParse()
+	for s.BitsLeft() > 0 {
+		bit, _ := s.LoadBit()
+		b.StoreBit(bit)
+	}
+	for _, ref := range c.refs {
+		b.StoreRef(ref)
+	}
+	return b
+}
+
+func (b *Builder) EndCell() (*Cell, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &Cell{data: append([]byte{}, b.data...), bitLen: b.bitLen, refs: append([]*Cell{}, b.refs...)}, nil
+}
+
+// Slice 顺序读取 cell 的数据与引用
+type Slice struct {
+	cell   *Cell
+	pos    int
+	refPos int
+}
+
+func (s *Slice) BitsLeft() int {
+	return s.cell.bitLen - s.pos
+}
+
+func (s *Slice) RefsLeft() int {
+	return len(s.cell.refs) - s.refPos
+}
+
+func (s *Slice) LoadBit() (bool, error) {
+	if s.pos >= s.cell.bitLen {
+		return false, errCellUnderflow
+	}
+	bit := s.cell.data[s.pos/8]>>(7-uint(s.pos%8))&1 == 1
+	s.pos++
+	return bit, nil
+}
+
+func (s *Slice) LoadUint(bits int) (uint64, error) {
+	if bits > 64 || s.BitsLeft() < bits {
+		return 0, errCellUnderflow
+	}
+	var v uint64
+	for i := 0; i < bits; i++ {
+		bit, _ := s.LoadBit()
+		v <<= 1
+		if bit {
+			v |= 1
+		}
+	}
+	return v, nil
+}
+
+func (s *Slice) LoadInt(bits int) (int64, error) {
+	v, err := s.LoadUint(bits)
+	if err != nil {
+		return 0, err
+	}
+	if bits < 64 && v>>(uint(bits)-1)&1 == 1 {
+		v |= ^uint64(0) << uint(bits)
+	}
+	return int64(v), nil
+}
+
+func (s *Slice) LoadBigUint(bits int) (*big.Int, error) {
+	if s.BitsLeft() < bits {
+		return nil, errCellUnderflow
+	}
+	v := new(big.Int)
+	for i := 0; i < bits; i++ {
+		bit, _ := s.LoadBit()
+		v.Lsh(v, 1)
+		if bit {
+			v.SetBit(v, 0, 1)
+		}
+	}
+	return v, nil
+}
+
+func (s *Slice) LoadBytes(n int) ([]byte, error) {
+	if s.BitsLeft() < n*8 {
+		return nil, errCellUnderflow
+	}
+	out := make([]byte, n)
+	for i := range out {
+		v, _ := s.LoadUint(8)
+		out[i] = byte(v)
+	}
+	return out, nil
+}
+
+func (s *Slice) LoadCoins() (*big.Int, error) {
+	n, err := s.LoadUint(4)
+	if err != nil {
+		return nil, err
+	}
+	return s.LoadBigUint(int(n) * 8)
+}
+
+// LoadAddress addr_none 返回 nil, 只支持 addr_std
+func (s *Slice) LoadAddress() (*Address, error) {
+	tag, err := s.LoadUint(2)
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case 0:
+		return nil, nil
+	case 2:
+	default:
+		return nil, errors.New("unsupported address type")
+	}
+	anycast, err := s.LoadBit()
+	if err != nil {
+		return nil, err
+	}
+	if anycast {
+		return nil, errors.New("anycast address is not supported")
+	}
+	workchain, err := s.LoadInt(8)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := s.LoadBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	address := &Address{Workchain: int8(workchain)}
+	copy(address.Hash[:], hash)
+	return address, nil
+}
+
+func (s *Slice) LoadRef() (*Cell, error) {
+	if s.refPos >= len(s.cell.refs) {
+		return nil, errCellUnderflow
+	}
+	ref := s.cell.refs[s.refPos]
+	s.refPos++
+	return ref, nil
+}
+
+func (s *Slice) LoadMaybeRef() (*Cell, error) {
+	has, err := s.LoadBit()
+	if err != nil || !has {
+		return nil, err
+	}
+	return s.LoadRef()
+}
+
+// Equal 按 representation hash 比较
+func (c *Cell) Equal(other *Cell) bool {
+	return bytes.Equal(c.Hash(), other.Hash())
+}
@@ -0,0 +1,158 @@
+package ton
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestEmptyCell(t *testing.T) {
+	cell, err := NewBuilder().EndCell()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7"; hex.EncodeToString(cell.Hash()) != want {
+		t.Errorf("hash = %x, want %s", cell.Hash(), want)
+	}
+	if want := "te6cckEBAQEAAgAAAEysuc0="; base64.StdEncoding.EncodeToString(cell.ToBoc()) != want {
+		t.Errorf("boc = %s, want %s", base64.StdEncoding.EncodeToString(cell.ToBoc()), want)
+	}
+}
+
+// 内置钱包代码的 representation hash 须与官方代码哈希一致, 重新序列化后 cell 不变 (cell 的排列顺序可能与原 BOC 不同)
+func TestParseBocWalletCode(t *testing.T) {
+	for version, code := range walletCodes {
+		raw, err := base64.StdEncoding.DecodeString(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cell, err := ParseBoc(raw)
+		if err != nil {
+			t.Errorf("%s: ParseBoc: %v", version, err)
+			continue
+		}
+		if hex.EncodeToString(cell.Hash()) != walletCodeHashes[version] {
+			t.Errorf("%s: code hash = %x, want %s", version, cell.Hash(), walletCodeHashes[version])
+		}
+		reparsed, err := ParseBoc(cell.ToBoc())
+		if err != nil {
+			t.Errorf("%s: ParseBoc(ToBoc): %v", version, err)
+			continue
+		}
+		if !reparsed.Equal(cell) {
+			t.Errorf("%s: boc round trip mismatch", version)
+		}
+	}
+}
+
+func TestParseBocInvalid(t *testing.T) {
+	valid, err := base64.StdEncoding.DecodeString(walletCodes[WalletV4R2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupted := append([]byte{}, valid...)
+	corrupted[len(corrupted)/2] ^= 0x01
+	badMagic := append([]byte{}, valid...)
+	badMagic[0] = 0x00
+	tests := []struct {
+		name string
+		boc  []byte
+	}{
+		{"crc mismatch", corrupted},
+		{"bad magic", badMagic},
+		{"truncated", valid[:8]},
+		{"empty", nil},
+	}
+	for _, tt := range tests {
+		if _, err := ParseBoc(tt.boc); err == nil {
+			t.Errorf("%s: ParseBoc should fail", tt.name)
+		}
+	}
+}
+
+func TestBuilderSlice(t *testing.T) {
+	ref, err := NewBuilder().StoreUint(0xdeadbeef, 32).EndCell()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := &Address{Workchain: MasterchainId, Hash: [32]byte{0x01, 0x02}}
+	coins, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	cell, err := NewBuilder().
+		StoreBit(true).
+		StoreUint(5, 3).
+		StoreInt(-2, 8).
+		StoreCoins(coins).
+		StoreCoins(new(big.Int)).
+		StoreAddress(address).
+		StoreAddress(nil).
+		StoreMaybeRef(ref).
+		StoreMaybeRef(nil).
+		EndCell()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 数据位数不足整字节, 经过 BOC 序列化后须保持一致
+	parsed, err := ParseBoc(cell.ToBoc())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Equal(cell) || parsed.BitLen() != cell.BitLen() {
+		t.Fatalf("boc round trip mismatch, bits %d vs %d", parsed.BitLen(), cell.BitLen())
+	}
+
+	s := parsed.BeginParse()
+	if bit, _ := s.LoadBit(); !bit {
+		t.Error("bit mismatch")
+	}
+	if v, _ := s.LoadUint(3); v != 5 {
+		t.Errorf("uint = %d, want 5", v)
+	}
+	if v, _ := s.LoadInt(8); v != -2 {
+		t.Errorf("int = %d, want -2", v)
+	}
+	if v, _ := s.LoadCoins(); v.Cmp(coins) != 0 {
+		t.Errorf("coins = %s, want %s", v, coins)
+	}
+	if v, _ := s.LoadCoins(); v.Sign() != 0 {
+		t.Errorf("coins = %s, want 0", v)
+	}
+	if v, _ := s.LoadAddress(); !address.Equal(v) {
+		t.Errorf("address = %v, want %v", v, address)
+	}
+	if v, err := s.LoadAddress(); v != nil || err != nil {
+		t.Errorf("addr_none = %v, %v", v, err)
+	}
+	if v, _ := s.LoadMaybeRef(); v == nil || !v.Equal(ref) {
+		t.Error("ref mismatch")
+	}
+	if v, err := s.LoadMaybeRef(); v != nil || err != nil {
+		t.Errorf("nothing = %v, %v", v, err)
+	}
+	if s.BitsLeft() != 0 || s.RefsLeft() != 0 {
+		t.Errorf("left %d bits %d refs", s.BitsLeft(), s.RefsLeft())
+	}
+	if _, err := s.LoadBit(); err != errCellUnderflow {
+		t.Errorf("err = %v, want underflow", err)
+	}
+}
+
+func TestBuilderOverflow(t *testing.T) {
+	if _, err := NewBuilder().StoreBytes(make([]byte, 128)).EndCell(); err != errCellOverflow {
+		t.Errorf("1024 bits: err = %v, want overflow", err)
+	}
+	if _, err := NewBuilder().StoreBytes(make([]byte, 127)).StoreUint(0, 7).EndCell(); err != nil {
+		t.Errorf("1023 bits: %v", err)
+	}
+	empty, _ := NewBuilder().EndCell()
+	b := NewBuilder()
+	for i := 0; i < 5; i++ {
+		b.StoreRef(empty)
+	}
+	if _, err := b.EndCell(); err != errCellOverflow {
+		t.Errorf("5 refs: err = %v, want overflow", err)
+	}
+	if _, err := NewBuilder().StoreBigUint(big.NewInt(256), 8).EndCell(); err != errCellOverflow {
+		t.Errorf("256 in 8 bits: err = %v, want overflow", err)
+	}
+}
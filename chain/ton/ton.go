@@ -1 +1,1069 @@
 package ton
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	ChainName = "Ton"
+
+	defaultWalletVersion = WalletV4R2
+	// 交易有效期, 超时未上链的外部消息会被钱包合约拒绝, 可安全重发
+	defaultValidSeconds = 600
+	// jetton 转账附带 0.05 TON, 覆盖 jetton 钱包执行与转发通知的费用
+	defaultJettonTonAmount = 50_000_000
+	// 未提供交易时按钱包合约转账的经验值估算, 单位 nanoton
+	defaultTransferFee = 10_000_000
+
+	defaultTxPageSize = 20
+	blockTxPageSize   = 256
+	maxBlockTxPages   = 40
+	// 追踪消息链的最大跳数, jetton 转账为 发送方钱包 -> 发送方 jetton 钱包 -> 接收方 jetton 钱包
+	maxMessageHops = 2
+)
+
+type ChainAdaptor struct {
+	tonClient *TonClient
+	wallet    *Wallet
+	testnet   bool
+	// jetton 钱包地址 (raw) -> *JettonWallet, owner 与 master 不会变化
+	jettonWallets sync.Map
+}
+
+// Params 同一公钥在不同钱包合约版本与 subwallet 下对应不同地址, 需与签名端保持一致
+// WalletVersion 为 v3r2/v4r2/v5r1, 默认 v4r2; v5r1 需通过 WalletCode 提供 base64 编码的合约代码
+type Params struct {
+	WalletVersion string `yaml:"wallet_version"`
+	WalletCode    string `yaml:"wallet_code"`
+	SubwalletId   uint32 `yaml:"subwallet_id"`
+	Testnet       bool   `yaml:"testnet"`
+}
+
+func init() {
+	chain.Register(ChainName, NewChainAdaptor)
+	config.RegisterChainValidator(ChainName, func(node *config.ChainConfig) error {
+		_, _, err := newWallet(node)
+		return err
+	})
+}
+
+func newWallet(node *config.ChainConfig) (*Wallet, *Params, error) {
+	var params Params
+	if err := node.DecodeParams(&params); err != nil {
+		return nil, nil, fmt.Errorf("params: %w", err)
+	}
+	version := WalletVersion(strings.ToLower(params.WalletVersion))
+	if version == "" {
+		version = defaultWalletVersion
+	}
+	wallet, err := NewWallet(version, params.WalletCode, params.SubwalletId, params.Testnet)
+	if err != nil {
+		return nil, nil, fmt.Errorf("params: %w", err)
+	}
+	return wallet, &params, nil
+}
+
+func NewChainAdaptor(conf *config.Config) (chain.IChainAdaptor, error) {
+	node, ok := conf.Chain(ChainName)
+	if !ok {
+		return nil, errors.New("ton rpc not configured")
+	}
+	wallet, params, err := newWallet(node)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		tonClient: NewTonClient(node.RPCs[0].RPCURL, node.DataApiKey, node.TimeOut.Duration()),
+		wallet:    wallet,
+		testnet:   params.Testnet,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// ConvertAddress 由 ed25519 公钥按配置的钱包版本计算地址, 返回 non-bounceable 格式 (UQ..), 转入未部署钱包时资金不会被退回
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	address, err := c.wallet.Address(req.PublicKey)
+	if err != nil {
+		return &account.ConvertAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: address.UserFriendly(false, c.testnet),
+	}, nil
+}
+
+/**
+ * @description: 校验地址, 支持 raw, bounceable 与 non-bounceable 三种格式
+ * canonical_address 为 raw 格式, 与交易查询结果中的地址一致
+ * 仅 basechain 且网络标志与配置一致的地址可提现
+ */
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	address, flags, err := ParseAddress(req.Address)
+	if err != nil {
+		return &account.ValidAddressResponse{
+			Code:  common.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	canWithdrawal := address.Workchain == BasechainId && (!flags.UserFriendly || flags.TestOnly == c.testnet)
+	return &account.ValidAddressResponse{
+		Code:             common.ReturnCode_SUCCESS,
+		Msg:              "valid address",
+		Valid:            true,
+		CanWithdrawal:    canWithdrawal,
+		CanonicalAddress: address.Raw(),
+	}, nil
+}
+
+// GetBlockByNumber 区块对应 masterchain 区块, 交易包含其引用的 shard 区块中的交易; height 为 0 时返回最新区块
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	block, err := c.getMasterchainBlock(req.Height)
+	if err != nil {
+		log.Error("get masterchain block fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	txs, err := c.getBlockTransactions(block.Seqno)
+	if err != nil {
+		log.Error("get block transactions fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block transactions fail",
+		}, nil
+	}
+	var txList []*account.BlockInfoTransactionList
+	for i := range txs {
+		tx := &txs[i]
+		if !req.ViewTx {
+			txList = append(txList, &account.BlockInfoTransactionList{Hash: tx.Hash})
+			continue
+		}
+		item := &account.BlockInfoTransactionList{
+			To:     normalizeAddress(tx.Account),
+			Hash:   tx.Hash,
+			Time:   strconv.FormatInt(tx.Now, 10),
+			Fee:    tx.TotalFees,
+			Status: txStatus(tx).String(),
+		}
+		if tx.InMsg != nil {
+			if tx.InMsg.Source != nil {
+				item.From = normalizeAddress(*tx.InMsg.Source)
+			}
+			if tx.InMsg.Value != nil {
+				item.Amount = *tx.InMsg.Value
+			}
+		}
+		txList = append(txList, item)
+	}
+	return &account.BlockResponse{
+		Code:         common.ReturnCode_SUCCESS,
+		Msg:          "get block success",
+		Height:       int64(block.Seqno),
+		Hash:         block.RootHash,
+		Transactions: txList,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return &account.BlockResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "ton does not support get block by hash",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	return &account.BlockHeaderResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "ton does not support get block header by hash",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	block, err := c.getMasterchainBlock(req.Height)
+	if err != nil {
+		log.Error("get masterchain block fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	genUtime, _ := strconv.ParseUint(block.GenUtime, 10, 64)
+	return &account.BlockHeaderResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get block header success",
+		BlockHeader: &account.BlockHeader{
+			Number: strconv.FormatUint(block.Seqno, 10),
+			Time:   genUtime,
+		},
+	}, nil
+}
+
+func (c *ChainAdaptor) getMasterchainBlock(height int64) (*Block, error) {
+	if height == 0 {
+		info, err := c.tonClient.GetMasterchainInfo()
+		if err != nil {
+			return nil, err
+		}
+		return &info.Last, nil
+	}
+	return c.tonClient.GetMasterchainBlock(uint64(height))
+}
+
+func (c *ChainAdaptor) getBlockTransactions(seqno uint64) ([]Transaction, error) {
+	var txs []Transaction
+	for page := uint32(0); page < maxBlockTxPages; page++ {
+		batch, err := c.tonClient.GetTransactionsByMasterchainBlock(seqno, blockTxPageSize, page*blockTxPageSize)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, batch...)
+		if len(batch) < blockTxPageSize {
+			return txs, nil
+		}
+	}
+	return nil, fmt.Errorf("too many transactions in block %d", seqno)
+}
+
+/**
+ * @description: 查询余额, 合约地址为 jetton master 地址, 为空时查询 TON
+ * Sequence 为钱包 seqno, 钱包未部署时为 0
+ */
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	info, err := c.tonClient.GetWalletInformation(req.Address)
+	if err != nil {
+		log.Error("get wallet information fail", "err", err)
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "get wallet information fail",
+			Balance: "0",
+		}, nil
+	}
+	balance := info.Balance
+	if req.ContractAddress != "" {
+		jettonWallet, err := c.tonClient.GetJettonWallet(req.Address, req.ContractAddress)
+		if errors.Is(err, ErrNotFound) {
+			balance = "0"
+		} else if err != nil {
+			log.Error("get jetton wallet fail", "err", err)
+			return &account.AccountResponse{
+				Code:    common.ReturnCode_ERROR,
+				Msg:     "get jetton wallet fail",
+				Balance: "0",
+			}, nil
+		} else {
+			balance = jettonWallet.Balance
+		}
+	}
+	return &account.AccountResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      strconv.FormatUint(uint64(info.Seqno), 10),
+		Network:       ChainName,
+		Balance:       balance,
+	}, nil
+}
+
+/**
+ * @description: 估算手续费, 单位为 nanoton
+ * rawTx 为 base64(json) 的 TxStructure 时以空签名构建外部消息并通过 estimateFee 计算发送方与接收方费用, 否则按经验值估算
+ * TON 手续费由网络配置决定, 没有优先级, 三档手续费相同
+ */
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	fee := uint64(defaultTransferFee)
+	if req.RawTx != "" {
+		txStructure, err := decodeTxStructure(req.RawTx)
+		if err != nil {
+			log.Error("decode tx structure fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "decode tx structure fail",
+			}, nil
+		}
+		tx, err := c.buildTransaction(txStructure)
+		if err != nil {
+			log.Error("build transaction fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "build transaction fail",
+			}, nil
+		}
+		body, err := c.wallet.SignedBody(tx.signingMessage, make([]byte, ed25519.SignatureSize))
+		if err != nil {
+			log.Error("build message body fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "build message body fail",
+			}, nil
+		}
+		request := &EstimateFeeRequest{
+			Address:      tx.wallet.Raw(),
+			Body:         base64.StdEncoding.EncodeToString(body.ToBoc()),
+			IgnoreChksig: true,
+		}
+		if tx.stateInit != nil {
+			refs := tx.stateInit.Refs()
+			request.InitCode = base64.StdEncoding.EncodeToString(refs[0].ToBoc())
+			request.InitData = base64.StdEncoding.EncodeToString(refs[1].ToBoc())
+		}
+		result, err := c.tonClient.EstimateFee(request)
+		if err != nil {
+			log.Error("estimate fee fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "estimate fee fail",
+			}, nil
+		}
+		fee = result.SourceFees.Total()
+		for i := range result.DestinationFees {
+			fee += result.DestinationFees[i].Total()
+		}
+	}
+	feeStr := strconv.FormatUint(fee, 10)
+	return &account.FeeResponse{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   feeStr,
+		NormalFee: feeStr,
+		FastFee:   feeStr,
+	}, nil
+}
+
+// SendTx raw_tx 为 BuildSignedTransaction 返回的 base64 编码外部消息, tx_hash 为外部消息哈希, 可用于 GetTxByHash
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	boc, err := base64.StdEncoding.DecodeString(req.RawTx)
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	message, err := ParseBoc(boc)
+	if err != nil {
+		log.Error("parse boc fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "parse boc fail",
+		}, nil
+	}
+	if _, _, _, _, err := c.wallet.ParseExternalMessage(message); err != nil {
+		log.Error("parse external message fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "parse external message fail",
+		}, nil
+	}
+	messageHash, err := c.tonClient.SendMessage(req.RawTx)
+	if err != nil {
+		log.Error("send message fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "send message fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: messageHash,
+	}, nil
+}
+
+/**
+ * @description: 查询地址的交易, 按 page/pagesize 分页
+ * 转入交易取 in_msg 的文本备注作为 memo; jetton 转入须由该地址自己的 jetton 钱包发出 transfer_notification, 否则视为普通 TON 转账
+ * 转出交易沿 out_msgs 追踪接收方交易, 接收方执行失败或消息被退回时为 Failed, 尚未送达时为 Pending
+ */
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	pageSize := req.Pagesize
+	if pageSize == 0 {
+		pageSize = defaultTxPageSize
+	}
+	var offset uint32
+	if req.Page > 1 {
+		offset = (req.Page - 1) * pageSize
+	}
+	var contract *Address
+	if req.ContractAddress != "" {
+		var err error
+		if contract, _, err = ParseAddress(req.ContractAddress); err != nil {
+			return &account.TxAddressResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "invalid contract address",
+			}, nil
+		}
+	}
+	txs, err := c.tonClient.GetTransactions(req.Address, "", pageSize, offset)
+	if err != nil {
+		log.Error("get transactions fail", "err", err)
+		return &account.TxAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by address fail",
+		}, nil
+	}
+	var txList []*account.TxMessage
+	for i := range txs {
+		txMessage, err := c.toTxMessage(&txs[i])
+		if err != nil {
+			log.Error("resolve transaction fail", "hash", txs[i].Hash, "err", err)
+			return &account.TxAddressResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "resolve transaction fail",
+			}, nil
+		}
+		if contract != nil && txMessage.ContractAddress != contract.Raw() {
+			continue
+		}
+		txList = append(txList, txMessage)
+	}
+	return &account.TxAddressResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txList,
+	}, nil
+}
+
+// GetTxByHash hash 可以为交易哈希或 SendTx 返回的外部消息哈希
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	txs, err := c.tonClient.GetTransactions("", req.Hash, 1, 0)
+	if err == nil && len(txs) == 0 {
+		txs, err = c.tonClient.GetTransactionsByMessage(req.Hash, MessageDirectionIn)
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		log.Error("get transaction fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}, nil
+	}
+	if len(txs) == 0 {
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_SUCCESS,
+			Msg:  "tx not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	txMessage, err := c.toTxMessage(&txs[0])
+	if err != nil {
+		log.Error("resolve transaction fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "resolve transaction fail",
+		}, nil
+	}
+	return &account.TxHashResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by hash success",
+		Tx:   txMessage,
+	}, nil
+}
+
+// GetBlockByRange 按 masterchain 区块序号返回区块内的全部交易, 不追踪消息链
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, errStart := strconv.ParseUint(req.Start, 10, 64)
+	end, errEnd := strconv.ParseUint(req.End, 10, 64)
+	if errStart != nil || errEnd != nil || start > end {
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid block range",
+		}, nil
+	}
+	var blockList []*account.BlockData
+	for seqno := start; seqno <= end; seqno++ {
+		block, err := c.tonClient.GetMasterchainBlock(seqno)
+		if err != nil {
+			log.Error("get masterchain block fail", "seqno", seqno, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block by range fail",
+			}, nil
+		}
+		txs, err := c.getBlockTransactions(seqno)
+		if err != nil {
+			log.Error("get block transactions fail", "seqno", seqno, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get block by range fail",
+			}, nil
+		}
+		var txList []*account.TxMessage
+		for i := range txs {
+			txList = append(txList, baseTxMessage(&txs[i]))
+		}
+		blockList = append(blockList, &account.BlockData{
+			Hash:         block.RootHash,
+			Transactions: txList,
+		})
+	}
+	return &account.BlockByRangeResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "get block by range success",
+		Blocks: blockList,
+	}, nil
+}
+
+/**
+ * @description: 创建未签名交易
+ * base64_tx 为 base64(json) 的 TxStructure, public_key 必填且须与 from_address 对应的钱包一致
+ * memo 为文本备注, jetton 转账时放在 forward_payload 中随 transfer_notification 送达接收方
+ * 返回 base64(json) 的 UnsignedTx, 签名对象为 signing_hash (hex)
+ */
+func (c *ChainAdaptor) CreateUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txStructure, err := decodeTxStructure(req.Base64Tx)
+	if err != nil {
+		log.Error("decode tx structure fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode tx structure fail",
+		}, nil
+	}
+	tx, err := c.buildTransaction(txStructure)
+	if err != nil {
+		log.Error("build transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "build transaction fail",
+		}, nil
+	}
+	unsignedTx := &UnsignedTx{
+		WalletAddress:  tx.wallet.Raw(),
+		PublicKey:      hex.EncodeToString(tx.publicKey),
+		SigningMessage: base64.StdEncoding.EncodeToString(tx.signingMessage.ToBoc()),
+		SigningHash:    hex.EncodeToString(tx.signingMessage.Hash()),
+	}
+	if tx.stateInit != nil {
+		unsignedTx.StateInit = base64.StdEncoding.EncodeToString(tx.stateInit.ToBoc())
+	}
+	unsignedJson, _ := json.Marshal(unsignedTx)
+	return &account.UnSignTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "create un sign transaction success",
+		UnSignTx: base64.StdEncoding.EncodeToString(unsignedJson),
+	}, nil
+}
+
+type walletTransaction struct {
+	wallet         *Address
+	publicKey      []byte
+	stateInit      *Cell
+	signingMessage *Cell
+}
+
+/**
+ * @description: 构建钱包待签名消息
+ * seqno 为 0 时附带 StateInit, 钱包随本次交易部署
+ * jetton 转账发往发送方的 jetton 钱包, 由其转给接收方的 jetton 钱包, 多余的 TON 通过 response_destination 退回发送方
+ */
+func (c *ChainAdaptor) buildTransaction(txStructure *TxStructure) (*walletTransaction, error) {
+	publicKey, err := hex.DecodeString(strings.TrimPrefix(txStructure.PublicKey, "0x"))
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+	walletAddress, err := c.wallet.Address(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	from, _, err := ParseAddress(txStructure.FromAddress)
+	if err != nil {
+		return nil, err
+	}
+	if !from.Equal(walletAddress) {
+		return nil, errors.New("public key does not match from address")
+	}
+	to, toFlags, err := ParseAddress(txStructure.ToAddress)
+	if err != nil {
+		return nil, err
+	}
+	amount, ok := new(big.Int).SetString(txStructure.Value, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, errors.New("invalid transfer value")
+	}
+
+	var seqno uint32
+	if txStructure.Seqno != "" {
+		value, err := strconv.ParseUint(txStructure.Seqno, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		seqno = uint32(value)
+	} else {
+		info, err := c.tonClient.GetWalletInformation(from.Raw())
+		if err != nil {
+			return nil, err
+		}
+		seqno = info.Seqno
+	}
+	tx := &walletTransaction{wallet: walletAddress, publicKey: publicKey}
+	if seqno == 0 {
+		if tx.stateInit, err = c.wallet.StateInit(publicKey); err != nil {
+			return nil, err
+		}
+	}
+	validUntil := txStructure.ValidUntil
+	if validUntil == 0 {
+		validUntil = uint32(time.Now().Unix()) + defaultValidSeconds
+	}
+
+	message := OutMessage{Mode: DefaultSendMode}
+	if txStructure.ContractAddress == "" {
+		message.Destination, message.Amount = to, amount
+		if txStructure.Bounce != nil {
+			message.Bounce = *txStructure.Bounce
+		} else if message.Bounce, err = c.bounceable(to, toFlags); err != nil {
+			return nil, err
+		}
+		if txStructure.Memo != "" {
+			if message.Body, err = CommentBody(txStructure.Memo); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		jettonWallet, err := c.tonClient.GetJettonWallet(from.Raw(), txStructure.ContractAddress)
+		if err != nil {
+			return nil, fmt.Errorf("get jetton wallet fail: %w", err)
+		}
+		if message.Destination, _, err = ParseAddress(jettonWallet.Address); err != nil {
+			return nil, err
+		}
+		message.Amount = big.NewInt(defaultJettonTonAmount)
+		if txStructure.TonAmount != "" {
+			if message.Amount, ok = new(big.Int).SetString(txStructure.TonAmount, 10); !ok || message.Amount.Sign() <= 0 {
+				return nil, errors.New("invalid ton amount")
+			}
+		}
+		message.Bounce = true
+		transfer := &JettonTransfer{
+			QueryId:             uint64(time.Now().UnixNano()),
+			Amount:              amount,
+			Destination:         to,
+			ResponseDestination: from,
+			Comment:             txStructure.Memo,
+		}
+		if message.Body, err = transfer.Body(); err != nil {
+			return nil, err
+		}
+	}
+	if tx.signingMessage, err = c.wallet.SigningMessage(seqno, validUntil, []OutMessage{message}); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// bounceable user-friendly 地址按地址中的标志, raw 地址仅在目标账户已部署时 bounce, 避免转入未部署钱包的资金被退回
+func (c *ChainAdaptor) bounceable(address *Address, flags *AddressFlags) (bool, error) {
+	if flags.UserFriendly {
+		return flags.Bounceable, nil
+	}
+	info, err := c.tonClient.GetAddressInformation(address.Raw())
+	if err != nil {
+		return false, err
+	}
+	return info.Status == AccountStatusActive, nil
+}
+
+/**
+ * @description: 组装签名交易
+ * base64_tx 为 CreateUnSignTransaction 返回值, signature 为对 signing_hash 的 64 字节 ed25519 签名 (hex)
+ * 返回 base64 编码的外部消息 BOC, 可直接用于 SendTx
+ */
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	unsignedJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var unsignedTx UnsignedTx
+	if err := json.Unmarshal(unsignedJson, &unsignedTx); err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "unmarshal unsigned tx fail",
+		}, nil
+	}
+	publicKey, err := hex.DecodeString(unsignedTx.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	walletAddress, err := c.wallet.Address(publicKey)
+	if err != nil || walletAddress.Raw() != unsignedTx.WalletAddress {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "public key does not match wallet address",
+		}, nil
+	}
+	signingMessage, err := decodeBoc(unsignedTx.SigningMessage)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode signing message fail",
+		}, nil
+	}
+	var stateInit *Cell
+	if unsignedTx.StateInit != "" {
+		expect, _ := c.wallet.StateInit(publicKey)
+		if stateInit, err = decodeBoc(unsignedTx.StateInit); err != nil || !stateInit.Equal(expect) {
+			return &account.SignedTransactionResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "invalid state init",
+			}, nil
+		}
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	if !ed25519.Verify(publicKey, signingMessage.Hash(), signature) {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "verify signature fail",
+		}, nil
+	}
+	message, err := c.wallet.ExternalMessage(walletAddress, stateInit, signingMessage, signature)
+	if err != nil {
+		log.Error("build external message fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "build external message fail",
+		}, nil
+	}
+	return &account.SignedTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "build signed transaction success",
+		SignedTx: base64.StdEncoding.EncodeToString(message.ToBoc()),
+	}, nil
+}
+
+// DecodeTransaction raw_tx 为 base64 编码的外部消息, jetton 转账时 contract_address 通过发送方 jetton 钱包查询 master 地址
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	message, err := decodeBoc(req.RawTx)
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	walletAddress, _, _, outMessages, err := c.wallet.ParseExternalMessage(message)
+	if err != nil {
+		log.Error("parse external message fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "parse external message fail",
+		}, nil
+	}
+	outMessage, err := ParseInternalMessage(outMessages[0])
+	if err != nil {
+		log.Error("parse internal message fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "parse internal message fail",
+		}, nil
+	}
+	bounce := outMessage.Bounce
+	txStructure := &TxStructure{
+		FromAddress: walletAddress.Raw(),
+		ToAddress:   outMessage.Destination.Raw(),
+		Value:       outMessage.Amount.String(),
+		Bounce:      &bounce,
+	}
+	if transfer, ok := ParseJettonTransfer(outMessage.Body); ok {
+		jettonWallet, err := c.getJettonWallet(outMessage.Destination)
+		if err != nil {
+			log.Error("get jetton wallet fail", "err", err)
+			return &account.DecodeTransactionResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "get jetton wallet fail",
+			}, nil
+		}
+		txStructure.ToAddress = transfer.Destination.Raw()
+		txStructure.Value = transfer.Amount.String()
+		txStructure.ContractAddress = normalizeAddress(jettonWallet.Jetton)
+		txStructure.JettonWallet = outMessage.Destination.Raw()
+		txStructure.TonAmount = outMessage.Amount.String()
+		txStructure.Memo = transfer.Comment
+		txStructure.Bounce = nil
+	} else if comment, ok := ParseComment(outMessage.Body); ok {
+		txStructure.Memo = comment
+	}
+	txJson, _ := json.Marshal(txStructure)
+	return &account.DecodeTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "decode transaction success",
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "ton does not support verify signed transaction",
+	}, nil
+}
+
+// GetExtraData 返回离线构建交易所需的钱包版本, wallet_id, seqno 与部署状态 (json)
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	info, err := c.tonClient.GetWalletInformation(req.Address)
+	if err != nil {
+		log.Error("get wallet information fail", "err", err)
+		return &account.ExtraDataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get wallet information fail",
+		}, nil
+	}
+	value, _ := json.Marshal(map[string]string{
+		"wallet_version": string(c.wallet.Version),
+		"wallet_id":      strconv.FormatUint(uint64(c.wallet.WalletId), 10),
+		"seqno":          strconv.FormatUint(uint64(info.Seqno), 10),
+		"status":         info.Status,
+	})
+	return &account.ExtraDataResponse{
+		Code:  common.ReturnCode_SUCCESS,
+		Msg:   "get extra data success",
+		Value: string(value),
+	}, nil
+}
+
+func decodeTxStructure(base64Tx string) (*TxStructure, error) {
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var txStructure TxStructure
+	if err := json.Unmarshal(txJson, &txStructure); err != nil {
+		return nil, err
+	}
+	return &txStructure, nil
+}
+
+func decodeBoc(base64Boc string) (*Cell, error) {
+	boc, err := base64.StdEncoding.DecodeString(base64Boc)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBoc(boc)
+}
+
+// normalizeAddress 统一为小写 raw 格式, 无法解析时原样返回
+func normalizeAddress(address string) string {
+	parsed, _, err := ParseAddress(address)
+	if err != nil {
+		return address
+	}
+	return parsed.Raw()
+}
+
+// getJettonWallet 查询 jetton 钱包的 owner 与 master, 不是 jetton 钱包时返回 ErrNotFound
+func (c *ChainAdaptor) getJettonWallet(address *Address) (*JettonWallet, error) {
+	if cached, ok := c.jettonWallets.Load(address.Raw()); ok {
+		return cached.(*JettonWallet), nil
+	}
+	jettonWallet, err := c.tonClient.GetJettonWalletByAddress(address.Raw())
+	if err != nil {
+		return nil, err
+	}
+	c.jettonWallets.Store(address.Raw(), jettonWallet)
+	return jettonWallet, nil
+}
+
+func txStatus(tx *Transaction) account.TxStatus {
+	if tx.Success() {
+		return account.TxStatus_Success
+	}
+	return account.TxStatus_Failed
+}
+
+func messageBody(message *Message) *Cell {
+	if message == nil || message.MessageContent == nil || message.MessageContent.Body == "" {
+		return nil
+	}
+	body, err := decodeBoc(message.MessageContent.Body)
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+func messageValue(message *Message) string {
+	if message.Value == nil {
+		return "0"
+	}
+	return *message.Value
+}
+
+// baseTxMessage 交易自身的信息, 不含转账方向与金额
+func baseTxMessage(tx *Transaction) *account.TxMessage {
+	return &account.TxMessage{
+		Hash:     tx.Hash,
+		Height:   strconv.FormatUint(tx.McBlockSeqno, 10),
+		Fee:      tx.TotalFees,
+		Status:   txStatus(tx),
+		Datetime: strconv.FormatInt(tx.Now, 10),
+	}
+}
+
+// toTxMessage 外部消息触发的交易为钱包转出, 内部消息触发的交易为转入
+func (c *ChainAdaptor) toTxMessage(tx *Transaction) (*account.TxMessage, error) {
+	txMessage := baseTxMessage(tx)
+	if tx.InMsg == nil {
+		return txMessage, nil
+	}
+	if tx.InMsg.Source == nil {
+		return txMessage, c.resolveOutgoing(tx, txMessage)
+	}
+	return txMessage, c.resolveIncoming(tx, txMessage)
+}
+
+/**
+ * @description: 解析转入交易
+ * jetton 转入以 transfer_notification 识别, 发送通知的合约须为 jetton 钱包且 owner 为本账户, 防止伪造通知
+ * 被退回的消息 (bounced) 是此前转出的资金返还, 不带备注
+ */
+func (c *ChainAdaptor) resolveIncoming(tx *Transaction, txMessage *account.TxMessage) error {
+	accountAddress := normalizeAddress(tx.Account)
+	source := normalizeAddress(*tx.InMsg.Source)
+	body := messageBody(tx.InMsg)
+	txMessage.Froms = []*account.Address{{Address: source}}
+	txMessage.Tos = []*account.Address{{Address: accountAddress}}
+	txMessage.Values = []*account.Value{{Value: messageValue(tx.InMsg)}}
+	if tx.InMsg.Bounced != nil && *tx.InMsg.Bounced {
+		return nil
+	}
+	if notification, ok := ParseJettonNotification(body); ok {
+		sourceAddress, _, err := ParseAddress(source)
+		if err != nil {
+			return err
+		}
+		jettonWallet, err := c.getJettonWallet(sourceAddress)
+		if errors.Is(err, ErrNotFound) {
+			log.Warn("transfer notification from non jetton wallet", "hash", tx.Hash, "source", source)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if normalizeAddress(jettonWallet.Owner) != accountAddress {
+			log.Warn("transfer notification from jetton wallet of other owner", "hash", tx.Hash, "source", source)
+			return nil
+		}
+		txMessage.Froms = []*account.Address{{Address: ""}}
+		if notification.Sender != nil {
+			txMessage.Froms[0].Address = notification.Sender.Raw()
+		}
+		txMessage.Values[0].Value = notification.Amount.String()
+		txMessage.ContractAddress = normalizeAddress(jettonWallet.Jetton)
+		txMessage.Memo = notification.Comment
+		return nil
+	}
+	txMessage.Memo, _ = ParseComment(body)
+	return nil
+}
+
+// resolveOutgoing 解析钱包转出交易, 状态取决于每条 out_msg 在接收方的执行结果
+func (c *ChainAdaptor) resolveOutgoing(tx *Transaction, txMessage *account.TxMessage) error {
+	accountAddress := normalizeAddress(tx.Account)
+	txMessage.Froms = []*account.Address{{Address: accountAddress}}
+	for i := range tx.OutMsgs {
+		outMsg := &tx.OutMsgs[i]
+		if outMsg.Destination == nil {
+			continue
+		}
+		destination := normalizeAddress(*outMsg.Destination)
+		to, value, hops := destination, messageValue(outMsg), 1
+		body := messageBody(outMsg)
+		if transfer, ok := ParseJettonTransfer(body); ok {
+			destinationAddress, _, err := ParseAddress(destination)
+			if err != nil {
+				return err
+			}
+			jettonWallet, err := c.getJettonWallet(destinationAddress)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+			if err == nil && normalizeAddress(jettonWallet.Owner) == accountAddress {
+				to, value, hops = transfer.Destination.Raw(), transfer.Amount.String(), maxMessageHops
+				txMessage.ContractAddress = normalizeAddress(jettonWallet.Jetton)
+				txMessage.Memo = transfer.Comment
+			}
+		} else if comment, ok := ParseComment(body); ok {
+			txMessage.Memo = comment
+		}
+		txMessage.Tos = append(txMessage.Tos, &account.Address{Address: to})
+		txMessage.Values = append(txMessage.Values, &account.Value{Value: value})
+		if txMessage.Status != account.TxStatus_Success {
+			continue
+		}
+		status, err := c.followMessage(outMsg.Hash, hops)
+		if err != nil {
+			return err
+		}
+		txMessage.Status = status
+	}
+	return nil
+}
+
+/**
+ * @description: 沿消息链追踪接收方交易
+ * 消息未被处理时为 Pending, 任一跳执行失败时为 Failed; jetton 转账追踪到接收方 jetton 钱包收到 internal_transfer 为止
+ */
+func (c *ChainAdaptor) followMessage(msgHash string, hops int) (account.TxStatus, error) {
+	txs, err := c.tonClient.GetTransactionsByMessage(msgHash, MessageDirectionIn)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return account.TxStatus_Pending, err
+	}
+	if len(txs) == 0 {
+		return account.TxStatus_Pending, nil
+	}
+	tx := &txs[0]
+	if !tx.Success() {
+		return account.TxStatus_Failed, nil
+	}
+	if hops <= 1 {
+		return account.TxStatus_Success, nil
+	}
+	for i := range tx.OutMsgs {
+		body := messageBody(&tx.OutMsgs[i])
+		if body == nil {
+			continue
+		}
+		if op, err := body.BeginParse().LoadUint(32); err == nil && op == OpJettonInternalTransfer {
+			return c.followMessage(tx.OutMsgs[i].Hash, hops-1)
+		}
+	}
+	return account.TxStatus_Failed, nil
+}
@@ -0,0 +1,347 @@
+package ton
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	defaultRequestTimeout = 10 * time.Second
+
+	AccountStatusActive = "active"
+	AccountStatusUninit = "uninit"
+
+	MessageDirectionIn  = "in"
+	MessageDirectionOut = "out"
+)
+
+var ErrNotFound = errors.New("ton resource not found")
+
+type WalletInformation struct {
+	Balance    string `json:"balance"`
+	WalletType string `json:"wallet_type"`
+	Seqno      uint32 `json:"seqno"`
+	WalletId   uint32 `json:"wallet_id"`
+	Status     string `json:"status"`
+}
+
+type AddressInformation struct {
+	Balance string `json:"balance"`
+	Code    string `json:"code"`
+	Data    string `json:"data"`
+	Status  string `json:"status"`
+}
+
+type JettonWallet struct {
+	Address string `json:"address"`
+	Balance string `json:"balance"`
+	Owner   string `json:"owner"`
+	Jetton  string `json:"jetton"`
+}
+
+type EstimateFeeRequest struct {
+	Address      string `json:"address"`
+	Body         string `json:"body"`
+	InitCode     string `json:"init_code,omitempty"`
+	InitData     string `json:"init_data,omitempty"`
+	IgnoreChksig bool   `json:"ignore_chksig"`
+}
+
+type Fees struct {
+	InFwdFee   uint64 `json:"in_fwd_fee"`
+	StorageFee uint64 `json:"storage_fee"`
+	GasFee     uint64 `json:"gas_fee"`
+	FwdFee     uint64 `json:"fwd_fee"`
+}
+
+func (f *Fees) Total() uint64 {
+	return f.InFwdFee + f.StorageFee + f.GasFee + f.FwdFee
+}
+
+type EstimateFeeResult struct {
+	SourceFees      Fees   `json:"source_fees"`
+	DestinationFees []Fees `json:"destination_fees"`
+}
+
+// Message 外部入站消息的 Source 为空
+type Message struct {
+	Hash           string  `json:"hash"`
+	Source         *string `json:"source"`
+	Destination    *string `json:"destination"`
+	Value          *string `json:"value"`
+	Bounce         *bool   `json:"bounce"`
+	Bounced        *bool   `json:"bounced"`
+	CreatedLt      *string `json:"created_lt"`
+	MessageContent *struct {
+		Hash string `json:"hash"`
+		Body string `json:"body"`
+	} `json:"message_content"`
+}
+
+type Transaction struct {
+	Account      string `json:"account"`
+	Hash         string `json:"hash"`
+	Lt           string `json:"lt"`
+	Now          int64  `json:"now"`
+	McBlockSeqno uint64 `json:"mc_block_seqno"`
+	OrigStatus   string `json:"orig_status"`
+	EndStatus    string `json:"end_status"`
+	TotalFees    string `json:"total_fees"`
+	Description  struct {
+		Aborted   bool `json:"aborted"`
+		ComputePh struct {
+			Skipped  bool `json:"skipped"`
+			Success  bool `json:"success"`
+			ExitCode int  `json:"exit_code"`
+		} `json:"compute_ph"`
+		Action *struct {
+			Success    bool `json:"success"`
+			ResultCode int  `json:"result_code"`
+		} `json:"action"`
+	} `json:"description"`
+	InMsg   *Message  `json:"in_msg"`
+	OutMsgs []Message `json:"out_msgs"`
+}
+
+// Success 计算与动作阶段均成功; 计算阶段被跳过 (如无代码的账户收款) 视为成功
+func (t *Transaction) Success() bool {
+	if t.Description.Aborted {
+		return false
+	}
+	if !t.Description.ComputePh.Skipped && !t.Description.ComputePh.Success {
+		return false
+	}
+	return t.Description.Action == nil || t.Description.Action.Success
+}
+
+type Block struct {
+	Workchain int    `json:"workchain"`
+	Shard     string `json:"shard"`
+	Seqno     uint64 `json:"seqno"`
+	RootHash  string `json:"root_hash"`
+	FileHash  string `json:"file_hash"`
+	GenUtime  string `json:"gen_utime"`
+	PrevBlock []struct {
+		Workchain int    `json:"workchain"`
+		Shard     string `json:"shard"`
+		Seqno     uint64 `json:"seqno"`
+	} `json:"prev_blocks"`
+}
+
+type MasterchainInfo struct {
+	First Block `json:"first"`
+	Last  Block `json:"last"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// TonClient toncenter v3 REST 客户端, baseUrl 包含 /api/v3
+type TonClient struct {
+	baseUrl    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewTonClient(baseUrl, apiKey string, timeout time.Duration) *TonClient {
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &TonClient{
+		baseUrl:    strings.TrimRight(baseUrl, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *TonClient) do(method, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseUrl+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr apiError
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("toncenter api %s: %s", path, apiErr.Error)
+		}
+		return fmt.Errorf("toncenter api %s status %d: %s", path, resp.StatusCode, string(data))
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		log.Error("unmarshal toncenter response fail", "path", path, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (c *TonClient) get(path string, params url.Values, result interface{}) error {
+	return c.do(http.MethodGet, path+"?"+params.Encode(), nil, result)
+}
+
+// GetWalletInformation 未部署的钱包 status 为 uninit, seqno 为 0
+func (c *TonClient) GetWalletInformation(address string) (*WalletInformation, error) {
+	var info WalletInformation
+	params := url.Values{"address": {address}, "use_v2": {"false"}}
+	if err := c.get("/walletInformation", params, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (c *TonClient) GetAddressInformation(address string) (*AddressInformation, error) {
+	var info AddressInformation
+	params := url.Values{"address": {address}, "use_v2": {"false"}}
+	if err := c.get("/addressInformation", params, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetJettonWallet 查询 owner 持有 jetton (master 地址) 的钱包合约, 不存在时返回 ErrNotFound
+func (c *TonClient) GetJettonWallet(owner, jetton string) (*JettonWallet, error) {
+	params := url.Values{"owner_address": {owner}, "jetton_address": {jetton}, "limit": {"1"}}
+	return c.getJettonWallet(params)
+}
+
+// GetJettonWalletByAddress 按 jetton 钱包合约地址查询其 owner 与 master
+func (c *TonClient) GetJettonWalletByAddress(address string) (*JettonWallet, error) {
+	return c.getJettonWallet(url.Values{"address": {address}, "limit": {"1"}})
+}
+
+func (c *TonClient) getJettonWallet(params url.Values) (*JettonWallet, error) {
+	var result struct {
+		JettonWallets []JettonWallet `json:"jetton_wallets"`
+	}
+	if err := c.get("/jetton/wallets", params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.JettonWallets) == 0 {
+		return nil, ErrNotFound
+	}
+	return &result.JettonWallets[0], nil
+}
+
+// EstimateFee body 为 base64 编码的外部消息体, 钱包未部署时需提供 init_code 与 init_data
+func (c *TonClient) EstimateFee(request *EstimateFeeRequest) (*EstimateFeeResult, error) {
+	var result EstimateFeeResult
+	if err := c.do(http.MethodPost, "/estimateFee", request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SendMessage boc 为 base64 编码的外部消息, 返回消息哈希
+func (c *TonClient) SendMessage(boc string) (string, error) {
+	var result struct {
+		MessageHash string `json:"message_hash"`
+	}
+	if err := c.do(http.MethodPost, "/message", map[string]string{"boc": boc}, &result); err != nil {
+		return "", err
+	}
+	return result.MessageHash, nil
+}
+
+// GetTransactions 按账户或交易哈希查询, 按 lt 倒序
+func (c *TonClient) GetTransactions(account, hash string, limit, offset uint32) ([]Transaction, error) {
+	params := url.Values{"limit": {strconv.FormatUint(uint64(limit), 10)}, "offset": {strconv.FormatUint(uint64(offset), 10)}, "sort": {"desc"}}
+	if account != "" {
+		params.Set("account", account)
+	}
+	if hash != "" {
+		params.Set("hash", hash)
+	}
+	var result struct {
+		Transactions []Transaction `json:"transactions"`
+	}
+	if err := c.get("/transactions", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Transactions, nil
+}
+
+// GetTransactionsByMessage direction 为 in 时返回处理该消息的交易, out 时返回产生该消息的交易
+func (c *TonClient) GetTransactionsByMessage(msgHash, direction string) ([]Transaction, error) {
+	params := url.Values{"msg_hash": {msgHash}, "direction": {direction}, "limit": {"10"}}
+	var result struct {
+		Transactions []Transaction `json:"transactions"`
+	}
+	if err := c.get("/transactionsByMessage", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Transactions, nil
+}
+
+func (c *TonClient) GetMasterchainInfo() (*MasterchainInfo, error) {
+	var info MasterchainInfo
+	if err := c.get("/masterchainInfo", url.Values{}, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetMasterchainBlock 不存在时返回 ErrNotFound
+func (c *TonClient) GetMasterchainBlock(seqno uint64) (*Block, error) {
+	params := url.Values{"workchain": {strconv.Itoa(MasterchainId)}, "seqno": {strconv.FormatUint(seqno, 10)}, "limit": {"1"}}
+	var result struct {
+		Blocks []Block `json:"blocks"`
+	}
+	if err := c.get("/blocks", params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Blocks) == 0 {
+		return nil, ErrNotFound
+	}
+	return &result.Blocks[0], nil
+}
+
+// GetTransactionsByMasterchainBlock 返回 masterchain 区块及其引用的 shard 区块中的交易
+func (c *TonClient) GetTransactionsByMasterchainBlock(seqno uint64, limit, offset uint32) ([]Transaction, error) {
+	params := url.Values{
+		"seqno":  {strconv.FormatUint(seqno, 10)},
+		"limit":  {strconv.FormatUint(uint64(limit), 10)},
+		"offset": {strconv.FormatUint(uint64(offset), 10)},
+		"sort":   {"asc"},
+	}
+	var result struct {
+		Transactions []Transaction `json:"transactions"`
+	}
+	if err := c.get("/transactionsByMasterchainBlock", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Transactions, nil
+}
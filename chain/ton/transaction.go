@@ -0,0 +1,236 @@
+package ton
+
+import (
+	"errors"
+	"math/big"
+	"unicode/utf8"
+)
+
+const (
+	OpComment                  = 0x00000000
+	OpJettonTransfer           = 0x0f8a7ea5
+	OpJettonTransferNotify     = 0x7362d09c
+	OpJettonInternalTransfer   = 0x178d4519
+	OpJettonExcesses           = 0xd53276db
+	maxCommentLength           = 1024
+	commentFirstCellBytes      = (1023 - 32) / 8
+	commentContinuationBytes   = 1023 / 8
+	defaultJettonForwardAmount = 1
+)
+
+// CommentBody text_comment#00000000 text:SnakeData, 交易所以此区分充值用户
+func CommentBody(comment string) (*Cell, error) {
+	if !utf8.ValidString(comment) || len(comment) > maxCommentLength {
+		return nil, errors.New("invalid comment")
+	}
+	data := []byte(comment)
+	chunks := [][]byte{}
+	first := min(len(data), commentFirstCellBytes)
+	chunks = append(chunks, data[:first])
+	for rest := data[first:]; len(rest) > 0; {
+		n := min(len(rest), commentContinuationBytes)
+		chunks = append(chunks, rest[:n])
+		rest = rest[n:]
+	}
+	var tail *Cell
+	for i := len(chunks) - 1; i >= 0; i-- {
+		b := NewBuilder()
+		if i == 0 {
+			b.StoreUint(OpComment, 32)
+		}
+		b.StoreBytes(chunks[i])
+		if tail != nil {
+			b.StoreRef(tail)
+		}
+		var err error
+		if tail, err = b.EndCell(); err != nil {
+			return nil, err
+		}
+	}
+	return tail, nil
+}
+
+// ParseComment 非文本备注时返回 false
+func ParseComment(body *Cell) (string, bool) {
+	if body == nil {
+		return "", false
+	}
+	s := body.BeginParse()
+	op, err := s.LoadUint(32)
+	if err != nil || op != OpComment {
+		return "", false
+	}
+	return loadSnakeString(s)
+}
+
+func loadSnakeString(s *Slice) (string, bool) {
+	var data []byte
+	for {
+		if s.BitsLeft()%8 != 0 {
+			return "", false
+		}
+		chunk, err := s.LoadBytes(s.BitsLeft() / 8)
+		if err != nil {
+			return "", false
+		}
+		data = append(data, chunk...)
+		if s.RefsLeft() == 0 || len(data) > maxCommentLength {
+			break
+		}
+		next, _ := s.LoadRef()
+		s = next.BeginParse()
+	}
+	if !utf8.Valid(data) {
+		return "", false
+	}
+	return string(data), true
+}
+
+// JettonTransfer transfer#0f8a7ea5, 发送至发送方自己的 jetton 钱包合约
+type JettonTransfer struct {
+	QueryId             uint64
+	Amount              *big.Int
+	Destination         *Address
+	ResponseDestination *Address
+	ForwardTonAmount    *big.Int
+	Comment             string
+}
+
+/**
+ * @description: 构建 jetton 转账消息体
+ * forward_ton_amount 大于 0 时接收方会收到 transfer_notification, 交易所依赖该通知识别充值, 备注放在 forward_payload 中
+ */
+func (t *JettonTransfer) Body() (*Cell, error) {
+	forwardAmount := t.ForwardTonAmount
+	if forwardAmount == nil {
+		forwardAmount = big.NewInt(defaultJettonForwardAmount)
+	}
+	b := NewBuilder().StoreUint(OpJettonTransfer, 32).StoreUint(t.QueryId, 64).
+		StoreCoins(t.Amount).StoreAddress(t.Destination).StoreAddress(t.ResponseDestination).
+		StoreBit(false).StoreCoins(forwardAmount)
+	if t.Comment == "" {
+		return b.StoreBit(false).EndCell()
+	}
+	comment, err := CommentBody(t.Comment)
+	if err != nil {
+		return nil, err
+	}
+	return b.StoreBit(true).StoreRef(comment).EndCell()
+}
+
+// ParseJettonTransfer 非 jetton 转账消息体时返回 false
+func ParseJettonTransfer(body *Cell) (*JettonTransfer, bool) {
+	if body == nil {
+		return nil, false
+	}
+	s := body.BeginParse()
+	if op, err := s.LoadUint(32); err != nil || op != OpJettonTransfer {
+		return nil, false
+	}
+	transfer := &JettonTransfer{}
+	var err error
+	if transfer.QueryId, err = s.LoadUint(64); err != nil {
+		return nil, false
+	}
+	if transfer.Amount, err = s.LoadCoins(); err != nil {
+		return nil, false
+	}
+	if transfer.Destination, err = s.LoadAddress(); err != nil || transfer.Destination == nil {
+		return nil, false
+	}
+	if transfer.ResponseDestination, err = s.LoadAddress(); err != nil {
+		return nil, false
+	}
+	if _, err = s.LoadMaybeRef(); err != nil {
+		return nil, false
+	}
+	if transfer.ForwardTonAmount, err = s.LoadCoins(); err != nil {
+		return nil, false
+	}
+	transfer.Comment = loadForwardComment(s)
+	return transfer, true
+}
+
+// JettonNotification transfer_notification#7362d09c, 由接收方的 jetton 钱包发给 owner
+type JettonNotification struct {
+	QueryId uint64
+	Amount  *big.Int
+	Sender  *Address
+	Comment string
+}
+
+// ParseJettonNotification 非转账通知时返回 false
+func ParseJettonNotification(body *Cell) (*JettonNotification, bool) {
+	if body == nil {
+		return nil, false
+	}
+	s := body.BeginParse()
+	if op, err := s.LoadUint(32); err != nil || op != OpJettonTransferNotify {
+		return nil, false
+	}
+	notification := &JettonNotification{}
+	var err error
+	if notification.QueryId, err = s.LoadUint(64); err != nil {
+		return nil, false
+	}
+	if notification.Amount, err = s.LoadCoins(); err != nil {
+		return nil, false
+	}
+	if notification.Sender, err = s.LoadAddress(); err != nil {
+		return nil, false
+	}
+	notification.Comment = loadForwardComment(s)
+	return notification, true
+}
+
+// loadForwardComment forward_payload:(Either Cell ^Cell) 中的文本备注
+func loadForwardComment(s *Slice) string {
+	inRef, err := s.LoadBit()
+	if err != nil {
+		return ""
+	}
+	var payload *Slice
+	if inRef {
+		ref, err := s.LoadRef()
+		if err != nil {
+			return ""
+		}
+		payload = ref.BeginParse()
+	} else {
+		payload = s
+	}
+	if op, err := payload.LoadUint(32); err != nil || op != OpComment {
+		return ""
+	}
+	comment, _ := loadSnakeString(payload)
+	return comment
+}
+
+// TxStructure CreateUnSignTransaction 的输入, contract_address 为 jetton master 地址, 为空时转 TON
+type TxStructure struct {
+	FromAddress     string `json:"from_address"`
+	ToAddress       string `json:"to_address"`
+	Value           string `json:"value"`
+	ContractAddress string `json:"contract_address,omitempty"`
+	PublicKey       string `json:"public_key"`
+	// 文本备注, 转入交易所时用于区分充值用户
+	Memo string `json:"memo,omitempty"`
+	// 为空时从链上读取, 为 0 时随交易部署钱包
+	Seqno      string `json:"seqno,omitempty"`
+	ValidUntil uint32 `json:"valid_until,omitempty"`
+	// 为空时按目标地址格式决定, raw 地址按目标账户是否已部署决定
+	Bounce *bool `json:"bounce,omitempty"`
+	// jetton 转账附带的 TON, 用于支付 jetton 钱包的执行费用, 多余部分退回发送方
+	TonAmount string `json:"ton_amount,omitempty"`
+	// DecodeTransaction 返回的发送方 jetton 钱包地址, 构建交易时忽略
+	JettonWallet string `json:"jetton_wallet,omitempty"`
+}
+
+// UnsignedTx CreateUnSignTransaction 的返回值, 签名方对 signing_hash 签名
+type UnsignedTx struct {
+	WalletAddress  string `json:"wallet_address"`
+	PublicKey      string `json:"public_key"`
+	StateInit      string `json:"state_init,omitempty"`
+	SigningMessage string `json:"signing_message"`
+	SigningHash    string `json:"signing_hash"`
+}
@@ -0,0 +1,429 @@
+package ton
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+type WalletVersion string
+
+const (
+	WalletV3R2 WalletVersion = "v3r2"
+	WalletV4R2 WalletVersion = "v4r2"
+	WalletV5R1 WalletVersion = "v5r1"
+
+	// v3/v4 默认 subwallet id, 与官方钱包一致
+	DefaultSubwalletId = 698983191
+
+	mainnetGlobalId = -239
+	testnetGlobalId = -3
+
+	opSignedExternal = 0x7369676e
+	actionSendMsg    = 0x0ec3c86d
+
+	// pay fees separately + ignore errors, v5 外部消息要求包含 ignore errors
+	DefaultSendMode = 3
+)
+
+// 钱包合约代码 (BOC), v5r1 需通过配置提供并按代码哈希校验
+var walletCodes = map[WalletVersion]string{
+	WalletV3R2: "te6cckEBAQEAcQAA3v8AIN0gggFMl7ohggEznLqxn3Gw7UTQ0x/THzHXC//jBOCk8mCDCNcYINMf0x/TH/gjE7vyY+1E0NMf0x/T/9FRMrryoVFEuvKiBPkBVBBV+RDyo/gAkyDXSpbTB9QC+wDo0QGkyMsfyx/L/8ntVBC9ba0=",
+	WalletV4R2: "te6cckECFAEAAtQAART/APSkE/S88sgLAQIBIAIDAgFIBAUE+PKDCNcYINMf0x/THwL4I7vyZO1E0NMf0x/T//QE0VFDuvKhUVG68qIF+QFUEGT5EPKj+AAkpMjLH1JAyx9SMMv/UhD0AMntVPgPAdMHIcAAn2xRkyDXSpbTB9QC+wDoMOAhwAHjACHAAuMAAcADkTDjDQOkyMsfEssfy/8QERITAubQAdDTAyFxsJJfBOAi10nBIJJfBOAC0x8hghBwbHVnvSKCEGRzdHK9sJJfBeAD+kAwIPpEAcjKB8v/ydDtRNCBAUDXIfQEMFyBAQj0Cm+hMbOSXwfgBdM/yCWCEHBsdWe6kjgw4w0DghBkc3RyupJfBuMNBgcCASAICQB4AfoA9AQw+CdvIjBQCqEhvvLgUIIQcGx1Z4MesXCAGFAEywUmzxZY+gIZ9ADLaRfLH1Jgyz8gyYBA+wAGAIpQBIEBCPRZMO1E0IEBQNcgyAHPFvQAye1UAXKwjiOCEGRzdHKDHrFwgBhQBcsFUAPPFiP6AhPLassfyz/JgED7AJJfA+ICASAKCwBZvSQrb2omhAgKBrkPoCGEcNQICEekk30pkQzmkD6f+YN4EoAbeBAUiYcVnzGEAgFYDA0AEbjJftRNDXCx+AA9sp37UTQgQFA1yH0BDACyMoHy//J0AGBAQj0Cm+hMYAIBIA4PABmtznaiaEAga5Drhf/AABmvHfaiaEAQa5DrhY/AAG7SB/oA1NQi+QAFyMoHFcv/ydB3dIAYyMsFywIizxZQBfoCFMtrEszMyXP7AMhAFIEBCPRR8qcCAHCBAQjXGPoA0z/IVCBHgQEI9FHyp4IQbm90ZXB0gBjIywXLAlAGzxZQBPoCFMtqEssfyz/Jc/sAAgBsgQEI1xj6ANM/MFIkgQEI9Fnyp4IQZHN0cnB0gBjIywXLAlAFzxZQA/oCE8tqyx8Syz/Jc/sAAAr0AMntVGliJeU=",
+}
+
+var walletCodeHashes = map[WalletVersion]string{
+	WalletV3R2: "84dafa449f98a6987789ba232358072bc0f76dc4524002a5d0918b9a75d2d599",
+	WalletV4R2: "feb5ff6820e2ff0d9483e7e0d62c817d846789fb4ae580c878866d959dabd5c0",
+	WalletV5R1: "20834b7b72b112147e1b2fb457b84e74d1a30f04f737d4f62a668e9552d2b72f",
+}
+
+// Wallet 钱包合约, 同一公钥在不同版本与 wallet id 下对应不同地址
+type Wallet struct {
+	Version  WalletVersion
+	Code     *Cell
+	WalletId uint32
+	TestOnly bool
+}
+
+/**
+ * @description: 创建钱包
+ * codeBoc 为 base64 编码的合约代码, 为空时使用内置代码 (v3r2/v4r2), 均需与该版本的代码哈希一致
+ * subwalletId: v3/v4 为 wallet id, 为 0 时使用默认值; v5 为 subwallet number, wallet id 由网络与 workchain 计算
+ */
+func NewWallet(version WalletVersion, codeBoc string, subwalletId uint32, testOnly bool) (*Wallet, error) {
+	expectHash, ok := walletCodeHashes[version]
+	if !ok {
+		return nil, fmt.Errorf("unsupported wallet version %s", version)
+	}
+	if codeBoc == "" {
+		if codeBoc = walletCodes[version]; codeBoc == "" {
+			return nil, fmt.Errorf("wallet code of %s must be configured", version)
+		}
+	}
+	raw, err := base64.StdEncoding.DecodeString(codeBoc)
+	if err != nil {
+		return nil, err
+	}
+	code, err := ParseBoc(raw)
+	if err != nil {
+		return nil, err
+	}
+	if hex.EncodeToString(code.Hash()) != expectHash {
+		return nil, fmt.Errorf("wallet code hash mismatch for %s", version)
+	}
+	wallet := &Wallet{Version: version, Code: code, WalletId: subwalletId, TestOnly: testOnly}
+	if version == WalletV5R1 {
+		wallet.WalletId = walletIdV5(subwalletId, testOnly)
+	} else if wallet.WalletId == 0 {
+		wallet.WalletId = DefaultSubwalletId
+	}
+	return wallet, nil
+}
+
+// walletIdV5 network_global_id ^ (1 || workchain:int8 || version:uint8 || subwallet:uint15)
+func walletIdV5(subwallet uint32, testOnly bool) uint32 {
+	globalId := int32(mainnetGlobalId)
+	if testOnly {
+		globalId = testnetGlobalId
+	}
+	context := uint32(1)<<31 | uint32(uint8(BasechainId))<<23 | subwallet&0x7fff
+	return uint32(globalId) ^ context
+}
+
+func (w *Wallet) data(publicKey []byte) (*Cell, error) {
+	b := NewBuilder()
+	switch w.Version {
+	case WalletV3R2:
+		b.StoreUint(0, 32).StoreUint(uint64(w.WalletId), 32).StoreBytes(publicKey)
+	case WalletV4R2:
+		b.StoreUint(0, 32).StoreUint(uint64(w.WalletId), 32).StoreBytes(publicKey).StoreBit(false)
+	case WalletV5R1:
+		b.StoreBit(true).StoreUint(0, 32).StoreUint(uint64(w.WalletId), 32).StoreBytes(publicKey).StoreBit(false)
+	}
+	return b.EndCell()
+}
+
+// StateInit split_depth:0 special:0 code:1 data:1 library:0
+func (w *Wallet) StateInit(publicKey []byte) (*Cell, error) {
+	if len(publicKey) != 32 {
+		return nil, errors.New("invalid ed25519 public key")
+	}
+	data, err := w.data(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewBuilder().StoreUint(0b00110, 5).StoreRef(w.Code).StoreRef(data).EndCell()
+}
+
+func (w *Wallet) Address(publicKey []byte) (*Address, error) {
+	stateInit, err := w.StateInit(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	address := &Address{Workchain: BasechainId}
+	copy(address.Hash[:], stateInit.Hash())
+	return address, nil
+}
+
+// OutMessage 钱包发出的内部消息
+type OutMessage struct {
+	Destination *Address
+	Amount      *big.Int
+	Bounce      bool
+	Body        *Cell
+	Mode        uint8
+}
+
+// cell int_msg_info$0 ihr_disabled:1 bounce bounced:0 src:addr_none dest value ihr_fee:0 fwd_fee:0 created_lt:0 created_at:0 init:nothing body:^Cell
+func (m *OutMessage) cell() (*Cell, error) {
+	b := NewBuilder().StoreBit(false).StoreBit(true).StoreBit(m.Bounce).StoreBit(false)
+	b.StoreAddress(nil).StoreAddress(m.Destination)
+	b.StoreCoins(m.Amount).StoreBit(false)
+	b.StoreCoins(big.NewInt(0)).StoreCoins(big.NewInt(0))
+	b.StoreUint(0, 64).StoreUint(0, 32).StoreBit(false)
+	if m.Body == nil {
+		return b.StoreBit(false).EndCell()
+	}
+	return b.StoreBit(true).StoreRef(m.Body).EndCell()
+}
+
+/**
+ * @description: 构建待签名消息, 签名对象为其 cell hash
+ * seqno 为 0 (钱包未部署) 时 valid_until 为全 1
+ */
+func (w *Wallet) SigningMessage(seqno, validUntil uint32, messages []OutMessage) (*Cell, error) {
+	if len(messages) == 0 || len(messages) > 4 {
+		return nil, errors.New("wallet supports 1 to 4 messages")
+	}
+	if seqno == 0 {
+		validUntil = 0xffffffff
+	}
+	cells := make([]*Cell, len(messages))
+	for i := range messages {
+		cell, err := messages[i].cell()
+		if err != nil {
+			return nil, err
+		}
+		cells[i] = cell
+	}
+	b := NewBuilder()
+	switch w.Version {
+	case WalletV3R2, WalletV4R2:
+		b.StoreUint(uint64(w.WalletId), 32).StoreUint(uint64(validUntil), 32).StoreUint(uint64(seqno), 32)
+		if w.Version == WalletV4R2 {
+			b.StoreUint(0, 8)
+		}
+		for i := range messages {
+			b.StoreUint(uint64(messages[i].Mode), 8).StoreRef(cells[i])
+		}
+	case WalletV5R1:
+		// out_list$_ prev:^OutList action_send_msg#0ec3c86d mode:uint8 out_msg:^MessageRelaxed
+		actions, err := NewBuilder().EndCell()
+		if err != nil {
+			return nil, err
+		}
+		for i := range messages {
+			actions, err = NewBuilder().StoreRef(actions).StoreUint(actionSendMsg, 32).
+				StoreUint(uint64(messages[i].Mode), 8).StoreRef(cells[i]).EndCell()
+			if err != nil {
+				return nil, err
+			}
+		}
+		b.StoreUint(opSignedExternal, 32).StoreUint(uint64(w.WalletId), 32).
+			StoreUint(uint64(validUntil), 32).StoreUint(uint64(seqno), 32).
+			StoreMaybeRef(actions).StoreBit(false)
+	}
+	return b.EndCell()
+}
+
+// SignedBody 外部消息体, v3/v4 签名位于开头, v5 位于末尾
+func (w *Wallet) SignedBody(signingMessage *Cell, signature []byte) (*Cell, error) {
+	if len(signature) != 64 {
+		return nil, errors.New("invalid ed25519 signature")
+	}
+	body := NewBuilder()
+	if w.Version == WalletV5R1 {
+		body.StoreCell(signingMessage).StoreBytes(signature)
+	} else {
+		body.StoreBytes(signature).StoreCell(signingMessage)
+	}
+	return body.EndCell()
+}
+
+// ExternalMessage ext_in_msg_info$10 src:addr_none dest import_fee:0 init:Maybe ^StateInit body:^Cell
+func (w *Wallet) ExternalMessage(destination *Address, stateInit, signingMessage *Cell, signature []byte) (*Cell, error) {
+	bodyCell, err := w.SignedBody(signingMessage, signature)
+	if err != nil {
+		return nil, err
+	}
+	b := NewBuilder().StoreUint(0b10, 2).StoreAddress(nil).StoreAddress(destination).StoreCoins(big.NewInt(0))
+	if stateInit != nil {
+		b.StoreBit(true).StoreBit(true).StoreRef(stateInit)
+	} else {
+		b.StoreBit(false)
+	}
+	return b.StoreBit(true).StoreRef(bodyCell).EndCell()
+}
+
+/**
+ * @description: 解析 ExternalMessage 构建的外部消息
+ * 返回钱包地址, 待签名消息, 签名与内部消息列表
+ */
+func (w *Wallet) ParseExternalMessage(message *Cell) (*Address, *Cell, []byte, []*Cell, error) {
+	s := message.BeginParse()
+	tag, err := s.LoadUint(2)
+	if err != nil || tag != 0b10 {
+		return nil, nil, nil, nil, errors.New("not an external in message")
+	}
+	if _, err := s.LoadAddress(); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	destination, err := s.LoadAddress()
+	if err != nil || destination == nil {
+		return nil, nil, nil, nil, errors.New("invalid destination")
+	}
+	if _, err := s.LoadCoins(); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	hasInit, err := s.LoadBit()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if hasInit {
+		if inRef, err := s.LoadBit(); err != nil || !inRef {
+			return nil, nil, nil, nil, errors.New("inline state init is not supported")
+		}
+		if _, err := s.LoadRef(); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
+	if bodyInRef, err := s.LoadBit(); err != nil || !bodyInRef {
+		return nil, nil, nil, nil, errors.New("inline body is not supported")
+	}
+	body, err := s.LoadRef()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	bs := body.BeginParse()
+	var signature []byte
+	payload := NewBuilder()
+	if w.Version == WalletV5R1 {
+		if bs.BitsLeft() < 512 {
+			return nil, nil, nil, nil, errCellUnderflow
+		}
+		for bs.BitsLeft() > 512 {
+			bit, _ := bs.LoadBit()
+			payload.StoreBit(bit)
+		}
+		signature, _ = bs.LoadBytes(64)
+	} else {
+		if signature, err = bs.LoadBytes(64); err != nil {
+			return nil, nil, nil, nil, err
+		}
+		for bs.BitsLeft() > 0 {
+			bit, _ := bs.LoadBit()
+			payload.StoreBit(bit)
+		}
+	}
+	for bs.RefsLeft() > 0 {
+		ref, _ := bs.LoadRef()
+		payload.StoreRef(ref)
+	}
+	signingMessage, err := payload.EndCell()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	messages, err := w.outMessages(signingMessage)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return destination, signingMessage, signature, messages, nil
+}
+
+// outMessages 从待签名消息中取出内部消息
+func (w *Wallet) outMessages(signingMessage *Cell) ([]*Cell, error) {
+	s := signingMessage.BeginParse()
+	var messages []*Cell
+	switch w.Version {
+	case WalletV3R2, WalletV4R2:
+		headerBits := 96
+		if w.Version == WalletV4R2 {
+			headerBits += 8
+		}
+		if _, err := s.LoadBigUint(headerBits); err != nil {
+			return nil, err
+		}
+		for s.RefsLeft() > 0 {
+			if _, err := s.LoadUint(8); err != nil {
+				return nil, err
+			}
+			message, _ := s.LoadRef()
+			messages = append(messages, message)
+		}
+	case WalletV5R1:
+		opcode, err := s.LoadUint(32)
+		if err != nil || opcode != opSignedExternal {
+			return nil, errors.New("invalid v5 signed message")
+		}
+		if _, err := s.LoadBigUint(96); err != nil {
+			return nil, err
+		}
+		actions, err := s.LoadMaybeRef()
+		if err != nil {
+			return nil, err
+		}
+		for actions != nil && actions.BitLen() > 0 {
+			as := actions.BeginParse()
+			prev, err := as.LoadRef()
+			if err != nil {
+				return nil, err
+			}
+			if action, err := as.LoadUint(32); err != nil || action != actionSendMsg {
+				return nil, errors.New("unsupported v5 out action")
+			}
+			if _, err := as.LoadUint(8); err != nil {
+				return nil, err
+			}
+			message, err := as.LoadRef()
+			if err != nil {
+				return nil, err
+			}
+			messages = append([]*Cell{message}, messages...)
+			actions = prev
+		}
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("no out message")
+	}
+	return messages, nil
+}
+
+// ParseInternalMessage 解析 OutMessage 构建的内部消息
+func ParseInternalMessage(message *Cell) (*OutMessage, error) {
+	s := message.BeginParse()
+	if tag, err := s.LoadBit(); err != nil || tag {
+		return nil, errors.New("not an internal message")
+	}
+	if _, err := s.LoadBit(); err != nil {
+		return nil, err
+	}
+	bounce, err := s.LoadBit()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.LoadBit(); err != nil {
+		return nil, err
+	}
+	if _, err := s.LoadAddress(); err != nil {
+		return nil, err
+	}
+	destination, err := s.LoadAddress()
+	if err != nil || destination == nil {
+		return nil, errors.New("invalid destination")
+	}
+	amount, err := s.LoadCoins()
+	if err != nil {
+		return nil, err
+	}
+	if hasExtra, err := s.LoadBit(); err != nil || hasExtra {
+		return nil, errors.New("extra currencies are not supported")
+	}
+	if _, err := s.LoadCoins(); err != nil {
+		return nil, err
+	}
+	if _, err := s.LoadCoins(); err != nil {
+		return nil, err
+	}
+	if _, err := s.LoadBigUint(96); err != nil {
+		return nil, err
+	}
+	if hasInit, err := s.LoadBit(); err != nil || hasInit {
+		return nil, errors.New("internal message with state init is not supported")
+	}
+	out := &OutMessage{Destination: destination, Amount: amount, Bounce: bounce}
+	bodyInRef, err := s.LoadBit()
+	if err != nil {
+		return out, nil
+	}
+	if bodyInRef {
+		out.Body, err = s.LoadRef()
+		return out, err
+	}
+	body := NewBuilder()
+	for s.BitsLeft() > 0 {
+		bit, _ := s.LoadBit()
+		body.StoreBit(bit)
+	}
+	for s.RefsLeft() > 0 {
+		ref, _ := s.LoadRef()
+		body.StoreRef(ref)
+	}
+	if out.Body, err = body.EndCell(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,274 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// DefaultDepth 默认记录最近的区块数, 也是查找分叉点时最多回溯的区块数
+const DefaultDepth = 256
+
+var (
+	// ErrBrokenChain 同一批区块头的 ParentHash 不连续, 通常是节点正在切换分叉, 稍后重试即可
+	ErrBrokenChain = errors.New("block headers are not linked by parent hash")
+	// ErrForkTooDeep 回溯超过记录深度仍未找到分叉点
+	ErrForkTooDeep = errors.New("fork point deeper than tracked depth")
+)
+
+// Header 与链无关的区块头, 只保留校验连续性所需的字段
+type Header struct {
+	Number     uint64
+	Hash       string
+	ParentHash string
+}
+
+// HeaderSource 适配器提供的区块头查询, 按哈希查询需能返回已被重组掉的区块 (节点通常会保留一段时间)
+type HeaderSource interface {
+	HeaderByNumber(number uint64) (*Header, error)
+	HeaderByHash(hash string) (*Header, error)
+}
+
+// Reorg 重组结果, Orphaned 为被重组掉的区块哈希, 按高度升序
+type Reorg struct {
+	ForkHeight uint64
+	ForkHash   string
+	Orphaned   []string
+}
+
+/**
+ * @description: 链追踪器, 每个适配器一个实例
+ * 记录最近 depth 个高度的规范链区块头与见过的所有区块头 (含孤块), 校验节点返回的区块头是否首尾相连,
+ * 与已记录的区块不一致时判定为重组并回溯分叉点
+ */
+type Tracker struct {
+	source HeaderSource
+	depth  uint64
+
+	lock sync.Mutex
+	//高度 -> 规范链区块哈希
+	canonical map[uint64]string
+	//哈希 -> 区块头, 包含被重组掉的区块
+	headers map[string]*Header
+	tip     uint64
+}
+
+func New(source HeaderSource, depth uint64) *Tracker {
+	if depth == 0 {
+		depth = DefaultDepth
+	}
+	return &Tracker{
+		source:    source,
+		depth:     depth,
+		canonical: make(map[uint64]string),
+		headers:   make(map[string]*Header),
+	}
+}
+
+/**
+ * @description: 记录节点返回的按高度升序的连续区块头
+ * 批内 ParentHash 不连续时返回 ErrBrokenChain 且不记录; 与已记录的规范链冲突时判定为重组,
+ * 以新区块为准更新记录并返回重组信息, 否则返回 nil
+ * @param headers 区块头
+ */
+func (t *Tracker) Observe(headers ...*Header) (*Reorg, error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	if err := VerifyLinked(headers); err != nil {
+		return nil, err
+	}
+	//第一个与记录冲突的区块, 包括其父区块与记录不一致的情况
+	var conflict *Header
+	t.lock.Lock()
+	for _, header := range headers {
+		if hash, ok := t.canonical[header.Number]; ok && hash != header.Hash {
+			conflict = header
+			break
+		}
+		if header.Number == 0 {
+			continue
+		}
+		if parent, ok := t.canonical[header.Number-1]; ok && parent != header.ParentHash {
+			conflict = header
+			break
+		}
+	}
+	t.lock.Unlock()
+
+	var reorg *Reorg
+	if conflict != nil {
+		var err error
+		reorg, err = t.forkFromNew(conflict)
+		if err != nil {
+			return nil, err
+		}
+		log.Warn("chain reorg detected", "forkHeight", reorg.ForkHeight, "forkHash", reorg.ForkHash, "orphaned", len(reorg.Orphaned))
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if reorg != nil {
+		//分叉点之上的旧记录作废, 由本批与之后的区块头重新填充
+		for height := reorg.ForkHeight + 1; height <= t.tip; height++ {
+			delete(t.canonical, height)
+		}
+		t.tip = reorg.ForkHeight
+	}
+	for _, header := range headers {
+		t.record(header)
+	}
+	return reorg, nil
+}
+
+/**
+ * @description: 判断调用方记录的 height 高度区块 hash 是否仍在规范链上, 不在时回溯分叉点
+ * 旧链区块优先从追踪器的记录中查找, 找不到时向节点按哈希查询
+ * @param height 调用方记录的区块高度
+ * @param hash 调用方记录的区块哈希
+ * @return reorg 未发生重组时为 nil
+ * @return canonical 当前规范链在 height 高度的区块
+ */
+func (t *Tracker) FindFork(height uint64, hash string) (*Reorg, *Header, error) {
+	canonical, err := t.source.HeaderByNumber(height)
+	if err != nil {
+		return nil, nil, err
+	}
+	if canonical.Hash == hash {
+		if _, err := t.Observe(canonical); err != nil {
+			return nil, nil, err
+		}
+		return nil, canonical, nil
+	}
+	stale, err := t.header(hash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("lookup block %s: %w", hash, err)
+	}
+	if stale.Number != height {
+		return nil, nil, fmt.Errorf("block %s is at height %d, not %d", hash, stale.Number, height)
+	}
+	reorg, err := t.forkFromOld(stale)
+	if err != nil {
+		return nil, nil, err
+	}
+	//分叉点之上的记录可能来自旧链, 全部作废
+	t.lock.Lock()
+	for h := reorg.ForkHeight + 1; h <= t.tip; h++ {
+		delete(t.canonical, h)
+	}
+	if t.tip > reorg.ForkHeight {
+		t.tip = reorg.ForkHeight
+	}
+	t.record(canonical)
+	t.lock.Unlock()
+	return reorg, canonical, nil
+}
+
+/**
+ * @description: 从新链区块沿 ParentHash 回溯, 直到父区块与记录的规范链一致 (或该高度无记录), 即为分叉点
+ * 孤块为记录中分叉点之上的规范链区块
+ * @param from 新链上第一个与记录冲突的区块
+ */
+func (t *Tracker) forkFromNew(from *Header) (*Reorg, error) {
+	cur := from
+	for i := uint64(0); i <= t.depth && cur.Number > 0; i++ {
+		t.lock.Lock()
+		recorded, ok := t.canonical[cur.Number-1]
+		t.lock.Unlock()
+		if !ok || recorded == cur.ParentHash {
+			reorg := &Reorg{ForkHeight: cur.Number - 1, ForkHash: cur.ParentHash}
+			t.lock.Lock()
+			for height := cur.Number; height <= t.tip; height++ {
+				if hash, ok := t.canonical[height]; ok {
+					reorg.Orphaned = append(reorg.Orphaned, hash)
+				}
+			}
+			t.lock.Unlock()
+			return reorg, nil
+		}
+		parent, err := t.header(cur.ParentHash)
+		if err != nil {
+			return nil, fmt.Errorf("lookup block %s: %w", cur.ParentHash, err)
+		}
+		cur = parent
+	}
+	return nil, ErrForkTooDeep
+}
+
+/**
+ * @description: 从旧链区块沿 ParentHash 回溯, 直到父区块在节点当前的规范链上, 即为分叉点
+ * 孤块为回溯经过的旧链区块
+ * @param from 已不在规范链上的区块
+ */
+func (t *Tracker) forkFromOld(from *Header) (*Reorg, error) {
+	var orphaned []string
+	cur := from
+	for i := uint64(0); i <= t.depth && cur.Number > 0; i++ {
+		orphaned = append([]string{cur.Hash}, orphaned...)
+		canonical, err := t.source.HeaderByNumber(cur.Number - 1)
+		if err != nil {
+			return nil, err
+		}
+		if canonical.Hash == cur.ParentHash {
+			return &Reorg{ForkHeight: canonical.Number, ForkHash: canonical.Hash, Orphaned: orphaned}, nil
+		}
+		parent, err := t.header(cur.ParentHash)
+		if err != nil {
+			return nil, fmt.Errorf("lookup block %s: %w", cur.ParentHash, err)
+		}
+		cur = parent
+	}
+	return nil, ErrForkTooDeep
+}
+
+func (t *Tracker) header(hash string) (*Header, error) {
+	t.lock.Lock()
+	header, ok := t.headers[hash]
+	t.lock.Unlock()
+	if ok {
+		return header, nil
+	}
+	header, err := t.source.HeaderByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	t.lock.Lock()
+	t.headers[header.Hash] = header
+	t.lock.Unlock()
+	return header, nil
+}
+
+// record 调用方需持有锁
+func (t *Tracker) record(header *Header) {
+	t.canonical[header.Number] = header.Hash
+	t.headers[header.Hash] = header
+	if header.Number <= t.tip {
+		return
+	}
+	t.tip = header.Number
+	if t.tip < t.depth {
+		return
+	}
+	floor := t.tip - t.depth
+	for height := range t.canonical {
+		if height < floor {
+			delete(t.canonical, height)
+		}
+	}
+	for hash, h := range t.headers {
+		if h.Number < floor {
+			delete(t.headers, hash)
+		}
+	}
+}
+
+// VerifyLinked 校验按高度升序的区块头首尾相连
+func VerifyLinked(headers []*Header) error {
+	for i := 1; i < len(headers); i++ {
+		if headers[i].Number != headers[i-1].Number+1 || headers[i].ParentHash != headers[i-1].Hash {
+			return fmt.Errorf("%w: height %d parent %s, previous %s", ErrBrokenChain, headers[i].Number, headers[i].ParentHash, headers[i-1].Hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testSource 节点的模拟, canonical 为当前规范链, all 包含所有见过的区块
+type testSource struct {
+	canonical map[uint64]*Header
+	all       map[string]*Header
+}
+
+func newTestSource() *testSource {
+	return &testSource{canonical: make(map[uint64]*Header), all: make(map[string]*Header)}
+}
+
+func (s *testSource) HeaderByNumber(number uint64) (*Header, error) {
+	if header, ok := s.canonical[number]; ok {
+		return header, nil
+	}
+	return nil, fmt.Errorf("block %d not found", number)
+}
+
+func (s *testSource) HeaderByHash(hash string) (*Header, error) {
+	if header, ok := s.all[hash]; ok {
+		return header, nil
+	}
+	return nil, fmt.Errorf("block %s not found", hash)
+}
+
+/**
+ * @description: 在 parent 之上生成分支 branch 的区块 from..to 并设为规范链, 哈希为 branch-高度
+ */
+func (s *testSource) extend(branch string, parent string, from, to uint64) []*Header {
+	var headers []*Header
+	for number := from; number <= to; number++ {
+		header := &Header{Number: number, Hash: fmt.Sprintf("%s-%d", branch, number), ParentHash: parent}
+		s.canonical[number] = header
+		s.all[header.Hash] = header
+		headers = append(headers, header)
+		parent = header.Hash
+	}
+	return headers
+}
+
+func TestObserveLinear(t *testing.T) {
+	s := newTestSource()
+	tr := New(s, 0)
+	chain := s.extend("a", "genesis", 1, 10)
+	for i := 0; i < len(chain); i += 3 {
+		reorg, err := tr.Observe(chain[i:min(i+3, len(chain))]...)
+		if err != nil || reorg != nil {
+			t.Fatalf("Observe batch %d: reorg %+v, err %v", i, reorg, err)
+		}
+	}
+	//重复观察同一区块不视为重组
+	if reorg, err := tr.Observe(chain[4]); err != nil || reorg != nil {
+		t.Errorf("Observe again: reorg %+v, err %v", reorg, err)
+	}
+}
+
+func TestObserveBrokenChain(t *testing.T) {
+	s := newTestSource()
+	tr := New(s, 0)
+	a := s.extend("a", "genesis", 1, 3)
+	b := s.extend("b", "a-1", 2, 3)
+	if _, err := tr.Observe(a[0], b[0], a[2]); !errors.Is(err, ErrBrokenChain) {
+		t.Fatalf("Observe unlinked batch: err = %v, want ErrBrokenChain", err)
+	}
+	if _, err := tr.Observe(a[0], a[2]); !errors.Is(err, ErrBrokenChain) {
+		t.Fatalf("Observe batch with missing height: err = %v, want ErrBrokenChain", err)
+	}
+	//不连续的批次不应被记录
+	if reorg, err := tr.Observe(a...); err != nil || reorg != nil {
+		t.Errorf("Observe after broken batch: reorg %+v, err %v", reorg, err)
+	}
+}
+
+func TestObserveReorg(t *testing.T) {
+	tests := []struct {
+		name string
+		// 新分支中交给 Observe 的第一个高度, 更低的新区块需向节点查询
+		observeFrom uint64
+	}{
+		{"batch starts at fork", 4},
+		{"batch starts above fork", 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSource()
+			tr := New(s, 0)
+			if _, err := tr.Observe(s.extend("a", "genesis", 1, 6)...); err != nil {
+				t.Fatal(err)
+			}
+			b := s.extend("b", "a-3", 4, 7)
+			reorg, err := tr.Observe(b[tt.observeFrom-4:]...)
+			if err != nil {
+				t.Fatalf("Observe: %v", err)
+			}
+			want := &Reorg{ForkHeight: 3, ForkHash: "a-3", Orphaned: []string{"a-4", "a-5", "a-6"}}
+			if !reflect.DeepEqual(reorg, want) {
+				t.Errorf("reorg = %+v, want %+v", reorg, want)
+			}
+			//新链成为规范链, 继续观察不再报告重组
+			if reorg, err := tr.Observe(s.extend("b", "b-7", 8, 8)...); err != nil || reorg != nil {
+				t.Errorf("Observe after reorg: reorg %+v, err %v", reorg, err)
+			}
+		})
+	}
+}
+
+func TestFindFork(t *testing.T) {
+	s := newTestSource()
+	tr := New(s, 0)
+	if _, err := tr.Observe(s.extend("a", "genesis", 1, 6)...); err != nil {
+		t.Fatal(err)
+	}
+
+	reorg, canonical, err := tr.FindFork(5, "a-5")
+	if err != nil || reorg != nil || canonical.Hash != "a-5" {
+		t.Fatalf("FindFork on canonical block: reorg %+v, canonical %+v, err %v", reorg, canonical, err)
+	}
+
+	s.extend("b", "a-2", 3, 7)
+	reorg, canonical, err = tr.FindFork(5, "a-5")
+	if err != nil {
+		t.Fatalf("FindFork: %v", err)
+	}
+	want := &Reorg{ForkHeight: 2, ForkHash: "a-2", Orphaned: []string{"a-3", "a-4", "a-5"}}
+	if !reflect.DeepEqual(reorg, want) {
+		t.Errorf("reorg = %+v, want %+v", reorg, want)
+	}
+	if canonical.Hash != "b-5" {
+		t.Errorf("canonical = %+v, want b-5", canonical)
+	}
+	if _, _, err := tr.FindFork(4, "a-5"); err == nil {
+		t.Error("FindFork with a hash from another height succeeded")
+	}
+}
+
+func TestForkTooDeep(t *testing.T) {
+	s := newTestSource()
+	tr := New(s, 2)
+	if _, err := tr.Observe(s.extend("a", "genesis", 1, 10)...); err != nil {
+		t.Fatal(err)
+	}
+	s.extend("b", "a-2", 3, 10)
+	if _, _, err := tr.FindFork(10, "a-10"); !errors.Is(err, ErrForkTooDeep) {
+		t.Errorf("FindFork deep fork: err = %v, want ErrForkTooDeep", err)
+	}
+}
+
+func TestVerifyLinked(t *testing.T) {
+	s := newTestSource()
+	chain := s.extend("a", "genesis", 1, 3)
+	if err := VerifyLinked(chain); err != nil {
+		t.Errorf("VerifyLinked: %v", err)
+	}
+	if err := VerifyLinked(nil); err != nil {
+		t.Errorf("VerifyLinked(nil): %v", err)
+	}
+	if err := VerifyLinked([]*Header{chain[1], chain[0]}); !errors.Is(err, ErrBrokenChain) {
+		t.Errorf("VerifyLinked descending: err = %v", err)
+	}
+}
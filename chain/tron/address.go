@@ -0,0 +1,65 @@
+package tron
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/CavnHan/wallet-chain-account/common/base58"
+)
+
+const (
+	// Tron 主网地址前缀, hex 形式地址以 41 开头
+	AddressPrefix byte = 0x41
+	addressLength      = 21
+)
+
+var errInvalidAddress = errors.New("invalid tron address")
+
+// AddressToBytes 解析 base58check 或 41 开头的 hex 地址, 返回 21 字节地址
+func AddressToBytes(address string) ([]byte, error) {
+	var raw []byte
+	if strings.HasPrefix(address, "T") {
+		decoded, err := base58.CheckDecode(address)
+		if err != nil {
+			return nil, err
+		}
+		raw = decoded
+	} else {
+		decoded, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+		if err != nil {
+			return nil, errInvalidAddress
+		}
+		raw = decoded
+	}
+	if len(raw) != addressLength || raw[0] != AddressPrefix {
+		return nil, errInvalidAddress
+	}
+	return raw, nil
+}
+
+// Base58ToHex base58check 地址转为 41 开头的 hex 地址
+func Base58ToHex(address string) (string, error) {
+	raw, err := AddressToBytes(address)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// HexToBase58 41 开头的 hex 地址转为 base58check 地址
+func HexToBase58(address string) (string, error) {
+	raw, err := AddressToBytes(address)
+	if err != nil {
+		return "", err
+	}
+	return base58.CheckEncode(raw), nil
+}
+
+// BytesToBase58 21 字节地址转为 base58check 地址, 20 字节时补齐 41 前缀
+func BytesToBase58(raw []byte) string {
+	if len(raw) == addressLength-1 {
+		raw = append([]byte{AddressPrefix}, raw...)
+	}
+	return base58.CheckEncode(raw)
+}
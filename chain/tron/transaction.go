@@ -0,0 +1,220 @@
+package tron
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	trc20TransferSelector = "transfer(address,uint256)"
+	trc20BalanceSelector  = "balanceOf(address)"
+
+	// protocol.Transaction.Contract.ContractType
+	transferContractType     = 1
+	triggerSmartContractType = 31
+
+	defaultFeeLimit = 100_000_000
+)
+
+var trc20TransferMethodId = []byte{0xa9, 0x05, 0x9c, 0xbb}
+
+// TxStructure CreateUnSignTransaction 入参及 DecodeTransaction 出参, 以 base64(json) 传递
+// ContractAddress 为空时为 TRX 转账, Value 单位为 sun 或代币最小单位
+type TxStructure struct {
+	ContractAddress string `json:"contract_address"`
+	FromAddress     string `json:"from_address"`
+	ToAddress       string `json:"to_address"`
+	Value           string `json:"value"`
+	FeeLimit        int64  `json:"fee_limit,omitempty"`
+	TxID            string `json:"tx_id,omitempty"`
+}
+
+// abiAddress 将地址编码为 32 字节 ABI 参数, 去掉 41 前缀
+func abiAddress(address string) (string, error) {
+	raw, err := AddressToBytes(address)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes.Repeat([]byte{0}, 12)) + hex.EncodeToString(raw[1:]), nil
+}
+
+func abiUint256(value *big.Int) string {
+	return fmt.Sprintf("%064x", value)
+}
+
+// trc20TransferParameter 构造 transfer(address,uint256) 参数
+func trc20TransferParameter(to string, amount *big.Int) (string, error) {
+	addr, err := abiAddress(to)
+	if err != nil {
+		return "", err
+	}
+	return addr + abiUint256(amount), nil
+}
+
+// verifyTxID 校验 txID 与 raw_data 一致, 避免签名被挂到篡改过的交易上
+func verifyTxID(tx *Transaction) ([]byte, error) {
+	rawData, err := hex.DecodeString(tx.RawDataHex)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(rawData)
+	if hex.EncodeToString(hash[:]) != tx.TxID {
+		return nil, errors.New("txID does not match raw_data")
+	}
+	return rawData, nil
+}
+
+// encodeSignedTransaction 按 protocol.Transaction 编码: raw_data = 1, signature = 2
+func encodeSignedTransaction(rawData []byte, signatures ...[]byte) []byte {
+	var buf []byte
+	buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+	buf = protowire.AppendBytes(buf, rawData)
+	for _, sig := range signatures {
+		buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, sig)
+	}
+	return buf
+}
+
+// decodeSignedTransaction 拆出 raw_data 与签名
+func decodeSignedTransaction(data []byte) ([]byte, [][]byte, error) {
+	var rawData []byte
+	var signatures [][]byte
+	err := rangeFields(data, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			rawData = value
+		case num == 2 && typ == protowire.BytesType:
+			signatures = append(signatures, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if rawData == nil {
+		return nil, nil, errors.New("transaction raw_data missing")
+	}
+	return rawData, signatures, nil
+}
+
+// decodeRawData 解析 raw_data 中第一个合约, 支持 TRX 转账与 TRC-20 transfer
+func decodeRawData(rawData []byte) (*TxStructure, error) {
+	var contract []byte
+	var feeLimit uint64
+	err := rangeFields(rawData, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch {
+		case num == 11 && typ == protowire.BytesType && contract == nil:
+			contract = value
+		case num == 18 && typ == protowire.VarintType:
+			feeLimit = varint
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if contract == nil {
+		return nil, errors.New("transaction contract missing")
+	}
+
+	var contractType uint64
+	var parameter []byte
+	err = rangeFields(contract, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			contractType = varint
+		case num == 2 && typ == protowire.BytesType:
+			// google.protobuf.Any: type_url = 1, value = 2
+			return rangeFields(value, func(n protowire.Number, t protowire.Type, v []byte, _ uint64) error {
+				if n == 2 && t == protowire.BytesType {
+					parameter = v
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	txStructure := &TxStructure{FeeLimit: int64(feeLimit)}
+	switch contractType {
+	case transferContractType:
+		err = rangeFields(parameter, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+			switch num {
+			case 1:
+				txStructure.FromAddress = BytesToBase58(value)
+			case 2:
+				txStructure.ToAddress = BytesToBase58(value)
+			case 3:
+				txStructure.Value = new(big.Int).SetUint64(varint).String()
+			}
+			return nil
+		})
+	case triggerSmartContractType:
+		var data []byte
+		err = rangeFields(parameter, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+			switch num {
+			case 1:
+				txStructure.FromAddress = BytesToBase58(value)
+			case 2:
+				txStructure.ContractAddress = BytesToBase58(value)
+			case 4:
+				data = value
+			}
+			return nil
+		})
+		if err == nil {
+			if len(data) != 68 || !bytes.Equal(data[:4], trc20TransferMethodId) {
+				return nil, errors.New("unsupported smart contract call")
+			}
+			txStructure.ToAddress = BytesToBase58(data[16:36])
+			txStructure.Value = new(big.Int).SetBytes(data[36:68]).String()
+		}
+	default:
+		return nil, fmt.Errorf("unsupported contract type %d", contractType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(rawData)
+	txStructure.TxID = hex.EncodeToString(hash[:])
+	return txStructure, nil
+}
+
+// rangeFields 依次回调 protobuf 字段, bytes 类型传 value, varint 类型传 varint
+func rangeFields(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		var value []byte
+		var varint uint64
+		switch typ {
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(data)
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := fn(num, typ, value, varint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
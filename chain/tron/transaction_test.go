@@ -0,0 +1,138 @@
+package tron
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// 测试向量按 protocol.Transaction.raw 编码: ref_block 5f6d, 过期时间 1700000060000, 时间戳 1700000000000
+// owner 为私钥 1 对应的地址
+const (
+	ownerAddress = "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC"
+	toAddress    = "T9yD14Nj9j7xAB4dbGeiX9h8unkeStP68h"
+	usdtAddress  = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+
+	// TransferContract: 1.5 TRX
+	trxRawData = "0a025f6d2208f4e8d1d24d7fd4ab40e0a499ffbc315a67080112630a2d747970652e676f6f676c65617069732e636f6d2f70726f746f636f6c2e5472616e73666572436f6e747261637412320a15417e5f4552091a69125d5dfcb7b8c2659029395bdf12154100000000000000000000000000000000000000aa18e0c65b7080d095ffbc31"
+	trxTxID    = "61638abb89cacea029a41795bffeb5a6f35f3efa010e5e8a1c7be9409498258e"
+
+	// TriggerSmartContract: USDT transfer 1234567, fee_limit 100 TRX
+	trc20RawData = "0a025f6d2208f4e8d1d24d7fd4ab40e0a499ffbc315aae01081f12a9010a31747970652e676f6f676c65617069732e636f6d2f70726f746f636f6c2e54726967676572536d617274436f6e747261637412740a15417e5f4552091a69125d5dfcb7b8c2659029395bdf121541a614f803b6fd780986a42c78ec9c7f77e6ded13c2244a9059cbb00000000000000000000000000000000000000000000000000000000000000aa000000000000000000000000000000000000000000000000000000000012d6877080d095ffbc31900180c2d72f"
+	trc20TxID    = "e0b5af16c0c6d2e0506fc1bb2e4410557a41e04e6d22cfd90918e8e7481c348c"
+)
+
+func TestDecodeRawData(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawData string
+		want    TxStructure
+		wantErr string
+	}{
+		{
+			name:    "trx transfer",
+			rawData: trxRawData,
+			want:    TxStructure{FromAddress: ownerAddress, ToAddress: toAddress, Value: "1500000", TxID: trxTxID},
+		},
+		{
+			name:    "trc20 transfer",
+			rawData: trc20RawData,
+			want: TxStructure{ContractAddress: usdtAddress, FromAddress: ownerAddress, ToAddress: toAddress,
+				Value: "1234567", FeeLimit: 100_000_000, TxID: trc20TxID},
+		},
+		{
+			//approve(address,uint256)
+			name:    "unsupported call",
+			rawData: strings.Replace(trc20RawData, "a9059cbb", "095ea7b3", 1),
+			wantErr: "unsupported smart contract call",
+		},
+		{
+			name:    "contract missing",
+			rawData: "0a025f6d",
+			wantErr: "transaction contract missing",
+		},
+		{
+			name:    "truncated",
+			rawData: trxRawData[:len(trxRawData)-20],
+			wantErr: "unexpected EOF",
+		},
+	}
+	for _, tt := range tests {
+		got, err := decodeRawDataHex(tt.rawData)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, *got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTxID(t *testing.T) {
+	tests := []struct {
+		name    string
+		tx      Transaction
+		wantErr bool
+	}{
+		{"trx", Transaction{TxID: trxTxID, RawDataHex: trxRawData}, false},
+		{"trc20", Transaction{TxID: trc20TxID, RawDataHex: trc20RawData}, false},
+		{"raw data swapped", Transaction{TxID: trxTxID, RawDataHex: trc20RawData}, true},
+		// 金额改为 1.5000001 TRX
+		{"amount tampered", Transaction{TxID: trxTxID, RawDataHex: strings.Replace(trxRawData, "18e0c65b", "18e1c65b", 1)}, true},
+		{"invalid hex", Transaction{TxID: trxTxID, RawDataHex: "zz"}, true},
+	}
+	for _, tt := range tests {
+		rawData, err := verifyTxID(&tt.tx)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && hex.EncodeToString(rawData) != tt.tx.RawDataHex {
+			t.Errorf("%s: raw data = %x", tt.name, rawData)
+		}
+	}
+}
+
+func TestSignedTransactionEncoding(t *testing.T) {
+	rawData, _ := hex.DecodeString(trxRawData)
+	sig := make([]byte, 65)
+	sig[0], sig[64] = 0x01, 0x1b
+	encoded := encodeSignedTransaction(rawData, sig)
+	// raw_data: tag 0a, 长度 133 (85 01); signature: tag 12, 长度 65 (41)
+	want := "0a8501" + trxRawData + "1241" + hex.EncodeToString(sig)
+	if hex.EncodeToString(encoded) != want {
+		t.Fatalf("encoded = %x, want %s", encoded, want)
+	}
+	gotRaw, sigs, err := decodeSignedTransaction(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(gotRaw) != trxRawData || len(sigs) != 1 || hex.EncodeToString(sigs[0]) != hex.EncodeToString(sig) {
+		t.Errorf("decoded %x, %x", gotRaw, sigs)
+	}
+	if _, _, err := decodeSignedTransaction(encoded[len(rawData)+3:]); err == nil {
+		t.Error("transaction without raw_data decoded")
+	}
+}
+
+func TestTrc20TransferParameter(t *testing.T) {
+	got, err := trc20TransferParameter(toAddress, big.NewInt(1234567))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Repeat("0", 62) + "aa" + strings.Repeat("0", 58) + "12d687"
+	if got != want {
+		t.Errorf("parameter = %s, want %s", got, want)
+	}
+	if _, err := trc20TransferParameter("TXXX", big.NewInt(1)); err == nil {
+		t.Error("invalid address accepted")
+	}
+}
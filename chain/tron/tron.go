@@ -1 +1,750 @@
 package tron
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/common/base58"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	ChainName = "Tron"
+
+	// 交易带宽 = raw_data 长度 + 签名 + 结果字段上限 + protobuf 开销
+	signatureBytes    = 65
+	maxResultSize     = 64
+	protobufOverhead  = 5
+	trxTransferRawLen = 134
+
+	contractRetSuccess = "SUCCESS"
+
+	// 单次 GetBlockByRange 最多返回的区块数
+	maxBlockRange = 100
+)
+
+type ChainAdaptor struct {
+	tronClient *TronClient
+}
+
+func init() {
+	chain.Register(ChainName, NewChainAdaptor)
+}
+
+func NewChainAdaptor(conf *config.Config) (chain.IChainAdaptor, error) {
+	node, ok := conf.Chain(ChainName)
+	if !ok {
+		return nil, errors.New("tron rpc not configured")
+	}
+	tronClient := NewTronClient(node.RPCs[0].RPCURL, node.DataApiKey, node.TimeOut.Duration())
+	return &ChainAdaptor{
+		tronClient: tronClient,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// ConvertAddress secp256k1 公钥 keccak256 后取后 20 字节, 加 41 前缀做 base58check
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	publicKey, err := crypto.UnmarshalPubkey(req.PublicKey)
+	if err != nil {
+		publicKey, err = crypto.DecompressPubkey(req.PublicKey)
+		if err != nil {
+			log.Error("parse public key fail", "err", err)
+			return &account.ConvertAddressResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "parse public key fail",
+			}, nil
+		}
+	}
+	return &account.ConvertAddressResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: BytesToBase58(crypto.PubkeyToAddress(*publicKey).Bytes()),
+	}, nil
+}
+
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	raw, err := AddressToBytes(req.Address)
+	if err != nil {
+		return &account.ValidAddressResponse{
+			Code:  common.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:             common.ReturnCode_SUCCESS,
+		Msg:              "valid address",
+		Valid:            true,
+		CanWithdrawal:    true,
+		CanonicalAddress: base58.CheckEncode(raw),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	var block *Block
+	var err error
+	if req.Height == 0 {
+		block, err = c.tronClient.GetNowBlock()
+	} else {
+		block, err = c.tronClient.GetBlockByNum(req.Height)
+	}
+	if err != nil {
+		log.Error("get block by number fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return toBlockResponse(block, req.ViewTx), nil
+}
+
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	block, err := c.tronClient.GetBlockByID(strings.TrimPrefix(req.Hash, "0x"))
+	if err != nil {
+		log.Error("get block by hash fail", "err", err)
+		return &account.BlockResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by hash fail",
+		}, nil
+	}
+	return toBlockResponse(block, req.ViewTx), nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	block, err := c.tronClient.GetBlockByID(strings.TrimPrefix(req.Hash, "0x"))
+	if err != nil {
+		log.Error("get block header by hash fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        common.ReturnCode_SUCCESS,
+		Msg:         "get block header by hash success",
+		BlockHeader: toBlockHeader(block),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	var block *Block
+	var err error
+	if req.Height == 0 {
+		block, err = c.tronClient.GetNowBlock()
+	} else {
+		block, err = c.tronClient.GetBlockByNum(req.Height)
+	}
+	if err != nil {
+		log.Error("get block header by number fail", "err", err)
+		return &account.BlockHeaderResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        common.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(block),
+	}, nil
+}
+
+// GetAccount 合约地址为空时返回 TRX 余额 (sun), 否则返回 TRC-20 余额
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	var balance *big.Int
+	var err error
+	if req.ContractAddress == "" {
+		var acc *Account
+		acc, err = c.tronClient.GetAccount(req.Address)
+		if err == nil {
+			balance = big.NewInt(acc.Balance)
+		}
+	} else {
+		balance, err = c.trc20Balance(req.ContractAddress, req.Address)
+	}
+	if err != nil {
+		log.Error("get balance by address fail", "err", err)
+		return &account.AccountResponse{
+			Code:    common.ReturnCode_ERROR,
+			Msg:     "get balance by address fail",
+			Balance: "0",
+		}, nil
+	}
+	return &account.AccountResponse{
+		Code:          common.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Network:       ChainName,
+		Balance:       balance.String(),
+	}, nil
+}
+
+func (c *ChainAdaptor) trc20Balance(contract, owner string) (*big.Int, error) {
+	parameter, err := abiAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.tronClient.TriggerConstantContract(owner, contract, trc20BalanceSelector, parameter)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.ConstantResult) == 0 {
+		return nil, errors.New("balanceOf returned empty result")
+	}
+	balance, ok := new(big.Int).SetString(result.ConstantResult[0], 16)
+	if !ok {
+		return nil, errors.New("balanceOf returned invalid result")
+	}
+	return balance, nil
+}
+
+/**
+ * @description: 估算手续费, 单位为 sun
+ * rawTx 为 base64(json) 的 TxStructure, 为空时按 req.Address 的 TRX 转账估算
+ * 先用账户已有的 energy/bandwidth 抵扣, 不足部分按链参数 getEnergyFee/getTransactionFee 燃烧 TRX
+ * Tron 没有手续费市场, 三档手续费相同
+ */
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	txStructure := &TxStructure{FromAddress: req.Address}
+	if req.RawTx != "" {
+		decoded, err := decodeTxStructure(req.RawTx)
+		if err != nil {
+			log.Error("decode tx structure fail", "err", err)
+			return &account.FeeResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "decode tx structure fail",
+			}, nil
+		}
+		txStructure = decoded
+	}
+	fee, err := c.estimateFee(txStructure)
+	if err != nil {
+		log.Error("estimate fee fail", "err", err)
+		return &account.FeeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "estimate fee fail",
+		}, nil
+	}
+	return &account.FeeResponse{
+		Code:      common.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee.String(),
+		NormalFee: fee.String(),
+		FastFee:   fee.String(),
+	}, nil
+}
+
+func (c *ChainAdaptor) estimateFee(txStructure *TxStructure) (*big.Int, error) {
+	params, err := c.tronClient.GetChainParameters()
+	if err != nil {
+		return nil, err
+	}
+	resource, err := c.tronClient.GetAccountResource(txStructure.FromAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	var energy int64
+	rawLen := int64(trxTransferRawLen)
+	newAccount := false
+	if txStructure.ContractAddress != "" {
+		amount, ok := new(big.Int).SetString(txStructure.Value, 10)
+		if !ok {
+			return nil, errors.New("invalid transfer value")
+		}
+		parameter, err := trc20TransferParameter(txStructure.ToAddress, amount)
+		if err != nil {
+			return nil, err
+		}
+		result, err := c.tronClient.TriggerConstantContract(txStructure.FromAddress, txStructure.ContractAddress, trc20TransferSelector, parameter)
+		if err != nil {
+			return nil, err
+		}
+		energy = result.EnergyUsed
+		if result.Transaction.RawDataHex != "" {
+			// constant 调用不带 fee_limit, 补上 varint 的长度
+			rawLen = int64(len(result.Transaction.RawDataHex)/2) + 6
+		}
+	} else if txStructure.ToAddress != "" {
+		to, err := c.tronClient.GetAccount(txStructure.ToAddress)
+		if err != nil {
+			return nil, err
+		}
+		newAccount = to.Address == ""
+	}
+
+	fee := new(big.Int)
+	availableEnergy := resource.EnergyLimit - resource.EnergyUsed
+	if energy > availableEnergy {
+		fee.Add(fee, big.NewInt((energy-availableEnergy)*params["getEnergyFee"]))
+	}
+
+	bandwidth := rawLen + signatureBytes + maxResultSize + protobufOverhead
+	stakedBandwidth := resource.NetLimit - resource.NetUsed
+	freeBandwidth := resource.FreeNetLimit - resource.FreeNetUsed
+	if newAccount {
+		// 激活新账户: 质押带宽不足时燃烧 getCreateAccountFee, 另收系统合约创建费
+		if bandwidth > stakedBandwidth {
+			fee.Add(fee, big.NewInt(params["getCreateAccountFee"]))
+		}
+		fee.Add(fee, big.NewInt(params["getCreateNewAccountFeeInSystemContract"]))
+	} else if bandwidth > stakedBandwidth && bandwidth > freeBandwidth {
+		fee.Add(fee, big.NewInt(bandwidth*params["getTransactionFee"]))
+	}
+	return fee, nil
+}
+
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	result, err := c.tronClient.BroadcastHex(strings.TrimPrefix(req.RawTx, "0x"))
+	if err != nil {
+		log.Error("broadcast tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "broadcast tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: result.TxID,
+	}, nil
+}
+
+/**
+ * @description: 查询地址交易记录
+ * 合约地址不为空时返回 TRC-20 转账, Cursor 为 TronGrid 的 fingerprint
+ */
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	var txList []*account.TxMessage
+	var err error
+	if req.ContractAddress != "" {
+		txList, err = c.trc20TxByAddress(req)
+	} else {
+		txList, err = c.trxTxByAddress(req)
+	}
+	if err != nil {
+		log.Error("get tx by address fail", "err", err)
+		return &account.TxAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by address fail",
+		}, nil
+	}
+	return &account.TxAddressResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txList,
+	}, nil
+}
+
+func (c *ChainAdaptor) trxTxByAddress(req *account.TxAddressRequest) ([]*account.TxMessage, error) {
+	txs, _, err := c.tronClient.GetAccountTransactions(req.Address, req.Pagesize, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	var txList []*account.TxMessage
+	for _, tx := range txs {
+		txMessage := &account.TxMessage{
+			Hash:     tx.TxID,
+			Height:   strconv.FormatInt(tx.BlockNumber, 10),
+			Fee:      strconv.FormatInt(tx.NetFee+tx.EnergyFee, 10),
+			Datetime: strconv.FormatInt(tx.BlockTimestamp/1000, 10),
+			Status:   account.TxStatus_Success,
+		}
+		if len(tx.Ret) > 0 && tx.Ret[0].ContractRet != contractRetSuccess {
+			txMessage.Status = account.TxStatus_Failed
+		}
+		if txStructure, err := decodeRawDataHex(tx.RawDataHex); err == nil {
+			fillTxMessage(txMessage, txStructure)
+		}
+		txList = append(txList, txMessage)
+	}
+	return txList, nil
+}
+
+func (c *ChainAdaptor) trc20TxByAddress(req *account.TxAddressRequest) ([]*account.TxMessage, error) {
+	txs, _, err := c.tronClient.GetAccountTrc20Transactions(req.Address, req.ContractAddress, req.Pagesize, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	var txList []*account.TxMessage
+	for _, tx := range txs {
+		txList = append(txList, &account.TxMessage{
+			Hash:            tx.TransactionID,
+			Froms:           []*account.Address{{Address: tx.From}},
+			Tos:             []*account.Address{{Address: tx.To}},
+			Values:          []*account.Value{{Value: tx.Value}},
+			Status:          account.TxStatus_Success,
+			ContractAddress: tx.TokenInfo.Address,
+			Datetime:        strconv.FormatInt(tx.BlockTimestamp/1000, 10),
+		})
+	}
+	return txList, nil
+}
+
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	txID := strings.TrimPrefix(req.Hash, "0x")
+	tx, err := c.tronClient.GetTransactionByID(txID)
+	if err != nil {
+		log.Error("get tx by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}, nil
+	}
+	info, err := c.tronClient.GetTransactionInfoByID(txID)
+	if err != nil {
+		log.Error("get tx info by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get tx info by hash fail",
+		}, nil
+	}
+	txMessage := &account.TxMessage{
+		Hash:   tx.TxID,
+		Status: txStatus(tx, info),
+	}
+	if info.BlockNumber > 0 {
+		txMessage.Height = strconv.FormatInt(info.BlockNumber, 10)
+		txMessage.Fee = strconv.FormatInt(info.Fee, 10)
+		txMessage.Datetime = strconv.FormatInt(info.BlockTimeStamp/1000, 10)
+	}
+	if txStructure, err := decodeRawDataHex(tx.RawDataHex); err == nil {
+		fillTxMessage(txMessage, txStructure)
+	}
+	return &account.TxHashResponse{
+		Code: common.ReturnCode_SUCCESS,
+		Msg:  "get tx by hash success",
+		Tx:   txMessage,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, errStart := strconv.ParseInt(req.Start, 10, 64)
+	end, errEnd := strconv.ParseInt(req.End, 10, 64)
+	if errStart != nil || errEnd != nil || start < 0 || start > end {
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid block range",
+		}, nil
+	}
+	if end-start >= maxBlockRange {
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  fmt.Sprintf("block range exceeds %d blocks", maxBlockRange),
+		}, nil
+	}
+	blocks, err := c.tronClient.GetBlockByLimitNext(start, end+1)
+	if err != nil {
+		log.Error("get block by range fail", "err", err)
+		return &account.BlockByRangeResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get block by range fail",
+		}, nil
+	}
+	var blockList []*account.BlockData
+	for i := range blocks {
+		var txList []*account.TxMessage
+		for _, tx := range blocks[i].Transactions {
+			txMessage := &account.TxMessage{Hash: tx.TxID}
+			if txStructure, err := decodeRawDataHex(tx.RawDataHex); err == nil {
+				fillTxMessage(txMessage, txStructure)
+			}
+			txList = append(txList, txMessage)
+		}
+		blockList = append(blockList, &account.BlockData{
+			Hash:         blocks[i].BlockID,
+			Transactions: txList,
+		})
+	}
+	return &account.BlockByRangeResponse{
+		Code:   common.ReturnCode_SUCCESS,
+		Msg:    "get block by range success",
+		Blocks: blockList,
+	}, nil
+}
+
+/**
+ * @description: 创建未签名交易
+ * base64_tx 为 base64(json) 的 TxStructure, 返回 base64(json) 的节点交易, 签名对象为其中的 txID
+ */
+func (c *ChainAdaptor) CreateUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txStructure, err := decodeTxStructure(req.Base64Tx)
+	if err != nil {
+		log.Error("decode tx structure fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode tx structure fail",
+		}, nil
+	}
+	amount, ok := new(big.Int).SetString(txStructure.Value, 10)
+	if !ok || amount.Sign() <= 0 {
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid transfer value",
+		}, nil
+	}
+
+	var tx *Transaction
+	if txStructure.ContractAddress == "" {
+		if !amount.IsInt64() {
+			return &account.UnSignTransactionResponse{
+				Code: common.ReturnCode_ERROR,
+				Msg:  "invalid transfer value",
+			}, nil
+		}
+		tx, err = c.tronClient.CreateTransaction(txStructure.FromAddress, txStructure.ToAddress, amount.Int64())
+	} else {
+		var parameter string
+		parameter, err = trc20TransferParameter(txStructure.ToAddress, amount)
+		if err == nil {
+			feeLimit := txStructure.FeeLimit
+			if feeLimit == 0 {
+				feeLimit = defaultFeeLimit
+			}
+			var result *TriggerResult
+			result, err = c.tronClient.TriggerSmartContract(txStructure.FromAddress, txStructure.ContractAddress, trc20TransferSelector, parameter, feeLimit)
+			if err == nil {
+				tx = &result.Transaction
+			}
+		}
+	}
+	if err != nil {
+		log.Error("create transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "create transaction fail",
+		}, nil
+	}
+	txJson, err := json.Marshal(tx)
+	if err != nil {
+		return &account.UnSignTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "marshal transaction fail",
+		}, nil
+	}
+	return &account.UnSignTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "create un sign transaction success",
+		UnSignTx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+/**
+ * @description: 组装签名交易
+ * base64_tx 为 CreateUnSignTransaction 返回的交易, signature 为对 txID 的 65 字节 secp256k1 签名 (hex)
+ * 返回 protobuf 编码的交易 hex, 可直接用于 SendTx
+ */
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var tx Transaction
+	if err := json.Unmarshal(txJson, &tx); err != nil {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "unmarshal transaction fail",
+		}, nil
+	}
+	rawData, err := verifyTxID(&tx)
+	if err != nil {
+		log.Error("verify tx id fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "verify tx id fail",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	if err := verifySigner(rawData, signature); err != nil {
+		log.Error("verify signer fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "verify signer fail",
+		}, nil
+	}
+	return &account.SignedTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "build signed transaction success",
+		SignedTx: hex.EncodeToString(encodeSignedTransaction(rawData, signature)),
+	}, nil
+}
+
+// verifySigner 从签名恢复地址, 与交易 owner_address 比对
+func verifySigner(rawData, signature []byte) error {
+	txStructure, err := decodeRawData(rawData)
+	if err != nil {
+		return err
+	}
+	sig := append([]byte{}, signature...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	txID, _ := hex.DecodeString(txStructure.TxID)
+	publicKey, err := crypto.SigToPub(txID, sig)
+	if err != nil {
+		return err
+	}
+	if BytesToBase58(crypto.PubkeyToAddress(*publicKey).Bytes()) != txStructure.FromAddress {
+		return errors.New("signer is not the transaction owner")
+	}
+	return nil
+}
+
+// DecodeTransaction raw_tx 为 BuildSignedTransaction 返回的 hex, 返回 base64(json) 的 TxStructure
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(req.RawTx, "0x"))
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	rawData, _, err := decodeSignedTransaction(data)
+	if err != nil {
+		log.Error("decode signed transaction fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode signed transaction fail",
+		}, nil
+	}
+	txStructure, err := decodeRawData(rawData)
+	if err != nil {
+		log.Error("decode transaction raw data fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "decode transaction raw data fail",
+		}, nil
+	}
+	txJson, _ := json.Marshal(txStructure)
+	return &account.DecodeTransactionResponse{
+		Code:     common.ReturnCode_SUCCESS,
+		Msg:      "decode transaction success",
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "tron does not support verify signed transaction",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: common.ReturnCode_ERROR,
+		Msg:  "tron does not support get extra data",
+	}, nil
+}
+
+func decodeTxStructure(base64Tx string) (*TxStructure, error) {
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var txStructure TxStructure
+	if err := json.Unmarshal(txJson, &txStructure); err != nil {
+		return nil, err
+	}
+	return &txStructure, nil
+}
+
+func decodeRawDataHex(rawDataHex string) (*TxStructure, error) {
+	rawData, err := hex.DecodeString(rawDataHex)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRawData(rawData)
+}
+
+func fillTxMessage(txMessage *account.TxMessage, txStructure *TxStructure) {
+	txMessage.Froms = []*account.Address{{Address: txStructure.FromAddress}}
+	txMessage.Tos = []*account.Address{{Address: txStructure.ToAddress}}
+	txMessage.Values = []*account.Value{{Value: txStructure.Value}}
+	txMessage.ContractAddress = txStructure.ContractAddress
+}
+
+func txStatus(tx *Transaction, info *TransactionInfo) account.TxStatus {
+	if info.BlockNumber == 0 {
+		return account.TxStatus_Pending
+	}
+	if len(tx.Ret) > 0 && tx.Ret[0].ContractRet != contractRetSuccess {
+		if info.Receipt.Result != "" {
+			return account.TxStatus_ContractExecuteFailed
+		}
+		return account.TxStatus_Failed
+	}
+	return account.TxStatus_Success
+}
+
+func toBlockHeader(block *Block) *account.BlockHeader {
+	rawData := block.BlockHeader.RawData
+	witness, err := HexToBase58(rawData.WitnessAddress)
+	if err != nil {
+		witness = rawData.WitnessAddress
+	}
+	return &account.BlockHeader{
+		ParentHash: rawData.ParentHash,
+		CoinBase:   witness,
+		TxHash:     rawData.TxTrieRoot,
+		Number:     strconv.FormatInt(rawData.Number, 10),
+		Time:       uint64(rawData.Timestamp / 1000),
+	}
+}
+
+func toBlockResponse(block *Block, viewTx bool) *account.BlockResponse {
+	var txList []*account.BlockInfoTransactionList
+	if viewTx {
+		for _, tx := range block.Transactions {
+			item := &account.BlockInfoTransactionList{
+				Hash: tx.TxID,
+				Time: strconv.FormatInt(tx.RawData.Timestamp/1000, 10),
+			}
+			if txStructure, err := decodeRawDataHex(tx.RawDataHex); err == nil {
+				item.From = txStructure.FromAddress
+				item.To = txStructure.ToAddress
+				item.Amount = txStructure.Value
+			}
+			if len(tx.Ret) > 0 {
+				item.Status = tx.Ret[0].ContractRet
+			}
+			txList = append(txList, item)
+		}
+	}
+	return &account.BlockResponse{
+		Code:         common.ReturnCode_SUCCESS,
+		Msg:          "get block success",
+		Height:       block.BlockHeader.RawData.Number,
+		Hash:         block.BlockID,
+		Transactions: txList,
+	}
+}
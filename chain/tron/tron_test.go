@@ -0,0 +1,102 @@
+package tron
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+func TestBuildSignedTransaction(t *testing.T) {
+	owner, _ := crypto.ToECDSA(privateKey(1))
+	other, _ := crypto.ToECDSA(privateKey(2))
+	txID, _ := hex.DecodeString(trc20TxID)
+	tx, _ := json.Marshal(&Transaction{TxID: trc20TxID, RawDataHex: trc20RawData})
+	tampered, _ := json.Marshal(&Transaction{TxID: trxTxID, RawDataHex: trc20RawData})
+
+	ownerSig, _ := crypto.Sign(txID, owner)
+	otherSig, _ := crypto.Sign(txID, other)
+	tests := []struct {
+		name    string
+		tx      []byte
+		sig     []byte
+		wantMsg string
+	}{
+		{"owner", tx, ownerSig, ""},
+		{"other signer", tx, otherSig, "verify signer fail"},
+		{"tx id mismatch", tampered, ownerSig, "verify tx id fail"},
+		{"short signature", tx, ownerSig[:64], "invalid signature"},
+	}
+	c := &ChainAdaptor{}
+	for _, tt := range tests {
+		resp, err := c.BuildSignedTransaction(&account.SignedTransactionRequest{
+			Base64Tx:  base64.StdEncoding.EncodeToString(tt.tx),
+			Signature: hex.EncodeToString(tt.sig),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.wantMsg != "" {
+			if resp.Code != common.ReturnCode_ERROR || resp.Msg != tt.wantMsg {
+				t.Errorf("%s: %v (%s), want %s", tt.name, resp.Code, resp.Msg, tt.wantMsg)
+			}
+			continue
+		}
+		rawData, _ := hex.DecodeString(trc20RawData)
+		if want := hex.EncodeToString(encodeSignedTransaction(rawData, tt.sig)); resp.SignedTx != want {
+			t.Errorf("%s: signed tx = %s (%s), want %s", tt.name, resp.SignedTx, resp.Msg, want)
+		}
+	}
+}
+
+// common32 32 字节大端私钥
+func privateKey(v int64) []byte {
+	key := make([]byte, 32)
+	return big.NewInt(v).FillBytes(key)
+}
+
+// 超出范围的请求在访问节点前被拒绝, 100 个区块以内按 [start, end+1) 请求节点
+func TestGetBlockByRangeLimit(t *testing.T) {
+	var requests []map[string]int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]int64
+		if r.URL.Path != "/wallet/getblockbylimitnext" || json.NewDecoder(r.Body).Decode(&body) != nil {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		requests = append(requests, body)
+		_, _ = w.Write([]byte(`{"block":[]}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		start, end string
+		wantMsg    string
+	}{
+		{"100", "99", "invalid block range"},
+		{"-1", "10", "invalid block range"},
+		{"1", "x", "invalid block range"},
+		{"100", "199", "get block by range success"},
+		{"100", "200", "block range exceeds 100 blocks"},
+	}
+	c := &ChainAdaptor{tronClient: NewTronClient(srv.URL, "", 0)}
+	for _, tt := range tests {
+		resp, err := c.GetBlockByRange(&account.BlockByRangeRequest{Start: tt.start, End: tt.end})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Msg != tt.wantMsg {
+			t.Errorf("[%s, %s]: %v (%s), want %s", tt.start, tt.end, resp.Code, resp.Msg, tt.wantMsg)
+		}
+	}
+	if len(requests) != 1 || requests[0]["startNum"] != 100 || requests[0]["endNum"] != 200 {
+		t.Errorf("node requests = %v, want one for [100, 200)", requests)
+	}
+}
@@ -0,0 +1,415 @@
+package tron
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	defaultRequestTimeout = 10 * time.Second
+	apiKeyHeader          = "TRON-PRO-API-KEY"
+)
+
+type BlockHeaderRawData struct {
+	Number         int64  `json:"number"`
+	TxTrieRoot     string `json:"txTrieRoot"`
+	WitnessAddress string `json:"witness_address"`
+	ParentHash     string `json:"parentHash"`
+	Version        int64  `json:"version"`
+	Timestamp      int64  `json:"timestamp"`
+}
+
+type Block struct {
+	BlockID     string `json:"blockID"`
+	BlockHeader struct {
+		RawData          BlockHeaderRawData `json:"raw_data"`
+		WitnessSignature string             `json:"witness_signature"`
+	} `json:"block_header"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+type ContractParameter struct {
+	Value   json.RawMessage `json:"value"`
+	TypeUrl string          `json:"type_url"`
+}
+
+type Contract struct {
+	Parameter ContractParameter `json:"parameter"`
+	Type      string            `json:"type"`
+}
+
+type TransactionRawData struct {
+	Contract      []Contract `json:"contract"`
+	RefBlockBytes string     `json:"ref_block_bytes"`
+	RefBlockHash  string     `json:"ref_block_hash"`
+	Expiration    int64      `json:"expiration"`
+	FeeLimit      int64      `json:"fee_limit,omitempty"`
+	Timestamp     int64      `json:"timestamp"`
+}
+
+type Transaction struct {
+	Visible    bool               `json:"visible"`
+	TxID       string             `json:"txID"`
+	RawData    TransactionRawData `json:"raw_data"`
+	RawDataHex string             `json:"raw_data_hex"`
+	Signature  []string           `json:"signature,omitempty"`
+	Ret        []struct {
+		ContractRet string `json:"contractRet"`
+	} `json:"ret,omitempty"`
+}
+
+type TransactionInfo struct {
+	ID              string   `json:"id"`
+	Fee             int64    `json:"fee"`
+	BlockNumber     int64    `json:"blockNumber"`
+	BlockTimeStamp  int64    `json:"blockTimeStamp"`
+	ContractAddress string   `json:"contract_address"`
+	ContractResult  []string `json:"contractResult"`
+	Receipt         struct {
+		EnergyUsage      int64  `json:"energy_usage"`
+		EnergyFee        int64  `json:"energy_fee"`
+		EnergyUsageTotal int64  `json:"energy_usage_total"`
+		NetUsage         int64  `json:"net_usage"`
+		NetFee           int64  `json:"net_fee"`
+		Result           string `json:"result"`
+	} `json:"receipt"`
+	Result string `json:"result"`
+}
+
+type Account struct {
+	Address string `json:"address"`
+	Balance int64  `json:"balance"`
+}
+
+type AccountResource struct {
+	FreeNetUsed  int64 `json:"freeNetUsed"`
+	FreeNetLimit int64 `json:"freeNetLimit"`
+	NetUsed      int64 `json:"NetUsed"`
+	NetLimit     int64 `json:"NetLimit"`
+	EnergyUsed   int64 `json:"EnergyUsed"`
+	EnergyLimit  int64 `json:"EnergyLimit"`
+}
+
+type ChainParameter struct {
+	Key   string `json:"key"`
+	Value int64  `json:"value"`
+}
+
+type TriggerResult struct {
+	Result struct {
+		Result  bool   `json:"result"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"result"`
+	EnergyUsed     int64       `json:"energy_used"`
+	ConstantResult []string    `json:"constant_result"`
+	Transaction    Transaction `json:"transaction"`
+}
+
+type BroadcastResult struct {
+	Result  bool   `json:"result"`
+	Code    string `json:"code"`
+	TxID    string `json:"txid"`
+	Message string `json:"message"`
+}
+
+// AccountTx TronGrid v1 账户交易列表中的单条记录
+type AccountTx struct {
+	TxID           string             `json:"txID"`
+	BlockNumber    int64              `json:"blockNumber"`
+	BlockTimestamp int64              `json:"block_timestamp"`
+	NetFee         int64              `json:"net_fee"`
+	EnergyFee      int64              `json:"energy_fee"`
+	RawData        TransactionRawData `json:"raw_data"`
+	RawDataHex     string             `json:"raw_data_hex"`
+	Ret            []struct {
+		ContractRet string `json:"contractRet"`
+		Fee         int64  `json:"fee"`
+	} `json:"ret"`
+}
+
+// AccountTrc20Tx TronGrid v1 账户 TRC-20 转账记录
+type AccountTrc20Tx struct {
+	TransactionID  string `json:"transaction_id"`
+	BlockTimestamp int64  `json:"block_timestamp"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Type           string `json:"type"`
+	Value          string `json:"value"`
+	TokenInfo      struct {
+		Address  string `json:"address"`
+		Symbol   string `json:"symbol"`
+		Decimals int    `json:"decimals"`
+	} `json:"token_info"`
+}
+
+type pageMeta struct {
+	Fingerprint string `json:"fingerprint"`
+	PageSize    int    `json:"page_size"`
+}
+
+type TronClient struct {
+	baseUrl    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewTronClient(baseUrl, apiKey string, timeout time.Duration) *TronClient {
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &TronClient{
+		baseUrl:    strings.TrimRight(baseUrl, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *TronClient) do(method, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseUrl+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tron api %s status %d: %s", path, resp.StatusCode, string(data))
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		log.Error("unmarshal tron response fail", "path", path, "err", err)
+		return err
+	}
+	return nil
+}
+
+func (c *TronClient) post(path string, body interface{}, result interface{}) error {
+	return c.do(http.MethodPost, path, body, result)
+}
+
+func (c *TronClient) GetNowBlock() (*Block, error) {
+	var block Block
+	if err := c.post("/wallet/getnowblock", map[string]interface{}{}, &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (c *TronClient) GetBlockByNum(number int64) (*Block, error) {
+	var block Block
+	if err := c.post("/wallet/getblockbynum", map[string]interface{}{"num": number}, &block); err != nil {
+		return nil, err
+	}
+	if block.BlockID == "" {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	return &block, nil
+}
+
+func (c *TronClient) GetBlockByID(id string) (*Block, error) {
+	var block Block
+	if err := c.post("/wallet/getblockbyid", map[string]interface{}{"value": id}, &block); err != nil {
+		return nil, err
+	}
+	if block.BlockID == "" {
+		return nil, fmt.Errorf("block %s not found", id)
+	}
+	return &block, nil
+}
+
+// GetBlockByLimitNext 返回 [start, end) 区间的区块
+func (c *TronClient) GetBlockByLimitNext(start, end int64) ([]Block, error) {
+	var result struct {
+		Block []Block `json:"block"`
+	}
+	if err := c.post("/wallet/getblockbylimitnext", map[string]interface{}{"startNum": start, "endNum": end}, &result); err != nil {
+		return nil, err
+	}
+	return result.Block, nil
+}
+
+func (c *TronClient) GetAccount(address string) (*Account, error) {
+	var acc Account
+	if err := c.post("/wallet/getaccount", map[string]interface{}{"address": address, "visible": true}, &acc); err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
+
+func (c *TronClient) GetAccountResource(address string) (*AccountResource, error) {
+	var resource AccountResource
+	if err := c.post("/wallet/getaccountresource", map[string]interface{}{"address": address, "visible": true}, &resource); err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// GetChainParameters 返回链参数, key 为参数名
+func (c *TronClient) GetChainParameters() (map[string]int64, error) {
+	var result struct {
+		ChainParameter []ChainParameter `json:"chainParameter"`
+	}
+	if err := c.post("/wallet/getchainparameters", map[string]interface{}{}, &result); err != nil {
+		return nil, err
+	}
+	params := make(map[string]int64, len(result.ChainParameter))
+	for _, p := range result.ChainParameter {
+		params[p.Key] = p.Value
+	}
+	return params, nil
+}
+
+func (c *TronClient) CreateTransaction(from, to string, amount int64) (*Transaction, error) {
+	var tx Transaction
+	body := map[string]interface{}{
+		"owner_address": from,
+		"to_address":    to,
+		"amount":        amount,
+		"visible":       true,
+	}
+	if err := c.post("/wallet/createtransaction", body, &tx); err != nil {
+		return nil, err
+	}
+	if tx.TxID == "" {
+		return nil, fmt.Errorf("create transaction fail")
+	}
+	return &tx, nil
+}
+
+func (c *TronClient) TriggerSmartContract(from, contract, selector, parameter string, feeLimit int64) (*TriggerResult, error) {
+	var result TriggerResult
+	body := map[string]interface{}{
+		"owner_address":     from,
+		"contract_address":  contract,
+		"function_selector": selector,
+		"parameter":         parameter,
+		"fee_limit":         feeLimit,
+		"call_value":        0,
+		"visible":           true,
+	}
+	if err := c.post("/wallet/triggersmartcontract", body, &result); err != nil {
+		return nil, err
+	}
+	if !result.Result.Result {
+		return nil, fmt.Errorf("trigger smart contract fail: %s %s", result.Result.Code, result.Result.Message)
+	}
+	return &result, nil
+}
+
+func (c *TronClient) TriggerConstantContract(from, contract, selector, parameter string) (*TriggerResult, error) {
+	var result TriggerResult
+	body := map[string]interface{}{
+		"owner_address":     from,
+		"contract_address":  contract,
+		"function_selector": selector,
+		"parameter":         parameter,
+		"visible":           true,
+	}
+	if err := c.post("/wallet/triggerconstantcontract", body, &result); err != nil {
+		return nil, err
+	}
+	if !result.Result.Result {
+		return nil, fmt.Errorf("trigger constant contract fail: %s %s", result.Result.Code, result.Result.Message)
+	}
+	return &result, nil
+}
+
+func (c *TronClient) BroadcastHex(txHex string) (*BroadcastResult, error) {
+	var result BroadcastResult
+	if err := c.post("/wallet/broadcasthex", map[string]interface{}{"transaction": txHex}, &result); err != nil {
+		return nil, err
+	}
+	if !result.Result {
+		return nil, fmt.Errorf("broadcast transaction fail: %s %s", result.Code, result.Message)
+	}
+	return &result, nil
+}
+
+func (c *TronClient) GetTransactionByID(txID string) (*Transaction, error) {
+	var tx Transaction
+	if err := c.post("/wallet/gettransactionbyid", map[string]interface{}{"value": txID, "visible": true}, &tx); err != nil {
+		return nil, err
+	}
+	if tx.TxID == "" {
+		return nil, fmt.Errorf("transaction %s not found", txID)
+	}
+	return &tx, nil
+}
+
+func (c *TronClient) GetTransactionInfoByID(txID string) (*TransactionInfo, error) {
+	var info TransactionInfo
+	if err := c.post("/wallet/gettransactioninfobyid", map[string]interface{}{"value": txID}, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetAccountTransactions TronGrid v1 接口, fingerprint 为上一页返回的游标
+func (c *TronClient) GetAccountTransactions(address string, limit uint32, fingerprint string) ([]AccountTx, string, error) {
+	var result struct {
+		Data []AccountTx `json:"data"`
+		Meta pageMeta    `json:"meta"`
+	}
+	if err := c.do(http.MethodGet, accountPath(address, "transactions", limit, fingerprint, ""), nil, &result); err != nil {
+		return nil, "", err
+	}
+	return result.Data, result.Meta.Fingerprint, nil
+}
+
+// GetAccountTrc20Transactions TronGrid v1 接口, 按合约地址过滤 TRC-20 转账
+func (c *TronClient) GetAccountTrc20Transactions(address, contract string, limit uint32, fingerprint string) ([]AccountTrc20Tx, string, error) {
+	var result struct {
+		Data []AccountTrc20Tx `json:"data"`
+		Meta pageMeta         `json:"meta"`
+	}
+	if err := c.do(http.MethodGet, accountPath(address, "transactions/trc20", limit, fingerprint, contract), nil, &result); err != nil {
+		return nil, "", err
+	}
+	return result.Data, result.Meta.Fingerprint, nil
+}
+
+func accountPath(address, resource string, limit uint32, fingerprint, contract string) string {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.FormatUint(uint64(limit), 10))
+	}
+	if fingerprint != "" {
+		query.Set("fingerprint", fingerprint)
+	}
+	if contract != "" {
+		query.Set("contract_address", contract)
+	}
+	path := "/v1/accounts/" + address + "/" + resource
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return path
+}
@@ -0,0 +1,203 @@
+package utxo
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"golang.org/x/crypto/ripemd160"
+
+	"github.com/CavnHan/wallet-chain-account/common/base58"
+	"github.com/CavnHan/wallet-chain-account/common/bech32"
+)
+
+type AddressType string
+
+const (
+	AddressP2PKH      AddressType = "p2pkh"
+	AddressP2SHP2WPKH AddressType = "p2sh-p2wpkh"
+	AddressP2WPKH     AddressType = "p2wpkh"
+	AddressP2TR       AddressType = "p2tr"
+	// 以下类型仅用于解析地址与脚本, 不能由公钥派生
+	AddressP2SH    AddressType = "p2sh"
+	AddressP2WSH   AddressType = "p2wsh"
+	AddressUnknown AddressType = "unknown"
+
+	maxBech32AddressLength = 90
+)
+
+var errInvalidAddress = errors.New("invalid utxo address")
+
+func Hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)
+}
+
+func DoubleSha256(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// TaggedHash BIP340 sha256(sha256(tag) || sha256(tag) || msg)
+func TaggedHash(tag string, msgs ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+	return h.Sum(nil)
+}
+
+// TaprootOutputKey BIP86 不含脚本路径的输出公钥: Q = P + H_TapTweak(P)·G, P 为偶数 y 的内部公钥
+func TaprootOutputKey(internalKey *btcec.PublicKey) *btcec.PublicKey {
+	internal, _ := schnorr.ParsePubKey(schnorr.SerializePubKey(internalKey))
+	var tweak btcec.ModNScalar
+	tweak.SetByteSlice(TaggedHash("TapTweak", schnorr.SerializePubKey(internal)))
+	var p, t, q btcec.JacobianPoint
+	internal.AsJacobian(&p)
+	btcec.ScalarBaseMultNonConst(&tweak, &t)
+	btcec.AddNonConst(&p, &t, &q)
+	q.ToAffine()
+	return btcec.NewPublicKey(&q.X, &q.Y)
+}
+
+/**
+ * @description: 由公钥派生锁定脚本
+ * P2PKH 使用传入格式的公钥 (压缩或非压缩), segwit 与 taproot 要求压缩公钥
+ */
+func PayToPubKeyScript(publicKey []byte, addressType AddressType) ([]byte, error) {
+	pubKey, err := btcec.ParsePubKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if addressType != AddressP2PKH && len(publicKey) != btcec.PubKeyBytesLenCompressed {
+		return nil, errors.New("segwit address requires compressed public key")
+	}
+	switch addressType {
+	case AddressP2PKH:
+		return payToPubKeyHashScript(Hash160(publicKey)), nil
+	case AddressP2SHP2WPKH:
+		return payToScriptHashScript(Hash160(PayToWitnessPubKeyHashScript(Hash160(publicKey)))), nil
+	case AddressP2WPKH:
+		return PayToWitnessPubKeyHashScript(Hash160(publicKey)), nil
+	case AddressP2TR:
+		return payToTaprootScript(schnorr.SerializePubKey(TaprootOutputKey(pubKey))), nil
+	}
+	return nil, fmt.Errorf("unsupported address type %s", addressType)
+}
+
+// NewAddress 由公钥派生地址
+func NewAddress(publicKey []byte, addressType AddressType, params *NetParams) (string, error) {
+	if !params.Supports(addressType) {
+		return "", fmt.Errorf("%s does not support %s address", params.Name, addressType)
+	}
+	script, err := PayToPubKeyScript(publicKey, addressType)
+	if err != nil {
+		return "", err
+	}
+	return ScriptAddress(script, params)
+}
+
+// Supports 网络是否支持由公钥派生该类型地址
+func (p *NetParams) Supports(addressType AddressType) bool {
+	switch addressType {
+	case AddressP2PKH:
+		return true
+	case AddressP2SHP2WPKH, AddressP2WPKH:
+		return p.Bech32HRP != ""
+	case AddressP2TR:
+		return p.Bech32HRP != "" && p.SupportTaproot
+	}
+	return false
+}
+
+/**
+ * @description: 解析地址, 返回锁定脚本与地址类型
+ * base58check 地址按版本字节区分 P2PKH/P2SH, bech32 地址按 witness 版本与程序长度区分, v0 须为 bech32 编码, v1 及以上须为 bech32m
+ */
+func DecodeAddress(address string, params *NetParams) ([]byte, AddressType, error) {
+	if params.Bech32HRP != "" && strings.HasPrefix(strings.ToLower(address), params.Bech32HRP+"1") {
+		if len(address) > maxBech32AddressLength {
+			return nil, "", errInvalidAddress
+		}
+		hrp, data, encoding, err := bech32.Decode(address)
+		if err != nil || hrp != params.Bech32HRP || len(data) < 1 {
+			return nil, "", errInvalidAddress
+		}
+		version := data[0]
+		program, err := bech32.ConvertBits(data[1:], 5, 8, false)
+		if err != nil || len(program) < 2 || len(program) > 40 || version > 16 {
+			return nil, "", errInvalidAddress
+		}
+		if (version == 0 && encoding != bech32.Bech32) || (version != 0 && encoding != bech32.Bech32m) {
+			return nil, "", errInvalidAddress
+		}
+		script := witnessScript(version, program)
+		addressType := ScriptType(script)
+		if addressType == AddressUnknown || (addressType == AddressP2TR && !params.SupportTaproot) {
+			return nil, "", errInvalidAddress
+		}
+		return script, addressType, nil
+	}
+	payload, err := base58.CheckDecode(address)
+	if err != nil || len(payload) != 21 {
+		return nil, "", errInvalidAddress
+	}
+	switch payload[0] {
+	case params.PubKeyHashAddrID:
+		return payToPubKeyHashScript(payload[1:]), AddressP2PKH, nil
+	case params.ScriptHashAddrID:
+		return payToScriptHashScript(payload[1:]), AddressP2SH, nil
+	}
+	return nil, "", errInvalidAddress
+}
+
+// ScriptAddress 锁定脚本对应的地址, 非标准脚本返回错误
+func ScriptAddress(script []byte, params *NetParams) (string, error) {
+	switch ScriptType(script) {
+	case AddressP2PKH:
+		return base58.CheckEncode(append([]byte{params.PubKeyHashAddrID}, script[3:23]...)), nil
+	case AddressP2SH:
+		return base58.CheckEncode(append([]byte{params.ScriptHashAddrID}, script[2:22]...)), nil
+	case AddressP2WPKH, AddressP2WSH, AddressP2TR:
+		if params.Bech32HRP == "" {
+			break
+		}
+		version := byte(0)
+		encoding := bech32.Bech32
+		if script[0] != 0 {
+			version, encoding = script[0]-0x50, bech32.Bech32m
+		}
+		program, err := bech32.ConvertBits(script[2:], 8, 5, true)
+		if err != nil {
+			return "", err
+		}
+		return bech32.Encode(params.Bech32HRP, append([]byte{version}, program...), encoding)
+	}
+	return "", errors.New("non standard script")
+}
+
+// ScriptType 识别标准锁定脚本
+func ScriptType(script []byte) AddressType {
+	switch {
+	case len(script) == 25 && script[0] == opDup && script[1] == opHash160 && script[2] == 20 && script[23] == opEqualVerify && script[24] == opCheckSig:
+		return AddressP2PKH
+	case len(script) == 23 && script[0] == opHash160 && script[1] == 20 && script[22] == opEqual:
+		return AddressP2SH
+	case len(script) == 22 && script[0] == op0 && script[1] == 20:
+		return AddressP2WPKH
+	case len(script) == 34 && script[0] == op0 && script[1] == 32:
+		return AddressP2WSH
+	case len(script) == 34 && script[0] == op1 && script[1] == 32:
+		return AddressP2TR
+	}
+	return AddressUnknown
+}
@@ -0,0 +1,133 @@
+package utxo
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/CavnHan/wallet-chain-account/common/bech32"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// 私钥为 1 (公钥为 G) 的地址, 以及 BIP-86 m/86'/0'/0'/0/0 的内部公钥
+func TestNewAddress(t *testing.T) {
+	const generator = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	tests := []struct {
+		pubKey      string
+		addressType AddressType
+		params      *NetParams
+		want        string
+	}{
+		{generator, AddressP2PKH, BitcoinMainNetParams, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"},
+		{"0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", AddressP2PKH, BitcoinMainNetParams, "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm"},
+		{generator, AddressP2SHP2WPKH, BitcoinMainNetParams, "3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN"},
+		{generator, AddressP2WPKH, BitcoinMainNetParams, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{"02cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115", AddressP2TR, BitcoinMainNetParams, "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	}
+	for _, tt := range tests {
+		got, err := NewAddress(mustHex(t, tt.pubKey), tt.addressType, tt.params)
+		if err != nil {
+			t.Errorf("NewAddress(%s, %s): %v", tt.pubKey, tt.addressType, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NewAddress(%s, %s) = %s, want %s", tt.pubKey, tt.addressType, got, tt.want)
+		}
+	}
+}
+
+func TestNewAddressUnsupported(t *testing.T) {
+	generator := mustHex(t, "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	if _, err := NewAddress(generator, AddressP2WPKH, DogecoinMainNetParams); err == nil {
+		t.Error("dogecoin p2wpkh address should be rejected")
+	}
+	if _, err := NewAddress(generator, AddressP2TR, BitcoinCashMainNetParams); err == nil {
+		t.Error("bitcoin cash p2tr address should be rejected")
+	}
+	uncompressed := mustHex(t, "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8")
+	if _, err := NewAddress(uncompressed, AddressP2WPKH, BitcoinMainNetParams); err == nil {
+		t.Error("segwit address from uncompressed public key should be rejected")
+	}
+}
+
+// BIP-173 与 BIP-350 的地址测试向量
+func TestDecodeAddress(t *testing.T) {
+	tests := []struct {
+		address     string
+		params      *NetParams
+		script      string
+		addressType AddressType
+	}{
+		{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", BitcoinMainNetParams, "0014751e76e8199196d454941c45d1b3a323f1433bd6", AddressP2WPKH},
+		{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", BitcoinTestNetParams, "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262", AddressP2WSH},
+		{"tb1qqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesrxh6hy", BitcoinTestNetParams, "0020000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433", AddressP2WSH},
+		{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", BitcoinMainNetParams, "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", AddressP2TR},
+		{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", BitcoinMainNetParams, "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac", AddressP2PKH},
+		{"3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN", BitcoinMainNetParams, "a914bcfeb728b584253d5f3f70bcb780e9ef218a68f487", AddressP2SH},
+	}
+	for _, tt := range tests {
+		script, addressType, err := DecodeAddress(tt.address, tt.params)
+		if err != nil {
+			t.Errorf("DecodeAddress(%s): %v", tt.address, err)
+			continue
+		}
+		if hex.EncodeToString(script) != tt.script || addressType != tt.addressType {
+			t.Errorf("DecodeAddress(%s) = %x %s, want %s %s", tt.address, script, addressType, tt.script, tt.addressType)
+			continue
+		}
+		// 地址由脚本重新编码, bech32 地址统一为小写
+		address, err := ScriptAddress(script, tt.params)
+		if err != nil {
+			t.Errorf("ScriptAddress(%x): %v", script, err)
+			continue
+		}
+		if !strings.EqualFold(address, tt.address) {
+			t.Errorf("ScriptAddress(%x) = %s, want %s", script, address, tt.address)
+		}
+	}
+}
+
+func TestDecodeAddressInvalid(t *testing.T) {
+	program := mustHex(t, "751e76e8199196d454941c45d1b3a323f1433bd6")
+	encode := func(hrp string, version byte, program []byte, encoding bech32.Encoding) string {
+		data, err := bech32.ConvertBits(program, 8, 5, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		address, err := bech32.Encode(hrp, append([]byte{version}, data...), encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return address
+	}
+	taproot := mustHex(t, "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	tests := []struct {
+		name    string
+		address string
+		params  *NetParams
+	}{
+		{"v0 encoded with bech32m", encode("bc", 0, program, bech32.Bech32m), BitcoinMainNetParams},
+		{"v1 encoded with bech32", encode("bc", 1, taproot, bech32.Bech32), BitcoinMainNetParams},
+		{"v0 program length", encode("bc", 0, program[:16], bech32.Bech32), BitcoinMainNetParams},
+		{"program too short", encode("bc", 1, program[:1], bech32.Bech32m), BitcoinMainNetParams},
+		{"testnet hrp on mainnet", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", BitcoinMainNetParams},
+		{"mixed case", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sL5k7", BitcoinTestNetParams},
+		{"bad checksum", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", BitcoinMainNetParams},
+		{"mainnet base58 on testnet", "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", BitcoinTestNetParams},
+		{"base58 bad checksum", "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMJ", BitcoinMainNetParams},
+		{"taproot unsupported", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", DogecoinMainNetParams},
+	}
+	for _, tt := range tests {
+		if _, _, err := DecodeAddress(tt.address, tt.params); err == nil {
+			t.Errorf("%s: DecodeAddress(%s) should fail", tt.name, tt.address)
+		}
+	}
+}
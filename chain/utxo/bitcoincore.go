@@ -0,0 +1,264 @@
+package utxo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// rpcInvalidAddressOrKey Bitcoin Core 查询不到交易或区块时返回的错误码
+const rpcInvalidAddressOrKey = -5
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("bitcoin rpc error %d: %s", e.Code, e.Message)
+}
+
+// BitcoinCoreClient Bitcoin Core 兼容节点 (含 Litecoin/Dogecoin/BCH 节点) 的 JSON-RPC 客户端, 使用 basic auth
+// 节点不索引地址, UTXO 通过 scantxoutset 扫描, 不支持地址交易历史; 按哈希查询交易需节点开启 txindex
+type BitcoinCoreClient struct {
+	url        string
+	user       string
+	password   string
+	httpClient *http.Client
+	nextId     atomic.Uint64
+}
+
+func NewBitcoinCoreClient(url, user, password string, timeout time.Duration) *BitcoinCoreClient {
+	return &BitcoinCoreClient{
+		url:        url,
+		user:       user,
+		password:   password,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *BitcoinCoreClient) call(result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "1.0",
+		"id":      c.nextId.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	// 节点对 RPC 错误同时返回 500 与 json 错误体
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&response); err != nil {
+		log.Error("decode bitcoin rpc response fail", "method", method, "status", resp.StatusCode, "err", err)
+		return fmt.Errorf("bitcoin rpc %s status %d", method, resp.StatusCode)
+	}
+	if response.Error != nil {
+		if response.Error.Code == rpcInvalidAddressOrKey {
+			return ErrTxNotFound
+		}
+		return response.Error
+	}
+	if result == nil {
+		return nil
+	}
+	decoder = json.NewDecoder(bytes.NewReader(response.Result))
+	decoder.UseNumber()
+	return decoder.Decode(result)
+}
+
+func (c *BitcoinCoreClient) ListUnspent(address string, pkScript []byte) ([]*Utxo, error) {
+	var result struct {
+		Success  bool   `json:"success"`
+		Height   uint64 `json:"height"`
+		Unspents []struct {
+			TxId         string      `json:"txid"`
+			Vout         uint32      `json:"vout"`
+			ScriptPubKey string      `json:"scriptPubKey"`
+			Amount       json.Number `json:"amount"`
+			Height       uint64      `json:"height"`
+		} `json:"unspents"`
+	}
+	descriptor := "raw(" + hex.EncodeToString(pkScript) + ")"
+	if err := c.call(&result, "scantxoutset", "start", []string{descriptor}); err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("scantxoutset for %s not finished", address)
+	}
+	var utxos []*Utxo
+	for _, unspent := range result.Unspents {
+		value, err := CoinToSatoshi(unspent.Amount)
+		if err != nil {
+			return nil, err
+		}
+		script, err := hex.DecodeString(unspent.ScriptPubKey)
+		if err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, &Utxo{
+			TxId:          unspent.TxId,
+			Index:         unspent.Vout,
+			Value:         value,
+			PkScript:      script,
+			Height:        unspent.Height,
+			Confirmations: result.Height - unspent.Height + 1,
+		})
+	}
+	return utxos, nil
+}
+
+func (c *BitcoinCoreClient) GetHistory(address string, pkScript []byte) ([]*HistoryItem, error) {
+	return nil, ErrUnsupported
+}
+
+func (c *BitcoinCoreClient) GetTransaction(txId string) (*TxInfo, error) {
+	var result struct {
+		Hex           string `json:"hex"`
+		BlockHash     string `json:"blockhash"`
+		Confirmations uint64 `json:"confirmations"`
+		BlockTime     uint64 `json:"blocktime"`
+	}
+	if err := c.call(&result, "getrawtransaction", txId, true); err != nil {
+		return nil, err
+	}
+	raw, err := hex.DecodeString(result.Hex)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := DeserializeTx(raw)
+	if err != nil {
+		return nil, err
+	}
+	info := &TxInfo{Tx: tx, BlockHash: result.BlockHash, Confirmations: result.Confirmations, Time: result.BlockTime}
+	if result.BlockHash != "" {
+		header, err := c.GetBlockHeaderByHash(result.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		info.Height = header.Height
+	}
+	return info, nil
+}
+
+func (c *BitcoinCoreClient) SendRawTransaction(raw []byte) (string, error) {
+	var txId string
+	if err := c.call(&txId, "sendrawtransaction", hex.EncodeToString(raw)); err != nil {
+		return "", err
+	}
+	return txId, nil
+}
+
+func (c *BitcoinCoreClient) EstimateFeeRate(blocks int) (float64, error) {
+	var result struct {
+		FeeRate json.Number `json:"feerate"`
+		Errors  []string    `json:"errors"`
+	}
+	if err := c.call(&result, "estimatesmartfee", blocks); err != nil {
+		return 0, err
+	}
+	if result.FeeRate == "" {
+		return 0, fmt.Errorf("estimatesmartfee: %s", strings.Join(result.Errors, ", "))
+	}
+	return feeRatePerKvB(result.FeeRate)
+}
+
+func (c *BitcoinCoreClient) GetBestHeight() (uint64, error) {
+	var height uint64
+	if err := c.call(&height, "getblockcount"); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+func (c *BitcoinCoreClient) GetBlockHeader(height uint64) (*BlockHeader, error) {
+	var hash string
+	if err := c.call(&hash, "getblockhash", height); err != nil {
+		return nil, err
+	}
+	return c.GetBlockHeaderByHash(hash)
+}
+
+func (c *BitcoinCoreClient) GetBlockHeaderByHash(hash string) (*BlockHeader, error) {
+	var result struct {
+		Hash              string `json:"hash"`
+		PreviousBlockHash string `json:"previousblockhash"`
+		MerkleRoot        string `json:"merkleroot"`
+		Height            uint64 `json:"height"`
+		Time              uint32 `json:"time"`
+		Bits              string `json:"bits"`
+		Nonce             uint32 `json:"nonce"`
+		Version           int32  `json:"version"`
+	}
+	if err := c.call(&result, "getblockheader", hash, true); err != nil {
+		return nil, err
+	}
+	bits, err := strconv.ParseUint(result.Bits, 16, 32)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockHeader{
+		Hash:       result.Hash,
+		PrevHash:   result.PreviousBlockHash,
+		MerkleRoot: result.MerkleRoot,
+		Height:     result.Height,
+		Time:       result.Time,
+		Bits:       uint32(bits),
+		Nonce:      result.Nonce,
+		Version:    result.Version,
+	}, nil
+}
+
+// GetBlock 解析原始区块, 不支持 AuxPoW 区块头与 MWEB 扩展数据
+func (c *BitcoinCoreClient) GetBlock(hash string) (*Block, error) {
+	var rawBlock string
+	if err := c.call(&rawBlock, "getblock", hash, 0); err != nil {
+		return nil, err
+	}
+	raw, err := hex.DecodeString(rawBlock)
+	if err != nil {
+		return nil, err
+	}
+	block, err := DeserializeBlock(raw)
+	if err != nil {
+		return nil, err
+	}
+	if block.Header, err = c.GetBlockHeaderByHash(hash); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
@@ -0,0 +1,156 @@
+package utxo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRequestTimeout = 10 * time.Second
+
+	blockHeaderSize = 80
+	satoshiPerCoin  = 100_000_000
+)
+
+var (
+	ErrTxNotFound  = errors.New("utxo transaction not found")
+	ErrUnsupported = errors.New("not supported by utxo backend")
+)
+
+type BlockHeader struct {
+	Hash       string
+	PrevHash   string
+	MerkleRoot string
+	Height     uint64
+	Time       uint32
+	Bits       uint32
+	Nonce      uint32
+	Version    int32
+}
+
+type Block struct {
+	Header *BlockHeader
+	Txs    []*MsgTx
+}
+
+// TxInfo 未打包时 BlockHash 为空且 Confirmations 为 0
+type TxInfo struct {
+	Tx            *MsgTx
+	BlockHash     string
+	Height        uint64
+	Confirmations uint64
+	Time          uint64
+}
+
+// HistoryItem 地址相关的交易, Height 为 0 表示在内存池中
+type HistoryItem struct {
+	TxId   string
+	Height uint64
+}
+
+/**
+ * @description: UTXO 链节点接口, 由 Bitcoin Core 兼容的 JSON-RPC 或 Electrum 服务实现
+ * 两种后端的能力不同, 不支持的查询返回 ErrUnsupported
+ */
+type UtxoClient interface {
+	ListUnspent(address string, pkScript []byte) ([]*Utxo, error)
+	GetHistory(address string, pkScript []byte) ([]*HistoryItem, error)
+	GetTransaction(txId string) (*TxInfo, error)
+	SendRawTransaction(raw []byte) (string, error)
+	// EstimateFeeRate 单位 sat/vB
+	EstimateFeeRate(blocks int) (float64, error)
+	GetBestHeight() (uint64, error)
+	GetBlockHeader(height uint64) (*BlockHeader, error)
+	GetBlockHeaderByHash(hash string) (*BlockHeader, error)
+	GetBlock(hash string) (*Block, error)
+}
+
+// NewUtxoClient tcp:// 与 ssl:// (tls://) 地址使用 Electrum 协议, 其余按 Bitcoin Core JSON-RPC 处理
+func NewUtxoClient(url, user, password string, timeout time.Duration) (UtxoClient, error) {
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	for _, scheme := range []string{"tcp://", "ssl://", "tls://"} {
+		if strings.HasPrefix(url, scheme) {
+			return NewElectrumClient(strings.TrimPrefix(url, scheme), scheme != "tcp://", timeout)
+		}
+	}
+	return NewBitcoinCoreClient(url, user, password, timeout), nil
+}
+
+// ParseBlockHeader 解析 80 字节区块头, 高度不在区块头中, 由调用方填写
+func ParseBlockHeader(raw []byte) (*BlockHeader, error) {
+	if len(raw) != blockHeaderSize {
+		return nil, errors.New("invalid block header")
+	}
+	return &BlockHeader{
+		Hash:       hex.EncodeToString(reverseBytes(DoubleSha256(raw))),
+		Version:    int32(binary.LittleEndian.Uint32(raw[0:4])),
+		PrevHash:   hex.EncodeToString(reverseBytes(raw[4:36])),
+		MerkleRoot: hex.EncodeToString(reverseBytes(raw[36:68])),
+		Time:       binary.LittleEndian.Uint32(raw[68:72]),
+		Bits:       binary.LittleEndian.Uint32(raw[72:76]),
+		Nonce:      binary.LittleEndian.Uint32(raw[76:80]),
+	}, nil
+}
+
+// DeserializeBlock 区块头与交易列表
+func DeserializeBlock(raw []byte) (*Block, error) {
+	if len(raw) < blockHeaderSize {
+		return nil, errMalformedTx
+	}
+	header, err := ParseBlockHeader(raw[:blockHeaderSize])
+	if err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(raw[blockHeaderSize:])
+	count, err := readVarInt(r)
+	if err != nil || count > uint64(r.Len()) {
+		return nil, errMalformedTx
+	}
+	block := &Block{Header: header}
+	for i := uint64(0); i < count; i++ {
+		tx, err := readTx(r)
+		if err != nil {
+			return nil, err
+		}
+		block.Txs = append(block.Txs, tx)
+	}
+	return block, nil
+}
+
+// CoinToSatoshi 节点返回的金额为 8 位小数的币单位, 按十进制解析避免浮点误差
+func CoinToSatoshi(amount json.Number) (int64, error) {
+	value, ok := new(big.Rat).SetString(amount.String())
+	if !ok {
+		return 0, errors.New("invalid amount")
+	}
+	value.Mul(value, new(big.Rat).SetInt64(satoshiPerCoin))
+	if !value.IsInt() {
+		return 0, errors.New("amount precision exceeds satoshi")
+	}
+	return value.Num().Int64(), nil
+}
+
+// SatoshiToCoin 8 位小数的币单位字符串
+func SatoshiToCoin(value int64) string {
+	return new(big.Rat).SetFrac64(value, satoshiPerCoin).FloatString(8)
+}
+
+// feeRatePerKvB 节点返回的费率单位为 coin/kvB, 转换为 sat/vB
+func feeRatePerKvB(rate json.Number) (float64, error) {
+	value, err := rate.Float64()
+	if err != nil {
+		return 0, err
+	}
+	if value <= 0 {
+		return 0, errors.New("fee rate unavailable")
+	}
+	return value * satoshiPerCoin / 1000, nil
+}
@@ -0,0 +1,220 @@
+package utxo
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+const (
+	// BnB 最大搜索次数, 与 Bitcoin Core 一致
+	bnbTotalTries = 100_000
+
+	// 交易固定部分 version + locktime + 输入输出数量, 单位 weight
+	txOverheadWeight = (4 + 4 + 1 + 1) * 4
+	// segwit marker 与 flag
+	witnessOverheadWeight = 2
+)
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+type Utxo struct {
+	TxId          string
+	Index         uint32
+	Value         int64
+	PkScript      []byte
+	Height        uint64
+	Confirmations uint64
+}
+
+// InputWeight 花费该类型输出的输入 weight, ECDSA 签名按最长 72 字节 DER 加 sighash 字节估算
+func InputWeight(addressType AddressType) int64 {
+	switch addressType {
+	case AddressP2SHP2WPKH:
+		return (32+4+1+23+4)*4 + 1 + 1 + 73 + 1 + 33
+	case AddressP2WPKH:
+		return (32+4+1+4)*4 + 1 + 1 + 73 + 1 + 33
+	case AddressP2TR:
+		return (32+4+1+4)*4 + 1 + 1 + 64
+	}
+	return (32 + 4 + 1 + 1 + 73 + 1 + 33 + 4) * 4
+}
+
+func OutputWeight(pkScript []byte) int64 {
+	return int64(8+varIntSize(uint64(len(pkScript)))+len(pkScript)) * 4
+}
+
+// FeeForWeight feeRate 单位 sat/vB, 向上取整
+func FeeForWeight(weight int64, feeRate float64) int64 {
+	return int64(math.Ceil(float64(weight) * feeRate / 4))
+}
+
+type CoinSelectionRequest struct {
+	Utxos []*Utxo
+	// 所有输入属于同一地址, 类型相同
+	InputType    AddressType
+	Outputs      []*TxOut
+	ChangeScript []byte
+	FeeRate      float64
+	// LongTermFeeRate 用于计算 waste, 为 0 时与 FeeRate 相同
+	LongTermFeeRate float64
+	Params          *NetParams
+}
+
+type CoinSelectionResult struct {
+	Selected []*Utxo
+	// 为 0 时不产生找零输出
+	Change int64
+	Fee    int64
+}
+
+/**
+ * @description: 选择花费的 UTXO
+ * 先使用 branch and bound 寻找不需要找零的组合 (输入有效金额之和落在 [目标, 目标 + 找零成本] 内),
+ * 找不到时按有效金额从大到小累加并产生找零, 找零低于粉尘阈值时并入手续费
+ */
+func SelectCoins(req *CoinSelectionRequest) (*CoinSelectionResult, error) {
+	if len(req.Outputs) == 0 {
+		return nil, errors.New("no outputs")
+	}
+	longTermFeeRate := req.LongTermFeeRate
+	if longTermFeeRate == 0 {
+		longTermFeeRate = req.FeeRate
+	}
+	inputWeight := InputWeight(req.InputType)
+	inputFee := FeeForWeight(inputWeight, req.FeeRate)
+
+	baseWeight := int64(txOverheadWeight)
+	if req.InputType != AddressP2PKH {
+		baseWeight += witnessOverheadWeight
+	}
+	var payment int64
+	for _, out := range req.Outputs {
+		if out.Value <= 0 || uint64(out.Value) < req.Params.DustLimit(out.PkScript) {
+			return nil, errors.New("output amount below dust limit")
+		}
+		payment += out.Value
+		baseWeight += OutputWeight(out.PkScript)
+	}
+	target := payment + FeeForWeight(baseWeight, req.FeeRate)
+
+	var candidates []*Utxo
+	for _, u := range req.Utxos {
+		if u.Value > inputFee {
+			candidates = append(candidates, u)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Value > candidates[j].Value
+	})
+
+	changeFee := FeeForWeight(OutputWeight(req.ChangeScript), req.FeeRate)
+	costOfChange := changeFee + FeeForWeight(inputWeight, longTermFeeRate)
+	if selected := branchAndBound(candidates, inputFee, target, costOfChange, inputFee-FeeForWeight(inputWeight, longTermFeeRate)); selected != nil {
+		var total int64
+		for _, u := range selected {
+			total += u.Value
+		}
+		return &CoinSelectionResult{Selected: selected, Fee: total - payment}, nil
+	}
+
+	var selected []*Utxo
+	var effective int64
+	for _, u := range candidates {
+		selected = append(selected, u)
+		effective += u.Value - inputFee
+		if effective >= target+changeFee {
+			break
+		}
+	}
+	if effective < target {
+		return nil, ErrInsufficientFunds
+	}
+	var total int64
+	for _, u := range selected {
+		total += u.Value
+	}
+	change := effective - target - changeFee
+	if change < 0 || uint64(change) < req.Params.DustLimit(req.ChangeScript) {
+		return &CoinSelectionResult{Selected: selected, Fee: total - payment}, nil
+	}
+	return &CoinSelectionResult{Selected: selected, Change: change, Fee: total - payment - change}, nil
+}
+
+/**
+ * @description: Bitcoin Core 的 branch and bound, 候选按有效金额降序深度优先搜索
+ * waste = 输入数 * (当前费率输入费 - 长期费率输入费) + 超出目标的金额, 返回 waste 最小的组合, 无解返回 nil
+ */
+func branchAndBound(candidates []*Utxo, inputFee, target, costOfChange, inputWaste int64) []*Utxo {
+	effective := make([]int64, len(candidates))
+	var available int64
+	for i, u := range candidates {
+		effective[i] = u.Value - inputFee
+		available += effective[i]
+	}
+	if available < target {
+		return nil
+	}
+
+	var best []bool
+	bestWaste := int64(math.MaxInt64)
+	selection := make([]bool, len(candidates))
+	var selectedValue, currentWaste int64
+	depth := 0
+	for tries := 0; tries < bnbTotalTries; tries++ {
+		backtrack := false
+		switch {
+		case selectedValue+available < target || selectedValue > target+costOfChange ||
+			(currentWaste > bestWaste && inputWaste > 0):
+			backtrack = true
+		case selectedValue >= target:
+			waste := currentWaste + selectedValue - target
+			if waste <= bestWaste {
+				best = append(best[:0], selection...)
+				bestWaste = waste
+			}
+			backtrack = true
+		}
+
+		if backtrack {
+			// 回退到最近一个被选中的候选, 改为不选
+			for depth > 0 && !selection[depth-1] {
+				depth--
+				available += effective[depth]
+			}
+			if depth == 0 {
+				break
+			}
+			depth--
+			selection[depth] = false
+			selectedValue -= effective[depth]
+			currentWaste -= inputWaste
+			depth++
+			continue
+		}
+
+		if depth >= len(candidates) {
+			continue
+		}
+		available -= effective[depth]
+		// 与前一个未选中的候选金额相同时, 选择它得到的组合已搜索过
+		if depth > 0 && !selection[depth-1] && effective[depth] == effective[depth-1] {
+			selection[depth] = false
+		} else {
+			selection[depth] = true
+			selectedValue += effective[depth]
+			currentWaste += inputWaste
+		}
+		depth++
+	}
+	if best == nil {
+		return nil
+	}
+	var selected []*Utxo
+	for i, chosen := range best {
+		if chosen {
+			selected = append(selected, candidates[i])
+		}
+	}
+	return selected
+}
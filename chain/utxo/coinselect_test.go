@@ -0,0 +1,138 @@
+package utxo
+
+import (
+	"errors"
+	"sort"
+	"testing"
+)
+
+func testUtxos(values ...int64) []*Utxo {
+	var utxos []*Utxo
+	for i, value := range values {
+		utxos = append(utxos, &Utxo{TxId: "tx", Index: uint32(i), Value: value})
+	}
+	return utxos
+}
+
+func selectedValues(result *CoinSelectionResult) []int64 {
+	var values []int64
+	for _, u := range result.Selected {
+		values = append(values, u.Value)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] > values[j] })
+	return values
+}
+
+// 费率为 0 时有效金额等于面值, 选中的组合须落在 [目标, 目标 + 找零成本] 内
+func TestBranchAndBound(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       int64
+		costOfChange int64
+		found        bool
+	}{
+		{"single exact", 3_000_000, 0, true},
+		{"pair exact", 5_000_000, 0, true},
+		{"all", 10_000_000, 0, true},
+		{"within cost of change", 2_999_500, 1_000, true},
+		{"no exact match", 2_500_000, 1_000, false},
+		{"more than available", 10_000_001, 1_000, false},
+	}
+	for _, tt := range tests {
+		candidates := testUtxos(4_000_000, 3_000_000, 2_000_000, 1_000_000)
+		selected := branchAndBound(candidates, 0, tt.target, tt.costOfChange, 0)
+		if !tt.found {
+			if selected != nil {
+				t.Errorf("%s: selected %d inputs, want none", tt.name, len(selected))
+			}
+			continue
+		}
+		var total int64
+		for _, u := range selected {
+			total += u.Value
+		}
+		if total < tt.target || total > tt.target+tt.costOfChange {
+			t.Errorf("%s: selected total %d, want within [%d, %d]", tt.name, total, tt.target, tt.target+tt.costOfChange)
+		}
+	}
+}
+
+// 输入费高于长期费率时 waste 为正, 应选择输入更少的组合
+func TestBranchAndBoundPrefersFewerInputs(t *testing.T) {
+	candidates := testUtxos(3_000_000, 2_000_000, 1_000_000)
+	selected := branchAndBound(candidates, 0, 3_000_000, 0, 100)
+	if len(selected) != 1 || selected[0].Value != 3_000_000 {
+		t.Errorf("selected %v, want single 3000000 input", selected)
+	}
+}
+
+func TestSelectCoins(t *testing.T) {
+	params := BitcoinMainNetParams
+	script := mustHex(t, "0014751e76e8199196d454941c45d1b3a323f1433bd6")
+	inputFee := FeeForWeight(InputWeight(AddressP2WPKH), 1)
+	baseFee := FeeForWeight(txOverheadWeight+witnessOverheadWeight+OutputWeight(script), 1)
+	changeFee := FeeForWeight(OutputWeight(script), 1)
+	const payment = 100_000
+	request := func(values ...int64) *CoinSelectionRequest {
+		return &CoinSelectionRequest{
+			Utxos:        testUtxos(values...),
+			InputType:    AddressP2WPKH,
+			Outputs:      []*TxOut{{Value: payment, PkScript: script}},
+			ChangeScript: script,
+			FeeRate:      1,
+			Params:       params,
+		}
+	}
+
+	t.Run("exact match without change", func(t *testing.T) {
+		exact := payment + baseFee + inputFee
+		result, err := SelectCoins(request(500_000, exact, 50_000))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := selectedValues(result); len(got) != 1 || got[0] != exact || result.Change != 0 || result.Fee != baseFee+inputFee {
+			t.Errorf("selected %v change %d fee %d", got, result.Change, result.Fee)
+		}
+	})
+	t.Run("largest first with change", func(t *testing.T) {
+		result, err := SelectCoins(request(30_000, 500_000, 80_000))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := int64(500_000) - inputFee - payment - baseFee - changeFee
+		if got := selectedValues(result); len(got) != 1 || got[0] != 500_000 || result.Change != want {
+			t.Errorf("selected %v change %d, want change %d", got, result.Change, want)
+		}
+		if result.Fee != baseFee+inputFee+changeFee {
+			t.Errorf("fee = %d", result.Fee)
+		}
+	})
+	t.Run("dust change goes to fee", func(t *testing.T) {
+		// 超出目标的金额高于 BnB 的找零成本, 但扣除找零输出后低于粉尘阈值
+		value := payment + baseFee + inputFee + changeFee + inputFee + 50
+		result, err := SelectCoins(request(value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Change != 0 || result.Fee != value-payment {
+			t.Errorf("change %d fee %d", result.Change, result.Fee)
+		}
+	})
+	t.Run("uneconomic utxos ignored", func(t *testing.T) {
+		if _, err := SelectCoins(request(inputFee, inputFee, inputFee)); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("err = %v, want ErrInsufficientFunds", err)
+		}
+	})
+	t.Run("insufficient funds", func(t *testing.T) {
+		if _, err := SelectCoins(request(40_000, 50_000)); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("err = %v, want ErrInsufficientFunds", err)
+		}
+	})
+	t.Run("dust output", func(t *testing.T) {
+		req := request(500_000)
+		req.Outputs[0].Value = 100
+		if _, err := SelectCoins(req); err == nil {
+			t.Error("dust output should be rejected")
+		}
+	})
+}
@@ -0,0 +1,296 @@
+package utxo
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	electrumClientName      = "wallet-chain-account"
+	electrumProtocolVersion = "1.4"
+)
+
+type electrumError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *electrumError) Error() string {
+	return fmt.Sprintf("electrum error %d: %s", e.Code, e.Message)
+}
+
+/**
+ * @description: Electrum 协议客户端 (ElectrumX/Fulcrum/electrs), 基于 TCP 或 TLS 的按行分隔 JSON-RPC
+ * 地址按 scripthash (锁定脚本 sha256 的字节逆序) 查询; 请求串行发送, 连接断开后下次请求时重连
+ * 服务不提供完整区块, GetBlock 与按哈希查询区块头返回 ErrUnsupported
+ */
+type ElectrumClient struct {
+	address string
+	useTls  bool
+	timeout time.Duration
+
+	lock   sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	nextId uint64
+}
+
+func NewElectrumClient(address string, useTls bool, timeout time.Duration) (*ElectrumClient, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, err
+	}
+	return &ElectrumClient{address: address, useTls: useTls, timeout: timeout}, nil
+}
+
+// ElectrumScriptHash 锁定脚本 sha256 的字节逆序 hex
+func ElectrumScriptHash(pkScript []byte) string {
+	hash := sha256.Sum256(pkScript)
+	return hex.EncodeToString(reverseBytes(hash[:]))
+}
+
+func (c *ElectrumClient) connect() error {
+	dialer := &net.Dialer{Timeout: c.timeout}
+	var conn net.Conn
+	var err error
+	if c.useTls {
+		host, _, _ := net.SplitHostPort(c.address)
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.address, &tls.Config{ServerName: host})
+	} else {
+		conn, err = dialer.Dial("tcp", c.address)
+	}
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+	// 协议要求连接后首先协商版本
+	if err := c.roundTrip(nil, "server.version", electrumClientName, electrumProtocolVersion); err != nil {
+		c.close()
+		return err
+	}
+	return nil
+}
+
+func (c *ElectrumClient) close() {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.conn = nil
+	c.reader = nil
+}
+
+func (c *ElectrumClient) call(result interface{}, method string, params ...interface{}) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.conn == nil {
+		if err := c.connect(); err != nil {
+			return err
+		}
+	}
+	err := c.roundTrip(result, method, params...)
+	var rpcErr *electrumError
+	if err != nil && !errors.As(err, &rpcErr) {
+		// 网络错误后连接状态未知, 丢弃连接
+		log.Warn("electrum connection reset", "method", method, "err", err)
+		c.close()
+	}
+	return err
+}
+
+func (c *ElectrumClient) roundTrip(result interface{}, method string, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	c.nextId++
+	id := c.nextId
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return err
+	}
+	if _, err := c.conn.Write(append(body, '\n')); err != nil {
+		return err
+	}
+	for {
+		line, err := c.reader.ReadBytes('\n')
+		if err != nil {
+			return err
+		}
+		var response struct {
+			Id     *uint64         `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  *electrumError  `json:"error"`
+		}
+		if err := json.Unmarshal(line, &response); err != nil {
+			return err
+		}
+		// 跳过订阅通知
+		if response.Id == nil || *response.Id != id {
+			continue
+		}
+		if response.Error != nil {
+			return response.Error
+		}
+		if result == nil {
+			return nil
+		}
+		decoder := json.NewDecoder(strings.NewReader(string(response.Result)))
+		decoder.UseNumber()
+		return decoder.Decode(result)
+	}
+}
+
+func (c *ElectrumClient) ListUnspent(address string, pkScript []byte) ([]*Utxo, error) {
+	var unspents []struct {
+		TxHash string `json:"tx_hash"`
+		TxPos  uint32 `json:"tx_pos"`
+		Height int64  `json:"height"`
+		Value  int64  `json:"value"`
+	}
+	if err := c.call(&unspents, "blockchain.scripthash.listunspent", ElectrumScriptHash(pkScript)); err != nil {
+		return nil, err
+	}
+	bestHeight, err := c.GetBestHeight()
+	if err != nil {
+		return nil, err
+	}
+	var utxos []*Utxo
+	for _, unspent := range unspents {
+		u := &Utxo{
+			TxId:     unspent.TxHash,
+			Index:    unspent.TxPos,
+			Value:    unspent.Value,
+			PkScript: pkScript,
+		}
+		// 内存池中的输出高度为 0 或 -1 (依赖未确认交易)
+		if unspent.Height > 0 {
+			u.Height = uint64(unspent.Height)
+			u.Confirmations = bestHeight - u.Height + 1
+		}
+		utxos = append(utxos, u)
+	}
+	return utxos, nil
+}
+
+func (c *ElectrumClient) GetHistory(address string, pkScript []byte) ([]*HistoryItem, error) {
+	var history []struct {
+		TxHash string `json:"tx_hash"`
+		Height int64  `json:"height"`
+	}
+	if err := c.call(&history, "blockchain.scripthash.get_history", ElectrumScriptHash(pkScript)); err != nil {
+		return nil, err
+	}
+	var items []*HistoryItem
+	for _, h := range history {
+		item := &HistoryItem{TxId: h.TxHash}
+		if h.Height > 0 {
+			item.Height = uint64(h.Height)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// GetTransaction verbose 查询依赖服务端连接的节点, 确认数与区块哈希由节点返回
+func (c *ElectrumClient) GetTransaction(txId string) (*TxInfo, error) {
+	var result struct {
+		Hex           string `json:"hex"`
+		BlockHash     string `json:"blockhash"`
+		Confirmations uint64 `json:"confirmations"`
+		BlockTime     uint64 `json:"blocktime"`
+	}
+	if err := c.call(&result, "blockchain.transaction.get", txId, true); err != nil {
+		var rpcErr *electrumError
+		if errors.As(err, &rpcErr) {
+			return nil, ErrTxNotFound
+		}
+		return nil, err
+	}
+	raw, err := hex.DecodeString(result.Hex)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := DeserializeTx(raw)
+	if err != nil {
+		return nil, err
+	}
+	info := &TxInfo{Tx: tx, BlockHash: result.BlockHash, Confirmations: result.Confirmations, Time: result.BlockTime}
+	if result.Confirmations > 0 {
+		bestHeight, err := c.GetBestHeight()
+		if err != nil {
+			return nil, err
+		}
+		info.Height = bestHeight - result.Confirmations + 1
+	}
+	return info, nil
+}
+
+func (c *ElectrumClient) SendRawTransaction(raw []byte) (string, error) {
+	var txId string
+	if err := c.call(&txId, "blockchain.transaction.broadcast", hex.EncodeToString(raw)); err != nil {
+		return "", err
+	}
+	return txId, nil
+}
+
+// EstimateFeeRate 服务端无法估算时返回 -1
+func (c *ElectrumClient) EstimateFeeRate(blocks int) (float64, error) {
+	var rate json.Number
+	if err := c.call(&rate, "blockchain.estimatefee", blocks); err != nil {
+		return 0, err
+	}
+	return feeRatePerKvB(rate)
+}
+
+func (c *ElectrumClient) GetBestHeight() (uint64, error) {
+	var tip struct {
+		Height uint64 `json:"height"`
+	}
+	if err := c.call(&tip, "blockchain.headers.subscribe"); err != nil {
+		return 0, err
+	}
+	return tip.Height, nil
+}
+
+func (c *ElectrumClient) GetBlockHeader(height uint64) (*BlockHeader, error) {
+	var rawHeader string
+	if err := c.call(&rawHeader, "blockchain.block.header", height); err != nil {
+		return nil, err
+	}
+	raw, err := hex.DecodeString(rawHeader)
+	if err != nil {
+		return nil, err
+	}
+	header, err := ParseBlockHeader(raw)
+	if err != nil {
+		return nil, err
+	}
+	header.Height = height
+	return header, nil
+}
+
+func (c *ElectrumClient) GetBlockHeaderByHash(hash string) (*BlockHeader, error) {
+	return nil, ErrUnsupported
+}
+
+func (c *ElectrumClient) GetBlock(hash string) (*Block, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,98 @@
+package utxo
+
+// NetParams 各 UTXO 链的地址版本与交易规则
+type NetParams struct {
+	Name             string
+	PubKeyHashAddrID byte
+	ScriptHashAddrID byte
+	// 为空时不支持 segwit 地址
+	Bech32HRP      string
+	SupportTaproot bool
+	// BCH 使用带 SIGHASH_FORKID 的 BIP143 签名摘要, 地址仅支持 legacy 格式
+	ForkId bool
+	// 粉尘阈值按 dust relay fee (sat/kvB) 计算, FixedDustLimit 不为 0 时直接使用
+	DustRelayFee   uint64
+	FixedDustLimit uint64
+}
+
+var (
+	BitcoinMainNetParams = &NetParams{
+		Name:             "mainnet",
+		PubKeyHashAddrID: 0x00,
+		ScriptHashAddrID: 0x05,
+		Bech32HRP:        "bc",
+		SupportTaproot:   true,
+		DustRelayFee:     3000,
+	}
+	BitcoinTestNetParams = &NetParams{
+		Name:             "testnet",
+		PubKeyHashAddrID: 0x6f,
+		ScriptHashAddrID: 0xc4,
+		Bech32HRP:        "tb",
+		SupportTaproot:   true,
+		DustRelayFee:     3000,
+	}
+	BitcoinRegressionNetParams = &NetParams{
+		Name:             "regtest",
+		PubKeyHashAddrID: 0x6f,
+		ScriptHashAddrID: 0xc4,
+		Bech32HRP:        "bcrt",
+		SupportTaproot:   true,
+		DustRelayFee:     3000,
+	}
+	LitecoinMainNetParams = &NetParams{
+		Name:             "litecoin",
+		PubKeyHashAddrID: 0x30,
+		ScriptHashAddrID: 0x32,
+		Bech32HRP:        "ltc",
+		SupportTaproot:   true,
+		DustRelayFee:     3000,
+	}
+	DogecoinMainNetParams = &NetParams{
+		Name:             "dogecoin",
+		PubKeyHashAddrID: 0x1e,
+		ScriptHashAddrID: 0x16,
+		FixedDustLimit:   1_000_000,
+	}
+	BitcoinCashMainNetParams = &NetParams{
+		Name:             "bitcoincash",
+		PubKeyHashAddrID: 0x00,
+		ScriptHashAddrID: 0x05,
+		ForkId:           true,
+		FixedDustLimit:   546,
+	}
+)
+
+// NetParamsByName 按配置中的网络名称查找
+func NetParamsByName(name string) (*NetParams, bool) {
+	for _, params := range []*NetParams{
+		BitcoinMainNetParams,
+		BitcoinTestNetParams,
+		BitcoinRegressionNetParams,
+		LitecoinMainNetParams,
+		DogecoinMainNetParams,
+		BitcoinCashMainNetParams,
+	} {
+		if params.Name == name {
+			return params, true
+		}
+	}
+	return nil, false
+}
+
+/**
+ * @description: 输出的粉尘阈值, 与 Bitcoin Core 的 GetDustThreshold 一致
+ * 花费该输出的输入大小: witness 输出按 67 vbytes, 其余按 148 bytes
+ */
+func (p *NetParams) DustLimit(pkScript []byte) uint64 {
+	if p.FixedDustLimit > 0 {
+		return p.FixedDustLimit
+	}
+	size := uint64(8 + varIntSize(uint64(len(pkScript))) + len(pkScript))
+	if isWitnessProgram(pkScript) {
+		size += 32 + 4 + 1 + 107/4 + 4
+	} else {
+		size += 32 + 4 + 1 + 107 + 4
+	}
+	return size * p.DustRelayFee / 1000
+}
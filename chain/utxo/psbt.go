@@ -0,0 +1,687 @@
+package utxo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// BIP174 PSBT v0
+const (
+	psbtMagic = "psbt\xff"
+
+	psbtGlobalUnsignedTx = 0x00
+
+	psbtInNonWitnessUtxo     = 0x00
+	psbtInWitnessUtxo        = 0x01
+	psbtInPartialSig         = 0x02
+	psbtInSighashType        = 0x03
+	psbtInRedeemScript       = 0x04
+	psbtInBip32Derivation    = 0x06
+	psbtInFinalScriptSig     = 0x07
+	psbtInFinalScriptWitness = 0x08
+	psbtInTapKeySig          = 0x13
+	psbtInTapInternalKey     = 0x17
+
+	psbtOutRedeemScript    = 0x00
+	psbtOutBip32Derivation = 0x02
+	psbtOutTapInternalKey  = 0x05
+
+	psbtProprietary = 0xfc
+	// 本服务写入的 proprietary 字段前缀, 子类型 0x00 为输入的签名摘要, 便于只签哈希的签名机使用
+	proprietaryIdentifier  = "wca"
+	proprietarySigningHash = 0x00
+)
+
+var errMalformedPsbt = errors.New("malformed psbt")
+
+type KeyValue struct {
+	Key   []byte
+	Value []byte
+}
+
+type PartialSig struct {
+	PubKey    []byte
+	Signature []byte
+}
+
+// Bip32Derivation 未知派生路径时指纹为 0 且路径为空, 仅用于标明输入对应的公钥
+type Bip32Derivation struct {
+	PubKey      []byte
+	Fingerprint uint32
+	Path        []uint32
+}
+
+type PsbtInput struct {
+	NonWitnessUtxo     *MsgTx
+	WitnessUtxo        *TxOut
+	PartialSigs        []*PartialSig
+	SighashType        uint32
+	RedeemScript       []byte
+	Bip32Derivation    []*Bip32Derivation
+	FinalScriptSig     []byte
+	FinalScriptWitness [][]byte
+	TapKeySig          []byte
+	TapInternalKey     []byte
+	Unknowns           []*KeyValue
+}
+
+type PsbtOutput struct {
+	RedeemScript    []byte
+	Bip32Derivation []*Bip32Derivation
+	TapInternalKey  []byte
+	Unknowns        []*KeyValue
+}
+
+type Psbt struct {
+	UnsignedTx *MsgTx
+	Unknowns   []*KeyValue
+	Inputs     []*PsbtInput
+	Outputs    []*PsbtOutput
+}
+
+// NewPsbt 未签名交易的 scriptSig 与 witness 必须为空
+func NewPsbt(tx *MsgTx) (*Psbt, error) {
+	for _, in := range tx.TxIn {
+		if len(in.SignatureScript) > 0 || len(in.Witness) > 0 {
+			return nil, errors.New("psbt unsigned tx must not contain signatures")
+		}
+	}
+	p := &Psbt{UnsignedTx: tx}
+	for range tx.TxIn {
+		p.Inputs = append(p.Inputs, &PsbtInput{})
+	}
+	for range tx.TxOut {
+		p.Outputs = append(p.Outputs, &PsbtOutput{})
+	}
+	return p, nil
+}
+
+func (p *Psbt) B64Encode() string {
+	return base64.StdEncoding.EncodeToString(p.Serialize())
+}
+
+func (p *Psbt) Serialize() []byte {
+	var w bytes.Buffer
+	w.WriteString(psbtMagic)
+	writeKeyValue(&w, []byte{psbtGlobalUnsignedTx}, p.UnsignedTx.SerializeNoWitness())
+	writeUnknowns(&w, p.Unknowns)
+	w.WriteByte(0x00)
+	for _, in := range p.Inputs {
+		if in.NonWitnessUtxo != nil {
+			writeKeyValue(&w, []byte{psbtInNonWitnessUtxo}, in.NonWitnessUtxo.Serialize())
+		}
+		if in.WitnessUtxo != nil {
+			var out bytes.Buffer
+			writeTxOut(&out, in.WitnessUtxo)
+			writeKeyValue(&w, []byte{psbtInWitnessUtxo}, out.Bytes())
+		}
+		for _, sig := range in.PartialSigs {
+			writeKeyValue(&w, append([]byte{psbtInPartialSig}, sig.PubKey...), sig.Signature)
+		}
+		if in.SighashType != 0 {
+			var value bytes.Buffer
+			writeUint32(&value, in.SighashType)
+			writeKeyValue(&w, []byte{psbtInSighashType}, value.Bytes())
+		}
+		if in.RedeemScript != nil {
+			writeKeyValue(&w, []byte{psbtInRedeemScript}, in.RedeemScript)
+		}
+		writeBip32Derivation(&w, psbtInBip32Derivation, in.Bip32Derivation)
+		if in.FinalScriptSig != nil {
+			writeKeyValue(&w, []byte{psbtInFinalScriptSig}, in.FinalScriptSig)
+		}
+		if in.FinalScriptWitness != nil {
+			var value bytes.Buffer
+			writeVarInt(&value, uint64(len(in.FinalScriptWitness)))
+			for _, item := range in.FinalScriptWitness {
+				writeVarBytes(&value, item)
+			}
+			writeKeyValue(&w, []byte{psbtInFinalScriptWitness}, value.Bytes())
+		}
+		if in.TapKeySig != nil {
+			writeKeyValue(&w, []byte{psbtInTapKeySig}, in.TapKeySig)
+		}
+		if in.TapInternalKey != nil {
+			writeKeyValue(&w, []byte{psbtInTapInternalKey}, in.TapInternalKey)
+		}
+		writeUnknowns(&w, in.Unknowns)
+		w.WriteByte(0x00)
+	}
+	for _, out := range p.Outputs {
+		if out.RedeemScript != nil {
+			writeKeyValue(&w, []byte{psbtOutRedeemScript}, out.RedeemScript)
+		}
+		writeBip32Derivation(&w, psbtOutBip32Derivation, out.Bip32Derivation)
+		if out.TapInternalKey != nil {
+			writeKeyValue(&w, []byte{psbtOutTapInternalKey}, out.TapInternalKey)
+		}
+		writeUnknowns(&w, out.Unknowns)
+		w.WriteByte(0x00)
+	}
+	return w.Bytes()
+}
+
+func DecodePsbtBase64(encoded string) (*Psbt, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return DecodePsbt(raw)
+}
+
+/**
+ * @description: 解析 PSBT v0
+ * 未识别的字段原样保留, 重新序列化时写回; 同一 map 中出现重复 key 视为格式错误
+ */
+func DecodePsbt(raw []byte) (*Psbt, error) {
+	if !bytes.HasPrefix(raw, []byte(psbtMagic)) {
+		return nil, errMalformedPsbt
+	}
+	r := bytes.NewReader(raw[len(psbtMagic):])
+	p := &Psbt{}
+	globals, err := readKeyValueMap(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, kv := range globals {
+		if kv.Key[0] == psbtGlobalUnsignedTx {
+			if len(kv.Key) != 1 {
+				return nil, errMalformedPsbt
+			}
+			if p.UnsignedTx, err = DeserializeTx(kv.Value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		p.Unknowns = append(p.Unknowns, kv)
+	}
+	if p.UnsignedTx == nil {
+		return nil, errors.New("psbt missing unsigned tx")
+	}
+	if _, err := NewPsbt(p.UnsignedTx); err != nil {
+		return nil, err
+	}
+	for range p.UnsignedTx.TxIn {
+		pairs, err := readKeyValueMap(r)
+		if err != nil {
+			return nil, err
+		}
+		in, err := parsePsbtInput(pairs)
+		if err != nil {
+			return nil, err
+		}
+		p.Inputs = append(p.Inputs, in)
+	}
+	for range p.UnsignedTx.TxOut {
+		pairs, err := readKeyValueMap(r)
+		if err != nil {
+			return nil, err
+		}
+		out, err := parsePsbtOutput(pairs)
+		if err != nil {
+			return nil, err
+		}
+		p.Outputs = append(p.Outputs, out)
+	}
+	if r.Len() != 0 {
+		return nil, errMalformedPsbt
+	}
+	return p, nil
+}
+
+func parsePsbtInput(pairs []*KeyValue) (*PsbtInput, error) {
+	in := &PsbtInput{}
+	var err error
+	for _, kv := range pairs {
+		keyData := kv.Key[1:]
+		if isInputSingletonKey(kv.Key[0]) && len(keyData) != 0 {
+			return nil, errMalformedPsbt
+		}
+		switch kv.Key[0] {
+		case psbtInNonWitnessUtxo:
+			in.NonWitnessUtxo, err = DeserializeTx(kv.Value)
+		case psbtInWitnessUtxo:
+			r := bytes.NewReader(kv.Value)
+			if in.WitnessUtxo, err = readTxOut(r); err == nil && r.Len() != 0 {
+				err = errMalformedPsbt
+			}
+		case psbtInPartialSig:
+			if _, err = btcec.ParsePubKey(keyData); err == nil {
+				in.PartialSigs = append(in.PartialSigs, &PartialSig{PubKey: keyData, Signature: kv.Value})
+			}
+		case psbtInSighashType:
+			if len(kv.Value) != 4 {
+				return nil, errMalformedPsbt
+			}
+			in.SighashType = binary.LittleEndian.Uint32(kv.Value)
+		case psbtInRedeemScript:
+			in.RedeemScript = kv.Value
+		case psbtInBip32Derivation:
+			var derivation *Bip32Derivation
+			if derivation, err = parseBip32Derivation(keyData, kv.Value); err == nil {
+				in.Bip32Derivation = append(in.Bip32Derivation, derivation)
+			}
+		case psbtInFinalScriptSig:
+			in.FinalScriptSig = kv.Value
+		case psbtInFinalScriptWitness:
+			r := bytes.NewReader(kv.Value)
+			var count uint64
+			if count, err = readVarInt(r); err == nil && count <= uint64(r.Len()) {
+				in.FinalScriptWitness = [][]byte{}
+				for i := uint64(0); i < count && err == nil; i++ {
+					var item []byte
+					if item, err = readVarBytes(r); err == nil {
+						in.FinalScriptWitness = append(in.FinalScriptWitness, item)
+					}
+				}
+			} else if err == nil {
+				err = errMalformedPsbt
+			}
+		case psbtInTapKeySig:
+			if len(kv.Value) != 64 && len(kv.Value) != 65 {
+				return nil, errMalformedPsbt
+			}
+			in.TapKeySig = kv.Value
+		case psbtInTapInternalKey:
+			if len(kv.Value) != 32 {
+				return nil, errMalformedPsbt
+			}
+			in.TapInternalKey = kv.Value
+		default:
+			in.Unknowns = append(in.Unknowns, kv)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return in, nil
+}
+
+func parsePsbtOutput(pairs []*KeyValue) (*PsbtOutput, error) {
+	out := &PsbtOutput{}
+	for _, kv := range pairs {
+		switch kv.Key[0] {
+		case psbtOutRedeemScript:
+			out.RedeemScript = kv.Value
+		case psbtOutBip32Derivation:
+			derivation, err := parseBip32Derivation(kv.Key[1:], kv.Value)
+			if err != nil {
+				return nil, err
+			}
+			out.Bip32Derivation = append(out.Bip32Derivation, derivation)
+		case psbtOutTapInternalKey:
+			if len(kv.Value) != 32 {
+				return nil, errMalformedPsbt
+			}
+			out.TapInternalKey = kv.Value
+		default:
+			out.Unknowns = append(out.Unknowns, kv)
+			continue
+		}
+		if kv.Key[0] != psbtOutBip32Derivation && len(kv.Key) != 1 {
+			return nil, errMalformedPsbt
+		}
+	}
+	return out, nil
+}
+
+// isInputSingletonKey 已识别且 key 中不带数据的输入字段
+func isInputSingletonKey(keyType byte) bool {
+	switch keyType {
+	case psbtInNonWitnessUtxo, psbtInWitnessUtxo, psbtInSighashType, psbtInRedeemScript,
+		psbtInFinalScriptSig, psbtInFinalScriptWitness, psbtInTapKeySig, psbtInTapInternalKey:
+		return true
+	}
+	return false
+}
+
+// PrevOut 输入花费的输出, non_witness_utxo 须与 outpoint 的交易哈希一致
+func (p *Psbt) PrevOut(index int) (*TxOut, error) {
+	in := p.Inputs[index]
+	outPoint := p.UnsignedTx.TxIn[index].PreviousOutPoint
+	if in.NonWitnessUtxo != nil {
+		if in.NonWitnessUtxo.TxHash() != outPoint.TxId() || int(outPoint.Index) >= len(in.NonWitnessUtxo.TxOut) {
+			return nil, fmt.Errorf("input %d non witness utxo mismatch", index)
+		}
+		return in.NonWitnessUtxo.TxOut[outPoint.Index], nil
+	}
+	if in.WitnessUtxo != nil {
+		return in.WitnessUtxo, nil
+	}
+	return nil, fmt.Errorf("input %d missing utxo", index)
+}
+
+func (p *Psbt) PrevOuts() ([]*TxOut, error) {
+	var prevOuts []*TxOut
+	for i := range p.Inputs {
+		prevOut, err := p.PrevOut(i)
+		if err != nil {
+			return nil, err
+		}
+		prevOuts = append(prevOuts, prevOut)
+	}
+	return prevOuts, nil
+}
+
+// Fee 输入总额减输出总额
+func (p *Psbt) Fee() (int64, error) {
+	prevOuts, err := p.PrevOuts()
+	if err != nil {
+		return 0, err
+	}
+	var fee int64
+	for _, prevOut := range prevOuts {
+		fee += prevOut.Value
+	}
+	for _, out := range p.UnsignedTx.TxOut {
+		fee -= out.Value
+	}
+	return fee, nil
+}
+
+// SignatureHash 第 index 个输入的签名摘要, P2SH 输入仅支持 P2SH-P2WPKH
+func (p *Psbt) SignatureHash(index int, params *NetParams) ([]byte, error) {
+	prevOuts, err := p.PrevOuts()
+	if err != nil {
+		return nil, err
+	}
+	prevOut := prevOuts[index]
+	if ScriptType(prevOut.PkScript) == AddressP2SH {
+		redeem := p.Inputs[index].RedeemScript
+		if ScriptType(redeem) != AddressP2WPKH || !bytes.Equal(Hash160(redeem), prevOut.PkScript[2:22]) {
+			return nil, fmt.Errorf("input %d unsupported p2sh redeem script", index)
+		}
+		return NestedSignatureHash(p.UnsignedTx, index, redeem[2:], prevOut.Value), nil
+	}
+	return SignatureHash(p.UnsignedTx, index, prevOuts, params)
+}
+
+// SetSigningHash 将签名摘要写入 proprietary 字段
+func (p *Psbt) SetSigningHash(index int, hash []byte) {
+	key := proprietaryKey(proprietarySigningHash)
+	in := p.Inputs[index]
+	for _, kv := range in.Unknowns {
+		if bytes.Equal(kv.Key, key) {
+			kv.Value = hash
+			return
+		}
+	}
+	in.Unknowns = append(in.Unknowns, &KeyValue{Key: key, Value: hash})
+}
+
+func (p *Psbt) SigningHash(index int) []byte {
+	key := proprietaryKey(proprietarySigningHash)
+	for _, kv := range p.Inputs[index].Unknowns {
+		if bytes.Equal(kv.Key, key) {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+// SignerPubKey 输入对应的签名公钥: ECDSA 输入取 bip32 derivation 中的公钥, taproot 输入取内部公钥
+func (p *Psbt) SignerPubKey(index int) []byte {
+	in := p.Inputs[index]
+	if in.TapInternalKey != nil {
+		return in.TapInternalKey
+	}
+	if len(in.Bip32Derivation) > 0 {
+		return in.Bip32Derivation[0].PubKey
+	}
+	return nil
+}
+
+/**
+ * @description: 校验并写入第 index 个输入的签名
+ * ECDSA 签名可为 DER (可带 sighash 后缀) 或 64 字节 r||s, 写入 partial_sig 时统一为 DER + sighash 字节;
+ * taproot 签名为 64 字节 schnorr 签名, 使用 BIP86 调整后的私钥签名, 按输出公钥校验
+ */
+func (p *Psbt) AddSignature(index int, pubKey, signature []byte, params *NetParams) error {
+	if index < 0 || index >= len(p.Inputs) {
+		return errors.New("input index out of range")
+	}
+	hash, err := p.SignatureHash(index, params)
+	if err != nil {
+		return err
+	}
+	prevOut, _ := p.PrevOut(index)
+	in := p.Inputs[index]
+	if ScriptType(prevOut.PkScript) == AddressP2TR {
+		if len(signature) == 65 && signature[64] == SigHashDefault {
+			signature = signature[:64]
+		}
+		sig, err := schnorr.ParseSignature(signature)
+		if err != nil {
+			return err
+		}
+		outputKey, err := schnorr.ParsePubKey(prevOut.PkScript[2:])
+		if err != nil {
+			return err
+		}
+		if !sig.Verify(hash, outputKey) {
+			return fmt.Errorf("input %d schnorr signature verify fail", index)
+		}
+		in.TapKeySig = signature
+		return nil
+	}
+	key, err := btcec.ParsePubKey(pubKey)
+	if err != nil {
+		return err
+	}
+	if err := checkPubKeyMatches(prevOut.PkScript, in.RedeemScript, pubKey); err != nil {
+		return fmt.Errorf("input %d: %w", index, err)
+	}
+	var sig *ecdsa.Signature
+	if len(signature) == 64 {
+		var r, s btcec.ModNScalar
+		r.SetByteSlice(signature[:32])
+		s.SetByteSlice(signature[32:])
+		sig = ecdsa.NewSignature(&r, &s)
+	} else {
+		if len(signature) > 0 && signature[len(signature)-1] == params.SigHashType() {
+			if parsed, err := ecdsa.ParseDERSignature(signature[:len(signature)-1]); err == nil {
+				sig = parsed
+			}
+		}
+		if sig == nil {
+			if sig, err = ecdsa.ParseDERSignature(signature); err != nil {
+				return err
+			}
+		}
+	}
+	if !sig.Verify(hash, key) {
+		return fmt.Errorf("input %d ecdsa signature verify fail", index)
+	}
+	// Serialize 输出 low-S 的 DER 编码, 满足标准交易规则
+	value := append(sig.Serialize(), params.SigHashType())
+	for _, partial := range in.PartialSigs {
+		if bytes.Equal(partial.PubKey, pubKey) {
+			partial.Signature = value
+			return nil
+		}
+	}
+	in.PartialSigs = append(in.PartialSigs, &PartialSig{PubKey: pubKey, Signature: value})
+	return nil
+}
+
+// checkPubKeyMatches 公钥须与锁定脚本中的公钥哈希一致
+func checkPubKeyMatches(pkScript, redeemScript, pubKey []byte) error {
+	var pubKeyHash []byte
+	switch ScriptType(pkScript) {
+	case AddressP2PKH:
+		pubKeyHash = pkScript[3:23]
+	case AddressP2WPKH:
+		pubKeyHash = pkScript[2:]
+	case AddressP2SH:
+		if ScriptType(redeemScript) != AddressP2WPKH {
+			return errors.New("unsupported p2sh redeem script")
+		}
+		pubKeyHash = redeemScript[2:]
+	default:
+		return errors.New("unsupported prevout script")
+	}
+	if !bytes.Equal(Hash160(pubKey), pubKeyHash) {
+		return errors.New("public key does not match prevout script")
+	}
+	return nil
+}
+
+/**
+ * @description: 合并签名端返回的 PSBT, 两者的未签名交易须一致
+ * 合并的签名逐个重新校验, 不信任签名端的 utxo 信息
+ */
+func (p *Psbt) Combine(other *Psbt, params *NetParams) error {
+	if !bytes.Equal(p.UnsignedTx.SerializeNoWitness(), other.UnsignedTx.SerializeNoWitness()) {
+		return errors.New("psbt unsigned tx mismatch")
+	}
+	for i, in := range other.Inputs {
+		for _, partial := range in.PartialSigs {
+			if err := p.AddSignature(i, partial.PubKey, partial.Signature, params); err != nil {
+				return err
+			}
+		}
+		if in.TapKeySig != nil {
+			if err := p.AddSignature(i, nil, in.TapKeySig, params); err != nil {
+				return err
+			}
+		}
+		if in.FinalScriptSig != nil || in.FinalScriptWitness != nil {
+			return fmt.Errorf("input %d already finalized by signer, expect partial signatures", i)
+		}
+	}
+	return nil
+}
+
+/**
+ * @description: BIP174 finalizer, 按输入类型生成 scriptSig 与 witness, 并清除签名过程中的字段
+ */
+func (p *Psbt) Finalize() error {
+	for i, in := range p.Inputs {
+		if in.FinalScriptSig != nil || in.FinalScriptWitness != nil {
+			continue
+		}
+		prevOut, err := p.PrevOut(i)
+		if err != nil {
+			return err
+		}
+		scriptType := ScriptType(prevOut.PkScript)
+		if scriptType == AddressP2TR {
+			if in.TapKeySig == nil {
+				return fmt.Errorf("input %d missing taproot signature", i)
+			}
+			in.FinalScriptWitness = [][]byte{in.TapKeySig}
+		} else {
+			if len(in.PartialSigs) != 1 {
+				return fmt.Errorf("input %d expect exactly one partial signature", i)
+			}
+			sig := in.PartialSigs[0]
+			switch scriptType {
+			case AddressP2PKH:
+				in.FinalScriptSig = append(pushData(sig.Signature), pushData(sig.PubKey)...)
+			case AddressP2WPKH:
+				in.FinalScriptWitness = [][]byte{sig.Signature, sig.PubKey}
+			case AddressP2SH:
+				in.FinalScriptSig = pushData(in.RedeemScript)
+				in.FinalScriptWitness = [][]byte{sig.Signature, sig.PubKey}
+			default:
+				return fmt.Errorf("input %d unsupported prevout script", i)
+			}
+		}
+		in.PartialSigs = nil
+		in.SighashType = 0
+		in.RedeemScript = nil
+		in.Bip32Derivation = nil
+		in.TapKeySig = nil
+		in.TapInternalKey = nil
+	}
+	return nil
+}
+
+// Extract 从已 finalize 的 PSBT 取出可广播的交易
+func (p *Psbt) Extract() (*MsgTx, error) {
+	tx := p.UnsignedTx.Copy()
+	for i, in := range p.Inputs {
+		if in.FinalScriptSig == nil && in.FinalScriptWitness == nil {
+			return nil, fmt.Errorf("input %d not finalized", i)
+		}
+		tx.TxIn[i].SignatureScript = in.FinalScriptSig
+		tx.TxIn[i].Witness = in.FinalScriptWitness
+	}
+	return tx, nil
+}
+
+func proprietaryKey(subType byte) []byte {
+	var key bytes.Buffer
+	key.WriteByte(psbtProprietary)
+	writeVarBytes(&key, []byte(proprietaryIdentifier))
+	key.WriteByte(subType)
+	return key.Bytes()
+}
+
+func parseBip32Derivation(pubKey, value []byte) (*Bip32Derivation, error) {
+	if _, err := btcec.ParsePubKey(pubKey); err != nil {
+		return nil, err
+	}
+	if len(value) < 4 || len(value)%4 != 0 {
+		return nil, errMalformedPsbt
+	}
+	derivation := &Bip32Derivation{PubKey: pubKey, Fingerprint: binary.LittleEndian.Uint32(value)}
+	for i := 4; i < len(value); i += 4 {
+		derivation.Path = append(derivation.Path, binary.LittleEndian.Uint32(value[i:]))
+	}
+	return derivation, nil
+}
+
+func writeBip32Derivation(w *bytes.Buffer, keyType byte, derivations []*Bip32Derivation) {
+	for _, derivation := range derivations {
+		var value bytes.Buffer
+		writeUint32(&value, derivation.Fingerprint)
+		for _, index := range derivation.Path {
+			writeUint32(&value, index)
+		}
+		writeKeyValue(w, append([]byte{keyType}, derivation.PubKey...), value.Bytes())
+	}
+}
+
+func writeKeyValue(w *bytes.Buffer, key, value []byte) {
+	writeVarBytes(w, key)
+	writeVarBytes(w, value)
+}
+
+func writeUnknowns(w *bytes.Buffer, unknowns []*KeyValue) {
+	for _, kv := range unknowns {
+		writeKeyValue(w, kv.Key, kv.Value)
+	}
+}
+
+// readKeyValueMap 读取一个以 0x00 结尾的 map
+func readKeyValueMap(r *bytes.Reader) ([]*KeyValue, error) {
+	var pairs []*KeyValue
+	seen := make(map[string]bool)
+	for {
+		key, err := readVarBytes(r)
+		if err != nil {
+			return nil, errMalformedPsbt
+		}
+		if len(key) == 0 {
+			return pairs, nil
+		}
+		value, err := readVarBytes(r)
+		if err != nil {
+			return nil, errMalformedPsbt
+		}
+		if seen[hex.EncodeToString(key)] {
+			return nil, errors.New("psbt duplicate key")
+		}
+		seen[hex.EncodeToString(key)] = true
+		pairs = append(pairs, &KeyValue{Key: key, Value: value})
+	}
+}
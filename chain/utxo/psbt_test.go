@@ -0,0 +1,179 @@
+package utxo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+)
+
+// BIP-174 有效测试向量: 一个带 non_witness_utxo 的 P2PKH 输入, 两个输出
+const bip174Valid = "cHNidP8BAHUCAAAAASaBcTce3/KF6Tet7qSze3gADAVmy7OtZGQXE8pCFxv2AAAAAAD+////AtPf9QUAAAAAGXapFNDFmQPFusKGh2DpD9UhpGZap2UgiKwA4fUFAAAAABepFDVF5uM7gyxHBQ8k0+65PJwDlIvHh7MuEwAAAQD9pQEBAAAAAAECiaPHHqtNIOA3G7ukzGmPopXJRjr6Ljl/hTPMti+VZ+UBAAAAFxYAFL4Y0VKpsBIDna89p95PUzSe7LmF/////4b4qkOnHf8USIk6UwpyN+9rRgi7st0tAXHmOuxqSJC0AQAAABcWABT+Pp7xp0XpdNkCxDVZQ6vLNL1TU/////8CAMLrCwAAAAAZdqkUhc/xCX/Z4Ai7NK9wnGIZeziXikiIrHL++E4sAAAAF6kUM5cluiHv1irHU6m80GfWx6ajnQWHAkcwRAIgJxK+IuAnDzlPVoMR3HyppolwuAJf3TskAinwf4pfOiQCIAGLONfc0xTnNMkna9b7QPZzMlvEuqFEyADS8vAtsnZcASED0uFWdJQbrUqZY3LLh+GFbTZSYG2YVi/jnF6efkE/IQUCSDBFAiEA0SuFLYXc2WHS9fSrZgZU327tzHlMDDPOXMMJ/7X85Y0CIGczio4OFyXBl/saiK9Z9R5E5CVbIBZ8hoQDHAXR8lkqASECI7cr7vCWXRC+B3jv7NYfysb3mk6haTkzgHNEZPhPKrMAAAAAAAAA"
+
+func TestDecodePsbtValid(t *testing.T) {
+	p, err := DecodePsbtBase64(bip174Valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Inputs) != 1 || len(p.Outputs) != 2 || p.Inputs[0].NonWitnessUtxo == nil {
+		t.Fatalf("unexpected psbt %d inputs %d outputs", len(p.Inputs), len(p.Outputs))
+	}
+	if want := "f61b1742ca13176464adb3cb66050c00787bb3a4eead37e985f2df1e37718126"; p.UnsignedTx.TxIn[0].PreviousOutPoint.TxId() != want {
+		t.Errorf("outpoint = %s, want %s", p.UnsignedTx.TxIn[0].PreviousOutPoint.TxId(), want)
+	}
+	// non_witness_utxo 的哈希须与 outpoint 一致
+	fee, err := p.Fee()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee != 200_000_000-99_999_699-100_000_000 {
+		t.Errorf("fee = %d", fee)
+	}
+	if got := p.B64Encode(); got != bip174Valid {
+		t.Errorf("round trip = %s", got)
+	}
+}
+
+func TestDecodePsbtInvalid(t *testing.T) {
+	valid, err := base64.StdEncoding.DecodeString(bip174Valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := DecodePsbt(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsignedTx := p.UnsignedTx.SerializeNoWitness()
+	build := func(globals ...[]byte) []byte {
+		var w bytes.Buffer
+		w.WriteString(psbtMagic)
+		for i := 0; i+1 < len(globals); i += 2 {
+			writeKeyValue(&w, globals[i], globals[i+1])
+		}
+		w.WriteByte(0x00)
+		return w.Bytes()
+	}
+	signed := p.UnsignedTx.Copy()
+	signed.TxIn[0].SignatureScript = []byte{0x51}
+	// 在有效向量的第一个输入 map 中追加一个字段
+	withInput := func(key, value []byte) []byte {
+		var w bytes.Buffer
+		w.WriteString(psbtMagic)
+		writeKeyValue(&w, []byte{psbtGlobalUnsignedTx}, unsignedTx)
+		w.WriteByte(0x00)
+		writeKeyValue(&w, []byte{psbtInNonWitnessUtxo}, p.Inputs[0].NonWitnessUtxo.Serialize())
+		writeKeyValue(&w, key, value)
+		w.Write([]byte{0x00, 0x00, 0x00})
+		return w.Bytes()
+	}
+
+	tests := []struct {
+		name string
+		raw  []byte
+	}{
+		{"network transaction without magic", unsignedTx},
+		{"missing unsigned tx", build([]byte{0x70}, []byte{0x01})},
+		{"unsigned tx with scriptSig", append(build([]byte{psbtGlobalUnsignedTx}, signed.SerializeNoWitness()), 0x00, 0x00, 0x00)},
+		{"duplicate global key", build([]byte{psbtGlobalUnsignedTx}, unsignedTx, []byte{psbtGlobalUnsignedTx}, unsignedTx)},
+		{"unsigned tx key with data", build([]byte{psbtGlobalUnsignedTx, 0x01}, unsignedTx)},
+		{"missing output maps", valid[:len(valid)-2]},
+		{"trailing data", append(append([]byte{}, valid...), 0x00)},
+		{"truncated", valid[:len(valid)/2]},
+		{"input singleton key with data", withInput([]byte{psbtInRedeemScript, 0x01}, []byte{0x51})},
+		{"partial sig with invalid pubkey", withInput([]byte{psbtInPartialSig, 0x02, 0x03}, []byte{0x30})},
+		{"sighash type length", withInput([]byte{psbtInSighashType}, []byte{0x01})},
+		{"taproot key sig length", withInput([]byte{psbtInTapKeySig}, make([]byte, 63))},
+	}
+	for _, tt := range tests {
+		if _, err := DecodePsbt(tt.raw); err == nil {
+			t.Errorf("%s: DecodePsbt should fail", tt.name)
+		}
+	}
+}
+
+// P2WPKH 输入的签名、合并、finalize 与提取
+func TestPsbtSignFinalize(t *testing.T) {
+	params := BitcoinMainNetParams
+	privKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x02}, 32))
+	pubKey := privKey.PubKey().SerializeCompressed()
+	script, err := PayToPubKeyScript(pubKey, AddressP2WPKH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outPoint, err := NewOutPoint("f61b1742ca13176464adb3cb66050c00787bb3a4eead37e985f2df1e37718126", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &MsgTx{
+		Version: 2,
+		TxIn:    []*TxIn{{PreviousOutPoint: outPoint, Sequence: RbfSequence}},
+		TxOut:   []*TxOut{{Value: 99_000, PkScript: script}},
+	}
+	p, err := NewPsbt(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Inputs[0].WitnessUtxo = &TxOut{Value: 100_000, PkScript: script}
+	p.Inputs[0].Bip32Derivation = []*Bip32Derivation{{PubKey: pubKey}}
+	hash, err := p.SignatureHash(0, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetSigningHash(0, hash)
+
+	// 签名端只拿到序列化后的 PSBT, 取出摘要与公钥后签名
+	signer, err := DecodePsbtBase64(p.B64Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(signer.SigningHash(0), hash) || !bytes.Equal(signer.SignerPubKey(0), pubKey) {
+		t.Fatal("signing hash or signer public key not preserved")
+	}
+	sig := ecdsa.Sign(privKey, signer.SigningHash(0))
+	otherKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x03}, 32))
+	if err := signer.AddSignature(0, otherKey.PubKey().SerializeCompressed(), ecdsa.Sign(otherKey, hash).Serialize(), params); err == nil {
+		t.Error("signature of another key should be rejected")
+	}
+	if err := signer.AddSignature(0, pubKey, ecdsa.Sign(otherKey, hash).Serialize(), params); err == nil {
+		t.Error("signature that does not verify should be rejected")
+	}
+	if err := signer.AddSignature(0, pubKey, append(sig.Serialize(), SigHashAll), params); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Combine(signer, params); err != nil {
+		t.Fatal(err)
+	}
+	if fee, err := p.Fee(); err != nil || fee != 1_000 {
+		t.Errorf("fee = %d, %v", fee, err)
+	}
+	if _, err := p.Extract(); err == nil {
+		t.Error("extract before finalize should fail")
+	}
+	if err := p.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := p.Extract()
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness := signed.TxIn[0].Witness
+	if len(witness) != 2 || !bytes.Equal(witness[1], pubKey) || witness[0][len(witness[0])-1] != SigHashAll {
+		t.Fatalf("unexpected witness %x", witness)
+	}
+	if len(signed.TxIn[0].SignatureScript) != 0 || signed.TxHash() != tx.TxHash() {
+		t.Error("witness signature should not change txid")
+	}
+	if p.Inputs[0].PartialSigs != nil || p.Inputs[0].Bip32Derivation != nil {
+		t.Error("finalize should clear signing fields")
+	}
+
+	other := *tx
+	other.LockTime = 1
+	mismatch, _ := NewPsbt(&other)
+	if err := p.Combine(mismatch, params); err == nil {
+		t.Error("combine with a different unsigned tx should fail")
+	}
+}
@@ -0,0 +1,93 @@
+package utxo
+
+const (
+	op0           = 0x00
+	op1           = 0x51
+	opPushData1   = 0x4c
+	opPushData2   = 0x4d
+	opDup         = 0x76
+	opEqual       = 0x87
+	opEqualVerify = 0x88
+	opHash160     = 0xa9
+	opCheckSig    = 0xac
+)
+
+func payToPubKeyHashScript(pubKeyHash []byte) []byte {
+	script := []byte{opDup, opHash160, 20}
+	script = append(script, pubKeyHash...)
+	return append(script, opEqualVerify, opCheckSig)
+}
+
+func payToScriptHashScript(scriptHash []byte) []byte {
+	script := []byte{opHash160, 20}
+	script = append(script, scriptHash...)
+	return append(script, opEqual)
+}
+
+func PayToWitnessPubKeyHashScript(pubKeyHash []byte) []byte {
+	return witnessScript(0, pubKeyHash)
+}
+
+func payToTaprootScript(outputKey []byte) []byte {
+	return witnessScript(1, outputKey)
+}
+
+func witnessScript(version byte, program []byte) []byte {
+	opVersion := byte(op0)
+	if version > 0 {
+		opVersion = op1 + version - 1
+	}
+	return append([]byte{opVersion, byte(len(program))}, program...)
+}
+
+// isWitnessProgram BIP141: 版本操作码后紧跟一次 2 到 40 字节的数据推送
+func isWitnessProgram(script []byte) bool {
+	if len(script) < 4 || len(script) > 42 {
+		return false
+	}
+	if script[0] != op0 && (script[0] < op1 || script[0] > op1+15) {
+		return false
+	}
+	return int(script[1])+2 == len(script)
+}
+
+// pushData 最小编码的数据推送, 用于构造 scriptSig
+func pushData(data []byte) []byte {
+	switch {
+	case len(data) < opPushData1:
+		return append([]byte{byte(len(data))}, data...)
+	case len(data) <= 0xff:
+		return append([]byte{opPushData1, byte(len(data))}, data...)
+	default:
+		return append([]byte{opPushData2, byte(len(data)), byte(len(data) >> 8)}, data...)
+	}
+}
+
+// parsePushes 解析仅包含数据推送的 scriptSig, 遇到其他操作码时返回 false
+func parsePushes(script []byte) ([][]byte, bool) {
+	var pushes [][]byte
+	for i := 0; i < len(script); {
+		op := int(script[i])
+		i++
+		size := 0
+		switch {
+		case op == op0:
+		case op < opPushData1:
+			size = op
+		case op == opPushData1 && i < len(script):
+			size = int(script[i])
+			i++
+		case op == opPushData2 && i+1 < len(script):
+			size = int(script[i]) | int(script[i+1])<<8
+			i += 2
+		default:
+			return nil, false
+		}
+		if i+size > len(script) {
+			return nil, false
+		}
+		pushes = append(pushes, script[i:i+size])
+		i += size
+	}
+	return pushes, true
+}
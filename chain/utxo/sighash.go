@@ -0,0 +1,130 @@
+package utxo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	SigHashDefault = 0x00
+	SigHashAll     = 0x01
+	// SigHashForkId BCH 重放保护, 与 SIGHASH_ALL 组合为 0x41
+	SigHashForkId = 0x40
+)
+
+/**
+ * @description: 计算第 index 个输入的签名摘要
+ * prevOuts 为所有输入花费的输出 (金额与锁定脚本), P2TR 按 BIP341 key path (SIGHASH_DEFAULT),
+ * segwit v0 与 BCH 按 BIP143, 其余按 legacy 规则
+ */
+func SignatureHash(tx *MsgTx, index int, prevOuts []*TxOut, params *NetParams) ([]byte, error) {
+	if index < 0 || index >= len(tx.TxIn) || len(prevOuts) != len(tx.TxIn) {
+		return nil, errors.New("input index or prevouts mismatch")
+	}
+	script := prevOuts[index].PkScript
+	switch ScriptType(script) {
+	case AddressP2TR:
+		return taprootSignatureHash(tx, index, prevOuts), nil
+	case AddressP2WPKH:
+		return witnessV0SignatureHash(tx, index, payToPubKeyHashScript(script[2:]), prevOuts[index].Value, SigHashAll), nil
+	case AddressP2PKH:
+		if params.ForkId {
+			return witnessV0SignatureHash(tx, index, script, prevOuts[index].Value, SigHashAll|SigHashForkId), nil
+		}
+		return legacySignatureHash(tx, index, script), nil
+	}
+	return nil, errors.New("unsupported prevout script")
+}
+
+// NestedSignatureHash P2SH-P2WPKH 输入, 锁定脚本只有 redeem script 的哈希, 需单独传入 witness 公钥哈希
+func NestedSignatureHash(tx *MsgTx, index int, pubKeyHash []byte, value int64) []byte {
+	return witnessV0SignatureHash(tx, index, payToPubKeyHashScript(pubKeyHash), value, SigHashAll)
+}
+
+// SigHashType 签名后缀的 sighash 字节, taproot 使用 SIGHASH_DEFAULT 不附加后缀
+func (p *NetParams) SigHashType() byte {
+	if p.ForkId {
+		return SigHashAll | SigHashForkId
+	}
+	return SigHashAll
+}
+
+func legacySignatureHash(tx *MsgTx, index int, subScript []byte) []byte {
+	txCopy := tx.Copy()
+	for i, in := range txCopy.TxIn {
+		in.Witness = nil
+		in.SignatureScript = nil
+		if i == index {
+			in.SignatureScript = subScript
+		}
+	}
+	var buf bytes.Buffer
+	txCopy.encode(&buf, false)
+	writeUint32(&buf, SigHashAll)
+	return DoubleSha256(buf.Bytes())
+}
+
+// witnessV0SignatureHash BIP143, hashType 仅支持 SIGHASH_ALL (可带 FORKID)
+func witnessV0SignatureHash(tx *MsgTx, index int, scriptCode []byte, value int64, hashType uint32) []byte {
+	var prevouts, sequences, outputs bytes.Buffer
+	for _, in := range tx.TxIn {
+		prevouts.Write(in.PreviousOutPoint.Hash[:])
+		writeUint32(&prevouts, in.PreviousOutPoint.Index)
+		writeUint32(&sequences, in.Sequence)
+	}
+	for _, out := range tx.TxOut {
+		writeTxOut(&outputs, out)
+	}
+	in := tx.TxIn[index]
+	var buf bytes.Buffer
+	writeUint32(&buf, uint32(tx.Version))
+	buf.Write(DoubleSha256(prevouts.Bytes()))
+	buf.Write(DoubleSha256(sequences.Bytes()))
+	buf.Write(in.PreviousOutPoint.Hash[:])
+	writeUint32(&buf, in.PreviousOutPoint.Index)
+	writeVarBytes(&buf, scriptCode)
+	var amount [8]byte
+	binary.LittleEndian.PutUint64(amount[:], uint64(value))
+	buf.Write(amount[:])
+	writeUint32(&buf, in.Sequence)
+	buf.Write(DoubleSha256(outputs.Bytes()))
+	writeUint32(&buf, tx.LockTime)
+	writeUint32(&buf, hashType)
+	return DoubleSha256(buf.Bytes())
+}
+
+// taprootSignatureHash BIP341 key path, SIGHASH_DEFAULT, 无 annex
+func taprootSignatureHash(tx *MsgTx, index int, prevOuts []*TxOut) []byte {
+	var prevouts, amounts, scripts, sequences, outputs bytes.Buffer
+	for i, in := range tx.TxIn {
+		prevouts.Write(in.PreviousOutPoint.Hash[:])
+		writeUint32(&prevouts, in.PreviousOutPoint.Index)
+		var amount [8]byte
+		binary.LittleEndian.PutUint64(amount[:], uint64(prevOuts[i].Value))
+		amounts.Write(amount[:])
+		writeVarBytes(&scripts, prevOuts[i].PkScript)
+		writeUint32(&sequences, in.Sequence)
+	}
+	for _, out := range tx.TxOut {
+		writeTxOut(&outputs, out)
+	}
+	single := func(data []byte) []byte {
+		hash := sha256.Sum256(data)
+		return hash[:]
+	}
+	var msg bytes.Buffer
+	msg.WriteByte(0x00) // epoch
+	msg.WriteByte(SigHashDefault)
+	writeUint32(&msg, uint32(tx.Version))
+	writeUint32(&msg, tx.LockTime)
+	msg.Write(single(prevouts.Bytes()))
+	msg.Write(single(amounts.Bytes()))
+	msg.Write(single(scripts.Bytes()))
+	msg.Write(single(sequences.Bytes()))
+	msg.Write(single(outputs.Bytes()))
+	msg.WriteByte(0x00) // spend_type: key path, 无 annex
+	writeUint32(&msg, uint32(index))
+	return TaggedHash("TapSighash", msg.Bytes())
+}
@@ -0,0 +1,166 @@
+package utxo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// BIP-143 的 native P2WPKH 与 P2SH-P2WPKH 示例
+func TestWitnessV0SignatureHash(t *testing.T) {
+	t.Run("p2wpkh", func(t *testing.T) {
+		tx, err := DeserializeTx(mustHex(t, "0100000002fff7f7881a8099afa6940d42d1e7f6362bec38171ea3edf433541db4e4ad969f0000000000eeffffffef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a0100000000ffffffff02202cb206000000001976a9148280b37df378db99f66f85c95a783a76ac7a6d5988ac9093510d000000001976a9143bde42dbee7e4dbe6a21b2d50ce2f0167faa815988ac11000000"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		prevOuts := []*TxOut{
+			{Value: 625_000_000, PkScript: mustHex(t, "2103c9f4836b9a4f77fc0d81f7bcb01b7f1b35916864b9476c241ce9fc198bd25432ac")},
+			{Value: 600_000_000, PkScript: mustHex(t, "00141d0f172a0ecb48aee1be1f2687d2963ae33f71a1")},
+		}
+		hash, err := SignatureHash(tx, 1, prevOuts, BitcoinMainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "c37af31116d1b27caf68aae9e3ac82f1477929014d5b917657d0eb49478cb670"; hex.EncodeToString(hash) != want {
+			t.Errorf("sighash = %x, want %s", hash, want)
+		}
+	})
+	t.Run("p2sh-p2wpkh", func(t *testing.T) {
+		tx, err := DeserializeTx(mustHex(t, "0100000001db6b1b20aa0fd7b23880be2ecbd4a98130974cf4748fb66092ac4d3ceb1a54770100000000feffffff02b8b4eb0b000000001976a914a457b684d7f0d539a46a45bbc043f35b59d0d96388ac0008af2f000000001976a914fd270b1ee6abcaea97fea7ad0402e8bd8ad6d77c88ac92040000"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		redeem := mustHex(t, "001479091972186c449eb1ded22b78e40d009bdf0089")
+		hash := NestedSignatureHash(tx, 0, redeem[2:], 1_000_000_000)
+		if want := "64f3b0f4dd2bb3aa1ce8566d220cc74dda9df97d8490cc81d89d735c92e59fb6"; hex.EncodeToString(hash) != want {
+			t.Errorf("sighash = %x, want %s", hash, want)
+		}
+	})
+}
+
+// 区块 170 中第一笔转账交易 (f4184fc5...), 签名须能以 legacy sighash 校验通过
+func TestLegacySignatureHash(t *testing.T) {
+	tx, err := DeserializeTx(mustHex(t, "0100000001c997a5e56e104102fa209c6a852dd90660a20b2d9c352423edce25857fcd3704000000004847304402204e45e16932b8af514961a1d3a1a25fdf3f4f7732e9d624c6c61548ab5fb8cd410220181522ec8eca07de4860a4acdd12909d831cc56cbbac4622082221a8768d1d0901ffffffff0200ca9a3b00000000434104ae1a62fe09c5f51b13905f07f06b99a2f7159b2225f374cd378d71302fa28414e7aab37397f554a7df5f142c21c1b7303b8a0626f1baded5c72a704f7e6cd84cac00286bee0000000043410411db93e1dcdb8a016b49840f8c53bc1eb68a382e97b1482ecad7b148a6909a5cb2e0eaddfb84ccf9744464f82e160bfa9b8b64f9d4c03f999b8643f656b412a3ac00000000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16"; tx.TxHash() != want {
+		t.Fatalf("txid = %s, want %s", tx.TxHash(), want)
+	}
+	// 花费的输出为 P2PK, scriptSig 为 <sig||SIGHASH_ALL>
+	pkScript := mustHex(t, "410411db93e1dcdb8a016b49840f8c53bc1eb68a382e97b1482ecad7b148a6909a5cb2e0eaddfb84ccf9744464f82e160bfa9b8b64f9d4c03f999b8643f656b412a3ac")
+	pushes, ok := parsePushes(tx.TxIn[0].SignatureScript)
+	if !ok || len(pushes) != 1 {
+		t.Fatalf("unexpected scriptSig %x", tx.TxIn[0].SignatureScript)
+	}
+	der := pushes[0]
+	if der[len(der)-1] != SigHashAll {
+		t.Fatalf("unexpected sighash type %x", der[len(der)-1])
+	}
+	sig, err := ecdsa.ParseDERSignature(der[:len(der)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey, err := btcec.ParsePubKey(pkScript[1:66])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sig.Verify(legacySignatureHash(tx, 0, pkScript), pubKey) {
+		t.Error("signature of block 170 transaction does not verify")
+	}
+}
+
+// BIP-341 key path: 签名摘要须承诺所有输入的金额与锁定脚本, 且能以 BIP-86 调整后的私钥签名并按输出公钥校验
+func TestTaprootSignatureHash(t *testing.T) {
+	privKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x01}, 32))
+	script, err := PayToPubKeyScript(privKey.PubKey().SerializeCompressed(), AddressP2TR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &MsgTx{
+		Version: 2,
+		TxIn: []*TxIn{
+			{PreviousOutPoint: OutPoint{Hash: [32]byte{0x01}, Index: 0}, Sequence: 0xffffffff},
+			{PreviousOutPoint: OutPoint{Hash: [32]byte{0x02}, Index: 1}, Sequence: 0xfffffffd},
+		},
+		TxOut: []*TxOut{{Value: 90_000, PkScript: script}},
+	}
+	prevOuts := []*TxOut{
+		{Value: 50_000, PkScript: script},
+		{Value: 50_000, PkScript: mustHex(t, "0014751e76e8199196d454941c45d1b3a323f1433bd6")},
+	}
+	hash, err := SignatureHash(tx, 0, prevOuts, BitcoinMainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 修改另一个输入的金额或脚本, 或修改输入序号, 摘要都须变化
+	changed := []*TxOut{prevOuts[0], {Value: 50_001, PkScript: prevOuts[1].PkScript}}
+	if other, _ := SignatureHash(tx, 0, changed, BitcoinMainNetParams); bytes.Equal(hash, other) {
+		t.Error("sighash does not commit to other input amount")
+	}
+	changed = []*TxOut{prevOuts[0], {Value: 50_000, PkScript: script}}
+	if other, _ := SignatureHash(tx, 0, changed, BitcoinMainNetParams); bytes.Equal(hash, other) {
+		t.Error("sighash does not commit to other input script")
+	}
+	if other := taprootSignatureHash(tx, 1, prevOuts); bytes.Equal(hash, other) {
+		t.Error("sighash does not commit to input index")
+	}
+
+	// BIP-86 私钥调整: d' = d 或 n-d (使 P 的 y 为偶数) 再加 tweak
+	var d btcec.ModNScalar
+	d.Set(&privKey.Key)
+	if privKey.PubKey().SerializeCompressed()[0] == 0x03 {
+		d.Negate()
+	}
+	var tweak btcec.ModNScalar
+	tweak.SetByteSlice(TaggedHash("TapTweak", schnorr.SerializePubKey(privKey.PubKey())))
+	d.Add(&tweak)
+	tweaked := btcec.PrivKeyFromScalar(&d)
+	if !bytes.Equal(schnorr.SerializePubKey(tweaked.PubKey()), script[2:]) {
+		t.Fatal("tweaked private key does not match output key")
+	}
+	sig, err := schnorr.Sign(tweaked, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputKey, err := schnorr.ParsePubKey(script[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sig.Verify(hash, outputKey) {
+		t.Error("schnorr signature does not verify against output key")
+	}
+}
+
+func TestSignatureHashForkId(t *testing.T) {
+	pkScript := mustHex(t, "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac")
+	tx := &MsgTx{
+		Version: 2,
+		TxIn:    []*TxIn{{PreviousOutPoint: OutPoint{Hash: [32]byte{0x01}}, Sequence: 0xffffffff}},
+		TxOut:   []*TxOut{{Value: 90_000, PkScript: pkScript}},
+	}
+	prevOuts := []*TxOut{{Value: 100_000, PkScript: pkScript}}
+	bch, err := SignatureHash(tx, 0, prevOuts, BitcoinCashMainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// BCH 的 P2PKH 按 BIP-143 算法计算, 并以 SIGHASH_ALL|FORKID 提交
+	if want := witnessV0SignatureHash(tx, 0, pkScript, 100_000, SigHashAll|SigHashForkId); !bytes.Equal(bch, want) {
+		t.Errorf("bch sighash = %x, want %x", bch, want)
+	}
+	btc, err := SignatureHash(tx, 0, prevOuts, BitcoinMainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(btc, legacySignatureHash(tx, 0, pkScript)) {
+		t.Error("bitcoin p2pkh sighash should use legacy algorithm")
+	}
+	if BitcoinCashMainNetParams.SigHashType() != 0x41 || BitcoinMainNetParams.SigHashType() != 0x01 {
+		t.Error("unexpected sighash type byte")
+	}
+}
@@ -0,0 +1,357 @@
+package utxo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+const (
+	// 标准交易 weight 上限为 400000, 限制反序列化时的分配大小
+	maxTxSize = 400_000
+
+	witnessMarker = 0x00
+	witnessFlag   = 0x01
+
+	MaxSequence = 0xffffffff
+	// RbfSequence BIP125 可替换的序列号
+	RbfSequence = 0xfffffffd
+)
+
+var errMalformedTx = errors.New("malformed utxo transaction")
+
+// OutPoint 交易哈希按内部字节序 (与显示的 txid 相反) 保存
+type OutPoint struct {
+	Hash  [32]byte
+	Index uint32
+}
+
+// NewOutPoint 由显示格式的 txid 构造
+func NewOutPoint(txId string, index uint32) (OutPoint, error) {
+	raw, err := hex.DecodeString(txId)
+	if err != nil || len(raw) != 32 {
+		return OutPoint{}, errors.New("invalid tx id")
+	}
+	var op OutPoint
+	copy(op.Hash[:], reverseBytes(raw))
+	op.Index = index
+	return op, nil
+}
+
+func (o OutPoint) TxId() string {
+	return hex.EncodeToString(reverseBytes(o.Hash[:]))
+}
+
+type TxIn struct {
+	PreviousOutPoint OutPoint
+	SignatureScript  []byte
+	Witness          [][]byte
+	Sequence         uint32
+}
+
+type TxOut struct {
+	Value    int64
+	PkScript []byte
+}
+
+type MsgTx struct {
+	Version  int32
+	TxIn     []*TxIn
+	TxOut    []*TxOut
+	LockTime uint32
+}
+
+func (tx *MsgTx) HasWitness() bool {
+	for _, in := range tx.TxIn {
+		if len(in.Witness) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Serialize 含 witness 的完整序列化, 用于广播
+func (tx *MsgTx) Serialize() []byte {
+	var buf bytes.Buffer
+	tx.encode(&buf, tx.HasWitness())
+	return buf.Bytes()
+}
+
+// SerializeNoWitness 不含 witness 的序列化, 用于计算 txid 与 PSBT 中的未签名交易
+func (tx *MsgTx) SerializeNoWitness() []byte {
+	var buf bytes.Buffer
+	tx.encode(&buf, false)
+	return buf.Bytes()
+}
+
+// TxHash 显示格式的 txid
+func (tx *MsgTx) TxHash() string {
+	return hex.EncodeToString(reverseBytes(DoubleSha256(tx.SerializeNoWitness())))
+}
+
+// VirtualSize BIP141 vsize = ceil(weight / 4)
+func (tx *MsgTx) VirtualSize() int64 {
+	base := int64(len(tx.SerializeNoWitness()))
+	total := int64(len(tx.Serialize()))
+	return (base*3 + total + 3) / 4
+}
+
+func (tx *MsgTx) Copy() *MsgTx {
+	cp := &MsgTx{Version: tx.Version, LockTime: tx.LockTime}
+	for _, in := range tx.TxIn {
+		ci := *in
+		ci.SignatureScript = append([]byte(nil), in.SignatureScript...)
+		ci.Witness = nil
+		for _, item := range in.Witness {
+			ci.Witness = append(ci.Witness, append([]byte(nil), item...))
+		}
+		cp.TxIn = append(cp.TxIn, &ci)
+	}
+	for _, out := range tx.TxOut {
+		cp.TxOut = append(cp.TxOut, &TxOut{Value: out.Value, PkScript: append([]byte(nil), out.PkScript...)})
+	}
+	return cp
+}
+
+func (tx *MsgTx) encode(w *bytes.Buffer, withWitness bool) {
+	writeUint32(w, uint32(tx.Version))
+	if withWitness {
+		w.WriteByte(witnessMarker)
+		w.WriteByte(witnessFlag)
+	}
+	writeVarInt(w, uint64(len(tx.TxIn)))
+	for _, in := range tx.TxIn {
+		w.Write(in.PreviousOutPoint.Hash[:])
+		writeUint32(w, in.PreviousOutPoint.Index)
+		writeVarBytes(w, in.SignatureScript)
+		writeUint32(w, in.Sequence)
+	}
+	writeVarInt(w, uint64(len(tx.TxOut)))
+	for _, out := range tx.TxOut {
+		writeTxOut(w, out)
+	}
+	if withWitness {
+		for _, in := range tx.TxIn {
+			writeVarInt(w, uint64(len(in.Witness)))
+			for _, item := range in.Witness {
+				writeVarBytes(w, item)
+			}
+		}
+	}
+	writeUint32(w, tx.LockTime)
+}
+
+// DeserializeTx 解析原始交易, 兼容 segwit 与非 segwit 格式
+func DeserializeTx(raw []byte) (*MsgTx, error) {
+	if len(raw) > maxTxSize {
+		return nil, errMalformedTx
+	}
+	r := bytes.NewReader(raw)
+	tx, err := readTx(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, errMalformedTx
+	}
+	return tx, nil
+}
+
+func readTx(r *bytes.Reader) (*MsgTx, error) {
+	version, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	tx := &MsgTx{Version: int32(version)}
+	inCount, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	withWitness := false
+	if inCount == 0 {
+		flag, err := r.ReadByte()
+		if err != nil || flag != witnessFlag {
+			return nil, errMalformedTx
+		}
+		withWitness = true
+		if inCount, err = readVarInt(r); err != nil {
+			return nil, err
+		}
+	}
+	if inCount > uint64(r.Len()/41) {
+		return nil, errMalformedTx
+	}
+	for i := uint64(0); i < inCount; i++ {
+		in := &TxIn{}
+		if _, err := io.ReadFull(r, in.PreviousOutPoint.Hash[:]); err != nil {
+			return nil, errMalformedTx
+		}
+		if in.PreviousOutPoint.Index, err = readUint32(r); err != nil {
+			return nil, err
+		}
+		if in.SignatureScript, err = readVarBytes(r); err != nil {
+			return nil, err
+		}
+		if in.Sequence, err = readUint32(r); err != nil {
+			return nil, err
+		}
+		tx.TxIn = append(tx.TxIn, in)
+	}
+	outCount, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if outCount > uint64(r.Len()/9) {
+		return nil, errMalformedTx
+	}
+	for i := uint64(0); i < outCount; i++ {
+		out, err := readTxOut(r)
+		if err != nil {
+			return nil, err
+		}
+		tx.TxOut = append(tx.TxOut, out)
+	}
+	if withWitness {
+		for _, in := range tx.TxIn {
+			count, err := readVarInt(r)
+			if err != nil {
+				return nil, err
+			}
+			if count > uint64(r.Len()) {
+				return nil, errMalformedTx
+			}
+			for j := uint64(0); j < count; j++ {
+				item, err := readVarBytes(r)
+				if err != nil {
+					return nil, err
+				}
+				in.Witness = append(in.Witness, item)
+			}
+		}
+	}
+	if tx.LockTime, err = readUint32(r); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func writeTxOut(w *bytes.Buffer, out *TxOut) {
+	var value [8]byte
+	binary.LittleEndian.PutUint64(value[:], uint64(out.Value))
+	w.Write(value[:])
+	writeVarBytes(w, out.PkScript)
+}
+
+func readTxOut(r *bytes.Reader) (*TxOut, error) {
+	var value [8]byte
+	if _, err := io.ReadFull(r, value[:]); err != nil {
+		return nil, errMalformedTx
+	}
+	script, err := readVarBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	return &TxOut{Value: int64(binary.LittleEndian.Uint64(value[:])), PkScript: script}, nil
+}
+
+func varIntSize(n uint64) int {
+	switch {
+	case n < 0xfd:
+		return 1
+	case n <= 0xffff:
+		return 3
+	case n <= 0xffffffff:
+		return 5
+	}
+	return 9
+}
+
+func writeVarInt(w *bytes.Buffer, n uint64) {
+	var buf [9]byte
+	switch varIntSize(n) {
+	case 1:
+		w.WriteByte(byte(n))
+	case 3:
+		buf[0] = 0xfd
+		binary.LittleEndian.PutUint16(buf[1:], uint16(n))
+		w.Write(buf[:3])
+	case 5:
+		buf[0] = 0xfe
+		binary.LittleEndian.PutUint32(buf[1:], uint32(n))
+		w.Write(buf[:5])
+	default:
+		buf[0] = 0xff
+		binary.LittleEndian.PutUint64(buf[1:], n)
+		w.Write(buf[:])
+	}
+}
+
+func readVarInt(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, errMalformedTx
+	}
+	var buf [8]byte
+	switch prefix {
+	case 0xfd:
+		if _, err := io.ReadFull(r, buf[:2]); err != nil {
+			return 0, errMalformedTx
+		}
+		return uint64(binary.LittleEndian.Uint16(buf[:2])), nil
+	case 0xfe:
+		if _, err := io.ReadFull(r, buf[:4]); err != nil {
+			return 0, errMalformedTx
+		}
+		return uint64(binary.LittleEndian.Uint32(buf[:4])), nil
+	case 0xff:
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return 0, errMalformedTx
+		}
+		return binary.LittleEndian.Uint64(buf[:]), nil
+	}
+	return uint64(prefix), nil
+}
+
+func writeVarBytes(w *bytes.Buffer, data []byte) {
+	writeVarInt(w, uint64(len(data)))
+	w.Write(data)
+}
+
+func readVarBytes(r *bytes.Reader) ([]byte, error) {
+	size, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(r.Len()) {
+		return nil, errMalformedTx
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, errMalformedTx
+	}
+	return data, nil
+}
+
+func writeUint32(w *bytes.Buffer, n uint32) {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], n)
+	w.Write(buf[:])
+}
+
+func readUint32(r *bytes.Reader) (uint32, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, errMalformedTx
+	}
+	return binary.LittleEndian.Uint32(buf[:]), nil
+}
+
+func reverseBytes(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i := range data {
+		out[len(data)-1-i] = data[i]
+	}
+	return out
+}
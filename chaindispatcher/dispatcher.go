@@ -3,16 +3,18 @@ package chaindispatcher
 import (
 	"context"
 	"github.com/CavnHan/wallet-chain-account/chain"
-	"github.com/CavnHan/wallet-chain-account/chain/ethereum"
+	"github.com/CavnHan/wallet-chain-account/common/retry"
 	"github.com/CavnHan/wallet-chain-account/config"
 	"github.com/CavnHan/wallet-chain-account/rpc/account"
 	"github.com/CavnHan/wallet-chain-account/rpc/common"
+	"github.com/CavnHan/wallet-chain-account/webhook"
 	"github.com/ethereum/go-ethereum/log"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"runtime/debug"
 	"strings"
+	"sync"
 )
 
 //RPC请求拦截器
@@ -22,12 +24,36 @@ type CommonRequest interface {
 	GetChain() string
 }
 
+// 管理接口, 使用 webhook.admin_token 鉴权
+var adminMethods = map[string]bool{
+	"AddWebhookWatch":    true,
+	"RemoveWebhookWatch": true,
+}
+
 type CommonReply = account.SupportChainsResponse
 type ChainType = string
 
 type ChainDispatcher struct {
-	//注册链,key:链名,value:链适配器
-	registry map[ChainType]chain.IChainAdaptor
+	//注册链,key:链名,value:链适配器, 后台重试成功或热加载时替换, 读写需加锁
+	registry map[ChainType]*adaptorEntry
+	//配置中每条链的初始化状态
+	status map[ChainType]*chainStatus
+	//当前生效的配置
+	conf *config.Config
+	lock sync.RWMutex
+	//串行化热加载
+	reloadLock sync.Mutex
+	//监听端口, 热加载不生效
+	serverPort string
+
+	//已确认转账的回调通知
+	webhook *webhook.Notifier
+
+	//停止后台重试
+	ctx    context.Context
+	cancel context.CancelFunc
+	//后台重试初始化的退避策略
+	setupRetry retry.Strategy
 }
 
 /**
@@ -35,37 +61,27 @@ type ChainDispatcher struct {
  * @param config 配置文件
  */
 func New(conf *config.Config) (*ChainDispatcher, error) {
+	return newDispatcher(conf, setupRetryStrategy)
+}
+
+func newDispatcher(conf *config.Config, setupRetry retry.Strategy) (*ChainDispatcher, error) {
 	//初始化调度器
+	ctx, cancel := context.WithCancel(context.Background())
 	dispatcher := ChainDispatcher{
-		registry: make(map[ChainType]chain.IChainAdaptor),
-	}
-	//链适配器工厂 map
-	chainAdaptorFactorMap := map[string]func(conf *config.Config) (chain.IChainAdaptor, error){
-		//链名:工厂方法
-		//add 支持的链以及对应的工厂方法
-		ethereum.ChainName: ethereum.NewChainAdaptor,
-	}
-
-	supportedChains := []string{
-		ethereum.ChainName,
-	}
-	//遍历配置文件中的链，根据工厂返回对应的链的适配器，即为链对应的chainAdaptor的实现
-	for _, c := range conf.Chains {
-		if factory, ok := chainAdaptorFactorMap[c]; ok {
-			//调用工厂方法，返回链适配器
-			adaptor, err := factory(conf)
-			if err != nil {
-				log.Crit("failed to setup chain", "chain", c, "error", err)
-			}
-			//注册链适配器
-			dispatcher.registry[c] = adaptor
-
-		} else {
-			log.Error("unsupported chain", "chain", c, "supportedChains", supportedChains)
-		}
+		conf:       &config.Config{},
+		serverPort: conf.Server.Port,
+		registry:   make(map[ChainType]*adaptorEntry),
+		status:     make(map[ChainType]*chainStatus),
+		ctx:        ctx,
+		cancel:     cancel,
+		setupRetry: setupRetry,
+	}
+	if _, err := dispatcher.apply(conf); err != nil {
+		cancel()
+		return nil, err
 	}
+	dispatcher.webhook = webhook.New(conf, dispatcher.adaptor)
 	return &dispatcher, nil
-
 }
 
 /**
@@ -91,24 +107,54 @@ func (d *ChainDispatcher) Interceptor(ctx context.Context, req interface{}, info
 	pos := strings.LastIndex(info.FullMethod, "/")
 	method := info.FullMethod[pos+1:]
 
-	//获取链名
+	//获取链名, 管理接口的请求带有管理 token, 不记录
 	chainName := req.(CommonRequest).GetChain()
-	log.Info(method, "chain", chainName, "req", req)
+	if adminMethods[method] {
+		log.Info(method, "chain", chainName)
+	} else {
+		log.Info(method, "chain", chainName, "req", req)
+	}
 
-	//调用handler处理请求
-	resp, err = handler(ctx, req)
+	//调用handler处理请求, 处理期间适配器不会因热加载被关闭; handler 通过上下文使用同一个适配器
+	adaptor, release := d.acquire(chainName)
+	defer release()
+	resp, err = handler(withAdaptor(ctx, adaptor), req)
 	log.Debug("Finish handling", "resp:", resp, "err:", err)
 	return
 }
 
+/**
+* @description: 流式 RPC 拦截器, 捕获 panic
+* 流式请求持续时间不定, 不计入适配器的处理中请求; 适配器被热加载替换后订阅以错误事件结束, 由调用方重新订阅
+* @param srv 服务
+* @param ss 服务端流
+* @param info 服务信息
+* @param handler 处理器
+* @return err 错误
+ */
+func (d *ChainDispatcher) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			log.Error("panic error", "msg", e)
+			log.Debug(string(debug.Stack()))
+			err = status.Errorf(codes.Internal, "Panic err: %v", e)
+		}
+	}()
+	log.Info("stream open", "method", info.FullMethod)
+	err = handler(srv, ss)
+	log.Info("stream closed", "method", info.FullMethod, "err", err)
+	return
+}
+
 /**
 * @description: 预处理
+* @param ctx 上下文
 * @param req 请求
 * @return resp 响应
  */
-func (d *ChainDispatcher) preHandler(req interface{}) (resp *CommonReply) {
+func (d *ChainDispatcher) preHandler(ctx context.Context, req interface{}) (resp *CommonReply) {
 	chainName := req.(CommonRequest).GetChain()
-	if _, ok := d.registry[chainName]; !ok {
+	if d.requestAdaptor(ctx, chainName) == nil {
 		return &CommonReply{
 			Code:    common.ReturnCode_ERROR,
 			Msg:     config.UnsupportedOperation,
@@ -118,195 +164,467 @@ func (d *ChainDispatcher) preHandler(req interface{}) (resp *CommonReply) {
 	return nil
 }
 
+// GetSupportChains 返回链的实时状态, 初始化失败 (后台重试中) 或不支持的链 support 为 false
 func (d *ChainDispatcher) GetSupportChains(ctx context.Context, request *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
-	//前置处理
-	resp := d.preHandler(request)
-	if resp != nil {
+	st, ok := d.chainStatus(request.Chain)
+	if !ok {
 		return &account.SupportChainsResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  config.UnsupportedOperation,
 		}, nil
 	}
+	//状态与拦截器取得的适配器分别读取, 期间链可能被热加载移除
+	adaptor := d.requestAdaptor(ctx, request.Chain)
+	if st.state != chainStateAvailable || adaptor == nil {
+		return &account.SupportChainsResponse{
+			Code:    common.ReturnCode_SUCCESS,
+			Msg:     st.describe(),
+			Support: false,
+		}, nil
+	}
 	//调用链适配器的方法
-	return d.registry[request.Chain].GetSupportChains(request)
+	return adaptor.GetSupportChains(request)
 }
 
 func (d *ChainDispatcher) ConvertAddress(ctx context.Context, request *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.ConvertAddressResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "covert address fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].ConvertAddress(request)
+	return d.requestAdaptor(ctx, request.Chain).ConvertAddress(request)
 }
+
 func (d *ChainDispatcher) ValidAddress(ctx context.Context, request *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
-	//TODO implement me
-	panic("implement me")
+	resp := d.preHandler(ctx, request)
+	if resp != nil {
+		return &account.ValidAddressResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "valid address fail at pre handle",
+		}, nil
+	}
+	return d.requestAdaptor(ctx, request.Chain).ValidAddress(request)
 }
 
 func (d *ChainDispatcher) GetBlockByNumber(ctx context.Context, request *account.BlockNumberRequest) (*account.BlockResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.BlockResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "get block by number fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].GetBlockByNumber(request)
+	return d.requestAdaptor(ctx, request.Chain).GetBlockByNumber(request)
 }
 
 func (d *ChainDispatcher) GetBlockByHash(ctx context.Context, request *account.BlockHashRequest) (*account.BlockResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.BlockResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "get block by hash fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].GetBlockByHash(request)
+	return d.requestAdaptor(ctx, request.Chain).GetBlockByHash(request)
 }
 
 func (d *ChainDispatcher) GetBlockHeaderByHash(ctx context.Context, request *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.BlockHeaderResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "get block header by hash fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].GetBlockHeaderByHash(request)
+	return d.requestAdaptor(ctx, request.Chain).GetBlockHeaderByHash(request)
 }
 
 func (d *ChainDispatcher) GetBlockHeaderByNumber(ctx context.Context, request *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.BlockHeaderResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "get block header by number fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].GetBlockHeaderByNumber(request)
+	return d.requestAdaptor(ctx, request.Chain).GetBlockHeaderByNumber(request)
 }
 
 func (d *ChainDispatcher) GetAccount(ctx context.Context, request *account.AccountRequest) (*account.AccountResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.AccountResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "get account information fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].GetAccount(request)
+	return d.requestAdaptor(ctx, request.Chain).GetAccount(request)
 }
 
 func (d *ChainDispatcher) GetFee(ctx context.Context, request *account.FeeRequest) (*account.FeeResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.FeeResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "get fee fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].GetFee(request)
+	return d.requestAdaptor(ctx, request.Chain).GetFee(request)
 }
 
 func (d *ChainDispatcher) SendTx(ctx context.Context, request *account.SendTxRequest) (*account.SendTxResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.SendTxResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "send tx fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].SendTx(request)
+	return d.requestAdaptor(ctx, request.Chain).SendTx(request)
 }
 
 func (d *ChainDispatcher) GetTxByAddress(ctx context.Context, request *account.TxAddressRequest) (*account.TxAddressResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.TxAddressResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "get tx by address fail pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].GetTxByAddress(request)
+	return d.requestAdaptor(ctx, request.Chain).GetTxByAddress(request)
 }
 
 func (d *ChainDispatcher) GetTxByHash(ctx context.Context, request *account.TxHashRequest) (*account.TxHashResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.TxHashResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "get tx by hash fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].GetTxByHash(request)
+	return d.requestAdaptor(ctx, request.Chain).GetTxByHash(request)
 }
 
 func (d *ChainDispatcher) GetBlockByRange(ctx context.Context, request *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.BlockByRangeResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "get blcok by range fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].GetBlockByRange(request)
+	return d.requestAdaptor(ctx, request.Chain).GetBlockByRange(request)
 }
 
 func (d *ChainDispatcher) CreateUnSignTransaction(ctx context.Context, request *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.UnSignTransactionResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "get un sign tx fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].CreateUnSignTransaction(request)
+	return d.requestAdaptor(ctx, request.Chain).CreateUnSignTransaction(request)
 }
 
 func (d *ChainDispatcher) BuildSignedTransaction(ctx context.Context, request *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.SignedTransactionResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "signed tx fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].BuildSignedTransaction(request)
+	return d.requestAdaptor(ctx, request.Chain).BuildSignedTransaction(request)
 }
 
 func (d *ChainDispatcher) DecodeTransaction(ctx context.Context, request *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.DecodeTransactionResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "decode tx fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].DecodeTransaction(request)
+	return d.requestAdaptor(ctx, request.Chain).DecodeTransaction(request)
 }
 
 func (d *ChainDispatcher) VerifySignedTransaction(ctx context.Context, request *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.VerifyTransactionResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "verify tx fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].VerifySignedTransaction(request)
+	return d.requestAdaptor(ctx, request.Chain).VerifySignedTransaction(request)
 }
 
 func (d *ChainDispatcher) GetExtraData(ctx context.Context, request *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
-	resp := d.preHandler(request)
+	resp := d.preHandler(ctx, request)
 	if resp != nil {
 		return &account.ExtraDataResponse{
 			Code: common.ReturnCode_ERROR,
 			Msg:  "get extra data fail at pre handle",
 		}, nil
 	}
-	return d.registry[request.Chain].GetExtraData(request)
+	return d.requestAdaptor(ctx, request.Chain).GetExtraData(request)
+}
+
+func (d *ChainDispatcher) GetUnspentOutputs(ctx context.Context, request *account.UnspentOutputsRequest) (*account.UnspentOutputsResponse, error) {
+	resp := d.preHandler(ctx, request)
+	if resp != nil {
+		return &account.UnspentOutputsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get unspent outputs fail at pre handle",
+		}, nil
+	}
+	utxoAdaptor, ok := d.requestAdaptor(ctx, request.Chain).(chain.IUtxoAdaptor)
+	if !ok {
+		return &account.UnspentOutputsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "chain does not support unspent outputs",
+		}, nil
+	}
+	return utxoAdaptor.GetUnspentOutputs(request)
+}
+
+func (d *ChainDispatcher) DetectReorg(ctx context.Context, request *account.ReorgRequest) (*account.ReorgResponse, error) {
+	resp := d.preHandler(ctx, request)
+	if resp != nil {
+		return &account.ReorgResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "detect reorg fail at pre handle",
+		}, nil
+	}
+	reorgAdaptor, ok := d.requestAdaptor(ctx, request.Chain).(chain.IReorgAdaptor)
+	if !ok {
+		return &account.ReorgResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "chain does not support reorg detection",
+		}, nil
+	}
+	return reorgAdaptor.DetectReorg(request)
+}
+
+func (d *ChainDispatcher) GetTransferLogs(ctx context.Context, request *account.TransferLogsRequest) (*account.TransferLogsResponse, error) {
+	resp := d.preHandler(ctx, request)
+	if resp != nil {
+		return &account.TransferLogsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get transfer logs fail at pre handle",
+		}, nil
+	}
+	transferLogAdaptor, ok := d.requestAdaptor(ctx, request.Chain).(chain.ITransferLogAdaptor)
+	if !ok {
+		return &account.TransferLogsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "chain does not support transfer log query",
+		}, nil
+	}
+	return transferLogAdaptor.GetTransferLogs(request)
+}
+
+func (d *ChainDispatcher) GetNfts(ctx context.Context, request *account.NftsRequest) (*account.NftsResponse, error) {
+	resp := d.preHandler(ctx, request)
+	if resp != nil {
+		return &account.NftsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get nfts fail at pre handle",
+		}, nil
+	}
+	nftAdaptor, ok := d.requestAdaptor(ctx, request.Chain).(chain.INftAdaptor)
+	if !ok {
+		return &account.NftsResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "chain does not support nft",
+		}, nil
+	}
+	return nftAdaptor.GetNfts(request)
+}
+
+func (d *ChainDispatcher) GetBalances(ctx context.Context, request *account.BalancesRequest) (*account.BalancesResponse, error) {
+	resp := d.preHandler(ctx, request)
+	if resp != nil {
+		return &account.BalancesResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get balances fail at pre handle",
+		}, nil
+	}
+	balanceAdaptor, ok := d.requestAdaptor(ctx, request.Chain).(chain.IBatchBalanceAdaptor)
+	if !ok {
+		return &account.BalancesResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "chain does not support batch balance query",
+		}, nil
+	}
+	return balanceAdaptor.GetBalances(request)
+}
+
+func (d *ChainDispatcher) ReserveNonce(ctx context.Context, request *account.ReserveNonceRequest) (*account.ReserveNonceResponse, error) {
+	resp := d.preHandler(ctx, request)
+	if resp != nil {
+		return &account.ReserveNonceResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "reserve nonce fail at pre handle",
+		}, nil
+	}
+	nonceAdaptor, ok := d.requestAdaptor(ctx, request.Chain).(chain.INonceAdaptor)
+	if !ok {
+		return &account.ReserveNonceResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "chain does not support nonce reservation",
+		}, nil
+	}
+	return nonceAdaptor.ReserveNonce(request)
+}
+
+func (d *ChainDispatcher) ReleaseNonce(ctx context.Context, request *account.ReleaseNonceRequest) (*account.ReleaseNonceResponse, error) {
+	resp := d.preHandler(ctx, request)
+	if resp != nil {
+		return &account.ReleaseNonceResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "release nonce fail at pre handle",
+		}, nil
+	}
+	nonceAdaptor, ok := d.requestAdaptor(ctx, request.Chain).(chain.INonceAdaptor)
+	if !ok {
+		return &account.ReleaseNonceResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "chain does not support nonce reservation",
+		}, nil
+	}
+	return nonceAdaptor.ReleaseNonce(request)
+}
+
+func (d *ChainDispatcher) GetNftMetadata(ctx context.Context, request *account.NftMetadataRequest) (*account.NftMetadataResponse, error) {
+	resp := d.preHandler(ctx, request)
+	if resp != nil {
+		return &account.NftMetadataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "get nft metadata fail at pre handle",
+		}, nil
+	}
+	nftAdaptor, ok := d.requestAdaptor(ctx, request.Chain).(chain.INftAdaptor)
+	if !ok {
+		return &account.NftMetadataResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "chain does not support nft",
+		}, nil
+	}
+	return nftAdaptor.GetNftMetadata(request)
+}
+
+func (d *ChainDispatcher) BuildNftTransfer(ctx context.Context, request *account.NftTransferRequest) (*account.NftTransferResponse, error) {
+	resp := d.preHandler(ctx, request)
+	if resp != nil {
+		return &account.NftTransferResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "build nft transfer fail at pre handle",
+		}, nil
+	}
+	nftAdaptor, ok := d.requestAdaptor(ctx, request.Chain).(chain.INftAdaptor)
+	if !ok {
+		return &account.NftTransferResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "chain does not support nft",
+		}, nil
+	}
+	return nftAdaptor.BuildNftTransfer(request)
+}
+
+func (d *ChainDispatcher) SubscribeBlocks(request *account.SubscribeBlocksRequest, stream grpc.ServerStreamingServer[account.BlockEvent]) error {
+	log.Info("SubscribeBlocks", "chain", request.Chain, "req", request)
+	resp := d.preHandler(stream.Context(), request)
+	if resp != nil {
+		return stream.Send(&account.BlockEvent{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "subscribe blocks fail at pre handle",
+		})
+	}
+	subscriber, ok := d.requestAdaptor(stream.Context(), request.Chain).(chain.IBlockSubscriber)
+	if !ok {
+		return stream.Send(&account.BlockEvent{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "chain does not support block subscription",
+		})
+	}
+	return subscriber.SubscribeBlocks(stream.Context(), request, stream.Send)
+}
+
+func (d *ChainDispatcher) WatchAddresses(request *account.WatchAddressesRequest, stream grpc.ServerStreamingServer[account.TransferEvent]) error {
+	log.Info("WatchAddresses", "chain", request.Chain, "addresses", len(request.Addresses))
+	resp := d.preHandler(stream.Context(), request)
+	if resp != nil {
+		return stream.Send(&account.TransferEvent{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "watch addresses fail at pre handle",
+		})
+	}
+	watcher, ok := d.requestAdaptor(stream.Context(), request.Chain).(chain.IAddressWatcher)
+	if !ok {
+		return stream.Send(&account.TransferEvent{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "chain does not support address watching",
+		})
+	}
+	return watcher.WatchAddresses(stream.Context(), request, stream.Send)
+}
+
+// AddWebhookWatch 添加回调通知的监听地址, 只保存在内存中, 重启后需重新添加
+func (d *ChainDispatcher) AddWebhookWatch(ctx context.Context, request *account.WebhookWatchRequest) (*account.WebhookWatchResponse, error) {
+	if err := d.webhook.Authorize(request.ConsumerToken); err != nil {
+		return &account.WebhookWatchResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	resp := d.preHandler(ctx, request)
+	if resp != nil {
+		return &account.WebhookWatchResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  "add webhook watch fail at pre handle",
+		}, nil
+	}
+	id, err := d.webhook.Add(config.WebhookWatch{
+		Chain:             request.Chain,
+		Addresses:         request.Addresses,
+		ContractAddresses: request.ContractAddresses,
+		CallbackUrl:       request.CallbackUrl,
+	})
+	if err != nil {
+		log.Error("add webhook watch fail", "chain", request.Chain, "err", err)
+		return &account.WebhookWatchResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	return &account.WebhookWatchResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "add webhook watch success",
+		WatchId: id,
+	}, nil
+}
+
+func (d *ChainDispatcher) RemoveWebhookWatch(ctx context.Context, request *account.RemoveWebhookWatchRequest) (*account.WebhookWatchResponse, error) {
+	if err := d.webhook.Authorize(request.ConsumerToken); err != nil {
+		return &account.WebhookWatchResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	if err := d.webhook.Remove(request.WatchId); err != nil {
+		log.Error("remove webhook watch fail", "watch", request.WatchId, "err", err)
+		return &account.WebhookWatchResponse{
+			Code: common.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	return &account.WebhookWatchResponse{
+		Code:    common.ReturnCode_SUCCESS,
+		Msg:     "remove webhook watch success",
+		WatchId: request.WatchId,
+	}, nil
 }
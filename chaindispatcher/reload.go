@@ -0,0 +1,149 @@
+package chaindispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/config"
+)
+
+// reloadResult 一次加载中各链的处理结果, 用于日志
+type reloadResult struct {
+	kept    []string
+	built   []string
+	removed []string
+	failed  []string
+}
+
+type retryJob struct {
+	chainName string
+	st        *chainStatus
+	factory   chain.Factory
+	ctx       context.Context
+}
+
+/**
+ * @description: 热加载配置, 仅重建配置发生变化的链适配器, 构建完成后一次性替换注册表
+ * 被替换或移除的适配器在其正在处理的请求结束后关闭
+ * strict 模式下任一链初始化失败则放弃本次加载, 保留当前配置;
+ * degraded 模式下失败的链继续使用旧适配器 (若有), 并在后台按新配置重试
+ * @param conf 新配置
+ */
+func (d *ChainDispatcher) Reload(conf *config.Config) error {
+	result, err := d.apply(conf)
+	if err != nil {
+		log.Error("reload config fail, keep current config", "err", err)
+		return err
+	}
+	d.webhook.Update(conf)
+	if d.serverPort != conf.Server.Port {
+		log.Warn("server port change requires restart", "current", d.serverPort, "configured", conf.Server.Port)
+	}
+	log.Info("reload config success", "kept", result.kept, "rebuilt", result.built, "removed", result.removed, "failed", result.failed)
+	return nil
+}
+
+// apply 按配置构建注册表, 启动与热加载共用; 当前注册表为空时即为启动流程
+func (d *ChainDispatcher) apply(conf *config.Config) (*reloadResult, error) {
+	startupMode, err := startupModeOf(conf)
+	if err != nil {
+		return nil, err
+	}
+	d.reloadLock.Lock()
+	defer d.reloadLock.Unlock()
+
+	d.lock.RLock()
+	oldConf, oldRegistry, oldStatus := d.conf, d.registry, d.status
+	d.lock.RUnlock()
+
+	//已通过 chain.Register 注册的链, 适配器包需被导入 (见 chain/builtin)
+	supportedChains := chain.Registered()
+	result := &reloadResult{}
+	registry := make(map[ChainType]*adaptorEntry)
+	status := make(map[ChainType]*chainStatus)
+	built := make(map[ChainType]*adaptorEntry)
+	var retries []retryJob
+	var setupErrs []error
+	//遍历配置文件中的链，根据工厂返回对应的链的适配器，即为链对应的chainAdaptor的实现
+	for _, c := range conf.Chains {
+		if _, ok := status[c]; ok {
+			continue
+		}
+		factory, ok := chain.Lookup(c)
+		if !ok {
+			log.Error("unsupported chain", "chain", c, "supportedChains", supportedChains)
+			status[c] = &chainStatus{state: chainStateUnsupported}
+			setupErrs = append(setupErrs, fmt.Errorf("chain %s: unsupported chain", c))
+			continue
+		}
+		old, running := oldRegistry[c]
+		if running && oldStatus[c].state == chainStateAvailable && oldStatus[c].lastErr == nil &&
+			reflect.DeepEqual(oldConf.ChainSection(c), conf.ChainSection(c)) {
+			registry[c] = old
+			status[c] = &chainStatus{state: chainStateAvailable, attempts: oldStatus[c].attempts}
+			result.kept = append(result.kept, c)
+			continue
+		}
+		//调用工厂方法，返回链适配器
+		adaptor, err := factory(conf)
+		if err != nil {
+			log.Error("failed to setup chain", "chain", c, "mode", startupMode, "error", err)
+			setupErrs = append(setupErrs, fmt.Errorf("chain %s: %w", c, err))
+			result.failed = append(result.failed, c)
+			st := &chainStatus{state: chainStateUnavailable, attempts: 1, lastErr: err}
+			if running {
+				//旧适配器继续服务, 重试成功后替换
+				registry[c] = old
+				st.state = chainStateAvailable
+			}
+			status[c] = st
+			retries = append(retries, retryJob{chainName: c, st: st, factory: factory})
+			continue
+		}
+		entry := &adaptorEntry{adaptor: adaptor}
+		built[c] = entry
+		registry[c] = entry
+		status[c] = &chainStatus{state: chainStateAvailable, attempts: 1}
+		result.built = append(result.built, c)
+	}
+
+	if len(setupErrs) > 0 && startupMode == config.StartupModeStrict {
+		for c, entry := range built {
+			closeAdaptor(c, entry.adaptor)
+		}
+		return nil, fmt.Errorf("setup chains fail in strict mode: %w", errors.Join(setupErrs...))
+	}
+	for i := range retries {
+		ctx, stop := context.WithCancel(d.ctx)
+		retries[i].ctx, retries[i].st.stop = ctx, stop
+	}
+
+	//一次性替换, 请求只会看到完整的旧注册表或新注册表
+	d.lock.Lock()
+	d.conf, d.registry, d.status = conf, registry, status
+	d.lock.Unlock()
+
+	for c, st := range oldStatus {
+		if st.stop != nil {
+			st.stop()
+		}
+		if _, ok := status[c]; !ok && st.state != chainStateUnsupported {
+			result.removed = append(result.removed, c)
+		}
+	}
+	for c, old := range oldRegistry {
+		if registry[c] != old {
+			go drain(c, old)
+		}
+	}
+	//初始化失败的链在后台重试
+	for _, job := range retries {
+		go d.retrySetup(job.ctx, job.chainName, job.st, job.factory, conf)
+	}
+	return result, nil
+}
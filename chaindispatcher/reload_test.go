@@ -0,0 +1,100 @@
+package chaindispatcher
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CavnHan/wallet-chain-account/config"
+)
+
+// withConfirmations 修改链的配置段, 使热加载重建该链
+func withConfirmations(conf *config.Config, chainName string, confirmations uint64) *config.Config {
+	next := *conf
+	next.ChainConfigs = make(map[string]config.ChainConfig, len(conf.ChainConfigs)+1)
+	for name, chainConf := range conf.ChainConfigs {
+		next.ChainConfigs[name] = chainConf
+	}
+	next.ChainConfigs[chainName] = config.ChainConfig{Node: config.Node{Confirmations: confirmations}}
+	return &next
+}
+
+// 配置未变化的链保留原适配器, 变化的链重建, 移除的链关闭
+func TestReloadKeepsUnchanged(t *testing.T) {
+	kept := registerFakeChain(t, 0)
+	changed := registerFakeChain(t, 0)
+	removed := registerFakeChain(t, 0)
+	conf := testConfig(config.StartupModeStrict, kept.name, changed.name, removed.name)
+	d, err := newTestDispatcher(t, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keptAdaptor, changedAdaptor := d.adaptor(kept.name), d.adaptor(changed.name)
+
+	next := withConfirmations(conf, changed.name, 3)
+	next.Chains = []string{kept.name, changed.name}
+	result, err := d.apply(next)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(result.kept) != 1 || result.kept[0] != kept.name ||
+		len(result.built) != 1 || result.built[0] != changed.name ||
+		len(result.removed) != 1 || result.removed[0] != removed.name {
+		t.Errorf("result = %+v", result)
+	}
+	if d.adaptor(kept.name) != keptAdaptor {
+		t.Error("unchanged adaptor was replaced")
+	}
+	if d.adaptor(changed.name) == changedAdaptor || atomic.LoadInt32(&changed.builds) != 2 {
+		t.Errorf("changed adaptor not rebuilt, builds = %d", atomic.LoadInt32(&changed.builds))
+	}
+	if d.adaptor(removed.name) != nil {
+		t.Error("removed chain still registered")
+	}
+	waitFor(t, "retired adaptors to close", func() bool { return changed.closed() == 1 && removed.closed() == 1 })
+	if kept.closed() != 0 {
+		t.Errorf("unchanged adaptor closed %d times", kept.closed())
+	}
+}
+
+// 被替换的适配器等处理中的请求结束后才关闭
+func TestReloadDrainsInflight(t *testing.T) {
+	fc := registerFakeChain(t, 0)
+	conf := testConfig(config.StartupModeStrict, fc.name)
+	d, err := newTestDispatcher(t, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, release := d.acquire(fc.name)
+
+	if err := d.Reload(withConfirmations(conf, fc.name, 3)); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if d.adaptor(fc.name) == old {
+		t.Fatal("adaptor not replaced")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if fc.closed() != 0 {
+		t.Fatal("old adaptor closed while a request was in flight")
+	}
+	release()
+	waitFor(t, "old adaptor to close after the request", func() bool { return fc.closed() == 1 })
+}
+
+// strict 模式下重建失败时保留当前配置与适配器
+func TestReloadStrictFailureKeepsCurrent(t *testing.T) {
+	fc := registerFakeChain(t, 0)
+	conf := testConfig(config.StartupModeStrict, fc.name)
+	d, err := newTestDispatcher(t, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := d.adaptor(fc.name)
+	atomic.StoreInt32(&fc.failures, 1)
+	if err := d.Reload(withConfirmations(conf, fc.name, 3)); err == nil {
+		t.Fatal("Reload should fail in strict mode")
+	}
+	if d.adaptor(fc.name) != current || fc.closed() != 0 {
+		t.Errorf("current adaptor replaced or closed (%d) after a failed reload", fc.closed())
+	}
+}
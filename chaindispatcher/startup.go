@@ -0,0 +1,179 @@
+package chaindispatcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/common/retry"
+	"github.com/CavnHan/wallet-chain-account/config"
+)
+
+type chainState int
+
+const (
+	chainStateAvailable chainState = iota
+	//初始化失败, 后台重试中
+	chainStateUnavailable
+	//配置了不支持的链名, 不会重试
+	chainStateUnsupported
+)
+
+type chainStatus struct {
+	state    chainState
+	attempts int
+	lastErr  error
+	//停止该状态对应的后台重试, 状态被热加载替换时调用
+	stop context.CancelFunc
+}
+
+func (s *chainStatus) describe() string {
+	switch s.state {
+	case chainStateAvailable:
+		return "Support this chain"
+	case chainStateUnavailable:
+		return fmt.Sprintf("chain unavailable, retrying initialisation after %d attempts: %v", s.attempts, s.lastErr)
+	}
+	return "chain configured but not supported"
+}
+
+// adaptorEntry 注册表中的适配器, inflight 记录正在处理的请求, 被替换后等待请求结束再关闭
+type adaptorEntry struct {
+	adaptor  chain.IChainAdaptor
+	inflight sync.WaitGroup
+}
+
+// 后台重试初始化的退避策略, 最长 5 分钟重试一次
+var setupRetryStrategy = &retry.ExponentialStrategy{
+	Min:       time.Second,
+	Max:       5 * time.Minute,
+	MaxJitter: time.Second,
+}
+
+func startupModeOf(conf *config.Config) (string, error) {
+	switch conf.StartupMode {
+	case "":
+		return config.StartupModeStrict, nil
+	case config.StartupModeStrict, config.StartupModeDegraded:
+		return conf.StartupMode, nil
+	}
+	return "", fmt.Errorf("unknown startup mode %s", conf.StartupMode)
+}
+
+func (d *ChainDispatcher) adaptor(chainName string) chain.IChainAdaptor {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if entry, ok := d.registry[chainName]; ok {
+		return entry.adaptor
+	}
+	return nil
+}
+
+// acquire 标记请求开始使用当前适配器, 返回该适配器与请求结束时调用的 release; 链不可用时适配器为 nil, release 为空操作
+func (d *ChainDispatcher) acquire(chainName string) (chain.IChainAdaptor, func()) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	entry, ok := d.registry[chainName]
+	if !ok {
+		return nil, func() {}
+	}
+	entry.inflight.Add(1)
+	return entry.adaptor, entry.inflight.Done
+}
+
+// acquiredAdaptor 拦截器取得的适配器, 链不可用时 adaptor 为 nil
+type acquiredAdaptor struct {
+	adaptor chain.IChainAdaptor
+}
+
+type acquiredAdaptorKey struct{}
+
+func withAdaptor(ctx context.Context, adaptor chain.IChainAdaptor) context.Context {
+	return context.WithValue(ctx, acquiredAdaptorKey{}, acquiredAdaptor{adaptor: adaptor})
+}
+
+// requestAdaptor 请求使用的适配器, 优先使用拦截器取得的适配器, 使其与 inflight 计数的一致; 流式请求等未经拦截器时查询注册表
+func (d *ChainDispatcher) requestAdaptor(ctx context.Context, chainName string) chain.IChainAdaptor {
+	if acquired, ok := ctx.Value(acquiredAdaptorKey{}).(acquiredAdaptor); ok {
+		return acquired.adaptor
+	}
+	return d.adaptor(chainName)
+}
+
+func (d *ChainDispatcher) chainStatus(chainName string) (chainStatus, bool) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	st, ok := d.status[chainName]
+	if !ok {
+		return chainStatus{}, false
+	}
+	return *st, true
+}
+
+/**
+ * @description: 后台重试初始化链适配器, 成功后注册 (替换旧适配器时等待其请求结束后关闭)
+ * 状态被热加载替换或 Close 后停止
+ * @param ctx 该状态的上下文
+ * @param chainName 链名
+ * @param st 发起重试的状态
+ * @param factory 工厂方法
+ * @param conf 配置文件
+ */
+func (d *ChainDispatcher) retrySetup(ctx context.Context, chainName string, st *chainStatus, factory chain.Factory, conf *config.Config) {
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.setupRetry.Duration(attempt)):
+		}
+		adaptor, err := factory(conf)
+		d.lock.Lock()
+		if d.status[chainName] != st {
+			d.lock.Unlock()
+			if err == nil {
+				closeAdaptor(chainName, adaptor)
+			}
+			return
+		}
+		st.attempts++
+		attempts := st.attempts
+		if err != nil {
+			st.lastErr = err
+			d.lock.Unlock()
+			log.Warn("retry setup chain fail", "chain", chainName, "attempts", attempts, "error", err)
+			continue
+		}
+		st.state, st.lastErr = chainStateAvailable, nil
+		old := d.registry[chainName]
+		d.registry[chainName] = &adaptorEntry{adaptor: adaptor}
+		d.lock.Unlock()
+		log.Info("chain setup success after retry", "chain", chainName, "attempts", attempts)
+		if old != nil {
+			go drain(chainName, old)
+		}
+		return
+	}
+}
+
+// drain 等待旧适配器上的请求处理完毕后关闭
+func drain(chainName string, entry *adaptorEntry) {
+	entry.inflight.Wait()
+	closeAdaptor(chainName, entry.adaptor)
+}
+
+func closeAdaptor(chainName string, adaptor chain.IChainAdaptor) {
+	if closer, ok := adaptor.(chain.IClosableAdaptor); ok {
+		closer.Close()
+		log.Info("closed retired chain adaptor", "chain", chainName)
+	}
+}
+
+// Close 停止后台重试与回调通知
+func (d *ChainDispatcher) Close() {
+	d.cancel()
+	d.webhook.Close()
+}
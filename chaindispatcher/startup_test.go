@@ -0,0 +1,172 @@
+package chaindispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/common/retry"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+// 测试中重试不等待
+var testRetryStrategy = &retry.ExponentialStrategy{Min: time.Millisecond, Max: time.Millisecond}
+
+var fakeChainSeq int32
+
+// fakeChain 注册到 chain 包的测试链, 前 failures 次初始化失败
+type fakeChain struct {
+	name     string
+	failures int32
+	builds   int32
+	closes   int32
+}
+
+// registerFakeChain 注册一条链名唯一的测试链
+func registerFakeChain(t *testing.T, failures int32) *fakeChain {
+	t.Helper()
+	fc := &fakeChain{
+		name:     fmt.Sprintf("%s-%d", t.Name(), atomic.AddInt32(&fakeChainSeq, 1)),
+		failures: failures,
+	}
+	chain.Register(fc.name, fc.factory)
+	return fc
+}
+
+func (fc *fakeChain) factory(conf *config.Config) (chain.IChainAdaptor, error) {
+	if atomic.AddInt32(&fc.failures, -1) >= 0 {
+		return nil, errors.New("node unavailable")
+	}
+	atomic.AddInt32(&fc.builds, 1)
+	return &fakeAdaptor{chain: fc}, nil
+}
+
+func (fc *fakeChain) closed() int32 {
+	return atomic.LoadInt32(&fc.closes)
+}
+
+type fakeAdaptor struct {
+	chain.IChainAdaptor
+	chain *fakeChain
+}
+
+func (a *fakeAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{Code: common.ReturnCode_SUCCESS, Msg: "Support this chain", Support: true}, nil
+}
+
+func (a *fakeAdaptor) Close() {
+	atomic.AddInt32(&a.chain.closes, 1)
+}
+
+func testConfig(mode string, chains ...string) *config.Config {
+	return &config.Config{NetWork: "mainnet", StartupMode: mode, Chains: chains}
+}
+
+func newTestDispatcher(t *testing.T, conf *config.Config) (*ChainDispatcher, error) {
+	t.Helper()
+	d, err := newDispatcher(conf, testRetryStrategy)
+	if err == nil {
+		t.Cleanup(d.Close)
+	}
+	return d, err
+}
+
+func supportChains(t *testing.T, d *ChainDispatcher, chainName string) *account.SupportChainsResponse {
+	t.Helper()
+	adaptor, release := d.acquire(chainName)
+	defer release()
+	resp, err := d.GetSupportChains(withAdaptor(context.Background(), adaptor), &account.SupportChainsRequest{Chain: chainName})
+	if err != nil {
+		t.Fatalf("GetSupportChains(%s): %v", chainName, err)
+	}
+	return resp
+}
+
+// waitFor 轮询直到 cond 成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// strict 模式汇总所有失败的链后退出, 已初始化的适配器被关闭
+func TestStartupStrict(t *testing.T) {
+	ok := registerFakeChain(t, 0)
+	bad := registerFakeChain(t, 1)
+	_, err := newTestDispatcher(t, testConfig(config.StartupModeStrict, ok.name, bad.name, "NoSuchChain"))
+	if err == nil {
+		t.Fatal("strict startup should fail")
+	}
+	for _, want := range []string{bad.name + ": node unavailable", "NoSuchChain: unsupported chain"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want %q", err, want)
+		}
+	}
+	if ok.closed() != 1 {
+		t.Errorf("built adaptor closed %d times, want 1", ok.closed())
+	}
+}
+
+// degraded 模式跳过失败的链, 其余链正常服务, 状态如实报告
+func TestStartupDegraded(t *testing.T) {
+	ok := registerFakeChain(t, 0)
+	bad := registerFakeChain(t, 1<<20)
+	d, err := newTestDispatcher(t, testConfig(config.StartupModeDegraded, ok.name, bad.name, "NoSuchChain"))
+	if err != nil {
+		t.Fatalf("degraded startup: %v", err)
+	}
+	if d.adaptor(ok.name) == nil || d.adaptor(bad.name) != nil {
+		t.Errorf("registered %v, %v, want only %s", d.adaptor(ok.name), d.adaptor(bad.name), ok.name)
+	}
+	tests := []struct {
+		chain   string
+		support bool
+		msg     string
+	}{
+		{ok.name, true, "Support this chain"},
+		{bad.name, false, "chain unavailable, retrying initialisation"},
+		{"NoSuchChain", false, "chain configured but not supported"},
+	}
+	for _, tt := range tests {
+		resp := supportChains(t, d, tt.chain)
+		if resp.Support != tt.support || !strings.Contains(resp.Msg, tt.msg) {
+			t.Errorf("%s: support %v (%s), want %v (%s)", tt.chain, resp.Support, resp.Msg, tt.support, tt.msg)
+		}
+	}
+	if resp := supportChains(t, d, "NotConfigured"); resp.Code != common.ReturnCode_ERROR {
+		t.Errorf("chain not in config: code = %v, want ERROR", resp.Code)
+	}
+}
+
+// 后台重试成功后注册该链
+func TestRetrySetup(t *testing.T) {
+	flaky := registerFakeChain(t, 3)
+	d, err := newTestDispatcher(t, testConfig(config.StartupModeDegraded, flaky.name))
+	if err != nil {
+		t.Fatalf("degraded startup: %v", err)
+	}
+	waitFor(t, "retry to register the chain", func() bool { return d.adaptor(flaky.name) != nil })
+
+	st, _ := d.chainStatus(flaky.name)
+	if st.state != chainStateAvailable || st.attempts != 4 || st.lastErr != nil {
+		t.Errorf("status = %+v, want available after 4 attempts", st)
+	}
+	if resp := supportChains(t, d, flaky.name); !resp.Support {
+		t.Errorf("support = false (%s), want true", resp.Msg)
+	}
+	if builds := atomic.LoadInt32(&flaky.builds); builds != 1 {
+		t.Errorf("adaptor built %d times, want 1", builds)
+	}
+}
@@ -0,0 +1,85 @@
+package base58
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	ErrInvalidBase58   = errors.New("invalid base58 string")
+	ErrInvalidChecksum = errors.New("invalid base58check checksum")
+)
+
+// Encode 比特币字母表的 base58 编码
+func Encode(input []byte) string {
+	x := new(big.Int).SetBytes(input)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var out []byte
+	for x.Sign() > 0 {
+		x.DivMod(x, radix, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+	for _, b := range input {
+		if b != 0 {
+			break
+		}
+		out = append(out, alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func Decode(input string) ([]byte, error) {
+	if input == "" {
+		return nil, ErrInvalidBase58
+	}
+	x := new(big.Int)
+	radix := big.NewInt(58)
+	for _, r := range input {
+		idx := strings.IndexRune(alphabet, r)
+		if idx < 0 {
+			return nil, ErrInvalidBase58
+		}
+		x.Mul(x, radix)
+		x.Add(x, big.NewInt(int64(idx)))
+	}
+	leadingZeros := 0
+	for leadingZeros < len(input) && input[leadingZeros] == alphabet[0] {
+		leadingZeros++
+	}
+	return append(make([]byte, leadingZeros), x.Bytes()...), nil
+}
+
+// Checksum 双 sha256 的前 4 字节
+func Checksum(input []byte) []byte {
+	first := sha256.Sum256(input)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
+
+func CheckEncode(input []byte) string {
+	return Encode(append(append([]byte{}, input...), Checksum(input)...))
+}
+
+func CheckDecode(input string) ([]byte, error) {
+	decoded, err := Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) < 5 {
+		return nil, ErrInvalidBase58
+	}
+	payload, sum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	if !bytes.Equal(Checksum(payload), sum) {
+		return nil, ErrInvalidChecksum
+	}
+	return payload, nil
+}
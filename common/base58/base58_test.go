@@ -0,0 +1,72 @@
+package base58
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// bitcoin core 的 base58_encode_decode.json
+var base58Vectors = []struct {
+	hex     string
+	encoded string
+}{
+	{"61", "2g"},
+	{"626262", "a3gV"},
+	{"636363", "aPEr"},
+	{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+	{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+	{"516b6fcd0f", "ABnLTmg"},
+	{"bf4f89001e670274dd", "3SEo3LWLoPntC"},
+	{"572e4794", "3EFU7m"},
+	{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+	{"10c8511e", "Rt5zm"},
+	{"00000000000000000000", "1111111111"},
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, tt := range base58Vectors {
+		input, _ := hex.DecodeString(tt.hex)
+		if got := Encode(input); got != tt.encoded {
+			t.Errorf("Encode(%s) = %s, want %s", tt.hex, got, tt.encoded)
+		}
+		decoded, err := Decode(tt.encoded)
+		if err != nil {
+			t.Errorf("Decode(%s): %v", tt.encoded, err)
+			continue
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Errorf("Decode(%s) = %x, want %s", tt.encoded, decoded, tt.hex)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, in := range []string{"", "0", "O", "I", "l", "3SEo3LWLoPntC0", "abc def"} {
+		if _, err := Decode(in); err == nil {
+			t.Errorf("Decode(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestCheckEncode(t *testing.T) {
+	// 比特币 P2PKH 地址: 版本 0x00 + hash160
+	payload, _ := hex.DecodeString("00010966776006953d5567439e5e39f86a0d273bee")
+	const address = "16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvM"
+	if got := CheckEncode(payload); got != address {
+		t.Errorf("CheckEncode = %s, want %s", got, address)
+	}
+	decoded, err := CheckDecode(address)
+	if err != nil {
+		t.Fatalf("CheckDecode: %v", err)
+	}
+	if !bytes.Equal(decoded, payload) {
+		t.Errorf("CheckDecode = %x", decoded)
+	}
+	if _, err := CheckDecode("16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvN"); err != ErrInvalidChecksum {
+		t.Errorf("CheckDecode with a bad checksum: err = %v", err)
+	}
+	if _, err := CheckDecode("1111"); err != ErrInvalidBase58 {
+		t.Errorf("CheckDecode of a short string: err = %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package bcs
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrInvalidBcs = errors.New("invalid bcs data")
+
+// Writer Move 系链 (Aptos/Sui) 使用的 BCS 序列化, 长度与枚举下标使用 uleb128
+type Writer struct {
+	buf []byte
+}
+
+func NewWriter(prefix []byte) *Writer {
+	return &Writer{buf: append([]byte{}, prefix...)}
+}
+
+func (w *Writer) Bytes() []byte {
+	return w.buf
+}
+
+func (w *Writer) Uleb128(v uint64) {
+	for v >= 0x80 {
+		w.buf = append(w.buf, byte(v)|0x80)
+		v >>= 7
+	}
+	w.buf = append(w.buf, byte(v))
+}
+
+func (w *Writer) U8(v uint8) {
+	w.buf = append(w.buf, v)
+}
+
+func (w *Writer) U16(v uint16) {
+	w.buf = binary.LittleEndian.AppendUint16(w.buf, v)
+}
+
+func (w *Writer) U64(v uint64) {
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, v)
+}
+
+func (w *Writer) Bool(v bool) {
+	if v {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+// Fixed 定长字节数组, 不带长度前缀
+func (w *Writer) Fixed(v []byte) {
+	w.buf = append(w.buf, v...)
+}
+
+// Vec 变长字节数组, 带 uleb128 长度前缀
+func (w *Writer) Vec(v []byte) {
+	w.Uleb128(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+func (w *Writer) Str(v string) {
+	w.Vec([]byte(v))
+}
+
+type Reader struct {
+	data []byte
+	pos  int
+}
+
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+func (r *Reader) Uleb128() (uint64, error) {
+	var v uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b, err := r.U8()
+		if err != nil {
+			return 0, err
+		}
+		v |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+	return 0, ErrInvalidBcs
+}
+
+func (r *Reader) U8() (uint8, error) {
+	if r.pos >= len(r.data) {
+		return 0, ErrInvalidBcs
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *Reader) U16() (uint16, error) {
+	b, err := r.Fixed(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(b), nil
+}
+
+func (r *Reader) U64() (uint64, error) {
+	b, err := r.Fixed(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+func (r *Reader) Bool() (bool, error) {
+	b, err := r.U8()
+	if err != nil {
+		return false, err
+	}
+	if b > 1 {
+		return false, ErrInvalidBcs
+	}
+	return b == 1, nil
+}
+
+func (r *Reader) Fixed(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, ErrInvalidBcs
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *Reader) Vec() ([]byte, error) {
+	n, err := r.Uleb128()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.data)) {
+		return nil, ErrInvalidBcs
+	}
+	return r.Fixed(int(n))
+}
+
+func (r *Reader) Str() (string, error) {
+	b, err := r.Vec()
+	return string(b), err
+}
+
+func (r *Reader) Remaining() []byte {
+	return r.data[r.pos:]
+}
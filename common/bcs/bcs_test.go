@@ -0,0 +1,99 @@
+package bcs
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+// BCS 规范中的 uleb128 示例
+func TestUleb128(t *testing.T) {
+	tests := []struct {
+		value   uint64
+		encoded string
+	}{
+		{0, "00"},
+		{1, "01"},
+		{127, "7f"},
+		{128, "8001"},
+		{16383, "ff7f"},
+		{16384, "808001"},
+		{2097151, "ffff7f"},
+		{2097152, "80808001"},
+		{math.MaxUint32, "ffffffff0f"},
+		{math.MaxUint64, "ffffffffffffffffff01"},
+	}
+	for _, tt := range tests {
+		w := NewWriter(nil)
+		w.Uleb128(tt.value)
+		if got := hex.EncodeToString(w.Bytes()); got != tt.encoded {
+			t.Errorf("Uleb128(%d) = %s, want %s", tt.value, got, tt.encoded)
+		}
+		encoded, _ := hex.DecodeString(tt.encoded)
+		r := NewReader(encoded)
+		value, err := r.Uleb128()
+		if err != nil || value != tt.value || len(r.Remaining()) != 0 {
+			t.Errorf("read %s = %d, %v, want %d", tt.encoded, value, err, tt.value)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	w := NewWriter([]byte{0xaa})
+	w.U8(1)
+	w.U16(0x1234)
+	w.U64(0x0102030405060708)
+	w.Bool(true)
+	w.Bool(false)
+	w.Fixed([]byte{0xde, 0xad})
+	w.Vec([]byte{0xbe, 0xef})
+	w.Str("bcs")
+	want := "aa" + "01" + "3412" + "0807060504030201" + "01" + "00" + "dead" + "02beef" + "03626373"
+	if got := hex.EncodeToString(w.Bytes()); got != want {
+		t.Fatalf("Writer = %s, want %s", got, want)
+	}
+
+	r := NewReader(w.Bytes()[1:])
+	u8, _ := r.U8()
+	u16, _ := r.U16()
+	u64, _ := r.U64()
+	b1, _ := r.Bool()
+	b2, _ := r.Bool()
+	fixed, _ := r.Fixed(2)
+	vec, _ := r.Vec()
+	str, err := r.Str()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u8 != 1 || u16 != 0x1234 || u64 != 0x0102030405060708 || !b1 || b2 ||
+		!bytes.Equal(fixed, []byte{0xde, 0xad}) || !bytes.Equal(vec, []byte{0xbe, 0xef}) || str != "bcs" {
+		t.Errorf("Reader = %d %x %x %v %v %x %x %q", u8, u16, u64, b1, b2, fixed, vec, str)
+	}
+	if len(r.Remaining()) != 0 {
+		t.Errorf("remaining %x", r.Remaining())
+	}
+}
+
+func TestReaderInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		read func(r *Reader) error
+	}{
+		{"empty u8", "", func(r *Reader) error { _, err := r.U8(); return err }},
+		{"short u16", "01", func(r *Reader) error { _, err := r.U16(); return err }},
+		{"short u64", "01020304050607", func(r *Reader) error { _, err := r.U64(); return err }},
+		{"bool out of range", "02", func(r *Reader) error { _, err := r.Bool(); return err }},
+		{"unterminated uleb128", "8080", func(r *Reader) error { _, err := r.Uleb128(); return err }},
+		{"uleb128 too long", "80808080808080808080", func(r *Reader) error { _, err := r.Uleb128(); return err }},
+		{"vec longer than data", "05010203", func(r *Reader) error { _, err := r.Vec(); return err }},
+		{"huge vec length", "ffffffffffffffffff01", func(r *Reader) error { _, err := r.Vec(); return err }},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.data)
+		if err := tt.read(NewReader(data)); err != ErrInvalidBcs {
+			t.Errorf("%s: err = %v, want ErrInvalidBcs", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package bech32
+
+import (
+	"errors"
+	"strings"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// Encoding 校验和常量, BIP-173 为 bech32, BIP-350 为 bech32m
+type Encoding uint32
+
+const (
+	Bech32  Encoding = 1
+	Bech32m Encoding = 0x2bc830a3
+
+	// BIP-173 限制总长度 90, Cosmos 部分地址 (如 32 字节模块账户) 会超出, 这里放宽
+	maxLength = 1023
+)
+
+var (
+	ErrInvalidBech32   = errors.New("invalid bech32 string")
+	ErrInvalidChecksum = errors.New("invalid bech32 checksum")
+	ErrInvalidPadding  = errors.New("invalid bech32 padding")
+)
+
+var generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func hrpExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// Encode data 为 5 bit 分组
+func Encode(hrp string, data []byte, encoding Encoding) (string, error) {
+	hrp = strings.ToLower(hrp)
+	values := append(hrpExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := polymod(values) ^ uint32(encoding)
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		if d >= 32 {
+			return "", ErrInvalidBech32
+		}
+		sb.WriteByte(charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(charset[(mod>>(5*(5-i)))&31])
+	}
+	return sb.String(), nil
+}
+
+// Decode 返回 hrp, 5 bit 分组的数据以及校验和类型
+func Decode(s string) (string, []byte, Encoding, error) {
+	if len(s) > maxLength {
+		return "", nil, 0, ErrInvalidBech32
+	}
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return "", nil, 0, ErrInvalidBech32
+	}
+	pos := strings.LastIndexByte(lower, '1')
+	if pos < 1 || pos+7 > len(lower) {
+		return "", nil, 0, ErrInvalidBech32
+	}
+	hrp := lower[:pos]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, 0, ErrInvalidBech32
+		}
+	}
+	data := make([]byte, 0, len(lower)-pos-1)
+	for i := pos + 1; i < len(lower); i++ {
+		d := strings.IndexByte(charset, lower[i])
+		if d < 0 {
+			return "", nil, 0, ErrInvalidBech32
+		}
+		data = append(data, byte(d))
+	}
+	encoding := Encoding(polymod(append(hrpExpand(hrp), data...)))
+	if encoding != Bech32 && encoding != Bech32m {
+		return "", nil, 0, ErrInvalidChecksum
+	}
+	return hrp, data[:len(data)-6], encoding, nil
+}
+
+// ConvertBits 在 8 bit 与 5 bit 分组之间转换
+func ConvertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc, bits uint
+	maxv := uint(1)<<toBits - 1
+	var out []byte
+	for _, b := range data {
+		if uint(b)>>fromBits != 0 {
+			return nil, ErrInvalidBech32
+		}
+		acc = acc<<fromBits | uint(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte((acc>>bits)&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte((acc<<(toBits-bits))&maxv))
+		}
+	} else if bits >= fromBits || (acc<<(toBits-bits))&maxv != 0 {
+		return nil, ErrInvalidPadding
+	}
+	return out, nil
+}
+
+// EncodeFromBase256 Cosmos 等地址使用的 bech32 编码, 输入为原始字节
+func EncodeFromBase256(hrp string, data []byte) (string, error) {
+	converted, err := ConvertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return Encode(hrp, converted, Bech32)
+}
+
+// DecodeToBase256 解码 bech32 字符串并转回原始字节
+func DecodeToBase256(s string) (string, []byte, error) {
+	hrp, data, encoding, err := Decode(s)
+	if err != nil {
+		return "", nil, err
+	}
+	if encoding != Bech32 {
+		return "", nil, ErrInvalidChecksum
+	}
+	converted, err := ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, converted, nil
+}
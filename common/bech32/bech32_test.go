@@ -0,0 +1,112 @@
+package bech32
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// BIP-173 与 BIP-350 的校验和测试向量
+func TestDecodeValid(t *testing.T) {
+	tests := []struct {
+		in       string
+		encoding Encoding
+	}{
+		{"A12UEL5L", Bech32},
+		{"a12uel5l", Bech32},
+		{"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs", Bech32},
+		{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", Bech32},
+		{"11" + strings.Repeat("q", 82) + "c8247j", Bech32},
+		{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", Bech32},
+		{"?1ezyfcl", Bech32},
+		{"A1LQFN3A", Bech32m},
+		{"a1lqfn3a", Bech32m},
+		{"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6", Bech32m},
+		{"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx", Bech32m},
+		{"11" + strings.Repeat("l", 83) + "udsr8", Bech32m},
+		{"split1checkupstagehandshakeupstreamerranterredcaperredlc445v", Bech32m},
+		{"?1v759aa", Bech32m},
+	}
+	for _, tt := range tests {
+		hrp, data, encoding, err := Decode(tt.in)
+		if err != nil {
+			t.Errorf("Decode(%q): %v", tt.in, err)
+			continue
+		}
+		if encoding != tt.encoding {
+			t.Errorf("Decode(%q) encoding = %x, want %x", tt.in, encoding, tt.encoding)
+		}
+		encoded, err := Encode(hrp, data, encoding)
+		if err != nil {
+			t.Errorf("Encode(%q): %v", tt.in, err)
+			continue
+		}
+		if encoded != strings.ToLower(tt.in) {
+			t.Errorf("round trip of %q = %q", tt.in, encoded)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []string{
+		"\x201nwldj5",   // hrp 字符超出范围
+		"\x7f1axkwrx",   // hrp 字符超出范围
+		"pzry9x0s0muk",  // 没有分隔符
+		"1pzry9x0s0muk", // hrp 为空
+		"x1b4n0q5v",     // 数据中有无效字符
+		"li1dgmt3",      // 校验和过短
+		"de1lg7wt\xff",  // 数据中有无效字符
+		"A1G7SGD8",      // 校验和按大写 hrp 计算
+		"10a06t8",       // hrp 为空
+		"1qzzfhee",      // hrp 为空
+		"a12UEL5L",      // 大小写混用
+		"a12uel5m",      // 校验和错误
+		"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryy", // bech32m 校验和错误
+	}
+	for _, in := range tests {
+		if _, _, _, err := Decode(in); err == nil {
+			t.Errorf("Decode(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestBase256RoundTrip(t *testing.T) {
+	// BIP-173 P2WPKH 地址的见证程序
+	program, _ := hex.DecodeString("751e76e8199196d454941c45d1b3a323f1433bd6")
+	encoded, err := EncodeFromBase256("cosmos", program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//数据部分与 BIP-173 中 bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4 去掉见证版本后相同
+	if !strings.HasPrefix(encoded, "cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k") || len(encoded) != len("cosmos1")+32+6 {
+		t.Errorf("EncodeFromBase256 = %s", encoded)
+	}
+	hrp, decoded, err := DecodeToBase256(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hrp != "cosmos" || !bytes.Equal(decoded, program) {
+		t.Errorf("DecodeToBase256 = %s %x", hrp, decoded)
+	}
+	if _, _, err := DecodeToBase256("a1lqfn3a"); err == nil {
+		t.Error("DecodeToBase256 accepted a bech32m string")
+	}
+}
+
+func TestConvertBitsPadding(t *testing.T) {
+	tests := []struct {
+		data []byte
+		ok   bool
+	}{
+		{[]byte{0x1f, 0x1c}, true},  // 10 bit, 末尾 2 bit 为 0
+		{[]byte{0x1f, 0x1d}, false}, // 末尾填充位不为 0
+		{[]byte{0x1f}, false},       // 5 bit 不足一个字节且超过 4 bit 填充
+	}
+	for _, tt := range tests {
+		_, err := ConvertBits(tt.data, 5, 8, false)
+		if (err == nil) != tt.ok {
+			t.Errorf("ConvertBits(%x): err = %v, want ok %v", tt.data, err, tt.ok)
+		}
+	}
+}
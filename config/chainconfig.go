@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ChainConfig chain_configs 下按链名配置的节点, 通用字段与 Node 相同
+// params 为链特有的配置 (如 chain id, 精度, bech32 前缀, 钱包版本), 由适配器通过 DecodeParams 自行解析与校验
+type ChainConfig struct {
+	Node   `yaml:",inline"`
+	Params map[string]interface{} `yaml:"params"`
+}
+
+// DecodeParams 将 params 解析到适配器定义的结构体, 包含未知键时报错
+func (c *ChainConfig) DecodeParams(out interface{}) error {
+	if len(c.Params) == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(c.Params)
+	if err != nil {
+		return err
+	}
+	err = yaml.UnmarshalStrict(data, out)
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+	//params 重新序列化后行号无意义, 只保留键名
+	var errs []error
+	for _, msg := range typeErr.Errors {
+		if match := unknownFieldPattern.FindStringSubmatch(msg); match != nil {
+			errs = append(errs, fmt.Errorf("unknown param %s", match[2]))
+			continue
+		}
+		errs = append(errs, errors.New(msg))
+	}
+	return errors.Join(errs...)
+}
+
+/**
+ * @description: 链的配置, 优先使用 chain_configs 中以链名为键的配置, 否则兼容旧的 wallet_node 布局
+ * 旧布局中 CosmosNode/TonNode/UtxoNode 的特有字段转换为 params
+ * @param chainName 链名
+ * @return ok 两种布局中均未配置 rpc 时为 false
+ */
+func (c *Config) Chain(chainName string) (*ChainConfig, bool) {
+	chainConf, _, ok := c.chainConfig(chainName)
+	return chainConf, ok
+}
+
+// chainConfig 同时返回配置所在的路径, 用于校验报错
+func (c *Config) chainConfig(chainName string) (*ChainConfig, string, bool) {
+	if chainConf, ok := c.ChainConfigs[chainName]; ok {
+		return &chainConf, "chain_configs." + chainName, true
+	}
+	return c.legacyChainConfig(chainName)
+}
+
+func (c *Config) legacyChainConfig(chainName string) (*ChainConfig, string, bool) {
+	key, node, ok := c.chainNode(chainName)
+	if !ok {
+		return nil, "", false
+	}
+	path := "wallet_node." + key
+	var chainConf *ChainConfig
+	switch n := node.(type) {
+	case Node:
+		chainConf = &ChainConfig{Node: n}
+	case CosmosNode:
+		chainConf = &ChainConfig{Node: n.Node, Params: map[string]interface{}{
+			"chain_id":      n.ChainId,
+			"bech32_prefix": n.Bech32Prefix,
+			"denom":         n.Denom,
+			"gas_price":     n.GasPrice,
+		}}
+	case TonNode:
+		chainConf = &ChainConfig{Node: n.Node, Params: map[string]interface{}{
+			"wallet_version": n.WalletVersion,
+			"wallet_code":    n.WalletCode,
+			"subwallet_id":   n.SubwalletId,
+			"testnet":        n.Testnet,
+		}}
+	case UtxoNode:
+		chainConf = &ChainConfig{Node: n.Node, Params: map[string]interface{}{
+			"network":      n.Network,
+			"address_type": n.AddressType,
+		}}
+	}
+	return chainConf, path, len(chainConf.RPCs) > 0
+}
+
+// ChainValidator 校验链的配置 (通常为 params), 由适配器在 init() 中注册
+type ChainValidator func(chainConf *ChainConfig) error
+
+var (
+	validatorsLock sync.RWMutex
+	validators     = make(map[string]ChainValidator)
+)
+
+// RegisterChainValidator 注册链配置的校验方法, Validate 对启用的链调用; 重复注册时 panic
+func RegisterChainValidator(chainName string, validator ChainValidator) {
+	validatorsLock.Lock()
+	defer validatorsLock.Unlock()
+	if _, dup := validators[chainName]; dup {
+		panic(fmt.Sprintf("config: register validator called twice for %s", chainName))
+	}
+	validators[chainName] = validator
+}
+
+func chainValidator(chainName string) (ChainValidator, bool) {
+	validatorsLock.RLock()
+	defer validatorsLock.RUnlock()
+	validator, ok := validators[chainName]
+	return validator, ok
+}
+
+var (
+	legacyKeysLock sync.RWMutex
+	legacyKeys     = make(map[string]string)
+)
+
+// RegisterLegacyNodeKey 注册链在旧布局 wallet_node 下的键名, 由适配器在 init() 中注册; 未注册时为链名的小写形式, 重复注册时 panic
+func RegisterLegacyNodeKey(chainName, key string) {
+	legacyKeysLock.Lock()
+	defer legacyKeysLock.Unlock()
+	if _, dup := legacyKeys[chainName]; dup {
+		panic(fmt.Sprintf("config: register legacy node key called twice for %s", chainName))
+	}
+	legacyKeys[chainName] = key
+}
+
+func legacyNodeKey(chainName string) string {
+	legacyKeysLock.RLock()
+	defer legacyKeysLock.RUnlock()
+	if key, ok := legacyKeys[chainName]; ok {
+		return key
+	}
+	return strings.ToLower(chainName)
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"reflect"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 
@@ -19,58 +21,172 @@ type RPC struct {
 }
 
 type Node struct {
-	RPCs         []*RPC `yaml:"rpcs"`
-	DataApiUrl   string `yaml:"data_api_url"`
-	DataApiKey   string `yaml:"data_api_key"`
-	DataApiToken string `yaml:"data_api_token"`
-	TimeOut      uint64 `yaml:"time_out"`
+	RPCs         []*RPC   `yaml:"rpcs"`
+	DataApiUrl   string   `yaml:"data_api_url"`
+	DataApiKey   string   `yaml:"data_api_key"`
+	DataApiToken string   `yaml:"data_api_token"`
+	TimeOut      Duration `yaml:"time_out"`
+	// Confirmations 交易所在区块之上至少有该数量的区块 (含自身) 才视为确认, 0 表示使用链的默认值
+	Confirmations uint64 `yaml:"confirmations"`
+	// ConfirmationMode 为 depth (默认, 使用 Confirmations), safe 或 finalized (使用节点的 safe/finalized 区块标签)
+	ConfirmationMode string `yaml:"confirmation_mode"`
 }
 
+// 交易确认的判断方式
+const (
+	ConfirmationModeDepth     = "depth"
+	ConfirmationModeSafe      = "safe"
+	ConfirmationModeFinalized = "finalized"
+)
+
+// CosmosNode 旧布局 wallet_node.cosmos, 新布局中对应 chain_configs.Cosmos.params
+// 每个 Cosmos SDK 链 (zone) 的地址前缀, 原生代币和 gas 价格不同, 需单独配置
+// ChainId 为空时从最新区块头读取
+type CosmosNode struct {
+	Node         `yaml:",inline"`
+	ChainId      string `yaml:"chain_id"`
+	Bech32Prefix string `yaml:"bech32_prefix"`
+	Denom        string `yaml:"denom"`
+	GasPrice     string `yaml:"gas_price"`
+}
+
+// TonNode 旧布局 wallet_node.ton, 新布局中对应 chain_configs.Ton.params
+// 同一公钥在不同钱包合约版本与 subwallet 下对应不同地址, 需与签名端保持一致
+// WalletVersion 为 v3r2/v4r2/v5r1, 默认 v4r2; v5r1 需通过 WalletCode 提供 base64 编码的合约代码
+type TonNode struct {
+	Node          `yaml:",inline"`
+	WalletVersion string `yaml:"wallet_version"`
+	WalletCode    string `yaml:"wallet_code"`
+	SubwalletId   uint32 `yaml:"subwallet_id"`
+	Testnet       bool   `yaml:"testnet"`
+}
+
+// UtxoNode 旧布局 wallet_node.bitcoin, 新布局中对应 chain_configs.Bitcoin.params
+// UTXO 链节点, rpc_url 为 http(s) 时使用 Bitcoin Core JSON-RPC (rpc_user/rpc_pass 为 basic auth),
+// 为 tcp:// 或 ssl:// 时使用 Electrum 协议; Network 为 mainnet/testnet/regtest, AddressType 为 ConvertAddress 派生的地址类型
+type UtxoNode struct {
+	Node        `yaml:",inline"`
+	Network     string `yaml:"network"`
+	AddressType string `yaml:"address_type"`
+}
+
+// WalletNode 旧的按字段配置的布局, 仍然兼容; 新增的链使用 chain_configs, 无需修改本包
 type WalletNode struct {
-	Eth     Node `yaml:"eth"`
-	Arbi    Node `yaml:"arbi"`
-	Op      Node `yaml:"op"`
-	Zksync  Node `yaml:"zksync"`
-	Bsc     Node `yaml:"bsc"`
-	Heco    Node `yaml:"heco"`
-	Avax    Node `yaml:"avax"`
-	Polygon Node `yaml:"polygon"`
-	Tron    Node `yaml:"tron"`
-	Sol     Node `yaml:"solana"`
-	Cosmos  Node `yaml:"cosmos"`
-	Aptos   Node `yaml:"aptos"`
-	Mantle  Node `yaml:"mantle"`
-	Scroll  Node `yaml:"scroll"`
-	Base    Node `yaml:"base"`
-	Linea   Node `yaml:"linea"`
-	Sui     Node `yaml:"sui"`
-	Ton     Node `yaml:"ton"`
-	Arweave Node `yaml:"arweave"`
+	Eth     Node       `yaml:"eth"`
+	Arbi    Node       `yaml:"arbi"`
+	Op      Node       `yaml:"op"`
+	Zksync  Node       `yaml:"zksync"`
+	Bsc     Node       `yaml:"bsc"`
+	Heco    Node       `yaml:"heco"`
+	Avax    Node       `yaml:"avax"`
+	Polygon Node       `yaml:"polygon"`
+	Tron    Node       `yaml:"tron"`
+	Sol     Node       `yaml:"solana"`
+	Cosmos  CosmosNode `yaml:"cosmos"`
+	Aptos   Node       `yaml:"aptos"`
+	Mantle  Node       `yaml:"mantle"`
+	Scroll  Node       `yaml:"scroll"`
+	Base    Node       `yaml:"base"`
+	Linea   Node       `yaml:"linea"`
+	Sui     Node       `yaml:"sui"`
+	Ton     TonNode    `yaml:"ton"`
+	Arweave Node       `yaml:"arweave"`
+	Bitcoin UtxoNode   `yaml:"bitcoin"`
 }
 
+// 链适配器初始化失败时的启动模式
+const (
+	// StartupModeStrict 汇总所有初始化失败与不支持的链后退出
+	StartupModeStrict = "strict"
+	// StartupModeDegraded 初始化失败的链标记为不可用, 后台重试初始化, 其余链正常服务
+	StartupModeDegraded = "degraded"
+)
+
 type Config struct {
-	Server     Server     `yaml:"server"`
-	WalletNode WalletNode `yaml:"wallet_node"`
-	NetWork    string     `yaml:"network"`
-	Chains     []string   `yaml:"chains"`
+	Server      Server     `yaml:"server"`
+	WalletNode  WalletNode `yaml:"wallet_node"`
+	NetWork     string     `yaml:"network"`
+	Chains      []string   `yaml:"chains"`
+	StartupMode string     `yaml:"startup_mode"`
+	//按链名配置, 优先于 wallet_node 中的同名链
+	ChainConfigs map[string]ChainConfig `yaml:"chain_configs"`
+	//已确认转账的回调通知
+	Webhook Webhook `yaml:"webhook"`
+
+	//加载时发现的未知键, 由 Validate 报告
+	unknownKeys []string
 }
 
 func New(path string) (*Config, error) {
-	var config = new(Config)
 	h := log.NewTerminalHandler(os.Stdout, true)
 	log.SetDefault(log.NewLogger(h))
+	return Load(path)
+}
 
+/**
+ * @description: 读取并解析配置文件, 热加载时使用, 不重新设置日志
+ * 依次处理 ${ENV} 替换, *_file 键从文件读取, 解析后再用 WCA_ 前缀的环境变量覆盖 (见 env.go)
+ * 只做解析, 未知键等问题由 Validate 报告
+ * @param path 配置文件路径
+ */
+func Load(path string) (*Config, error) {
+	var config = new(Config)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	//替换环境变量会重新编码文档, 未知键的行号按原始文件计算
+	config.unknownKeys = unknownKeys(data)
+	data, err = interpolateEnv(data)
+	if err != nil {
+		return nil, err
+	}
+	data, err = resolveFileRefs(data)
+	if err != nil {
+		return nil, err
+	}
 
 	err = yaml.Unmarshal(data, config)
 	if err != nil {
 		return nil, err
 	}
+	if err := applyEnvOverrides(config); err != nil {
+		return nil, err
+	}
 	return config, nil
 }
 
+/**
+ * @description: 旧布局中链对应的 wallet_node 配置段, 按 yaml 键名查找, 键名见 RegisterLegacyNodeKey
+ * @param chainName 链名
+ * @return key wallet_node 下的键名
+ * @return node 配置段 (Node 或内嵌 Node 的结构体)
+ * @return ok wallet_node 中是否有该键
+ */
+func (c *Config) chainNode(chainName string) (key string, node interface{}, ok bool) {
+	key = legacyNodeKey(chainName)
+	v := reflect.ValueOf(c.WalletNode)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if tag, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); tag == key {
+			return key, v.Field(i).Interface(), true
+		}
+	}
+	return "", nil, false
+}
+
+/**
+ * @description: 链适配器依赖的配置段, 热加载时比较前后是否变化以决定是否重建适配器
+ * @param chainName 链名
+ */
+func (c *Config) ChainSection(chainName string) interface{} {
+	chainConf, _ := c.Chain(chainName)
+	//network 影响所有链 (如 chain id)
+	return struct {
+		NetWork string
+		Chain   *ChainConfig
+	}{c.NetWork, chainConf}
+}
+
 const UnsupportedChain = "Unsupport chain"
 const UnsupportedOperation = UnsupportedChain
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 与 ethereum 适配器的注册一致, config 的测试不引入适配器包
+func init() {
+	RegisterLegacyNodeKey("Ethereum", "eth")
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadUnknownKeyLineWithEnvReference(t *testing.T) {
+	path := writeConfig(t, `server:
+  port: 8189
+network: mainnet
+chains: [Ethereum]
+chain_configs:
+  Ethereum:
+    rpcs:
+      - rpc_url: 'http://127.0.0.1:8545'
+    data_api_key: '${WCA_TEST_UNSET_KEY:-}'
+    bogus_key: 1
+`)
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(conf.unknownKeys) != 1 {
+		t.Fatalf("unknown keys = %v, want one", conf.unknownKeys)
+	}
+	if !strings.HasPrefix(conf.unknownKeys[0], "line 10: unknown key bogus_key") {
+		t.Errorf("unknown key reported as %q, want line 10", conf.unknownKeys[0])
+	}
+}
+
+func TestLoadLegacyIntegerTimeout(t *testing.T) {
+	path := writeConfig(t, `server:
+  port: 8189
+network: mainnet
+chains: [Ethereum]
+wallet_node:
+  eth:
+    rpcs:
+      - rpc_url: 'https://rpc.flashbots.net'
+    confirmations: 4
+    data_api_url: 'https://api.etherscan.io/api?'
+    data_api_key: ''
+    data_api_token: ''
+    time_out: 100
+`)
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := conf.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if got := conf.WalletNode.Eth.TimeOut.Duration(); got != 100*time.Second {
+		t.Errorf("time_out = %s, want 100s", got)
+	}
+}
+
+func TestDurationUnmarshal(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"10s", 10 * time.Second, false},
+		{"500ms", 500 * time.Millisecond, false},
+		{"1m", time.Minute, false},
+		{"100", 100 * time.Second, false},
+		{"0", 0, false},
+		{"~", 0, false},
+		{"-5", 0, true},
+		{"ten", 0, true},
+		{"99999999999999999", 0, true},
+	}
+	for _, tt := range tests {
+		var v struct {
+			D Duration `yaml:"d"`
+		}
+		err := yaml.Unmarshal([]byte("d: "+tt.in), &v)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && v.D.Duration() != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.in, v.D, tt.want)
+		}
+	}
+}
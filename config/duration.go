@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/yaml.v2"
+)
+
+// Duration 配置中的时长, 应带单位, 如 10s / 500ms / 1m; 旧配置中不带单位的整数按秒解析
+type Duration time.Duration
+
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// UnmarshalYAML 兼容旧的 wallet_node 布局 (time_out: 100 表示 100 秒), 不带单位的整数按秒解析并提示改写
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	switch value := raw.(type) {
+	case nil:
+		*d = 0
+		return nil
+	case string:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return &yaml.TypeError{Errors: []string{fmt.Sprintf("invalid duration %q: %v", value, err)}}
+		}
+		*d = Duration(parsed)
+		return nil
+	case int:
+		if value >= 0 && int64(value) <= maxLegacySeconds {
+			*d = legacySeconds(int64(value))
+			return nil
+		}
+	}
+	return &yaml.TypeError{Errors: []string{fmt.Sprintf("invalid duration %v, expect a value with a unit, e.g. \"10s\"", raw)}}
+}
+
+// 不带单位的秒数上限, 超过时转换为 time.Duration 会溢出
+const maxLegacySeconds = int64(math.MaxInt64 / int64(time.Second))
+
+func legacySeconds(seconds int64) Duration {
+	if seconds != 0 {
+		log.Warn("duration without unit is deprecated, interpreted as seconds", "value", seconds, "use", fmt.Sprintf("%ds", seconds))
+	}
+	return Duration(time.Duration(seconds) * time.Second)
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,409 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// EnvPrefix 环境变量覆盖配置的前缀, 如 WCA_WALLET_NODE_ETH_DATA_API_KEY 覆盖 wallet_node.eth.data_api_key
+	EnvPrefix = "WCA_"
+	// FileSuffix 值从文件读取的键或环境变量后缀, 如 data_api_key_file 或 WCA_WALLET_NODE_ETH_DATA_API_KEY_FILE
+	FileSuffix = "_file"
+)
+
+// ${NAME} 或 ${NAME:-default}
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+/**
+ * @description: 替换配置中字符串值里的 ${NAME} 与 ${NAME:-default}, 变量未设置且无默认值时报错
+ * 在解析后的值上替换, 注释与键名中的引用不处理, 替换后的值重新编码, 其中的 : # 引号与换行不会破坏文档;
+ * 值恰好为一个引用时按 YAML 标量解析 (如 port: ${PORT} 得到数字), 其余形式的 $ 保持原样
+ * @param data 配置文件内容
+ */
+func interpolateEnv(data []byte) ([]byte, error) {
+	if !envRefPattern.Match(data) {
+		return data, nil
+	}
+	var root yaml.MapSlice
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var missing []string
+	resolved, changed := interpolateEnvIn(root, &missing)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("undefined environment variables in config: %s", strings.Join(missing, ", "))
+	}
+	if !changed {
+		return data, nil
+	}
+	return yaml.Marshal(resolved)
+}
+
+func interpolateEnvIn(value interface{}, missing *[]string) (interface{}, bool) {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		changed := false
+		out := make(yaml.MapSlice, len(v))
+		for i, item := range v {
+			resolved, itemChanged := interpolateEnvIn(item.Value, missing)
+			changed = changed || itemChanged
+			out[i] = yaml.MapItem{Key: item.Key, Value: resolved}
+		}
+		return out, changed
+	case []interface{}:
+		changed := false
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			resolved, elemChanged := interpolateEnvIn(elem, missing)
+			changed = changed || elemChanged
+			out[i] = resolved
+		}
+		return out, changed
+	case string:
+		loc := envRefPattern.FindStringIndex(v)
+		if loc == nil {
+			return v, false
+		}
+		out := envRefPattern.ReplaceAllStringFunc(v, func(ref string) string {
+			match := envRefPattern.FindStringSubmatch(ref)
+			if value, ok := os.LookupEnv(match[1]); ok {
+				return value
+			}
+			if match[2] != "" {
+				return match[3]
+			}
+			*missing = append(*missing, match[1])
+			return ref
+		})
+		if loc[0] == 0 && loc[1] == len(v) {
+			return typedScalar(out), true
+		}
+		return out, true
+	}
+	return value, false
+}
+
+// typedScalar 数字与布尔值按 YAML 标量解析, 其余保持字符串, 避免值中的 # 等被当作 YAML 语法
+func typedScalar(value string) interface{} {
+	if strings.TrimSpace(value) != value || strings.Contains(value, "#") {
+		return value
+	}
+	switch scalar := parseScalar(value).(type) {
+	case int, int64, uint64, float64, bool:
+		return scalar
+	}
+	return value
+}
+
+/**
+ * @description: 将 YAML 中以 _file 结尾的键替换为去掉后缀的键, 值为文件内容 (去除末尾换行)
+ * 用于挂载为文件的 Kubernetes secret, 如 data_api_key_file: /run/secrets/etherscan
+ * 同时存在 key 与 key_file 时报错; 不包含 _file 键时原样返回
+ * @param data 配置文件内容
+ */
+func resolveFileRefs(data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte(FileSuffix)) {
+		return data, nil
+	}
+	var root yaml.MapSlice
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	resolved, changed, err := resolveFileRefsIn(root, "")
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return data, nil
+	}
+	return yaml.Marshal(resolved)
+}
+
+func resolveFileRefsIn(value interface{}, path string) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		changed := false
+		keys := make(map[string]bool, len(v))
+		for _, item := range v {
+			keys[fmt.Sprint(item.Key)] = true
+		}
+		out := make(yaml.MapSlice, 0, len(v))
+		for _, item := range v {
+			key := fmt.Sprint(item.Key)
+			itemPath := strings.TrimPrefix(path+"."+key, ".")
+			if file, ok := item.Value.(string); ok && strings.HasSuffix(key, FileSuffix) {
+				target := strings.TrimSuffix(key, FileSuffix)
+				if keys[target] {
+					return nil, false, fmt.Errorf("%s: both %s and %s are set", path, target, key)
+				}
+				content, err := readSecretFile(file)
+				if err != nil {
+					return nil, false, fmt.Errorf("%s: %w", itemPath, err)
+				}
+				out = append(out, yaml.MapItem{Key: target, Value: content})
+				changed = true
+				continue
+			}
+			resolved, itemChanged, err := resolveFileRefsIn(item.Value, itemPath)
+			if err != nil {
+				return nil, false, err
+			}
+			changed = changed || itemChanged
+			out = append(out, yaml.MapItem{Key: item.Key, Value: resolved})
+		}
+		return out, changed, nil
+	case []interface{}:
+		changed := false
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			resolved, elemChanged, err := resolveFileRefsIn(elem, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, false, err
+			}
+			changed = changed || elemChanged
+			out[i] = resolved
+		}
+		return out, changed, nil
+	}
+	return value, false, nil
+}
+
+func readSecretFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+/**
+ * @description: 使用环境变量覆盖配置, 变量名为 WCA_ 加上 yaml 键路径的大写下划线形式
+ * 如 WCA_SERVER_PORT, WCA_WALLET_NODE_ETH_DATA_API_KEY, WCA_WALLET_NODE_ETH_RPCS_0_RPC_URL;
+ * 变量名加 _FILE 后缀时从文件读取值; 列表类型 (如 WCA_CHAINS) 以逗号分隔
+ * @param conf 已解析的配置
+ */
+func applyEnvOverrides(conf *Config) error {
+	return applyEnv(reflect.ValueOf(conf).Elem(), strings.TrimSuffix(EnvPrefix, "_"))
+}
+
+func applyEnv(v reflect.Value, name string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			tag := strings.Split(field.Tag.Get("yaml"), ",")
+			fieldName := name
+			if len(tag) < 2 || tag[1] != "inline" {
+				key := tag[0]
+				if key == "" {
+					key = strings.ToLower(field.Name)
+				}
+				if key == "-" {
+					continue
+				}
+				fieldName = name + "_" + strings.ToUpper(key)
+			}
+			if err := applyEnv(v.Field(i), fieldName); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			if !hasEnvWithPrefix(name + "_") {
+				return nil
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return applyEnv(v.Elem(), name)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Struct || v.Type().Elem().Kind() == reflect.Ptr {
+			//结构体列表按下标覆盖, 下标等于长度时追加
+			for i := 0; i < v.Len() || hasEnvWithPrefix(fmt.Sprintf("%s_%d_", name, i)); i++ {
+				if i == v.Len() {
+					v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+				}
+				if err := applyEnv(v.Index(i), fmt.Sprintf("%s_%d", name, i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		return applyEnvMap(v, name)
+	}
+
+	value, ok, err := lookupEnv(name)
+	if err != nil || !ok {
+		return err
+	}
+	if err := setFromString(v, value); err != nil {
+		return fmt.Errorf("env %s: %w", name, err)
+	}
+	return nil
+}
+
+/**
+ * @description: 按键覆盖 map 中的值, 键名转为大写, 如 WCA_CHAIN_CONFIGS_ETHEREUM_DATA_API_KEY
+ * 值为 interface{} 的 map (chain_configs 的 params) 还可以新增键, 值按 YAML 标量解析,
+ * 如 WCA_CHAIN_CONFIGS_COSMOS_PARAMS_GAS_PRICE; 其余 map 只能覆盖配置文件中已有的键
+ */
+func applyEnvMap(v reflect.Value, name string) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+	free := v.Type().Elem().Kind() == reflect.Interface
+	existing := make(map[string]bool, v.Len())
+	for _, key := range v.MapKeys() {
+		keyName := name + "_" + strings.ToUpper(key.String())
+		existing[keyName] = true
+		if free {
+			value, ok, err := lookupEnv(keyName)
+			if err != nil {
+				return err
+			}
+			if ok {
+				v.SetMapIndex(key, reflect.ValueOf(parseScalar(value)))
+			}
+			continue
+		}
+		//map 的值不可寻址, 复制后覆盖再写回
+		elem := reflect.New(v.Type().Elem()).Elem()
+		elem.Set(v.MapIndex(key))
+		if err := applyEnv(elem, keyName); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+	}
+	if !free {
+		return nil
+	}
+	prefix := name + "_"
+	for _, kv := range os.Environ() {
+		envName, _, _ := strings.Cut(kv, "=")
+		envName = strings.TrimSuffix(envName, strings.ToUpper(FileSuffix))
+		if !strings.HasPrefix(envName, prefix) || existing[envName] {
+			continue
+		}
+		value, ok, err := lookupEnv(envName)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		existing[envName] = true
+		key := strings.ToLower(strings.TrimPrefix(envName, prefix))
+		v.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(parseScalar(value)))
+	}
+	return nil
+}
+
+// parseScalar 按 YAML 标量解析, 使数字与布尔值与配置文件中的写法一致
+func parseScalar(value string) interface{} {
+	var scalar interface{}
+	if err := yaml.Unmarshal([]byte(value), &scalar); err != nil || scalar == nil {
+		return value
+	}
+	switch scalar.(type) {
+	case string, int, int64, uint64, float64, bool:
+		return scalar
+	}
+	return value
+}
+
+// lookupEnv NAME 优先于 NAME_FILE
+func lookupEnv(name string) (string, bool, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true, nil
+	}
+	fileName := name + strings.ToUpper(FileSuffix)
+	if path, ok := os.LookupEnv(fileName); ok {
+		value, err := readSecretFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("env %s: %w", fileName, err)
+		}
+		return value, true, nil
+	}
+	return "", false, nil
+}
+
+func hasEnvWithPrefix(prefix string) bool {
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func setFromString(v reflect.Value, value string) error {
+	if v.Type() == reflect.TypeOf(Duration(0)) {
+		//与配置文件一致, 不带单位的整数按秒解析
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds >= 0 && seconds <= maxLegacySeconds {
+			v.SetInt(int64(legacySeconds(seconds)))
+			return nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setFromString(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
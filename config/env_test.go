@@ -0,0 +1,194 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeSecret(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadInterpolateEnv(t *testing.T) {
+	t.Setenv("WCA_TEST_PORT", "8190")
+	// 值中的 : # 与引号不能破坏 YAML
+	t.Setenv("WCA_TEST_KEY", `a: b # "c"`)
+	t.Setenv("WCA_TEST_HOST", "127.0.0.1")
+	path := writeConfig(t, `server:
+  port: ${WCA_TEST_PORT}
+network: ${WCA_TEST_UNSET_NETWORK:-mainnet}
+chains: [Ethereum]
+chain_configs:
+  Ethereum:
+    rpcs:
+      - rpc_url: 'http://${WCA_TEST_HOST}:8545'
+    data_api_key: ${WCA_TEST_KEY}
+    data_api_token: '$literal'
+`)
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	eth := conf.ChainConfigs["Ethereum"]
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"port", conf.Server.Port, "8190"},
+		{"default", conf.NetWork, "mainnet"},
+		{"embedded", eth.RPCs[0].RPCURL, "http://127.0.0.1:8545"},
+		{"special characters", eth.DataApiKey, `a: b # "c"`},
+		{"no reference", eth.DataApiToken, "$literal"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadInterpolateEnvUndefined(t *testing.T) {
+	path := writeConfig(t, `network: ${WCA_TEST_UNSET_NETWORK}
+chains: [Ethereum]
+`)
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "WCA_TEST_UNSET_NETWORK") {
+		t.Errorf("err = %v, want undefined WCA_TEST_UNSET_NETWORK", err)
+	}
+}
+
+func TestLoadFileRefs(t *testing.T) {
+	secret := writeSecret(t, "etherscan-key\n")
+	tests := []struct {
+		name    string
+		node    string
+		want    string
+		wantErr string
+	}{
+		{"file", "data_api_key_file: " + secret, "etherscan-key", ""},
+		{"both key and file", "data_api_key: inline\n    data_api_key_file: " + secret, "", "both data_api_key and data_api_key_file are set"},
+		{"missing file", "data_api_key_file: " + filepath.Join(t.TempDir(), "missing"), "", "chain_configs.Ethereum.data_api_key_file"},
+	}
+	for _, tt := range tests {
+		path := writeConfig(t, `network: mainnet
+chains: [Ethereum]
+chain_configs:
+  Ethereum:
+    rpcs:
+      - rpc_url: 'http://127.0.0.1:8545'
+    `+tt.node+"\n")
+		conf, err := Load(path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Load: %v", tt.name, err)
+			continue
+		}
+		if got := conf.ChainConfigs["Ethereum"].DataApiKey; got != tt.want {
+			t.Errorf("%s: data_api_key = %q, want %q", tt.name, got, tt.want)
+		}
+		// *_file 键已替换, 不报告为未知键
+		if len(conf.unknownKeys) != 0 {
+			t.Errorf("%s: unknown keys %v", tt.name, conf.unknownKeys)
+		}
+	}
+}
+
+func TestLoadEnvOverrides(t *testing.T) {
+	path := writeConfig(t, `server:
+  port: 8189
+network: mainnet
+chains: [Ethereum]
+wallet_node:
+  eth:
+    rpcs:
+      - rpc_url: 'http://127.0.0.1:8545'
+    data_api_key: from-file
+    time_out: 10s
+chain_configs:
+  Ethereum:
+    rpcs:
+      - rpc_url: 'http://127.0.0.1:8545'
+    params:
+      gas_limit: 21000
+`)
+	t.Setenv("WCA_SERVER_PORT", "9000")
+	t.Setenv("WCA_CHAINS", "Ethereum, Bitcoin")
+	t.Setenv("WCA_WALLET_NODE_ETH_RPCS_0_RPC_URL", "http://10.0.0.1:8545")
+	t.Setenv("WCA_WALLET_NODE_ETH_RPCS_1_RPC_URL", "http://10.0.0.2:8545")
+	t.Setenv("WCA_WALLET_NODE_ETH_TIME_OUT", "30")
+	t.Setenv("WCA_WALLET_NODE_ETH_CONFIRMATIONS", "12")
+	// NAME 优先于 NAME_FILE
+	t.Setenv("WCA_WALLET_NODE_ETH_DATA_API_KEY", "from-env")
+	t.Setenv("WCA_WALLET_NODE_ETH_DATA_API_KEY_FILE", writeSecret(t, "from-secret"))
+	t.Setenv("WCA_WALLET_NODE_ETH_DATA_API_TOKEN_FILE", writeSecret(t, "token\r\n"))
+	t.Setenv("WCA_CHAIN_CONFIGS_ETHEREUM_DATA_API_URL", "https://api.etherscan.io/api?")
+	t.Setenv("WCA_CHAIN_CONFIGS_ETHEREUM_PARAMS_GAS_LIMIT", "50000")
+	t.Setenv("WCA_CHAIN_CONFIGS_ETHEREUM_PARAMS_EIP1559", "true")
+
+	conf, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	eth := conf.WalletNode.Eth
+	chainConf := conf.ChainConfigs["Ethereum"]
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"server.port", conf.Server.Port, "9000"},
+		{"chains", strings.Join(conf.Chains, ","), "Ethereum,Bitcoin"},
+		{"rpcs[0]", eth.RPCs[0].RPCURL, "http://10.0.0.1:8545"},
+		{"rpcs[1] appended", len(eth.RPCs) == 2 && eth.RPCs[1].RPCURL == "http://10.0.0.2:8545", true},
+		{"time_out without unit", eth.TimeOut.Duration(), 30 * time.Second},
+		{"confirmations", eth.Confirmations, uint64(12)},
+		{"env before file", eth.DataApiKey, "from-env"},
+		{"file", eth.DataApiToken, "token"},
+		{"chain_configs existing key", chainConf.DataApiUrl, "https://api.etherscan.io/api?"},
+		{"params override", chainConf.Params["gas_limit"], 50000},
+		{"params new key", chainConf.Params["eip1559"], true},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v (%T), want %v (%T)", tt.name, tt.got, tt.got, tt.want, tt.want)
+		}
+	}
+}
+
+func TestLoadEnvOverrideInvalid(t *testing.T) {
+	path := writeConfig(t, `network: mainnet
+chains: [Ethereum]
+`)
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"WCA_WALLET_NODE_ETH_CONFIRMATIONS", "-1"},
+		{"WCA_WALLET_NODE_ETH_TIME_OUT", "ten"},
+		{"WCA_WALLET_NODE_TON_TESTNET", "maybe"},
+		{"WCA_WALLET_NODE_ETH_DATA_API_KEY_FILE", filepath.Join(t.TempDir(), "missing")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.name, tt.value)
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.name) {
+				t.Errorf("err = %v, want error naming %s", err, tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// yaml.v2 严格模式的未知键错误, 如 "line 12: field confirmation not found in type config.Node"
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type (\S+)$`)
+
+/**
+ * @description: 以严格模式再解析一次, 收集未知键与重复键, 带行号
+ * *_file 键在 resolveFileRefs 中替换, 不视为未知键
+ * @param data 原始配置内容, 行号与用户的文件一致
+ */
+func unknownKeys(data []byte) []string {
+	var typeErr *yaml.TypeError
+	if err := yaml.UnmarshalStrict(data, new(Config)); !errors.As(err, &typeErr) {
+		return nil
+	}
+	var keys []string
+	for _, msg := range typeErr.Errors {
+		match := unknownFieldPattern.FindStringSubmatch(msg)
+		if match == nil {
+			if strings.Contains(msg, "already set") {
+				keys = append(keys, msg)
+			}
+			continue
+		}
+		if strings.HasSuffix(match[2], FileSuffix) {
+			continue
+		}
+		keys = append(keys, fmt.Sprintf("line %s: unknown key %s (in %s)", match[1], match[2], match[3]))
+	}
+	return keys
+}
+
+/**
+ * @description: 启动与热加载前检查配置, 一次返回所有问题
+ * 包括未知键 (带行号), 端口范围, 启动模式, 重复的链, 每条启用链的 rpc 与链特有的必填项, 以及 webhook
+ */
+func (c *Config) Validate() error {
+	var errs []error
+	for _, key := range c.unknownKeys {
+		errs = append(errs, errors.New(key))
+	}
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port: %q is not a valid port (1-65535)", c.Server.Port))
+	}
+	if c.StartupMode != "" && c.StartupMode != StartupModeStrict && c.StartupMode != StartupModeDegraded {
+		errs = append(errs, fmt.Errorf("startup_mode: unknown mode %q, expect %s or %s", c.StartupMode, StartupModeStrict, StartupModeDegraded))
+	}
+	if len(c.Chains) == 0 {
+		errs = append(errs, errors.New("chains: at least one chain is required"))
+	}
+	seen := make(map[string]bool, len(c.Chains))
+	for _, name := range c.Chains {
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("chains: duplicate chain %s", name))
+			continue
+		}
+		seen[name] = true
+		errs = append(errs, c.validateChain(name)...)
+	}
+	errs = append(errs, c.Webhook.validate(c.Chains)...)
+	return errors.Join(errs...)
+}
+
+// validateChain 通用字段在此检查, params 等链特有的配置由适配器注册的 ChainValidator 检查
+func (c *Config) validateChain(chainName string) []error {
+	chainConf, path, ok := c.chainConfig(chainName)
+	if !ok {
+		if path == "" {
+			path = "chain_configs." + chainName
+		}
+		return []error{fmt.Errorf("%s: chain %s is enabled but not configured", path, chainName)}
+	}
+	if _, ok := c.ChainConfigs[chainName]; ok {
+		if _, legacyPath, legacy := c.legacyChainConfig(chainName); legacy {
+			return []error{fmt.Errorf("chain_configs.%s: chain is also configured in %s, remove one of them", chainName, legacyPath)}
+		}
+	}
+	errs := chainConf.Node.validate(path)
+	if validator, ok := chainValidator(chainName); ok {
+		if err := validator(chainConf); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	return errs
+}
+
+// rpc_url 支持的协议, tcp/ssl/tls 用于 Electrum
+var rpcSchemes = []string{"http", "https", "ws", "wss", "tcp", "ssl", "tls"}
+
+// validate 至少一个 rpc, rpc_url 与 data_api_url 需为带主机名的合法 URL
+func (n Node) validate(path string) []error {
+	var errs []error
+	if len(n.RPCs) == 0 {
+		errs = append(errs, fmt.Errorf("%s.rpcs: at least one rpc is required", path))
+	}
+	for i, rpc := range n.RPCs {
+		if rpc == nil {
+			errs = append(errs, fmt.Errorf("%s.rpcs[%d]: empty rpc", path, i))
+			continue
+		}
+		if err := validateUrl(rpc.RPCURL, rpcSchemes); err != nil {
+			errs = append(errs, fmt.Errorf("%s.rpcs[%d].rpc_url: %w", path, i, err))
+		}
+	}
+	if n.DataApiUrl != "" {
+		if err := validateUrl(n.DataApiUrl, []string{"http", "https"}); err != nil {
+			errs = append(errs, fmt.Errorf("%s.data_api_url: %w", path, err))
+		}
+	}
+	switch n.ConfirmationMode {
+	case "", ConfirmationModeDepth, ConfirmationModeSafe, ConfirmationModeFinalized:
+	default:
+		errs = append(errs, fmt.Errorf("%s.confirmation_mode: unknown mode %q, expect %s, %s or %s", path, n.ConfirmationMode,
+			ConfirmationModeDepth, ConfirmationModeSafe, ConfirmationModeFinalized))
+	}
+	if n.TimeOut < 0 {
+		errs = append(errs, fmt.Errorf("%s.time_out: must not be negative", path))
+	}
+	return errs
+}
+
+func validateUrl(rawUrl string, schemes []string) error {
+	if rawUrl == "" {
+		return errors.New("required")
+	}
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", rawUrl)
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q has unsupported scheme %q, expect one of %s", rawUrl, u.Scheme, strings.Join(schemes, ", "))
+}
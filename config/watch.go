@@ -0,0 +1,74 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// DefaultWatchInterval 轮询配置文件变更的间隔
+const DefaultWatchInterval = 5 * time.Second
+
+/**
+ * @description: 监听配置文件, 收到 SIGHUP 或文件内容变化时重新加载并回调 reload
+ * 文件通过轮询内容判断变化, 兼容编辑器原子替换 (rename) 与 k8s ConfigMap 的软链接切换
+ * 解析失败时保留当前配置, 仅记录日志; ctx 取消后返回
+ * @param ctx 上下文
+ * @param path 配置文件路径
+ * @param interval 轮询间隔, 为 0 时只响应 SIGHUP
+ * @param reload 回调, 在当前 goroutine 中串行调用
+ */
+func Watch(ctx context.Context, path string, interval time.Duration, reload func(conf *Config)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	last, err := os.ReadFile(path)
+	if err != nil {
+		log.Warn("read config for watch fail", "path", path, "err", err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Info("received SIGHUP, reloading config", "path", path)
+		case <-tick:
+			data, err := os.ReadFile(path)
+			if err != nil {
+				log.Warn("read config for watch fail", "path", path, "err", err)
+				continue
+			}
+			if bytes.Equal(data, last) {
+				continue
+			}
+			log.Info("config file changed, reloading config", "path", path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Error("reload config fail, keep current config", "path", path, "err", err)
+			continue
+		}
+		last = data
+		conf, err := Load(path)
+		if err == nil {
+			err = conf.Validate()
+		}
+		if err != nil {
+			log.Error("reload config fail, keep current config", "path", path, "err", err)
+			continue
+		}
+		reload(conf)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Webhook 已确认转账的回调通知, secret 为空时不启用
+// 回调请求体为 JSON, 签名为 HMAC-SHA256(secret, body), 投递失败按指数退避重试, 最终失败写入 dead_letter_path
+type Webhook struct {
+	// CallbackUrl 默认回调地址, watches 中未单独配置时使用
+	CallbackUrl string `yaml:"callback_url"`
+	Secret      string `yaml:"secret"`
+	// AdminToken 非空时启用 AddWebhookWatch/RemoveWebhookWatch, 请求的 consumer_token 需与之相同
+	AdminToken string `yaml:"admin_token"`
+	// MaxAttempts 单次通知最多投递次数, 0 表示默认值
+	MaxAttempts int `yaml:"max_attempts"`
+	// TimeOut 单次投递的超时, 0 表示默认值
+	TimeOut        Duration       `yaml:"time_out"`
+	DeadLetterPath string         `yaml:"dead_letter_path"`
+	Watches        []WebhookWatch `yaml:"watches"`
+}
+
+// WebhookWatch 监听的地址, contract_addresses 为空时通知所有 ERC-20 转账
+type WebhookWatch struct {
+	Chain             string   `yaml:"chain"`
+	Addresses         []string `yaml:"addresses"`
+	ContractAddresses []string `yaml:"contract_addresses"`
+	CallbackUrl       string   `yaml:"callback_url"`
+}
+
+// Enabled 是否配置了签名密钥
+func (w Webhook) Enabled() bool {
+	return w.Secret != ""
+}
+
+// WatchCallbackUrl 监听使用的回调地址
+func (w Webhook) WatchCallbackUrl(watch WebhookWatch) string {
+	if watch.CallbackUrl != "" {
+		return watch.CallbackUrl
+	}
+	return w.CallbackUrl
+}
+
+// validate 配置了回调地址或监听时必须配置 secret; 监听的链需已启用
+func (w Webhook) validate(chains []string) []error {
+	var errs []error
+	if !w.Enabled() {
+		if w.CallbackUrl != "" || len(w.Watches) > 0 {
+			errs = append(errs, errors.New("webhook.secret: required when callback_url or watches is set"))
+		}
+		return errs
+	}
+	if w.CallbackUrl != "" {
+		if err := validateUrl(w.CallbackUrl, []string{"http", "https"}); err != nil {
+			errs = append(errs, fmt.Errorf("webhook.callback_url: %w", err))
+		}
+	}
+	if w.MaxAttempts < 0 {
+		errs = append(errs, errors.New("webhook.max_attempts: must not be negative"))
+	}
+	if w.TimeOut < 0 {
+		errs = append(errs, errors.New("webhook.time_out: must not be negative"))
+	}
+	enabled := make(map[string]bool, len(chains))
+	for _, chainName := range chains {
+		enabled[chainName] = true
+	}
+	for i, watch := range w.Watches {
+		path := fmt.Sprintf("webhook.watches[%d]", i)
+		if !enabled[watch.Chain] {
+			errs = append(errs, fmt.Errorf("%s.chain: chain %q is not enabled", path, watch.Chain))
+		}
+		if len(watch.Addresses) == 0 {
+			errs = append(errs, fmt.Errorf("%s.addresses: at least one address is required", path))
+		}
+		if err := validateUrl(w.WatchCallbackUrl(watch), []string{"http", "https"}); err != nil {
+			errs = append(errs, fmt.Errorf("%s.callback_url: %w", path, err))
+		}
+	}
+	return errs
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"net"
 
@@ -8,6 +9,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	_ "github.com/CavnHan/wallet-chain-account/chain/builtin"
 	"github.com/CavnHan/wallet-chain-account/chaindispatcher"
 	"github.com/CavnHan/wallet-chain-account/config"
 	"github.com/CavnHan/wallet-chain-account/rpc/account"
@@ -23,6 +25,10 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	if err := conf.Validate(); err != nil {
+		log.Error("invalid config", "path", *f, "err", err)
+		panic(err)
+	}
 
 	//创建调度器
 	dispatcher, err := chaindispatcher.New(conf)
@@ -30,9 +36,17 @@ func main() {
 		log.Error("Setup dispatcher failed", "err", err)
 		panic(err)
 	}
+	defer dispatcher.Close()
+
+	//监听配置变更 (SIGHUP 或文件修改) 并热加载
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go config.Watch(ctx, *f, config.DefaultWatchInterval, func(conf *config.Config) {
+		_ = dispatcher.Reload(conf)
+	})
 
 	//创建grpc服务
-	server := grpc.NewServer(grpc.UnaryInterceptor(dispatcher.Interceptor))
+	server := grpc.NewServer(grpc.UnaryInterceptor(dispatcher.Interceptor), grpc.StreamInterceptor(dispatcher.StreamInterceptor))
 	defer server.GracefulStop()
 
 	//注册服务
@@ -37,6 +37,19 @@ const (
 	WalletAccountService_DecodeTransaction_FullMethodName       = "/proto.WalletAccountService/decodeTransaction"
 	WalletAccountService_VerifySignedTransaction_FullMethodName = "/proto.WalletAccountService/verifySignedTransaction"
 	WalletAccountService_GetExtraData_FullMethodName            = "/proto.WalletAccountService/getExtraData"
+	WalletAccountService_GetUnspentOutputs_FullMethodName       = "/proto.WalletAccountService/GetUnspentOutputs"
+	WalletAccountService_DetectReorg_FullMethodName             = "/proto.WalletAccountService/DetectReorg"
+	WalletAccountService_SubscribeBlocks_FullMethodName         = "/proto.WalletAccountService/SubscribeBlocks"
+	WalletAccountService_WatchAddresses_FullMethodName          = "/proto.WalletAccountService/WatchAddresses"
+	WalletAccountService_AddWebhookWatch_FullMethodName         = "/proto.WalletAccountService/AddWebhookWatch"
+	WalletAccountService_RemoveWebhookWatch_FullMethodName      = "/proto.WalletAccountService/RemoveWebhookWatch"
+	WalletAccountService_GetTransferLogs_FullMethodName         = "/proto.WalletAccountService/GetTransferLogs"
+	WalletAccountService_GetNfts_FullMethodName                 = "/proto.WalletAccountService/GetNfts"
+	WalletAccountService_GetNftMetadata_FullMethodName          = "/proto.WalletAccountService/GetNftMetadata"
+	WalletAccountService_BuildNftTransfer_FullMethodName        = "/proto.WalletAccountService/BuildNftTransfer"
+	WalletAccountService_GetBalances_FullMethodName             = "/proto.WalletAccountService/GetBalances"
+	WalletAccountService_ReserveNonce_FullMethodName            = "/proto.WalletAccountService/ReserveNonce"
+	WalletAccountService_ReleaseNonce_FullMethodName            = "/proto.WalletAccountService/ReleaseNonce"
 )
 
 // WalletAccountServiceClient is the client API for WalletAccountService service.
@@ -61,6 +74,19 @@ type WalletAccountServiceClient interface {
 	DecodeTransaction(ctx context.Context, in *DecodeTransactionRequest, opts ...grpc.CallOption) (*DecodeTransactionResponse, error)
 	VerifySignedTransaction(ctx context.Context, in *VerifyTransactionRequest, opts ...grpc.CallOption) (*VerifyTransactionResponse, error)
 	GetExtraData(ctx context.Context, in *ExtraDataRequest, opts ...grpc.CallOption) (*ExtraDataResponse, error)
+	GetUnspentOutputs(ctx context.Context, in *UnspentOutputsRequest, opts ...grpc.CallOption) (*UnspentOutputsResponse, error)
+	DetectReorg(ctx context.Context, in *ReorgRequest, opts ...grpc.CallOption) (*ReorgResponse, error)
+	SubscribeBlocks(ctx context.Context, in *SubscribeBlocksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BlockEvent], error)
+	WatchAddresses(ctx context.Context, in *WatchAddressesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TransferEvent], error)
+	AddWebhookWatch(ctx context.Context, in *WebhookWatchRequest, opts ...grpc.CallOption) (*WebhookWatchResponse, error)
+	RemoveWebhookWatch(ctx context.Context, in *RemoveWebhookWatchRequest, opts ...grpc.CallOption) (*WebhookWatchResponse, error)
+	GetTransferLogs(ctx context.Context, in *TransferLogsRequest, opts ...grpc.CallOption) (*TransferLogsResponse, error)
+	GetNfts(ctx context.Context, in *NftsRequest, opts ...grpc.CallOption) (*NftsResponse, error)
+	GetNftMetadata(ctx context.Context, in *NftMetadataRequest, opts ...grpc.CallOption) (*NftMetadataResponse, error)
+	BuildNftTransfer(ctx context.Context, in *NftTransferRequest, opts ...grpc.CallOption) (*NftTransferResponse, error)
+	GetBalances(ctx context.Context, in *BalancesRequest, opts ...grpc.CallOption) (*BalancesResponse, error)
+	ReserveNonce(ctx context.Context, in *ReserveNonceRequest, opts ...grpc.CallOption) (*ReserveNonceResponse, error)
+	ReleaseNonce(ctx context.Context, in *ReleaseNonceRequest, opts ...grpc.CallOption) (*ReleaseNonceResponse, error)
 }
 
 type walletAccountServiceClient struct {
@@ -251,6 +277,154 @@ func (c *walletAccountServiceClient) GetExtraData(ctx context.Context, in *Extra
 	return out, nil
 }
 
+func (c *walletAccountServiceClient) GetUnspentOutputs(ctx context.Context, in *UnspentOutputsRequest, opts ...grpc.CallOption) (*UnspentOutputsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnspentOutputsResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_GetUnspentOutputs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) DetectReorg(ctx context.Context, in *ReorgRequest, opts ...grpc.CallOption) (*ReorgResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReorgResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_DetectReorg_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) SubscribeBlocks(ctx context.Context, in *SubscribeBlocksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BlockEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WalletAccountService_ServiceDesc.Streams[0], WalletAccountService_SubscribeBlocks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeBlocksRequest, BlockEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WalletAccountService_SubscribeBlocksClient = grpc.ServerStreamingClient[BlockEvent]
+
+func (c *walletAccountServiceClient) WatchAddresses(ctx context.Context, in *WatchAddressesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TransferEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WalletAccountService_ServiceDesc.Streams[1], WalletAccountService_WatchAddresses_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchAddressesRequest, TransferEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WalletAccountService_WatchAddressesClient = grpc.ServerStreamingClient[TransferEvent]
+
+func (c *walletAccountServiceClient) AddWebhookWatch(ctx context.Context, in *WebhookWatchRequest, opts ...grpc.CallOption) (*WebhookWatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebhookWatchResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_AddWebhookWatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) RemoveWebhookWatch(ctx context.Context, in *RemoveWebhookWatchRequest, opts ...grpc.CallOption) (*WebhookWatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebhookWatchResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_RemoveWebhookWatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) GetTransferLogs(ctx context.Context, in *TransferLogsRequest, opts ...grpc.CallOption) (*TransferLogsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransferLogsResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_GetTransferLogs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) GetNfts(ctx context.Context, in *NftsRequest, opts ...grpc.CallOption) (*NftsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NftsResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_GetNfts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) GetNftMetadata(ctx context.Context, in *NftMetadataRequest, opts ...grpc.CallOption) (*NftMetadataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NftMetadataResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_GetNftMetadata_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) BuildNftTransfer(ctx context.Context, in *NftTransferRequest, opts ...grpc.CallOption) (*NftTransferResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NftTransferResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_BuildNftTransfer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) GetBalances(ctx context.Context, in *BalancesRequest, opts ...grpc.CallOption) (*BalancesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalancesResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_GetBalances_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) ReserveNonce(ctx context.Context, in *ReserveNonceRequest, opts ...grpc.CallOption) (*ReserveNonceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReserveNonceResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_ReserveNonce_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) ReleaseNonce(ctx context.Context, in *ReleaseNonceRequest, opts ...grpc.CallOption) (*ReleaseNonceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReleaseNonceResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_ReleaseNonce_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WalletAccountServiceServer is the server API for WalletAccountService service.
 // All implementations should embed UnimplementedWalletAccountServiceServer
 // for forward compatibility.
@@ -273,6 +447,19 @@ type WalletAccountServiceServer interface {
 	DecodeTransaction(context.Context, *DecodeTransactionRequest) (*DecodeTransactionResponse, error)
 	VerifySignedTransaction(context.Context, *VerifyTransactionRequest) (*VerifyTransactionResponse, error)
 	GetExtraData(context.Context, *ExtraDataRequest) (*ExtraDataResponse, error)
+	GetUnspentOutputs(context.Context, *UnspentOutputsRequest) (*UnspentOutputsResponse, error)
+	DetectReorg(context.Context, *ReorgRequest) (*ReorgResponse, error)
+	SubscribeBlocks(*SubscribeBlocksRequest, grpc.ServerStreamingServer[BlockEvent]) error
+	WatchAddresses(*WatchAddressesRequest, grpc.ServerStreamingServer[TransferEvent]) error
+	AddWebhookWatch(context.Context, *WebhookWatchRequest) (*WebhookWatchResponse, error)
+	RemoveWebhookWatch(context.Context, *RemoveWebhookWatchRequest) (*WebhookWatchResponse, error)
+	GetTransferLogs(context.Context, *TransferLogsRequest) (*TransferLogsResponse, error)
+	GetNfts(context.Context, *NftsRequest) (*NftsResponse, error)
+	GetNftMetadata(context.Context, *NftMetadataRequest) (*NftMetadataResponse, error)
+	BuildNftTransfer(context.Context, *NftTransferRequest) (*NftTransferResponse, error)
+	GetBalances(context.Context, *BalancesRequest) (*BalancesResponse, error)
+	ReserveNonce(context.Context, *ReserveNonceRequest) (*ReserveNonceResponse, error)
+	ReleaseNonce(context.Context, *ReleaseNonceRequest) (*ReleaseNonceResponse, error)
 }
 
 // UnimplementedWalletAccountServiceServer should be embedded to have
@@ -336,6 +523,45 @@ func (UnimplementedWalletAccountServiceServer) VerifySignedTransaction(context.C
 func (UnimplementedWalletAccountServiceServer) GetExtraData(context.Context, *ExtraDataRequest) (*ExtraDataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetExtraData not implemented")
 }
+func (UnimplementedWalletAccountServiceServer) GetUnspentOutputs(context.Context, *UnspentOutputsRequest) (*UnspentOutputsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUnspentOutputs not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) DetectReorg(context.Context, *ReorgRequest) (*ReorgResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DetectReorg not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) SubscribeBlocks(*SubscribeBlocksRequest, grpc.ServerStreamingServer[BlockEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeBlocks not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) WatchAddresses(*WatchAddressesRequest, grpc.ServerStreamingServer[TransferEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAddresses not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) AddWebhookWatch(context.Context, *WebhookWatchRequest) (*WebhookWatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddWebhookWatch not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) RemoveWebhookWatch(context.Context, *RemoveWebhookWatchRequest) (*WebhookWatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveWebhookWatch not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) GetTransferLogs(context.Context, *TransferLogsRequest) (*TransferLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransferLogs not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) GetNfts(context.Context, *NftsRequest) (*NftsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNfts not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) GetNftMetadata(context.Context, *NftMetadataRequest) (*NftMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNftMetadata not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) BuildNftTransfer(context.Context, *NftTransferRequest) (*NftTransferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuildNftTransfer not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) GetBalances(context.Context, *BalancesRequest) (*BalancesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalances not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) ReserveNonce(context.Context, *ReserveNonceRequest) (*ReserveNonceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveNonce not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) ReleaseNonce(context.Context, *ReleaseNonceRequest) (*ReleaseNonceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseNonce not implemented")
+}
 func (UnimplementedWalletAccountServiceServer) testEmbeddedByValue() {}
 
 // UnsafeWalletAccountServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -680,6 +906,226 @@ func _WalletAccountService_GetExtraData_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WalletAccountService_GetUnspentOutputs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnspentOutputsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).GetUnspentOutputs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_GetUnspentOutputs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).GetUnspentOutputs(ctx, req.(*UnspentOutputsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_DetectReorg_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorgRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).DetectReorg(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_DetectReorg_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).DetectReorg(ctx, req.(*ReorgRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_SubscribeBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletAccountServiceServer).SubscribeBlocks(m, &grpc.GenericServerStream[SubscribeBlocksRequest, BlockEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WalletAccountService_SubscribeBlocksServer = grpc.ServerStreamingServer[BlockEvent]
+
+func _WalletAccountService_WatchAddresses_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAddressesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletAccountServiceServer).WatchAddresses(m, &grpc.GenericServerStream[WatchAddressesRequest, TransferEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WalletAccountService_WatchAddressesServer = grpc.ServerStreamingServer[TransferEvent]
+
+func _WalletAccountService_AddWebhookWatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookWatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).AddWebhookWatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_AddWebhookWatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).AddWebhookWatch(ctx, req.(*WebhookWatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_RemoveWebhookWatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveWebhookWatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).RemoveWebhookWatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_RemoveWebhookWatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).RemoveWebhookWatch(ctx, req.(*RemoveWebhookWatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_GetTransferLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).GetTransferLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_GetTransferLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).GetTransferLogs(ctx, req.(*TransferLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_GetNfts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NftsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).GetNfts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_GetNfts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).GetNfts(ctx, req.(*NftsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_GetNftMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NftMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).GetNftMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_GetNftMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).GetNftMetadata(ctx, req.(*NftMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_BuildNftTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NftTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).BuildNftTransfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_BuildNftTransfer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).BuildNftTransfer(ctx, req.(*NftTransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_GetBalances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BalancesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).GetBalances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_GetBalances_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).GetBalances(ctx, req.(*BalancesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_ReserveNonce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReserveNonceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).ReserveNonce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_ReserveNonce_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).ReserveNonce(ctx, req.(*ReserveNonceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_ReleaseNonce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseNonceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).ReleaseNonce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_ReleaseNonce_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).ReleaseNonce(ctx, req.(*ReleaseNonceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // WalletAccountService_ServiceDesc is the grpc.ServiceDesc for WalletAccountService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -759,7 +1205,62 @@ var WalletAccountService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "getExtraData",
 			Handler:    _WalletAccountService_GetExtraData_Handler,
 		},
+		{
+			MethodName: "GetUnspentOutputs",
+			Handler:    _WalletAccountService_GetUnspentOutputs_Handler,
+		},
+		{
+			MethodName: "DetectReorg",
+			Handler:    _WalletAccountService_DetectReorg_Handler,
+		},
+		{
+			MethodName: "AddWebhookWatch",
+			Handler:    _WalletAccountService_AddWebhookWatch_Handler,
+		},
+		{
+			MethodName: "RemoveWebhookWatch",
+			Handler:    _WalletAccountService_RemoveWebhookWatch_Handler,
+		},
+		{
+			MethodName: "GetTransferLogs",
+			Handler:    _WalletAccountService_GetTransferLogs_Handler,
+		},
+		{
+			MethodName: "GetNfts",
+			Handler:    _WalletAccountService_GetNfts_Handler,
+		},
+		{
+			MethodName: "GetNftMetadata",
+			Handler:    _WalletAccountService_GetNftMetadata_Handler,
+		},
+		{
+			MethodName: "BuildNftTransfer",
+			Handler:    _WalletAccountService_BuildNftTransfer_Handler,
+		},
+		{
+			MethodName: "GetBalances",
+			Handler:    _WalletAccountService_GetBalances_Handler,
+		},
+		{
+			MethodName: "ReserveNonce",
+			Handler:    _WalletAccountService_ReserveNonce_Handler,
+		},
+		{
+			MethodName: "ReleaseNonce",
+			Handler:    _WalletAccountService_ReleaseNonce_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeBlocks",
+			Handler:       _WalletAccountService_SubscribeBlocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchAddresses",
+			Handler:       _WalletAccountService_WatchAddresses_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/account.proto",
 }
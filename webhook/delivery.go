@@ -0,0 +1,212 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/common/retry"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+)
+
+const (
+	// SignatureHeader 请求体的签名, 格式为 sha256=<hex(HMAC-SHA256(secret, body))>
+	SignatureHeader = "X-Wallet-Signature-256"
+	// DeliveryHeader 通知 id, 同一笔转账重试时不变, 接收方据此去重
+	DeliveryHeader = "X-Wallet-Delivery"
+
+	defaultMaxAttempts    = 8
+	defaultTimeout        = 10 * time.Second
+	defaultDeadLetterPath = "webhook_dead_letter.log"
+)
+
+// 投递失败后的重试间隔, 最长 10 分钟
+var deliveryRetryStrategy = &retry.ExponentialStrategy{
+	Min:       time.Second,
+	Max:       10 * time.Minute,
+	MaxJitter: time.Second,
+}
+
+// Payload 回调请求体
+type Payload struct {
+	// Id 通知 id, 由链, 交易哈希, 合约地址与日志序号组成
+	Id              string `json:"id"`
+	WatchId         string `json:"watch_id"`
+	Chain           string `json:"chain"`
+	Network         string `json:"network"`
+	TxHash          string `json:"tx_hash"`
+	LogIndex        uint32 `json:"log_index"`
+	Height          uint64 `json:"height"`
+	BlockHash       string `json:"block_hash"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Value           string `json:"value"`
+	ContractAddress string `json:"contract_address"`
+	// Direction 相对监听地址的方向: in, out, self
+	Direction     string `json:"direction"`
+	Confirmations uint64 `json:"confirmations"`
+	Timestamp     int64  `json:"timestamp"`
+}
+
+func newPayload(watchId, chainName, network string, event *account.TransferEvent) *Payload {
+	return &Payload{
+		Id:              transferId(chainName, event),
+		WatchId:         watchId,
+		Chain:           chainName,
+		Network:         network,
+		TxHash:          event.TxHash,
+		LogIndex:        event.LogIndex,
+		Height:          event.Height,
+		BlockHash:       event.BlockHash,
+		From:            event.From,
+		To:              event.To,
+		Value:           event.Value,
+		ContractAddress: event.ContractAddress,
+		Direction:       strings.ToLower(strings.TrimPrefix(event.Direction.String(), "Direction")),
+		Confirmations:   event.Confirmations,
+		Timestamp:       time.Now().Unix(),
+	}
+}
+
+// transferId 同一交易中原生币转账与代币转账的日志序号可能相同, 需带上合约地址区分
+func transferId(chainName string, event *account.TransferEvent) string {
+	return fmt.Sprintf("%s:%s:%s:%d", chainName, event.TxHash, event.ContractAddress, event.LogIndex)
+}
+
+// Sign 请求体的签名, 接收方用同一 secret 计算后与 SignatureHeader 比较 (使用 hmac.Equal)
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// delivery 一次待投递的通知
+type delivery struct {
+	url     string
+	payload *Payload
+	//已投递的次数
+	attempts int
+}
+
+// deadLetter 投递最终失败的通知, 按行写入 JSON
+type deadLetter struct {
+	Url      string   `json:"url"`
+	Payload  *Payload `json:"payload"`
+	Attempts int      `json:"attempts"`
+	Error    string   `json:"error"`
+	Time     string   `json:"time"`
+}
+
+type sender struct {
+	client         *http.Client
+	secret         string
+	maxAttempts    int
+	retryStrategy  retry.Strategy
+	deadLetterPath string
+	//串行写死信日志
+	lock sync.Mutex
+}
+
+func newSender(secret string, maxAttempts int, timeout time.Duration, deadLetterPath string) *sender {
+	if maxAttempts == 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	if deadLetterPath == "" {
+		deadLetterPath = defaultDeadLetterPath
+	}
+	return &sender{
+		client:         &http.Client{Timeout: timeout},
+		secret:         secret,
+		maxAttempts:    maxAttempts,
+		retryStrategy:  deliveryRetryStrategy,
+		deadLetterPath: deadLetterPath,
+	}
+}
+
+/**
+ * @description: 投递一次通知, 非 2xx 响应与网络错误返回下次重试前的等待时间, 由调用方按时重新入队,
+ * 等待期间不占用投递协程; 达到最大次数或 ctx 已取消时写入死信日志
+ * @param ctx 通知器的上下文, 取消后不再重试
+ * @param d 通知
+ * @return wait 下次重试前的等待时间
+ * @return again 是否需要重试
+ */
+func (s *sender) deliver(ctx context.Context, d *delivery) (wait time.Duration, again bool) {
+	body, err := json.Marshal(d.payload)
+	if err != nil {
+		s.deadLetter(d, err)
+		return 0, false
+	}
+	d.attempts++
+	if err := s.post(ctx, d, body); err != nil {
+		log.Warn("webhook delivery fail", "id", d.payload.Id, "url", d.url, "attempt", d.attempts, "err", err)
+		if ctx.Err() != nil || d.attempts >= s.maxAttempts {
+			s.deadLetter(d, err)
+			return 0, false
+		}
+		return s.retryStrategy.Duration(d.attempts - 1), true
+	}
+	log.Info("webhook delivered", "id", d.payload.Id, "url", d.url, "attempts", d.attempts)
+	return 0, false
+}
+
+func (s *sender) post(ctx context.Context, d *delivery, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(s.secret, body))
+	req.Header.Set(DeliveryHeader, d.payload.Id)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *sender) deadLetter(d *delivery, cause error) {
+	log.Error("webhook delivery failed permanently", "id", d.payload.Id, "url", d.url, "attempts", d.attempts, "err", cause)
+	line, err := json.Marshal(&deadLetter{
+		Url:      d.url,
+		Payload:  d.payload,
+		Attempts: d.attempts,
+		Error:    cause.Error(),
+		Time:     time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		log.Error("marshal dead letter fail", "id", d.payload.Id, "err", err)
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	file, err := os.OpenFile(s.deadLetterPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		log.Error("open dead letter log fail", "path", s.deadLetterPath, "err", err)
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		log.Error("write dead letter log fail", "path", s.deadLetterPath, "err", err)
+	}
+}
@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/config"
+)
+
+const testSecret = "test-secret"
+
+// recordStrategy 记录每次重试的序号, 等待固定时间
+type recordStrategy struct {
+	lock     sync.Mutex
+	wait     time.Duration
+	attempts []int
+}
+
+func (r *recordStrategy) Duration(attempt int) time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.attempts = append(r.attempts, attempt)
+	return r.wait
+}
+
+func (r *recordStrategy) recorded() []int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]int(nil), r.attempts...)
+}
+
+// newTestNotifier 不含监听的通知器, 投递使用 strategy 重试
+func newTestNotifier(t *testing.T, maxAttempts int, strategy *recordStrategy) (*Notifier, string) {
+	t.Helper()
+	deadLetterPath := filepath.Join(t.TempDir(), "dead_letter.log")
+	conf := &config.Config{
+		NetWork: "mainnet",
+		Webhook: config.Webhook{
+			Secret:         testSecret,
+			MaxAttempts:    maxAttempts,
+			TimeOut:        config.Duration(time.Second),
+			DeadLetterPath: deadLetterPath,
+		},
+	}
+	n := New(conf, func(string) chain.IChainAdaptor { return nil })
+	t.Cleanup(n.Close)
+	n.lock.Lock()
+	n.sender.retryStrategy = strategy
+	n.lock.Unlock()
+	return n, deadLetterPath
+}
+
+func send(n *Notifier, url, id string) {
+	n.enqueue(n.ctx, &delivery{url: url, payload: &Payload{Id: id, Chain: "Ethereum", Value: "1"}})
+}
+
+func readDeadLetters(t *testing.T, path string) []deadLetter {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		content, err := os.ReadFile(path)
+		if err == nil && len(content) > 0 {
+			var letters []deadLetter
+			for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+				var letter deadLetter
+				if err := json.Unmarshal([]byte(line), &letter); err != nil {
+					t.Fatalf("decode dead letter %q: %v", line, err)
+				}
+				letters = append(letters, letter)
+			}
+			return letters
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no dead letter written to %s", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// RFC 4231 test case 2
+	got := Sign("Jefe", []byte("what do ya want for nothing?"))
+	want := "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestDeliverSignatureHeader(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	n, _ := newTestNotifier(t, 1, &recordStrategy{})
+	send(n, srv.URL, "Ethereum:0xabc::0")
+
+	select {
+	case r := <-received:
+		body := <-bodies
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if got := r.Header.Get(SignatureHeader); got != Sign(testSecret, body) {
+			t.Errorf("%s = %q, want %q", SignatureHeader, got, Sign(testSecret, body))
+		}
+		if got := r.Header.Get(DeliveryHeader); got != "Ethereum:0xabc::0" {
+			t.Errorf("%s = %q", DeliveryHeader, got)
+		}
+		var payload Payload
+		if err := json.Unmarshal(body, &payload); err != nil || payload.Id != "Ethereum:0xabc::0" {
+			t.Errorf("payload = %s, err %v", body, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+}
+
+func TestDeliverRetrySchedule(t *testing.T) {
+	var calls int32
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		close(done)
+	}))
+	defer srv.Close()
+
+	strategy := &recordStrategy{wait: 10 * time.Millisecond}
+	n, deadLetterPath := newTestNotifier(t, 5, strategy)
+	send(n, srv.URL, "retry")
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered after retries")
+	}
+	if got := strategy.recorded(); len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Errorf("retry attempts = %v, want [0 1]", got)
+	}
+	if _, err := os.Stat(deadLetterPath); !os.IsNotExist(err) {
+		t.Errorf("dead letter log written for a delivered webhook")
+	}
+}
+
+func TestDeliverDeadLetter(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	strategy := &recordStrategy{wait: 10 * time.Millisecond}
+	n, deadLetterPath := newTestNotifier(t, 3, strategy)
+	send(n, srv.URL, "dead")
+
+	letters := readDeadLetters(t, deadLetterPath)
+	if len(letters) != 1 {
+		t.Fatalf("dead letters = %+v, want one", letters)
+	}
+	letter := letters[0]
+	if letter.Url != srv.URL || letter.Payload.Id != "dead" || letter.Attempts != 3 {
+		t.Errorf("dead letter = %+v", letter)
+	}
+	if !strings.Contains(letter.Error, "500") {
+		t.Errorf("dead letter error = %q, want the last status", letter.Error)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("delivery attempts = %d, want 3", got)
+	}
+	if got := strategy.recorded(); len(got) != 2 {
+		t.Errorf("retry attempts = %v, want 2 waits", got)
+	}
+}
+
+func TestCloseStopsRetryWait(t *testing.T) {
+	failed := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		failed <- struct{}{}
+	}))
+	defer srv.Close()
+
+	n, deadLetterPath := newTestNotifier(t, 8, &recordStrategy{wait: time.Hour})
+	send(n, srv.URL, "closed")
+	select {
+	case <-failed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not attempted")
+	}
+	n.Close()
+
+	letters := readDeadLetters(t, deadLetterPath)
+	if len(letters) != 1 || letters[0].Payload.Id != "closed" || letters[0].Attempts != 1 {
+		t.Errorf("dead letters = %+v, want the pending delivery after one attempt", letters)
+	}
+}
+
+func TestDeadURLDoesNotBlockWorkers(t *testing.T) {
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer dead.Close()
+	done := make(chan struct{})
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(done)
+	}))
+	defer live.Close()
+
+	n, _ := newTestNotifier(t, 8, &recordStrategy{wait: time.Hour})
+	for i := 0; i < deliveryWorkers*2; i++ {
+		send(n, dead.URL, "dead")
+	}
+	send(n, live.URL, "live")
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery to a live url blocked behind retries to a dead url")
+	}
+}
@@ -0,0 +1,386 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/common/retry"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const (
+	// 同时投递的通知数
+	deliveryWorkers = 4
+	// 已通知的转账保留的区块数, 用于重新订阅后去重
+	deliveredDepth = 1024
+)
+
+// 链不可用或订阅中断后重新订阅的退避策略
+var resubscribeStrategy = &retry.ExponentialStrategy{
+	Min:       time.Second,
+	Max:       time.Minute,
+	MaxJitter: time.Second,
+}
+
+var (
+	ErrDisabled     = errors.New("webhook is not configured")
+	ErrUnauthorized = errors.New("invalid admin token")
+)
+
+// AdaptorLookup 按链名返回当前的链适配器, 链不可用时为 nil; 热加载替换适配器后订阅会中断并重新订阅新的适配器
+type AdaptorLookup func(chainName string) chain.IChainAdaptor
+
+/**
+ * @description: 已确认转账的回调通知器
+ * 每个监听通过链适配器的 WatchAddresses 订阅转账, 达到确认条件时投递到回调地址
+ * 监听来自配置 (webhook.watches) 或管理接口, 管理接口添加的监听只保存在内存中
+ */
+type Notifier struct {
+	lookup AdaptorLookup
+	queue  chan *delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+	//重新订阅的退避策略
+	resubscribe retry.Strategy
+
+	lock    sync.Mutex
+	conf    config.Webhook
+	network string
+	sender  *sender
+	watches map[string]*watch
+	nextId  uint64
+}
+
+type watch struct {
+	id         string
+	conf       config.WebhookWatch
+	fromConfig bool
+	cancel     context.CancelFunc
+	state      *watchState
+	//监听协程退出时关闭, 之后才能把 state 交给重启后的监听
+	done chan struct{}
+}
+
+// watchState 监听的订阅进度, 配置热加载重启监听时由新的监听接着使用
+type watchState struct {
+	from     uint64
+	lastHash string
+	//未确认的转账 id -> 高度
+	pending map[string]uint64
+	//已通知的转账 id -> 高度, 重新订阅后去重
+	delivered map[string]uint64
+}
+
+func newWatchState() *watchState {
+	return &watchState{
+		pending:   make(map[string]uint64),
+		delivered: make(map[string]uint64),
+	}
+}
+
+// rewind 未确认的转账需重新扫描其所在区块, 重新订阅前调用
+func (s *watchState) rewind() {
+	for _, height := range s.pending {
+		if s.from == 0 || height < s.from {
+			s.from, s.lastHash = height, ""
+		}
+	}
+	s.pending = make(map[string]uint64)
+}
+
+func New(conf *config.Config, lookup AdaptorLookup) *Notifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Notifier{
+		lookup:      lookup,
+		queue:       make(chan *delivery, 1024),
+		ctx:         ctx,
+		cancel:      cancel,
+		resubscribe: resubscribeStrategy,
+		watches:     make(map[string]*watch),
+	}
+	for i := 0; i < deliveryWorkers; i++ {
+		go n.work()
+	}
+	n.Update(conf)
+	return n
+}
+
+/**
+ * @description: 应用 webhook 配置, 启动与热加载时调用
+ * 配置变化时重启配置中的监听, 链与地址未变的监听从上次通知的进度继续; 关闭 webhook (secret 为空) 时同时停止管理接口添加的监听
+ * 投递时使用最新的 secret, 超时与死信日志路径, 包括管理接口添加的监听与等待重试的通知
+ * @param conf 配置
+ */
+func (n *Notifier) Update(conf *config.Config) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.sender != nil && reflect.DeepEqual(n.conf, conf.Webhook) && n.network == conf.NetWork {
+		return
+	}
+	//切换网络后原有的进度不再适用
+	sameNetwork := n.network == conf.NetWork
+	n.conf, n.network = conf.Webhook, conf.NetWork
+	n.sender = newSender(conf.Webhook.Secret, conf.Webhook.MaxAttempts, conf.Webhook.TimeOut.Duration(), conf.Webhook.DeadLetterPath)
+	var stopped []*watch
+	for id, w := range n.watches {
+		if w.fromConfig || !n.conf.Enabled() {
+			w.cancel()
+			delete(n.watches, id)
+			if w.fromConfig && sameNetwork {
+				stopped = append(stopped, w)
+			}
+		}
+	}
+	if !n.conf.Enabled() {
+		return
+	}
+	for i, watchConf := range n.conf.Watches {
+		var prev *watch
+		for j, w := range stopped {
+			if sameSubscription(w.conf, watchConf) {
+				prev = w
+				stopped = append(stopped[:j], stopped[j+1:]...)
+				break
+			}
+		}
+		n.start(fmt.Sprintf("config-%d", i), watchConf, true, prev)
+	}
+	log.Info("webhook config applied", "watches", len(n.watches))
+}
+
+// Authorize 校验管理接口的 token, 未配置 admin_token 时管理接口不可用
+func (n *Notifier) Authorize(token string) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if !n.conf.Enabled() || n.conf.AdminToken == "" {
+		return ErrDisabled
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(n.conf.AdminToken)) != 1 {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+/**
+ * @description: 添加监听, 返回监听 id
+ * @param watchConf 监听的链与地址, callback_url 为空时使用 webhook.callback_url
+ */
+func (n *Notifier) Add(watchConf config.WebhookWatch) (string, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if !n.conf.Enabled() {
+		return "", ErrDisabled
+	}
+	if len(watchConf.Addresses) == 0 {
+		return "", errors.New("addresses is required")
+	}
+	if n.conf.WatchCallbackUrl(watchConf) == "" {
+		return "", errors.New("callback_url is required")
+	}
+	if n.lookup(watchConf.Chain) == nil {
+		return "", fmt.Errorf("chain %s is not available", watchConf.Chain)
+	}
+	n.nextId++
+	id := fmt.Sprintf("watch-%d", n.nextId)
+	n.start(id, watchConf, false, nil)
+	return id, nil
+}
+
+// Remove 移除管理接口添加的监听, 配置中的监听需修改配置
+func (n *Notifier) Remove(id string) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	w, ok := n.watches[id]
+	if !ok {
+		return fmt.Errorf("watch %s not found", id)
+	}
+	if w.fromConfig {
+		return fmt.Errorf("watch %s is defined in config", id)
+	}
+	w.cancel()
+	delete(n.watches, id)
+	return nil
+}
+
+// Close 停止所有监听与投递, 未完成的投递写入死信日志
+func (n *Notifier) Close() {
+	n.cancel()
+}
+
+// sameSubscription 链与监听的地址相同, 回调地址不影响订阅进度
+func sameSubscription(a, b config.WebhookWatch) bool {
+	return a.Chain == b.Chain && reflect.DeepEqual(a.Addresses, b.Addresses) && reflect.DeepEqual(a.ContractAddresses, b.ContractAddresses)
+}
+
+/**
+ * @description: 启动监听, 调用方需持有锁
+ * @param prev 被重启的监听, 不为 nil 时等其退出后接着它的进度订阅
+ */
+func (n *Notifier) start(id string, watchConf config.WebhookWatch, fromConfig bool, prev *watch) {
+	ctx, cancel := context.WithCancel(n.ctx)
+	w := &watch{
+		id:         id,
+		conf:       watchConf,
+		fromConfig: fromConfig,
+		cancel:     cancel,
+		state:      newWatchState(),
+		done:       make(chan struct{}),
+	}
+	var after <-chan struct{}
+	if prev != nil {
+		w.state, after = prev.state, prev.done
+	}
+	n.watches[id] = w
+	go n.run(ctx, w, after, n.network)
+}
+
+// callbackUrl 监听当前的回调地址, 未单独配置时使用最新的 webhook.callback_url
+func (n *Notifier) callbackUrl(watchConf config.WebhookWatch) string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.conf.WatchCallbackUrl(watchConf)
+}
+
+// currentSender 最新配置对应的 sender
+func (n *Notifier) currentSender() *sender {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.sender
+}
+
+/**
+ * @description: 订阅监听地址的转账, 中断后从检查点 (或最低的未确认转账) 重新订阅
+ * @param ctx 监听的上下文, 被移除或配置变化时取消
+ * @param w 监听
+ * @param after 被重启的监听退出时关闭, 之前不能使用 w.state; 为 nil 时无需等待
+ * @param network 网络
+ */
+func (n *Notifier) run(ctx context.Context, w *watch, after <-chan struct{}, network string) {
+	defer close(w.done)
+	if after != nil {
+		select {
+		case <-after:
+		case <-ctx.Done():
+			return
+		}
+	}
+	state := w.state
+	var failures int
+	onEvent := func(event *account.TransferEvent) error {
+		if event.Code != common.ReturnCode_SUCCESS {
+			log.Warn("webhook watch interrupted", "watch", w.id, "chain", w.conf.Chain, "msg", event.Msg)
+			return nil
+		}
+		failures = 0
+		//检查点
+		if event.TxHash == "" {
+			state.from, state.lastHash = event.Height+1, event.BlockHash
+			for id, height := range state.delivered {
+				if height+deliveredDepth < event.Height {
+					delete(state.delivered, id)
+				}
+			}
+			return nil
+		}
+		id := transferId(w.conf.Chain, event)
+		switch {
+		case event.Removed:
+			delete(state.pending, id)
+		case event.Status == account.TxStatus_Pending:
+			state.pending[id] = event.Height
+		case event.Status == account.TxStatus_Success:
+			delete(state.pending, id)
+			if _, ok := state.delivered[id]; ok {
+				return nil
+			}
+			state.delivered[id] = event.Height
+			n.enqueue(ctx, &delivery{url: n.callbackUrl(w.conf), payload: newPayload(w.id, w.conf.Chain, network, event)})
+		}
+		return nil
+	}
+
+	for {
+		state.rewind()
+		adaptor := n.lookup(w.conf.Chain)
+		if adaptor == nil {
+			log.Warn("webhook watch chain unavailable", "watch", w.id, "chain", w.conf.Chain)
+		} else if watcher, ok := adaptor.(chain.IAddressWatcher); !ok {
+			log.Error("chain does not support address watching, webhook watch stopped", "watch", w.id, "chain", w.conf.Chain)
+			return
+		} else {
+			err := watcher.WatchAddresses(ctx, &account.WatchAddressesRequest{
+				Chain:             w.conf.Chain,
+				Network:           network,
+				Addresses:         w.conf.Addresses,
+				ContractAddresses: w.conf.ContractAddresses,
+				FromHeight:        state.from,
+				LastHash:          state.lastHash,
+				Checkpoints:       true,
+			}, onEvent)
+			if err != nil {
+				log.Warn("webhook watch fail", "watch", w.id, "chain", w.conf.Chain, "err", err)
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(n.resubscribe.Duration(failures)):
+		}
+		failures++
+	}
+}
+
+func (n *Notifier) work() {
+	for {
+		select {
+		case <-n.ctx.Done():
+			for {
+				select {
+				case d := <-n.queue:
+					n.currentSender().deadLetter(d, n.ctx.Err())
+				default:
+					return
+				}
+			}
+		case d := <-n.queue:
+			if wait, again := n.currentSender().deliver(n.ctx, d); again {
+				go n.retryLater(d, wait)
+			}
+		}
+	}
+}
+
+// retryLater 等待后重新入队, 等待期间 Close 时写入死信日志
+func (n *Notifier) retryLater(d *delivery, wait time.Duration) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-n.ctx.Done():
+		n.currentSender().deadLetter(d, n.ctx.Err())
+	case <-timer.C:
+		n.enqueue(n.ctx, d)
+	}
+}
+
+// enqueue 队列满时阻塞, ctx 取消时写入死信日志
+func (n *Notifier) enqueue(ctx context.Context, d *delivery) {
+	select {
+	case n.queue <- d:
+	case <-ctx.Done():
+		n.currentSender().deadLetter(d, ctx.Err())
+	}
+}
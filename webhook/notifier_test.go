@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CavnHan/wallet-chain-account/chain"
+	"github.com/CavnHan/wallet-chain-account/common/retry"
+	"github.com/CavnHan/wallet-chain-account/config"
+	"github.com/CavnHan/wallet-chain-account/rpc/account"
+	"github.com/CavnHan/wallet-chain-account/rpc/common"
+)
+
+const testAddress = "0x00000000000000000000000000000000000000aa"
+
+// fakeWatcher 每次订阅先上报请求, 再推送 streams 中的事件, 直到断线或 ctx 结束
+type fakeWatcher struct {
+	chain.IChainAdaptor
+	requests chan *account.WatchAddressesRequest
+	streams  chan []*account.TransferEvent
+}
+
+func newFakeWatcher() *fakeWatcher {
+	return &fakeWatcher{
+		requests: make(chan *account.WatchAddressesRequest, 16),
+		streams:  make(chan []*account.TransferEvent),
+	}
+}
+
+func (f *fakeWatcher) WatchAddresses(ctx context.Context, req *account.WatchAddressesRequest, send func(*account.TransferEvent) error) error {
+	f.requests <- req
+	for {
+		select {
+		case events := <-f.streams:
+			//nil 表示断线
+			if events == nil {
+				return nil
+			}
+			for _, event := range events {
+				if err := send(event); err != nil {
+					return err
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// nextRequest 等待下一次订阅
+func (f *fakeWatcher) nextRequest(t *testing.T) *account.WatchAddressesRequest {
+	t.Helper()
+	select {
+	case req := <-f.requests:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch not subscribed")
+		return nil
+	}
+}
+
+// push 推送一组事件
+func (f *fakeWatcher) push(t *testing.T, events ...*account.TransferEvent) {
+	t.Helper()
+	f.stream(t, append([]*account.TransferEvent{}, events...))
+}
+
+// drop 中断当前订阅
+func (f *fakeWatcher) drop(t *testing.T) {
+	t.Helper()
+	f.stream(t, nil)
+}
+
+func (f *fakeWatcher) stream(t *testing.T, events []*account.TransferEvent) {
+	t.Helper()
+	select {
+	case f.streams <- events:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch not subscribed")
+	}
+}
+
+func transfer(txHash string, height uint64, status account.TxStatus) *account.TransferEvent {
+	return &account.TransferEvent{Code: common.ReturnCode_SUCCESS, TxHash: txHash, Height: height, Status: status, To: testAddress}
+}
+
+func checkpoint(height uint64, hash string) *account.TransferEvent {
+	return &account.TransferEvent{Code: common.ReturnCode_SUCCESS, Msg: "checkpoint", Height: height, BlockHash: hash}
+}
+
+// received 回调收到的请求
+type received struct {
+	signature string
+	body      []byte
+	payload   Payload
+}
+
+func newReceiver(t *testing.T) (*httptest.Server, chan *received) {
+	ch := make(chan *received, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec := &received{signature: r.Header.Get(SignatureHeader), body: body}
+		if err := json.Unmarshal(body, &rec.payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		ch <- rec
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ch
+}
+
+func nextDelivery(t *testing.T, ch chan *received) *received {
+	t.Helper()
+	select {
+	case rec := <-ch:
+		return rec
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+		return nil
+	}
+}
+
+func expectNoDelivery(t *testing.T, ch chan *received) {
+	t.Helper()
+	select {
+	case rec := <-ch:
+		t.Errorf("unexpected delivery %s", rec.payload.Id)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// newWatchNotifier 监听 Ethereum 上 testAddress 的通知器, 重新订阅不等待
+func newWatchNotifier(t *testing.T, url string, watcher *fakeWatcher, watches ...config.WebhookWatch) (*Notifier, *config.Config) {
+	t.Helper()
+	conf := &config.Config{
+		NetWork: "mainnet",
+		Webhook: config.Webhook{
+			CallbackUrl:    url,
+			Secret:         testSecret,
+			MaxAttempts:    1,
+			DeadLetterPath: filepath.Join(t.TempDir(), "dead_letter.log"),
+		},
+	}
+	n := New(conf, func(chainName string) chain.IChainAdaptor {
+		if chainName != "Ethereum" {
+			return nil
+		}
+		return watcher
+	})
+	t.Cleanup(n.Close)
+	//启动监听前替换退避策略
+	n.resubscribe = &retry.ExponentialStrategy{Min: time.Millisecond, Max: time.Millisecond}
+	withWatches := *conf
+	withWatches.Webhook.Watches = watches
+	n.Update(&withWatches)
+	return n, &withWatches
+}
+
+// 重新订阅后再次推送的已通知转账不重复投递, 未确认的转账从其所在区块重新扫描
+func TestRunDedup(t *testing.T) {
+	srv, deliveries := newReceiver(t)
+	watcher := newFakeWatcher()
+	newWatchNotifier(t, srv.URL, watcher, config.WebhookWatch{Chain: "Ethereum", Addresses: []string{testAddress}})
+
+	if req := watcher.nextRequest(t); req.FromHeight != 0 || !req.Checkpoints {
+		t.Errorf("first subscription from %d, checkpoints %v", req.FromHeight, req.Checkpoints)
+	}
+	watcher.push(t,
+		transfer("0x01", 10, account.TxStatus_Success),
+		transfer("0x02", 11, account.TxStatus_Pending),
+		checkpoint(12, "0xc12"),
+	)
+	if rec := nextDelivery(t, deliveries); rec.payload.TxHash != "0x01" {
+		t.Errorf("delivered %s, want 0x01", rec.payload.TxHash)
+	}
+	watcher.drop(t)
+
+	if req := watcher.nextRequest(t); req.FromHeight != 11 || req.LastHash != "" {
+		t.Errorf("resubscribed from %d (%q), want the pending transfer at 11", req.FromHeight, req.LastHash)
+	}
+	watcher.push(t,
+		transfer("0x01", 10, account.TxStatus_Success),
+		transfer("0x02", 11, account.TxStatus_Success),
+		checkpoint(13, "0xc13"),
+	)
+	if rec := nextDelivery(t, deliveries); rec.payload.TxHash != "0x02" {
+		t.Errorf("delivered %s, want 0x02", rec.payload.TxHash)
+	}
+	expectNoDelivery(t, deliveries)
+	watcher.drop(t)
+
+	if req := watcher.nextRequest(t); req.FromHeight != 14 || req.LastHash != "0xc13" {
+		t.Errorf("resubscribed from %d (%q), want 14 after checkpoint 0xc13", req.FromHeight, req.LastHash)
+	}
+}
+
+// 热加载重启配置中的监听时从上次通知的进度继续, 已通知的转账不重复投递
+func TestRunResumeAfterReload(t *testing.T) {
+	srv, deliveries := newReceiver(t)
+	watcher := newFakeWatcher()
+	watchConf := config.WebhookWatch{Chain: "Ethereum", Addresses: []string{testAddress}}
+	n, conf := newWatchNotifier(t, srv.URL, watcher, watchConf)
+
+	watcher.nextRequest(t)
+	watcher.push(t,
+		transfer("0x01", 20, account.TxStatus_Success),
+		checkpoint(20, "0xc20"),
+		transfer("0x02", 21, account.TxStatus_Pending),
+		checkpoint(21, "0xc21"),
+	)
+	nextDelivery(t, deliveries)
+
+	reloaded := *conf
+	reloaded.Webhook.MaxAttempts = 2
+	n.Update(&reloaded)
+	if req := watcher.nextRequest(t); req.FromHeight != 21 || req.LastHash != "" {
+		t.Errorf("reloaded watch subscribed from %d (%q), want the pending transfer at 21", req.FromHeight, req.LastHash)
+	}
+	watcher.push(t,
+		transfer("0x01", 20, account.TxStatus_Success),
+		transfer("0x02", 21, account.TxStatus_Success),
+	)
+	if rec := nextDelivery(t, deliveries); rec.payload.TxHash != "0x02" {
+		t.Errorf("delivered %s, want 0x02", rec.payload.TxHash)
+	}
+	expectNoDelivery(t, deliveries)
+
+	// 监听的地址变化后从最新区块开始
+	changed := reloaded
+	changed.Webhook.Watches = []config.WebhookWatch{{Chain: "Ethereum", Addresses: []string{"0x00000000000000000000000000000000000000bb"}}}
+	n.Update(&changed)
+	if req := watcher.nextRequest(t); req.FromHeight != 0 {
+		t.Errorf("changed watch subscribed from %d, want latest", req.FromHeight)
+	}
+}
+
+// 热加载更换 secret 后, 管理接口添加的监听也使用新的 secret 签名
+func TestRunNewSecret(t *testing.T) {
+	srv, deliveries := newReceiver(t)
+	watcher := newFakeWatcher()
+	n, conf := newWatchNotifier(t, srv.URL, watcher)
+	if _, err := n.Add(config.WebhookWatch{Chain: "Ethereum", Addresses: []string{testAddress}}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	watcher.nextRequest(t)
+
+	reloaded := *conf
+	reloaded.Webhook.Secret = "rotated-secret"
+	n.Update(&reloaded)
+	watcher.push(t, transfer("0x01", 30, account.TxStatus_Success))
+
+	rec := nextDelivery(t, deliveries)
+	if rec.signature != Sign("rotated-secret", rec.body) {
+		t.Errorf("%s = %q, want signed with the new secret", SignatureHeader, rec.signature)
+	}
+}